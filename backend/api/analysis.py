@@ -0,0 +1,1253 @@
+import io
+import os
+from flask import Blueprint, Response, request, jsonify
+from core.sequence import (back_translate, clean_sequence, degeneracy, expand_ambiguous, six_frame_translate,
+                           translate)
+from core.protein import Protein
+from core.annealing import anneal, render_duplex_svg
+from core.cloning import check_overhangs, design_gibson, design_golden_gate
+from core.pcr import virtual_pcr
+from core.gel import LADDERS, lane_bands, render_gel_svg
+from core.restriction import ENZYMES, digest, find_cuts
+from core.rebase import merge_catalog, read_rebase
+from core.mutagenesis import apply_changes, silent_site_changes
+from core.oligo_calc import concentration_from_a260, oligo_properties, pmol_to_ug, ug_to_pmol
+from core.composition import gc_windows, cumulative_gc_skew, skew_extremes
+from core.complexity import complexity_summary
+from core.repeats import find_palindromes, find_repeats
+from core.myers import find_approximate
+from core.sketch import Sketch, compare_sketches
+from core.motif import best_score, gibbs_sample, parse_motifs, scan
+from core.hmm import ProfileHMM
+from core.dotplot import dot_matches, render_svg
+from core.structure import render_structure_svg, structure_summary
+from core.complexes import Complex, parse_kernel
+from core.domainlib import OrthogonalLibraryGenerator
+from core.enumerator import DEFAULT_RELEASE_CUTOFF, enumerate_reactions
+from core.crn import CRN
+from core.sbml import read_crn, to_sbml
+from core.kinetics import simulate
+from core.sweep import ParameterSweep
+from core.fold import cofold, melt_curve, partition_function, render_dot_plot_svg, structure_energy
+from core.genbank import format_genbank, parse_genbank
+from core.plasmid_map import render_plasmid_map, unique_cutters
+from core.annotation import (Annotation, IntervalTree, format_bed, format_gff3, orf_annotations, primer_annotations,
+                             read_bed, read_gff3, render_tracks_svg, restriction_annotations, track_layout)
+from core.seqio import read_sequences
+from core.phylo import build_tree, parse_newick, render_tree_svg, robinson_foulds
+from core.simulate import empirical_p_value
+from core.manifest import Manifest, new_seed, resolve_seed
+from core.screen import CONTAMINANTS, screen_reads
+from core.blast import parse_blast
+from core.align import ScoringScheme, global_align, local_align, semiglobal_align
+from core.alignview import alignment_view, format_aligned_fasta, format_clustal, pairwise_view
+from core.diff import EditScript, diff, patch
+from api.jobs import parse_job_records
+from api.cache import analysis_cache, cached_analysis
+from api.streaming import ndjson_response, wants_ndjson
+from core.strand import Strand
+
+analysis_bp = Blueprint('analysis', __name__)
+
+# ROBIN_REBASE names a REBASE withrefm or bairoch file that updates the built-in enzyme catalog at startup
+if os.environ.get('ROBIN_REBASE'):
+    with open(os.environ['ROBIN_REBASE'], encoding='utf-8', errors='replace') as rebase_file:
+        merge_catalog(ENZYMES, read_rebase(rebase_file.read()).values())
+
+
+def _request_sequence(data: dict) -> str:
+    """Cleaned sequence from a JSON body, raising ValueError when missing"""
+    sequence = clean_sequence(data.get('sequence', ''))
+    if not sequence:
+        raise ValueError('Sequence is required')
+    return sequence
+
+
+@analysis_bp.route('/windows', methods=['POST'])
+def windowed_composition():
+    """Sliding-window GC content, GC skew, AT skew and cumulative GC skew"""
+    try:
+        data = request.get_json(silent=True) or {}
+        sequence = _request_sequence(data)
+        window = int(data.get('window', 100))
+        step = int(data.get('step', 10))
+
+        return jsonify({
+            'success': True,
+            'length': len(sequence),
+            'window': window,
+            'step': step,
+            'points': gc_windows(sequence, window, step),
+            'cumulative_gc_skew': cumulative_gc_skew(sequence, window, step),
+            'extremes': skew_extremes(sequence, window, step)
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/translate', methods=['POST'])
+def six_frame_translation():
+    """Translations of all six reading frames, aligned under the nucleotide sequence"""
+    try:
+        data = request.get_json(silent=True) or {}
+        sequence = _request_sequence(data)
+        return jsonify({
+            'success': True,
+            'length': len(sequence),
+            'sequence': sequence,
+            'frames': six_frame_translate(sequence)
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/protein', methods=['POST'])
+def protein_properties():
+    """Molecular weight, pI, GRAVY and extinction coefficient of a protein or translated DNA"""
+    try:
+        data = request.get_json(silent=True) or {}
+        if data.get('protein'):
+            protein = Protein(data['protein'], name=data.get('name', ''))
+        else:
+            sequence = _request_sequence(data)
+            protein = Protein(translate(sequence, frame=int(data.get('frame', 0)),
+                                        to_stop=bool(data.get('to_stop', True))), name=data.get('name', ''))
+        return jsonify({'success': True, 'protein': protein.sequence, **protein.properties()})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/back-translate', methods=['POST'])
+def back_translation():
+    """Degenerate IUPAC DNA for a protein, with up to cap concrete encodings"""
+    try:
+        data = request.get_json(silent=True) or {}
+        protein = Protein(data.get('protein', ''))
+        if not protein.sequence:
+            raise ValueError('Protein sequence is required')
+        cap = int(data.get('cap', 100))
+        degenerate = back_translate(protein.sequence)
+        expansions = list(expand_ambiguous(degenerate, cap + 1))
+        return jsonify({
+            'success': True,
+            'protein': protein.sequence,
+            'sequence': degenerate,
+            'degeneracy': degeneracy(degenerate),
+            'expansions': expansions[:cap],
+            'truncated': len(expansions) > cap
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/expand', methods=['POST'])
+def expand_degenerate():
+    """Every concrete ACGT sequence of a degenerate (IUPAC) sequence, e.g. for ordering primer mixes"""
+    try:
+        data = request.get_json(silent=True) or {}
+        strand = Strand(_request_sequence(data), name=data.get('name', ''))
+        limit = int(data.get('limit', 1000))
+        return jsonify({
+            'success': True,
+            'degeneracy': strand.degeneracy(),
+            'sequences': [str(expanded) for expanded in strand.expand(limit)]
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/oligo-calc', methods=['POST'])
+def oligo_calculator():
+    """Oligo molecular weight, ε260, nmol and µg per OD, and optional µg/pmol and A260 conversions"""
+    try:
+        data = request.get_json(silent=True) or {}
+        sequence = _request_sequence(data)
+        result = oligo_properties(sequence, bool(data.get('double_stranded', False)),
+                                  data.get('method', 'nearest_neighbor'))
+        if data.get('ug') not in (None, ''):
+            result['pmol'] = round(ug_to_pmol(float(data['ug']), result['molecular_weight']), 3)
+        if data.get('pmol') not in (None, ''):
+            result['ug'] = round(pmol_to_ug(float(data['pmol']), result['molecular_weight']), 4)
+        if data.get('a260') not in (None, ''):
+            micromolar = concentration_from_a260(float(data['a260']), result['extinction_coefficient'],
+                                                 float(data.get('path_length', 1.0)), float(data.get('dilution', 1.0)))
+            result['concentration_um'] = round(micromolar, 3)
+            result['concentration_ng_ul'] = round(micromolar * result['molecular_weight'] / 1000, 3)
+        return jsonify({'success': True, **result})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/anneal', methods=['POST'])
+@cached_analysis('anneal')
+def anneal_oligos():
+    """Best annealing registers of two oligos with mismatches, overhangs and duplex diagrams"""
+    try:
+        data = request.get_json(silent=True) or {}
+        sequence_a = clean_sequence(data.get('sequence_a', ''))
+        sequence_b = clean_sequence(data.get('sequence_b', ''))
+        duplexes = anneal(sequence_a, sequence_b, int(data.get('min_run', 4)), int(data.get('top', 3)))
+        return jsonify({
+            'success': True,
+            'duplexes': [dict(duplex.to_dict(), svg=render_duplex_svg(duplex)) for duplex in duplexes]
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/cloning', methods=['POST'])
+def plan_cloning():
+    """Gibson overlaps or Golden Gate fusion sites and primers for fragments in assembly order"""
+    try:
+        data = request.get_json(silent=True) or {}
+        fragments = [record.sequence for record in parse_job_records()]
+        circular = bool(data.get('circular', True))
+        method = data.get('method', 'gibson')
+        if method == 'gibson':
+            plan = design_gibson(fragments, int(data.get('overlap', 30)), circular, float(data.get('target_tm', 60.0)))
+        elif method == 'golden_gate':
+            plan = design_golden_gate(fragments, data.get('enzyme', 'BsaI'), circular,
+                                      float(data.get('target_tm', 60.0)))
+        else:
+            raise ValueError(f'Unknown cloning method "{method}"; use gibson or golden_gate')
+        return jsonify({'success': True, **plan})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/cloning/overhangs', methods=['POST'])
+def check_fusion_sites():
+    """Check a set of Golden Gate overhangs for duplicates, palindromes and cross-ligation"""
+    try:
+        data = request.get_json(silent=True) or {}
+        overhangs = data.get('overhangs') or []
+        if isinstance(overhangs, str):
+            overhangs = overhangs.replace(',', ' ').split()
+        if not overhangs:
+            raise ValueError('Overhangs are required')
+        issues = check_overhangs(overhangs)
+        return jsonify({'success': True, 'valid': not issues, 'issues': issues})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/pcr', methods=['POST'])
+@cached_analysis('pcr')
+def in_silico_pcr():
+    """Predicted amplicons of a primer pair on a template, with a virtual gel"""
+    try:
+        data = request.get_json(silent=True) or {}
+        template = _request_sequence(data)
+        forward = clean_sequence(data.get('forward', ''))
+        reverse = clean_sequence(data.get('reverse', ''))
+        if not forward or not reverse:
+            raise ValueError('Forward and reverse primers are required')
+        amplicons = virtual_pcr(template, [('forward', forward), ('reverse', reverse)],
+                                int(data.get('max_mismatches', 2)), int(data.get('three_prime_exact', 3)),
+                                bool(data.get('circular', False)), int(data.get('max_size', 10000)))
+        return jsonify({
+            'success': True,
+            'template_length': len(template),
+            'amplicons': amplicons,
+            'gel_svg': render_gel_svg([{'name': 'PCR', 'sizes': [amplicon['size'] for amplicon in amplicons]}],
+                                      data.get('ladder', '1kb'))
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/digest', methods=['POST'])
+@cached_analysis('digest')
+def restriction_digest():
+    """Restriction fragments with their overhangs, plus every cut and nick of the enzymes"""
+    try:
+        data = request.get_json(silent=True) or {}
+        sequence = _request_sequence(data)
+        enzymes = data.get('enzymes') or []
+        if not enzymes:
+            raise ValueError('At least one enzyme is required')
+        unknown = [name for name in enzymes if name not in ENZYMES]
+        if unknown:
+            raise ValueError(f'Unknown enzyme "{unknown[0]}"')
+        circular, methylation = bool(data.get('circular')), data.get('methylation')
+
+        cutters = [name for name in enzymes if not ENZYMES[name].nick]
+        cuts = [cut for name in enzymes for cut in find_cuts(sequence, ENZYMES[name], circular, methylation)]
+        return jsonify({
+            'success': True,
+            'length': len(sequence),
+            'circular': circular,
+            'fragments': digest(sequence, cutters, circular=circular, methylation=methylation) if cutters else [],
+            'cuts': [cut.to_dict() for cut in cuts]
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/enzymes', methods=['GET'])
+def list_enzymes():
+    """Restriction enzyme catalog with cut offsets, nicking strand and methylation sensitivity"""
+    return jsonify({'success': True, 'enzymes': [enzyme.to_dict() for enzyme in ENZYMES.values()]})
+
+
+@analysis_bp.route('/enzymes/rebase', methods=['POST'])
+def import_rebase():
+    """Refresh the enzyme catalog from a REBASE withrefm or bairoch file (upload or JSON text)"""
+    try:
+        if 'file' in request.files:
+            text = request.files['file'].read().decode('utf-8', errors='replace')
+            options = request.form
+        else:
+            options = request.get_json(silent=True) or {}
+            text = options.get('text', '')
+        if not text.strip():
+            raise ValueError('REBASE text is required')
+
+        enzymes = read_rebase(text, commercial_only=str(options.get('commercial_only', '')).lower() in ('1', 'true'))
+        counts = merge_catalog(ENZYMES, enzymes.values(), replace=str(options.get('replace', '')).lower() in ('1', 'true'))
+        # Digests are keyed by enzyme name, so cached results may describe the old catalog
+        analysis_cache.clear()
+        return jsonify({'success': True, **counts})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/gel', methods=['POST'])
+def virtual_gel():
+    """Simulated agarose gel of fragment sizes or restriction digests next to a ladder"""
+    try:
+        data = request.get_json(silent=True) or {}
+        lanes = []
+        for i, lane in enumerate(data.get('lanes') or []):
+            name = lane.get('name') or f'Lane {i + 1}'
+            if lane.get('enzymes'):
+                sequence = clean_sequence(lane.get('sequence', ''))
+                if not sequence:
+                    raise ValueError(f'{name}: a sequence is required to digest')
+                sizes = [fragment['length'] for fragment in
+                         digest(sequence, lane['enzymes'], circular=bool(lane.get('circular')),
+                                methylation=lane.get('methylation'))]
+            else:
+                sizes = [int(size) for size in lane.get('sizes') or []]
+            lanes.append({'name': name, 'sizes': sizes})
+        if not lanes:
+            raise ValueError('At least one lane is required')
+        ladder = data.get('ladder', '1kb')
+        return jsonify({
+            'success': True,
+            'ladder': ladder,
+            'ladders': sorted(LADDERS),
+            'lanes': [dict(lane, bands=lane_bands(lane['sizes'])) for lane in lanes],
+            'svg': render_gel_svg(lanes, ladder)
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/silent-sites', methods=['POST'])
+@cached_analysis('silent-sites')
+def silent_sites():
+    """Synonymous codon changes that introduce or remove a restriction site in a CDS"""
+    try:
+        data = request.get_json(silent=True) or {}
+        sequence = _request_sequence(data)
+        suggestions = silent_site_changes(sequence, data.get('enzyme', ''), data.get('mode', 'introduce'),
+                                          int(data.get('max_changes', 3)), int(data.get('limit', 20)))
+        for suggestion in suggestions:
+            suggestion['sequence'] = apply_changes(sequence, suggestion['changes'])
+        return jsonify({'success': True, 'protein': translate(sequence), 'suggestions': suggestions})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/screen', methods=['POST'])
+@cached_analysis('screen')
+def screen():
+    """Percentage of reads or strands containing bundled or user-supplied contaminant sequences"""
+    try:
+        data = request.get_json(silent=True) or {}
+        records = parse_job_records()
+        if not records:
+            raise ValueError('At least one sequence is required')
+        contaminants = data.get('contaminants') or {}
+        if not isinstance(contaminants, dict):
+            raise ValueError('contaminants must map names to sequences')
+        return jsonify({
+            'success': True,
+            **screen_reads(records, contaminants, data.get('include_bundled', True), int(data.get('k', 12)),
+                           int(data.get('min_hits', 1)))
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/screen/contaminants', methods=['GET'])
+def list_contaminants():
+    """Bundled contaminant sequences used by the screen"""
+    return jsonify({'success': True, 'contaminants': CONTAMINANTS})
+
+
+@analysis_bp.route('/blast/parse', methods=['POST'])
+def parse_blast_results():
+    """Hits from BLAST XML or tabular output (upload or JSON text), in 0-based query coordinates"""
+    try:
+        if 'file' in request.files:
+            text = request.files['file'].read().decode('utf-8', errors='replace')
+        else:
+            text = (request.get_json(silent=True) or {}).get('text', '')
+        if not text.strip():
+            raise ValueError('BLAST output is required')
+
+        hits = parse_blast(text)
+        return jsonify({'success': True, 'count': len(hits), 'hits': [hit.to_dict() for hit in hits]})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/diff', methods=['POST'])
+@cached_analysis('diff')
+def sequence_diff():
+    """Edit script turning sequence a into sequence b, in its text serialization"""
+    try:
+        data = request.get_json(silent=True) or {}
+        a, b = ''.join(str(data.get('a', '')).split()), ''.join(str(data.get('b', '')).split())
+        if not a and not b:
+            raise ValueError('Sequences a and b are required')
+        script = diff(a, b)
+        return jsonify({'success': True, 'diff': script.to_text(), **script.summary()})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/patch', methods=['POST'])
+def sequence_patch():
+    """Apply a text diff to the sequence it was made from"""
+    try:
+        data = request.get_json(silent=True) or {}
+        if not data.get('diff'):
+            raise ValueError('A diff is required')
+        sequence = ''.join(str(data.get('sequence', '')).split())
+        return jsonify({'success': True, 'sequence': patch(sequence, EditScript.from_text(data['diff']))})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/complexity', methods=['POST'])
+@cached_analysis('complexity')
+def sequence_complexity():
+    """Shannon entropy, linguistic complexity and DUST low-complexity masking"""
+    try:
+        data = request.get_json(silent=True) or {}
+        sequence = _request_sequence(data)
+        window = int(data.get('window', 64))
+        threshold = float(data.get('threshold', 20.0))
+        hard = bool(data.get('hard_mask', False))
+
+        summary = complexity_summary(sequence, window=window, threshold=threshold)
+        regions = [(r['start'], r['end']) for r in summary['low_complexity_regions']]
+        masked = Strand(sequence).mask(regions, hard=hard)
+
+        return jsonify({'success': True, **summary, 'masked_sequence': masked.sequence})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/repeats', methods=['POST'])
+@cached_analysis('repeats')
+def sequence_repeats():
+    """Homopolymer runs, short tandem repeats and inverted repeats (potential hairpins)"""
+    try:
+        data = request.get_json(silent=True) or {}
+        sequence = _request_sequence(data)
+        regions = find_repeats(
+            sequence,
+            min_homopolymer=int(data.get('min_homopolymer', 6)),
+            min_copies=int(data.get('min_copies', 4)),
+            min_stem=int(data.get('min_stem', 6)),
+            max_loop=int(data.get('max_loop', 20))
+        )
+        return jsonify({'success': True, 'length': len(sequence), 'repeats': regions})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/palindromes', methods=['POST'])
+def sequence_palindromes():
+    """Reverse-complement palindromes, allowing a number of mismatches"""
+    try:
+        data = request.get_json(silent=True) or {}
+        sequence = _request_sequence(data)
+        palindromes = find_palindromes(
+            sequence,
+            min_length=int(data.get('min_length', 4)),
+            max_length=int(data.get('max_length', 12)),
+            max_mismatches=int(data.get('max_mismatches', 0))
+        )
+        return jsonify({'success': True, 'length': len(sequence), 'palindromes': palindromes})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/approximate', methods=['POST'])
+def approximate_matches():
+    """Occurrences of a short pattern (primer, adapter) with up to max_errors substitutions or indels"""
+    try:
+        data = request.get_json(silent=True) or {}
+        sequence = _request_sequence(data)
+        matches = find_approximate(sequence, data.get('pattern', ''), int(data.get('max_errors', 1)),
+                                   bool(data.get('both_strands', True)))
+        return jsonify({'success': True, 'length': len(sequence), 'matches': matches})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/sketch', methods=['POST'])
+def sketch_compare():
+    """MinHash sketches of sequences or read sets, compared pairwise and with saved sketches"""
+    try:
+        data = request.get_json(silent=True) or {}
+        k, size = int(data.get('k', 21)), int(data.get('size', 1000))
+        records = parse_job_records()
+        if data.get('combine') and records:
+            sketches = [Sketch.from_sequences((record.sequence for record in records), k, size,
+                                              name=data.get('name') or 'combined')]
+        else:
+            sketches = [Sketch.from_sequences([record.sequence], k, size, name=record.name) for record in records]
+        saved = data.get('sketches') or []
+        if not isinstance(saved, list):
+            raise ValueError('sketches must be a list of saved sketches')
+        sketches.extend(Sketch.from_dict(item) for item in saved)
+        if not sketches:
+            raise ValueError('At least one sequence or sketch is required')
+        return jsonify({'success': True, 'sketches': [sketch.to_dict() for sketch in sketches],
+                        'comparisons': compare_sketches(sketches)})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/motifs', methods=['POST'])
+def motif_search():
+    """Scan a sequence with JASPAR or MEME position weight matrices"""
+    try:
+        data = request.get_json(silent=True) or {}
+        sequence = _request_sequence(data)
+        if not data.get('motifs'):
+            raise ValueError('Motif text (JASPAR or MEME) is required')
+        motifs = parse_motifs(data['motifs'], data.get('format'))
+        threshold = float(data.get('threshold', 0.8))
+        relative = bool(data.get('relative', True))
+
+        shuffles = int(data.get('shuffles', 0))
+        seed = resolve_seed(data.get('seed')) if shuffles else None
+
+        hits = [hit for motif in motifs for hit in scan(sequence, motif, threshold, relative)]
+        summaries = []
+        for motif in motifs:
+            summary = {'id': motif.id, 'name': motif.name, 'length': len(motif), 'consensus': motif.consensus()}
+            if shuffles:
+                # Best score against dinucleotide-shuffled copies of the sequence
+                significance = empirical_p_value(lambda s: best_score(s, motif), sequence, 2, shuffles, seed)
+                summary['best_score'] = round(significance['observed'], 3)
+                summary['p_value'] = round(significance['p_value'], 4)
+            summaries.append(summary)
+        return jsonify({
+            'success': True,
+            'motifs': summaries,
+            'hits': sorted(hits, key=lambda hit: (hit['start'], hit['motif'])),
+            'seed': seed,
+            'manifest': Manifest.create('motifs', data, seed, sequence=sequence, motifs=data['motifs']).to_dict()
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/motifs/discover', methods=['POST'])
+def motif_discovery():
+    """Discover a shared length-k motif in a set of sequences by Gibbs sampling"""
+    try:
+        data = request.get_json(silent=True) or {}
+        records = parse_job_records()
+        seed = resolve_seed(data.get('seed'))
+        result = gibbs_sample(
+            [record.sequence for record in records],
+            k=int(data.get('k', 8)),
+            iterations=int(data.get('iterations', 500)),
+            restarts=int(data.get('restarts', 10)),
+            seed=seed
+        )
+        motif = result['motif']
+        for site in result['sites']:
+            site['name'] = records[site['sequence_index']].name
+
+        return jsonify({
+            'success': True,
+            'consensus': motif.consensus(),
+            'counts': motif.counts,
+            'pssm': motif.pssm(),
+            'information': result['information'],
+            'sites': result['sites'],
+            'seed': seed,
+            'manifest': Manifest.create('motifs/discover', {name: data[name] for name in ('k', 'iterations', 'restarts')
+                                                            if name in data}, seed,
+                                        sequences={record.name: record.sequence for record in records}).to_dict()
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/hmm', methods=['POST'])
+@cached_analysis('hmm')
+def profile_hmm():
+    """Build a profile HMM from a multiple alignment and score sequences against it
+
+    With Accept: application/x-ndjson (or ?format=ndjson) the model comes first and then each
+    sequence's result on its own line as soon as it is scored.
+    """
+    try:
+        data = request.get_json(silent=True) or {}
+        alignment = data.get('alignment')
+        if isinstance(alignment, str):
+            alignment = [record.sequence for record in read_sequences(io.StringIO(alignment))]
+        if not alignment:
+            raise ValueError('An alignment (aligned FASTA or a list of rows) is required')
+
+        profile = ProfileHMM(alignment, max_gap_fraction=float(data.get('max_gap_fraction', 0.5)))
+
+        def score(record):
+            aligned = profile.viterbi(record.sequence)
+            return {
+                'name': record.name,
+                'viterbi_score': round(aligned.score, 3),
+                'forward_score': round(profile.forward(record.sequence), 3),
+                'path': aligned.path,
+                'model_line': aligned.model_line,
+                'sequence_line': aligned.sequence_line
+            }
+
+        records = parse_job_records()
+        model = {'success': True, 'model_length': profile.length, 'consensus': profile.consensus()}
+        if wants_ndjson():
+            return ndjson_response(model, (score(record) for record in records))
+        return jsonify({**model, 'results': [score(record) for record in records]})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/dotplot', methods=['POST'])
+@cached_analysis('dotplot')
+def dotplot():
+    """Word-match dot plot of two sequences as SVG (raw with format=svg, otherwise wrapped in JSON)"""
+    try:
+        data = request.get_json(silent=True) or {}
+        sequence_a = clean_sequence(data.get('sequence_a', ''))
+        sequence_b = clean_sequence(data.get('sequence_b', '')) or sequence_a
+        if not sequence_a:
+            raise ValueError('sequence_a is required')
+
+        dots = dot_matches(
+            sequence_a, sequence_b,
+            word=int(data.get('word', 10)),
+            mismatches=int(data.get('mismatches', 0)),
+            both_strands=bool(data.get('both_strands', True))
+        )
+        svg = render_svg(sequence_a, sequence_b, dots, size=int(data.get('size', 500)),
+                         name_a=data.get('name_a', 'Sequence A'), name_b=data.get('name_b', 'Sequence B'))
+
+        if data.get('format') == 'svg':
+            return Response(svg, mimetype='image/svg+xml')
+        return jsonify({
+            'success': True,
+            'length_a': len(sequence_a),
+            'length_b': len(sequence_b),
+            'forward_matches': sum(1 for dot in dots if dot[2] == '+'),
+            'reverse_matches': sum(1 for dot in dots if dot[2] == '-'),
+            'svg': svg
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/fold', methods=['POST'])
+@cached_analysis('fold')
+def fold_ensemble():
+    """Partition function, base-pair probabilities and dot plot of a strand, or of two with sequence_b
+
+    An optional constraint string (x unpaired, | paired, () forced pair) limits the ensemble, and
+    a target dot-bracket adds its ensemble defect.
+    The dot plot is returned raw with format=svg.
+    """
+    try:
+        data = request.get_json(silent=True) or {}
+        temperature = float(data.get('temperature', 37.0))
+        constraint = str(data.get('constraint', ''))
+        if data.get('sequence_b'):
+            ensemble = cofold(str(data.get('sequence', '')), str(data['sequence_b']), temperature, constraint)
+        else:
+            ensemble = partition_function(str(data.get('sequence', '')), temperature, constraint)
+        dot_plot = render_dot_plot_svg(ensemble)
+        if data.get('format') == 'svg':
+            return Response(dot_plot, mimetype='image/svg+xml')
+
+        centroid = ensemble.centroid()
+        extra = {}
+        if data.get('target'):
+            defects = ensemble.defect(str(data['target']))
+            extra = {'defect': round(sum(defects), 3), 'normalized_defect': round(sum(defects) / len(defects), 4),
+                     'base_defects': [round(value, 3) for value in defects]}
+        return jsonify({
+            'success': True,
+            **ensemble.to_dict(float(data.get('threshold', 0.01))),
+            **extra,
+            'centroid_dg': round(structure_energy(ensemble.sequence, centroid, temperature), 2),
+            'centroid_probability': round(ensemble.probability(centroid), 4),
+            'dot_plot': dot_plot,
+            'centroid_svg': render_structure_svg(centroid, ensemble.sequence)
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/fold/melt', methods=['POST'])
+@cached_analysis('fold_melt')
+def fold_melt():
+    """Melt curve and Tm of a strand, or of its duplex with sequence_b, from start to stop °C"""
+    try:
+        data = request.get_json(silent=True) or {}
+        start, stop = float(data.get('start', 20)), float(data.get('stop', 90))
+        step = float(data.get('step', 5))
+        if step <= 0 or stop < start:
+            raise ValueError('Temperatures need start <= stop and a positive step')
+        temperatures = [start + step * index for index in range(int((stop - start) / step) + 1)]
+        curve = melt_curve(str(data.get('sequence', '')), str(data.get('sequence_b', '')) or None, temperatures,
+                           float(data.get('concentration', 250.0)))
+        return jsonify({'success': True, **curve})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/domains/library', methods=['POST'])
+def domain_library():
+    """Mutually orthogonal domains of one length, as JSON or raw FASTA/CSV for ordering with format"""
+    try:
+        data = request.get_json(silent=True) or {}
+        threshold = data.get('threshold')
+        seed = resolve_seed(data.get('seed'))
+        generator = OrthogonalLibraryGenerator(
+            int(data.get('count', 10)), int(data.get('length', 20)), str(data.get('method', 'thermo')),
+            float(threshold) if threshold is not None else None,
+            float(data.get('gc_min', 40.0)), float(data.get('gc_max', 60.0)), seed, prefix=str(data.get('prefix', 'd')))
+        library = generator.generate()
+        library.complements = bool(data.get('complements', False))
+        fmt = data.get('format', 'json').lower()
+        if fmt == 'fasta':
+            return Response(library.to_fasta(), mimetype='text/plain')
+        if fmt == 'csv':
+            return Response(library.to_csv(), mimetype='text/csv')
+        if fmt != 'json':
+            raise ValueError(f'Unknown library format "{fmt}"')
+        return jsonify({'success': True, **library.to_dict(), 'seed': seed,
+                        'manifest': Manifest.create('domains/library', data, seed).to_dict()})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/complex', methods=['POST'])
+@cached_analysis('complex')
+def complex_notation():
+    """Kernel, Visual DSD and ASCII duplex renderings of a domain-level complex
+
+    The complex is given in kernel notation, or as strands (lists of domain names) with a
+    domain-level dot-bracket structure. dsd and ascii are null for complexes that are not one
+    bottom strand with top strands bound to it.
+    """
+    try:
+        data = request.get_json(silent=True) or {}
+        if data.get('kernel'):
+            complex_ = parse_kernel(str(data['kernel']))
+        elif data.get('strands'):
+            strands = [strand.split() if isinstance(strand, str) else [str(name) for name in strand]
+                       for strand in data['strands']]
+            complex_ = Complex(strands, str(data.get('structure', '')))
+        else:
+            raise ValueError('Provide kernel notation, or strands with a structure')
+        return jsonify({'success': True, **complex_.to_dict()})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+def _enumerate(data: dict):
+    """Reaction network of a request's 'name = kernel' complexes under its enumeration options"""
+    lengths = {str(name): int(length) for name, length in (data.get('lengths') or {}).items()}
+    return enumerate_reactions(
+        str(data.get('complexes', '')), str(data.get('semantics', 'condensed')), lengths,
+        int(data.get('release_cutoff', DEFAULT_RELEASE_CUTOFF)), int(data.get('max_complex_size', 6)),
+        min(int(data.get('max_species', 200)), 500), bool(data.get('remote', True)), bool(data.get('leak', False)))
+
+
+@analysis_bp.route('/enumerate', methods=['POST'])
+@cached_analysis('enumerate')
+def enumerate_dsd():
+    """Species and reactions reachable from DSD complexes given as 'name = kernel' lines, with optional leak analysis"""
+    try:
+        data = request.get_json(silent=True) or {}
+        enumeration = _enumerate(data)
+        return jsonify({'success': True, **enumeration.to_dict(), 'text': enumeration.format()})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/network', methods=['POST'])
+def export_network():
+    """Reaction network of DSD complexes, or a CRN as text or SBML, as drawable JSON, Graphviz DOT, GraphML or SBML"""
+    try:
+        data = request.get_json(silent=True) or {}
+        output = data.get('format', 'json')
+        if output not in ('json', 'dot', 'graphml', 'sbml', 'crn'):
+            raise ValueError('format must be json, dot, graphml, sbml or crn')
+        crn = read_crn(str(data['crn'])) if data.get('crn') else CRN.from_enumeration(_enumerate(data))
+        if output == 'dot':
+            return Response(crn.to_dot(), mimetype='text/vnd.graphviz')
+        if output == 'graphml':
+            return Response(crn.to_graphml(), mimetype='application/graphml+xml')
+        if output == 'sbml':
+            amounts = {str(name): float(amount) for name, amount in (data.get('initial') or {}).items()}
+            sbml = to_sbml(crn, amounts, str(data.get('model') or 'crn'), float(data.get('rate', 1.0)))
+            return Response(sbml, mimetype='application/sbml+xml')
+        if output == 'crn':
+            return Response(crn.format(), mimetype='text/plain')
+        return jsonify({'success': True, **crn.to_dict()})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/simulate', methods=['POST'])
+def simulate_kinetics():
+    """Time course of a CRN, or of enumerated DSD complexes, as plot-ready series, an SVG chart or CSV"""
+    try:
+        data = request.get_json(silent=True) or {}
+        output = data.get('format', 'json')
+        if output not in ('json', 'svg', 'csv'):
+            raise ValueError('format must be json, svg or csv')
+        crn = read_crn(str(data['crn'])) if data.get('crn') else CRN.from_enumeration(_enumerate(data))
+        initial = {**crn.amounts, **{str(name): float(amount) for name, amount in (data.get('initial') or {}).items()}}
+        if not initial:
+            raise ValueError('Give initial amounts, e.g. {"A": 1e-6}')
+        method = str(data.get('method', 'ode'))
+        seed = resolve_seed(data.get('seed')) if method == 'ssa' else None
+        course = simulate(crn, initial, float(data.get('t_end', 0)), method,
+                          int(data.get('points', 200)), float(data.get('rate', 1.0)), seed)
+        species = [str(name) for name in data.get('species') or []]
+        if output == 'svg':
+            return Response(course.to_svg(species), mimetype='image/svg+xml')
+        if output == 'csv':
+            return Response(course.to_csv(species), mimetype='text/csv')
+        inputs = {'crn': data['crn']} if data.get('crn') else {'complexes': data.get('complexes', '')}
+        return jsonify({'success': True, 'species': crn.species, **course.to_dict(species), 'seed': seed,
+                        'manifest': Manifest.create('simulate', data, seed, **inputs).to_dict()})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/sweep', methods=['POST'])
+def sweep_parameters():
+    """Output metric of simulations over one or two swept parameters, as a grid, SVG heatmap or CSV"""
+    try:
+        data = request.get_json(silent=True) or {}
+        output = data.get('format', 'json')
+        if output not in ('json', 'svg', 'csv'):
+            raise ValueError('format must be json, svg or csv')
+        if data.get('method') == 'ssa' and data.get('seed') is None:
+            data = {**data, 'seed': new_seed()}
+        result = ParameterSweep.from_dict(data).run()
+        if output == 'svg':
+            return Response(result.to_svg(), mimetype='image/svg+xml')
+        if output == 'csv':
+            return Response(result.to_csv(), mimetype='text/csv')
+        inputs = {name: data[name] for name in ('crn', 'complexes') if data.get(name)}
+        return jsonify({'success': True, **result.to_dict(), 'seed': data.get('seed'),
+                        'manifest': Manifest.create('sweep', data, data.get('seed'), **inputs).to_dict()})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/structure', methods=['POST'])
+def draw_structure():
+    """Secondary structure drawing of a dot-bracket string (strands separated by &) as SVG, raw with format=svg"""
+    try:
+        data = request.get_json(silent=True) or {}
+        structure = ''.join(str(data.get('structure', '')).split())
+        if not structure:
+            raise ValueError('A dot-bracket structure is required')
+
+        svg = render_structure_svg(structure, data.get('sequence', ''), number_every=int(data.get('number_every', 10)))
+        if data.get('format') == 'svg':
+            return Response(svg, mimetype='image/svg+xml')
+        return jsonify({'success': True, **structure_summary(structure), 'svg': svg})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/plasmid-map', methods=['POST'])
+@cached_analysis('plasmid-map')
+def plasmid_map():
+    """Circular SVG map of a GenBank record with its features and restriction sites"""
+    try:
+        data = request.get_json(silent=True) or {}
+        if not data.get('genbank'):
+            raise ValueError('GenBank text is required')
+        record = parse_genbank(data['genbank'])[0]
+        enzymes = data.get('enzymes')
+        svg = render_plasmid_map(record, enzymes=enzymes, size=int(data.get('size', 600)))
+
+        if data.get('format') == 'svg':
+            return Response(svg, mimetype='image/svg+xml')
+        return jsonify({
+            'success': True,
+            'name': record.name,
+            'length': len(record.sequence),
+            'topology': record.topology,
+            'features': [feature.to_dict() for feature in record.features],
+            'unique_cutters': unique_cutters(record.sequence, circular=record.circular),
+            'svg': svg
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/genbank/rotate', methods=['POST'])
+def rotate_genbank():
+    """Renumber a circular GenBank record from a new origin, moving features that span it"""
+    try:
+        data = request.get_json(silent=True) or {}
+        if not data.get('genbank'):
+            raise ValueError('GenBank text is required')
+        record = parse_genbank(data['genbank'])[0]
+        if data.get('canonical'):
+            origin = record.strand().canonical_origin()
+        else:
+            origin = int(data.get('origin', 0))
+        rotated = record.rotate(origin)
+
+        if data.get('format') == 'genbank':
+            return Response(format_genbank(rotated), mimetype='text/plain')
+        return jsonify({
+            'success': True,
+            'name': rotated.name,
+            'origin': origin % len(record.sequence) if record.sequence else 0,
+            'features': [feature.to_dict() for feature in rotated.features],
+            'genbank': format_genbank(rotated)
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/annotations', methods=['POST'])
+def annotation_tracks():
+    """Linear feature tracks (ORFs, restriction sites, primers, user annotations) as JSON and SVG"""
+    try:
+        data = request.get_json(silent=True) or {}
+        sequence = _request_sequence(data)
+
+        annotations = orf_annotations(sequence, min_length=int(data.get('min_orf_length', 300)))
+        annotations += restriction_annotations(sequence, data.get('enzymes', []), bool(data.get('circular')))
+        annotations += primer_annotations(sequence, data.get('primers', []))
+        annotations += [Annotation.from_dict(item) for item in data.get('annotations', [])]
+
+        layout = track_layout(len(sequence), annotations)
+        return jsonify({'success': True, **layout, 'svg': render_tracks_svg(layout)})
+
+    except (ValueError, TypeError, KeyError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/annotations/export', methods=['POST'])
+def export_annotations():
+    """Write annotations as GFF3 or BED text"""
+    try:
+        data = request.get_json(silent=True) or {}
+        annotations = [Annotation.from_dict(item) for item in data.get('annotations', [])]
+        seqid = data.get('seqid', 'sequence')
+        length = int(data['length']) if data.get('length') is not None else None
+        fmt = data.get('format', 'gff3').lower()
+        if fmt == 'gff3':
+            return Response(format_gff3(seqid, annotations, length), mimetype='text/x-gff3')
+        if fmt == 'bed':
+            return Response(format_bed(seqid, annotations, length), mimetype='text/plain')
+        raise ValueError(f'Unknown annotation format "{fmt}"')
+
+    except (ValueError, TypeError, KeyError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/annotations/import', methods=['POST'])
+def import_annotations():
+    """Read annotations from GFF3 or BED text (JSON body or file upload)"""
+    try:
+        if 'file' in request.files:
+            upload = request.files['file']
+            text = upload.read().decode('utf-8')
+            fmt = request.form.get('format') or ('bed' if upload.filename.lower().endswith('.bed') else 'gff3')
+        else:
+            data = request.get_json(silent=True) or {}
+            text, fmt = data.get('text', ''), data.get('format', 'gff3')
+
+        fmt = fmt.lower()
+        if fmt == 'gff3':
+            parsed = read_gff3(text)
+        elif fmt == 'bed':
+            parsed = read_bed(text)
+        else:
+            raise ValueError(f'Unknown annotation format "{fmt}"')
+
+        return jsonify({
+            'success': True,
+            'sequences': {seqid: [a.to_dict() for a in annotations] for seqid, annotations in parsed.items()}
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/annotations/query', methods=['POST'])
+def query_annotations():
+    """Annotations covering a position or overlapping a range, from JSON annotations or GFF3/BED text"""
+    try:
+        data = request.get_json(silent=True) or {}
+        if data.get('text'):
+            fmt = data.get('format', 'gff3').lower()
+            if fmt not in ('gff3', 'bed'):
+                raise ValueError(f'Unknown annotation format "{fmt}"')
+            parsed = read_gff3(data['text']) if fmt == 'gff3' else read_bed(data['text'])
+            seqid = data.get('seqid') or next(iter(parsed), '')
+            annotations = parsed.get(seqid, [])
+        else:
+            annotations = [Annotation.from_dict(item) for item in data.get('annotations', [])]
+
+        if data.get('position') is not None:
+            start = int(data['position'])
+            end = start + 1
+        elif data.get('start') is not None:
+            start = int(data['start'])
+            end = int(data.get('end', start + 1))
+        else:
+            raise ValueError('A position or a start and end are required')
+        if end <= start:
+            raise ValueError('end must be greater than start')
+
+        length = int(data['length']) if data.get('length') is not None else None
+        hits = IntervalTree(annotations, length).overlapping(start, end)
+        return jsonify({'success': True, 'start': start, 'end': end, 'total': len(annotations),
+                        'annotations': [annotation.to_dict() for annotation in hits]})
+
+    except (ValueError, TypeError, KeyError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/alignment/view', methods=['POST'])
+def view_alignment():
+    """Display blocks and CLUSTAL/aligned FASTA exports of a multiple alignment, or of two sequences aligned pairwise
+
+    Send either alignment (aligned FASTA or a list of rows) or a and b to align, with
+    local, free_ends and scoring as for batch align jobs.
+    """
+    try:
+        data = request.get_json(silent=True) or {}
+        width = int(data.get('width', 60))
+        if data.get('a') or data.get('b'):
+            a, b = clean_sequence(data.get('a', '')), clean_sequence(data.get('b', ''))
+            if not a or not b:
+                raise ValueError('Both sequences a and b are required')
+            scoring = ScoringScheme(**data.get('scoring', {}))
+            if data.get('free_ends') and not data.get('local'):
+                alignment = semiglobal_align(a, b, scoring, data['free_ends'])
+            else:
+                align = local_align if data.get('local') else global_align
+                alignment = align(a, b, scoring)
+            names = [data.get('name_a') or 'a', data.get('name_b') or 'b']
+            rows = [alignment.aligned_a, alignment.aligned_b]
+            view = pairwise_view(alignment, *names, width=width)
+        else:
+            alignment = data.get('alignment')
+            if isinstance(alignment, str):
+                records = list(read_sequences(io.StringIO(alignment)))
+                names, rows = [record.name.split()[0] for record in records], [record.sequence for record in records]
+            else:
+                rows = [clean_sequence(row) for row in alignment or []]
+                names = [f'seq{i + 1}' for i in range(len(rows))]
+            view = alignment_view(names, rows, width)
+
+        return jsonify({
+            'success': True,
+            'view': view,
+            'clustal': format_clustal(names, rows, width),
+            'fasta': format_aligned_fasta(names, rows, width)
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/phylogeny', methods=['POST'])
+@cached_analysis('phylogeny')
+def phylogeny():
+    """Distance matrix, neighbor-joining or UPGMA tree (Newick) and SVG rendering of aligned sequences"""
+    try:
+        data = request.get_json(silent=True) or {}
+        records = parse_job_records()
+        if len(records) < 2:
+            raise ValueError('At least two aligned sequences are required')
+        result = build_tree(records, data.get('model', 'jc69'), data.get('method', 'nj'))
+        return jsonify({
+            'success': True,
+            'names': result['names'],
+            'matrix': [[round(value, 6) for value in row] for row in result['matrix']],
+            'newick': result['tree'].to_newick(),
+            'svg': render_tree_svg(result['tree'])
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@analysis_bp.route('/phylogeny/compare', methods=['POST'])
+def compare_trees():
+    """Robinson-Foulds distance between two Newick trees over the same leaves"""
+    try:
+        data = request.get_json(silent=True) or {}
+        if not data.get('tree_a') or not data.get('tree_b'):
+            raise ValueError('Both tree_a and tree_b (Newick) are required')
+        return jsonify({
+            'success': True,
+            **robinson_foulds(parse_newick(data['tree_a']), parse_newick(data['tree_b']))
+        })
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
@@ -0,0 +1,136 @@
+import os
+import json
+import base64
+import hashlib
+import hmac
+import secrets
+import uuid
+import urllib.parse
+import urllib.request
+from typing import Optional
+from flask import Blueprint, Flask, current_app, jsonify, redirect, request, session
+
+auth_bp = Blueprint('auth', __name__)
+
+# GitHub OAuth is enabled when both credentials are configured
+OAUTH_CLIENT_ID = os.environ.get('OAUTH_CLIENT_ID', '')
+OAUTH_CLIENT_SECRET = os.environ.get('OAUTH_CLIENT_SECRET', '')
+OAUTH_AUTHORIZE_URL = 'https://github.com/login/oauth/authorize'
+OAUTH_TOKEN_URL = 'https://github.com/login/oauth/access_token'
+OAUTH_USER_URL = 'https://api.github.com/user'
+FRONTEND_URL = os.environ.get('FRONTEND_URL', 'http://localhost:3000')
+
+
+def oauth_enabled() -> bool:
+    return bool(OAUTH_CLIENT_ID and OAUTH_CLIENT_SECRET)
+
+
+def current_owner() -> str:
+    """Owner id for the current session; anonymous sessions get a random id"""
+    if 'owner_id' not in session:
+        session['owner_id'] = f'anon:{uuid.uuid4()}'
+    return session['owner_id']
+
+
+def current_author() -> str:
+    """Name recorded on workspace edits: the signed-in login, or the anonymous owner id"""
+    return (session.get('user') or {}).get('login') or current_owner()
+
+
+def _signature(value: str) -> str:
+    key = str(current_app.secret_key).encode()
+    digest = hmac.new(key, f'share:{value}'.encode(), hashlib.sha256).digest()
+    return base64.urlsafe_b64encode(digest).decode().rstrip('=')
+
+
+def sign_token(value: str) -> str:
+    """URL-safe token carrying value, signed with the app secret (set SECRET_KEY so tokens survive restarts)"""
+    encoded = base64.urlsafe_b64encode(value.encode()).decode().rstrip('=')
+    return f'{encoded}.{_signature(value)}'
+
+
+def verify_token(token: str) -> Optional[str]:
+    """The value inside a token from sign_token, or None when it is malformed or the signature is wrong"""
+    encoded, _, signature = token.partition('.')
+    try:
+        value = base64.urlsafe_b64decode(encoded + '=' * (-len(encoded) % 4)).decode()
+    except ValueError:
+        return None
+    return value if hmac.compare_digest(signature, _signature(value)) else None
+
+
+def init_sessions(app: Flask):
+    """Configure signed cookie sessions"""
+    app.secret_key = os.environ.get('SECRET_KEY') or secrets.token_hex(32)
+    app.config['SESSION_COOKIE_HTTPONLY'] = True
+    app.config['SESSION_COOKIE_SAMESITE'] = 'Lax'
+
+
+def _http_json(url: str, data: dict = None, headers: dict = None) -> dict:
+    """POST form data (or GET when data is None) and decode a JSON response"""
+    body = urllib.parse.urlencode(data).encode() if data is not None else None
+    req = urllib.request.Request(url, data=body, headers={'Accept': 'application/json', **(headers or {})})
+    with urllib.request.urlopen(req, timeout=10) as response:
+        return json.loads(response.read().decode())
+
+
+@auth_bp.route('/me', methods=['GET'])
+def me():
+    """Current session's owner and login state"""
+    return jsonify({
+        'success': True,
+        'owner_id': current_owner(),
+        'user': session.get('user'),
+        'oauth_enabled': oauth_enabled()
+    })
+
+
+@auth_bp.route('/login', methods=['GET'])
+def login():
+    """Start the OAuth authorization code flow"""
+    if not oauth_enabled():
+        return jsonify({'success': False, 'error': 'OAuth login is not configured'}), 404
+
+    session['oauth_state'] = secrets.token_urlsafe(16)
+    params = urllib.parse.urlencode({
+        'client_id': OAUTH_CLIENT_ID,
+        'redirect_uri': request.url_root.rstrip('/') + '/api/auth/callback',
+        'state': session['oauth_state'],
+        'scope': 'read:user'
+    })
+    return redirect(f'{OAUTH_AUTHORIZE_URL}?{params}')
+
+
+@auth_bp.route('/callback', methods=['GET'])
+def callback():
+    """Finish the OAuth flow and bind the session to the provider account"""
+    if not oauth_enabled():
+        return jsonify({'success': False, 'error': 'OAuth login is not configured'}), 404
+
+    state = session.pop('oauth_state', None)
+    if not state or request.args.get('state') != state:
+        return jsonify({'success': False, 'error': 'Invalid OAuth state'}), 400
+
+    try:
+        token = _http_json(OAUTH_TOKEN_URL, {
+            'client_id': OAUTH_CLIENT_ID,
+            'client_secret': OAUTH_CLIENT_SECRET,
+            'code': request.args.get('code', '')
+        })
+        if 'access_token' not in token:
+            return jsonify({'success': False, 'error': token.get('error_description', 'OAuth login failed')}), 400
+
+        user = _http_json(OAUTH_USER_URL, headers={'Authorization': f"Bearer {token['access_token']}"})
+        session['owner_id'] = f"github:{user['id']}"
+        session['user'] = {'login': user.get('login'), 'name': user.get('name')}
+        return redirect(FRONTEND_URL)
+
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 502
+
+
+@auth_bp.route('/logout', methods=['POST'])
+def logout():
+    """Clear the session; a new anonymous owner is assigned on the next request"""
+    session.clear()
+    return jsonify({'success': True})
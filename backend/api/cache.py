@@ -0,0 +1,106 @@
+import os
+import hmac
+import functools
+from typing import Callable, Dict
+from flask import Blueprint, Response, jsonify, request
+from core.cache import AnalysisCache, LRUCache, RedisCache
+from core.seqio import SequenceRecord
+from .metrics import Counter, Gauge, registry
+from .streaming import wants_ndjson
+
+cache_bp = Blueprint('cache', __name__)
+
+# Bearer token required to clear the cache; unset disables DELETE /api/cache
+ADMIN_TOKEN = os.environ.get('ROBIN_ADMIN_TOKEN', '')
+
+
+def build_cache() -> AnalysisCache:
+    """Cache configured by ROBIN_CACHE: memory (default, ROBIN_CACHE_SIZE entries), redis or off"""
+    backend = os.environ.get('ROBIN_CACHE', 'memory').lower()
+    if backend == 'off':
+        return AnalysisCache()
+    if backend == 'redis':
+        import redis
+        client = redis.Redis.from_url(os.environ.get('ROBIN_CACHE_REDIS_URL', 'redis://localhost:6379/1'),
+                                      decode_responses=True)
+        return AnalysisCache(RedisCache(client, ttl=int(os.environ.get('ROBIN_CACHE_TTL', 3600))))
+    if backend != 'memory':
+        raise ValueError(f'Unknown ROBIN_CACHE backend "{backend}"; use memory, redis or off')
+    return AnalysisCache(LRUCache(int(os.environ.get('ROBIN_CACHE_SIZE', 1024))))
+
+
+analysis_cache = build_cache()
+
+CACHE_LOOKUPS = registry.register(Counter(
+    'oligo_cache_lookups_total', 'Analysis cache lookups by operation and result', ('operation', 'result')))
+registry.register(Gauge('oligo_cache_entries', 'Entries in the in-process analysis cache',
+                        lambda: analysis_cache.stats().get('entries', 0)))
+registry.register(Gauge('oligo_cache_evictions', 'Entries evicted from the in-process analysis cache',
+                        lambda: analysis_cache.stats().get('evictions', 0)))
+
+
+def _record_lookup(operation: str) -> Callable[[bool], None]:
+    return lambda hit: CACHE_LOOKUPS.inc(operation=operation, result='hit' if hit else 'miss')
+
+
+def cached_analysis(operation: str):
+    """Serve an analysis endpoint's successful JSON responses from the cache
+
+    The key is the body's sequence plus every other field as params; uploads, non-JSON and
+    streamed (NDJSON) requests bypass the cache. Only 200 responses are stored, so errors are always recomputed.
+    """
+
+    def decorator(view):
+        @functools.wraps(view)
+        def wrapper(*args, **kwargs):
+            data = request.get_json(silent=True)
+            if not analysis_cache.enabled or request.files or not isinstance(data, dict) or wants_ndjson():
+                return view(*args, **kwargs)
+
+            sequence = str(data.get('sequence', ''))
+            params = {key: value for key, value in data.items() if key != 'sequence'}
+            cached = analysis_cache.get(operation, sequence, params)
+            _record_lookup(operation)(cached is not None)
+            if cached is not None:
+                return jsonify(cached)
+
+            response = view(*args, **kwargs)
+            if isinstance(response, Response) and response.status_code == 200 and response.is_json:
+                analysis_cache.set(operation, sequence, params, response.get_json())
+            return response
+
+        return wrapper
+
+    return decorator
+
+
+def cached_operation(operation: str, func: Callable[[SequenceRecord, Dict], Dict]) -> Callable:
+    """Wrap a per-sequence job operation so results for the same sequence and params are reused"""
+
+    def wrapper(record: SequenceRecord, params: Dict) -> Dict:
+        # FASTQ qualities change results (trim), so they are part of the key
+        quality = getattr(record, 'quality', None)
+        key_params = {**params, '_quality': quality} if quality else params
+        result = analysis_cache.cached(operation, record.sequence, key_params, lambda: func(record, params),
+                                       _record_lookup(operation))
+        # Cached results may come from a record with another name
+        return {**result, 'name': record.name} if 'name' in result else result
+
+    return wrapper
+
+
+@cache_bp.route('/cache', methods=['GET'])
+def cache_stats():
+    """Analysis cache backend, size and hit rate"""
+    return jsonify({'success': True, 'cache': analysis_cache.stats()})
+
+
+@cache_bp.route('/cache', methods=['DELETE'])
+def clear_cache():
+    """Drop every cached analysis result, for every user; needs the admin token"""
+    if not ADMIN_TOKEN:
+        return jsonify({'success': False, 'error': 'Clearing the cache is disabled; set ROBIN_ADMIN_TOKEN'}), 403
+    if not hmac.compare_digest(request.headers.get('Authorization', '').encode(), f'Bearer {ADMIN_TOKEN}'.encode()):
+        return jsonify({'success': False, 'error': 'Clearing the cache needs the admin token'}), 401
+    analysis_cache.clear()
+    return jsonify({'success': True})
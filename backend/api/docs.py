@@ -0,0 +1,82 @@
+import os
+import re
+import json
+from typing import Dict, List, Set, Tuple
+from flask import Blueprint, Flask, jsonify
+
+docs_bp = Blueprint('docs', __name__)
+
+SPEC_PATH = os.path.join(os.path.dirname(__file__), 'openapi.json')
+
+SWAGGER_UI_HTML = """<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="utf-8"/>
+    <title>Oligonucleotide Designer API</title>
+    <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css"/>
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+<script>
+    window.ui = SwaggerUIBundle({url: '/api/openapi.json', dom_id: '#swagger-ui'});
+</script>
+</body>
+</html>
+"""
+
+HTTP_METHODS = {'get', 'post', 'put', 'patch', 'delete'}
+
+
+def load_spec() -> Dict:
+    """Load the OpenAPI document from disk"""
+    with open(SPEC_PATH) as f:
+        return json.load(f)
+
+
+def _spec_operations(spec: Dict) -> Set[Tuple[str, str]]:
+    """(path, METHOD) pairs documented in the spec"""
+    return {
+        (path, method.upper())
+        for path, operations in spec.get('paths', {}).items()
+        for method in operations
+        if method in HTTP_METHODS
+    }
+
+
+def _app_operations(app: Flask) -> Set[Tuple[str, str]]:
+    """(path, METHOD) pairs served by the app, with Flask converters rewritten to OpenAPI templates"""
+    operations = set()
+    for rule in app.url_map.iter_rules():
+        if not rule.rule.startswith('/api/'):
+            continue
+        path = re.sub(r'<(?:[^:<>]+:)?([^<>]+)>', r'{\1}', rule.rule)
+        for method in rule.methods - {'HEAD', 'OPTIONS'}:
+            operations.add((path, method))
+    return operations
+
+
+def spec_drift(app: Flask, spec: Dict = None) -> Dict[str, List[str]]:
+    """Compare the app's routes with the spec
+
+    Returns the operations served but not documented, and documented but not served.
+    """
+    spec = spec or load_spec()
+    served = _app_operations(app)
+    documented = _spec_operations(spec)
+    return {
+        'undocumented': sorted(f"{method} {path}" for path, method in served - documented),
+        'missing': sorted(f"{method} {path}" for path, method in documented - served)
+    }
+
+
+@docs_bp.route('/openapi.json', methods=['GET'])
+def openapi_spec():
+    """Serve the OpenAPI document"""
+    return jsonify(load_spec())
+
+
+@docs_bp.route('/docs', methods=['GET'])
+def swagger_ui():
+    """Serve Swagger UI pointed at the OpenAPI document"""
+    return SWAGGER_UI_HTML, 200, {'Content-Type': 'text/html; charset=utf-8'}
@@ -0,0 +1,451 @@
+import io
+import json
+import os
+import time
+from dataclasses import asdict
+from typing import Callable, Dict, List
+from flask import Blueprint, Response, request, jsonify, stream_with_context
+from core.jobs import JobQueue, QueueFullError, current_job, job_progress, report_progress
+from core.seqio import FastqRecord, SequenceRecord, format_fasta, format_fastq, read_fastq, read_sequences
+from core.sequence import clean_sequence, translate, find_orfs
+from core.align import global_align, local_align, semiglobal_align, ScoringScheme
+from core.trimming import TrimSettings, trim_read
+from core.protein import Protein
+from core.parallel import gc_content, parallel_reverse_complement
+from core.blast import RemoteBlast, blast
+from core.seqdesign import DefectDesigner, parse_design_spec
+from core.fold import partition_function, structure_energy
+from core.assembly import assemble_de_bruijn, assemble_olc, assembly_stats
+from core.sweep import ParameterSweep, run_task
+from core.manifest import new_seed
+from core.registry import registry
+from core.pipeline import Pipeline, parse_pipeline, sequence_artifact
+from .metrics import timed_operation, register_job_queue_metrics
+from .auth import current_owner
+from .cache import cached_operation
+from .streaming import ndjson_response, wants_ndjson
+
+jobs_bp = Blueprint('jobs', __name__)
+
+
+def _record(data: Dict):
+    return FastqRecord(data['name'], data['sequence'], data['quality']) if 'quality' in data \
+        else SequenceRecord(data['name'], data['sequence'])
+
+
+def encode_job_items(operation: str, items: List) -> List:
+    """Job items as JSON: sweep tasks and pipeline inputs already are, assemble has one list of reads"""
+    if operation in ('sweep', 'pipeline'):
+        return items
+    if operation == 'assemble':
+        return [[asdict(record) for record in reads] for reads in items]
+    return [asdict(record) for record in items]
+
+
+def decode_job_items(operation: str, values: List) -> List:
+    """Inverse of encode_job_items"""
+    if operation in ('sweep', 'pipeline'):
+        return values
+    if operation == 'assemble':
+        return [[_record(data) for data in reads] for reads in values]
+    return [_record(data) for data in values]
+
+
+# With ROBIN_JOB_DIR set, jobs are checkpointed there and survive restarts once restore_jobs() has run
+job_queue = JobQueue(max_workers=4, max_pending=32, checkpoint_dir=os.environ.get('ROBIN_JOB_DIR') or None,
+                     encode_items=encode_job_items, decode_items=decode_job_items)
+register_job_queue_metrics(job_queue)
+
+MAX_JOB_SEQUENCES = 10000
+EVENT_POLL_SECONDS = 1.0
+
+# One client for every job so NCBI's one-request-per-10-seconds limit holds across workers
+remote_blast = RemoteBlast(email=os.environ.get('NCBI_EMAIL'))
+
+
+# gc and revcomp split long sequences (chromosomes, assemblies) into chunks processed in parallel
+def _op_gc(record: SequenceRecord, params: Dict) -> Dict:
+    return {'name': record.name, 'length': len(record.sequence),
+            'gc_content': round(gc_content(record.sequence), 2)}
+
+
+def _op_revcomp(record: SequenceRecord, params: Dict) -> Dict:
+    return {'name': record.name, 'sequence': parallel_reverse_complement(record.sequence)}
+
+
+def _op_translate(record: SequenceRecord, params: Dict) -> Dict:
+    return {'name': record.name,
+            'protein': translate(record.sequence, frame=int(params.get('frame', 0)),
+                                 to_stop=bool(params.get('to_stop', False)))}
+
+
+def _op_protein(record: SequenceRecord, params: Dict) -> Dict:
+    # Translated from DNA unless the record is already a protein sequence
+    if params.get('protein'):
+        protein = Protein(record.sequence, name=record.name)
+    else:
+        protein = Protein(translate(record.sequence, frame=int(params.get('frame', 0)),
+                                    to_stop=bool(params.get('to_stop', True))), name=record.name)
+    return {**protein.properties(), 'protein': protein.sequence}
+
+
+def _op_orfs(record: SequenceRecord, params: Dict) -> Dict:
+    return {'name': record.name, 'orfs': find_orfs(record.sequence, min_length=int(params.get('min_length', 30)))}
+
+
+def _op_align(record: SequenceRecord, params: Dict) -> Dict:
+    scoring = ScoringScheme(**params.get('scoring', {}))
+    if params.get('free_ends') and not params.get('local'):
+        # 'a' lets reads align within a longer reference without paying for its overhangs
+        alignment = semiglobal_align(params['reference'], record.sequence, scoring, params['free_ends'],
+                                     job_progress(name=record.name))
+    else:
+        align = local_align if params.get('local') else global_align
+        alignment = align(params['reference'], record.sequence, scoring, job_progress(name=record.name))
+    return {
+        'name': record.name,
+        'score': alignment.score,
+        'identity': round(alignment.identity, 2),
+        'aligned_reference': alignment.aligned_a,
+        'aligned_query': alignment.aligned_b,
+        'reference_start': alignment.start_a,
+        'reference_end': alignment.end_a
+    }
+
+
+def _op_trim(record: SequenceRecord, params: Dict) -> Dict:
+    # FASTA input has no qualities, so only adapter trimming and length filtering apply
+    quality = getattr(record, 'quality', None) or 'I' * len(record.sequence)
+    adapters = params.get('adapters', ())
+    if isinstance(adapters, str):
+        adapters = [adapter.strip() for adapter in adapters.split(',') if adapter.strip()]
+    settings = TrimSettings(
+        adapters=tuple(adapters),
+        window=int(params.get('window', 4)),
+        min_window_quality=float(params.get('min_quality', 20)),
+        min_length=int(params.get('min_length', 30)),
+        max_length=int(params['max_length']) if params.get('max_length') else None
+    )
+    trimmed, details = trim_read(FastqRecord(record.name, record.sequence, quality), settings)
+    if trimmed:
+        details.update(sequence=trimmed.sequence, quality=trimmed.quality)
+    return details
+
+
+def _op_blast(record: SequenceRecord, params: Dict) -> Dict:
+    # Local blast+ against ROBIN_BLAST_DB when installed, otherwise NCBI's servers; remote searches take minutes
+    hits = blast(record, program=params.get('program', 'blastn'), database=params.get('database', 'nt'),
+                 expect=float(params.get('expect', 10)), hitlist_size=int(params.get('hitlist_size', 50)),
+                 remote=remote_blast)
+    return {'name': record.name, 'length': len(record.sequence), 'hits': [hit.to_dict() for hit in hits]}
+
+
+def _op_design(record: SequenceRecord, params: Dict) -> Dict:
+    # The record is a template for the target: N where the designer may choose, fixed bases elsewhere.
+    # An all-N record leaves the length to the target, so a spec alone can drive the design.
+    seed = params.get('seed')
+    spec = parse_design_spec(str(params.get('spec', '')))
+    template = '' if set(record.sequence.upper()) <= {'N'} else record.sequence
+    designer = DefectDesigner(str(params.get('target', '')), template, float(params.get('temperature', 37.0)),
+                              int(seed) if seed is not None else None, spec)
+
+    def progress(iteration, score):
+        report_progress(name=record.name, iteration=iteration, objective=round(score.total, 4),
+                        objectives={name: round(value, 4) for name, value in score.objectives.items()})
+
+    design = designer.run(float(params.get('stop', 0.02)), int(params.get('max_iterations', 500)), progress=progress)
+    return dict(design.to_dict(), name=record.name)
+
+
+def _op_fold(record: SequenceRecord, params: Dict) -> Dict:
+    temperature = float(params.get('temperature', 37.0))
+    ensemble = partition_function(record.sequence, temperature, str(params.get('constraint', '')) or None,
+                                  job_progress(name=record.name))
+    centroid = ensemble.centroid()
+    return {'name': record.name, **ensemble.to_dict(float(params.get('threshold', 0.01))),
+            'centroid_dg': round(structure_energy(ensemble.sequence, centroid, temperature), 2)}
+
+
+def _op_assemble(records: List[SequenceRecord], params: Dict) -> Dict:
+    # The job's one item is every read, so a checkpoint holds the reads until the assembly finishes
+    reads = [record.sequence for record in records]
+    min_contig_length = int(params.get('min_contig_length', 0))
+    algorithm = params.get('algo', 'dbg')
+    if algorithm == 'dbg':
+        contigs = assemble_de_bruijn(reads, k=int(params.get('k', 31)), min_count=int(params.get('min_count', 2)),
+                                     min_contig_length=min_contig_length, progress=job_progress())
+    elif algorithm == 'olc':
+        contigs = [contig for contig in assemble_olc(reads, min_overlap=int(params.get('min_overlap', 20)),
+                                                     min_identity=float(params.get('min_identity', 90.0)),
+                                                     progress=job_progress())
+                   if len(contig) >= min_contig_length]
+    else:
+        raise ValueError(f'Unknown assembly algorithm "{algorithm}"; use dbg or olc')
+    return {'stats': assembly_stats(contigs),
+            'contigs': [{'name': contig.name, 'sequence': contig.sequence} for contig in contigs]}
+
+
+def _op_sweep(task: Dict, params: Dict) -> Dict:
+    # Items are the sweep's grid points, so an interrupted sweep picks up at the first point it had not simulated
+    return run_task(task)
+
+
+def _workspace_store():
+    # Imported when first needed, since the workspace blueprint imports this module
+    from .workspace import store
+    return store
+
+
+def _pipeline(params: Dict) -> Pipeline:
+    spec = params.get('pipeline')
+    return parse_pipeline(spec) if isinstance(spec, str) else Pipeline.from_dict(spec)
+
+
+def _op_pipeline(item: Dict, params: Dict) -> Dict:
+    # The job's one item holds the inputs' text; every step's output goes to the owner's workspace as soon as it
+    # exists, so a failed pipeline still leaves what it got through (a resumed one starts again from the top)
+    pipeline = _pipeline(params)
+    job = current_job()
+    owner_id, job_id = (job.owner_id, job.id) if job else ('', '')
+    store = _workspace_store()
+    steps = []
+
+    def save(step, artifact):
+        stored = store.add_artifact(owner_id, f'{pipeline.name}/{step.id}', artifact.format, artifact.text,
+                                    pipeline.name, job_id, artifact.summary)
+        steps.append({'id': step.id, 'analysis': step.analysis, 'format': artifact.format,
+                      'artifact_id': stored.id, 'summary': artifact.summary})
+        report_progress(step=step.id, steps_done=len(steps), steps=len(pipeline.steps))
+
+    pipeline.run({name: sequence_artifact(name, text) for name, text in item['inputs'].items()}, save)
+    return {'pipeline': pipeline.name, 'steps': steps}
+
+
+def _pipeline_item(records: List[SequenceRecord], params: Dict) -> Dict:
+    """Text of every pipeline input, read while the request is at hand
+
+    params.inputs gives an input as FASTA/FASTQ text, {sequence_id(s)} of saved sequences or {artifact_id} of a
+    stored FASTA/FASTQ artifact; the sequences sent with the job fill the first input it leaves out.
+    """
+    pipeline = _pipeline(params)
+    sources = params.get('inputs') or {}
+    store, owner_id = _workspace_store(), current_owner()
+    inputs = {}
+    for name in pipeline.inputs:
+        source = sources.get(name)
+        if source is None and records:
+            source, records = records, []
+            inputs[name] = format_fastq(source) if isinstance(source[0], FastqRecord) else format_fasta(source)
+        elif isinstance(source, str):
+            inputs[name] = source
+        elif isinstance(source, dict) and source.get('artifact_id'):
+            artifact = store.get_artifact(owner_id, str(source['artifact_id']))
+            if artifact is None or artifact.format not in ('fasta', 'fastq'):
+                raise ValueError(f'Input "{name}": no FASTA or FASTQ artifact "{source["artifact_id"]}"')
+            inputs[name] = artifact.content
+        elif isinstance(source, dict) and (source.get('sequence_id') or source.get('sequence_ids')):
+            saved = [store.get(owner_id, str(sequence_id))
+                     for sequence_id in source.get('sequence_ids') or [source['sequence_id']]]
+            if not all(saved):
+                raise ValueError(f'Input "{name}": saved sequence not found')
+            inputs[name] = format_fasta([SequenceRecord(item.name, item.sequence) for item in saved])
+        else:
+            raise ValueError(f'Pipeline input "{name}" is missing')
+    return {'inputs': inputs}
+
+
+# Operation name -> per-item function(item, params); items are sequences except for GROUP_OPERATIONS
+OPERATIONS = {
+    'gc': _op_gc,
+    'revcomp': _op_revcomp,
+    'translate': _op_translate,
+    'orfs': _op_orfs,
+    'protein': _op_protein,
+    'align': _op_align,
+    'trim': _op_trim,
+    'blast': _op_blast,
+    'design': _op_design,
+    'fold': _op_fold,
+    'assemble': _op_assemble,
+    'sweep': _op_sweep,
+    'pipeline': _op_pipeline
+}
+
+# Analysis plugins are job operations too, under their own names; built-in operations win a clash
+for analysis in registry.discover():
+    OPERATIONS.setdefault(analysis.name, analysis)
+
+# Operations whose items are built from the request: assemble takes all the reads as one item, sweep takes
+# no sequences and runs one item per grid point of the sweep described by its params, and pipeline runs its
+# steps over its inputs as one item
+GROUP_OPERATIONS = {
+    'assemble': lambda records, params: [records],
+    'sweep': lambda records, params: ParameterSweep.from_dict(params).tasks(),
+    'pipeline': lambda records, params: [_pipeline_item(records, params)]
+}
+
+
+def seeded_params(operation: str, params: Dict) -> Dict:
+    """params with a fresh seed when the operation draws random numbers and was not given one
+
+    Fixing the seed up front lets a resumed job, a rerun or a stored manifest repeat the same draws.
+    """
+    stochastic = operation == 'design' or (operation == 'sweep' and params.get('method') == 'ssa')
+    if stochastic and params.get('seed') is None:
+        return {**params, 'seed': new_seed()}
+    return params
+
+
+def job_function(operation: str) -> Callable:
+    """Timed operation, with results cached per sequence for operations over sequences"""
+    func = OPERATIONS[operation]
+    if operation not in GROUP_OPERATIONS:
+        func = cached_operation(operation, func)
+    return timed_operation(operation, func)
+
+
+def restore_jobs() -> List:
+    """Bring back checkpointed jobs and resume interrupted ones; call once, from the serving process"""
+    return job_queue.restore({operation: job_function(operation) for operation in OPERATIONS})
+
+
+def _parse_text(text: str) -> List[SequenceRecord]:
+    """FASTQ (detected by a leading '@') or FASTA/raw records"""
+    if text.lstrip().startswith('@'):
+        return list(read_fastq(io.StringIO(text.lstrip())))
+    return list(read_sequences(io.StringIO(text)))
+
+
+def parse_job_records() -> List[SequenceRecord]:
+    """Collect sequences from an uploaded file, a FASTA/FASTQ string or a JSON sequence list"""
+    if 'file' in request.files:
+        return _parse_text(request.files['file'].read().decode('utf-8'))
+
+    data = request.get_json(silent=True) or {}
+    if data.get('fasta'):
+        return _parse_text(data['fasta'])
+    if data.get('fastq'):
+        return _parse_text(data['fastq'])
+
+    records = []
+    for i, item in enumerate(data.get('sequences', [])):
+        if isinstance(item, dict):
+            records.append(SequenceRecord(item.get('name') or f'seq{i + 1}', clean_sequence(item.get('sequence', ''))))
+        else:
+            records.append(SequenceRecord(f'seq{i + 1}', clean_sequence(str(item))))
+    return records
+
+
+@jobs_bp.route('/jobs', methods=['POST'])
+def create_job():
+    """Queue a batch job over many sequences"""
+    try:
+        if request.files:
+            operation = request.form.get('operation', '')
+            params = {key: value for key, value in request.form.items() if key != 'operation'}
+        else:
+            data = request.get_json(silent=True) or {}
+            operation = data.get('operation', '')
+            params = data.get('params', {})
+
+        if operation not in OPERATIONS:
+            return jsonify({'success': False,
+                            'error': f'Unknown operation "{operation}". Available: {sorted(OPERATIONS)}'}), 400
+
+        params = seeded_params(operation, params)
+        records = parse_job_records() if operation != 'sweep' else []
+        if not records and operation not in ('sweep', 'pipeline'):
+            return jsonify({'success': False, 'error': 'No sequences provided'}), 400
+        if len(records) > MAX_JOB_SEQUENCES:
+            return jsonify({'success': False,
+                            'error': f'Too many sequences ({len(records)}), maximum is {MAX_JOB_SEQUENCES}'}), 400
+        if operation == 'align' and not params.get('reference'):
+            return jsonify({'success': False, 'error': 'align requires a reference sequence in params'}), 400
+        if operation == 'design':
+            try:
+                spec = parse_design_spec(str(params.get('spec', '')))
+            except ValueError as e:
+                return jsonify({'success': False, 'error': f'Invalid design spec: {e}'}), 400
+            if not params.get('target') and not spec.target:
+                return jsonify({'success': False, 'error': 'design requires a target structure in params or the spec'}), 400
+
+        try:
+            items = GROUP_OPERATIONS[operation](records, params) if operation in GROUP_OPERATIONS else records
+        except (ValueError, TypeError) as e:
+            return jsonify({'success': False, 'error': f'Invalid {operation} params: {e}'}), 400
+
+        job = job_queue.submit(operation, items, job_function(operation), params, owner_id=current_owner())
+        return jsonify({'success': True, 'job': job.to_dict(include_results=False)}), 202
+
+    except QueueFullError as e:
+        return jsonify({'success': False, 'error': str(e)}), 503
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@jobs_bp.route('/jobs', methods=['GET'])
+def list_jobs():
+    """List the session's retained jobs without their results"""
+    return jsonify({'success': True, 'jobs': [job.to_dict(include_results=False) for job in job_queue.list(current_owner())]})
+
+
+@jobs_bp.route('/jobs/<job_id>', methods=['GET'])
+def get_job(job_id):
+    """Poll a job for progress and results; NDJSON streams the job, then one result per line"""
+    job = job_queue.get(job_id, current_owner())
+    if not job:
+        return jsonify({'success': False, 'error': f'Job "{job_id}" not found'}), 404
+    if wants_ndjson():
+        return ndjson_response({'success': True, 'job': job.to_dict(include_results=False)}, list(job.results))
+    return jsonify({'success': True, 'job': job.to_dict()})
+
+
+@jobs_bp.route('/jobs/<job_id>/cancel', methods=['POST'])
+def cancel_job(job_id):
+    """Stop a queued or running job, keeping the results it has so far"""
+    job = job_queue.get(job_id, current_owner())
+    if not job:
+        return jsonify({'success': False, 'error': f'Job "{job_id}" not found'}), 404
+    try:
+        job_queue.cancel(job.id)
+    except ValueError as e:
+        return jsonify({'success': False, 'error': str(e)}), 409
+    return jsonify({'success': True, 'job': job.to_dict(include_results=False)})
+
+
+@jobs_bp.route('/jobs/<job_id>/resume', methods=['POST'])
+def resume_job(job_id):
+    """Continue a failed, cancelled or interrupted job from its first item without a result"""
+    job = job_queue.get(job_id, current_owner())
+    if not job:
+        return jsonify({'success': False, 'error': f'Job "{job_id}" not found'}), 404
+    try:
+        job_queue.resume(job.id, job_function(job.operation))
+    except ValueError as e:
+        return jsonify({'success': False, 'error': str(e)}), 409
+    except QueueFullError as e:
+        return jsonify({'success': False, 'error': str(e)}), 503
+    return jsonify({'success': True, 'job': job.to_dict(include_results=False)}), 202
+
+
+@jobs_bp.route('/jobs/<job_id>/events', methods=['GET'])
+def job_events(job_id):
+    """Server-sent events with the job's progress whenever it changes, ending with its results"""
+    job = job_queue.get(job_id, current_owner())
+    if not job:
+        return jsonify({'success': False, 'error': f'Job "{job_id}" not found'}), 404
+
+    def events():
+        last = None
+        while True:
+            finished = job.status in ('completed', 'failed', 'cancelled', 'interrupted')
+            state = job.to_dict(include_results=finished)
+            if state != last:
+                yield f'data: {json.dumps(state)}\n\n'
+                last = state
+            if finished:
+                return
+            time.sleep(EVENT_POLL_SECONDS)
+
+    return Response(stream_with_context(events()), mimetype='text/event-stream',
+                    headers={'Cache-Control': 'no-cache', 'X-Accel-Buffering': 'no'})
@@ -0,0 +1,151 @@
+import functools
+import os
+import threading
+import time
+from typing import Any, Dict, Optional, Tuple
+from flask import Flask, request, jsonify
+from werkzeug.middleware.proxy_fix import ProxyFix
+from core.context import Cancelled, Context, using
+
+# Defaults sized for a public deployment
+DEFAULT_MAX_BODY_BYTES = 10 * 1024 * 1024  # 10 MB
+DEFAULT_MAX_SEQUENCE_LENGTH = 1_000_000  # nt per sequence field
+DEFAULT_RATE_LIMIT = 60  # requests per window per client IP
+DEFAULT_RATE_WINDOW = 60.0  # seconds
+# Reverse proxies in front of the app whose X-Forwarded-For is trusted; 0 (the default) uses the socket address
+DEFAULT_TRUSTED_PROXIES = int(os.environ.get('ROBIN_TRUSTED_PROXIES', 0))
+# Seconds a request may compute (streamed responses included) before its work is cancelled; 0 never cancels.
+# Batch jobs run outside requests and are not limited.
+DEFAULT_REQUEST_TIMEOUT = float(os.environ.get('ROBIN_REQUEST_TIMEOUT', 120))
+
+# JSON keys whose string values are sequence payloads
+SEQUENCE_KEYS = ('sequence', 'sequences', 'fasta', 'reference', 'template')
+
+
+class RateLimiter:
+    """Per-client token bucket rate limiter"""
+
+    def __init__(self, limit: int = DEFAULT_RATE_LIMIT, window: float = DEFAULT_RATE_WINDOW):
+        self.limit = limit
+        self.window = window
+        self.buckets: Dict[str, Tuple[float, float]] = {}  # client -> (tokens, last refill time)
+        self.lock = threading.Lock()
+        self.last_sweep = time.monotonic()
+
+    def allow(self, client: str) -> Tuple[bool, float]:
+        """Consume a token for client; returns (allowed, seconds until a token is available)"""
+        now = time.monotonic()
+        refill_rate = self.limit / self.window
+
+        with self.lock:
+            if now - self.last_sweep >= self.window:
+                self._evict_idle(now)
+            tokens, last = self.buckets.get(client, (float(self.limit), now))
+            tokens = min(float(self.limit), tokens + (now - last) * refill_rate)
+            if tokens >= 1.0:
+                self.buckets[client] = (tokens - 1.0, now)
+                return True, 0.0
+            self.buckets[client] = (tokens, now)
+            return False, (1.0 - tokens) / refill_rate
+
+    def _evict_idle(self, now: float):
+        """Drop buckets idle for a full window: they have refilled, so a fresh bucket is the same"""
+        self.buckets = {client: (tokens, last) for client, (tokens, last) in self.buckets.items()
+                        if now - last < self.window}
+        self.last_sweep = now
+
+
+def find_oversized_sequence(data: Any, max_length: int, key: Optional[str] = None) -> Optional[Tuple[str, int]]:
+    """Find the first sequence field in a JSON payload longer than max_length"""
+    if isinstance(data, dict):
+        for child_key, value in data.items():
+            found = find_oversized_sequence(value, max_length, child_key)
+            if found:
+                return found
+    elif isinstance(data, list):
+        for value in data:
+            found = find_oversized_sequence(value, max_length, key)
+            if found:
+                return found
+    elif isinstance(data, str) and key in SEQUENCE_KEYS and len(data) > max_length:
+        return key, len(data)
+    return None
+
+
+def init_limits(app: Flask, max_body_bytes: int = DEFAULT_MAX_BODY_BYTES,
+                max_sequence_length: int = DEFAULT_MAX_SEQUENCE_LENGTH,
+                rate_limit: int = DEFAULT_RATE_LIMIT, rate_window: float = DEFAULT_RATE_WINDOW,
+                trusted_proxies: int = DEFAULT_TRUSTED_PROXIES) -> RateLimiter:
+    """Install request size caps and per-IP rate limiting on an app
+
+    Clients are told apart by the socket address. X-Forwarded-For is client-controlled, so it is
+    only read when the app runs behind trusted_proxies reverse proxies, each appending one entry.
+    """
+    app.config['MAX_CONTENT_LENGTH'] = max_body_bytes
+    if trusted_proxies:
+        app.wsgi_app = ProxyFix(app.wsgi_app, x_for=trusted_proxies)
+    limiter = RateLimiter(rate_limit, rate_window)
+
+    @app.before_request
+    def enforce_limits():
+        if request.method == 'OPTIONS':
+            return None
+
+        client = request.remote_addr or 'unknown'
+        allowed, retry_after = limiter.allow(client)
+        if not allowed:
+            response = jsonify({
+                'success': False,
+                'error': f'Rate limit exceeded: {rate_limit} requests per {rate_window:g} seconds. '
+                         f'Retry in {retry_after:.0f} seconds.'
+            })
+            response.headers['Retry-After'] = str(max(1, round(retry_after)))
+            return response, 429
+
+        if request.is_json:
+            oversized = find_oversized_sequence(request.get_json(silent=True), max_sequence_length)
+            if oversized:
+                field, length = oversized
+                return jsonify({
+                    'success': False,
+                    'error': f'Sequence in "{field}" is {length} characters; maximum is {max_sequence_length}'
+                }), 413
+        return None
+
+    @app.errorhandler(413)
+    def request_too_large(e):
+        return jsonify({
+            'success': False,
+            'error': f'Request body too large; maximum is {max_body_bytes // (1024 * 1024)} MB'
+        }), 413
+
+    return limiter
+
+
+def init_deadlines(app: Flask, timeout: Optional[float] = None):
+    """Run every view under a context that is cancelled after timeout seconds
+
+    The timeout defaults to app.config['REQUEST_TIMEOUT'], itself defaulting to
+    $ROBIN_REQUEST_TIMEOUT or 120 seconds. Alignment, folding, assembly, enumeration and
+    simulation stop at their next cancellation check and the request gets a 503, instead of the
+    work running on after the client has given up. Streamed responses keep the same context
+    (see streaming.ndjson_response). Call it once every route is registered, since it wraps the
+    view functions.
+    """
+    if timeout is None:
+        timeout = float(app.config.setdefault('REQUEST_TIMEOUT', DEFAULT_REQUEST_TIMEOUT))
+
+    def cancellable(view):
+        @functools.wraps(view)
+        def wrapper(*args, **kwargs):
+            try:
+                with using(Context(timeout=timeout or None)):
+                    return view(*args, **kwargs)
+            except Cancelled:
+                return jsonify({'success': False,
+                                'error': f'Request took longer than {timeout:g} seconds and was stopped'}), 503
+
+        return wrapper
+
+    for endpoint, view in list(app.view_functions.items()):
+        app.view_functions[endpoint] = cancellable(view)
@@ -0,0 +1,176 @@
+import time
+import threading
+from typing import Callable, Dict, List, Sequence, Tuple
+from flask import Blueprint, Flask, Response, g, request
+from .request_logging import sequence_size
+
+metrics_bp = Blueprint('metrics', __name__)
+
+LATENCY_BUCKETS = (0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0, 30.0, 60.0)
+LENGTH_BUCKETS = (10, 25, 50, 100, 250, 500, 1000, 5000, 10000, 100000, 1000000)
+INF_LABEL = 'le="+Inf"'
+
+
+def _format_labels(labelnames: Sequence[str], values: Tuple, extra: str = '') -> str:
+    """Render a Prometheus label set"""
+    parts = [f'{name}="{str(value)}"' for name, value in zip(labelnames, values)]
+    if extra:
+        parts.append(extra)
+    return '{' + ','.join(parts) + '}' if parts else ''
+
+
+class Counter:
+    """Monotonically increasing counter with labels"""
+
+    def __init__(self, name: str, documentation: str, labelnames: Sequence[str] = ()):
+        self.name = name
+        self.documentation = documentation
+        self.labelnames = tuple(labelnames)
+        self.values: Dict[Tuple, float] = {}
+        self.lock = threading.Lock()
+
+    def inc(self, amount: float = 1.0, **labels):
+        key = tuple(labels.get(name, '') for name in self.labelnames)
+        with self.lock:
+            self.values[key] = self.values.get(key, 0.0) + amount
+
+    def render(self) -> List[str]:
+        lines = [f'# HELP {self.name} {self.documentation}', f'# TYPE {self.name} counter']
+        with self.lock:
+            for key, value in sorted(self.values.items()):
+                lines.append(f'{self.name}{_format_labels(self.labelnames, key)} {value:g}')
+        return lines
+
+
+class Histogram:
+    """Cumulative histogram with labels"""
+
+    def __init__(self, name: str, documentation: str, labelnames: Sequence[str] = (),
+                 buckets: Sequence[float] = LATENCY_BUCKETS):
+        self.name = name
+        self.documentation = documentation
+        self.labelnames = tuple(labelnames)
+        self.buckets = tuple(buckets)
+        self.values: Dict[Tuple, Tuple[List[int], float, int]] = {}  # labels -> (bucket counts, sum, count)
+        self.lock = threading.Lock()
+
+    def observe(self, value: float, **labels):
+        key = tuple(labels.get(name, '') for name in self.labelnames)
+        with self.lock:
+            counts, total, count = self.values.get(key, ([0] * len(self.buckets), 0.0, 0))
+            for i, bound in enumerate(self.buckets):
+                if value <= bound:
+                    counts[i] += 1
+            self.values[key] = (counts, total + value, count + 1)
+
+    def render(self) -> List[str]:
+        lines = [f'# HELP {self.name} {self.documentation}', f'# TYPE {self.name} histogram']
+        with self.lock:
+            for key, (counts, total, count) in sorted(self.values.items()):
+                for bound, bucket_count in zip(self.buckets, counts):
+                    labels = _format_labels(self.labelnames, key, f'le="{bound:g}"')
+                    lines.append(f'{self.name}_bucket{labels} {bucket_count}')
+                lines.append(f'{self.name}_bucket{_format_labels(self.labelnames, key, INF_LABEL)} {count}')
+                lines.append(f'{self.name}_sum{_format_labels(self.labelnames, key)} {total:g}')
+                lines.append(f'{self.name}_count{_format_labels(self.labelnames, key)} {count}')
+        return lines
+
+
+class Gauge:
+    """Gauge whose value is read from a callback at scrape time"""
+
+    def __init__(self, name: str, documentation: str, callback: Callable[[], float]):
+        self.name = name
+        self.documentation = documentation
+        self.callback = callback
+
+    def render(self) -> List[str]:
+        return [f'# HELP {self.name} {self.documentation}', f'# TYPE {self.name} gauge',
+                f'{self.name} {self.callback():g}']
+
+
+class MetricsRegistry:
+    """Collection of metrics rendered in the Prometheus text format"""
+
+    def __init__(self):
+        self.metrics = []
+
+    def register(self, metric):
+        self.metrics.append(metric)
+        return metric
+
+    def render(self) -> str:
+        lines = []
+        for metric in self.metrics:
+            lines.extend(metric.render())
+        return '\n'.join(lines) + '\n'
+
+
+registry = MetricsRegistry()
+
+REQUESTS = registry.register(Counter(
+    'oligo_http_requests_total', 'HTTP requests by method, route and status', ('method', 'route', 'status')))
+REQUEST_LATENCY = registry.register(Histogram(
+    'oligo_http_request_duration_seconds', 'HTTP request latency by route', ('route',)))
+SEQUENCE_LENGTH = registry.register(Histogram(
+    'oligo_request_sequence_length', 'Total sequence characters per request by route', ('route',), LENGTH_BUCKETS))
+OPERATION_DURATION = registry.register(Histogram(
+    'oligo_operation_duration_seconds', 'Processing time per sequence by operation', ('operation',)))
+OPERATIONS_TOTAL = registry.register(Counter(
+    'oligo_operations_total', 'Sequences processed by operation and outcome', ('operation', 'outcome')))
+
+
+def timed_operation(operation: str, func: Callable) -> Callable:
+    """Wrap a per-item operation so its duration and outcome are recorded"""
+
+    def wrapper(*args, **kwargs):
+        start = time.perf_counter()
+        try:
+            result = func(*args, **kwargs)
+        except Exception:
+            OPERATIONS_TOTAL.inc(operation=operation, outcome='error')
+            raise
+        OPERATION_DURATION.observe(time.perf_counter() - start, operation=operation)
+        OPERATIONS_TOTAL.inc(operation=operation, outcome='ok')
+        return result
+
+    return wrapper
+
+
+def register_job_queue_metrics(job_queue):
+    """Expose worker pool utilization for a JobQueue"""
+    registry.register(Gauge('oligo_job_workers', 'Size of the job worker pool',
+                            lambda: job_queue.max_workers))
+    registry.register(Gauge('oligo_job_workers_busy', 'Workers currently running a job',
+                            lambda: job_queue.stats()['running']))
+    registry.register(Gauge('oligo_jobs_queued', 'Jobs waiting for a worker',
+                            lambda: job_queue.stats()['queued']))
+    registry.register(Gauge('oligo_job_worker_utilization', 'Fraction of workers busy',
+                            lambda: job_queue.stats()['running'] / job_queue.max_workers))
+
+
+def init_metrics(app: Flask):
+    """Record request counts, latency and sequence sizes for every request"""
+
+    @app.before_request
+    def start_timer():
+        g.metrics_start = time.perf_counter()
+
+    @app.after_request
+    def record_request(response):
+        route = request.url_rule.rule if request.url_rule else 'unmatched'
+        if route != '/metrics':
+            elapsed = time.perf_counter() - g.get('metrics_start', time.perf_counter())
+            REQUESTS.inc(method=request.method, route=route, status=response.status_code)
+            REQUEST_LATENCY.observe(elapsed, route=route)
+            if request.is_json:
+                size = sequence_size(request.get_json(silent=True))
+                if size:
+                    SEQUENCE_LENGTH.observe(size, route=route)
+        return response
+
+
+@metrics_bp.route('/metrics', methods=['GET'])
+def metrics():
+    """Prometheus scrape endpoint"""
+    return Response(registry.render(), mimetype='text/plain; version=0.0.4')
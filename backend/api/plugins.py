@@ -0,0 +1,50 @@
+from flask import Blueprint, request, jsonify
+from core.registry import registry
+from core.seqio import SequenceRecord
+from core.sequence import clean_sequence
+from .jobs import parse_job_records
+from .cache import cached_operation
+from .streaming import ndjson_response, wants_ndjson
+
+plugins_bp = Blueprint('plugins', __name__)
+registry.discover()
+
+MAX_PLUGIN_SEQUENCES = 100
+
+
+@plugins_bp.route('/plugins', methods=['GET'])
+def list_plugins():
+    """Registered analysis plugins with their parameter schemas, for building forms"""
+    return jsonify({'success': True, 'analyses': [analysis.to_dict() for analysis in registry]})
+
+
+@plugins_bp.route('/plugins/<name>', methods=['POST'])
+def run_plugin(name):
+    """Run an analysis plugin on each given sequence"""
+    try:
+        analysis = registry.get(name)
+        if analysis is None:
+            return jsonify({'success': False,
+                            'error': f'Unknown analysis "{name}". Available: {[item.name for item in registry]}'}), 404
+
+        data = request.get_json(silent=True) or {}
+        records = parse_job_records()
+        if not records and data.get('sequence'):
+            records = [SequenceRecord(data.get('name') or 'seq1', clean_sequence(data['sequence']))]
+        if not records:
+            return jsonify({'success': False, 'error': 'No sequences provided'}), 400
+        if len(records) > MAX_PLUGIN_SEQUENCES:
+            return jsonify({'success': False, 'error': f'Too many sequences ({len(records)}), maximum is '
+                                                       f'{MAX_PLUGIN_SEQUENCES}; queue a batch job instead'}), 400
+
+        params = analysis.parse_params(data.get('params'))
+        func = cached_operation(name, analysis)
+        head = {'success': True, 'analysis': name, 'params': params}
+        if wants_ndjson():
+            return ndjson_response(head, (func(record, params) for record in records))
+        return jsonify({**head, 'results': [func(record, params) for record in records]})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
@@ -0,0 +1,102 @@
+import os
+import json
+import time
+import uuid
+import logging
+from datetime import datetime, timezone
+from typing import Any
+from flask import Flask, g, request
+from .limits import SEQUENCE_KEYS
+
+logger = logging.getLogger('oligo_designer')
+
+# Attributes every LogRecord has; anything else was passed through `extra`
+_STANDARD_RECORD_FIELDS = set(vars(logging.LogRecord('', 0, '', 0, '', None, None))) | {'message', 'asctime'}
+
+
+class JsonFormatter(logging.Formatter):
+    """Format log records as one JSON object per line"""
+
+    def format(self, record: logging.LogRecord) -> str:
+        entry = {
+            'time': datetime.fromtimestamp(record.created, tz=timezone.utc).isoformat(),
+            'level': record.levelname,
+            'logger': record.name,
+            'message': record.getMessage()
+        }
+        for key, value in vars(record).items():
+            if key not in _STANDARD_RECORD_FIELDS:
+                entry[key] = value
+        if record.exc_info:
+            entry['exception'] = self.formatException(record.exc_info)
+        return json.dumps(entry, default=str)
+
+
+def configure_logging(level: str = None):
+    """Send JSON logs to stderr at the level given by LOG_LEVEL (default INFO)"""
+    handler = logging.StreamHandler()
+    handler.setFormatter(JsonFormatter())
+    root = logging.getLogger()
+    root.handlers = [handler]
+    root.setLevel(level or os.environ.get('LOG_LEVEL', 'INFO'))
+
+
+def sequence_size(data: Any, key: str = None) -> int:
+    """Total number of characters in sequence fields of a JSON payload"""
+    if isinstance(data, dict):
+        return sum(sequence_size(value, child_key) for child_key, value in data.items())
+    if isinstance(data, list):
+        return sum(sequence_size(value, key) for value in data)
+    if isinstance(data, str) and key in SEQUENCE_KEYS:
+        return len(data)
+    return 0
+
+
+def init_tracing(app: Flask) -> bool:
+    """Export OpenTelemetry traces when OTEL_EXPORTER_OTLP_ENDPOINT is set and the SDK is installed"""
+    if not os.environ.get('OTEL_EXPORTER_OTLP_ENDPOINT'):
+        return False
+    try:
+        from opentelemetry import trace
+        from opentelemetry.sdk.resources import Resource
+        from opentelemetry.sdk.trace import TracerProvider
+        from opentelemetry.sdk.trace.export import BatchSpanProcessor
+        from opentelemetry.exporter.otlp.proto.http.trace_exporter import OTLPSpanExporter
+        from opentelemetry.instrumentation.flask import FlaskInstrumentor
+    except ImportError:
+        logger.warning("OTEL_EXPORTER_OTLP_ENDPOINT is set but OpenTelemetry packages are not installed")
+        return False
+
+    service_name = os.environ.get('OTEL_SERVICE_NAME', 'oligo-designer')
+    provider = TracerProvider(resource=Resource.create({'service.name': service_name}))
+    provider.add_span_processor(BatchSpanProcessor(OTLPSpanExporter()))
+    trace.set_tracer_provider(provider)
+    FlaskInstrumentor().instrument_app(app)
+    logger.info("OpenTelemetry tracing enabled", extra={'service': service_name})
+    return True
+
+
+def init_request_logging(app: Flask):
+    """Log every request with its id, status, latency and sequence size"""
+
+    @app.before_request
+    def start_request():
+        g.request_id = request.headers.get('X-Request-ID') or uuid.uuid4().hex
+        g.request_start = time.perf_counter()
+
+    @app.after_request
+    def log_request(response):
+        latency_ms = (time.perf_counter() - g.get('request_start', time.perf_counter())) * 1000
+        payload = request.get_json(silent=True) if request.is_json else None
+        logger.info("request", extra={
+            'request_id': g.get('request_id'),
+            'method': request.method,
+            'path': request.path,
+            'status': response.status_code,
+            'latency_ms': round(latency_ms, 2),
+            'body_bytes': request.content_length or 0,
+            'sequence_size': sequence_size(payload),
+            'client': request.remote_addr
+        })
+        response.headers['X-Request-ID'] = g.get('request_id', '')
+        return response
@@ -0,0 +1,56 @@
+import json
+from typing import Dict, Iterable
+from flask import Response, request, stream_with_context
+from core.context import Cancelled, Context, current, using
+
+NDJSON_MIMETYPE = 'application/x-ndjson'
+_END = object()
+
+
+def wants_ndjson() -> bool:
+    """Whether the client asked for a streamed response, by ?format=ndjson or Accept: application/x-ndjson"""
+    if request.args.get('format') == 'ndjson':
+        return True
+    return request.accept_mimetypes.best_match(['application/json', NDJSON_MIMETYPE]) == NDJSON_MIMETYPE
+
+
+def ndjson_response(head: Dict, rows: Iterable[Dict]) -> Response:
+    """Newline-delimited JSON: head, then one line per row as it is computed, then a closing line
+
+    rows should be a generator, so each record's result is sent as soon as it is ready instead
+    of after the whole batch. The closing line is {"done": true, "count": n}; a failure part-way
+    through replaces it with {"success": false, "error": ..., "done": true, "count": n}, since
+    the 200 status has already been sent. A stream without a closing line was cut off.
+
+    Rows are computed after the view has returned, so each is computed under the request's
+    context (init_deadlines): a stream past the deadline stops with an error line, and one whose
+    client disconnects is cancelled rather than computing rows nobody will read.
+    """
+    context = current() or Context()
+
+    def lines():
+        yield json.dumps(head) + '\n'
+        count, iterator = 0, iter(rows)
+        try:
+            while True:
+                with using(context):
+                    context.check()
+                    row = next(iterator, _END)
+                if row is _END:
+                    break
+                yield json.dumps(row) + '\n'
+                count += 1
+        except GeneratorExit:
+            # The server closes the response when the client goes away
+            context.cancel('Client disconnected')
+            raise
+        except Cancelled as e:
+            yield json.dumps({'success': False, 'error': f'Stopped: {e}', 'done': True, 'count': count}) + '\n'
+            return
+        except Exception as e:
+            yield json.dumps({'success': False, 'error': str(e), 'done': True, 'count': count}) + '\n'
+            return
+        yield json.dumps({'done': True, 'count': count}) + '\n'
+
+    return Response(stream_with_context(lines()), mimetype=NDJSON_MIMETYPE,
+                    headers={'Cache-Control': 'no-cache', 'X-Accel-Buffering': 'no'})
@@ -0,0 +1,350 @@
+import os
+from dataclasses import asdict
+from flask import Blueprint, Response, request, jsonify
+from core.seqio import SequenceRecord
+from core.sequence import clean_sequence
+from core.workspace import SQLiteSequenceStore
+from core.entrez import EntrezClient, EntrezError, accession_list
+from core.regions import RegionClient, RegionError
+from core.homology import HomologySearch
+from core.manifest import Manifest
+from .jobs import GROUP_OPERATIONS, OPERATIONS, seeded_params
+from .cache import cached_operation
+from .auth import FRONTEND_URL, current_author, current_owner, sign_token, verify_token
+
+workspace_bp = Blueprint('workspace', __name__)
+store = SQLiteSequenceStore(os.environ.get('WORKSPACE_DB', 'workspace.db'))
+# NCBI_API_KEY raises the E-utilities rate limit from 3 to 10 requests per second
+entrez = EntrezClient(api_key=os.environ.get('NCBI_API_KEY'), email=os.environ.get('NCBI_EMAIL'))
+region_clients = {source: RegionClient(source) for source in ('ucsc', 'ensembl')}
+
+MAX_ENTREZ_ACCESSIONS = 20
+
+
+@workspace_bp.route('/sequences', methods=['GET'])
+def list_sequences():
+    """List the session's saved sequences"""
+    return jsonify({'success': True, 'sequences': [asdict(saved) for saved in store.list(current_owner())]})
+
+
+@workspace_bp.route('/sequences', methods=['POST'])
+def create_sequence():
+    """Save a named sequence to the session's workspace"""
+    try:
+        data = request.get_json(silent=True)
+        if not data:
+            return jsonify({'success': False, 'error': 'No JSON data provided'}), 400
+
+        name = data.get('name', '').strip()
+        sequence = clean_sequence(data.get('sequence', ''))
+        if not name:
+            return jsonify({'success': False, 'error': 'Sequence name is required'}), 400
+        if not sequence:
+            return jsonify({'success': False, 'error': 'Sequence is required'}), 400
+
+        saved = store.create(current_owner(), name, sequence, data.get('description', ''), current_author())
+        return jsonify({'success': True, 'sequence': asdict(saved),
+                        'duplicates': store.duplicates(current_owner(), sequence, saved.id)}), 201
+
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@workspace_bp.route('/sequences/<sequence_id>', methods=['GET'])
+def get_sequence(sequence_id):
+    """Get a saved sequence with its analyses"""
+    saved = store.get(current_owner(), sequence_id)
+    if not saved:
+        return jsonify({'success': False, 'error': f'Sequence "{sequence_id}" not found'}), 404
+    return jsonify({'success': True, 'sequence': asdict(saved)})
+
+
+@workspace_bp.route('/sequences/<sequence_id>', methods=['PUT'])
+def update_sequence(sequence_id):
+    """Update a saved sequence's name, sequence or description"""
+    try:
+        data = request.get_json(silent=True) or {}
+        if 'sequence' in data:
+            data['sequence'] = clean_sequence(data['sequence'])
+        data.pop('author', None)
+        saved = store.update(current_owner(), sequence_id, current_author(), **data)
+        if not saved:
+            return jsonify({'success': False, 'error': f'Sequence "{sequence_id}" not found'}), 404
+        return jsonify({'success': True, 'sequence': asdict(saved),
+                        'duplicates': store.duplicates(current_owner(), saved.sequence, saved.id)})
+
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@workspace_bp.route('/sequences/<sequence_id>', methods=['DELETE'])
+def delete_sequence(sequence_id):
+    """Delete a saved sequence and its analyses"""
+    if not store.delete(current_owner(), sequence_id):
+        return jsonify({'success': False, 'error': f'Sequence "{sequence_id}" not found'}), 404
+    return jsonify({'success': True})
+
+
+@workspace_bp.route('/sequences/<sequence_id>/versions', methods=['GET'])
+def list_versions(sequence_id):
+    """Version history of a saved sequence, oldest first"""
+    versions = store.versions(current_owner(), sequence_id)
+    if versions is None:
+        return jsonify({'success': False, 'error': f'Sequence "{sequence_id}" not found'}), 404
+    return jsonify({'success': True, 'versions': [asdict(version) for version in versions]})
+
+
+@workspace_bp.route('/sequences/<sequence_id>/versions/<int:version>', methods=['GET'])
+def get_version(sequence_id, version):
+    """A saved sequence as it was at one version"""
+    sequence = store.version_sequence(current_owner(), sequence_id, version)
+    if sequence is None:
+        return jsonify({'success': False, 'error': f'Version {version} of sequence "{sequence_id}" not found'}), 404
+    return jsonify({'success': True, 'version': version, 'sequence': sequence})
+
+
+@workspace_bp.route('/sequences/<sequence_id>/versions/<int:version>/revert', methods=['POST'])
+def revert_version(sequence_id, version):
+    """Restore an earlier version's sequence, recorded as a new version"""
+    try:
+        saved = store.revert(current_owner(), sequence_id, version, current_author())
+        if not saved:
+            return jsonify({'success': False, 'error': f'Version {version} of sequence "{sequence_id}" not found'}), 404
+        return jsonify({'success': True, 'sequence': asdict(saved)})
+
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@workspace_bp.route('/sequences/<sequence_id>/analyses', methods=['POST'])
+def run_analysis(sequence_id):
+    """Run an operation on a saved sequence and store the result with its reproducibility manifest"""
+    try:
+        data = request.get_json(silent=True) or {}
+        operation = data.get('operation', '')
+
+        # Group operations take many sequences or none, so they only run as batch jobs
+        available = sorted(set(OPERATIONS) - set(GROUP_OPERATIONS))
+        if operation not in available:
+            return jsonify({'success': False,
+                            'error': f'Unknown operation "{operation}". Available: {available}'}), 400
+        params = seeded_params(operation, data.get('params', {}))
+
+        saved = store.get(current_owner(), sequence_id)
+        if not saved:
+            return jsonify({'success': False, 'error': f'Sequence "{sequence_id}" not found'}), 404
+
+        func = cached_operation(operation, OPERATIONS[operation])
+        result = func(SequenceRecord(saved.name, saved.sequence), params)
+        manifest = Manifest.create(operation, params, params.get('seed'), sequence=saved.sequence)
+        analysis = store.add_analysis(sequence_id, operation, params, result, manifest.to_dict())
+        return jsonify({'success': True, 'analysis': asdict(analysis)}), 201
+
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+ARTIFACT_MIMETYPES = {'json': 'application/json'}
+ARTIFACT_EXTENSIONS = {'fasta': 'fa', 'pileup': 'pileup.tsv'}
+
+
+@workspace_bp.route('/artifacts', methods=['GET'])
+def list_artifacts():
+    """Pipeline outputs kept in the session's workspace, newest first, or one job's in step order with ?job_id="""
+    artifacts = store.list_artifacts(current_owner(), request.args.get('job_id', ''))
+    return jsonify({'success': True, 'artifacts': [asdict(artifact) for artifact in artifacts]})
+
+
+@workspace_bp.route('/artifacts/<artifact_id>', methods=['GET'])
+def get_artifact(artifact_id):
+    """A pipeline output with its content, or the content alone as a file with ?download=1"""
+    artifact = store.get_artifact(current_owner(), artifact_id)
+    if not artifact:
+        return jsonify({'success': False, 'error': f'Artifact "{artifact_id}" not found'}), 404
+    if request.args.get('download'):
+        filename = f"{artifact.name.replace('/', '-')}.{ARTIFACT_EXTENSIONS.get(artifact.format, artifact.format)}"
+        return Response(artifact.content, mimetype=ARTIFACT_MIMETYPES.get(artifact.format, 'text/plain'),
+                        headers={'Content-Disposition': f'attachment; filename="{filename}"'})
+    return jsonify({'success': True, 'artifact': asdict(artifact)})
+
+
+@workspace_bp.route('/artifacts/<artifact_id>', methods=['DELETE'])
+def delete_artifact(artifact_id):
+    """Delete a pipeline output"""
+    if not store.delete_artifact(current_owner(), artifact_id):
+        return jsonify({'success': False, 'error': f'Artifact "{artifact_id}" not found'}), 404
+    return jsonify({'success': True})
+
+
+@workspace_bp.route('/search', methods=['POST'])
+def search_library():
+    """Seeded homology search of a fragment against the session's saved sequences, offline
+
+    Hits use BLAST's fields with 0-based coordinates; subject is the saved sequence's name
+    and sequence_id its id.
+    """
+    try:
+        data = request.get_json(silent=True) or {}
+        query = clean_sequence(data.get('sequence', ''))
+        if not query:
+            return jsonify({'success': False, 'error': 'Sequence is required'}), 400
+
+        saved = store.list(current_owner())
+        if not saved:
+            return jsonify({'success': True, 'searched': 0, 'hits': []})
+        names = {item.id: item.name for item in saved}
+        search = HomologySearch([SequenceRecord(item.id, item.sequence) for item in saved], k=int(data.get('k', 11)))
+        hits = []
+        for hit in search.search(query, data.get('name') or 'query', max_hits=int(data.get('max_hits', 50)),
+                                 max_evalue=float(data.get('evalue', 1e-3))):
+            hits.append({**hit.to_dict(), 'subject': names[hit.subject], 'sequence_id': hit.subject})
+        return jsonify({'success': True, 'searched': len(saved), 'hits': hits})
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@workspace_bp.route('/import/entrez', methods=['POST'])
+def import_entrez():
+    """Fetch nucleotide records from NCBI by accession and save them to the workspace
+
+    Each record's topology and features are stored with it as a 'genbank' analysis.
+    """
+    try:
+        data = request.get_json(silent=True) or {}
+        accessions = data.get('accessions', '')
+        accessions = accession_list(accessions if isinstance(accessions, str) else ' '.join(map(str, accessions)))
+        if not accessions:
+            return jsonify({'success': False, 'error': 'At least one accession is required'}), 400
+        if len(accessions) > MAX_ENTREZ_ACCESSIONS:
+            return jsonify({'success': False,
+                            'error': f'Too many accessions ({len(accessions)}), maximum is {MAX_ENTREZ_ACCESSIONS}'}), 400
+
+        imported = []
+        for accession, record in zip(accessions, entrez.fetch_many(accessions)):
+            saved = store.create(current_owner(), accession, record.sequence, record.definition, current_author())
+            store.add_analysis(saved.id, 'genbank', {'accession': accession}, {
+                'name': record.name,
+                'topology': record.topology,
+                'features': [feature.to_dict() for feature in record.features]
+            })
+            imported.append({'accession': accession, 'sequence': asdict(store.get(current_owner(), saved.id)),
+                             'duplicates': store.duplicates(current_owner(), record.sequence, saved.id)})
+        return jsonify({'success': True, 'imported': imported}), 201
+
+    except ValueError as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except EntrezError as e:
+        return jsonify({'success': False, 'error': str(e)}), e.status
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@workspace_bp.route('/import/region', methods=['POST'])
+def import_region():
+    """Fetch a genomic region (assembly, chrom, 0-based start, exclusive end) from UCSC or Ensembl into the workspace"""
+    try:
+        data = request.get_json(silent=True) or {}
+        source = data.get('source', 'ucsc')
+        if source not in region_clients:
+            return jsonify({'success': False, 'error': f'Unknown source "{source}"; use ucsc or ensembl'}), 400
+        if not data.get('assembly') or not data.get('chrom'):
+            return jsonify({'success': False, 'error': 'assembly and chrom are required'}), 400
+
+        assembly, chrom = str(data['assembly']), str(data['chrom'])
+        start, end, strand = int(data.get('start', 0)), int(data.get('end', 0)), int(data.get('strand', 1))
+        fetched = region_clients[source].fetch(assembly, chrom, start, end, strand)
+        name = data.get('name') or fetched.name
+        description = f'{assembly} {chrom}:{start + 1}-{end} ({"+" if strand == 1 else "-"} strand) from {source}'
+        saved = store.create(current_owner(), name, fetched.sequence, description, current_author())
+        return jsonify({'success': True, 'sequence': asdict(saved),
+                        'duplicates': store.duplicates(current_owner(), fetched.sequence, saved.id)}), 201
+
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
+    except RegionError as e:
+        return jsonify({'success': False, 'error': str(e)}), e.status
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+def _project_json(project) -> dict:
+    """Project fields for its owner, with a share link while sharing is on"""
+    result = asdict(project)
+    share_id = result.pop('share_id')
+    result['share_url'] = f'{FRONTEND_URL}/?share={sign_token(f"{project.id}:{share_id}")}' if share_id else None
+    return result
+
+
+@workspace_bp.route('/projects', methods=['GET'])
+def list_projects():
+    """List the session's projects"""
+    return jsonify({'success': True, 'projects': [_project_json(p) for p in store.list_projects(current_owner())]})
+
+
+@workspace_bp.route('/projects', methods=['POST'])
+def create_project():
+    """Start a project for grouping saved sequences"""
+    try:
+        data = request.get_json(silent=True) or {}
+        name = data.get('name', '').strip()
+        if not name:
+            return jsonify({'success': False, 'error': 'Project name is required'}), 400
+        project = store.create_project(current_owner(), name, data.get('description', ''))
+        return jsonify({'success': True, 'project': _project_json(project)}), 201
+
+    except Exception as e:
+        return jsonify({'success': False, 'error': str(e)}), 500
+
+
+@workspace_bp.route('/projects/<project_id>', methods=['GET'])
+def get_project(project_id):
+    """Get a project with its sequence ids"""
+    project = store.get_project(current_owner(), project_id)
+    if not project:
+        return jsonify({'success': False, 'error': f'Project "{project_id}" not found'}), 404
+    return jsonify({'success': True, 'project': _project_json(project)})
+
+
+@workspace_bp.route('/projects/<project_id>', methods=['DELETE'])
+def delete_project(project_id):
+    """Delete a project, keeping its sequences"""
+    if not store.delete_project(current_owner(), project_id):
+        return jsonify({'success': False, 'error': f'Project "{project_id}" not found'}), 404
+    return jsonify({'success': True})
+
+
+@workspace_bp.route('/projects/<project_id>/sequences/<sequence_id>', methods=['PUT', 'DELETE'])
+def project_membership(project_id, sequence_id):
+    """Add a saved sequence to a project (PUT) or remove it (DELETE)"""
+    project = store.set_membership(current_owner(), project_id, sequence_id, request.method == 'PUT')
+    if not project:
+        return jsonify({'success': False,
+                        'error': f'Project "{project_id}" or sequence "{sequence_id}" not found'}), 404
+    return jsonify({'success': True, 'project': _project_json(project)})
+
+
+@workspace_bp.route('/projects/<project_id>/share', methods=['POST', 'DELETE'])
+def share_project(project_id):
+    """Create a new read-only share link, revoking older ones (POST), or stop sharing (DELETE)"""
+    project = store.set_share(current_owner(), project_id, request.method == 'POST')
+    if not project:
+        return jsonify({'success': False, 'error': f'Project "{project_id}" not found'}), 404
+    return jsonify({'success': True, 'project': _project_json(project)})
+
+
+@workspace_bp.route('/shared/<token>', methods=['GET'])
+def shared_project(token):
+    """Read-only view of a shared project with its sequences and analyses"""
+    project_id, _, share_id = (verify_token(token) or '').partition(':')
+    shared = store.shared_project(project_id, share_id)
+    if not shared:
+        return jsonify({'success': False, 'error': 'This share link is invalid or has been revoked'}), 404
+    project, sequences = shared
+    return jsonify({
+        'success': True,
+        'project': {'name': project.name, 'description': project.description, 'created_at': project.created_at},
+        'sequences': [{key: value for key, value in asdict(saved).items() if key != 'owner_id'} for saved in sequences]
+    })
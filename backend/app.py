@@ -2,13 +2,39 @@ from flask import Flask, request, jsonify
 from flask_cors import CORS
 import redis
 import json
+import os
 import random
 import uuid
 from typing import Dict, List, Optional
 import primer3
+from api.cache import cache_bp
+from api.docs import docs_bp, spec_drift
+from api.jobs import jobs_bp, restore_jobs
+from api.limits import init_deadlines, init_limits
+from api.metrics import metrics_bp, init_metrics
+from api.workspace import workspace_bp
+from api.auth import auth_bp, init_sessions
+from api.analysis import analysis_bp
+from api.plugins import plugins_bp
+from api.request_logging import configure_logging, init_request_logging, init_tracing, logger
+from core.manifest import Manifest, resolve_seed
 
 app = Flask(__name__)
-CORS(app)
+CORS(app, supports_credentials=True)
+init_sessions(app)
+configure_logging()
+init_request_logging(app)
+init_metrics(app)
+init_limits(app)
+init_tracing(app)
+app.register_blueprint(docs_bp, url_prefix='/api')
+app.register_blueprint(cache_bp, url_prefix='/api')
+app.register_blueprint(jobs_bp, url_prefix='/api/v1')
+app.register_blueprint(metrics_bp)
+app.register_blueprint(workspace_bp, url_prefix='/api/workspace')
+app.register_blueprint(auth_bp, url_prefix='/api/auth')
+app.register_blueprint(analysis_bp, url_prefix='/api/analysis')
+app.register_blueprint(plugins_bp, url_prefix='/api')
 
 # Redis connection
 r = redis.Redis(host='localhost', port=6379, db=0, decode_responses=True)
@@ -206,9 +232,9 @@ def get_oligos_by_length(length: int) -> List[str]:
         # Get sequence IDs for this length
         seq_ids = r.smembers(f"oligo:length:{length}")
 
-        # Get actual sequences from the oligo records
+        # Get actual sequences from the oligo records, in a fixed order so a seeded pick repeats
         sequences = []
-        for seq_id in seq_ids:
+        for seq_id in sorted(seq_ids):
             sequence = r.hget(f"oligo:{seq_id}", "sequence")
             if sequence:
                 sequences.append(sequence)
@@ -228,15 +254,15 @@ def get_all_oligo_lengths() -> List[int]:
         return []
 
 
-def get_random_oligo(length: int) -> Optional[str]:
+def get_random_oligo(length: int, rng: random.Random) -> Optional[str]:
     """Get random oligo sequence of specific length from Redis, with fallback strategies"""
     # First try exact length
     oligos = get_oligos_by_length(length)
     if oligos:
-        return random.choice(oligos)
+        return rng.choice(oligos)
 
     # Fallback: construct from shorter oligos
-    return construct_oligo_from_shorter(length)
+    return construct_oligo_from_shorter(length, rng)
 
 
 def check_can_construct_length(target_length: int, available_lengths: List[int]) -> bool:
@@ -259,7 +285,7 @@ def check_can_construct_length(target_length: int, available_lengths: List[int])
     return False
 
 
-def construct_oligo_from_shorter(target_length: int) -> Optional[str]:
+def construct_oligo_from_shorter(target_length: int, rng: random.Random) -> Optional[str]:
     """Construct oligo of target length by joining shorter oligos and truncating"""
     available_lengths = get_all_oligo_lengths()
 
@@ -277,7 +303,7 @@ def construct_oligo_from_shorter(target_length: int) -> Optional[str]:
             best_length = min(longer_lengths)  # Use shortest available longer oligo
             oligos = get_oligos_by_length(best_length)
             if oligos:
-                selected_oligo = random.choice(oligos)
+                selected_oligo = rng.choice(oligos)
                 return selected_oligo[:target_length]  # Truncate to target length
         return None
 
@@ -304,7 +330,7 @@ def construct_oligo_from_shorter(target_length: int) -> Optional[str]:
         if not oligos:
             return None
 
-        selected_oligo = random.choice(oligos)
+        selected_oligo = rng.choice(oligos)
 
         # Add to sequence (truncate if needed)
         if remaining_length >= len(selected_oligo):
@@ -317,16 +343,16 @@ def construct_oligo_from_shorter(target_length: int) -> Optional[str]:
     return constructed_sequence
 
 
-def get_oligo_with_properties(length: int) -> Optional[Dict]:
+def get_oligo_with_properties(length: int, rng: random.Random) -> Optional[Dict]:
     """Get random oligo with its thermodynamic properties"""
     try:
         # Get sequence IDs for this length
-        seq_ids = list(r.smembers(f"oligo:length:{length}"))
+        seq_ids = sorted(r.smembers(f"oligo:length:{length}"))
         if not seq_ids:
             return None
 
         # Pick random sequence ID
-        seq_id = random.choice(seq_ids)
+        seq_id = rng.choice(seq_ids)
 
         # Get the full oligo data
         oligo_data = r.hget(f"oligo:{seq_id}", "data")
@@ -533,12 +559,14 @@ def delete_strand(strand_id):
 
 @app.route('/api/generate-strands', methods=['POST'])
 def generate_strands():
-    """Generate strand sequences by randomly selecting oligos from Redis"""
+    """Generate strand sequences by randomly selecting oligos from Redis; the same seed picks the same oligos"""
     data = request.json
     settings = data.get('settings', {})
     strand_ids = data.get('strand_ids', [])
 
     try:
+        seed = resolve_seed(data.get('seed'))
+        rng = random.Random(seed)
         # Get target strands
         target_strands = [strands[sid] for sid in strand_ids if sid in strands]
 
@@ -556,7 +584,7 @@ def generate_strands():
         domain_assignments = {}
         errors = []
 
-        for base_name in required_domains:
+        for base_name in sorted(required_domains):
             if base_name not in domain_cache:
                 errors.append(f"Domain '{base_name}' not found in cache")
                 continue
@@ -564,7 +592,7 @@ def generate_strands():
             length = domain_cache[base_name]
 
             # Get random oligo sequence of this length from Redis
-            base_sequence = get_random_oligo(length)
+            base_sequence = get_random_oligo(length, rng)
 
             if base_sequence:
                 domain_assignments[base_name] = base_sequence
@@ -615,9 +643,14 @@ def generate_strands():
             'success': success,
             'message': message,
             'generated_strands': generated_strands,
-            'errors': errors
+            'errors': errors,
+            'seed': seed,
+            'manifest': Manifest.create('generate-strands', data, seed,
+                                        strands=[strand['domains'] for strand in target_strands]).to_dict()
         })
 
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
     except Exception as e:
         return jsonify({'success': False, 'error': str(e)}), 500
 
@@ -828,6 +861,8 @@ def generate_optimized_strand_sets():
     num_generations = data.get('num_generations', 100)
 
     try:
+        seed = resolve_seed(data.get('seed'))
+        rng = random.Random(seed)
         # Get target strands
         target_strands = [strands[sid] for sid in strand_ids if sid in strands]
 
@@ -848,13 +883,13 @@ def generate_optimized_strand_sets():
             domain_assignments = {}
             generation_failed = False
 
-            for base_name in required_domains:
+            for base_name in sorted(required_domains):
                 if base_name not in domain_cache:
                     generation_failed = True
                     break
 
                 length = domain_cache[base_name]
-                base_sequence = get_random_oligo(length)
+                base_sequence = get_random_oligo(length, rng)
 
                 if base_sequence:
                     domain_assignments[base_name] = base_sequence
@@ -942,9 +977,14 @@ def generate_optimized_strand_sets():
             'message': f"Generated {num_generations} strand sets. Found {len(valid_strand_sets)} valid sets.",
             'total_generated': num_generations,
             'total_valid': len(valid_strand_sets),
-            'top_strand_sets': top_sets
+            'top_strand_sets': top_sets,
+            'seed': seed,
+            'manifest': Manifest.create('generate-optimized-strand-sets', data, seed,
+                                        strands=[strand['domains'] for strand in target_strands]).to_dict()
         })
 
+    except (ValueError, TypeError) as e:
+        return jsonify({'success': False, 'error': str(e)}), 400
     except Exception as e:
         return jsonify({'success': False, 'error': str(e)}), 500
 
@@ -1272,5 +1312,19 @@ def calculate_strand_set_score(strands, cross_dimer_results, settings):
     }
 
 
+init_deadlines(app)
+
 if __name__ == '__main__':
+    # Warn when routes and the OpenAPI document have drifted apart
+    drift = spec_drift(app)
+    for operation in drift['undocumented']:
+        logger.warning("Route is not documented in api/openapi.json", extra={'operation': operation})
+    for operation in drift['missing']:
+        logger.warning("Route is documented in api/openapi.json but not served", extra={'operation': operation})
+
+    # The debug reloader runs this block in a watching process and again in the child that serves
+    # (WERKZEUG_RUN_MAIN set), so checkpointed jobs are resumed in the child only
+    if os.environ.get('WERKZEUG_RUN_MAIN'):
+        restore_jobs()
+
     app.run(debug=True, port=5000)
\ No newline at end of file
@@ -0,0 +1,66 @@
+#!/usr/bin/env python3
+"""
+Alignment kernel benchmarks
+Compares the full-matrix DP with banded global alignment and striped Smith-Waterman on read-sized problems
+
+Usage (from backend/): python -m benchmarks.bench_align --lengths 100 250 1000 --lanes 32
+"""
+
+import argparse
+import random
+import time
+from core.align import ScoringScheme, banded_global_align, global_align, local_align, striped_local_align
+
+
+def mutate(sequence: str, rate: float) -> str:
+    """Copy with substitutions and single-base indels at the given rate"""
+    bases = []
+    for base in sequence:
+        roll = random.random()
+        if roll < rate / 3:
+            continue
+        bases.append(random.choice('ACGT') if roll < 2 * rate / 3 else base)
+        if roll > 1 - rate / 3:
+            bases.append(random.choice('ACGT'))
+    return ''.join(bases)
+
+
+def timed(func, *args, **kwargs):
+    start = time.perf_counter()
+    result = func(*args, **kwargs)
+    return result, (time.perf_counter() - start) * 1000
+
+
+def main():
+    parser = argparse.ArgumentParser(description='Benchmark banded and striped alignment against the full DP')
+    parser.add_argument('--lengths', type=int, nargs='+', default=[100, 250, 1000],
+                        help='Read lengths (nt) to compare, default: 100 250 1000')
+    parser.add_argument('--error-rate', type=float, default=0.05, help='Read error rate, default: 0.05')
+    parser.add_argument('--band', type=int, default=16, help='Band for banded global alignment, default: 16')
+    parser.add_argument('--lanes', type=int, default=32, help='Lanes per striped vector, default: 32')
+    args = parser.parse_args()
+
+    random.seed(0)
+    scoring = ScoringScheme()
+    print(f"Error rate {args.error_rate}, band {args.band}, {args.lanes} lanes; times in ms")
+    for length in args.lengths:
+        read = ''.join(random.choices('ACGT', k=length))
+        mutated = mutate(read, args.error_rate)
+        # A mapper verifies a read against a reference window padded on both sides
+        pad = max(length // 10, 10)
+        window = ''.join(random.choices('ACGT', k=pad)) + mutated + ''.join(random.choices('ACGT', k=pad))
+
+        full, full_ms = timed(global_align, read, mutated, scoring)
+        banded, banded_ms = timed(banded_global_align, read, mutated, scoring, args.band)
+        local, local_ms = timed(local_align, window, read, scoring)
+        striped, striped_ms = timed(striped_local_align, window, read, scoring, args.lanes)
+        if striped.score != local.score:
+            raise SystemExit(f'Striped score {striped.score} differs from the full DP {local.score}')
+        banded_note = '' if banded.score == full.score else f' (band too narrow: {banded.score} vs {full.score})'
+
+        print(f"  {length:>5} nt  global {full_ms:9.1f}  banded {banded_ms:8.1f}{banded_note}"
+              f"  local {local_ms:9.1f}  striped {striped_ms:8.1f}")
+
+
+if __name__ == '__main__':
+    main()
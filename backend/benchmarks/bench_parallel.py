@@ -0,0 +1,52 @@
+#!/usr/bin/env python3
+"""
+Parallel chunk benchmarks
+Times GC counting, complementing and pattern search serially and with chunked worker processes
+
+Usage (from backend/): python -m benchmarks.bench_parallel --length 100000000 --workers 1 2 4 8
+"""
+
+import argparse
+import os
+import random
+import time
+from core.parallel import find_all, gc_content, parallel_complement
+from core.restriction import site_pattern
+
+
+def main():
+    parser = argparse.ArgumentParser(description='Benchmark chunked parallel sequence operations')
+    parser.add_argument('--length', type=int, default=100_000_000, help='Sequence length (nt), default: 100000000')
+    parser.add_argument('--workers', type=int, nargs='+', default=[1, 2, 4, os.cpu_count() or 1],
+                        help='Worker counts to compare, default: 1 2 4 and the CPU count')
+    parser.add_argument('--pattern', default='GAATTC', help='Pattern to search for, default: GAATTC')
+    args = parser.parse_args()
+
+    # Repeating a random block keeps generation fast for very long sequences
+    random.seed(0)
+    block = ''.join(random.choices('ACGT', k=min(args.length, 1_000_000)))
+    sequence = (block * (args.length // len(block) + 1))[:args.length]
+
+    benchmarks = {
+        'gc_content': lambda workers: gc_content(sequence, workers),
+        'complement': lambda workers: parallel_complement(sequence, workers),
+        f'find {args.pattern}': lambda workers: find_all(sequence, site_pattern(args.pattern), len(args.pattern),
+                                                         workers),
+    }
+
+    print(f"Sequence length: {args.length} nt, {os.cpu_count()} CPUs")
+    for name, func in benchmarks.items():
+        baseline = None
+        for workers in sorted(set(args.workers)):
+            start = time.perf_counter()
+            result = func(workers)
+            seconds = time.perf_counter() - start
+            if baseline is None:
+                baseline, expected = seconds, result
+            elif result != expected:
+                raise SystemExit(f'{name} with {workers} workers disagrees with {min(args.workers)} worker(s)')
+            print(f"  {name:<16} {workers:>3} workers {seconds * 1000:10.1f} ms  {baseline / seconds:5.2f}x")
+
+
+if __name__ == '__main__':
+    main()
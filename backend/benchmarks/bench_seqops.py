@@ -0,0 +1,34 @@
+#!/usr/bin/env python3
+"""
+seqops benchmarks
+Times each available seqops backend (tests/test_seqops.py checks that they agree)
+
+Usage (from backend/): python -m benchmarks.bench_seqops --length 100000000
+"""
+
+import argparse
+import random
+import timeit
+from core import seqops
+
+
+def main():
+    parser = argparse.ArgumentParser(description='Benchmark seqops backends')
+    parser.add_argument('--length', type=int, default=10_000_000, help='Buffer length (bytes), default: 10000000')
+    parser.add_argument('--repeat', type=int, default=5, help='Iterations per benchmark, default: 5')
+    args = parser.parse_args()
+
+    random.seed(0)
+    block = ''.join(random.choices('ACGT', k=min(args.length, 1_000_000))).encode('ascii')
+    data = (block * (args.length // len(block) + 1))[:args.length]
+
+    print(f"Backends: {', '.join(seqops.BACKENDS)} (default: {seqops.BACKEND})")
+    print(f"Buffer length: {args.length} bytes, {args.repeat} iterations")
+    for operation in (seqops.reverse_complement, seqops.gc_count, seqops.base_counts):
+        for backend in seqops.BACKENDS:
+            seconds = timeit.timeit(lambda: operation(data, backend), number=args.repeat) / args.repeat
+            print(f"  {operation.__name__:<20} {backend:<7} {seconds * 1000:9.3f} ms/op")
+
+
+if __name__ == '__main__':
+    main()
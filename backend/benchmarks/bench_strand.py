@@ -0,0 +1,47 @@
+#!/usr/bin/env python3
+"""
+Strand benchmarks
+Compares the copying Strand operations with their in-place variants, and the lookup-table
+complement with a per-base dictionary lookup and the bytes fast path
+
+Usage (from backend/): python -m benchmarks.bench_strand --length 1000000
+"""
+
+import argparse
+import random
+import timeit
+from core.sequence import COMPLEMENTS, complement_bytes, reverse_complement_bytes
+from core.strand import Strand
+
+
+def main():
+    parser = argparse.ArgumentParser(description='Benchmark copying vs in-place Strand operations')
+    parser.add_argument('--length', type=int, default=100000, help='Strand length (nt), default: 100000')
+    parser.add_argument('--repeat', type=int, default=20, help='Iterations per benchmark, default: 20')
+    args = parser.parse_args()
+
+    random.seed(0)
+    strand = Strand(''.join(random.choice('ACGT') for _ in range(args.length)))
+    data = strand.sequence.encode('ascii')
+
+    benchmarks = {
+        'reverse (copy)': lambda: strand.reverse(),
+        'reverse_in_place': lambda: strand.reverse_in_place(),
+        'complement (copy)': lambda: strand.complement(),
+        'complement_in_place': lambda: strand.complement_in_place(),
+        'reverse_complement (copy)': lambda: strand.reverse_complement(),
+        'reverse_complement_in_place': lambda: strand.reverse_complement_in_place(),
+        'clone': lambda: strand.clone(),
+        'per-base dict complement': lambda: ''.join(COMPLEMENTS.get(base, 'N') for base in strand.sequence),
+        'complement_bytes': lambda: complement_bytes(data),
+        'reverse_complement_bytes': lambda: reverse_complement_bytes(data),
+    }
+
+    print(f"Strand length: {args.length} nt, {args.repeat} iterations")
+    for name, func in benchmarks.items():
+        seconds = timeit.timeit(func, number=args.repeat) / args.repeat
+        print(f"  {name:<30} {seconds * 1000:9.3f} ms/op")
+
+
+if __name__ == '__main__':
+    main()
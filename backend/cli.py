@@ -0,0 +1,954 @@
+#!/usr/bin/env python3
+"""
+robin command-line interface
+Sequence utilities that read FASTA (or one raw sequence per line) from files or stdin
+and write results to stdout, so they can be used in shell pipelines
+"""
+
+import io
+import json
+import os
+import sys
+import random
+import signal
+import argparse
+from typing import List, Optional
+from core.seqio import SequenceRecord, format_fastq, read_fastq, read_pairs, read_sequences, format_fasta
+from core.sequence import reverse_complement, gc_content, translate, find_orfs
+from core.restriction import ENZYMES, METHYLATION, digest
+from core.rebase import merge_catalog, read_rebase
+from core.mutagenesis import silent_site_changes
+from core.align import banded_global_align, global_align, local_align, semiglobal_align, format_alignment, ScoringScheme
+from core.annotation import (IntervalTree, format_bed, format_gff3, from_regions, orf_annotations, read_bed,
+                             read_gff3)
+from core.repeats import find_repeats
+from core.assembly import assemble_de_bruijn, assemble_olc, assembly_stats
+from core.trimming import TrimSettings, trim_reads
+from core.barcodes import read_barcodes
+from core.demux import Demultiplexer
+from core.screen import screen_reads
+from core.myers import find_approximate
+from core.umi import deduplicate, extract_umis
+from core.blast import format_tabular
+from core.homology import HomologySearch
+from core.mapper import ReadMapper, estimate_insert_size, is_proper_pair
+from core.minimizers import MinimizerIndex
+from core.sam import mapping_summary, sam_header, sam_pair, sam_record
+from core.variants import call_variants, coverage, coverage_summary, format_vcf, pileup
+from core.phylo import parse_newick
+from core.faidx import IndexedFasta, build_index, parse_region, write_index
+from core.simulate import evolve_along_tree, random_sequence, simulate_reads
+from core.complexes import parse_kernel
+from core.enumerator import DEFAULT_RELEASE_CUTOFF, enumerate_reactions
+from core.crn import CRN
+from core.context import Cancelled, Context, using
+from core.fold import partition_function
+from core.progress import Progress, ProgressUpdate
+from core.sbml import read_crn, to_sbml
+from core.kinetics import simulate
+from core.sweep import ParameterSweep, SweepParameter, sweep_values
+from core.manifest import Manifest, resolve_seed
+from core.registry import PARAM_TYPES, Analysis, registry
+from core.pipeline import parse_pipeline, sequence_artifact
+from core.sketch import Sketch, compare_sketches, dump_sketches, load_sketches
+
+
+PROGRESS_WIDTH = 30
+
+
+def progress_bar() -> Optional[Progress]:
+    """Stage, bar, percentage and ETA redrawn on stderr, when stderr is a terminal"""
+    if not sys.stderr.isatty():
+        return None
+
+    def draw(update: ProgressUpdate):
+        filled = int(update.percent / 100 * PROGRESS_WIDTH)
+        eta = f' ETA {int(update.eta) // 60}:{int(update.eta) % 60:02d}' if update.eta else ''
+        sys.stderr.write(f'\r{update.stage:<16} [{"#" * filled}{"-" * (PROGRESS_WIDTH - filled)}] '
+                         f'{update.percent:5.1f}%{eta}\033[K' + ('\n' if update.percent >= 100 else ''))
+        sys.stderr.flush()
+
+    return Progress(draw, interval=0.1)
+
+
+def write_manifest(args, seed: Optional[int], **inputs):
+    """Write the run's reproducibility manifest as JSON to the file --manifest names, if any"""
+    if not args.manifest:
+        return
+    parameters = {name: value for name, value in vars(args).items()
+                  if name not in ('handler', 'files', 'manifest', 'command')}
+    with open(args.manifest, 'w') as handle:
+        json.dump(Manifest.create(args.command, parameters, seed, **inputs).to_dict(), handle, indent=2)
+        handle.write('\n')
+
+
+def load_records(paths: List[str]) -> List[SequenceRecord]:
+    """Read records from the given files, or stdin when no files (or '-') are given"""
+    if not paths:
+        paths = ['-']
+
+    records = []
+    for path in paths:
+        if path == '-':
+            records.extend(read_sequences(sys.stdin))
+        else:
+            with open(path) as handle:
+                records.extend(read_sequences(handle))
+    return records
+
+
+def load_reads(paths: List[str]) -> List[SequenceRecord]:
+    """Read records from FASTQ or FASTA files (detected from the first character), or stdin"""
+    reads = []
+    for path in paths or ['-']:
+        handle = sys.stdin if path == '-' else open(path)
+        try:
+            text = handle.read()
+        finally:
+            if handle is not sys.stdin:
+                handle.close()
+        reader = read_fastq if text.lstrip().startswith('@') else read_sequences
+        reads.extend(reader(io.StringIO(text)))
+    return reads
+
+
+def cmd_revcomp(args) -> int:
+    records = [SequenceRecord(r.name, reverse_complement(r.sequence)) for r in load_records(args.files)]
+    sys.stdout.write(format_fasta(records))
+    return 0
+
+
+def cmd_gc(args) -> int:
+    for record in load_records(args.files):
+        print(f"{record.name}\t{len(record.sequence)}\t{gc_content(record.sequence):.2f}")
+    return 0
+
+
+def cmd_translate(args) -> int:
+    records = [SequenceRecord(r.name, translate(r.sequence, frame=args.frame, to_stop=args.to_stop))
+               for r in load_records(args.files)]
+    sys.stdout.write(format_fasta(records))
+    return 0
+
+
+def write_annotations(per_record: List[tuple], fmt: str):
+    """Write (record name, annotations) pairs as GFF3 or BED"""
+    if fmt == 'gff3':
+        sys.stdout.write('##gff-version 3\n')
+        for name, annotations in per_record:
+            sys.stdout.write(format_gff3(name, annotations).split('\n', 1)[1])
+    else:
+        for name, annotations in per_record:
+            sys.stdout.write(format_bed(name, annotations))
+
+
+def cmd_orf(args) -> int:
+    if args.format != 'tsv':
+        records = load_records(args.files)
+        write_annotations([(r.name, [a for a in orf_annotations(r.sequence, args.min_length)
+                                     if not args.forward_only or a.strand == 1]) for r in records], args.format)
+        return 0
+
+    print("name\tstart\tend\tstrand\tframe\tlength\tprotein")
+    for record in load_records(args.files):
+        for orf in find_orfs(record.sequence, min_length=args.min_length, both_strands=not args.forward_only):
+            print(f"{record.name}\t{orf['start']}\t{orf['end']}\t{orf['strand']}\t{orf['frame']}\t"
+                  f"{orf['length']}\t{orf['protein']}")
+    return 0
+
+
+def cmd_repeats(args) -> int:
+    if args.format == 'tsv':
+        print("name\ttype\tstart\tend\tunit")
+    per_record = []
+    for record in load_records(args.files):
+        regions = find_repeats(record.sequence, min_homopolymer=args.min_homopolymer,
+                               min_copies=args.min_copies, min_stem=args.min_stem)
+        if args.format == 'tsv':
+            for region in regions:
+                print(f"{record.name}\t{region['type']}\t{region['start']}\t{region['end']}\t"
+                      f"{region.get('unit') or region.get('stem', '')}")
+        else:
+            per_record.append((record.name, from_regions(regions, 'repeat_region', 'repeat')))
+    if args.format != 'tsv':
+        write_annotations(per_record, args.format)
+    return 0
+
+
+def cmd_digest(args) -> int:
+    if args.rebase:
+        with open(args.rebase, encoding='utf-8', errors='replace') as handle:
+            merge_catalog(ENZYMES, read_rebase(handle.read()).values())
+    enzymes = [name.strip() for name in args.enzymes.split(',') if name.strip()]
+    methylation = None
+    if args.methylation is not None:
+        methylation = [name.strip() for name in args.methylation.split(',') if name.strip()]
+    print("name\tstart\tend\tlength\tleft\tright")
+    for record in load_records(args.files):
+        for fragment in digest(record.sequence, enzymes, circular=args.circular, methylation=methylation):
+            print(f"{record.name}\t{fragment['start']}\t{fragment['end']}\t{fragment['length']}\t"
+                  f"{','.join(fragment['left_enzymes']) or '-'}\t{','.join(fragment['right_enzymes']) or '-'}")
+    return 0
+
+
+def cmd_silent(args) -> int:
+    print("name\tsite_position\tstrand\tnucleotide_changes\tchanges")
+    for record in load_records(args.files):
+        for suggestion in silent_site_changes(record.sequence, args.enzyme, args.mode, args.max_changes, args.limit):
+            changes = ','.join(f"{c['codon'] + 1}{c['amino_acid']}:{c['from']}>{c['to']}" for c in suggestion['changes'])
+            print(f"{record.name}\t{suggestion['site_position']}\t{suggestion['strand']}\t"
+                  f"{suggestion['nucleotide_changes']}\t{changes}")
+    return 0
+
+
+def cmd_assemble(args) -> int:
+    reads = [record.sequence for record in load_reads(args.files)]
+    if args.algo == 'dbg':
+        contigs = assemble_de_bruijn(reads, k=args.k, min_count=args.min_count,
+                                     min_contig_length=args.min_contig_length, progress=progress_bar())
+    else:
+        contigs = [contig for contig in assemble_olc(reads, min_overlap=args.min_overlap,
+                                                     min_identity=args.min_identity, progress=progress_bar())
+                   if len(contig) >= args.min_contig_length]
+
+    sys.stdout.write(format_fasta([SequenceRecord(c.name, c.sequence) for c in contigs]))
+    stats = assembly_stats(contigs)
+    print(f"contigs={stats['contigs']} total={stats['total_length']} longest={stats['longest']} "
+          f"N50={stats['n50']} L50={stats['l50']}", file=sys.stderr)
+    return 0
+
+
+def cmd_trim(args) -> int:
+    records = []
+    for path in args.files or ['-']:
+        if path == '-':
+            records.extend(read_fastq(sys.stdin))
+        else:
+            with open(path) as handle:
+                records.extend(read_fastq(handle))
+
+    settings = TrimSettings(
+        adapters=tuple(args.adapter),
+        window=args.window,
+        min_window_quality=args.min_quality,
+        min_length=args.min_length,
+        max_length=args.max_length
+    )
+    kept, report = trim_reads(records, settings)
+    sys.stdout.write(format_fastq(kept))
+
+    for label in ('before', 'after'):
+        stats = report[label]
+        print(f"{label}: reads={stats['reads']} bases={stats['bases']} mean_length={stats['mean_length']} "
+              f"mean_quality={stats['mean_quality']} q30={stats['q30_fraction']}", file=sys.stderr)
+    dropped = ', '.join(f'{reason}={count}' for reason, count in report['dropped'].items()) or 'none'
+    print(f"adapter_trimmed={report['adapter_trimmed']} dropped: {dropped}", file=sys.stderr)
+    return 0
+
+
+def cmd_demux(args) -> int:
+    with open(args.barcodes) as handle:
+        demultiplexer = Demultiplexer(read_barcodes(handle.read()), args.mismatches, args.location, not args.no_trim)
+    for warning in demultiplexer.warnings():
+        print(f"Warning: {warning}", file=sys.stderr)
+
+    records = []
+    for path in args.files or ['-']:
+        if path == '-':
+            records.extend(read_fastq(sys.stdin))
+        else:
+            with open(path) as handle:
+                records.extend(read_fastq(handle))
+    result = demultiplexer.run(records)
+
+    os.makedirs(args.outdir, exist_ok=True)
+    for sample, reads in result.reads.items():
+        with open(os.path.join(args.outdir, f'{sample}.fastq'), 'w') as handle:
+            handle.write(format_fastq(reads))
+
+    report = result.report()
+    print("sample\treads\tfraction\tperfect\tmismatched")
+    for sample in report['samples']:
+        perfect = sample['mismatches'].get(0, 0)
+        print(f"{sample['name']}\t{sample['reads']}\t{sample['fraction']}\t{perfect}\t"
+              f"{sum(sample['mismatches'].values()) - perfect}")
+    return 0
+
+
+def cmd_screen(args) -> int:
+    extra = {record.name: record.sequence for record in load_records(args.contaminants)} if args.contaminants else {}
+    report = screen_reads(load_reads(args.files), extra, not args.no_bundled, args.k, args.min_hits)
+
+    print(f"contaminated\t{report['contaminated']}/{report['total']}\t{report['contaminated_percent']}%")
+    for item in report['contaminants']:
+        print(f"{item['name']}\t{item['reads']}\t{item['percent']}%")
+    return 0
+
+
+def cmd_grep(args) -> int:
+    print("name\tstart\tend\tstrand\terrors")
+    for record in load_records(args.files):
+        for match in find_approximate(record.sequence, args.pattern, args.max_errors, not args.forward_only):
+            print(f"{record.name}\t{match['start']}\t{match['end']}\t{match['strand']}\t{match['errors']}")
+    return 0
+
+
+def cmd_search(args) -> int:
+    with open(args.query) as handle:
+        queries = list(read_sequences(handle))
+    search = HomologySearch(load_records(args.files), k=args.k)
+    for query in queries:
+        sys.stdout.write(format_tabular(search.search(query.sequence, query.name.split()[0], max_hits=args.max_hits,
+                                                      max_evalue=args.evalue)))
+    return 0
+
+
+def load_mapper(args) -> ReadMapper:
+    """Mapper over a saved minimizer index (cli.py index) or a reference FASTA indexed now"""
+    if MinimizerIndex.is_index(args.reference):
+        return ReadMapper(index=MinimizerIndex.load(args.reference))
+    with open(args.reference) as handle:
+        return ReadMapper(list(read_sequences(handle)), k=args.k, w=args.w)
+
+
+def cmd_index(args) -> int:
+    index = MinimizerIndex(load_records(args.files), k=args.k, w=args.w)
+    index.save(args.output)
+    stats = index.stats()
+    print(f"indexed {stats['bases']} bases in {stats['references']} references: {stats['positions']} minimizer "
+          f"positions ({stats['minimizers']} distinct), k={stats['k']} w={stats['w']}", file=sys.stderr)
+    return 0
+
+
+def cmd_map(args) -> int:
+    mapper = load_mapper(args)
+    sys.stdout.write(sam_header(mapper.references))
+
+    if len(args.files) == 2 or args.interleaved:
+        handles = [open(path) for path in args.files[:2]] if args.files else [sys.stdin]
+        try:
+            pairs = list(read_pairs(*handles))
+        finally:
+            for handle in handles:
+                if handle is not sys.stdin:
+                    handle.close()
+        hits = [(mapper.map_read(r1.sequence), mapper.map_read(r2.sequence)) for r1, r2 in pairs]
+        insert_stats = estimate_insert_size(hits)
+        for (r1, r2), (hit1, hit2) in zip(pairs, hits):
+            print('\n'.join(sam_pair(r1, r2, hit1, hit2, is_proper_pair(hit1, hit2, insert_stats))))
+        summary = mapping_summary([hit for pair in hits for hit in pair])
+        print(f"insert size: median={insert_stats['median']} mean={insert_stats['mean']} "
+              f"sd={insert_stats['sd']} from {insert_stats['pairs']} pairs", file=sys.stderr)
+    else:
+        records = load_reads(args.files)
+        hits = [mapper.map_read(record.sequence) for record in records]
+        for record, hit in zip(records, hits):
+            print(sam_record(record, hit))
+        summary = mapping_summary(hits)
+
+    print(f"mapped {summary['mapped']}/{summary['reads']} reads ({summary['mapped_fraction']:.1%}), "
+          f"mean MAPQ {summary['mean_mapq']}", file=sys.stderr)
+    return 0
+
+
+def cmd_umi(args) -> int:
+    records = []
+    for path in args.files or ['-']:
+        if path == '-':
+            records.extend(read_fastq(sys.stdin))
+        else:
+            with open(path) as handle:
+                records.extend(read_fastq(handle))
+    tagged, report = extract_umis(records, args.pattern)
+    sys.stdout.write(format_fastq(tagged))
+    print(f"tagged {report['reads']} reads with {report['distinct_umis']} distinct UMIs, "
+          f"{report['too_short']} too short", file=sys.stderr)
+    return 0
+
+
+def cmd_dedup(args) -> int:
+    mapper = load_mapper(args)
+    records = load_reads(args.files)
+    kept, report = deduplicate((record, mapper.map_read(record.sequence)) for record in records)
+
+    sys.stdout.write(sam_header(mapper.references))
+    for record, hit in kept:
+        print(sam_record(record, hit))
+    print(f"kept {report['unique']}/{report['mapped']} mapped reads ({report['unmapped']} unmapped), "
+          f"duplication {report['duplication_rate']:.1%} by position+UMI, "
+          f"{report['position_only_duplication_rate']:.1%} by position alone", file=sys.stderr)
+    return 0
+
+
+def cmd_call(args) -> int:
+    mapper = load_mapper(args)
+    mapped = [(record.sequence, mapper.map_read(record.sequence)) for record in load_reads(args.files)]
+
+    variants = []
+    depth_lines = []
+    for reference in mapper.references:
+        columns = pileup(reference, mapped)
+        variants.extend(call_variants(reference.name, columns, args.min_depth, args.min_frequency))
+        depths = coverage(columns)
+        depth_lines.extend(f'{reference.name}\t{i + 1}\t{depth}' for i, depth in enumerate(depths))
+        summary = coverage_summary(depths)
+        print(f"{reference.name}: mean depth {summary['mean_depth']}, "
+              f"{summary['covered_fraction']:.1%} covered", file=sys.stderr)
+
+    if args.coverage:
+        with open(args.coverage, 'w') as handle:
+            handle.write('\n'.join(depth_lines) + '\n')
+    sys.stdout.write(format_vcf(variants, mapper.references))
+    return 0
+
+
+def cmd_align(args) -> int:
+    records = load_records(args.files)
+    if len(records) < 2:
+        print("Error: align needs two sequences", file=sys.stderr)
+        return 1
+
+    matrix = None
+    if args.matrix:
+        matrix = open(args.matrix).read() if os.path.isfile(args.matrix) else args.matrix
+    scoring = ScoringScheme(match=args.match, mismatch=args.mismatch, gap=args.gap, matrix=matrix)
+    if args.free_ends and not args.local:
+        alignment = semiglobal_align(records[0].sequence, records[1].sequence, scoring, args.free_ends, progress_bar())
+    elif args.band is not None and not args.local:
+        alignment = banded_global_align(records[0].sequence, records[1].sequence, scoring, args.band, progress_bar())
+    else:
+        align = local_align if args.local else global_align
+        alignment = align(records[0].sequence, records[1].sequence, scoring, progress_bar())
+    print(format_alignment(alignment, records[0].name, records[1].name))
+    return 0
+
+
+def cmd_fold(args) -> int:
+    for record in load_records(args.files):
+        ensemble = partition_function(record.sequence, args.temperature, args.constraint, progress_bar())
+        print(f'>{record.name}\n{ensemble.sequence}\n{ensemble.centroid()} ({ensemble.free_energy:.2f})')
+    return 0
+
+
+def cmd_simulate(args) -> int:
+    seed = resolve_seed(args.seed)
+    rng = random.Random(seed)
+    if args.tree:
+        root = random_sequence(args.length, args.gc, rng)
+        leaves = evolve_along_tree(parse_newick(args.tree), root, args.kappa, args.indel_rate, seed=rng)
+        records = [SequenceRecord(name, sequence) for name, sequence in leaves.items()]
+    else:
+        records = [SequenceRecord(f'random_{i + 1}', random_sequence(args.length, args.gc, rng))
+                   for i in range(args.count)]
+
+    if args.reads:
+        reads = []
+        for record in records:
+            reads.extend(simulate_reads(record.sequence, args.reads, args.read_length, args.error_rate,
+                                        seed=rng, prefix=record.name.replace(' ', '_')))
+        sys.stdout.write(format_fastq(reads))
+    else:
+        sys.stdout.write(format_fasta(records))
+    write_manifest(args, seed, **({'tree': args.tree} if args.tree else {}))
+    return 0
+
+
+def cmd_faidx(args) -> int:
+    if not args.regions:
+        write_index(build_index(args.fasta), args.fasta + '.fai')
+        return 0
+    with IndexedFasta(args.fasta) as fasta:
+        records = []
+        for region in args.regions:
+            try:
+                records.append(SequenceRecord(region, fasta.fetch_region(region)))
+            except KeyError as e:
+                print(f"Error: {e.args[0]}", file=sys.stderr)
+                return 1
+        sys.stdout.write(format_fasta(records))
+    return 0
+
+
+def cmd_features(args) -> int:
+    with open(args.annotations) as handle:
+        text = handle.read()
+    fmt = args.format or ('bed' if args.annotations.lower().endswith('.bed') else 'gff3')
+    trees = {seqid: IntervalTree(annotations)
+             for seqid, annotations in (read_bed(text) if fmt == 'bed' else read_gff3(text)).items()}
+
+    per_record = []
+    for region in args.regions:
+        seqid, start, end = parse_region(region, trees)
+        if seqid not in trees:
+            print(f"Error: no annotations on \"{seqid}\"", file=sys.stderr)
+            return 1
+        tree = trees[seqid]
+        hits = tree.overlapping(start or 0, end if end is not None else max(a.end for a in tree.annotations) + 1)
+        per_record.append((seqid, hits))
+    write_annotations(per_record, fmt)
+    return 0
+
+
+def cmd_complex(args) -> int:
+    lines = args.complexes or [line for line in sys.stdin.read().splitlines() if line.strip()]
+    blocks = []
+    for line in lines:
+        complex_ = parse_kernel(line)
+        if args.format == 'all':
+            # Notations the complex has no form in (null) are left out
+            rendered = complex_.to_dict()
+            blocks.append('\n'.join(rendered[name] for name in ('kernel', 'dsd', 'ascii') if rendered[name]))
+        else:
+            blocks.append({'kernel': complex_.kernel, 'dsd': complex_.dsd, 'ascii': complex_.ascii}[args.format]())
+    print(('\n' if args.format in ('kernel', 'dsd') else '\n\n').join(blocks))
+    return 0
+
+
+def cmd_enumerate(args) -> int:
+    text = ''.join(sys.stdin.read() if path == '-' else open(path).read() for path in args.files or ['-'])
+    lengths = {}
+    for item in args.length:
+        name, _, length = item.partition('=')
+        if not length.isdigit():
+            print(f"Error: --length takes NAME=NT, got \"{item}\"", file=sys.stderr)
+            return 1
+        lengths[name] = int(length)
+    enumeration = enumerate_reactions(text, args.semantics, lengths, args.release_cutoff, args.max_complex_size,
+                                      args.max_species, not args.no_remote, args.leak)
+    if args.format == 'text':
+        sys.stdout.write(enumeration.format())
+    else:
+        crn = CRN.from_enumeration(enumeration)
+        formats = {'dot': crn.to_dot, 'graphml': crn.to_graphml, 'sbml': lambda: to_sbml(crn, model='dsd')}
+        sys.stdout.write(formats[args.format]())
+    return 0
+
+
+def cmd_kinetics(args) -> int:
+    text = ''.join(sys.stdin.read() if path == '-' else open(path).read() for path in args.files or ['-'])
+    crn = read_crn(text)
+    initial = dict(crn.amounts)
+    for item in args.initial:
+        name, _, amount = item.partition('=')
+        try:
+            initial[name] = float(amount)
+        except ValueError:
+            print(f"Error: --initial takes NAME=AMOUNT, got \"{item}\"", file=sys.stderr)
+            return 1
+    if not initial:
+        print('Error: give initial amounts with --initial NAME=AMOUNT', file=sys.stderr)
+        return 1
+    seed = resolve_seed(args.seed) if args.method == 'ssa' else None
+    course = simulate(crn, initial, args.t_end, args.method, args.points, args.rate, seed)
+    sys.stdout.write(course.to_svg(args.species) if args.format == 'svg' else course.to_csv(args.species))
+    write_manifest(args, seed, crn=text)
+    return 0
+
+
+def cmd_crn(args) -> int:
+    crn = read_crn(''.join(sys.stdin.read() if path == '-' else open(path).read() for path in args.files or ['-']))
+    if args.format == 'text':
+        sys.stdout.write(crn.format())
+        return 0
+    amounts = {}
+    for item in args.initial:
+        name, _, amount = item.partition('=')
+        try:
+            amounts[name] = float(amount)
+        except ValueError:
+            print(f"Error: --initial takes NAME=AMOUNT, got \"{item}\"", file=sys.stderr)
+            return 1
+    sys.stdout.write(to_sbml(crn, amounts, args.model, args.rate))
+    return 0
+
+
+def _parse_vary(item: str) -> SweepParameter:
+    """KIND:TARGET=START:STOP:STEPS[:log] or KIND:TARGET=V1,V2,..."""
+    name, _, spec = item.partition('=')
+    kind, _, target = name.partition(':')
+    if not spec or not target:
+        raise ValueError(f'--vary takes KIND:TARGET=START:STOP:STEPS[:log] or KIND:TARGET=V1,V2,..., got "{item}"')
+    parts = spec.split(':')
+    if len(parts) == 1:
+        return SweepParameter(kind, target, [float(value) for value in parts[0].split(',')])
+    if len(parts) not in (3, 4) or parts[3:] not in ([], ['log']):
+        raise ValueError(f'Bad range in --vary "{item}"')
+    return SweepParameter(kind, target, sweep_values(float(parts[0]), float(parts[1]), int(parts[2]),
+                                                     parts[3:] == ['log']))
+
+
+def cmd_sweep(args) -> int:
+    text = ''.join(sys.stdin.read() if path == '-' else open(path).read() for path in args.files or ['-'])
+    initial = {}
+    for item in args.initial:
+        name, _, amount = item.partition('=')
+        initial[name] = float(amount)
+    seed = resolve_seed(args.seed) if args.method == 'ssa' else None
+    sweep = ParameterSweep([_parse_vary(item) for item in args.vary], args.output, initial, args.t_end,
+                           crn='' if args.dsd else text, complexes=text if args.dsd else '', metric=args.metric,
+                           method=args.method, points=args.points, rate=args.rate, seed=seed,
+                           workers=args.workers)
+    result = sweep.run()
+    for error in result.errors:
+        print(f'Warning: {error}', file=sys.stderr)
+    sys.stdout.write(result.to_svg() if args.format == 'svg' else result.to_csv())
+    write_manifest(args, seed, **{'complexes' if args.dsd else 'crn': text})
+    return 0
+
+
+PIPELINE_EXTENSIONS = {'fasta': 'fa', 'pileup': 'pileup.tsv'}
+
+
+def cmd_pipeline(args) -> int:
+    with open(args.pipeline) as handle:
+        pipeline = parse_pipeline(handle.read())
+    inputs = {}
+    for item in args.input:
+        name, _, path = item.partition('=')
+        if not path:
+            print(f"Error: --input takes NAME=FILE, got \"{item}\"", file=sys.stderr)
+            return 1
+        with open(path) as handle:
+            inputs[name] = sequence_artifact(name, handle.read())
+    os.makedirs(args.outdir, exist_ok=True)
+
+    def save(step, artifact):
+        path = os.path.join(args.outdir, f'{step.id}.{PIPELINE_EXTENSIONS.get(artifact.format, artifact.format)}')
+        with open(path, 'w') as handle:
+            handle.write(artifact.text)
+        print(f'{step.id}\t{step.analysis}\t{path}\t{json.dumps(artifact.summary, default=str)}')
+
+    pipeline.run(inputs, save)
+    return 0
+
+
+def file_sketches(paths: List[str], k: int, size: int, per_record: bool) -> List[Sketch]:
+    """One sketch per file (a read set) or per record; .json files are sketches saved by cli.py sketch"""
+    sketches = []
+    for path in paths or ['-']:
+        if path.endswith('.json'):
+            with open(path) as handle:
+                sketches.extend(load_sketches(handle.read()))
+        elif per_record:
+            sketches.extend(Sketch.from_sequences([record.sequence], k, size, name=record.name)
+                            for record in load_reads([path]))
+        else:
+            sketches.append(Sketch.from_sequences((record.sequence for record in load_reads([path])), k, size,
+                                                  name=path))
+    return sketches
+
+
+def cmd_sketch(args) -> int:
+    sys.stdout.write(dump_sketches(file_sketches(args.files, args.k, args.size, args.per_record)))
+    return 0
+
+
+def cmd_compare(args) -> int:
+    sketches = file_sketches(args.files, args.k, args.size, args.per_record)
+    if len(sketches) < 2:
+        print("Error: compare needs at least two sketches", file=sys.stderr)
+        return 1
+    print("query\tsubject\tjaccard\tcontainment\tdistance")
+    for row in compare_sketches(sketches):
+        print(f"{row['query']}\t{row['subject']}\t{row['jaccard']}\t{row['containment']}\t{row['distance']}")
+    return 0
+
+
+def plugin_command(analysis: Analysis):
+    """Handler running an analysis plugin on every record, one JSON result per line"""
+
+    def handler(args) -> int:
+        params = analysis.parse_params({param.name: getattr(args, param.name) for param in analysis.params})
+        for record in load_records(args.files):
+            print(json.dumps(analysis.run(record, params)))
+        return 0
+
+    return handler
+
+
+def build_parser() -> argparse.ArgumentParser:
+    parser = argparse.ArgumentParser(prog='robin', description='Sequence utilities for the oligo designer')
+    subparsers = parser.add_subparsers(dest='command', required=True)
+
+    def add_command(name: str, handler, help_text: str) -> argparse.ArgumentParser:
+        sub = subparsers.add_parser(name, help=help_text)
+        sub.add_argument('files', nargs='*', help="Input files (FASTA or raw); stdin if omitted or '-'")
+        sub.set_defaults(handler=handler)
+        return sub
+
+    add_command('revcomp', cmd_revcomp, 'Reverse complement sequences')
+    add_command('gc', cmd_gc, 'Report length and GC content')
+
+    sub = add_command('translate', cmd_translate, 'Translate sequences to protein')
+    sub.add_argument('--frame', type=int, choices=[0, 1, 2], default=0, help='Reading frame, default: 0')
+    sub.add_argument('--to-stop', action='store_true', help='Stop translation at the first stop codon')
+
+    sub = add_command('orf', cmd_orf, 'Find open reading frames')
+    sub.add_argument('--min-length', type=int, default=30, help='Minimum ORF length (nt), default: 30')
+    sub.add_argument('--forward-only', action='store_true', help='Only search the forward strand')
+    sub.add_argument('--format', choices=['tsv', 'gff3', 'bed'], default='tsv', help='Output format, default: tsv')
+
+    sub = add_command('repeats', cmd_repeats, 'Find homopolymers, tandem and inverted repeats')
+    sub.add_argument('--min-homopolymer', type=int, default=6, help='Minimum homopolymer run, default: 6')
+    sub.add_argument('--min-copies', type=int, default=4, help='Minimum tandem repeat copies, default: 4')
+    sub.add_argument('--min-stem', type=int, default=6, help='Minimum inverted repeat stem, default: 6')
+    sub.add_argument('--format', choices=['tsv', 'gff3', 'bed'], default='tsv', help='Output format, default: tsv')
+
+    sub = add_command('digest', cmd_digest, 'Digest sequences with restriction enzymes')
+    sub.add_argument('--enzymes', '-e', required=True,
+                     help=f"Comma-separated enzyme names ({', '.join(sorted(ENZYMES))})")
+    sub.add_argument('--circular', action='store_true',
+                     help='Treat sequences as circular (plasmids); the fragment across the origin has end < start')
+    sub.add_argument('--methylation', '-m', metavar='MODELS',
+                     help=f"Comma-separated active methylation ({', '.join(METHYLATION)}), e.g. dam,dcm for "
+                          f"plasmid DNA from E. coli; '' for none. Blocked sites are not cut")
+    sub.add_argument('--rebase', metavar='FILE', help='REBASE withrefm or bairoch file adding to the enzyme catalog')
+
+    sub = add_command('silent', cmd_silent, 'Suggest synonymous codon changes that add or remove a restriction site')
+    sub.add_argument('--enzyme', '-e', required=True, choices=sorted(ENZYMES), help='Enzyme whose site to change')
+    sub.add_argument('--mode', choices=['introduce', 'remove'], default='introduce', help='default: introduce')
+    sub.add_argument('--max-changes', type=int, default=3, help='Maximum nucleotide changes, default: 3')
+    sub.add_argument('--limit', type=int, default=20, help='Maximum suggestions per sequence, default: 20')
+
+    sub = add_command('assemble', cmd_assemble, 'Assemble reads (FASTQ or FASTA) into contigs')
+    sub.add_argument('--algo', choices=['dbg', 'olc'], default='dbg',
+                     help='de Bruijn graph (short reads) or overlap-layout-consensus (few long reads), default: dbg')
+    sub.add_argument('-k', type=int, default=31, help='k-mer size for dbg, default: 31')
+    sub.add_argument('--min-count', type=int, default=2, help='Minimum k-mer count for dbg, default: 2')
+    sub.add_argument('--min-overlap', type=int, default=20, help='Minimum overlap for olc, default: 20')
+    sub.add_argument('--min-identity', type=float, default=90.0, help='Minimum overlap identity %% for olc, default: 90')
+    sub.add_argument('--min-contig-length', type=int, default=0, help='Drop shorter contigs, default: 0')
+
+    sub = add_command('trim', cmd_trim, 'Adapter and quality trim FASTQ reads')
+    sub.add_argument('--adapter', '-a', action='append', default=[], help='Adapter sequence (repeatable)')
+    sub.add_argument('--window', type=int, default=4, help='Quality window size, default: 4')
+    sub.add_argument('--min-quality', type=float, default=20.0, help='Minimum mean window quality, default: 20')
+    sub.add_argument('--min-length', type=int, default=30, help='Drop reads shorter than this, default: 30')
+    sub.add_argument('--max-length', type=int, default=None, help='Drop reads longer than this')
+
+    sub = add_command('demux', cmd_demux, 'Split FASTQ reads into per-sample files by barcode')
+    sub.add_argument('--barcodes', '-b', required=True, help='Sample sheet: one "name sequence" pair per line')
+    sub.add_argument('--outdir', '-o', default='demux', help='Directory for SAMPLE.fastq files, default: demux')
+    sub.add_argument('--mismatches', '-m', type=int, default=1, help='Mismatches allowed per barcode, default: 1')
+    sub.add_argument('--location', choices=['read', 'header'], default='read',
+                     help='Barcode at the start of each read, or the index in Illumina headers, default: read')
+    sub.add_argument('--no-trim', action='store_true', help='Keep in-read barcodes on the reads')
+
+    sub = add_command('screen', cmd_screen, 'Report reads containing adapter, primer or vector sequences')
+    sub.add_argument('--contaminants', '-c', action='append', default=[],
+                     help='FASTA of extra contaminant sequences (repeatable)')
+    sub.add_argument('--no-bundled', action='store_true', help='Screen only against --contaminants sequences')
+    sub.add_argument('-k', type=int, default=12, help='K-mer length, default: 12')
+    sub.add_argument('--min-hits', type=int, default=1, help='Shared k-mers needed to call a read, default: 1')
+
+    sub = add_command('grep', cmd_grep, 'Find a primer or adapter allowing substitutions and indels (Myers bit-vector)')
+    sub.add_argument('--pattern', '-p', required=True, help='Pattern to find, IUPAC codes allowed')
+    sub.add_argument('--max-errors', '-e', type=int, default=1, help='Edits allowed per match, default: 1')
+    sub.add_argument('--forward-only', action='store_true', help='Skip the reverse complement of the pattern')
+
+    sub = add_command('search', cmd_search, 'Seeded homology search of query sequences against FASTA files (BLAST tabular)')
+    sub.add_argument('--query', '-q', required=True, help='Query FASTA')
+    sub.add_argument('-k', type=int, default=11, help='Seed k-mer size, default: 11')
+    sub.add_argument('--evalue', '-e', type=float, default=10.0, help='Maximum E-value, default: 10')
+    sub.add_argument('--max-hits', type=int, default=50, help='Hits per query, default: 50')
+
+    sub = add_command('index', cmd_index, 'Build a minimizer index of reference FASTA files for map, dedup and call')
+    sub.add_argument('--output', '-o', required=True, help='Index file to write')
+    sub.add_argument('-k', type=int, default=15, help='Seed k-mer size, default: 15')
+    sub.add_argument('-w', type=int, default=10, help='Minimizer window in k-mers, default: 10')
+
+    sub = add_command('map', cmd_map, 'Map reads to a reference and write SAM (two files = paired-end R1 R2)')
+    sub.add_argument('--reference', '-r', required=True, help='Reference FASTA, or an index saved by cli.py index')
+    sub.add_argument('-k', type=int, default=15, help='Seed k-mer size, default: 15')
+    sub.add_argument('-w', type=int, default=10, help='Minimizer window in k-mers, default: 10')
+    sub.add_argument('--interleaved', action='store_true', help='Single FASTQ with mates interleaved')
+
+    sub = add_command('umi', cmd_umi, 'Move UMIs from read prefixes into read IDs')
+    sub.add_argument('--pattern', '-p', required=True,
+                     help='Prefix layout: N for UMI bases, X for bases kept on the read (e.g. NNNNNNNN)')
+
+    sub = add_command('dedup', cmd_dedup, 'Map UMI-tagged reads and keep one per position and UMI, as SAM')
+    sub.add_argument('--reference', '-r', required=True, help='Reference FASTA, or an index saved by cli.py index')
+    sub.add_argument('-k', type=int, default=15, help='Seed k-mer size, default: 15')
+    sub.add_argument('-w', type=int, default=10, help='Minimizer window in k-mers, default: 10')
+
+    sub = add_command('call', cmd_call, 'Map reads, build a pileup and call variants as VCF')
+    sub.add_argument('--reference', '-r', required=True, help='Reference FASTA, or an index saved by cli.py index')
+    sub.add_argument('-k', type=int, default=15, help='Seed k-mer size, default: 15')
+    sub.add_argument('-w', type=int, default=10, help='Minimizer window in k-mers, default: 10')
+    sub.add_argument('--min-depth', type=int, default=10, help='Minimum depth to call, default: 10')
+    sub.add_argument('--min-frequency', type=float, default=0.2, help='Minimum allele frequency, default: 0.2')
+    sub.add_argument('--coverage', help='Also write per-position depth (TSV) to this file')
+
+    sub = subparsers.add_parser('simulate', help='Generate random sequences, evolve them along a tree or sample reads')
+    sub.set_defaults(handler=cmd_simulate)
+    sub.add_argument('--length', type=int, default=1000, help='Sequence length (nt), default: 1000')
+    sub.add_argument('--gc', type=float, default=0.5, help='Expected GC fraction, default: 0.5')
+    sub.add_argument('--count', type=int, default=1, help='Random sequences to generate without --tree, default: 1')
+    sub.add_argument('--seed', type=int, default=None, help='Random seed for reproducible output; see --manifest')
+    sub.add_argument('--tree', help='Newick tree; evolve a random root sequence to its leaves')
+    sub.add_argument('--kappa', type=float, default=2.0, help='Transition/transversion rate ratio, default: 2')
+    sub.add_argument('--indel-rate', type=float, default=0.0, help='Indels per site per unit branch length, default: 0')
+    sub.add_argument('--reads', type=int, default=0, help='Write this many FASTQ reads per sequence instead')
+    sub.add_argument('--read-length', type=int, default=100, help='Simulated read length, default: 100')
+    sub.add_argument('--error-rate', type=float, default=0.0, help='Per-base substitution error rate, default: 0')
+    sub.add_argument('--manifest', metavar='FILE',
+                     help='Write the version, parameters, seed and input hashes of the run to FILE as JSON')
+
+    sub = subparsers.add_parser('faidx', help='Index a FASTA file (.fai) or fetch regions from it')
+    sub.set_defaults(handler=cmd_faidx)
+    sub.add_argument('fasta', help='FASTA file; the index is read from or written to FASTA.fai')
+    sub.add_argument('regions', nargs='*', help="Regions to fetch, e.g. chr1 or chr1:1001-2000 (1-based, inclusive)")
+
+    sub = subparsers.add_parser('features', help='Print the GFF3/BED features overlapping regions')
+    sub.set_defaults(handler=cmd_features)
+    sub.add_argument('annotations', help='GFF3 or BED file')
+    sub.add_argument('regions', nargs='+', help="Regions, e.g. chr1:1001-2000 or chr1:1500-1500 (1-based, inclusive)")
+    sub.add_argument('--format', choices=['gff3', 'bed'], help='Annotation format, default: from the file extension')
+
+    sub = subparsers.add_parser('complex', help='Print domain-level complexes in kernel, Visual DSD and ASCII notation')
+    sub.set_defaults(handler=cmd_complex)
+    sub.add_argument('complexes', nargs='*', help="Complexes in kernel notation, e.g. 't( x( + ) )'; one per stdin "
+                                                  "line if omitted")
+    sub.add_argument('--format', choices=['all', 'kernel', 'dsd', 'ascii'], default='all',
+                     help='Notation to print, default: all')
+
+    sub = add_command('enumerate', cmd_enumerate, "Enumerate the reactions of DSD complexes ('name = kernel' lines)")
+    sub.add_argument('--semantics', choices=['condensed', 'infinite'], default='condensed',
+                     help='condensed: reactions between resting states; infinite: every step. Default: condensed')
+    sub.add_argument('--length', action='append', default=[], metavar='NAME=NT',
+                     help='Domain length; default 15 nt, or 6 for toeholds marked t^. Repeatable')
+    sub.add_argument('--release-cutoff', type=int, default=DEFAULT_RELEASE_CUTOFF,
+                     help=f'Longest helix (nt) that opens spontaneously, default: {DEFAULT_RELEASE_CUTOFF}')
+    sub.add_argument('--max-complex-size', type=int, default=6, help='Most strands in one complex, default: 6')
+    sub.add_argument('--max-species', type=int, default=200, help='Stop with an error past this many, default: 200')
+    sub.add_argument('--no-remote', action='store_true', help='Only branch-migrate into adjacent helices')
+    sub.add_argument('--leak', action='store_true',
+                     help='Also list zero-toehold and blunt-end leaks between the input complexes, worst offenders last')
+    sub.add_argument('--format', choices=['text', 'dot', 'graphml', 'sbml'], default='text',
+                     help='Listing, or the reaction network as a Graphviz DOT or GraphML graph or an SBML model. '
+                          'Default: text')
+
+    sub = add_command('kinetics', cmd_kinetics, "Simulate a CRN ('A + B -> C [rate]' lines, or SBML) over time")
+    sub.add_argument('--initial', action='append', default=[], metavar='NAME=AMOUNT',
+                     help='Initial concentration (ode) or count (ssa) of a species, over any an SBML model gives. '
+                          'Repeatable')
+    sub.add_argument('--t-end', type=float, required=True, help='End time, in the time unit of the rates')
+    sub.add_argument('--method', choices=['ode', 'ssa'], default='ode', help='Mass-action ODEs or Gillespie SSA, '
+                                                                             'default: ode')
+    sub.add_argument('--points', type=int, default=200, help='Evenly spaced samples, default: 200')
+    sub.add_argument('--rate', type=float, default=1.0, help='Rate of reactions that do not give one, default: 1')
+    sub.add_argument('--seed', type=int, help='Random seed for ssa')
+    sub.add_argument('--species', action='append', help='Species to output; all if omitted. Repeatable')
+    sub.add_argument('--format', choices=['csv', 'svg'], default='csv', help='CSV table or SVG chart, default: csv')
+    sub.add_argument('--manifest', metavar='FILE',
+                     help='Write the version, parameters, seed and input hashes of the run to FILE as JSON')
+
+    sub = add_command('crn', cmd_crn, "Convert a CRN between 'A + B -> C [rate]' lines and SBML")
+    sub.add_argument('--format', choices=['sbml', 'text'], default='sbml',
+                     help='SBML Level 3 model, or reaction lines as kinetics reads them. Default: sbml')
+    sub.add_argument('--initial', action='append', default=[], metavar='NAME=AMOUNT',
+                     help='Initial concentration of a species in the SBML model. Repeatable')
+    sub.add_argument('--model', default='crn', help='SBML model id, default: crn')
+    sub.add_argument('--rate', type=float, default=1.0, help='Rate of reactions that do not give one, default: 1')
+
+    sub = add_command('sweep', cmd_sweep, 'Simulate a CRN, or DSD complexes with --dsd, over one or two parameters')
+    sub.add_argument('--vary', action='append', required=True, metavar='KIND:TARGET=RANGE',
+                     help='initial:SPECIES, rate:N or toehold:DOMAIN, over START:STOP:STEPS[:log] or V1,V2,...; '
+                          'once or twice')
+    sub.add_argument('--output', required=True, help='Species to measure; for --dsd an input name or a kernel')
+    sub.add_argument('--metric', choices=['final', 'max', 'half_time'], default='final',
+                     help='What to measure of the output, default: final')
+    sub.add_argument('--initial', action='append', default=[], metavar='NAME=AMOUNT',
+                     help='Initial concentration (ode) or count (ssa) of a species, over any an SBML model gives. '
+                          'Repeatable')
+    sub.add_argument('--t-end', type=float, required=True, help='End time, in the time unit of the rates')
+    sub.add_argument('--dsd', action='store_true', help="Input is 'name = kernel' complexes, enumerated with model "
+                                                       "rates")
+    sub.add_argument('--method', choices=['ode', 'ssa'], default='ode', help='Simulation method, default: ode')
+    sub.add_argument('--points', type=int, default=200, help='Samples per simulation, default: 200')
+    sub.add_argument('--rate', type=float, default=1.0, help='Rate of reactions that do not give one, default: 1')
+    sub.add_argument('--seed', type=int, help='Random seed for ssa')
+    sub.add_argument('--workers', type=int, help='Worker processes; all CPUs if omitted')
+    sub.add_argument('--format', choices=['csv', 'svg'], default='csv', help='CSV grid or SVG heatmap, default: csv')
+    sub.add_argument('--manifest', metavar='FILE',
+                     help='Write the version, parameters, seed and input hashes of the run to FILE as JSON')
+
+    sub = add_command('fold', cmd_fold, 'Fold each sequence: centroid structure and ensemble free energy (kcal/mol)')
+    sub.add_argument('--temperature', type=float, default=37.0, help='Temperature in °C, default: 37')
+    sub.add_argument('--constraint', help='x unpaired, | paired, () forced pair, . free; one per base')
+
+    sub = add_command('align', cmd_align, 'Align the first two sequences')
+    sub.add_argument('--local', action='store_true', help='Local (Smith-Waterman) instead of global alignment')
+    sub.add_argument('--match', type=int, default=2, help='Match score, default: 2')
+    sub.add_argument('--mismatch', type=int, default=-1, help='Mismatch score, default: -1')
+    sub.add_argument('--gap', type=int, default=-2, help='Gap score, default: -2')
+    sub.add_argument('--matrix', help='Substitution matrix instead of --match/--mismatch: EDNAFULL, or a file in '
+                                      'NCBI/EMBOSS or JSON format (ambiguity codes it leaves out are derived)')
+    sub.add_argument('--free-ends', choices=['a', 'b', 'both'],
+                     help='Semi-global: no penalty for overhanging ends of the first sequence (a), the second (b) '
+                          'or either (both)')
+    sub.add_argument('--band', type=int, help='Global alignment only within this many diagonals of the main one '
+                                              '(beyond the length difference), for long similar sequences')
+
+    for name, handler, help_text in (
+            ('sketch', cmd_sketch, 'MinHash sketch of each file (or record) as JSON, for fast comparison'),
+            ('compare', cmd_compare, 'Jaccard, containment and Mash distance between sequence files or saved '
+                                     'sketches')):
+        sub = add_command(name, handler, help_text)
+        sub.add_argument('-k', type=int, default=21, help='K-mer length, default: 21')
+        sub.add_argument('--size', '-s', type=int, default=1000, help='Hashes kept per sketch, default: 1000')
+        sub.add_argument('--per-record', action='store_true', help='Sketch each record instead of each file')
+
+    sub = subparsers.add_parser('pipeline', help='Run a YAML or JSON pipeline of chained analyses, e.g. '
+                                                 'trim -> map -> pileup -> call')
+    sub.set_defaults(handler=cmd_pipeline)
+    sub.add_argument('pipeline', help='Pipeline file')
+    sub.add_argument('--input', action='append', default=[], metavar='NAME=FILE',
+                     help='FASTA or FASTQ file for a pipeline input. Repeatable')
+    sub.add_argument('-o', '--outdir', default='.', help="Directory for each step's output, named by step id, "
+                                                          "default: current directory")
+
+    # Analysis plugins (backend/plugins, ROBIN_PLUGINS) become subcommands with an option per parameter
+    for analysis in registry.discover():
+        if analysis.name in subparsers.choices:
+            continue
+        sub = add_command(analysis.name, plugin_command(analysis), analysis.description or analysis.title)
+        for param in analysis.params:
+            flag = f'-{param.name}' if len(param.name) == 1 else f'--{param.name.replace("_", "-")}'
+            help_text = param.description + (f', default: {param.default}' if param.default is not None else '')
+            if param.type == 'boolean':
+                sub.add_argument(flag, dest=param.name, default=param.default,
+                                 action=argparse.BooleanOptionalAction, help=param.description)
+            else:
+                sub.add_argument(flag, dest=param.name, default=param.default,
+                                 type=PARAM_TYPES[param.type], choices=param.choices, required=param.required,
+                                 help=help_text)
+
+    return parser
+
+
+def main() -> int:
+    """Main function for the robin CLI"""
+    args = build_parser().parse_args()
+    context = Context()
+
+    def interrupt(signum, frame):
+        # Cancel first so parallel work drops what it has not started, then unwind the main thread
+        context.cancel('Interrupted')
+        raise KeyboardInterrupt
+
+    signal.signal(signal.SIGINT, interrupt)
+    try:
+        with using(context):
+            return args.handler(args)
+    except (OSError, ValueError) as e:
+        print(f"Error: {e}", file=sys.stderr)
+        return 1
+    except (Cancelled, KeyboardInterrupt):
+        print("Interrupted", file=sys.stderr)
+        return 130
+
+
+if __name__ == '__main__':
+    sys.exit(main())
@@ -0,0 +1,395 @@
+from dataclasses import dataclass
+from typing import Optional
+from .context import check_cancelled
+from .matrices import SubstitutionMatrix, load_matrix
+from .progress import Progress
+from .sequence import clean_sequence
+
+
+@dataclass
+class ScoringScheme:
+    """Linear gap scoring scheme for pairwise alignment
+
+    Pairs score match/mismatch unless a substitution matrix is given (a SubstitutionMatrix, a
+    built-in name such as 'EDNAFULL', or matrix text), in which case the matrix decides.
+    """
+    match: int = 2
+    mismatch: int = -1
+    gap: int = -2
+    matrix: Optional[SubstitutionMatrix] = None
+
+    def __post_init__(self):
+        if self.matrix is not None:
+            self.matrix = load_matrix(self.matrix)
+
+    def score(self, a: str, b: str) -> int:
+        if self.matrix is not None:
+            return self.matrix.score(a, b)
+        return self.match if a == b else self.mismatch
+
+    def max_score(self) -> int:
+        return self.matrix.maximum if self.matrix is not None else self.match
+
+    def min_score(self) -> int:
+        return self.matrix.minimum if self.matrix is not None else self.mismatch
+
+
+@dataclass
+class Alignment:
+    """Pairwise alignment of two sequences"""
+    aligned_a: str
+    aligned_b: str
+    score: int
+    start_a: int = 0
+    end_a: int = 0
+    start_b: int = 0
+    end_b: int = 0
+
+    @property
+    def identity(self) -> float:
+        """Percentage of alignment columns that are identical"""
+        if not self.aligned_a:
+            return 0.0
+        matches = sum(1 for a, b in zip(self.aligned_a, self.aligned_b) if a == b and a != '-')
+        return (matches / len(self.aligned_a)) * 100
+
+    def midline(self) -> str:
+        """Match line between the aligned sequences ('|' for identities)"""
+        return ''.join('|' if a == b and a != '-' else ' ' for a, b in zip(self.aligned_a, self.aligned_b))
+
+
+def _fill_matrix(seq_a: str, seq_b: str, scoring: ScoringScheme, local: bool, progress: Progress = None,
+                 free_a: bool = False, free_b: bool = False):
+    """Fill the dynamic programming matrix and return it with its best cell
+
+    free_a and free_b make gaps against the overhanging ends of seq_a or seq_b free (semi-global).
+    """
+    rows, cols = len(seq_a) + 1, len(seq_b) + 1
+    matrix = [[0] * cols for _ in range(rows)]
+
+    if not local:
+        for i in range(1, rows):
+            matrix[i][0] = 0 if free_a else i * scoring.gap
+        for j in range(1, cols):
+            matrix[0][j] = 0 if free_b else j * scoring.gap
+
+    best, best_cell = 0, (0, 0)
+    for i in range(1, rows):
+        check_cancelled()
+        if progress:
+            progress.update('align', i - 1, rows - 1)
+        for j in range(1, cols):
+            diagonal = matrix[i - 1][j - 1] + (
+                scoring.score(seq_a[i - 1], seq_b[j - 1]))
+            score = max(diagonal, matrix[i - 1][j] + scoring.gap, matrix[i][j - 1] + scoring.gap)
+            if local:
+                score = max(score, 0)
+                if score > best:
+                    best, best_cell = score, (i, j)
+            matrix[i][j] = score
+
+    if progress:
+        progress.update('align', rows - 1, rows - 1)
+    if not local:
+        # A free trailing overhang of seq_a lets the alignment end anywhere in the last column, of seq_b the last row
+        ends = [(rows - 1, cols - 1)]
+        if free_a:
+            ends.extend((i, cols - 1) for i in range(rows))
+        if free_b:
+            ends.extend((rows - 1, j) for j in range(cols))
+        best_cell = max(ends, key=lambda cell: matrix[cell[0]][cell[1]])
+    return matrix, best_cell
+
+
+def _traceback(seq_a: str, seq_b: str, matrix, cell, scoring: ScoringScheme, local: bool,
+               free_a: bool = False, free_b: bool = False) -> Alignment:
+    """Trace back through the matrix from cell to build the alignment"""
+    i, j = cell
+    end_a, end_b = i, j
+    aligned_a, aligned_b = [], []
+
+    while i > 0 or j > 0:
+        if local and matrix[i][j] == 0:
+            break
+        if (free_a and j == 0) or (free_b and i == 0):
+            break  # the rest is a free leading overhang
+        if i > 0 and j > 0 and matrix[i][j] == matrix[i - 1][j - 1] + (
+                scoring.score(seq_a[i - 1], seq_b[j - 1])):
+            aligned_a.append(seq_a[i - 1])
+            aligned_b.append(seq_b[j - 1])
+            i, j = i - 1, j - 1
+        elif i > 0 and matrix[i][j] == matrix[i - 1][j] + scoring.gap:
+            aligned_a.append(seq_a[i - 1])
+            aligned_b.append('-')
+            i -= 1
+        else:
+            aligned_a.append('-')
+            aligned_b.append(seq_b[j - 1])
+            j -= 1
+
+    return Alignment(
+        aligned_a=''.join(reversed(aligned_a)),
+        aligned_b=''.join(reversed(aligned_b)),
+        score=matrix[cell[0]][cell[1]],
+        start_a=i, end_a=end_a,
+        start_b=j, end_b=end_b
+    )
+
+
+def global_align(seq_a: str, seq_b: str, scoring: ScoringScheme = None, progress: Progress = None) -> Alignment:
+    """Needleman-Wunsch global alignment"""
+    scoring = scoring or ScoringScheme()
+    seq_a, seq_b = clean_sequence(seq_a), clean_sequence(seq_b)
+    matrix, cell = _fill_matrix(seq_a, seq_b, scoring, local=False, progress=progress)
+    return _traceback(seq_a, seq_b, matrix, cell, scoring, local=False)
+
+
+def local_align(seq_a: str, seq_b: str, scoring: ScoringScheme = None, progress: Progress = None) -> Alignment:
+    """Smith-Waterman local alignment"""
+    scoring = scoring or ScoringScheme()
+    seq_a, seq_b = clean_sequence(seq_a), clean_sequence(seq_b)
+    matrix, cell = _fill_matrix(seq_a, seq_b, scoring, local=True, progress=progress)
+    return _traceback(seq_a, seq_b, matrix, cell, scoring, local=True)
+
+
+def semiglobal_align(seq_a: str, seq_b: str, scoring: ScoringScheme = None, free_ends: str = 'both',
+                     progress: Progress = None) -> Alignment:
+    """End-gap-free (glocal) alignment: global, except that overhanging ends cost nothing
+
+    free_ends says whose overhangs are free: 'a' aligns all of seq_b within seq_a (a read
+    against a reference that contains it), 'b' the reverse, and 'both' also allows the two
+    to overlap end to end (an adapter running off the end of a read). The alignment covers
+    only the overlap; start/end give where it lies in each sequence.
+    """
+    if free_ends not in ('a', 'b', 'both'):
+        raise ValueError("free_ends must be 'a', 'b' or 'both'")
+    scoring = scoring or ScoringScheme()
+    seq_a, seq_b = clean_sequence(seq_a), clean_sequence(seq_b)
+    free_a, free_b = free_ends in ('a', 'both'), free_ends in ('b', 'both')
+    matrix, cell = _fill_matrix(seq_a, seq_b, scoring, False, progress, free_a, free_b)
+    return _traceback(seq_a, seq_b, matrix, cell, scoring, False, free_a, free_b)
+
+
+def banded_global_align(seq_a: str, seq_b: str, scoring: ScoringScheme = None, band: int = 16,
+                        progress: Progress = None) -> Alignment:
+    """Needleman-Wunsch restricted to a band of diagonals
+
+    Only cells within band diagonals of the main one (widened by the length difference) are
+    filled, so time and memory grow with length * band rather than length_a * length_b. The
+    result is the optimal global alignment whenever that alignment stays inside the band,
+    which it does when the sequences differ by at most band indels beyond their length
+    difference.
+    """
+    scoring = scoring or ScoringScheme()
+    seq_a, seq_b = clean_sequence(seq_a), clean_sequence(seq_b)
+    if band < 0:
+        raise ValueError('band must not be negative')
+    rows, cols = len(seq_a), len(seq_b)
+    below, above = band + max(0, rows - cols), band + max(0, cols - rows)  # allowed i - j and j - i
+    firsts, matrix = [], []
+
+    def cell(i: int, j: int) -> float:
+        offset = j - firsts[i]
+        return matrix[i][offset] if 0 <= offset < len(matrix[i]) else float('-inf')
+
+    for i in range(rows + 1):
+        check_cancelled()
+        if progress:
+            progress.update('align', i, rows)
+        first, last = max(0, i - below), min(cols, i + above)
+        firsts.append(first)
+        matrix.append([])
+        for j in range(first, last + 1):
+            if i == 0 or j == 0:
+                score = (i + j) * scoring.gap
+            else:
+                score = max(cell(i - 1, j - 1) + (scoring.score(seq_a[i - 1], seq_b[j - 1])),
+                            cell(i - 1, j) + scoring.gap, cell(i, j - 1) + scoring.gap)
+            matrix[i].append(score)
+
+    i, j = rows, cols
+    aligned_a, aligned_b = [], []
+    while i > 0 or j > 0:
+        if i > 0 and j > 0 and cell(i, j) == cell(i - 1, j - 1) + (
+                scoring.score(seq_a[i - 1], seq_b[j - 1])):
+            aligned_a.append(seq_a[i - 1])
+            aligned_b.append(seq_b[j - 1])
+            i, j = i - 1, j - 1
+        elif i > 0 and cell(i, j) == cell(i - 1, j) + scoring.gap:
+            aligned_a.append(seq_a[i - 1])
+            aligned_b.append('-')
+            i -= 1
+        else:
+            aligned_a.append('-')
+            aligned_b.append(seq_b[j - 1])
+            j -= 1
+    return Alignment(''.join(reversed(aligned_a)), ''.join(reversed(aligned_b)), int(cell(rows, cols)),
+                     0, rows, 0, cols)
+
+
+class _LaneVectors:
+    """SWAR arithmetic on many small unsigned scores packed into one Python int
+
+    Each lane is width bits: a guard bit on top of the value bits. Comparisons and saturating
+    subtraction work on all lanes at once by borrowing into the guard bits, the way SIMD
+    instructions do in hardware striped Smith-Waterman implementations.
+    """
+
+    def __init__(self, lanes: int, width: int):
+        self.lanes, self.width = lanes, width
+        self.ones = sum(1 << (lane * width) for lane in range(lanes))
+        self.guard = self.ones << (width - 1)
+        self.values = self.guard - self.ones
+        self.full = (1 << (lanes * width)) - 1
+
+    def pack(self, values) -> int:
+        return sum(value << (lane * self.width) for lane, value in enumerate(values))
+
+    def unpack(self, vector: int):
+        mask = (1 << self.width) - 1
+        return [(vector >> (lane * self.width)) & mask for lane in range(self.lanes)]
+
+    def max(self, a: int, b: int) -> int:
+        ge = ((a | self.guard) - b) & self.guard
+        mask = ge - (ge >> (self.width - 1))
+        return (a & mask) | (b & ~mask & self.values)
+
+    def sub(self, a: int, b: int) -> int:
+        """a - b per lane, floored at 0"""
+        difference = (a | self.guard) - b
+        keep = difference & self.guard
+        return difference & (keep - (keep >> (self.width - 1)))
+
+    def any_greater(self, a: int, b: int) -> bool:
+        return ((b | self.guard) - a) & self.guard != self.guard
+
+    def shift(self, vector: int) -> int:
+        """Each lane moved up one, lane 0 zeroed"""
+        return (vector << self.width) & self.full
+
+    def horizontal_max(self, vector: int) -> int:
+        """Largest lane, by folding the upper half of the lanes onto the lower half"""
+        span = self.lanes
+        while span > 1:
+            half = (span + 1) // 2
+            vector = self.max(vector, vector >> (half * self.width))
+            span = half
+        return vector & ((1 << self.width) - 1)
+
+
+def _striped_scan(query: str, database: str, scoring: ScoringScheme, lanes: int):
+    """Best local score and its end (end_query, end_database) by Farrar's striped Smith-Waterman
+
+    The query column is split into lanes interleaved segments (query position k + lane * segments
+    is segment k of that lane), so a whole segment of the DP column is one packed vector and a
+    column costs about segments vector operations. Vertical gaps crossing from one lane into the
+    next are fixed up afterwards by the lazy-F loop, which only goes on while such a gap still
+    beats the scores it reaches.
+    """
+    gap = -scoring.gap
+    bias = max(0, -scoring.min_score())
+    segments = -(-len(query) // lanes)
+    best_possible = scoring.max_score() * min(len(query), len(database))
+    vectors = _LaneVectors(lanes, (best_possible + scoring.max_score() + bias + gap).bit_length() + 1)
+    v_bias, v_gap = vectors.ones * bias, vectors.ones * gap
+
+    profile = {}
+    for base in set(database):
+        profile[base] = [vectors.pack(
+            scoring.score(query[k + lane * segments], base) + bias
+            if k + lane * segments < len(query) else 0 for lane in range(lanes)) for k in range(segments)]
+
+    previous, current, gaps_e = [0] * segments, [0] * segments, [0] * segments
+    v_max, last_max, best, best_column, end_database = 0, 0, 0, None, 0
+    # The vector operations are inlined in the inner loop; method calls would cost more than the arithmetic
+    guard, values, width = vectors.guard, vectors.values, vectors.width - 1
+    for j, base in enumerate(database):
+        check_cancelled()
+        scores = profile[base]
+        v_f = 0
+        v_h = vectors.shift(previous[-1])
+        for k in range(segments):
+            difference = ((v_h + scores[k]) | guard) - v_bias
+            keep = difference & guard
+            v_h = difference & (keep - (keep >> width))
+            for other in (gaps_e[k], v_f):
+                ge = ((v_h | guard) - other) & guard
+                mask = ge - (ge >> width)
+                v_h = (v_h & mask) | (other & ~mask & values)
+            current[k] = v_h
+            ge = ((v_max | guard) - v_h) & guard
+            mask = ge - (ge >> width)
+            v_max = (v_max & mask) | (v_h & ~mask & values)
+            # Linear gaps: a gap opened from this cell costs the same as extending one
+            difference = (v_h | guard) - v_gap
+            keep = difference & guard
+            gaps_e[k] = v_f = difference & (keep - (keep >> width))
+            v_h = previous[k]
+
+        v_f = vectors.shift(v_f)
+        k = 0
+        # A vertical gap entering a segment matters only where it beats the score already there
+        while vectors.any_greater(v_f, current[k]):
+            v_h = vectors.max(current[k], v_f)
+            current[k] = v_h
+            v_max = vectors.max(v_max, v_h)
+            gaps_e[k] = vectors.max(gaps_e[k], vectors.sub(v_h, v_gap))
+            v_f = vectors.sub(v_f, v_gap)
+            k += 1
+            if k == segments:
+                k, v_f = 0, vectors.shift(v_f)
+
+        if v_max != last_max:
+            last_max, column_best = v_max, vectors.horizontal_max(v_max)
+            if column_best > best:
+                best, best_column, end_database = column_best, list(current), j + 1
+        previous, current = current, previous
+
+    if not best:
+        return 0, 0, 0
+    for k, vector in enumerate(best_column):
+        for lane, value in enumerate(vectors.unpack(vector)):
+            if value == best:
+                return best, k + lane * segments + 1, end_database
+    return best, len(query), end_database
+
+
+def striped_local_align(seq_a: str, seq_b: str, scoring: ScoringScheme = None, lanes: int = 64) -> Alignment:
+    """Smith-Waterman local alignment by a striped SWAR kernel, with the same result score as local_align
+
+    The striped scan finds the best score and where it ends; a second scan of both reversed
+    prefixes finds where it starts, and the alignment itself comes from a banded global
+    alignment of just that region, with the band wide enough for every gap the score allows.
+    """
+    scoring = scoring or ScoringScheme()
+    seq_a, seq_b = clean_sequence(seq_a), clean_sequence(seq_b)
+    if scoring.gap >= 0 or lanes < 1:
+        raise ValueError('Striped alignment needs a negative gap score and at least one lane')
+    if not seq_a or not seq_b:
+        return Alignment('', '', 0)
+    score, end_a, end_b = _striped_scan(seq_a, seq_b, scoring, lanes)
+    if not score:
+        return Alignment('', '', 0)
+    _, length_a, length_b = _striped_scan(seq_a[:end_a][::-1], seq_b[:end_b][::-1], scoring, lanes)
+    start_a, start_b = end_a - length_a, end_b - length_b
+    # Every gap costs at least -gap below a gap-free alignment of the shorter region
+    max_gaps = (scoring.max_score() * min(length_a, length_b) - score) // -scoring.gap
+    region = banded_global_align(seq_a[start_a:end_a], seq_b[start_b:end_b], scoring, max(max_gaps, 0))
+    if region.score != score:
+        # The reverse scan found an equally good alignment elsewhere; fall back to the full matrix
+        return local_align(seq_a, seq_b, scoring)
+    return Alignment(region.aligned_a, region.aligned_b, score, start_a, end_a, start_b, end_b)
+
+
+def format_alignment(alignment: Alignment, name_a: str = 'a', name_b: str = 'b', width: int = 60) -> str:
+    """Format an alignment as wrapped text blocks"""
+    label_width = max(len(name_a), len(name_b))
+    midline = alignment.midline()
+    lines = []
+    for offset in range(0, len(alignment.aligned_a), width):
+        lines.append(f"{name_a.ljust(label_width)}  {alignment.aligned_a[offset:offset + width]}")
+        lines.append(f"{' ' * label_width}  {midline[offset:offset + width]}")
+        lines.append(f"{name_b.ljust(label_width)}  {alignment.aligned_b[offset:offset + width]}")
+        lines.append('')
+    lines.append(f"Score: {alignment.score}  Identity: {alignment.identity:.1f}%")
+    return '\n'.join(lines)
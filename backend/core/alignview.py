@@ -0,0 +1,144 @@
+from collections import Counter
+from typing import Dict, List
+from .align import Alignment
+from .seqio import SequenceRecord, format_fasta
+
+GAPS = '-.'
+NUCLEOTIDES = set('ACGTUN' + GAPS)
+
+# CLUSTAL's conserved residue groups: ':' when a column falls in one strong group, '.' in one weak group
+STRONG_GROUPS = ('STA', 'NEQK', 'NHQK', 'NDEQ', 'QHRK', 'MILV', 'MILF', 'HY', 'FYW')
+WEAK_GROUPS = ('CSA', 'ATV', 'SAG', 'STNK', 'STPA', 'SGND', 'SNDEQK', 'NDEQHK', 'NEQHRK', 'FVLIM', 'HFY')
+
+
+def _check_rows(names: List[str], rows: List[str]):
+    if len(rows) < 2:
+        raise ValueError('At least two aligned sequences are required')
+    if len(names) != len(rows):
+        raise ValueError('Every aligned row needs a name')
+    if len({len(row) for row in rows}) > 1:
+        raise ValueError('Aligned sequences must all be the same length (gaps as -)')
+
+
+def is_protein(rows: List[str]) -> bool:
+    return any(set(row.upper()) - NUCLEOTIDES for row in rows)
+
+
+def conservation_line(rows: List[str]) -> str:
+    """CLUSTAL conservation marks: '*' identical, ':' strongly and '.' weakly similar (protein only)"""
+    protein = is_protein(rows)
+    marks = []
+    for column in zip(*(row.upper() for row in rows)):
+        residues = set(column)
+        if residues & set(GAPS):
+            marks.append(' ')
+        elif len(residues) == 1:
+            marks.append('*')
+        elif protein and any(residues <= set(group) for group in STRONG_GROUPS):
+            marks.append(':')
+        elif protein and any(residues <= set(group) for group in WEAK_GROUPS):
+            marks.append('.')
+        else:
+            marks.append(' ')
+    return ''.join(marks)
+
+
+def consensus(rows: List[str]) -> str:
+    """Most common residue of each column, '-' where gaps are the majority"""
+    result = []
+    for column in zip(*(row.upper() for row in rows)):
+        counts = Counter('-' if residue in GAPS else residue for residue in column)
+        result.append(counts.most_common(1)[0][0])
+    return ''.join(result)
+
+
+def format_clustal(names: List[str], rows: List[str], width: int = 60) -> str:
+    """Alignment in CLUSTAL W format, with residue counts at the end of each line"""
+    _check_rows(names, rows)
+    label_width = max(len(name) for name in names) + 4
+    conservation = conservation_line(rows)
+    counts = [0] * len(rows)
+    lines = ['CLUSTAL W multiple sequence alignment', '', '']
+    for offset in range(0, len(rows[0]), width):
+        for i, (name, row) in enumerate(zip(names, rows)):
+            segment = row[offset:offset + width]
+            counts[i] += sum(1 for residue in segment if residue not in GAPS)
+            lines.append(f'{name.ljust(label_width)}{segment}\t{counts[i]}')
+        lines.append(' ' * label_width + conservation[offset:offset + width])
+        lines.append('')
+    return '\n'.join(lines)
+
+
+def format_aligned_fasta(names: List[str], rows: List[str], width: int = 60) -> str:
+    _check_rows(names, rows)
+    return format_fasta([SequenceRecord(name, row) for name, row in zip(names, rows)], width)
+
+
+def _cell(residue: str, reference: str) -> str:
+    if residue in GAPS:
+        return 'gap'
+    return 'match' if residue.upper() == reference else 'mismatch'
+
+
+def alignment_view(names: List[str], rows: List[str], width: int = 60, reference: str = None,
+                   starts: List[int] = None) -> Dict:
+    """Blocks of an alignment for display, each residue classed as match, mismatch or gap
+
+    Residues are compared with reference (by default the consensus). Every block carries a
+    ruler of alignment columns (ticks every 10) and each row's 1-based residue range, counted
+    from starts (0-based offsets into the ungapped sequences, e.g. a local alignment's).
+    """
+    _check_rows(names, rows)
+    if width < 10:
+        raise ValueError('width must be at least 10')
+    reference = (reference or consensus(rows)).upper()
+    conservation = conservation_line(rows)
+    positions = list(starts or [0] * len(rows))
+    length = len(rows[0])
+    blocks = []
+    for offset in range(0, length, width):
+        end = min(offset + width, length)
+        block_rows = []
+        for i, (name, row) in enumerate(zip(names, rows)):
+            segment = row[offset:end]
+            residues = sum(1 for residue in segment if residue not in GAPS)
+            block_rows.append({
+                'name': name,
+                'segment': segment,
+                'start': positions[i] + 1 if residues else positions[i],
+                'end': positions[i] + residues,
+                'cells': [_cell(residue, reference[offset + j]) for j, residue in enumerate(segment)]
+            })
+            positions[i] += residues
+        blocks.append({
+            'start': offset + 1,
+            'end': end,
+            'ruler': [column for column in range(offset + 1, end + 1) if column % 10 == 0],
+            'rows': block_rows,
+            'conservation': conservation[offset:end]
+        })
+
+    identical = conservation.count('*')
+    return {
+        'names': names,
+        'length': length,
+        'width': width,
+        'protein': is_protein(rows),
+        'consensus': reference,
+        'identity': round(100 * identical / length, 2) if length else 0.0,
+        'blocks': blocks
+    }
+
+
+def pairwise_view(alignment: Alignment, name_a: str = 'a', name_b: str = 'b', width: int = 60) -> Dict:
+    """View of a pairwise alignment; cells compare each row with the other, so mismatches show on both"""
+    rows = [alignment.aligned_a, alignment.aligned_b]
+    view = alignment_view([name_a, name_b], rows, width, reference=alignment.aligned_a,
+                          starts=[alignment.start_a, alignment.start_b])
+    for block in view['blocks']:
+        first, second = block['rows']
+        for i, (a, b) in enumerate(zip(first['segment'], second['segment'])):
+            if 'gap' not in (first['cells'][i], second['cells'][i]):
+                first['cells'][i] = second['cells'][i] = 'match' if a.upper() == b.upper() else 'mismatch'
+    view.update(score=alignment.score, identity=round(alignment.identity, 2))
+    return view
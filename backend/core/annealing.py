@@ -0,0 +1,124 @@
+from dataclasses import dataclass
+from typing import Dict, List
+from xml.sax.saxutils import escape
+from .sequence import COMPLEMENTS, clean_sequence, reverse_complement
+
+# SantaLucia (1998) unified nearest-neighbor ΔG37 (kcal/mol), keyed by the top-strand dinucleotide
+_NN_DG37 = {
+    'AA': -1.00, 'AT': -0.88, 'TA': -0.58, 'CA': -1.45, 'GT': -1.44,
+    'CT': -1.28, 'GA': -1.30, 'CG': -2.17, 'GC': -2.24, 'GG': -1.84
+}
+NN_DG37 = {**{reverse_complement(pair): dg for pair, dg in _NN_DG37.items()}, **_NN_DG37}
+DG_INITIATION = 1.96
+DG_TERMINAL_AT = 0.05
+
+
+def duplex_dg(sequence: str) -> float:
+    """ΔG37 (kcal/mol) of a perfectly paired duplex of sequence with its complement"""
+    sequence = clean_sequence(sequence)
+    if len(sequence) < 2:
+        return 0.0
+    dg = DG_INITIATION + sum(NN_DG37.get(sequence[i:i + 2], 0.0) for i in range(len(sequence) - 1))
+    dg += DG_TERMINAL_AT * sum(1 for base in (sequence[0], sequence[-1]) if base in 'AT')
+    return dg
+
+
+@dataclass
+class Duplex:
+    """One annealing register of two strands
+
+    Strand a is written 5'->3' on top and b 3'->5' underneath, shifted by offset columns
+    (negative when b starts before a). Pairs are compared column by column.
+    """
+    top: str
+    bottom: str
+    offset: int
+    pairs: str          # per overlap column: '|' Watson-Crick, 'x' mismatch
+    matches: int
+    mismatches: int
+    best_run: str       # longest perfectly paired stretch, as top-strand sequence
+    dg: float           # ΔG37 of the best run
+
+    @property
+    def overlap(self) -> int:
+        return len(self.pairs)
+
+    def overhangs(self) -> Dict[str, int]:
+        """Unpaired bases at each end, e.g. a 5' overhang of the top strand"""
+        left, right = self.offset, len(self.top) - (self.offset + len(self.bottom))
+        return {
+            'top_5': max(left, 0), 'bottom_3': max(-left, 0),
+            'top_3': max(right, 0), 'bottom_5': max(-right, 0)
+        }
+
+    def ascii(self) -> str:
+        """Three-line duplex diagram with 5'/3' ends, match bars and x for mismatches"""
+        shift = min(self.offset, 0)
+        top_pad, bottom_pad = -shift, self.offset - shift
+        start = max(self.offset, 0) - shift
+        return '\n'.join([
+            "5'-" + ' ' * top_pad + self.top + '-3\'',
+            '   ' + ' ' * start + self.pairs,
+            "3'-" + ' ' * bottom_pad + self.bottom[::-1] + '-5\''
+        ])
+
+    def to_dict(self) -> Dict:
+        return {
+            'offset': self.offset,
+            'overlap': self.overlap,
+            'matches': self.matches,
+            'mismatches': self.mismatches,
+            'best_run': self.best_run,
+            'best_run_length': len(self.best_run),
+            'dg': round(self.dg, 2),
+            'overhangs': self.overhangs(),
+            'ascii': self.ascii()
+        }
+
+
+def _register(top: str, bottom: str, offset: int) -> Duplex:
+    reversed_bottom = bottom[::-1]
+    start, end = max(offset, 0), min(len(top), offset + len(bottom))
+    pairs, run, best = [], 0, (0, 0)  # best = (length, end column)
+    for i in range(start, end):
+        paired = COMPLEMENTS.get(top[i]) == reversed_bottom[i - offset] and top[i] in 'ACGT'
+        pairs.append('|' if paired else 'x')
+        run = run + 1 if paired else 0
+        if run > best[0]:
+            best = (run, i + 1)
+    best_run = top[best[1] - best[0]:best[1]]
+    matches = pairs.count('|')
+    return Duplex(top, bottom, offset, ''.join(pairs), matches, len(pairs) - matches, best_run, duplex_dg(best_run))
+
+
+def anneal(a: str, b: str, min_run: int = 4, top: int = 3) -> List[Duplex]:
+    """Best annealing registers of two strands, strongest (lowest ΔG of the best run) first
+
+    Every relative offset is tried; registers whose longest perfectly paired stretch is
+    shorter than min_run are dropped.
+    """
+    a, b = clean_sequence(a), clean_sequence(b)
+    if not a or not b:
+        raise ValueError('Two sequences are required')
+    registers = [_register(a, b, offset) for offset in range(-(len(b) - 1), len(a))]
+    registers = [duplex for duplex in registers if len(duplex.best_run) >= min_run]
+    registers.sort(key=lambda duplex: (duplex.dg, -duplex.matches))
+    return registers[:top]
+
+
+def render_duplex_svg(duplex: Duplex, char_width: float = 9.6, line_height: int = 18) -> str:
+    """Monospace SVG of a duplex with paired columns in blue and mismatches in red"""
+    lines = duplex.ascii().split('\n')
+    width = int(max(len(line) for line in lines) * char_width) + 20
+    height = line_height * len(lines) + 16
+    svg = [f'<svg xmlns="http://www.w3.org/2000/svg" width="{width}" height="{height}" '
+           f'font-family="Courier New, monospace" font-size="16">']
+    for row, line in enumerate(lines):
+        y = 10 + line_height * (row + 1) - 4
+        for column, char in enumerate(line):
+            if char == ' ':
+                continue
+            color = '#2b6cb0' if char == '|' else '#c53030' if char == 'x' and row == 1 else '#333'
+            svg.append(f'<text x="{10 + column * char_width:.1f}" y="{y}" fill="{color}">{escape(char)}</text>')
+    svg.append('</svg>')
+    return ''.join(svg)
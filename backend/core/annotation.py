@@ -0,0 +1,442 @@
+from urllib.parse import quote, unquote
+from dataclasses import dataclass, field, replace
+from typing import Dict, List, Optional, Tuple
+from xml.sax.saxutils import escape
+from .sequence import clean_sequence, find_orfs
+from .restriction import ENZYMES, find_sites
+from .multisearch import AhoCorasick
+
+TRACK_ORDER = ('orf', 'restriction_site', 'primer', 'user')
+TRACK_COLORS = {
+    'orf': '#2563eb',
+    'restriction_site': '#dc2626',
+    'primer': '#7c3aed',
+    'user': '#059669',
+}
+
+
+@dataclass
+class Annotation:
+    """Feature on a sequence: 0-based, end-exclusive; strand is 1, -1 or 0 when unstranded
+
+    On a circular sequence a feature spanning the origin has end < start: it runs from start
+    to the end of the sequence and on from 0 to end.
+    """
+    start: int
+    end: int
+    type: str
+    label: str = ""
+    strand: int = 0
+    track: str = 'user'
+    source: str = 'robin'
+    score: Optional[float] = None
+    attributes: Dict[str, str] = field(default_factory=dict)
+
+    def __post_init__(self):
+        if self.start < 0 or self.end < 0:
+            raise ValueError(f'Invalid annotation range {self.start}..{self.end}')
+
+    @property
+    def wraps(self) -> bool:
+        """Whether the feature spans the origin of a circular sequence"""
+        return self.end < self.start
+
+    def span(self, length: int) -> int:
+        """Bases covered on a sequence of the given length"""
+        return self.end - self.start if not self.wraps else length - self.start + self.end
+
+    def segments(self, length: int) -> List[Tuple[int, int]]:
+        """Linear (start, end) pieces: one, or two for a feature spanning the origin"""
+        if not self.wraps:
+            return [(self.start, self.end)]
+        if self.start > length:
+            raise ValueError(f'Annotation {self.start}..{self.end} starts beyond a {length} bp sequence')
+        return [(self.start, length)] + ([(0, self.end)] if self.end else [])
+
+    def rotated(self, origin: int, length: int) -> 'Annotation':
+        """Copy with coordinates on the circular sequence rotated to start at origin (see Strand.rotate)"""
+        span = self.span(length)
+        start = (self.start - origin) % length
+        end = start + span if start + span <= length else start + span - length
+        return replace(self, start=start, end=end, attributes=dict(self.attributes))
+
+    def to_dict(self) -> Dict:
+        return {
+            'start': self.start,
+            'end': self.end,
+            'type': self.type,
+            'label': self.label,
+            'strand': self.strand,
+            'track': self.track,
+            'source': self.source,
+            'score': self.score,
+            'attributes': self.attributes
+        }
+
+    @classmethod
+    def from_dict(cls, data: Dict) -> 'Annotation':
+        return cls(
+            start=int(data['start']),
+            end=int(data['end']),
+            type=data.get('type', 'misc_feature'),
+            label=data.get('label', ''),
+            strand=int(data.get('strand', 0)),
+            track=data.get('track', 'user'),
+            source=data.get('source', 'robin'),
+            score=data.get('score'),
+            attributes=dict(data.get('attributes', {}))
+        )
+
+
+def orf_annotations(sequence: str, min_length: int = 300) -> List[Annotation]:
+    """ORFs as annotations, labeled with their frame"""
+    return [
+        Annotation(orf['start'], orf['end'], 'ORF', f"ORF {orf['strand']}{orf['frame'] + 1}",
+                   strand=1 if orf['strand'] == '+' else -1, track='orf',
+                   attributes={'protein_length': str(len(orf['protein']))})
+        for orf in find_orfs(sequence, min_length=min_length)
+    ]
+
+
+def restriction_annotations(sequence: str, enzyme_names: List[str], circular: bool = False) -> List[Annotation]:
+    """Zero-width annotations at each enzyme's top-strand cut positions"""
+    annotations = []
+    for name in enzyme_names:
+        if name not in ENZYMES:
+            raise ValueError(f'Unknown enzyme "{name}"')
+        for cut in find_sites(sequence, ENZYMES[name], circular):
+            annotations.append(Annotation(cut, cut, 'restriction_site', name, track='restriction_site',
+                                          attributes={'site': ENZYMES[name].site}))
+    return annotations
+
+
+def primer_annotations(sequence: str, primers: List[Dict]) -> List[Annotation]:
+    """Exact binding sites of primers ({name, sequence}) on either strand, found in one pass"""
+    oligos = {str(i): primer.get('sequence', '') for i, primer in enumerate(primers)
+              if clean_sequence(primer.get('sequence', ''))}
+    if not oligos:
+        return []
+    matches = AhoCorasick(oligos, both_strands=True).iter_matches(clean_sequence(sequence))
+    return [Annotation(start, end, 'primer_bind', primers[int(key)].get('name', 'primer'),
+                       strand=1 if strand == '+' else -1, track='primer')
+            for start, end, key, strand in matches]
+
+
+def split_at_origin(annotations: List[Annotation], length: int) -> List[Annotation]:
+    """Annotations with each feature spanning the origin replaced by its two linear pieces"""
+    pieces = []
+    for annotation in annotations:
+        if annotation.wraps:
+            pieces += [replace(annotation, start=start, end=end) for start, end in annotation.segments(length)]
+        else:
+            pieces.append(annotation)
+    return pieces
+
+
+def assign_lanes(annotations: List[Annotation]) -> List[int]:
+    """Lane index per annotation so overlapping features in a track never share a lane"""
+    lanes = [0] * len(annotations)
+    lane_ends: Dict[str, List[int]] = {}
+    for i in sorted(range(len(annotations)), key=lambda k: (annotations[k].start, -annotations[k].end)):
+        annotation = annotations[i]
+        ends = lane_ends.setdefault(annotation.track, [])
+        for lane, end in enumerate(ends):
+            if annotation.start >= end:
+                ends[lane] = max(annotation.end, annotation.start + 1)
+                lanes[i] = lane
+                break
+        else:
+            ends.append(max(annotation.end, annotation.start + 1))
+            lanes[i] = len(ends) - 1
+    return lanes
+
+
+class _Node:
+    __slots__ = ('center', 'by_start', 'by_end', 'left', 'right')
+
+    def __init__(self, center, by_start, by_end, left, right):
+        self.center, self.by_start, self.by_end, self.left, self.right = center, by_start, by_end, left, right
+
+
+class IntervalTree:
+    """Static centered interval tree over annotations for O(log n + k) overlap queries
+
+    Each node keeps the intervals containing its center point, sorted by start and by end,
+    with the intervals wholly left and right of it in subtrees. Zero-width annotations (cut
+    sites) are treated as covering the one base after them, as in the track layout.
+    Features spanning the origin of a circular sequence are indexed as their two pieces,
+    which needs the sequence length.
+    """
+
+    def __init__(self, annotations: List[Annotation], length: int = None):
+        self.annotations = list(annotations)
+        intervals = []
+        for annotation in self.annotations:
+            if annotation.wraps and length is None:
+                raise ValueError('The sequence length is required to index features spanning the origin')
+            for start, end in annotation.segments(length):
+                intervals.append((start, max(end, start + 1), annotation))
+        self._root = self._build(intervals)
+
+    def _build(self, intervals) -> Optional[_Node]:
+        if not intervals:
+            return None
+        starts = sorted(start for start, _, _ in intervals)
+        center = starts[len(starts) // 2]
+        here, left, right = [], [], []
+        for interval in intervals:
+            start, end, _ = interval
+            if end <= center:
+                left.append(interval)
+            elif start > center:
+                right.append(interval)
+            else:
+                here.append(interval)
+        return _Node(center, sorted(here, key=lambda i: i[0]), sorted(here, key=lambda i: -i[1]),
+                     self._build(left), self._build(right))
+
+    def __len__(self) -> int:
+        return len(self.annotations)
+
+    def at(self, position: int) -> List[Annotation]:
+        """Annotations covering a 0-based position"""
+        return self.overlapping(position, position + 1)
+
+    def overlapping(self, start: int, end: int) -> List[Annotation]:
+        """Annotations overlapping the 0-based, end-exclusive range [start, end), by position"""
+        found, stack = [], [self._root]
+        while stack:
+            node = stack.pop()
+            if node is None:
+                continue
+            if end <= node.center:
+                # Every interval here reaches past the range's end, so only starts matter
+                for interval in node.by_start:
+                    if interval[0] >= end:
+                        break
+                    found.append(interval)
+                stack.append(node.left)
+            elif start > node.center:
+                for interval in node.by_end:
+                    if interval[1] <= start:
+                        break
+                    found.append(interval)
+                stack.append(node.right)
+            else:
+                found.extend(node.by_start)
+                stack.extend((node.left, node.right))
+        # A feature across the origin can overlap the range with both pieces
+        hits, seen = [], set()
+        for _, _, annotation in sorted(found, key=lambda i: (i[0], i[1])):
+            if id(annotation) not in seen:
+                seen.add(id(annotation))
+                hits.append(annotation)
+        return hits
+
+
+def track_layout(length: int, annotations: List[Annotation]) -> Dict:
+    """Renderer-neutral payload: tracks in display order, each with laid-out features
+
+    Features spanning the origin are drawn as two pieces, at the end and the start.
+    """
+    annotations = split_at_origin(annotations, length)
+    lanes = assign_lanes(annotations)
+    names = [t for t in TRACK_ORDER if any(a.track == t for a in annotations)]
+    names += sorted({a.track for a in annotations} - set(names))
+
+    tracks = []
+    for name in names:
+        features = [dict(a.to_dict(), lane=lane) for a, lane in zip(annotations, lanes) if a.track == name]
+        tracks.append({
+            'name': name,
+            'lanes': max((f['lane'] for f in features), default=-1) + 1,
+            'features': sorted(features, key=lambda f: (f['start'], f['end']))
+        })
+    return {'length': length, 'tracks': tracks}
+
+
+def render_tracks_svg(layout: Dict, width: int = 900, lane_height: int = 14) -> str:
+    """Linear feature-track view of a track_layout payload as SVG"""
+    length = max(layout['length'], 1)
+    margin, label_width = 10, 110
+    scale = (width - label_width - 2 * margin) / length
+
+    def x_of(position: float) -> float:
+        return label_width + margin + position * scale
+
+    rows = []
+    y = 30
+    for track in layout['tracks']:
+        color = TRACK_COLORS.get(track['name'], '#6b7280')
+        rows.append(f'<text x="{margin}" y="{y + lane_height - 3}" fill="#374151">{escape(track["name"])}</text>')
+        for feature in track['features']:
+            top = y + feature['lane'] * (lane_height + 4)
+            x1, x2 = x_of(feature['start']), x_of(feature['end'])
+            tooltip = f'<title>{escape(feature["label"])} {feature["start"] + 1}..{feature["end"]}</title>'
+            if feature['end'] == feature['start']:
+                rows.append(f'<line x1="{x1:.2f}" y1="{top}" x2="{x1:.2f}" y2="{top + lane_height}" '
+                            f'stroke="{color}" stroke-width="2">{tooltip}</line>')
+            else:
+                rows.append(f'<rect x="{x1:.2f}" y="{top}" width="{max(x2 - x1, 1):.2f}" height="{lane_height}" '
+                            f'fill="{color}" fill-opacity="0.75">{tooltip}</rect>')
+                arrow = {1: '▶', -1: '◀'}.get(feature['strand'], '')
+                rows.append(f'<text x="{x1 + 2:.2f}" y="{top + lane_height - 3}" fill="white" font-size="10">'
+                            f'{escape(feature["label"])} {arrow}</text>')
+        y += max(track['lanes'], 1) * (lane_height + 4) + 10
+
+    axis = [
+        f'<line x1="{x_of(0):.2f}" y1="16" x2="{x_of(length):.2f}" y2="16" stroke="#374151"/>',
+        f'<text x="{x_of(0):.2f}" y="12" font-size="10">1</text>',
+        f'<text x="{x_of(length):.2f}" y="12" font-size="10" text-anchor="end">{length}</text>'
+    ]
+    return '\n'.join(
+        [f'<svg xmlns="http://www.w3.org/2000/svg" width="{width}" height="{y + 10}" '
+         f'font-family="sans-serif" font-size="11">'] + axis + rows + ['</svg>']
+    )
+
+
+STRAND_SYMBOLS = {1: '+', -1: '-', 0: '.'}
+SYMBOL_STRANDS = {'+': 1, '-': -1, '.': 0, '?': 0}
+
+
+def from_regions(regions: List[Dict], type: str, track: str = 'user', label_key: str = None) -> List[Annotation]:
+    """Annotations from finder results (repeats, palindromes, motif hits, ...)
+
+    Regions need start and end; a '+'/'-' or 1/-1 strand, a score and a label field are
+    used when present.
+    """
+    annotations = []
+    for region in regions:
+        strand = region.get('strand', 0)
+        label = region.get(label_key) if label_key else (
+            region.get('label') or region.get('motif') or region.get('unit') or region.get('type') or type)
+        annotations.append(Annotation(
+            start=int(region['start']),
+            end=int(region['end']),
+            type=type,
+            label=str(label),
+            strand=SYMBOL_STRANDS.get(strand, 0) if isinstance(strand, str) else int(strand),
+            track=track,
+            score=region.get('score')
+        ))
+    return annotations
+
+
+def _gff_escape(value: str) -> str:
+    return quote(str(value), safe=' ()[]{}:/|.-_+*!@#$^')
+
+
+def format_gff3(seqid: str, annotations: List[Annotation], length: int = None) -> str:
+    """GFF3 text (1-based, inclusive coordinates)
+
+    Zero-width features such as cut sites are written with start == end and a
+    zero_length=true attribute so read_gff3 restores them exactly. Features spanning the
+    origin are written with end past the sequence length, as GFF3 does for circular
+    sequences, so the length is required for them.
+    """
+    lines = ['##gff-version 3']
+    for i, annotation in enumerate(sorted(annotations, key=lambda a: (a.start, a.end)), start=1):
+        attributes = {'ID': f'{annotation.type}{i}'}
+        if annotation.label:
+            attributes['Name'] = annotation.label
+        if annotation.track != 'user':
+            attributes['track'] = annotation.track
+        attributes.update(annotation.attributes)
+        start, end = annotation.start + 1, annotation.end
+        if annotation.wraps:
+            if length is None:
+                raise ValueError('The sequence length is required to write features spanning the origin')
+            end = length + annotation.end
+        elif annotation.end == annotation.start:
+            start, end = annotation.start, annotation.start
+            attributes['zero_length'] = 'true'
+
+        lines.append('\t'.join([
+            _gff_escape(seqid),
+            _gff_escape(annotation.source or '.'),
+            _gff_escape(annotation.type),
+            str(start),
+            str(end),
+            '.' if annotation.score is None else f'{annotation.score:g}',
+            STRAND_SYMBOLS.get(annotation.strand, '.'),
+            '.',
+            ';'.join(f'{_gff_escape(k)}={_gff_escape(v)}' for k, v in attributes.items())
+        ]))
+    return '\n'.join(lines) + '\n'
+
+
+def read_gff3(text: str) -> Dict[str, List[Annotation]]:
+    """Annotations per seqid from GFF3 text; directives, comments and any ##FASTA section are skipped"""
+    by_seqid: Dict[str, List[Annotation]] = {}
+    for number, line in enumerate(text.splitlines(), start=1):
+        if line.startswith('##FASTA'):
+            break
+        if not line.strip() or line.startswith('#'):
+            continue
+        columns = line.rstrip('\n').split('\t')
+        if len(columns) != 9:
+            raise ValueError(f'GFF3 line {number} has {len(columns)} columns, expected 9')
+        seqid, source, type, start, end, score, strand, _, attribute_text = columns
+
+        attributes = {}
+        for pair in filter(None, attribute_text.split(';')):
+            key, _, value = pair.partition('=')
+            attributes[unquote(key.strip())] = unquote(value)
+        label = attributes.pop('Name', '')
+        attributes.pop('ID', None)
+        track = attributes.pop('track', 'user')
+        zero_length = attributes.pop('zero_length', '') == 'true'
+
+        annotation = Annotation(
+            start=int(start) if zero_length else int(start) - 1,
+            end=int(end),
+            type=unquote(type),
+            label=label,
+            strand=SYMBOL_STRANDS.get(strand, 0),
+            track=track,
+            source=unquote(source) if source != '.' else '',
+            score=None if score == '.' else float(score),
+            attributes=attributes
+        )
+        by_seqid.setdefault(unquote(seqid), []).append(annotation)
+    return by_seqid
+
+
+def format_bed(chrom: str, annotations: List[Annotation], length: int = None) -> str:
+    """BED6 text (0-based, end-exclusive); scores are clamped to BED's 0..1000 range
+
+    BED has no circular coordinates, so features spanning the origin are written as their
+    two pieces, which needs the sequence length.
+    """
+    if any(annotation.wraps for annotation in annotations):
+        if length is None:
+            raise ValueError('The sequence length is required to write features spanning the origin')
+        annotations = split_at_origin(annotations, length)
+    lines = []
+    for annotation in sorted(annotations, key=lambda a: (a.start, a.end)):
+        score = 0 if annotation.score is None else int(min(max(annotation.score, 0), 1000))
+        name = (annotation.label or annotation.type).replace('\t', ' ').replace(' ', '_')
+        lines.append('\t'.join([chrom, str(annotation.start), str(annotation.end), name, str(score),
+                                STRAND_SYMBOLS.get(annotation.strand, '.')]))
+    return '\n'.join(lines) + ('\n' if lines else '')
+
+
+def read_bed(text: str, type: str = 'region') -> Dict[str, List[Annotation]]:
+    """Annotations per chrom from BED3..BED12 text; track and browser lines are skipped"""
+    by_chrom: Dict[str, List[Annotation]] = {}
+    for number, line in enumerate(text.splitlines(), start=1):
+        if not line.strip() or line.startswith(('#', 'track', 'browser')):
+            continue
+        columns = line.split('\t') if '\t' in line else line.split()
+        if len(columns) < 3:
+            raise ValueError(f'BED line {number} needs at least chrom, start and end')
+        annotation = Annotation(
+            start=int(columns[1]),
+            end=int(columns[2]),
+            type=type,
+            label=columns[3] if len(columns) > 3 else '',
+            score=float(columns[4]) if len(columns) > 4 and columns[4] != '.' else None,
+            strand=SYMBOL_STRANDS.get(columns[5], 0) if len(columns) > 5 else 0
+        )
+        by_chrom.setdefault(columns[0], []).append(annotation)
+    return by_chrom
@@ -0,0 +1,193 @@
+from collections import Counter, defaultdict
+from typing import Dict, Iterable, List
+from .sequence import clean_sequence, reverse_complement
+from .strand import Strand
+from .align import ScoringScheme, local_align
+from .context import check_cancelled
+from .progress import Progress
+
+
+def assembly_stats(contigs: List[Strand]) -> Dict:
+    """Contig count, total length, longest contig, N50 and L50"""
+    lengths = sorted((len(contig) for contig in contigs), reverse=True)
+    total = sum(lengths)
+    n50 = l50 = 0
+    running = 0
+    for i, length in enumerate(lengths, start=1):
+        running += length
+        if running * 2 >= total:
+            n50, l50 = length, i
+            break
+    return {
+        'contigs': len(lengths),
+        'total_length': total,
+        'longest': lengths[0] if lengths else 0,
+        'n50': n50,
+        'l50': l50
+    }
+
+
+class DeBruijnGraph:
+    """de Bruijn graph with (k-1)-mer nodes and k-mer edges
+
+    k-mers seen fewer than min_count times are treated as sequencing errors and dropped.
+    With both_strands, reverse complements of reads are added too, so each contig is
+    usually reported once per strand.
+    """
+
+    def __init__(self, k: int = 31, min_count: int = 2, both_strands: bool = False):
+        if k < 3:
+            raise ValueError('k must be at least 3')
+        self.k = k
+        self.min_count = min_count
+        self.both_strands = both_strands
+        self.kmer_counts: Counter = Counter()
+
+    def add_reads(self, reads: Iterable[str], progress: Progress = None) -> 'DeBruijnGraph':
+        if progress:
+            reads = list(reads)  # counted for the percentage
+        for number, read in enumerate(reads):
+            check_cancelled()
+            if progress:
+                progress.update('count k-mers', number, len(reads))
+            read = clean_sequence(read)
+            for sequence in ([read, reverse_complement(read)] if self.both_strands else [read]):
+                for i in range(len(sequence) - self.k + 1):
+                    kmer = sequence[i:i + self.k]
+                    if 'N' not in kmer:
+                        self.kmer_counts[kmer] += 1
+        if progress:
+            progress.update('count k-mers', len(reads), len(reads))
+        return self
+
+    def edges(self) -> Dict[str, List[str]]:
+        """Successor nodes of each node, from the solid k-mers"""
+        successors = defaultdict(list)
+        for kmer, count in self.kmer_counts.items():
+            if count >= self.min_count:
+                successors[kmer[:-1]].append(kmer[1:])
+        return successors
+
+    def unitigs(self) -> List[str]:
+        """Sequences of maximal non-branching paths (collapsed unambiguous paths)"""
+        successors = self.edges()
+        in_degree = Counter(node for targets in successors.values() for node in targets)
+        nodes = set(successors) | set(in_degree)
+
+        def one_in_one_out(node: str) -> bool:
+            return in_degree[node] == 1 and len(successors.get(node, [])) == 1
+
+        paths, used = [], set()
+        for node in sorted(nodes):
+            if one_in_one_out(node):
+                continue
+            for target in successors.get(node, []):
+                path = [node, target]
+                used.update(path)
+                while one_in_one_out(path[-1]):
+                    path.append(successors[path[-1]][0])
+                    used.add(path[-1])
+                paths.append(path)
+
+        # Isolated cycles where every node is one-in-one-out
+        for node in sorted(nodes - used):
+            if node in used or not one_in_one_out(node):
+                continue
+            path = [node]
+            used.add(node)
+            while successors[path[-1]][0] != node:
+                path.append(successors[path[-1]][0])
+                used.add(path[-1])
+            path.append(node)
+            paths.append(path)
+
+        return [path[0] + ''.join(step[-1] for step in path[1:]) for path in paths]
+
+
+def assemble_de_bruijn(reads: Iterable[str], k: int = 31, min_count: int = 2, min_contig_length: int = 0,
+                       both_strands: bool = False, progress: Progress = None) -> List[Strand]:
+    """Contigs from a de Bruijn graph of the reads, longest first"""
+    graph = DeBruijnGraph(k, min_count, both_strands).add_reads(reads, progress)
+    contigs = sorted(graph.unitigs(), key=lambda contig: (-len(contig), contig))
+    return [
+        Strand(sequence=contig, name=f'contig{i}')
+        for i, contig in enumerate((c for c in contigs if len(c) >= min_contig_length), start=1)
+    ]
+
+
+def find_overlap(a: str, b: str, min_overlap: int = 20, min_identity: float = 90.0, max_overhang: int = 3,
+                 window: int = 1000, scoring: ScoringScheme = None) -> Dict:
+    """Best suffix(a)/prefix(b) overlap, found by local alignment of a's tail against b's head
+
+    Returns None when the alignment is shorter than min_overlap, below min_identity, or
+    leaves more than max_overhang unaligned bases at the end of a or the start of b.
+    """
+    tail_offset = max(len(a) - window, 0)
+    tail, head = a[tail_offset:], b[:window]
+    alignment = local_align(tail, head, scoring)
+    overlap = alignment.end_a - alignment.start_a
+    if (overlap < min_overlap or alignment.identity < min_identity
+            or len(tail) - alignment.end_a > max_overhang or alignment.start_b > max_overhang):
+        return None
+    return {
+        'a_end': tail_offset + alignment.end_a,
+        'b_start': alignment.end_b,
+        'length': overlap,
+        'identity': alignment.identity,
+        'score': alignment.score
+    }
+
+
+def _shares_kmer(a: str, b: str, k: int, window: int) -> bool:
+    head = {b[i:i + k] for i in range(min(len(b), window) - k + 1)}
+    tail = a[-window:]
+    return any(tail[i:i + k] in head for i in range(len(tail) - k + 1))
+
+
+def assemble_olc(reads: Iterable[str], min_overlap: int = 20, min_identity: float = 90.0,
+                 seed: int = 12, window: int = 1000, progress: Progress = None) -> List[Strand]:
+    """Greedy overlap-layout-consensus assembly for a small number of long reads
+
+    Overlaps come from the pairwise alignment module; pairs are only aligned when they share
+    a seed k-mer. The best-scoring overlap is merged repeatedly until none remain, and the
+    earlier read wins where merged reads disagree. Contigs are returned longest first. Progress
+    counts merges against the most there could be, so an assembly that stops early jumps to 100%.
+    """
+    contigs = [clean_sequence(read) for read in reads if read]
+    contigs = [read for read in contigs if len(read) >= min_overlap]
+
+    # Drop reads contained in longer ones
+    contigs.sort(key=len, reverse=True)
+    kept = []
+    for read in contigs:
+        if not any(read in other for other in kept):
+            kept.append(read)
+    contigs = kept
+
+    cache: Dict = {}
+    merges = max(len(contigs) - 1, 1)
+    while True:
+        if progress:
+            progress.update('merge overlaps', len(kept) - len(contigs), merges)
+        best = None
+        for i, a in enumerate(contigs):
+            check_cancelled()
+            for j, b in enumerate(contigs):
+                if i == j:
+                    continue
+                if (a, b) not in cache:
+                    cache[a, b] = (find_overlap(a, b, min_overlap, min_identity, window=window)
+                                   if _shares_kmer(a, b, seed, window) else None)
+                overlap = cache[a, b]
+                if overlap and (best is None or overlap['score'] > best[2]['score']):
+                    best = (i, j, overlap)
+        if best is None:
+            break
+        i, j, overlap = best
+        merged = contigs[i][:overlap['a_end']] + contigs[j][overlap['b_start']:]
+        contigs = [contig for k, contig in enumerate(contigs) if k not in (i, j)] + [merged]
+
+    if progress:
+        progress.update('merge overlaps', merges, merges)
+    contigs.sort(key=lambda contig: (-len(contig), contig))
+    return [Strand(sequence=contig, name=f'contig{i}') for i, contig in enumerate(contigs, start=1)]
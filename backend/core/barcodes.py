@@ -0,0 +1,113 @@
+"""Barcode matching with no dependencies beyond the language core
+
+This module imports nothing, not even typing, dataclasses or itertools, so the same file runs
+under MicroPython on a microcontroller driving a portable sequencer as well as in the backend:
+copy core/barcodes.py to the device and `import barcodes`. Keep it that way; demux.py builds
+the FASTQ-level demultiplexer on top of it.
+"""
+
+BASES = 'ACGTN'
+
+
+def clean_barcode(sequence):
+    """Uppercase a barcode and strip whitespace"""
+    return ''.join(sequence.split()).upper()
+
+
+def hamming(a, b):
+    """Substitutions between two sequences, counting any length difference as mismatches"""
+    distance = abs(len(a) - len(b))
+    for i in range(min(len(a), len(b))):
+        if a[i] != b[i]:
+            distance += 1
+    return distance
+
+
+def neighbours(sequence, distance, start=0):
+    """Every sequence within the given number of substitutions at or after start, with its distance"""
+    yield sequence, 0
+    if distance <= 0:
+        return
+    for p in range(start, len(sequence)):
+        for base in BASES:
+            if base == sequence[p]:
+                continue
+            variant = sequence[:p] + base + sequence[p + 1:]
+            for neighbour, d in neighbours(variant, distance - 1, p + 1):
+                yield neighbour, d + 1
+
+
+def header_index(name):
+    """Index sequence from an Illumina read header ('... 1:N:0:ACGTACGT+TTGCAGCA'); dual indexes are joined"""
+    parts = name.split(None, 1)
+    fields = parts[1].split(':') if len(parts) > 1 else []
+    return fields[-1].replace('+', '') if len(fields) >= 4 else ''
+
+
+class BarcodeMatcher:
+    """Names the barcode a sequence came from, allowing up to max_mismatches substitutions
+
+    Every sequence within max_mismatches of a barcode is precomputed, so matching is one dict
+    lookup. Sequences equally close to two barcodes are ambiguous and match nothing. The table
+    holds about len(barcodes) * (4 * length) ** max_mismatches entries, which on a device with
+    little memory is the thing to budget for.
+    """
+
+    def __init__(self, barcodes, max_mismatches=1):
+        if not barcodes:
+            raise ValueError('At least one barcode is required')
+        self.barcodes = {}
+        for name in barcodes:
+            self.barcodes[name] = clean_barcode(barcodes[name])
+        lengths = set(len(sequence) for sequence in self.barcodes.values())
+        if len(lengths) != 1 or 0 in lengths:
+            raise ValueError('Barcodes must be non-empty and of equal length')
+        self.length = lengths.pop()
+        self.max_mismatches = max_mismatches
+
+        best = {}  # sequence -> (distance, name or None if tied)
+        for name in self.barcodes:
+            for variant, distance in neighbours(self.barcodes[name], max_mismatches):
+                hit = best.get(variant)
+                if hit is None or distance < hit[0]:
+                    best[variant] = (distance, name)
+                elif distance == hit[0] and hit[1] != name:
+                    best[variant] = (distance, None)
+        self._lookup = {}
+        for variant in best:
+            if best[variant][1] is not None:
+                self._lookup[variant] = best[variant]
+
+    def match(self, sequence):
+        """(name, mismatches) of the barcode a sequence of barcode length matches, or (None, -1)"""
+        distance, name = self._lookup.get(sequence.upper(), (-1, None))
+        return (name, distance) if name is not None else (None, -1)
+
+    def conflicts(self):
+        """(name, name, distance) for barcode pairs too similar for the mismatch tolerance to separate reliably"""
+        names = list(self.barcodes)
+        pairs = []
+        for i in range(len(names)):
+            for j in range(i + 1, len(names)):
+                distance = hamming(self.barcodes[names[i]], self.barcodes[names[j]])
+                if distance <= 2 * self.max_mismatches:
+                    pairs.append((names[i], names[j], distance))
+        return pairs
+
+
+def read_barcodes(text):
+    """Sample barcodes from 'name<TAB or comma or space>sequence' lines; '#' starts a comment"""
+    barcodes = {}
+    number = 0
+    for line in text.splitlines():
+        number += 1
+        line = line.split('#', 1)[0].strip()
+        if not line:
+            continue
+        fields = line.replace(',', ' ').split()
+        if len(fields) != 2:
+            raise ValueError('Barcode line %d: expected a sample name and a sequence' % number)
+        if fields[0] in barcodes:
+            raise ValueError('Barcode line %d: duplicate sample "%s"' % (number, fields[0]))
+        barcodes[fields[0]] = fields[1]
+    return barcodes
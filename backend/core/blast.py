@@ -0,0 +1,258 @@
+import os
+import re
+import shutil
+import subprocess
+import time
+import urllib.error
+import urllib.parse
+import urllib.request
+import xml.etree.ElementTree as ElementTree
+from dataclasses import asdict, dataclass
+from typing import Callable, Dict, List, Optional
+from .entrez import RETRY_STATUSES, RateLimiter
+from .seqio import SequenceRecord, format_fasta
+
+BLAST_URL = 'https://blast.ncbi.nlm.nih.gov/Blast.cgi'
+PROGRAMS = ('blastn', 'blastp', 'blastx', 'tblastn', 'tblastx')
+
+# outfmt 6's default columns; outfmt 7 names its own in a "# Fields:" comment
+TABULAR_FIELDS = ('qseqid', 'sseqid', 'pident', 'length', 'mismatch', 'gapopen',
+                  'qstart', 'qend', 'sstart', 'send', 'evalue', 'bitscore')
+FIELD_NAMES = {
+    'query acc.ver': 'qseqid', 'query id': 'qseqid', 'subject acc.ver': 'sseqid', 'subject id': 'sseqid',
+    '% identity': 'pident', 'alignment length': 'length', 'mismatches': 'mismatch', 'gap opens': 'gapopen',
+    'q. start': 'qstart', 'q. end': 'qend', 's. start': 'sstart', 's. end': 'send',
+    'evalue': 'evalue', 'bit score': 'bitscore', 'subject title': 'stitle'
+}
+
+
+class BlastError(Exception):
+    """A BLAST search failed; status is the HTTP status to report"""
+
+    def __init__(self, message: str, status: int = 502):
+        super().__init__(message)
+        self.status = status
+
+
+@dataclass
+class BlastHit:
+    """One HSP of a query against a subject
+
+    Coordinates are 0-based and end-exclusive on each sequence's forward strand; BLAST's own
+    are 1-based and inclusive, with start > end on a reversed strand. strand is -1 when the
+    subject matches the query's reverse complement.
+    """
+    query: str
+    subject: str
+    identity: float
+    length: int
+    mismatches: int
+    gap_opens: int
+    query_start: int
+    query_end: int
+    subject_start: int
+    subject_end: int
+    strand: int
+    evalue: float
+    bit_score: float
+    description: str = ""
+    aligned_query: str = ""
+    aligned_subject: str = ""
+    midline: str = ""
+
+    def to_dict(self) -> Dict:
+        return asdict(self)
+
+
+def _span(start: int, end: int):
+    """0-based start, exclusive end and orientation of a 1-based inclusive BLAST range"""
+    return (start - 1, end, 1) if start <= end else (end - 1, start, -1)
+
+
+def _hit(query: str, subject: str, identity: float, length: int, mismatches: int, gap_opens: int,
+         qstart: int, qend: int, sstart: int, send: int, evalue: float, bit_score: float, **extra) -> BlastHit:
+    query_start, query_end, query_strand = _span(qstart, qend)
+    subject_start, subject_end, subject_strand = _span(sstart, send)
+    return BlastHit(query, subject, identity, length, mismatches, gap_opens, query_start, query_end,
+                    subject_start, subject_end, query_strand * subject_strand, evalue, bit_score, **extra)
+
+
+def parse_tabular(text: str) -> List[BlastHit]:
+    """Hits from tabular output (-outfmt 6, or 7 with its comment lines)"""
+    fields, hits = list(TABULAR_FIELDS), []
+    for line in text.splitlines():
+        if line.startswith('# Fields:'):
+            fields = [FIELD_NAMES.get(name.strip(), name.strip()) for name in line[len('# Fields:'):].split(',')]
+        if not line.strip() or line.startswith('#'):
+            continue
+        row = dict(zip(fields, line.rstrip('\n').split('\t')))
+        try:
+            hits.append(_hit(row['qseqid'], row['sseqid'], float(row['pident']), int(row['length']),
+                             int(row['mismatch']), int(row['gapopen']), int(row['qstart']), int(row['qend']),
+                             int(row['sstart']), int(row['send']), float(row['evalue']), float(row['bitscore']),
+                             description=row.get('stitle', '')))
+        except (KeyError, ValueError) as e:
+            raise ValueError(f'Malformed tabular BLAST line: {line.strip()[:80]}') from e
+    return hits
+
+
+def format_tabular(hits: List[BlastHit]) -> str:
+    """Hits as -outfmt 6 lines, back in BLAST's 1-based coordinates with minus-strand subjects reversed"""
+    lines = []
+    for hit in hits:
+        subject_range = (hit.subject_start + 1, hit.subject_end)
+        if hit.strand == -1:
+            subject_range = subject_range[::-1]
+        lines.append('\t'.join(str(value) for value in (
+            hit.query, hit.subject, f'{hit.identity:.3f}', hit.length, hit.mismatches, hit.gap_opens,
+            hit.query_start + 1, hit.query_end, *subject_range, f'{hit.evalue:.2e}', hit.bit_score)))
+    return '\n'.join(lines) + ('\n' if lines else '')
+
+
+def _gap_opens(*aligned: str) -> int:
+    return sum(len(re.findall(r'-+', sequence)) for sequence in aligned)
+
+
+def _hsp(hsp, tag: str, default: str = '0') -> str:
+    return hsp.findtext(f'Hsp_{tag}') or default
+
+
+def parse_xml(text: str) -> List[BlastHit]:
+    """Hits from BLAST XML (-outfmt 5, or the web service's XML)"""
+    try:
+        root = ElementTree.fromstring(text.strip())
+    except ElementTree.ParseError as e:
+        raise ValueError(f'Invalid BLAST XML: {e}') from e
+
+    hits = []
+    for iteration in root.iter('Iteration'):
+        query = iteration.findtext('Iteration_query-def') or root.findtext('BlastOutput_query-def') or 'query'
+        for hit in iteration.iter('Hit'):
+            subject = hit.findtext('Hit_accession') or hit.findtext('Hit_id', '')
+            for hsp in hit.iter('Hsp'):
+                length, identical, gaps = (int(_hsp(hsp, tag)) for tag in ('align-len', 'identity', 'gaps'))
+                aligned_query, aligned_subject = _hsp(hsp, 'qseq', ''), _hsp(hsp, 'hseq', '')
+                hits.append(_hit(query.split()[0], subject, round(100 * identical / length, 3) if length else 0.0,
+                                 length, length - identical - gaps, _gap_opens(aligned_query, aligned_subject),
+                                 int(_hsp(hsp, 'query-from')), int(_hsp(hsp, 'query-to')),
+                                 int(_hsp(hsp, 'hit-from')), int(_hsp(hsp, 'hit-to')),
+                                 float(_hsp(hsp, 'evalue')), float(_hsp(hsp, 'bit-score')),
+                                 description=hit.findtext('Hit_def', ''), aligned_query=aligned_query,
+                                 aligned_subject=aligned_subject, midline=_hsp(hsp, 'midline', '')))
+    return hits
+
+
+def parse_blast(text: str) -> List[BlastHit]:
+    """Hits from BLAST XML or tabular output, detected from the first character"""
+    return parse_xml(text) if text.lstrip().startswith('<') else parse_tabular(text)
+
+
+def _check_program(program: str):
+    if program not in PROGRAMS:
+        raise ValueError(f'Unknown BLAST program "{program}"; use one of {", ".join(PROGRAMS)}')
+
+
+class RemoteBlast:
+    """NCBI BLAST URL API client: Put a search, poll its RID until ready, then Get the XML
+
+    NCBI asks for no more than one request every 10 seconds and one status poll per
+    minute per search, so calls go through a shared rate limiter and polls are spaced by
+    poll_interval. Searches still running after max_wait seconds are given up on.
+    """
+
+    def __init__(self, email: str = None, tool: str = 'robin', base_url: str = BLAST_URL,
+                 poll_interval: float = 60, max_wait: float = 900, timeout: float = 60, retries: int = 3,
+                 opener: Callable = urllib.request.urlopen, sleep: Callable[[float], None] = time.sleep):
+        self.email, self.tool, self.base_url = email, tool, base_url
+        self.poll_interval, self.max_wait, self.timeout, self.retries = poll_interval, max_wait, timeout, retries
+        self.opener, self.sleep = opener, sleep
+        self.limiter = RateLimiter(0.1, sleep=sleep)
+
+    def _call(self, **params) -> str:
+        params.update(tool=self.tool)
+        if self.email:
+            params['email'] = self.email
+        body = urllib.parse.urlencode(params).encode()
+        for attempt in range(self.retries + 1):
+            self.limiter.wait()
+            try:
+                with self.opener(urllib.request.Request(self.base_url, data=body), timeout=self.timeout) as response:
+                    return response.read().decode('utf-8', errors='replace')
+            except urllib.error.HTTPError as e:
+                if e.code not in RETRY_STATUSES or attempt == self.retries:
+                    raise BlastError(f'NCBI BLAST returned HTTP {e.code}') from e
+            except urllib.error.URLError as e:
+                if attempt == self.retries:
+                    raise BlastError(f'Cannot reach NCBI BLAST: {e.reason}') from e
+            self.sleep(2 ** attempt)
+
+    def submit(self, query: str, program: str = 'blastn', database: str = 'nt',
+               expect: float = 10, hitlist_size: int = 50) -> str:
+        """Request ID (RID) of a newly queued search"""
+        _check_program(program)
+        text = self._call(CMD='Put', PROGRAM=program, DATABASE=database, QUERY=query,
+                          EXPECT=expect, HITLIST_SIZE=hitlist_size)
+        match = re.search(r'^\s*RID = (\S+)', text, flags=re.MULTILINE)
+        if not match:
+            raise BlastError('NCBI BLAST did not return a request ID')
+        return match.group(1)
+
+    def status(self, rid: str) -> str:
+        """WAITING, READY, FAILED or UNKNOWN"""
+        match = re.search(r'Status=(\w+)', self._call(CMD='Get', FORMAT_OBJECT='SearchInfo', RID=rid))
+        return match.group(1) if match else 'UNKNOWN'
+
+    def results(self, rid: str) -> str:
+        return self._call(CMD='Get', RID=rid, FORMAT_TYPE='XML')
+
+    def search(self, query: str, program: str = 'blastn', database: str = 'nt',
+               expect: float = 10, hitlist_size: int = 50) -> List[BlastHit]:
+        """Submit a search and wait for its hits"""
+        rid = self.submit(query, program, database, expect, hitlist_size)
+        waited = 0.0
+        while True:
+            self.sleep(self.poll_interval)
+            waited += self.poll_interval
+            status = self.status(rid)
+            if status == 'READY':
+                return parse_xml(self.results(rid))
+            if status in ('FAILED', 'UNKNOWN'):
+                raise BlastError(f'NCBI BLAST search {rid} {status.lower()}')
+            if waited >= self.max_wait:
+                raise BlastError(f'NCBI BLAST search {rid} still running after {int(waited)} s', 504)
+
+
+def local_blast_available(program: str = 'blastn') -> bool:
+    return shutil.which(program) is not None
+
+
+def local_blast(query: str, database: str, program: str = 'blastn', expect: float = 10,
+                max_target_seqs: int = 50, timeout: float = 600) -> List[BlastHit]:
+    """Hits from a blast+ binary on PATH against a local database (makeblastdb output)"""
+    _check_program(program)
+    binary = shutil.which(program)
+    if not binary:
+        raise BlastError(f'{program} is not installed', 503)
+    try:
+        completed = subprocess.run(
+            [binary, '-db', database, '-outfmt', '5', '-evalue', str(expect), '-max_target_seqs', str(max_target_seqs)],
+            input=query, capture_output=True, text=True, timeout=timeout)
+    except subprocess.TimeoutExpired as e:
+        raise BlastError(f'{program} ran longer than {timeout} s', 504) from e
+    if completed.returncode != 0:
+        raise BlastError(f'{program} failed: {completed.stderr.strip()[:200]}', 500)
+    return parse_xml(completed.stdout)
+
+
+def blast(record: SequenceRecord, program: str = 'blastn', database: str = 'nt', expect: float = 10,
+          hitlist_size: int = 50, remote: Optional[RemoteBlast] = None, local_db: str = None) -> List[BlastHit]:
+    """Search one sequence, locally when the blast+ binary and a local database are available
+
+    local_db defaults to ROBIN_BLAST_DB; without it, or without the binary, the search goes
+    to NCBI.
+    """
+    query = format_fasta([record])
+    local_db = local_db or os.environ.get('ROBIN_BLAST_DB')
+    if local_db and local_blast_available(program):
+        return local_blast(query, local_db, program, expect, hitlist_size)
+    return (remote or RemoteBlast()).search(query, program, database, expect, hitlist_size)
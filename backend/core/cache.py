@@ -0,0 +1,145 @@
+import hashlib
+import json
+import threading
+from collections import OrderedDict
+from typing import Callable, Dict, Optional
+from .checksum import md5
+
+
+def cache_key(operation: str, sequence: str, params: Dict) -> str:
+    """'operation:sequence MD5:params hash'; params are hashed as canonical JSON so key order does not matter
+
+    The MD5 is over the normalized (uppercased, whitespace-free) sequence, as analyses clean
+    their input the same way.
+    """
+    params_hash = hashlib.sha256(json.dumps(params, sort_keys=True, default=str).encode()).hexdigest()[:16]
+    return f'{operation}:{md5(sequence)}:{params_hash}'
+
+
+class LRUCache:
+    """Thread-safe in-process cache evicting the least recently used entry beyond max_entries"""
+
+    def __init__(self, max_entries: int = 1024):
+        if max_entries < 1:
+            raise ValueError('max_entries must be positive')
+        self.max_entries = max_entries
+        self.entries: 'OrderedDict[str, object]' = OrderedDict()
+        self.hits = self.misses = self.evictions = 0
+        self.lock = threading.Lock()
+
+    def get(self, key: str):
+        """Cached value, or None on a miss"""
+        with self.lock:
+            if key not in self.entries:
+                self.misses += 1
+                return None
+            self.entries.move_to_end(key)
+            self.hits += 1
+            return self.entries[key]
+
+    def set(self, key: str, value):
+        with self.lock:
+            self.entries[key] = value
+            self.entries.move_to_end(key)
+            while len(self.entries) > self.max_entries:
+                self.entries.popitem(last=False)
+                self.evictions += 1
+
+    def clear(self):
+        with self.lock:
+            self.entries.clear()
+
+    def stats(self) -> Dict:
+        with self.lock:
+            lookups = self.hits + self.misses
+            return {'backend': 'memory', 'entries': len(self.entries), 'max_entries': self.max_entries,
+                    'hits': self.hits, 'misses': self.misses, 'evictions': self.evictions,
+                    'hit_rate': round(self.hits / lookups, 4) if lookups else 0.0}
+
+
+class RedisCache:
+    """Cache shared between processes in Redis; values are stored as JSON and expire after ttl seconds
+
+    Redis's own maxmemory policy (e.g. allkeys-lru) bounds its size. Connection errors count as
+    misses so an unavailable Redis slows requests down rather than failing them.
+    """
+
+    def __init__(self, client, prefix: str = 'robin:cache:', ttl: int = 3600):
+        self.client, self.prefix, self.ttl = client, prefix, ttl
+        self.hits = self.misses = self.errors = 0
+        self.lock = threading.Lock()
+
+    def _count(self, counter: str):
+        with self.lock:
+            setattr(self, counter, getattr(self, counter) + 1)
+
+    def get(self, key: str):
+        try:
+            raw = self.client.get(self.prefix + key)
+        except Exception:
+            self._count('errors')
+            raw = None
+        self._count('hits' if raw is not None else 'misses')
+        return json.loads(raw) if raw is not None else None
+
+    def set(self, key: str, value):
+        try:
+            self.client.set(self.prefix + key, json.dumps(value), ex=self.ttl)
+        except Exception:
+            self._count('errors')
+
+    def clear(self):
+        try:
+            keys = list(self.client.scan_iter(f'{self.prefix}*'))
+            if keys:
+                self.client.delete(*keys)
+        except Exception:
+            self._count('errors')
+
+    def stats(self) -> Dict:
+        with self.lock:
+            lookups = self.hits + self.misses
+            return {'backend': 'redis', 'ttl': self.ttl, 'hits': self.hits, 'misses': self.misses,
+                    'errors': self.errors, 'hit_rate': round(self.hits / lookups, 4) if lookups else 0.0}
+
+
+class AnalysisCache:
+    """Results of expensive analyses keyed by (operation, sequence hash, params)
+
+    backend is an LRUCache or RedisCache, or None to disable caching. Results must be
+    JSON-serializable so both backends return the same thing.
+    """
+
+    def __init__(self, backend=None):
+        self.backend = backend
+
+    @property
+    def enabled(self) -> bool:
+        return self.backend is not None
+
+    def get(self, operation: str, sequence: str, params: Dict):
+        return self.backend.get(cache_key(operation, sequence, params)) if self.backend else None
+
+    def set(self, operation: str, sequence: str, params: Dict, value):
+        if self.backend:
+            self.backend.set(cache_key(operation, sequence, params), value)
+
+    def cached(self, operation: str, sequence: str, params: Dict, compute: Callable[[], object],
+               on_lookup: Optional[Callable[[bool], None]] = None):
+        """Cached result, computing and storing it on a miss; on_lookup(hit) is called when caching is on"""
+        if not self.backend:
+            return compute()
+        value = self.get(operation, sequence, params)
+        if on_lookup:
+            on_lookup(value is not None)
+        if value is None:
+            value = compute()
+            self.set(operation, sequence, params, value)
+        return value
+
+    def clear(self):
+        if self.backend:
+            self.backend.clear()
+
+    def stats(self) -> Dict:
+        return self.backend.stats() if self.backend else {'backend': 'off'}
@@ -0,0 +1,51 @@
+import base64
+import hashlib
+from .sequence import clean_sequence, reverse_complement
+
+
+def minimal_rotation(sequence: str) -> int:
+    """Start of the lexicographically smallest rotation (Booth's algorithm, linear time)"""
+    doubled = sequence + sequence
+    failure = [-1] * len(doubled)
+    best = 0
+    for j in range(1, len(doubled)):
+        char = doubled[j]
+        i = failure[j - best - 1]
+        while i != -1 and char != doubled[best + i + 1]:
+            if char < doubled[best + i + 1]:
+                best = j - i - 1
+            i = failure[i]
+        if i == -1 and char != doubled[best]:
+            if char < doubled[best]:
+                best = j
+            failure[j - best] = -1
+        else:
+            failure[j - best] = i + 1
+    return best % len(sequence) if sequence else 0
+
+
+def canonical_rotation(sequence: str) -> str:
+    """The lexicographically smallest rotation, the same for every choice of origin"""
+    start = minimal_rotation(sequence)
+    return sequence[start:] + sequence[:start]
+
+
+def seguid(sequence: str) -> str:
+    """SEGUID: base64 SHA-1 of the uppercased sequence, without padding"""
+    digest = hashlib.sha1(clean_sequence(sequence).encode()).digest()
+    return base64.b64encode(digest).decode().rstrip('=')
+
+
+def md5(sequence: str) -> str:
+    """Hex MD5 of the uppercased sequence with whitespace removed"""
+    return hashlib.md5(clean_sequence(sequence).encode()).hexdigest()
+
+
+def circular_seguid(sequence: str) -> str:
+    """SEGUID of a circular double-stranded sequence, independent of origin and strand
+
+    Both strands are brought to their smallest rotation and the smaller of the two is hashed,
+    so a plasmid keeps its checksum when it is rotated or entered as its reverse complement.
+    """
+    sequence = clean_sequence(sequence)
+    return seguid(min(canonical_rotation(sequence), canonical_rotation(reverse_complement(sequence))))
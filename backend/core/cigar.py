@@ -0,0 +1,113 @@
+import re
+from typing import Iterable, List, Tuple
+from .align import Alignment
+
+CIGAR_PATTERN = re.compile(r'(\d+)([MIDNSHP=X])')
+CONSUMES_QUERY = 'MIS=X'
+CONSUMES_REFERENCE = 'MDN=X'
+
+Cigar = List[Tuple[int, str]]
+
+
+def parse_cigar(cigar: str) -> Cigar:
+    """(count, op) pairs of a SAM CIGAR string; '*' (unavailable) is an empty list"""
+    if cigar == '*':
+        return []
+    ops = [(int(count), op) for count, op in CIGAR_PATTERN.findall(cigar)]
+    if ''.join(f'{count}{op}' for count, op in ops) != cigar:
+        raise ValueError(f'Invalid CIGAR "{cigar}"')
+    return ops
+
+
+def merge_cigar(ops: Iterable[Tuple[int, str]]) -> Cigar:
+    """Join neighbouring operations of the same kind and drop empty ones, e.g. 3M 2M 0I -> 5M"""
+    merged = []
+    for count, op in ops:
+        if not count:
+            continue
+        if merged and merged[-1][1] == op:
+            merged[-1] = (merged[-1][0] + count, op)
+        else:
+            merged.append((count, op))
+    return merged
+
+
+def format_cigar(ops: Iterable[Tuple[int, str]]) -> str:
+    return ''.join(f'{count}{op}' for count, op in merge_cigar(ops)) or '*'
+
+
+def _ops(cigar) -> Cigar:
+    return parse_cigar(cigar) if isinstance(cigar, str) else list(cigar)
+
+
+def reference_length(cigar) -> int:
+    """Reference bases an alignment covers (M, D, N, = and X)"""
+    return sum(count for count, op in _ops(cigar) if op in CONSUMES_REFERENCE)
+
+
+def query_length(cigar) -> int:
+    """Read bases the CIGAR accounts for, soft clips included (M, I, S, = and X); should equal the SEQ length"""
+    return sum(count for count, op in _ops(cigar) if op in CONSUMES_QUERY)
+
+
+def alignment_cigar(alignment: Alignment, read_length: int) -> str:
+    """CIGAR (M/I/D with S soft clips) of a local alignment with the reference as sequence a"""
+    ops = [(alignment.start_b, 'S')]
+    ops.extend((1, 'I' if ref_base == '-' else 'D' if read_base == '-' else 'M')
+               for ref_base, read_base in zip(alignment.aligned_a, alignment.aligned_b))
+    ops.append((read_length - alignment.end_b, 'S'))
+    return format_cigar(ops)
+
+
+def _walk(cigar, read: str, reference: str, position: int):
+    """(op, read base, reference base) for every aligned column, '' standing in for the missing side
+
+    Skipped regions (N, e.g. introns) and clips are stepped over without a column.
+    """
+    ref_pos, read_pos = position, 0
+    for count, op in _ops(cigar):
+        for _ in range(count):
+            if op in 'M=X':
+                if ref_pos >= len(reference) or read_pos >= len(read):
+                    raise ValueError('CIGAR runs past the end of the read or reference')
+                yield op, read[read_pos], reference[ref_pos]
+            elif op == 'D':
+                if ref_pos >= len(reference):
+                    raise ValueError('CIGAR runs past the end of the reference')
+                yield op, '', reference[ref_pos]
+            elif op == 'I':
+                yield op, read[read_pos:read_pos + 1], ''
+            if op in CONSUMES_REFERENCE:
+                ref_pos += 1
+            if op in CONSUMES_QUERY:
+                read_pos += 1
+
+
+def md_tag(cigar, read: str, reference: str, position: int) -> str:
+    """SAM MD tag of a read aligned at a 0-based reference position: matching runs, mismatched
+    reference bases and ^-prefixed deleted ones, e.g. 10A5^AC6 (insertions and clips do not appear)"""
+    md, run, deleting = [], 0, False
+    for op, read_base, ref_base in _walk(cigar, read.upper(), reference.upper(), position):
+        if op == 'I':
+            deleting = False
+            continue
+        if op == 'D':
+            if not deleting:
+                md.append(f'{run}^')
+                run, deleting = 0, True
+            md.append(ref_base)
+            continue
+        deleting = False
+        if read_base == ref_base:
+            run += 1
+        else:
+            md.append(f'{run}{ref_base}')
+            run = 0
+    md.append(str(run))
+    return ''.join(md)
+
+
+def edit_distance(cigar, read: str, reference: str, position: int) -> int:
+    """SAM NM: mismatched, inserted and deleted bases"""
+    return sum(1 for op, read_base, ref_base in _walk(cigar, read.upper(), reference.upper(), position)
+               if read_base != ref_base)
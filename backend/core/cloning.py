@@ -0,0 +1,176 @@
+import re
+import primer3
+from typing import Dict, List
+from .restriction import ENZYMES, RestrictionEnzyme, site_pattern
+from .sequence import clean_sequence, gc_content, reverse_complement
+
+
+
+def type_iis_enzymes() -> Dict[str, RestrictionEnzyme]:
+    """Catalog enzymes usable for Golden Gate: cutting downstream of their site, leaving a 5' overhang"""
+    return {name: enzyme for name, enzyme in ENZYMES.items()
+            if enzyme.type_iis and not enzyme.nick and enzyme.cut > len(enzyme.site) and enzyme.overhang_length > 0}
+
+
+PRIMER_PADDING = 'TT'  # extra bases 5' of a Type IIS site so the enzyme can bind near the end
+
+
+def _tm(sequence: str) -> float:
+    return round(primer3.calc_tm(sequence), 1)
+
+
+def _annealing_region(sequence: str, target_tm: float, min_length: int = 18, max_length: int = 35) -> str:
+    """Shortest prefix of sequence reaching the target Tm (capped at max_length)"""
+    for length in range(min_length, min(max_length, len(sequence)) + 1):
+        if _tm(sequence[:length]) >= target_tm:
+            return sequence[:length]
+    return sequence[:max_length]
+
+
+def _junctions(fragments: List[str], circular: bool):
+    count = len(fragments)
+    return [(i, (i + 1) % count) for i in range(count if circular else count - 1)]
+
+
+def _fragment_primers(fragments: List[str], forward_tails: List[str], reverse_tails: List[str],
+                      target_tm: float) -> List[Dict]:
+    primers = []
+    for i, fragment in enumerate(fragments):
+        forward = _annealing_region(fragment, target_tm)
+        reverse = _annealing_region(reverse_complement(fragment), target_tm)
+        primers.append({
+            'fragment': i,
+            'forward': forward_tails[i] + forward,
+            'reverse': reverse_tails[i] + reverse,
+            'forward_tm': _tm(forward),
+            'reverse_tm': _tm(reverse)
+        })
+    return primers
+
+
+def design_gibson(fragments: List[str], overlap: int = 30, circular: bool = True, target_tm: float = 60.0) -> Dict:
+    """Gibson assembly: overlaps across each junction and primers that add them
+
+    Each junction overlap is the last overlap/2 bases of one fragment and the first
+    overlap/2 of the next; the next fragment's forward primer carries the first half and
+    the previous fragment's reverse primer the second. Junctions are flagged when the
+    overlap Tm is low, GC content is extreme or the overlap occurs elsewhere in the product.
+    """
+    fragments = [clean_sequence(fragment) for fragment in fragments]
+    if not fragments:
+        raise ValueError('At least one fragment is required')
+    if len(fragments) < 2 and not circular:
+        raise ValueError('At least two fragments are required for a linear assembly')
+    if any(len(fragment) < overlap for fragment in fragments):
+        raise ValueError(f'Every fragment must be at least {overlap} nt long')
+
+    product = ''.join(fragments)
+    search = product + product[:overlap] if circular else product
+    half = overlap // 2
+    forward_tails, reverse_tails = [''] * len(fragments), [''] * len(fragments)
+    junctions = []
+    for left, right in _junctions(fragments, circular):
+        left_part, right_part = fragments[left][-half:], fragments[right][:overlap - half]
+        sequence = left_part + right_part
+        forward_tails[right] = left_part
+        reverse_tails[left] = reverse_complement(right_part)
+
+        tm, gc = _tm(sequence), gc_content(sequence)
+        warnings = []
+        if tm < 48:
+            warnings.append(f'Overlap Tm {tm} °C is below 48 °C')
+        if not 30 <= gc <= 70:
+            warnings.append(f'Overlap GC content {gc:.0f}% is outside 30-70%')
+        occurrences = search.count(sequence) + search.count(reverse_complement(sequence))
+        if occurrences > 1:
+            warnings.append('Overlap sequence occurs more than once in the assembly')
+        junctions.append({'left': left, 'right': right, 'overlap': sequence, 'tm': tm,
+                          'gc_content': round(gc, 1), 'warnings': warnings})
+
+    return {
+        'method': 'gibson',
+        'circular': circular,
+        'product': product,
+        'product_length': len(product),
+        'junctions': junctions,
+        'warnings': [f'Junction {j["left"] + 1}-{j["right"] + 1}: {w}' for j in junctions for w in j['warnings']],
+        'primers': _fragment_primers(fragments, forward_tails, reverse_tails, target_tm)
+    }
+
+
+def internal_sites(sequence: str, enzyme: RestrictionEnzyme) -> List[Dict]:
+    """Occurrences of a Type IIS site on either strand (0-based start of the site)"""
+    sequence = clean_sequence(sequence)
+    hits = []
+    for strand, site in (('+', enzyme.site), ('-', reverse_complement(enzyme.site))):
+        hits += [{'position': m.start(), 'strand': strand} for m in re.finditer(f'(?={site_pattern(site)})', sequence)]
+    return sorted(hits, key=lambda hit: hit['position'])
+
+
+def check_overhangs(overhangs: List[str]) -> List[str]:
+    """Problems with a set of Golden Gate fusion sites
+
+    Overhangs must be unique, must not equal another's reverse complement (they would
+    ligate to each other), must not be palindromic and should not be all A/T or all G/C.
+    """
+    issues = []
+    seen = {}
+    for i, overhang in enumerate(overhangs):
+        overhang = clean_sequence(overhang)
+        if overhang == reverse_complement(overhang):
+            issues.append(f'Overhang {i + 1} ({overhang}) is palindromic and can self-ligate')
+        if overhang in seen:
+            issues.append(f'Overhangs {seen[overhang] + 1} and {i + 1} are both {overhang}')
+        elif reverse_complement(overhang) in seen:
+            issues.append(f'Overhang {i + 1} ({overhang}) is the reverse complement of overhang '
+                          f'{seen[reverse_complement(overhang)] + 1}')
+        if set(overhang) <= set('AT') or set(overhang) <= set('GC'):
+            issues.append(f'Overhang {i + 1} ({overhang}) has extreme GC content and ligates poorly')
+        seen.setdefault(overhang, i)
+    return issues
+
+
+def design_golden_gate(fragments: List[str], enzyme: str = 'BsaI', circular: bool = True,
+                       target_tm: float = 60.0) -> Dict:
+    """Golden Gate: fusion sites at each junction, primers adding Type IIS sites, and checks
+
+    The fusion site of a junction is the last overhang-length bases of the left fragment, so
+    the assembly is scarless. Fragments containing the enzyme's site are flagged, since the
+    enzyme would also cut there.
+    """
+    catalog = type_iis_enzymes()
+    if enzyme not in catalog:
+        raise ValueError(f'Unknown Type IIS enzyme "{enzyme}"; use one of {", ".join(catalog)}')
+    enzyme = catalog[enzyme]
+    fragments = [clean_sequence(fragment) for fragment in fragments]
+    if not fragments:
+        raise ValueError('At least one fragment is required')
+    if len(fragments) < 2 and not circular:
+        raise ValueError('At least two fragments are required for a linear assembly')
+
+    spacer, overhang_length = enzyme.cut - len(enzyme.site), enzyme.overhang_length
+    site_tail = PRIMER_PADDING + enzyme.site + 'A' * spacer
+    forward_tails = [site_tail] * len(fragments)
+    reverse_tails = [site_tail] * len(fragments)
+    junctions = []
+    for left, right in _junctions(fragments, circular):
+        overhang = fragments[left][-overhang_length:]
+        forward_tails[right] = site_tail + overhang
+        junctions.append({'left': left, 'right': right, 'overhang': overhang})
+
+    warnings = check_overhangs([junction['overhang'] for junction in junctions])
+    for i, fragment in enumerate(fragments):
+        for hit in internal_sites(fragment, enzyme):
+            warnings.append(f'Fragment {i + 1} has an internal {enzyme.name} site at {hit["position"] + 1} '
+                            f'({hit["strand"]} strand)')
+
+    return {
+        'method': 'golden_gate',
+        'enzyme': enzyme.name,
+        'circular': circular,
+        'product': ''.join(fragments),
+        'product_length': sum(len(fragment) for fragment in fragments),
+        'junctions': junctions,
+        'warnings': warnings,
+        'primers': _fragment_primers(fragments, forward_tails, reverse_tails, target_tm)
+    }
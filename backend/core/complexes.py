@@ -0,0 +1,279 @@
+import re
+from dataclasses import dataclass, field
+from typing import Dict, List, Optional, Tuple
+from .structure import parse_dot_bracket
+
+# Kernel tokens: a domain, a domain opening a pair "x(", a closing ")" or a strand break "+"
+_KERNEL_TOKEN = re.compile(r'[^\s()+]+\(?|\)|\+|\S')
+_DOMAIN_NAME = re.compile(r'[A-Za-z0-9_^\-]+\*?')
+
+
+def complement_domain(name: str) -> str:
+    """x <-> x*"""
+    return name[:-1] if name.endswith('*') else name + '*'
+
+
+@dataclass
+class _Slot:
+    """One column of a duplex diagram: a top domain, a bottom domain, a pair of them or strand ends"""
+    top: Optional[str] = None
+    bottom: Optional[str] = None
+    strand: Optional[int] = None    # top strand of the top domain or end
+    top_end: str = ''               # "5'" or "3'" where a top strand starts or stops
+    bottom_end: str = ''            # likewise for the bottom strand
+
+    @property
+    def top_text(self) -> str:
+        return self.top or self.top_end
+
+    @property
+    def bottom_text(self) -> str:
+        return self.bottom or self.bottom_end
+
+    @property
+    def width(self) -> int:
+        return max(len(self.top_text), len(self.bottom_text), 1)
+
+
+def _mark(slots: List[_Slot], strand: int = None, top_end: str = '', bottom_end: str = ''):
+    """Append a strand end, sharing the previous column when it only holds the other strand's end"""
+    last = slots[-1] if slots else None
+    if last and not last.top and not last.bottom and (top_end and not last.top_end or bottom_end and not last.bottom_end):
+        if top_end:
+            last.strand, last.top_end = strand, top_end
+        else:
+            last.bottom_end = bottom_end
+        return
+    slots.append(_Slot(strand=strand, top_end=top_end, bottom_end=bottom_end))
+
+
+@dataclass
+class Complex:
+    """Domain-level complex: strands as lists of domain names and pairs over their flattened domains
+
+    The structure is a domain-level dot-bracket with '+' between strands, e.g. strands
+    [['t', 'x'], ['x*', 't*']] with '((+))' for a fully bound duplex. Paired domains must be
+    complementary (x with x*).
+    """
+    strands: List[List[str]]
+    structure: str = ''
+    pairs: Dict[int, int] = field(init=False, default_factory=dict)
+
+    def __post_init__(self):
+        if not self.strands or any(not strand for strand in self.strands):
+            raise ValueError('A complex needs at least one strand, and every strand at least one domain')
+        for name in self.domains:
+            if not _DOMAIN_NAME.fullmatch(name):
+                raise ValueError(f'"{name}" is not a domain name; use letters, digits, _ ^ - and a trailing *')
+        structure = ''.join(self.structure.split()) or '+'.join('.' * len(strand) for strand in self.strands)
+        if [len(part) for part in structure.split('+')] != [len(strand) for strand in self.strands]:
+            raise ValueError('The structure must have one character per domain and a + between strands')
+        self.structure = structure
+        for i, j, bracket in parse_dot_bracket(structure):
+            if bracket != '(':
+                raise ValueError('Domain-level structures use ( ) only')
+            if self.domains[j] != complement_domain(self.domains[i]):
+                raise ValueError(f'Domains {self.domains[i]} and {self.domains[j]} are paired but not complementary')
+            self.pairs[i], self.pairs[j] = j, i
+        if not self._connected():
+            raise ValueError('The strands do not form one connected complex')
+
+    @property
+    def domains(self) -> List[str]:
+        return [name for strand in self.strands for name in strand]
+
+    def locate(self, index: int) -> Tuple[int, int]:
+        """(strand, domain within the strand) of a flattened domain index"""
+        for s, strand in enumerate(self.strands):
+            if index < len(strand):
+                return s, index
+            index -= len(strand)
+        raise IndexError(index)
+
+    def _connected(self) -> bool:
+        reached, frontier = {0}, [0]
+        while frontier:
+            strand = frontier.pop()
+            for i, j in self.pairs.items():
+                s, t = self.locate(i)[0], self.locate(j)[0]
+                if s == strand and t not in reached:
+                    reached.add(t)
+                    frontier.append(t)
+        return len(reached) == len(self.strands)
+
+    def kernel(self) -> str:
+        """Kernel notation, e.g. t( x( + ) ): each pair written as x( ... )"""
+        tokens, index = [], 0
+        for s, strand in enumerate(self.strands):
+            if s:
+                tokens.append('+')
+            for name in strand:
+                partner = self.pairs.get(index)
+                tokens.append(name if partner is None else f'{name}(' if partner > index else ')')
+                index += 1
+        return ' '.join(tokens)
+
+    def _layout(self) -> Tuple[int, List[_Slot]]:
+        """Bottom strand and diagram columns, with every other strand bound only to the bottom one
+
+        The bottom strand is drawn 3'->5' left to right under top strands drawn 5'->3', so each
+        top strand's bound domains meet the bottom in order. Hairpins, top-top pairs and
+        crossing strands raise ValueError.
+        """
+        offsets = [sum(len(strand) for strand in self.strands[:s]) for s in range(len(self.strands))]
+        if len(self.strands) == 1:
+            if self.pairs:
+                raise ValueError('Single-strand complexes with pairs cannot be drawn as a duplex')
+            return -1, [_Slot(strand=0, top_end="5'")] + [_Slot(top=name, strand=0) for name in self.strands[0]] + \
+                [_Slot(strand=0, top_end="3'")]
+
+        bound = [sum(1 for i in range(offsets[s], offsets[s] + len(strand)) if i in self.pairs)
+                 for s, strand in enumerate(self.strands)]
+        bottom = max(range(len(self.strands)), key=lambda s: (bound[s], len(self.strands[s]), s))
+        for i, j in self.pairs.items():
+            if (self.locate(i)[0] == bottom) == (self.locate(j)[0] == bottom):
+                raise ValueError('Only complexes of top strands bound to one bottom strand can be drawn as a duplex')
+
+        slots: List[_Slot] = []
+        current, emitted, finished = None, {}, set()
+
+        def close(strand: int):
+            names = self.strands[strand]
+            for position in range(emitted[strand] + 1, len(names)):
+                if offsets[strand] + position in self.pairs:
+                    raise ValueError('Top strands cross each other and cannot be drawn as a duplex')
+                slots.append(_Slot(top=names[position], strand=strand))
+            _mark(slots, strand, top_end="3'")
+            finished.add(strand)
+
+        _mark(slots, bottom_end="3'")
+        for position in reversed(range(len(self.strands[bottom]))):
+            name = self.strands[bottom][position]
+            partner = self.pairs.get(offsets[bottom] + position)
+            if partner is None:
+                slots.append(_Slot(bottom=name))
+                continue
+            strand, top_position = self.locate(partner)
+            if strand != current:
+                if strand in finished:
+                    raise ValueError('Top strands cross each other and cannot be drawn as a duplex')
+                current = strand
+                emitted[strand] = -1
+                _mark(slots, strand, top_end="5'")
+            if top_position <= emitted[strand]:
+                raise ValueError('Top strands cross each other and cannot be drawn as a duplex')
+            for skipped in range(emitted[strand] + 1, top_position):
+                if offsets[strand] + skipped in self.pairs:
+                    raise ValueError('Top strands cross each other and cannot be drawn as a duplex')
+                slots.append(_Slot(top=self.strands[strand][skipped], strand=strand))
+            slots.append(_Slot(top=self.strands[strand][top_position], bottom=name, strand=strand))
+            emitted[strand] = top_position
+            # A strand's unbound 3' domains follow its last pair, before the bottom strand goes on
+            if not any(offsets[strand] + later in self.pairs for later in range(top_position + 1, len(self.strands[strand]))):
+                close(strand)
+                current = None
+        _mark(slots, bottom_end="5'")
+        return bottom, slots
+
+    def ascii(self) -> str:
+        """Three-line duplex diagram: top strands 5'->3', pair bars, the bottom strand 3'->5'"""
+        _, slots = self._layout()
+        rows = ['', '', '']
+        top_strand, in_bottom = None, False
+        for k, slot in enumerate(slots):
+            width = slot.width
+            if slot.top_end == "5'":
+                top_strand = slot.strand
+            if slot.bottom_end == "3'":
+                in_bottom = True
+            # Strand backbones run through every column between a strand's ends
+            top_fill = '-' if top_strand is not None and slot.top_end != "3'" else ' '
+            bottom_fill = '-' if in_bottom and slot.bottom_end != "5'" else ' '
+            if k:
+                rows[0] += '-' if top_strand is not None and slot.top_end != "5'" else ' '
+                rows[1] += ' '
+                rows[2] += '-' if in_bottom and slot.bottom_end != "3'" else ' '
+            rows[0] += slot.top_text.ljust(width, top_fill) if slot.top_text else top_fill * width
+            rows[1] += ('|' if slot.top and slot.bottom else '').ljust(width)
+            rows[2] += slot.bottom_text.ljust(width, bottom_fill) if slot.bottom_text else bottom_fill * width
+            if slot.top_end == "3'":
+                top_strand = None
+            if slot.bottom_end == "5'":
+                in_bottom = False
+        return '\n'.join(row.rstrip() for row in rows if row.strip())
+
+    def dsd(self) -> str:
+        """Visual DSD notation: segments {lower}<upper>[duplex]<upper>{lower} joined by ':' along the bottom strand"""
+        _, slots = self._layout()
+        segments, pending_bottom = [], []
+        groups: Dict[int, List[_Slot]] = {}
+        order: List[int] = []
+        for slot in slots:
+            if slot.strand is None:
+                if slot.bottom:
+                    pending_bottom.append(slot.bottom)
+                continue
+            if slot.strand not in groups:
+                groups[slot.strand] = [_Slot(bottom=' '.join(pending_bottom)) if pending_bottom else None]
+                order.append(slot.strand)
+                pending_bottom = []
+            elif slot.bottom and pending_bottom:
+                raise ValueError('Bulged complexes have no Visual DSD form')
+            groups[slot.strand].append(slot)
+
+        for index, strand in enumerate(order):
+            lower_left, *body = groups[strand]
+            body = [slot for slot in body if slot.top]
+            paired = [k for k, slot in enumerate(body) if slot.bottom]
+            if not paired:
+                segments.append('<' + ' '.join(slot.top for slot in body) + '>')
+                continue
+            if paired != list(range(paired[0], paired[-1] + 1)):
+                raise ValueError('Bulged complexes have no Visual DSD form')
+            text = '{' + lower_left.bottom + '}' if lower_left else ''
+            left, duplex, right = body[:paired[0]], body[paired[0]:paired[-1] + 1], body[paired[-1] + 1:]
+            if left:
+                text += '<' + ' '.join(slot.top for slot in left) + '>'
+            text += '[' + ' '.join(slot.top for slot in duplex) + ']'
+            if right:
+                text += '<' + ' '.join(slot.top for slot in right) + '>'
+            if index == len(order) - 1 and pending_bottom:
+                text += '{' + ' '.join(pending_bottom) + '}'
+            segments.append(text)
+        return ':'.join(segments)
+
+    def to_dict(self) -> Dict:
+        data = {'strands': self.strands, 'structure': self.structure, 'kernel': self.kernel()}
+        for name, render in (('dsd', self.dsd), ('ascii', self.ascii)):
+            try:
+                data[name] = render()
+            except ValueError:
+                data[name] = None
+        return data
+
+
+def parse_kernel(text: str) -> Complex:
+    """Complex from kernel notation, e.g. 't( x( + ) )'; ')' closes the latest open pair with its complement"""
+    strands: List[List[str]] = [[]]
+    structure, stack = [], []
+    for token in _KERNEL_TOKEN.findall(text):
+        if token == '+':
+            strands.append([])
+            structure.append('+')
+        elif token == ')':
+            if not stack:
+                raise ValueError('Unbalanced ")" in kernel notation')
+            strands[-1].append(complement_domain(stack.pop()))
+            structure.append(')')
+        elif token == '(':
+            raise ValueError('"(" must follow a domain name, e.g. x(')
+        elif token.endswith('('):
+            strands[-1].append(token[:-1])
+            stack.append(token[:-1])
+            structure.append('(')
+        else:
+            strands[-1].append(token)
+            structure.append('.')
+    if stack:
+        raise ValueError(f'Unclosed pair on domain {stack[-1]}')
+    return Complex(strands, ''.join(structure))
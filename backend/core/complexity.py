@@ -0,0 +1,85 @@
+import math
+from collections import Counter
+from typing import Dict, List, Tuple
+from .sequence import clean_sequence
+from .windows import windows
+
+Region = Tuple[int, int]  # 0-based, end-exclusive
+
+
+def shannon_entropy(sequence: str, k: int = 1) -> float:
+    """Shannon entropy (bits) of the k-mer distribution; 2.0 is the maximum for k=1 over ACGT"""
+    sequence = clean_sequence(sequence)
+    kmers = [kmer for _, kmer in windows(sequence, k)]
+    if not kmers:
+        return 0.0
+    total = len(kmers)
+    return -sum((count / total) * math.log2(count / total) for count in Counter(kmers).values())
+
+
+def linguistic_complexity(sequence: str, max_k: int = None, alphabet_size: int = 4) -> float:
+    """Linguistic complexity: distinct k-mers observed over the maximum possible, summed over k
+
+    Ranges from near 0 (simple repeats) to 1 (every possible substring present).
+    """
+    sequence = clean_sequence(sequence)
+    length = len(sequence)
+    if not length:
+        return 0.0
+
+    max_k = min(max_k or length, length)
+    observed = possible = 0
+    for k in range(1, max_k + 1):
+        observed += len({sequence[i:i + k] for i in range(length - k + 1)})
+        possible += min(alphabet_size ** k, length - k + 1)
+    return observed / possible
+
+
+def dust_score(sequence: str) -> float:
+    """DUST triplet score of a window: sum of c(c-1)/2 over triplet counts, divided by (triplets - 1)"""
+    sequence = clean_sequence(sequence)
+    triplets = len(sequence) - 2
+    if triplets < 2:
+        return 0.0
+    counts = Counter(sequence[i:i + 3] for i in range(triplets))
+    return sum(c * (c - 1) / 2 for c in counts.values()) / (triplets - 1)
+
+
+def merge_regions(regions: List[Region]) -> List[Region]:
+    """Merge overlapping or adjacent regions"""
+    merged = []
+    for start, end in sorted(regions):
+        if merged and start <= merged[-1][1]:
+            merged[-1] = (merged[-1][0], max(merged[-1][1], end))
+        else:
+            merged.append((start, end))
+    return merged
+
+
+def dust_regions(sequence: str, window: int = 64, step: int = 1, threshold: float = 20.0) -> List[Region]:
+    """Low-complexity regions: windows whose DUST score exceeds threshold, merged"""
+    sequence = clean_sequence(sequence)
+    window = min(window, len(sequence))
+    if window < 3:
+        return []
+
+    flagged = []
+    for start in range(0, len(sequence) - window + 1, step):
+        if dust_score(sequence[start:start + window]) > threshold:
+            flagged.append((start, start + window))
+    return merge_regions(flagged)
+
+
+def complexity_summary(sequence: str, window: int = 64, threshold: float = 20.0) -> Dict:
+    """Entropy, linguistic complexity and low-complexity regions of a sequence"""
+    regions = dust_regions(sequence, window=window, threshold=threshold)
+    length = len(clean_sequence(sequence))
+    masked = sum(end - start for start, end in regions)
+    return {
+        'length': length,
+        'entropy': round(shannon_entropy(sequence), 4),
+        'dinucleotide_entropy': round(shannon_entropy(sequence, 2), 4),
+        'linguistic_complexity': round(linguistic_complexity(sequence, max_k=12), 4),
+        'low_complexity_regions': [{'start': start, 'end': end} for start, end in regions],
+        'masked_fraction': round(masked / length, 4) if length else 0.0
+    }
@@ -0,0 +1,62 @@
+from typing import Dict, List
+from .sequence import clean_sequence
+from .windows import windows
+
+
+def _skew(first: int, second: int) -> float:
+    """(first - second) / (first + second), 0 when neither base is present"""
+    total = first + second
+    return (first - second) / total if total else 0.0
+
+
+def gc_windows(sequence: str, window: int = 100, step: int = 10) -> List[Dict]:
+    """GC content, GC skew and AT skew in sliding windows
+
+    Each point reports its window's 0-based start, end and center along with the
+    GC percentage, GC skew (G-C)/(G+C) and AT skew (A-T)/(A+T).
+    """
+    if window < 1 or step < 1:
+        raise ValueError('window and step must be positive')
+
+    sequence = clean_sequence(sequence)
+    window = min(window, len(sequence))
+    if not window:
+        return []
+
+    points = []
+    for start, chunk in windows(sequence, window, step):
+        g, c = chunk.count('G'), chunk.count('C')
+        a, t = chunk.count('A'), chunk.count('T')
+        points.append({
+            'start': start,
+            'end': start + window,
+            'center': start + window / 2,
+            'gc_content': round((g + c) / window * 100, 2),
+            'gc_skew': round(_skew(g, c), 4),
+            'at_skew': round(_skew(a, t), 4)
+        })
+    return points
+
+
+def cumulative_gc_skew(sequence: str, window: int = 100, step: int = 10) -> List[Dict]:
+    """Running sum of windowed GC skew
+
+    On bacterial chromosomes the minimum of the cumulative skew marks the likely origin
+    of replication and the maximum the terminus.
+    """
+    total = 0.0
+    series = []
+    for point in gc_windows(sequence, window, step):
+        total += point['gc_skew']
+        series.append({'center': point['center'], 'cumulative_gc_skew': round(total, 4)})
+    return series
+
+
+def skew_extremes(sequence: str, window: int = 100, step: int = 10) -> Dict:
+    """Positions of the cumulative GC skew minimum (putative origin) and maximum (putative terminus)"""
+    series = cumulative_gc_skew(sequence, window, step)
+    if not series:
+        return {'origin': None, 'terminus': None}
+    minimum = min(series, key=lambda point: point['cumulative_gc_skew'])
+    maximum = max(series, key=lambda point: point['cumulative_gc_skew'])
+    return {'origin': minimum['center'], 'terminus': maximum['center']}
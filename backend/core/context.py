@@ -0,0 +1,76 @@
+import contextvars
+import threading
+import time
+from contextlib import contextmanager
+from typing import Iterator, Optional
+
+
+class Cancelled(BaseException):
+    """Raised inside long operations once their context is cancelled or past its deadline
+
+    Like asyncio's CancelledError it is not an Exception, so the handlers that turn errors into
+    results or responses let it through to whoever cancelled the work.
+    """
+
+
+class Context:
+    """Cancellation signal for long-running work, with an optional deadline
+
+    A child context is cancelled with its parent and never outlives the parent's deadline.
+    Work runs under a context with using(); the loops of alignment, folding, assembly,
+    enumeration, simulation, phylogeny, profile HMMs and structure design call
+    check_cancelled() to stop soon after it is cancelled.
+    """
+
+    def __init__(self, parent: Optional['Context'] = None, timeout: Optional[float] = None):
+        self.parent = parent
+        self.deadline = time.monotonic() + timeout if timeout else None
+        if parent is not None and parent.deadline is not None:
+            self.deadline = min(self.deadline or parent.deadline, parent.deadline)
+        self.reason = ''
+        self._event = threading.Event()
+
+    def cancel(self, reason: str = 'Cancelled'):
+        if not self._event.is_set():
+            self.reason = reason
+            self._event.set()
+
+    @property
+    def cancelled(self) -> bool:
+        if self._event.is_set():
+            return True
+        if self.parent is not None and self.parent.cancelled:
+            self.cancel(self.parent.reason)
+        elif self.deadline is not None and time.monotonic() >= self.deadline:
+            self.cancel('Deadline exceeded')
+        return self._event.is_set()
+
+    def check(self):
+        """Raise Cancelled if the context is cancelled"""
+        if self.cancelled:
+            raise Cancelled(self.reason)
+
+
+_current: contextvars.ContextVar = contextvars.ContextVar('context', default=None)
+
+
+def current() -> Optional[Context]:
+    """Context of the work running in this thread, if any"""
+    return _current.get()
+
+
+@contextmanager
+def using(context: Context) -> Iterator[Context]:
+    """Run the enclosed work under context"""
+    token = _current.set(context)
+    try:
+        yield context
+    finally:
+        _current.reset(token)
+
+
+def check_cancelled():
+    """Raise Cancelled if the work running in this thread has been cancelled; long loops call it"""
+    context = _current.get()
+    if context is not None:
+        context.check()
@@ -0,0 +1,203 @@
+import re
+from dataclasses import dataclass, field
+from typing import Dict, List, Optional, Tuple
+from xml.sax.saxutils import escape, quoteattr
+from .enumerator import Enumeration
+
+_SPECIES = re.compile(r'(?:(\d+)\s*)?([A-Za-z_][A-Za-z0-9_^*\-]*)')
+_RATES = re.compile(r'\[([^\]]*)\]\s*$')
+# Nothing on one side of a reaction, as in "A -> 0"
+_EMPTY = ('', '0', '∅')
+
+
+@dataclass
+class CRNReaction:
+    """Reaction of a chemical reaction network; reactants and products repeat for stoichiometry"""
+    reactants: Tuple[str, ...]
+    products: Tuple[str, ...]
+    rate: Optional[float] = None
+    kind: str = ''
+
+    def __str__(self) -> str:
+        text = f'{" + ".join(self.reactants) or "0"} -> {" + ".join(self.products) or "0"}'
+        return text + (f' [{self.rate:.12g}]' if self.rate is not None else '')
+
+    def to_dict(self) -> Dict:
+        return {'reactants': list(self.reactants), 'products': list(self.products), 'rate': self.rate,
+                'kind': self.kind}
+
+
+@dataclass
+class CRN:
+    """Chemical reaction network with species in first-seen order
+
+    Species can carry a label, such as the kernel notation of an enumerated complex, and
+    initial species are those a system starts from. Amounts are initial concentrations
+    that came with the network, as in an SBML model.
+    """
+    reactions: List[CRNReaction]
+    species: List[str] = field(default_factory=list)
+    labels: Dict[str, str] = field(default_factory=dict)
+    initial: List[str] = field(default_factory=list)
+    amounts: Dict[str, float] = field(default_factory=dict)
+
+    def __post_init__(self):
+        for name in self.initial + list(self.amounts) + [name for reaction in self.reactions
+                                    for name in reaction.reactants + reaction.products]:
+            if name not in self.species:
+                self.species.append(name)
+
+    @classmethod
+    def from_enumeration(cls, enumeration: Enumeration) -> 'CRN':
+        reactions = [CRNReaction(reaction.reactants, reaction.products, reaction.rate, reaction.kind)
+                     for reaction in enumeration.reactions]
+        return cls(reactions, list(enumeration.species),
+                   {name: complex_.kernel() for name, complex_ in enumeration.species.items()},
+                   list(enumeration.initial))
+
+    def format(self) -> str:
+        """One 'A + B -> C [rate]' line per reaction, as parse_crn reads"""
+        return '\n'.join(str(reaction) for reaction in self.reactions) + '\n'
+
+    def _edges(self) -> List[Tuple[str, str, int]]:
+        """(source, target, stoichiometry) of the bipartite graph, reaction nodes named r#1, r#2, ...
+
+        Species names never hold '#', which starts comments in both input formats.
+        """
+        edges = []
+        for number, reaction in enumerate(self.reactions, start=1):
+            node = f'r#{number}'
+            for name in dict.fromkeys(reaction.reactants):
+                edges.append((name, node, reaction.reactants.count(name)))
+            for name in dict.fromkeys(reaction.products):
+                edges.append((node, name, reaction.products.count(name)))
+        return edges
+
+    def _reaction_label(self, reaction: CRNReaction) -> str:
+        return ' '.join(part for part in (reaction.kind, f'{reaction.rate:g}' if reaction.rate is not None else '')
+                        if part)
+
+    def to_dot(self) -> str:
+        """Graphviz DOT: species as ellipses (initial ones shaded), reactions as small boxes between them"""
+        def quote(text: str) -> str:
+            return '"' + text.replace('\\', '\\\\').replace('"', '\\"') + '"'
+
+        lines = ['digraph crn {', '  rankdir=LR;', '  node [fontname="Helvetica", fontsize=10];']
+        for name in self.species:
+            attributes = [f'label={quote(name)}', 'shape=ellipse']
+            if name in self.labels:
+                attributes.append(f'tooltip={quote(self.labels[name])}')
+            if name in self.initial:
+                attributes.append('style=filled, fillcolor="#dbeafe"')
+            lines.append(f'  {quote(name)} [{", ".join(attributes)}];')
+        for number, reaction in enumerate(self.reactions, start=1):
+            lines.append(f'  "r#{number}" [label={quote(self._reaction_label(reaction))}, shape=box, height=0.2, '
+                         f'fontsize=8, tooltip={quote(str(reaction))}];')
+        for source, target, count in self._edges():
+            label = f' [label="{count}"]' if count > 1 else ''
+            lines.append(f'  {quote(source)} -> {quote(target)}{label};')
+        lines.append('}')
+        return '\n'.join(lines) + '\n'
+
+    def to_graphml(self) -> str:
+        """GraphML with node type (species or reaction), label, kernel, initial, kind and rate, and edge stoichiometry"""
+        lines = [
+            '<?xml version="1.0" encoding="UTF-8"?>',
+            '<graphml xmlns="http://graphml.graphdrawing.org/xmlns">',
+            '  <key id="type" for="node" attr.name="type" attr.type="string"/>',
+            '  <key id="label" for="node" attr.name="label" attr.type="string"/>',
+            '  <key id="kernel" for="node" attr.name="kernel" attr.type="string"/>',
+            '  <key id="initial" for="node" attr.name="initial" attr.type="boolean"/>',
+            '  <key id="kind" for="node" attr.name="kind" attr.type="string"/>',
+            '  <key id="rate" for="node" attr.name="rate" attr.type="double"/>',
+            '  <key id="stoichiometry" for="edge" attr.name="stoichiometry" attr.type="int"/>',
+            '  <graph id="crn" edgedefault="directed">'
+        ]
+        for name in self.species:
+            lines.append(f'    <node id={quoteattr(name)}>')
+            lines.append('      <data key="type">species</data>')
+            lines.append(f'      <data key="label">{escape(name)}</data>')
+            if name in self.labels:
+                lines.append(f'      <data key="kernel">{escape(self.labels[name])}</data>')
+            lines.append(f'      <data key="initial">{"true" if name in self.initial else "false"}</data>')
+            lines.append('    </node>')
+        for number, reaction in enumerate(self.reactions, start=1):
+            lines.append(f'    <node id="r#{number}">')
+            lines.append('      <data key="type">reaction</data>')
+            lines.append(f'      <data key="label">{escape(str(reaction))}</data>')
+            if reaction.kind:
+                lines.append(f'      <data key="kind">{escape(reaction.kind)}</data>')
+            if reaction.rate is not None:
+                lines.append(f'      <data key="rate">{reaction.rate!r}</data>')
+            lines.append('    </node>')
+        for number, (source, target, count) in enumerate(self._edges(), start=1):
+            lines.append(f'    <edge id="e{number}" source={quoteattr(source)} target={quoteattr(target)}>')
+            lines.append(f'      <data key="stoichiometry">{count}</data>')
+            lines.append('    </edge>')
+        lines += ['  </graph>', '</graphml>']
+        return '\n'.join(lines) + '\n'
+
+    def to_dict(self) -> Dict:
+        """Nodes and edges for drawing, with the species and reactions they come from"""
+        nodes = [{'id': name, 'type': 'species', 'label': name, 'kernel': self.labels.get(name),
+                  'initial': name in self.initial} for name in self.species]
+        nodes += [{'id': f'r#{number}', 'type': 'reaction', 'label': self._reaction_label(reaction),
+                   'reaction': str(reaction)} for number, reaction in enumerate(self.reactions, start=1)]
+        return {
+            'species': self.species,
+            'initial': self.initial,
+            'amounts': self.amounts,
+            'reactions': [reaction.to_dict() for reaction in self.reactions],
+            'nodes': nodes,
+            'edges': [{'source': source, 'target': target, 'stoichiometry': count}
+                      for source, target, count in self._edges()]
+        }
+
+
+def _side(text: str, number: int) -> Tuple[str, ...]:
+    names: List[str] = []
+    if text.strip() in _EMPTY:
+        return ()
+    for term in text.split('+'):
+        match = _SPECIES.fullmatch(term.strip())
+        if not match:
+            raise ValueError(f'Line {number}: "{term.strip()}" is not a species')
+        names += [match.group(2)] * int(match.group(1) or 1)
+    return tuple(names)
+
+
+def parse_crn(text: str) -> CRN:
+    """CRN from lines like 'A + B -> C [1e5]' or '2A <=> B [1, 0.5]' (# comments, 0 for nothing)
+
+    Rates in brackets are optional; a reversible reaction takes a forward and a reverse rate.
+    """
+    reactions: List[CRNReaction] = []
+    for number, line in enumerate(text.splitlines(), start=1):
+        line = line.split('#', 1)[0].strip()
+        if not line:
+            continue
+        rates: List[float] = []
+        match = _RATES.search(line)
+        if match:
+            try:
+                rates = [float(rate) for rate in match.group(1).split(',')]
+            except ValueError:
+                raise ValueError(f'Line {number}: rates must be numbers, got "{match.group(1)}"')
+            if any(rate < 0 for rate in rates):
+                raise ValueError(f'Line {number}: rates cannot be negative')
+            line = line[:match.start()].strip()
+        reversible = '<=>' in line
+        left, arrow, right = line.partition('<=>' if reversible else '->')
+        if not arrow:
+            raise ValueError(f'Line {number}: expected "->" or "<=>"')
+        if len(rates) > (2 if reversible else 1):
+            raise ValueError(f'Line {number}: too many rates')
+        reactants, products = _side(left, number), _side(right, number)
+        if not reactants and not products:
+            raise ValueError(f'Line {number}: a reaction needs reactants or products')
+        reactions.append(CRNReaction(reactants, products, rates[0] if rates else None))
+        if reversible:
+            reactions.append(CRNReaction(products, reactants, rates[1] if len(rates) > 1 else None))
+    if not reactions:
+        raise ValueError('At least one reaction is required')
+    return CRN(reactions)
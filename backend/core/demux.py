@@ -0,0 +1,73 @@
+from dataclasses import dataclass, field
+from typing import Dict, Iterable, List, Tuple
+from .barcodes import BarcodeMatcher, header_index
+from .seqio import FastqRecord
+
+UNDETERMINED = 'undetermined'
+BARCODE_LOCATIONS = ('read', 'header')
+
+
+@dataclass
+class DemuxResult:
+    """Reads per sample (and the undetermined bin) with counts and mismatch statistics"""
+    reads: Dict[str, List[FastqRecord]] = field(default_factory=dict)
+    counts: Dict[str, int] = field(default_factory=dict)
+    mismatches: Dict[str, Dict[int, int]] = field(default_factory=dict)
+
+    def report(self) -> Dict:
+        total = sum(self.counts.values())
+        return {
+            'total': total,
+            'samples': [{'name': name, 'reads': count, 'fraction': round(count / total, 4) if total else 0.0,
+                         'mismatches': self.mismatches.get(name, {})}
+                        for name, count in self.counts.items()]
+        }
+
+
+class Demultiplexer:
+    """Assigns reads to samples by barcode, allowing up to max_mismatches substitutions
+
+    Barcodes are read from the start of each read (location='read', trimmed off unless
+    trim is False) or from the index field of Illumina headers (location='header').
+    Matching itself is core/barcodes.py's BarcodeMatcher, which also runs on embedded
+    devices; sequences equally close to two barcodes go to the undetermined bin.
+    """
+
+    def __init__(self, barcodes: Dict[str, str], max_mismatches: int = 1, location: str = 'read',
+                 trim: bool = True):
+        if location not in BARCODE_LOCATIONS:
+            raise ValueError(f'Unknown barcode location "{location}"; use read or header')
+        if UNDETERMINED in barcodes:
+            raise ValueError(f'"{UNDETERMINED}" is reserved for unassigned reads')
+        self.matcher = BarcodeMatcher(barcodes, max_mismatches)
+        self.barcodes, self.length = self.matcher.barcodes, self.matcher.length
+        self.max_mismatches, self.location, self.trim = max_mismatches, location, trim
+
+    def warnings(self) -> List[str]:
+        """Barcode pairs too similar for the mismatch tolerance to separate them reliably"""
+        return [f'Barcodes {a} and {b} differ at only {distance} positions'
+                for a, b, distance in self.matcher.conflicts()]
+
+    def assign(self, record: FastqRecord) -> Tuple[str, int, FastqRecord]:
+        """(sample, mismatches, read) for one read; mismatches is -1 for undetermined reads"""
+        observed = record.sequence[:self.length] if self.location == 'read' else header_index(record.name)
+        sample, distance = self.matcher.match(observed)
+        if sample is None:
+            return UNDETERMINED, -1, record
+        if self.location == 'read' and self.trim:
+            record = FastqRecord(record.name, record.sequence[self.length:], record.quality[self.length:])
+        return sample, distance, record
+
+    def run(self, records: Iterable[FastqRecord]) -> DemuxResult:
+        """Split reads into per-sample bins plus the undetermined bin"""
+        result = DemuxResult()
+        for name in list(self.barcodes) + [UNDETERMINED]:
+            result.reads[name], result.counts[name] = [], 0
+        for record in records:
+            sample, distance, read = self.assign(record)
+            result.reads[sample].append(read)
+            result.counts[sample] += 1
+            if distance >= 0:
+                per_sample = result.mismatches.setdefault(sample, {})
+                per_sample[distance] = per_sample.get(distance, 0) + 1
+        return result
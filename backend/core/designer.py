@@ -1,3 +1,4 @@
+import random
 from datetime import datetime
 from typing import List, Dict
 from .models import Domain, GlobalParams, ValidationResult, GeneratedStrand, DesignResult
@@ -15,8 +16,8 @@ class OligonucleotideDesigner:
         self.validator = SequenceValidator(self.thermo_calc)
 
     def design_strand(self, strand_name: str, domains: List[Dict],
-                      global_params: Dict) -> DesignResult:
-        """Design a complete oligonucleotide strand"""
+                      global_params: Dict, rng: random.Random = None) -> DesignResult:
+        """Design a complete oligonucleotide strand; pass a seeded rng for reproducible domain sequences"""
         start_time = datetime.now()
 
         try:
@@ -40,7 +41,7 @@ class OligonucleotideDesigner:
                     domain.generated_sequence = domain.fixed_sequence.upper()
                 else:
                     domain.generated_sequence = self.repository.get_orthogonal_sequence(
-                        domain.length, domain.target_gc_content, all_sequences
+                        domain.length, domain.target_gc_content, all_sequences, rng
                     )
                 all_sequences.append(domain.generated_sequence)
 
@@ -0,0 +1,190 @@
+import re
+import zlib
+from dataclasses import dataclass, field
+from typing import Dict, List, Optional, Tuple
+from .align import global_align
+from .context import check_cancelled
+
+DIFF_HEADER = 'robin-diff 1'
+# Replaced regions up to this many alignment cells are realigned to separate substitutions from indels
+MAX_ALIGN_CELLS = 250_000
+# Beyond this many inserted plus deleted bases the sequences are treated as unrelated and fully replaced
+MAX_EDIT_DISTANCE = 1000
+
+_TOKEN = re.compile(r'([=\-])(\d+)$|([+*])(\S+)$')
+
+
+def _checksum(sequence: str) -> str:
+    return f'{zlib.crc32(sequence.encode()):08x}'
+
+
+@dataclass
+class EditScript:
+    """Edits turning a source sequence into a target, as a list of operations
+
+    Operations are ('=', n) copy n bases, ('-', n) delete n bases, ('+', bases) insert bases
+    and ('*', bases) substitute len(bases) bases. The source length and checksum are kept so
+    a script is only applied to the sequence it was made from.
+    """
+    ops: List[Tuple[str, object]] = field(default_factory=list)
+    source_length: int = 0
+    source_checksum: str = ''
+    target_length: int = 0
+
+    def _push(self, op: str, value):
+        if not value:
+            return
+        if self.ops and self.ops[-1][0] == op:
+            self.ops[-1] = (op, self.ops[-1][1] + value)
+        else:
+            self.ops.append((op, value))
+
+    def summary(self) -> Dict[str, int]:
+        """Substituted, inserted and deleted base counts and the number of edit operations"""
+        counts = {'substituted': 0, 'inserted': 0, 'deleted': 0}
+        for op, value in self.ops:
+            if op == '*':
+                counts['substituted'] += len(value)
+            elif op == '+':
+                counts['inserted'] += len(value)
+            elif op == '-':
+                counts['deleted'] += value
+        counts['edits'] = sum(1 for op, _ in self.ops if op != '=')
+        return counts
+
+    def to_text(self) -> str:
+        """Header line, source/target lines and one line of space-separated operations"""
+        ops = ' '.join(f'{op}{value}' for op, value in self.ops)
+        return (f'{DIFF_HEADER}\nsource {self.source_length} {self.source_checksum}\n'
+                f'target {self.target_length}\n{ops}\n')
+
+    @classmethod
+    def from_text(cls, text: str) -> 'EditScript':
+        lines = text.strip().splitlines()
+        if len(lines) < 3 or lines[0].strip() != DIFF_HEADER:
+            raise ValueError(f'Not a sequence diff (expected a "{DIFF_HEADER}" header)')
+        source, target = lines[1].split(), lines[2].split()
+        if len(source) != 3 or source[0] != 'source' or len(target) != 2 or target[0] != 'target':
+            raise ValueError('Diff is missing its source or target line')
+        script = cls(source_length=int(source[1]), source_checksum=source[2], target_length=int(target[1]))
+        for token in ' '.join(lines[3:]).split():
+            match = _TOKEN.match(token)
+            if not match:
+                raise ValueError(f'Invalid diff operation "{token}"')
+            script.ops.append((match.group(1), int(match.group(2))) if match.group(1)
+                              else (match.group(3), match.group(4)))
+        return script
+
+
+def _aligned_edits(script: EditScript, a: str, b: str):
+    """Append edits for a replaced region, aligning it when small enough to find substitutions"""
+    if len(a) * len(b) > MAX_ALIGN_CELLS or not a or not b:
+        script._push('-', len(a))
+        script._push('+', b)
+        return
+    alignment = global_align(a, b)
+    i = j = 0
+    for column_a, column_b in zip(alignment.aligned_a, alignment.aligned_b):
+        if column_b == '-':
+            script._push('-', 1)
+            i += 1
+        elif column_a == '-':
+            script._push('+', b[j])
+            j += 1
+        else:
+            if a[i] == b[j]:
+                script._push('=', 1)
+            else:
+                script._push('*', b[j])
+            i, j = i + 1, j + 1
+
+
+def _myers(a: str, b: str, max_distance: int) -> Optional[List[Tuple[str, int, int]]]:
+    """Shortest insert/delete path from a to b as ('=', '-' or '+', i, j) steps, or None past max_distance"""
+    n, m = len(a), len(b)
+    frontier, trace = {1: 0}, []
+    for d in range(max_distance + 1):
+        check_cancelled()
+        trace.append(dict(frontier))
+        for k in range(-d, d + 1, 2):
+            x = frontier[k + 1] if k == -d or (k != d and frontier[k - 1] < frontier[k + 1]) else frontier[k - 1] + 1
+            y = x - k
+            while x < n and y < m and a[x] == b[y]:
+                x, y = x + 1, y + 1
+            frontier[k] = x
+            if x >= n and y >= m:
+                return _backtrack(trace, n, m)
+    return None
+
+
+def _backtrack(trace: List[Dict[int, int]], x: int, y: int) -> List[Tuple[str, int, int]]:
+    steps = []
+    for d in range(len(trace) - 1, -1, -1):
+        frontier, k = trace[d], x - y
+        previous_k = k + 1 if k == -d or (k != d and frontier[k - 1] < frontier[k + 1]) else k - 1
+        previous_x = frontier[previous_k]
+        previous_y = previous_x - previous_k
+        while x > previous_x and y > previous_y:
+            x, y = x - 1, y - 1
+            steps.append(('=', x, y))
+        if d:
+            steps.append(('+', x, previous_y) if x == previous_x else ('-', previous_x, y))
+        x, y = previous_x, previous_y
+    steps.reverse()
+    return steps
+
+
+def diff(a: str, b: str) -> EditScript:
+    """Edit script turning a into b
+
+    The shortest insert/delete path (Myers' O(ND) diff, fast when the sequences are close)
+    anchors the script on matching runs; the changed regions between them are realigned
+    globally so point mutations come out as substitutions rather than delete-insert pairs.
+    Case is significant, so soft-masking changes are recorded as edits.
+    """
+    script = EditScript(source_length=len(a), source_checksum=_checksum(a), target_length=len(b))
+    prefix = 0
+    while prefix < min(len(a), len(b)) and a[prefix] == b[prefix]:
+        prefix += 1
+    suffix = 0
+    while suffix < min(len(a), len(b)) - prefix and a[-1 - suffix] == b[-1 - suffix]:
+        suffix += 1
+    middle_a, middle_b = a[prefix:len(a) - suffix], b[prefix:len(b) - suffix]
+
+    script._push('=', prefix)
+    steps = _myers(middle_a, middle_b, MAX_EDIT_DISTANCE)
+    if steps is None:
+        _aligned_edits(script, middle_a, middle_b)
+    else:
+        a_start = b_start = 0  # start of the pending changed region
+        for op, i, j in steps + [('=', len(middle_a), len(middle_b))]:
+            if op == '=':
+                _aligned_edits(script, middle_a[a_start:i], middle_b[b_start:j])
+                script._push('=', 1 if i < len(middle_a) else 0)
+                a_start, b_start = i + 1, j + 1
+    script._push('=', suffix)
+    return script
+
+
+def patch(a: str, script: EditScript) -> str:
+    """Apply an edit script to the sequence it was made from"""
+    if len(a) != script.source_length or _checksum(a) != script.source_checksum:
+        raise ValueError('Diff does not apply: the sequence differs from the diff source')
+    parts, position = [], 0
+    for op, value in script.ops:
+        if op == '=':
+            parts.append(a[position:position + value])
+            position += value
+        elif op == '-':
+            position += value
+        elif op == '+':
+            parts.append(value)
+        else:
+            parts.append(value)
+            position += len(value)
+    if position != len(a):
+        raise ValueError(f'Diff covers {position} of {len(a)} source bases')
+    result = ''.join(parts)
+    if len(result) != script.target_length:
+        raise ValueError(f'Patched sequence is {len(result)} bases, diff expects {script.target_length}')
+    return result
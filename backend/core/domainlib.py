@@ -0,0 +1,159 @@
+import csv
+import io
+import random
+import re
+from dataclasses import dataclass
+from typing import Dict, List, Optional, Tuple
+from .align import local_align
+from .annealing import anneal
+from .seqio import SequenceRecord, format_fasta
+from .sequence import gc_content, reverse_complement
+
+METHODS = ('thermo', 'alignment')
+# Default threshold per method: strongest allowed ΔG37 (kcal/mol), or highest identity (% of the domain length)
+DEFAULT_THRESHOLDS = {'thermo': -7.0, 'alignment': 60.0}
+MAX_LIBRARY_SIZE = 100
+MIN_DOMAIN_LENGTH, MAX_DOMAIN_LENGTH = 6, 60
+# Four of a base in a row are hard to synthesize and prone to slippage
+_HOMOPOLYMER = re.compile(r'(A{4}|C{4}|G{4}|T{4})')
+
+
+def _identity(a: str, b: str) -> float:
+    """Identical bases in the best local alignment as a percentage of the shorter sequence"""
+    alignment = local_align(a, b)
+    matches = sum(1 for x, y in zip(alignment.aligned_a, alignment.aligned_b) if x == y and x != '-')
+    return 100 * matches / min(len(a), len(b))
+
+
+def interaction(a: str, b: str, method: str = 'thermo') -> float:
+    """Strongest unintended interaction between two domains and their complements
+
+    For thermo this is the lowest ΔG37 of any register of a with b, a with b* or (when a is b)
+    a with itself; for alignment the highest identity of a with b or b*, where a resembling
+    b* means a binds b. Higher interaction is worse for alignment, lower for thermo.
+    """
+    if method == 'thermo':
+        pairs = [(a, a)] if a == b else [(a, b), (a, reverse_complement(b))]
+        dgs = [duplexes[0].dg for x, y in pairs for duplexes in [anneal(x, y, min_run=2, top=1)] if duplexes]
+        return min(dgs, default=0.0)
+    if method == 'alignment':
+        if a == b:
+            return _identity(a, reverse_complement(a))
+        return max(_identity(a, b), _identity(a, reverse_complement(b)))
+    raise ValueError(f'Unknown method "{method}"; use {" or ".join(METHODS)}')
+
+
+def _passes(value: float, method: str, threshold: float) -> bool:
+    return value >= threshold if method == 'thermo' else value <= threshold
+
+
+@dataclass
+class DomainLibrary:
+    """Mutually orthogonal domains and the worst interaction between any two of them"""
+    names: List[str]
+    sequences: List[str]
+    method: str
+    threshold: float
+    worst: Optional[Tuple[int, int, float]] = None  # (i, j, interaction); i == j for a domain with itself
+    attempts: int = 0
+    complements: bool = False
+
+    def records(self) -> List[SequenceRecord]:
+        """Domains for ordering, each followed by its complement (name*) when complements is set"""
+        records = []
+        for name, sequence in zip(self.names, self.sequences):
+            records.append(SequenceRecord(name, sequence))
+            if self.complements:
+                records.append(SequenceRecord(name + '*', reverse_complement(sequence)))
+        return records
+
+    def to_fasta(self) -> str:
+        return format_fasta(self.records())
+
+    def to_csv(self) -> str:
+        out = io.StringIO()
+        writer = csv.writer(out, lineterminator='\n')
+        writer.writerow(['name', 'sequence', 'length', 'gc_percent'])
+        for record in self.records():
+            writer.writerow([record.name, record.sequence, len(record.sequence), round(gc_content(record.sequence), 1)])
+        return out.getvalue()
+
+    def to_dict(self) -> Dict:
+        worst = None
+        if self.worst:
+            i, j, value = self.worst
+            worst = {'a': self.names[i], 'b': self.names[j], 'value': round(value, 2)}
+        return {
+            'method': self.method,
+            'threshold': self.threshold,
+            'domains': [{'name': name, 'sequence': sequence, 'gc_percent': round(gc_content(sequence), 1)}
+                        for name, sequence in zip(self.names, self.sequences)],
+            'worst': worst,
+            'attempts': self.attempts,
+            'fasta': self.to_fasta(),
+            'csv': self.to_csv()
+        }
+
+
+class OrthogonalLibraryGenerator:
+    """Greedy random generator of mutually orthogonal domains
+
+    Candidates are drawn with GC content within [gc_min, gc_max] and no homopolymer of four,
+    and kept when neither the candidate nor its complement interacts with itself or any kept
+    domain beyond threshold. Generation stops at count domains or after max_attempts draws.
+    """
+
+    def __init__(self, count: int, length: int, method: str = 'thermo', threshold: float = None,
+                 gc_min: float = 40.0, gc_max: float = 60.0, seed: Optional[int] = None, max_attempts: int = None,
+                 prefix: str = 'd'):
+        if method not in METHODS:
+            raise ValueError(f'Unknown method "{method}"; use {" or ".join(METHODS)}')
+        if not 1 <= count <= MAX_LIBRARY_SIZE:
+            raise ValueError(f'count must be between 1 and {MAX_LIBRARY_SIZE}')
+        if not MIN_DOMAIN_LENGTH <= length <= MAX_DOMAIN_LENGTH:
+            raise ValueError(f'length must be between {MIN_DOMAIN_LENGTH} and {MAX_DOMAIN_LENGTH}')
+        if not 0 <= gc_min <= gc_max <= 100:
+            raise ValueError('GC range must satisfy 0 <= gc_min <= gc_max <= 100')
+        self.gc_counts = [n for n in range(length + 1) if gc_min <= 100 * n / length <= gc_max]
+        if not self.gc_counts:
+            raise ValueError(f'No {length} nt sequence has GC content between {gc_min}% and {gc_max}%')
+        self.count, self.length, self.method, self.prefix = count, length, method, prefix
+        self.threshold = DEFAULT_THRESHOLDS[method] if threshold is None else threshold
+        self.max_attempts = max_attempts or 500 * count
+        self.random = random.Random(seed)
+
+    def candidate(self) -> str:
+        """Random domain with an allowed GC count and no homopolymer of four"""
+        while True:
+            gc = self.random.choice(self.gc_counts)
+            bases = [self.random.choice('GC') for _ in range(gc)] + [self.random.choice('AT') for _ in range(self.length - gc)]
+            self.random.shuffle(bases)
+            sequence = ''.join(bases)
+            if not _HOMOPOLYMER.search(sequence):
+                return sequence
+
+    def generate(self) -> DomainLibrary:
+        sequences: List[str] = []
+        worst: Optional[Tuple[int, int, float]] = None
+        attempts = 0
+        while len(sequences) < self.count and attempts < self.max_attempts:
+            attempts += 1
+            sequence = self.candidate()
+            index = len(sequences)
+            values = [(index, index, interaction(sequence, sequence, self.method))]
+            if not _passes(values[0][2], self.method, self.threshold):
+                continue
+            for j, other in enumerate(sequences):
+                values.append((j, index, interaction(other, sequence, self.method)))
+                if not _passes(values[-1][2], self.method, self.threshold):
+                    break
+            else:
+                sequences.append(sequence)
+                for value in values:
+                    if worst is None or not _passes(value[2], self.method, worst[2]):
+                        worst = value
+        if len(sequences) < self.count:
+            raise ValueError(f'Found only {len(sequences)} of {self.count} orthogonal domains in {attempts} attempts; '
+                             f'relax the threshold or GC range, or shorten the library')
+        names = [f'{self.prefix}{i + 1}' for i in range(self.count)]
+        return DomainLibrary(names, sequences, self.method, self.threshold, worst, attempts)
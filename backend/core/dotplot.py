@@ -0,0 +1,88 @@
+from collections import defaultdict
+from typing import Dict, List, Tuple
+from xml.sax.saxutils import escape
+from .sequence import clean_sequence, reverse_complement
+
+MAX_MISMATCH_CELLS = 25_000_000  # pairwise comparisons allowed when mismatches are tolerated
+
+Dot = Tuple[int, int, str]  # (position in a, position in b, '+' or '-')
+
+
+def _words(sequence: str, word: int) -> Dict[str, List[int]]:
+    index = defaultdict(list)
+    for i in range(len(sequence) - word + 1):
+        index[sequence[i:i + word]].append(i)
+    return index
+
+
+def _within(left: str, right: str, mismatches: int) -> bool:
+    misses = 0
+    for x, y in zip(left, right):
+        if x != y:
+            misses += 1
+            if misses > mismatches:
+                return False
+    return True
+
+
+def dot_matches(a: str, b: str, word: int = 10, mismatches: int = 0, both_strands: bool = True) -> List[Dot]:
+    """Word matches between two sequences
+
+    A dot (i, j, '+') means a[i:i+word] matches b[j:j+word] with at most `mismatches`
+    differences. Reverse-complement matches are reported as (i, j, '-') where j is the
+    forward-strand start of the matching word in b, so inversions show up as
+    anti-diagonals.
+    """
+    a, b = clean_sequence(a), clean_sequence(b)
+    if word < 1 or mismatches < 0 or mismatches >= word:
+        raise ValueError('word must be positive and mismatches smaller than word')
+
+    targets = [('+', b)]
+    if both_strands:
+        targets.append(('-', reverse_complement(b)))
+
+    dots = []
+    for orientation, target in targets:
+        def to_b(j: int) -> int:
+            return j if orientation == '+' else len(b) - j - word
+
+        if mismatches == 0:
+            index = _words(target, word)
+            for i in range(len(a) - word + 1):
+                for j in index.get(a[i:i + word], ()):
+                    dots.append((i, to_b(j), orientation))
+        else:
+            if len(a) * len(b) > MAX_MISMATCH_CELLS:
+                raise ValueError('Sequences are too long for a mismatch-tolerant dot plot; use mismatches=0')
+            for i in range(len(a) - word + 1):
+                left = a[i:i + word]
+                for j in range(len(target) - word + 1):
+                    if _within(left, target[j:j + word], mismatches):
+                        dots.append((i, to_b(j), orientation))
+    return dots
+
+
+def render_svg(a: str, b: str, dots: List[Dot], size: int = 500, name_a: str = 'Sequence A',
+               name_b: str = 'Sequence B') -> str:
+    """Dot plot as a standalone SVG: a along the x axis, b down the y axis"""
+    len_a, len_b = max(len(clean_sequence(a)), 1), max(len(clean_sequence(b)), 1)
+    margin = 40
+    scale = size / max(len_a, len_b)
+    width, height = len_a * scale, len_b * scale
+    radius = max(scale / 2, 0.6)
+    colors = {'+': '#2563eb', '-': '#dc2626'}
+
+    parts = [
+        f'<svg xmlns="http://www.w3.org/2000/svg" width="{width + 2 * margin:.0f}" '
+        f'height="{height + 2 * margin:.0f}" font-family="sans-serif" font-size="11">',
+        f'<rect x="{margin}" y="{margin}" width="{width:.1f}" height="{height:.1f}" fill="white" stroke="#9ca3af"/>',
+        f'<text x="{margin + width / 2:.1f}" y="{margin - 12}" text-anchor="middle">'
+        f'{escape(name_a)} ({len_a} nt)</text>',
+        f'<text x="{margin - 12}" y="{margin + height / 2:.1f}" text-anchor="middle" '
+        f'transform="rotate(-90 {margin - 12} {margin + height / 2:.1f})">{escape(name_b)} ({len_b} nt)</text>'
+    ]
+    for i, j, orientation in dots:
+        parts.append(f'<circle cx="{margin + (i + 0.5) * scale:.2f}" cy="{margin + (j + 0.5) * scale:.2f}" '
+                     f'r="{radius:.2f}" fill="{colors[orientation]}"/>')
+    parts.append('</svg>')
+    return '\n'.join(parts)
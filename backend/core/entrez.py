@@ -0,0 +1,118 @@
+import re
+import threading
+import time
+import urllib.error
+import urllib.parse
+import urllib.request
+from typing import Callable, List, Optional
+from .genbank import GenBankRecord, parse_genbank
+
+EUTILS_URL = 'https://eutils.ncbi.nlm.nih.gov/entrez/eutils'
+
+# GenBank/RefSeq accessions, optionally versioned: U49845, NC_000913.3, MN908947.3
+ACCESSION_PATTERN = re.compile(r'^[A-Za-z]{1,6}_?[A-Za-z]{0,4}\d{1,10}(\.\d+)?$')
+
+# HTTP statuses worth retrying: rate limited, or NCBI overloaded
+RETRY_STATUSES = (429, 500, 502, 503, 504)
+
+
+class EntrezError(Exception):
+    """An E-utilities request failed; status is 404 when the accession was not found"""
+
+    def __init__(self, message: str, status: int = 502):
+        super().__init__(message)
+        self.status = status
+
+
+class RateLimiter:
+    """Spaces calls at least 1/per_second seconds apart across threads"""
+
+    def __init__(self, per_second: float, clock: Callable[[], float] = time.monotonic,
+                 sleep: Callable[[float], None] = time.sleep):
+        self.interval = 1.0 / per_second
+        self.clock, self.sleep = clock, sleep
+        self.next_allowed = 0.0
+        self.lock = threading.Lock()
+
+    def wait(self):
+        with self.lock:
+            now = self.clock()
+            if now < self.next_allowed:
+                self.sleep(self.next_allowed - now)
+                now = self.next_allowed
+            self.next_allowed = now + self.interval
+
+
+class EntrezClient:
+    """NCBI E-utilities efetch client for nucleotide records
+
+    NCBI allows 3 requests per second, or 10 with an API key, and asks clients to identify
+    themselves with tool and email. Rate-limited and server-error responses are retried
+    with exponential backoff.
+    """
+
+    def __init__(self, api_key: str = None, email: str = None, tool: str = 'robin', base_url: str = EUTILS_URL,
+                 retries: int = 3, backoff: float = 1.0, timeout: float = 30,
+                 opener: Callable = urllib.request.urlopen, sleep: Callable[[float], None] = time.sleep):
+        self.api_key, self.email, self.tool = api_key, email, tool
+        self.base_url = base_url.rstrip('/')
+        self.retries, self.backoff, self.timeout = retries, backoff, timeout
+        self.opener, self.sleep = opener, sleep
+        self.limiter = RateLimiter(10 if api_key else 3, sleep=sleep)
+
+    def _params(self, **params) -> str:
+        params.update(tool=self.tool)
+        if self.email:
+            params['email'] = self.email
+        if self.api_key:
+            params['api_key'] = self.api_key
+        return urllib.parse.urlencode(params)
+
+    def _get(self, utility: str, **params) -> str:
+        url = f'{self.base_url}/{utility}.fcgi?{self._params(**params)}'
+        for attempt in range(self.retries + 1):
+            self.limiter.wait()
+            try:
+                with self.opener(url, timeout=self.timeout) as response:
+                    return response.read().decode('utf-8', errors='replace')
+            except urllib.error.HTTPError as e:
+                if e.code == 400:
+                    raise EntrezError(f'NCBI rejected the request for {params.get("id", "")}', 404) from e
+                if e.code not in RETRY_STATUSES or attempt == self.retries:
+                    raise EntrezError(f'NCBI E-utilities returned HTTP {e.code}') from e
+            except urllib.error.URLError as e:
+                if attempt == self.retries:
+                    raise EntrezError(f'Cannot reach NCBI E-utilities: {e.reason}') from e
+            self.sleep(self.backoff * 2 ** attempt)
+
+    def efetch(self, accession: str, rettype: str = 'gb', db: str = 'nuccore') -> str:
+        """Raw efetch text for one accession"""
+        accession = accession.strip()
+        if not ACCESSION_PATTERN.match(accession):
+            raise ValueError(f'"{accession}" is not a GenBank/RefSeq accession')
+        text = self._get('efetch', db=db, id=accession, rettype=rettype, retmode='text')
+        # efetch answers unknown accessions with an empty body or an error line, not a 404
+        if not text.strip() or text.lstrip().startswith(('Error', '<ERROR>')) or 'Failed to retrieve' in text:
+            raise EntrezError(f'Accession "{accession}" was not found', 404)
+        return text
+
+    def fetch_genbank(self, accession: str) -> GenBankRecord:
+        """GenBank record (sequence, topology, features) of a nucleotide accession"""
+        text = self.efetch(accession, 'gbwithparts')
+        try:
+            return parse_genbank(text)[0]
+        except ValueError as e:
+            raise EntrezError(f'NCBI returned no GenBank record for "{accession}"', 404) from e
+
+    def fetch_many(self, accessions: List[str]) -> List[GenBankRecord]:
+        """GenBank records for several accessions, fetched one by one under the rate limit"""
+        return [self.fetch_genbank(accession) for accession in accessions]
+
+
+def accession_list(text: Optional[str]) -> List[str]:
+    """Accessions from comma, space or newline separated text, without duplicates"""
+    seen = []
+    for accession in re.split(r'[\s,;]+', text or ''):
+        if accession and accession not in seen:
+            seen.append(accession)
+    return seen
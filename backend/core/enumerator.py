@@ -0,0 +1,680 @@
+import itertools
+import math
+from dataclasses import dataclass, field
+from typing import Dict, List, Optional, Set, Tuple
+from .complexes import Complex, complement_domain, parse_kernel
+from .context import check_cancelled
+
+SEMANTICS = ('condensed', 'infinite')
+# Helices of at most this many nucleotides open spontaneously (Peppercorn's release cutoff)
+DEFAULT_RELEASE_CUTOFF = 7
+DEFAULT_DOMAIN_LENGTH = 15
+# Domains marked as toeholds the Visual DSD way (t^) default to this length
+DEFAULT_TOEHOLD_LENGTH = 6
+
+# Rate model for infinite semantics: binding is diffusion-limited, a helix opens at the rate that
+# balances binding against its free energy (-1.4 kcal/mol per nucleotide plus 1.9 initiation at
+# 25 C), and branch migration is a random walk of one step per nucleotide
+BIND21_RATE = 3e6           # /M/s
+BIND11_RATE = 1e4           # /s, closing a loop within a complex
+HELIX_DG_PER_NT = -1.4      # kcal/mol
+HELIX_INITIATION_DG = 1.9   # kcal/mol
+RT = 0.0019872 * 298.15     # kcal/mol
+BRANCH_STEP_TIME = 2.5e-6   # s
+
+
+@dataclass(frozen=True)
+class _Species:
+    """Complex in canonical form: strands and the partner of each flattened domain (-1 when unpaired)"""
+    strands: Tuple[Tuple[str, ...], ...]
+    partners: Tuple[int, ...]
+
+    @property
+    def domains(self) -> List[str]:
+        return [name for strand in self.strands for name in strand]
+
+    def strand_of(self) -> List[int]:
+        return [s for s, strand in enumerate(self.strands) for _ in strand]
+
+    def to_complex(self) -> Complex:
+        structure, index = [], 0
+        for s, strand in enumerate(self.strands):
+            if s:
+                structure.append('+')
+            for _ in strand:
+                partner = self.partners[index]
+                structure.append('.' if partner < 0 else '(' if partner > index else ')')
+                index += 1
+        return Complex([list(strand) for strand in self.strands], ''.join(structure))
+
+
+def _nested(partners: List[int]) -> bool:
+    stack = []
+    for i, partner in enumerate(partners):
+        if partner > i:
+            stack.append(i)
+        elif partner >= 0 and (not stack or stack.pop() != partner):
+            return False
+    return True
+
+
+def _rotations(strands: List[Tuple[str, ...]], partners: List[int]) -> List[Tuple[tuple, tuple]]:
+    """Rotations of the strand order that keep the structure nested; each puts another nick outside"""
+    offsets = list(itertools.accumulate([0] + [len(strand) for strand in strands]))
+    total = offsets[-1]
+    rotations = []
+    for r in range(len(strands)):
+        shift = offsets[r]
+        rotated = [-1] * total
+        for i, partner in enumerate(partners):
+            rotated[(i - shift) % total] = -1 if partner < 0 else (partner - shift) % total
+        if _nested(rotated):
+            rotations.append((tuple(strands[r:] + strands[:r]), tuple(rotated)))
+    return rotations
+
+
+def _canonical(strands: List[Tuple[str, ...]], partners: List[int]) -> _Species:
+    """Least rotation of the strand order that keeps the structure nested"""
+    return _Species(*min(_rotations(strands, partners)))
+
+
+def _split(strands: List[Tuple[str, ...]], partners: List[int]) -> List[_Species]:
+    """Connected complexes of a set of strands, each in canonical form"""
+    strand_of = [s for s, strand in enumerate(strands) for _ in strand]
+    parent = list(range(len(strands)))
+
+    def find(s: int) -> int:
+        while parent[s] != s:
+            parent[s] = parent[parent[s]]
+            s = parent[s]
+        return s
+
+    for i, partner in enumerate(partners):
+        if partner > i:
+            parent[find(strand_of[i])] = find(strand_of[partner])
+    groups: Dict[int, List[int]] = {}
+    for s in range(len(strands)):
+        groups.setdefault(find(s), []).append(s)
+
+    offsets = list(itertools.accumulate([0] + [len(strand) for strand in strands]))
+    products = []
+    for members in groups.values():
+        index = {}
+        for s in members:
+            for i in range(offsets[s], offsets[s + 1]):
+                index[i] = len(index)
+        sub = [-1 if partners[i] < 0 else index[partners[i]] for i in sorted(index)]
+        products.append(_canonical([strands[s] for s in members], sub))
+    return products
+
+
+def _loops(partners: Tuple[int, ...]) -> List[List[int]]:
+    """Domains facing each loop in order around it, the exterior loop first; a stem shows both its ends"""
+    def walk(start: int, end: int) -> List[int]:
+        faces, i = [], start
+        while i < end:
+            if partners[i] > i:
+                faces += [i, partners[i]]
+                i = partners[i] + 1
+            else:
+                faces.append(i)
+                i += 1
+        return faces
+
+    loops = [walk(0, len(partners))]
+    for i, partner in enumerate(partners):
+        if partner > i:
+            loops.append([i] + walk(i + 1, partner) + [partner])
+    return loops
+
+
+@dataclass
+class Reaction:
+    """Reaction between named species: bind11, bind21, open, migrate or displace, or condensed
+
+    Under infinite semantics each reaction carries a rate constant from the rate model (/M/s for
+    bind21, /s otherwise); condensed reactions have none.
+    """
+    kind: str
+    reactants: Tuple[str, ...]
+    products: Tuple[str, ...]
+    rate: Optional[float] = None
+
+    def __str__(self) -> str:
+        return f'{self.kind}: {" + ".join(self.reactants)} -> {" + ".join(self.products)}'
+
+    def to_dict(self) -> Dict:
+        return {'kind': self.kind, 'reactants': list(self.reactants), 'products': list(self.products),
+                'rate': self.rate}
+
+
+@dataclass
+class Leak:
+    """Zero-toehold invasion of a target's helix end by an unpaired domain of an invader
+
+    The end is blunt when both strands of its outermost pair end there; otherwise an overhang
+    sits beside it that offers the invader no toehold.
+    """
+    invader: str
+    target: str
+    domain: str
+    blunt: bool
+    products: Tuple[str, ...]
+    outcomes: List[Tuple[str, ...]]     # resting states the products settle into
+    intended: bool                      # invader and target also react through a toehold
+    productive: bool                    # some outcome differs from the reactants' own resting states
+
+    @property
+    def kind(self) -> str:
+        return 'blunt' if self.blunt else 'zero-toehold'
+
+    def __str__(self) -> str:
+        settles = ' | '.join(' + '.join(outcome) for outcome in self.outcomes)
+        notes = [note for note, flag in (('productive', self.productive), ('intended', self.intended)) if flag]
+        return (f'{self.kind} {self.domain}: {self.invader} + {self.target} -> {" + ".join(self.products)}'
+                f' => {settles or "nothing"}' + (f' [{", ".join(notes)}]' if notes else ''))
+
+    def to_dict(self) -> Dict:
+        return {'kind': self.kind, 'invader': self.invader, 'target': self.target, 'domain': self.domain,
+                'products': list(self.products), 'outcomes': [list(outcome) for outcome in self.outcomes],
+                'intended': self.intended, 'productive': self.productive}
+
+
+@dataclass
+class LeakReport:
+    """Leak pathways between the initial complexes of a design
+
+    The score counts productive leaks between complexes with no intended reaction, so 0 is a
+    leak-free design; offenders rank complexes by the unintended leaks they take part in.
+    """
+    leaks: List[Leak]
+    species: Dict[str, Complex] = field(default_factory=dict)   # leak products not already enumerated
+
+    @property
+    def score(self) -> int:
+        return sum(1 for leak in self.leaks if leak.productive and not leak.intended)
+
+    def offenders(self) -> List[Dict]:
+        counts: Dict[str, Dict] = {}
+        for leak in self.leaks:
+            if leak.intended:
+                continue
+            for name, other in ((leak.invader, leak.target), (leak.target, leak.invader)):
+                entry = counts.setdefault(name, {'name': name, 'leaks': 0, 'productive': 0, 'partners': []})
+                entry['leaks'] += 1
+                entry['productive'] += int(leak.productive)
+                if other not in entry['partners']:
+                    entry['partners'].append(other)
+                if name == other:
+                    break
+        return sorted(counts.values(), key=lambda entry: (-entry['productive'], -entry['leaks'], entry['name']))
+
+    def format(self) -> str:
+        lines = [f'# leaks (score {self.score})']
+        lines += [f'{name} = {complex_.kernel()}' for name, complex_ in self.species.items()]
+        lines += [str(leak) for leak in self.leaks]
+        lines.append('# worst offenders')
+        lines += [f'{entry["name"]}: {entry["productive"]} productive of {entry["leaks"]} leaks, with '
+                  f'{", ".join(entry["partners"])}' for entry in self.offenders()]
+        return '\n'.join(lines) + '\n'
+
+    def to_dict(self) -> Dict:
+        return {
+            'score': self.score,
+            'leaks': [leak.to_dict() for leak in self.leaks],
+            'offenders': self.offenders(),
+            'species': [dict(complex_.to_dict(), name=name) for name, complex_ in self.species.items()]
+        }
+
+
+@dataclass
+class Enumeration:
+    """Reachable species and reactions; with condensed semantics, resting states group the complexes"""
+    semantics: str
+    species: Dict[str, Complex]
+    reactions: List[Reaction]
+    initial: List[str]
+    resting: Dict[str, List[str]] = field(default_factory=dict)
+    leaks: Optional[LeakReport] = None
+
+    def format(self) -> str:
+        """Plain-text listing: species in kernel notation, then reactions"""
+        lines = ['# species']
+        lines += [f'{name} = {complex_.kernel()}' for name, complex_ in self.species.items()]
+        if self.resting:
+            lines.append('# resting states')
+            lines += [f'{name}: {", ".join(members)}' for name, members in self.resting.items()]
+        lines.append('# reactions')
+        lines += [str(reaction) for reaction in self.reactions]
+        return '\n'.join(lines) + '\n' + (self.leaks.format() if self.leaks else '')
+
+    def to_dict(self) -> Dict:
+        return {
+            'semantics': self.semantics,
+            'initial': self.initial,
+            'species': [dict(complex_.to_dict(), name=name) for name, complex_ in self.species.items()],
+            'resting': [{'name': name, 'complexes': members} for name, members in self.resting.items()],
+            'reactions': [reaction.to_dict() for reaction in self.reactions],
+            'leaks': self.leaks.to_dict() if self.leaks else None
+        }
+
+
+def read_complexes(text: str) -> Dict[str, Complex]:
+    """Named complexes, one 'name = kernel' per line (# comments); unnamed lines are called c1, c2, ..."""
+    complexes: Dict[str, Complex] = {}
+    for number, line in enumerate(text.splitlines(), start=1):
+        line = line.split('#', 1)[0].strip()
+        if not line:
+            continue
+        name, _, kernel = line.rpartition('=')
+        name = name.strip() or f'c{len(complexes) + 1}'
+        if name in complexes:
+            raise ValueError(f'Line {number}: complex "{name}" is defined twice')
+        try:
+            complexes[name] = parse_kernel(kernel)
+        except ValueError as e:
+            raise ValueError(f'Line {number}: {e}') from e
+    if not complexes:
+        raise ValueError('At least one complex is required')
+    return complexes
+
+
+class ReactionEnumerator:
+    """Domain-level reaction enumeration in the manner of Peppercorn
+
+    Complexes react by binding complementary unpaired domains in the same loop (bind11) or in
+    the exterior loops of two complexes (bind21), by opening helices of at most release_cutoff
+    nucleotides (open), and by 3-way branch migration of an unpaired domain into a helix of
+    its loop (migrate, or displace when a strand falls off); with remote off the helix must be
+    adjacent to the invading domain. Under infinite semantics every step
+    is listed; under condensed semantics unimolecular steps are fast, complexes settle into
+    resting states, and the result lists bimolecular reactions between resting states with
+    the resting states they end in. Leak analysis adds zero-toehold and blunt-end invasions
+    between the initial complexes.
+    """
+
+    def __init__(self, complexes: Dict[str, Complex], semantics: str = 'condensed', lengths: Dict[str, int] = None,
+                 release_cutoff: int = DEFAULT_RELEASE_CUTOFF, max_complex_size: int = 6, max_species: int = 200,
+                 remote: bool = True):
+        if semantics not in SEMANTICS:
+            raise ValueError(f'Unknown semantics "{semantics}"; use {" or ".join(SEMANTICS)}')
+        self.semantics = semantics
+        self.lengths = lengths or {}
+        self.release_cutoff = release_cutoff
+        self.max_complex_size = max_complex_size
+        self.max_species = max_species
+        self.remote = remote
+        self.names: Dict[_Species, str] = {}
+        self.initial: List[str] = []
+        for name, complex_ in complexes.items():
+            partners = [complex_.pairs.get(i, -1) for i in range(len(complex_.domains))]
+            species = _canonical([tuple(strand) for strand in complex_.strands], partners)
+            if species in self.names:
+                raise ValueError(f'Complexes {self.names[species]} and {name} are the same')
+            self.names[species] = name
+            self.initial.append(name)
+        self._unimolecular: Dict[_Species, List[Tuple[str, Tuple[_Species, ...]]]] = {}
+        # Rate constant of each (reactants, kind, products) step
+        self._rates: Dict[Tuple[Tuple[_Species, ...], str, Tuple[_Species, ...]], float] = {}
+        # Resting-state bookkeeping for condensed semantics, shared with leak analysis
+        self._resting_of: Dict[_Species, str] = {}
+        self._resting: Dict[str, List[_Species]] = {}
+        self._transient: Set[_Species] = set()
+        self._fates_memo: Dict[int, Set[Tuple[str, ...]]] = {}
+        self._component: Dict[_Species, int] = {}
+
+    def length(self, domain: str) -> int:
+        base = domain.rstrip('*')
+        if base in self.lengths:
+            return int(self.lengths[base])
+        return DEFAULT_TOEHOLD_LENGTH if base.endswith('^') else DEFAULT_DOMAIN_LENGTH
+
+    def name(self, species: _Species) -> str:
+        if species not in self.names:
+            if len(self.names) >= self.max_species:
+                raise ValueError(f'Enumeration passed {self.max_species} species; the system may polymerize')
+            taken = set(self.names.values())
+            number = len(self.names) + 1
+            while f'e{number}' in taken:
+                number += 1
+            self.names[species] = f'e{number}'
+        return self.names[species]
+
+    @staticmethod
+    def open_rate(length: int) -> float:
+        """Rate (/s) at which a helix of this many nucleotides comes apart"""
+        return BIND21_RATE * math.exp((HELIX_INITIATION_DG + HELIX_DG_PER_NT * length) / RT)
+
+    def unimolecular(self, species: _Species) -> List[Tuple[str, Tuple[_Species, ...]]]:
+        """(kind, products) of every bind11, open and 3-way branch migration of a complex"""
+        if species in self._unimolecular:
+            return self._unimolecular[species]
+        domains, partners = species.domains, species.partners
+        strand_of = species.strand_of()
+        strands = list(species.strands)
+        reactions = []
+
+        def react(kind: str, changes: Dict[int, int], rate: float):
+            updated = list(partners)
+            for i, partner in changes.items():
+                updated[i] = partner
+            products = tuple(_split(strands, updated))
+            kind = 'displace' if kind == 'migrate' and len(products) > 1 else kind
+            reactions.append((kind, products))
+            self._rates.setdefault(((species,), kind, products), rate)
+
+        for loop in _loops(partners):
+            unpaired = [i for i in loop if partners[i] < 0]
+            for a, i in enumerate(unpaired):
+                for j in unpaired[a + 1:]:
+                    # Neighbouring domains of one strand cannot close a hairpin with no loop
+                    if domains[j] == complement_domain(domains[i]) and not (j == i + 1 and strand_of[i] == strand_of[j]):
+                        react('bind11', {i: j, j: i}, BIND11_RATE)
+            for position, i in enumerate(loop):
+                if partners[i] >= 0:
+                    continue
+                neighbours = {loop[position - 1], loop[(position + 1) % len(loop)]}
+                for j in loop:
+                    k = partners[j]
+                    # Every stem in the loop shows both its ends, so each pair is tried once per end
+                    if k >= 0 and domains[i] == domains[j] and (self.remote or neighbours & {j, k}):
+                        react('migrate', {i: k, k: i, j: -1}, 1 / (BRANCH_STEP_TIME * self.length(domains[i]) ** 2))
+
+        for i, partner in enumerate(partners):
+            # One open per helix, from its outermost pair
+            if partner <= i or (i > 0 and partner + 1 < len(partners) and partners[i - 1] == partner + 1
+                                and strand_of[i - 1] == strand_of[i] and strand_of[partner + 1] == strand_of[partner]):
+                continue
+            helix, a, b = {}, i, partner
+            while a < b and partners[a] == b:
+                helix[a], helix[b] = -1, -1
+                if not (a + 1 < b - 1 and strand_of[a + 1] == strand_of[a] and strand_of[b - 1] == strand_of[b]):
+                    break
+                a, b = a + 1, b - 1
+            length = sum(self.length(domains[x]) for x in helix if x < partners[x])
+            if length <= self.release_cutoff:
+                react('open', helix, self.open_rate(length))
+
+        unique = []
+        for reaction in reactions:
+            if reaction not in unique:
+                unique.append(reaction)
+        self._unimolecular[species] = unique
+        return unique
+
+    def bimolecular(self, a: _Species, b: _Species) -> List[Tuple[str, Tuple[_Species, ...]]]:
+        """bind21 products of two complexes through complementary domains in their exterior loops"""
+        if len(a.strands) + len(b.strands) > self.max_complex_size:
+            return []
+        exterior_a = [i for i in _loops(a.partners)[0] if a.partners[i] < 0]
+        exterior_b = [i for i in _loops(b.partners)[0] if b.partners[i] < 0]
+        domains_a, domains_b = a.domains, b.domains
+        offset = len(domains_a)
+        reactions = []
+        for i in exterior_a:
+            for j in exterior_b:
+                if domains_b[j] == complement_domain(domains_a[i]):
+                    partners = list(a.partners) + [p + offset if p >= 0 else -1 for p in b.partners]
+                    partners[i], partners[offset + j] = offset + j, i
+                    product = ('bind21', tuple(_split(list(a.strands) + list(b.strands), partners)))
+                    if product not in reactions:
+                        reactions.append(product)
+                        self._rates.setdefault(((a, b), 'bind21', product[1]), BIND21_RATE)
+        return reactions
+
+    def invasions(self, a: _Species, b: _Species) -> List[Tuple[int, str, bool, Tuple[_Species, ...]]]:
+        """(invading domain, displaced domain name, blunt, products) of zero-toehold invasions of b by a
+
+        An unpaired domain in a's exterior loop takes over the outermost pair of a helix end that
+        b shows on an open loop when it matches the domain on one side of that pair.
+        """
+        if len(a.strands) + len(b.strands) > self.max_complex_size:
+            return []
+        domains_a, offset = a.domains, len(a.partners)
+        exterior_a = [i for i in _loops(a.partners)[0] if a.partners[i] < 0]
+        invasions: Dict[Tuple[int, str, Tuple[_Species, ...]], bool] = {}
+        # Every loop with a nick is open to solution; rotating that nick outside makes it b's exterior loop
+        for strands, partners_b in _rotations(list(b.strands), list(b.partners)):
+            domains_b = [name for strand in strands for name in strand]
+            strand_of = [s for s, strand in enumerate(strands) for _ in strand]
+            for p in _loops(partners_b)[0]:
+                q = partners_b[p]
+                if q <= p:
+                    continue
+                blunt = (p == 0 or strand_of[p - 1] != strand_of[p]) and \
+                    (q + 1 == len(domains_b) or strand_of[q + 1] != strand_of[q])
+                for i in exterior_a:
+                    for displaced, kept in ((p, q), (q, p)):
+                        if domains_a[i] != domains_b[displaced]:
+                            continue
+                        partners = list(a.partners) + [x + offset if x >= 0 else -1 for x in partners_b]
+                        partners[i], partners[offset + kept], partners[offset + displaced] = offset + kept, i, -1
+                        products = tuple(_split(list(a.strands) + list(strands), partners))
+                        # A one-domain helix shows both of its ends; either being blunt makes the pathway blunt
+                        key = (i, domains_b[displaced], products)
+                        invasions[key] = invasions.get(key, False) or blunt
+        return [(i, domain, blunt, products) for (i, domain, products), blunt in invasions.items()]
+
+    def leaks(self, enumeration: Enumeration) -> LeakReport:
+        """Zero-toehold and blunt-end invasions between the initial complexes, and where they settle"""
+        initial = sorted((species for species in self.names if self.names[species] in self.initial),
+                         key=lambda species: self.initial.index(self.names[species]))
+        self._settle(initial)
+        reacting = {tuple(sorted(reaction.reactants)) for reaction in enumeration.reactions
+                    if len(reaction.reactants) == 2}
+
+        def state(species: _Species) -> str:
+            return self._resting_of.get(species, self.names[species]) if self.semantics == 'condensed' \
+                else self.names[species]
+
+        leaks: List[Leak] = []
+        reached: List[_Species] = []
+        for x, a in enumerate(initial):
+            for b in initial[x:]:
+                unchanged = {tuple(sorted(fa + fb)) for fa in self._fates(a) for fb in self._fates(b)}
+                pairs = [(a, b)] if a == b else [(a, b), (b, a)]
+                for invader, target in pairs:
+                    for i, domain, blunt, products in self.invasions(invader, target):
+                        outcomes = sorted(self._outcomes(products))
+                        reached += list(products) + [node for outcome in outcomes for name in outcome
+                                                     for node in self._resting[name]]
+                        leak = Leak(self.names[invader], self.names[target], domain, blunt,
+                                    tuple(sorted(self.name(product) for product in products)), outcomes,
+                                    tuple(sorted((state(invader), state(target)))) in reacting,
+                                    any(outcome not in unchanged for outcome in outcomes))
+                        if leak not in leaks:
+                            leaks.append(leak)
+        extra = [node for node in reached if self.names[node] not in enumeration.species]
+        return LeakReport(leaks, self._species(extra) if extra else {})
+
+    def enumerate(self) -> Enumeration:
+        if self.semantics == 'infinite':
+            return self._enumerate_infinite()
+        return self._enumerate_condensed()
+
+    def _record(self, reactions: List[Reaction], kind: str, reactants: List[_Species], products: Tuple[_Species, ...]):
+        reaction = Reaction(kind, tuple(self.name(s) for s in reactants), tuple(sorted(self.name(p) for p in products)),
+                            self._rates.get((tuple(reactants), kind, products)))
+        if reaction not in reactions:
+            reactions.append(reaction)
+
+    def _enumerate_infinite(self) -> Enumeration:
+        queue = list(self.names)
+        done: List[_Species] = []
+        reactions: List[Reaction] = []
+        while queue:
+            check_cancelled()
+            species = queue.pop(0)
+            done.append(species)
+            steps = [([species], kind, products) for kind, products in self.unimolecular(species)]
+            steps += [([other, species], kind, products)
+                      for other in done for kind, products in self.bimolecular(other, species)]
+            for reactants, kind, products in steps:
+                for product in products:
+                    self.name(product)
+                    if product not in done and product not in queue:
+                        queue.append(product)
+                self._record(reactions, kind, reactants, products)
+        return Enumeration('infinite', self._species(done), reactions, self.initial)
+
+    def _fast_closure(self, start: List[_Species]) -> List[_Species]:
+        seen, queue = list(start), list(start)
+        while queue:
+            check_cancelled()
+            for _, products in self.unimolecular(queue.pop(0)):
+                for product in products:
+                    if product not in seen:
+                        self.name(product)
+                        seen.append(product)
+                        queue.append(product)
+        return seen
+
+    def _components(self, nodes: List[_Species]) -> Dict[_Species, int]:
+        """Strongly connected component of each complex over single-product fast reactions (Tarjan)"""
+        index, low, stack, on_stack, component = {}, {}, [], set(), {}
+        counter = itertools.count()
+
+        def successors(node: _Species) -> List[_Species]:
+            return [products[0] for _, products in self.unimolecular(node) if len(products) == 1]
+
+        for root in nodes:
+            if root in index:
+                continue
+            work = [(root, iter(successors(root)))]
+            index[root] = low[root] = next(counter)
+            stack.append(root)
+            on_stack.add(root)
+            while work:
+                node, children = work[-1]
+                child = next(children, None)
+                if child is not None:
+                    if child not in index:
+                        index[child] = low[child] = next(counter)
+                        stack.append(child)
+                        on_stack.add(child)
+                        work.append((child, iter(successors(child))))
+                    elif child in on_stack:
+                        low[node] = min(low[node], index[child])
+                    continue
+                work.pop()
+                if work:
+                    low[work[-1][0]] = min(low[work[-1][0]], low[node])
+                if low[node] == index[node]:
+                    number = len(set(component.values()))
+                    while True:
+                        member = stack.pop()
+                        on_stack.discard(member)
+                        component[member] = number
+                        if member == node:
+                            break
+        return component
+
+    def _settle(self, start: List[_Species]):
+        """Classify every complex fast-reachable from start as resting or transient"""
+        nodes = [node for node in self._fast_closure(start) if node not in self._resting_of and node not in self._transient]
+        if not nodes:
+            return
+        offset = len(set(self._component.values()))
+        local = self._components(nodes)
+        members: Dict[int, List[_Species]] = {}
+        for node, number in local.items():
+            self._component[node] = offset + number
+            members.setdefault(offset + number, []).append(node)
+        for number, group in members.items():
+            leaves = any(len(products) > 1 or self._component.get(products[0]) != number
+                         for node in group for _, products in self.unimolecular(node))
+            if leaves:
+                self._transient.update(group)
+                continue
+            group.sort(key=lambda node: (node not in self.names or self.names[node] not in self.initial,
+                                         self.name(node)))
+            name = self.name(group[0])
+            self._resting[name] = group
+            for node in group:
+                self._resting_of[node] = name
+
+    def _fates(self, node: _Species) -> Set[Tuple[str, ...]]:
+        """Multisets of resting states a settled complex can end up as through fast reactions"""
+        if node in self._resting_of:
+            return {(self._resting_of[node],)}
+        number = self._component[node]
+        if number in self._fates_memo:
+            return self._fates_memo[number]
+        self._fates_memo[number] = set()  # guards against revisiting while the component is being resolved
+        outcomes: Set[Tuple[str, ...]] = set()
+        group = [other for other, n in self._component.items() if n == number]
+        for member in group:
+            for _, products in self.unimolecular(member):
+                if len(products) == 1 and self._component.get(products[0]) == number:
+                    continue
+                for combination in itertools.product(*(self._fates(product) for product in products)):
+                    outcomes.add(tuple(sorted(name for part in combination for name in part)))
+        self._fates_memo[number] = outcomes
+        return outcomes
+
+    def _outcomes(self, products: Tuple[_Species, ...]) -> Set[Tuple[str, ...]]:
+        """Resting-state multisets that the products of a reaction settle into"""
+        self._settle(list(products))
+        return {tuple(sorted(name for part in combination for name in part))
+                for combination in itertools.product(*(self._fates(product) for product in products))}
+
+    def _enumerate_condensed(self) -> Enumeration:
+        resting, transient = self._resting, self._transient
+        initial = [species for species in self.names]
+        self._settle(initial)
+        reactions: List[Reaction] = []
+        for species in initial:
+            if species in transient:
+                for outcome in sorted(self._fates(species)):
+                    reactions.append(Reaction('condensed', (self.names[species],), outcome))
+
+        processed: List[str] = []
+        queue = list(resting)
+        while queue:
+            check_cancelled()
+            state = queue.pop(0)
+            processed.append(state)
+            for other in list(processed):
+                for a in resting[other]:
+                    for b in resting[state]:
+                        for _, products in self.bimolecular(a, b):
+                            for outcome in sorted(self._outcomes(products)):
+                                if outcome == tuple(sorted((other, state))):
+                                    continue
+                                reaction = Reaction('condensed', (other, state), outcome)
+                                if reaction not in reactions:
+                                    reactions.append(reaction)
+                                for name in outcome:
+                                    if name not in processed and name not in queue:
+                                        queue.append(name)
+
+        kept = [node for name in processed for node in resting[name]]
+        kept += [node for node in initial if node in transient]
+        return Enumeration('condensed', self._species(kept), reactions, self.initial,
+                           {name: [self.names[node] for node in resting[name]] for name in processed})
+
+    def _species(self, nodes: List[_Species]) -> Dict[str, Complex]:
+        ordered = sorted(set(nodes), key=lambda node: (self.names[node] not in self.initial,
+                                                       self.initial.index(self.names[node])
+                                                       if self.names[node] in self.initial else 0,
+                                                       len(self.names[node]), self.names[node]))
+        return {self.names[node]: node.to_complex() for node in ordered}
+
+
+def canonical_kernel(text: str) -> str:
+    """Kernel notation of a complex in the form enumeration names it, whatever strand it starts from"""
+    complex_ = parse_kernel(text)
+    partners = [complex_.pairs.get(i, -1) for i in range(len(complex_.domains))]
+    return _canonical([tuple(strand) for strand in complex_.strands], partners).to_complex().kernel()
+
+
+def enumerate_reactions(text: str, semantics: str = 'condensed', lengths: Dict[str, int] = None,
+                        release_cutoff: int = DEFAULT_RELEASE_CUTOFF, max_complex_size: int = 6,
+                        max_species: int = 200, remote: bool = True, leak: bool = False) -> Enumeration:
+    """Enumerate the reaction network of complexes given as 'name = kernel' lines, optionally with leak analysis"""
+    enumerator = ReactionEnumerator(read_complexes(text), semantics, lengths, release_cutoff, max_complex_size,
+                                    max_species, remote)
+    enumeration = enumerator.enumerate()
+    if leak:
+        enumeration.leaks = enumerator.leaks(enumeration)
+    return enumeration
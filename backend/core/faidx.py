@@ -0,0 +1,164 @@
+"""
+Indexed FASTA access
+
+Reads and writes samtools faidx (.fai) indexes and fetches regions of large references
+through a memory map, so only the pages holding the requested bases are read from disk.
+"""
+
+import mmap
+import os
+from dataclasses import dataclass
+from typing import Dict, List, Optional, Tuple
+
+
+@dataclass
+class FaiEntry:
+    """One .fai line: sequence name, length, byte offset of the first base, bases and bytes per line"""
+    name: str
+    length: int
+    offset: int
+    line_bases: int
+    line_width: int
+
+    def byte_offset(self, position: int) -> int:
+        """File offset of a 0-based position within the sequence"""
+        return self.offset + (position // self.line_bases) * self.line_width + position % self.line_bases
+
+
+def build_index(path: str) -> List[FaiEntry]:
+    """Scan a FASTA file and index it
+
+    Within a record every line must hold the same number of bases, except the last one.
+    """
+    entries = []
+    current, ended = None, False  # ended: a short (final) line of the current record was seen
+    offset = 0
+    with open(path, 'rb') as handle:
+        for line_number, line in enumerate(handle, 1):
+            if line.startswith(b'>'):
+                fields = line[1:].split(None, 1)
+                if not fields:
+                    raise ValueError(f'Line {line_number}: FASTA header has no name')
+                current, ended = FaiEntry(fields[0].decode(), 0, offset + len(line), 0, 0), False
+                entries.append(current)
+            elif current is None:
+                if line.strip():
+                    raise ValueError(f'Line {line_number}: sequence before the first FASTA header')
+            else:
+                bases = len(line.rstrip(b'\r\n'))
+                if bases and (ended or (current.line_bases and bases > current.line_bases)):
+                    raise ValueError(f'Line {line_number}: uneven line lengths in "{current.name}"')
+                if not current.line_bases:
+                    current.line_bases, current.line_width = bases, len(line)
+                elif bases < current.line_bases or len(line) != current.line_width:
+                    ended = True
+                current.length += bases
+                if not bases and current.length:
+                    ended = True  # blank lines may only trail a record
+            offset += len(line)
+
+    names = [entry.name for entry in entries]
+    if len(set(names)) != len(names):
+        raise ValueError('Duplicate sequence names in FASTA file')
+    return entries
+
+
+def write_index(entries: List[FaiEntry], path: str):
+    """Write entries in .fai format"""
+    with open(path, 'w') as handle:
+        for entry in entries:
+            handle.write(f'{entry.name}\t{entry.length}\t{entry.offset}\t{entry.line_bases}\t{entry.line_width}\n')
+
+
+def read_index(path: str) -> List[FaiEntry]:
+    """Read a .fai file (extra columns, as in FASTQ indexes, are ignored)"""
+    entries = []
+    with open(path) as handle:
+        for line_number, line in enumerate(handle, 1):
+            if not line.strip():
+                continue
+            fields = line.rstrip('\n').split('\t')
+            if len(fields) < 5:
+                raise ValueError(f'{path} line {line_number}: expected 5 tab-separated columns')
+            entries.append(FaiEntry(fields[0], *(int(value) for value in fields[1:5])))
+    return entries
+
+
+def parse_region(region: str, names=()) -> Tuple[str, Optional[int], Optional[int]]:
+    """samtools-style region 'name', 'name:start' or 'name:start-end' (1-based, inclusive)
+
+    Returns the name with a 0-based, end-exclusive range; None means the sequence start or end.
+    A name that itself contains ':' is matched whole when it is among the known names.
+    """
+    region = region.strip()
+    if region in names or ':' not in region:
+        return region, None, None
+    name, _, span = region.rpartition(':')
+    start, _, end = span.replace(',', '').partition('-')
+    try:
+        start = int(start) - 1 if start else None
+        end = int(end) if end else None
+    except ValueError:
+        raise ValueError(f'Invalid region "{region}"')
+    if (start is not None and start < 0) or (start is not None and end is not None and end <= start):
+        raise ValueError(f'Invalid region "{region}"')
+    return name, start, end
+
+
+class IndexedFasta:
+    """Random access to a FASTA file through its .fai index and a memory map
+
+    The index is read from path + '.fai', or built (and saved there when save_index is
+    set) if it does not exist. Use as a context manager, or call close().
+    """
+
+    def __init__(self, path: str, index_path: str = None, save_index: bool = True):
+        self.path = path
+        index_path = index_path or path + '.fai'
+        if os.path.exists(index_path):
+            entries = read_index(index_path)
+        else:
+            entries = build_index(path)
+            if save_index:
+                write_index(entries, index_path)
+        self.index: Dict[str, FaiEntry] = {entry.name: entry for entry in entries}
+        self._file = open(path, 'rb')
+        size = os.fstat(self._file.fileno()).st_size
+        self._map = mmap.mmap(self._file.fileno(), 0, access=mmap.ACCESS_READ) if size else b''
+
+    @property
+    def names(self) -> List[str]:
+        return list(self.index)
+
+    def length(self, name: str) -> int:
+        return self._entry(name).length
+
+    def _entry(self, name: str) -> FaiEntry:
+        if name not in self.index:
+            raise KeyError(f'Sequence "{name}" is not in {self.path}')
+        return self.index[name]
+
+    def fetch(self, name: str, start: int = None, end: int = None) -> str:
+        """Bases [start, end) of a sequence (0-based), as stored (case kept); clipped to the sequence"""
+        entry = self._entry(name)
+        start = max(start or 0, 0)
+        end = entry.length if end is None else min(end, entry.length)
+        if start >= end:
+            return ''
+        raw = self._map[entry.byte_offset(start):entry.byte_offset(end - 1) + 1]
+        return raw.replace(b'\n', b'').replace(b'\r', b'').decode('ascii')
+
+    def fetch_region(self, region: str) -> str:
+        """Bases of a samtools-style region string, e.g. 'chr1:1,001-2,000'"""
+        return self.fetch(*parse_region(region, self.index))
+
+    def close(self):
+        if isinstance(self._map, mmap.mmap):
+            self._map.close()
+        self._file.close()
+
+    def __enter__(self) -> 'IndexedFasta':
+        return self
+
+    def __exit__(self, *exc_info):
+        self.close()
@@ -0,0 +1,608 @@
+import math
+from dataclasses import dataclass
+from typing import Dict, List, Tuple
+from xml.sax.saxutils import escape
+from .annealing import DG_INITIATION, DG_TERMINAL_AT, NN_DG37
+from .context import check_cancelled
+from .progress import Progress
+from .sequence import clean_sequence, reverse_complement
+from .structure import parse_dot_bracket, strand_breaks
+
+# SantaLucia (1998) unified nearest-neighbor ΔH (kcal/mol), keyed like NN_DG37; the two give
+# each stack's ΔS, so stacks (and the AT and initiation terms) follow ΔG = ΔH - TΔS
+_NN_DH = {
+    'AA': -7.9, 'AT': -7.2, 'TA': -7.2, 'CA': -8.5, 'GT': -8.4,
+    'CT': -7.8, 'GA': -8.2, 'CG': -10.6, 'GC': -9.8, 'GG': -8.0
+}
+NN_DH = {**{reverse_complement(pair): dh for pair, dh in _NN_DH.items()}, **_NN_DH}
+DH_INITIATION = 0.2
+DH_TERMINAL_AT = 2.2
+
+# SantaLucia & Hicks (2004) DNA loop initiation ΔG37 (kcal/mol) by loop size; sizes between
+# entries and beyond the last are extrapolated with the Jacobson-Stockmayer log term
+HAIRPIN_DG37 = {3: 3.5, 4: 3.5, 5: 3.3, 6: 4.0, 7: 4.2, 8: 4.3, 9: 4.5, 10: 4.6, 12: 5.0, 14: 5.1, 16: 5.3,
+                18: 5.5, 20: 5.7, 25: 6.1, 30: 6.3}
+BULGE_DG37 = {1: 4.0, 2: 2.9, 3: 3.1, 4: 3.2, 5: 3.3, 6: 3.5, 7: 3.7, 8: 3.9, 9: 4.1, 10: 4.3, 12: 4.5, 14: 4.8,
+              16: 5.0, 18: 5.2, 20: 5.3, 25: 5.6, 30: 5.9}
+# Size 2 (a single mismatch) stands in for the 1x1 mismatch table
+INTERNAL_DG37 = {2: 2.0, 3: 3.2, 4: 3.6, 5: 4.0, 6: 4.4, 7: 4.6, 8: 4.8, 9: 4.9, 10: 4.9, 12: 5.2, 14: 5.4,
+                 16: 5.6, 18: 5.8, 20: 5.9, 25: 6.3, 30: 6.6}
+ASYMMETRY_DG37 = 0.3
+# Multiloop closing, per-branch and per-unpaired-base terms
+MULTILOOP_A, MULTILOOP_C, MULTILOOP_B = 3.4, 0.4, 0.0
+
+MIN_HAIRPIN = 3
+MAX_LOOP = 30
+# The recursions are cubic in pure Python, so longer strands take too long for a request
+MAX_FOLD_LENGTH = 200
+GAS_CONSTANT = 1.98717e-3  # kcal/(mol K)
+
+PAIRS = {'AT', 'TA', 'GC', 'CG'}
+
+
+def _loop_dg(table: Dict[int, float], size: int, rt: float) -> float:
+    known = max(key for key in table if key <= size)
+    return table[known] + 2.44 * rt * math.log(size / known)
+
+
+def parse_constraint(constraint: str, length: int) -> Tuple[set, Dict[int, int], set]:
+    """Bases forced single-stranded, forced pairs (both directions) and bases that must pair
+
+    Constraint strings are dot-bracket with x for unpaired, | for paired with anything, ()
+    for a forced pair and . for unconstrained; strand breaks (&) are ignored.
+    """
+    constraint = ''.join(constraint.split()).replace('&', '')
+    if not constraint:
+        return set(), {}, set()
+    if len(constraint) != length:
+        raise ValueError(f'Constraint has {len(constraint)} positions but the sequence has {length} bases')
+    single, forced, must_pair, stack = set(), {}, set(), []
+    for position, char in enumerate(constraint):
+        if char == 'x':
+            single.add(position)
+        elif char == '|':
+            must_pair.add(position)
+        elif char == '(':
+            stack.append(position)
+        elif char == ')':
+            if not stack:
+                raise ValueError(f'Unbalanced ")" at constraint position {position + 1}')
+            opening = stack.pop()
+            forced[opening], forced[position] = position, opening
+            must_pair.update((opening, position))
+        elif char != '.':
+            raise ValueError(f'Unexpected "{char}" in constraint; use . x | ( )')
+    if stack:
+        raise ValueError(f'Unbalanced "(" at constraint position {stack[-1] + 1}')
+    return single, forced, must_pair
+
+
+class EnergyModel:
+    """Simplified DNA nearest-neighbor model: Watson-Crick stacks, loop initiation and AT closure
+
+    Terminal mismatches, dangles and coaxial stacking are left out, so energies are coarser
+    than mfold's or NUPACK's but keep the same ordering for designed sequences. U reads as T.
+    """
+
+    def __init__(self, sequence: str, temperature: float = 37.0, cut: int = None, constraint: str = None):
+        self.sequence = clean_sequence(sequence).replace('U', 'T')
+        # Index of the second strand's first base when two strands are folded together
+        self.cut = cut
+        self.rt = GAS_CONSTANT * (temperature + 273.15)
+        # Loop terms are taken as purely entropic, so they scale with absolute temperature
+        self.loop_scale = (temperature + 273.15) / 310.15
+        self.single, self.forced, must_pair = parse_constraint(constraint or '', len(self.sequence))
+        for i, j in self.forced.items():
+            if i < j and not self._pairable(i, j):
+                raise ValueError(f'Bases {i + 1} and {j + 1} are constrained to pair but cannot')
+        # Running count of bases that must pair, so any stretch can be checked in constant time
+        self._must_pair = [0]
+        for position in range(len(self.sequence)):
+            self._must_pair.append(self._must_pair[-1] + (position in must_pair))
+
+    def nicked(self, i: int, j: int) -> bool:
+        """Whether the strand break lies between positions i and j"""
+        return self.cut is not None and i < self.cut <= j
+
+    def _pairable(self, i: int, j: int) -> bool:
+        return (j - i > MIN_HAIRPIN or self.nicked(i, j)) and self.sequence[i] + self.sequence[j] in PAIRS
+
+    def can_pair(self, i: int, j: int) -> bool:
+        if not self._pairable(i, j) or i in self.single or j in self.single:
+            return False
+        if self.forced.get(i, j) != j or self.forced.get(j, i) != i:
+            return False
+        # A pair may not cross a forced one
+        return not any(i < k < j < l or k < i < l < j for k, l in self.forced.items() if k < l)
+
+    def can_unpair(self, start: int, end: int) -> bool:
+        """Whether bases start..end-1 may all stay unpaired"""
+        return self._must_pair[end] == self._must_pair[start]
+
+    def _dg(self, dh: float, dg37: float) -> float:
+        """ΔG at the model's temperature from ΔH and ΔG37"""
+        return dh - self.loop_scale * (dh - dg37)
+
+    def stack(self, i: int, k: int) -> float:
+        """Stack of the pair at i on the pair at k, its 3' neighbour"""
+        pair = self.sequence[i] + self.sequence[k]
+        return self._dg(NN_DH[pair], NN_DG37[pair])
+
+    def terminal(self, i: int, j: int) -> float:
+        return self._dg(DH_TERMINAL_AT, DG_TERMINAL_AT) if self.sequence[i] in 'AT' else 0.0
+
+    def initiation(self) -> float:
+        """Bimolecular initiation penalty of a complex"""
+        return self._dg(DH_INITIATION, DG_INITIATION)
+
+    def hairpin(self, i: int, j: int) -> float:
+        return self.loop_scale * _loop_dg(HAIRPIN_DG37, j - i - 1, self.rt) + self.terminal(i, j)
+
+    def interior(self, i: int, j: int, k: int, l: int) -> float:
+        """Loop closed by (i, j) outside and (k, l) inside: a stack, bulge or internal loop"""
+        left, right = k - i - 1, j - l - 1
+        if left == right == 0:
+            return self.stack(i, k)
+        if left == 0 or right == 0:
+            size = left + right
+            dg = self.loop_scale * _loop_dg(BULGE_DG37, size, self.rt)
+            if size == 1:
+                # A single bulged base leaves the neighbouring pairs stacked
+                return dg + self.stack(i, k)
+            return dg + self.terminal(i, j) + self.terminal(k, l)
+        return (self.loop_scale * (_loop_dg(INTERNAL_DG37, left + right, self.rt) + ASYMMETRY_DG37 * abs(left - right))
+                + self.terminal(i, j) + self.terminal(k, l))
+
+
+@dataclass
+class Ensemble:
+    """Boltzmann ensemble of one strand's secondary structures, or of two joined at cut"""
+    sequence: str
+    temperature: float
+    partition: float
+    probabilities: Dict[Tuple[int, int], float]
+    cut: int = None
+
+    @property
+    def free_energy(self) -> float:
+        """Ensemble free energy -RT ln Q (kcal/mol)"""
+        return -GAS_CONSTANT * (self.temperature + 273.15) * math.log(self.partition)
+
+    def unpaired(self) -> List[float]:
+        unpaired = [1.0] * len(self.sequence)
+        for (i, j), p in self.probabilities.items():
+            unpaired[i] -= p
+            unpaired[j] -= p
+        return [max(p, 0.0) for p in unpaired]
+
+    def centroid(self) -> str:
+        """Dot-bracket of every pair with probability above one half, which never conflict"""
+        structure = ['.'] * len(self.sequence)
+        for (i, j), p in self.probabilities.items():
+            if p > 0.5:
+                structure[i], structure[j] = '(', ')'
+        if self.cut is not None:
+            structure.insert(self.cut, '&')
+        return ''.join(structure)
+
+    def defect(self, target: str) -> List[float]:
+        """Per-base ensemble defect: the probability each base is out of its state in target
+
+        Their sum is NUPACK's ensemble defect, the expected number of incorrectly paired bases.
+        """
+        target = ''.join(target.split())
+        pairs, breaks = parse_dot_bracket(target), strand_breaks(target)
+        if len(target) - len(breaks) != len(self.sequence):
+            raise ValueError(f'Target has {len(target) - len(breaks)} bases but the sequence has {len(self.sequence)}')
+        if breaks != ([] if self.cut is None else [self.cut]):
+            raise ValueError("The target's strand breaks do not match the strands")
+        if any(bracket != '(' for _, _, bracket in pairs):
+            raise ValueError('Pseudoknotted targets are not supported')
+        partner = {}
+        for i, j, _ in pairs:
+            partner[i], partner[j] = j, i
+        unpaired = self.unpaired()
+        return [1 - self.probabilities.get((min(i, partner[i]), max(i, partner[i])), 0.0) if i in partner
+                else 1 - unpaired[i] for i in range(len(self.sequence))]
+
+    def probability(self, structure: str) -> float:
+        """Share of the ensemble in one dot-bracket structure"""
+        if self.cut is not None:
+            before = structure.split('&')[0]
+            # Unbound structures of two strands are outside a complex's ensemble
+            if before.count('(') == before.count(')'):
+                return 0.0
+        rt = GAS_CONSTANT * (self.temperature + 273.15)
+        return math.exp(-(structure_energy(self.sequence, structure, self.temperature) - self.free_energy) / rt)
+
+    def to_dict(self, threshold: float = 0.01) -> Dict:
+        return {
+            'length': len(self.sequence),
+            'cut': self.cut,
+            'temperature': self.temperature,
+            'ensemble_dg': round(self.free_energy, 2),
+            'centroid': self.centroid(),
+            'pairs': [{'i': i, 'j': j, 'probability': round(p, 4)}
+                      for (i, j), p in sorted(self.probabilities.items()) if p >= threshold],
+            'unpaired': [round(p, 4) for p in self.unpaired()]
+        }
+
+
+def _inside_outside(model: EnergyModel, progress: Progress = None,
+                    stage: str = 'fold') -> Tuple[float, Dict[Tuple[int, int], float]]:
+    """Partition function and pair probabilities of model's sequence, nicked at model.cut if set
+
+    The inside pass fills QB (i, j paired), QM1 (one multiloop branch starting at i), QM (one
+    or more branches) and the exterior prefix Z; the outside pass runs the same recursions
+    in reverse, so P(i, j) = QB(i, j) * outside(i, j) / Q. A loop containing the nick is open:
+    it costs only its closing pair's AT penalty and holds exterior-loop stretches on either
+    side of the nick (SA, the suffixes of the first strand, and PB, the prefixes of the second).
+    """
+    n = len(model.sequence)
+    # -1 never matches an index, so the nick checks below drop out for a single strand
+    cut = -1 if model.cut is None else model.cut
+    min_span = MIN_HAIRPIN + 1 if model.cut is None else 1
+
+    def boltzmann(dg: float) -> float:
+        return math.exp(-dg / model.rt)
+
+    branch = [[boltzmann(MULTILOOP_C + model.terminal(i, j)) if model.can_pair(i, j) else 0.0
+               for j in range(n)] for i in range(n)]
+    exterior = [[boltzmann(model.terminal(i, j)) for j in range(n)] for i in range(n)]
+    closing = [[boltzmann(MULTILOOP_A + MULTILOOP_C + model.terminal(i, j)) for j in range(n)] for i in range(n)]
+    unpaired = [boltzmann(MULTILOOP_B * length) for length in range(n + 1)]
+
+    def free_before(i: int, u: int) -> float:
+        """Weight of multiloop bases i..u-1 left unpaired before a branch at u"""
+        return unpaired[u - i] if not model.nicked(i, u) and model.can_unpair(i, u) else 0.0
+
+    def free_after(l: int, j: int) -> bool:
+        """Whether bases l+1..j after a branch closed at l may stay unpaired in a multiloop"""
+        return not model.nicked(l, j) and model.can_unpair(l + 1, j + 1)
+
+    def single(x: int) -> float:
+        return 1.0 if model.can_unpair(x, x + 1) else 0.0
+
+    def multi_splits(i: int, j: int) -> range:
+        """Split points u of a multiloop closed by (i, j); empty when the nick would fall in the loop"""
+        if cut in (i + 1, j):
+            return range(0)
+        return range(i + 2, j - 1)
+
+    qb = [[0.0] * n for _ in range(n)]
+    qm1 = [[0.0] * n for _ in range(n)]
+    qm = [[0.0] * n for _ in range(n)]
+    suffix_a = [1.0] * (n + 1)
+    prefix_b = [1.0] * (n + 1)
+    if model.cut is not None:
+        # Single bases next to the nick; longer stretches are filled in with the span loop
+        suffix_a[cut - 1], prefix_b[cut + 1] = single(cut - 1), single(cut)
+    # Inner loops of each pair, kept for the outside pass
+    loops: Dict[Tuple[int, int], List[Tuple[int, int, float]]] = {}
+    # Progress counts the cells of both passes
+    cells, done = 2 * sum(n - span for span in range(min_span, n)), 0
+
+    for span in range(min_span, n):
+        check_cancelled()
+        if progress:
+            progress.update(stage, done, cells)
+            done += n - span
+        for i in range(n - span):
+            j = i + span
+            if model.can_pair(i, j):
+                hairpin = not model.nicked(i, j) and model.can_unpair(i + 1, j)
+                total = boltzmann(model.hairpin(i, j)) if hairpin else 0.0
+                inner = []
+                for k in range(i + 1, min(i + MAX_LOOP + 2, j - min_span)):
+                    if model.nicked(i, k) or not model.can_unpair(i + 1, k):
+                        break
+                    for l in range(max(k + min_span, j - 1 - (MAX_LOOP - (k - i - 1))), j):
+                        if qb[k][l] and not model.nicked(l, j) and model.can_unpair(l + 1, j):
+                            weight = boltzmann(model.interior(i, j, k, l))
+                            inner.append((k, l, weight))
+                            total += weight * qb[k][l]
+                loops[i, j] = inner
+                total += closing[i][j] * sum(qm[i + 1][u - 1] * qm1[u][j - 1] for u in multi_splits(i, j) if u != cut)
+                if model.nicked(i, j):
+                    total += exterior[i][j] * suffix_a[i + 1] * prefix_b[j]
+                qb[i][j] = total
+            qm1[i][j] = sum(qb[i][l] * branch[i][l] * unpaired[j - l]
+                            for l in range(i + min_span, j + 1) if free_after(l, j))
+            qm[i][j] = sum((free_before(i, u)
+                            + (qm[i][u - 1] if i < u != cut else 0.0)) * qm1[u][j] for u in range(i, j + 1))
+            if j == cut - 1:
+                suffix_a[i] = suffix_a[i + 1] * single(i) + sum(qb[i][l] * exterior[i][l] * suffix_a[l + 1] for l in range(i, cut))
+            if i == cut:
+                prefix_b[j + 1] = prefix_b[j] * single(j) + sum(prefix_b[k] * qb[k][j] * exterior[k][j] for k in range(cut, j + 1))
+
+    # z[j] is the exterior partition function of seq[:j]
+    z = [1.0] * (n + 1)
+    for j in range(1, n + 1):
+        z[j] = z[j - 1] * single(j - 1) + sum(z[k] * qb[k][j - 1] * exterior[k][j - 1] for k in range(j - 1))
+
+    z_out = [0.0] * (n + 1)
+    z_out[n] = 1.0
+    qb_out = [[0.0] * n for _ in range(n)]
+    qm1_out = [[0.0] * n for _ in range(n)]
+    qm_out = [[0.0] * n for _ in range(n)]
+    suffix_a_out = [0.0] * (n + 1)
+    prefix_b_out = [0.0] * (n + 1)
+    for j in range(n, 0, -1):
+        z_out[j - 1] += z_out[j] * single(j - 1)
+        for k in range(j - 1):
+            if qb[k][j - 1]:
+                z_out[k] += z_out[j] * qb[k][j - 1] * exterior[k][j - 1]
+                qb_out[k][j - 1] += z_out[j] * z[k] * exterior[k][j - 1]
+
+    for span in range(n - 1, min_span - 1, -1):
+        check_cancelled()
+        if progress:
+            progress.update(stage, done, cells)
+            done += n - span
+        for i in range(n - span):
+            j = i + span
+            if i == cut and prefix_b_out[j + 1]:
+                outside = prefix_b_out[j + 1]
+                prefix_b_out[j] += outside * single(j)
+                for k in range(cut, j + 1):
+                    prefix_b_out[k] += outside * qb[k][j] * exterior[k][j]
+                    qb_out[k][j] += outside * prefix_b[k] * exterior[k][j]
+            if j == cut - 1 and suffix_a_out[i]:
+                outside = suffix_a_out[i]
+                suffix_a_out[i + 1] += outside * single(i)
+                for l in range(i, cut):
+                    suffix_a_out[l + 1] += outside * qb[i][l] * exterior[i][l]
+                    qb_out[i][l] += outside * suffix_a[l + 1] * exterior[i][l]
+            if qm_out[i][j]:
+                for u in range(i, j + 1):
+                    qm1_out[u][j] += qm_out[i][j] * (free_before(i, u)
+                                                     + (qm[i][u - 1] if i < u != cut else 0.0))
+                    if i < u != cut:
+                        qm_out[i][u - 1] += qm_out[i][j] * qm1[u][j]
+            if qm1_out[i][j]:
+                for l in range(i + min_span, j + 1):
+                    if free_after(l, j):
+                        qb_out[i][l] += qm1_out[i][j] * branch[i][l] * unpaired[j - l]
+            if qb[i][j] and qb_out[i][j]:
+                outside = qb_out[i][j]
+                for k, l, weight in loops[i, j]:
+                    qb_out[k][l] += outside * weight
+                for u in multi_splits(i, j):
+                    if u != cut:
+                        qm_out[i + 1][u - 1] += outside * closing[i][j] * qm1[u][j - 1]
+                        qm1_out[u][j - 1] += outside * closing[i][j] * qm[i + 1][u - 1]
+                if model.nicked(i, j):
+                    suffix_a_out[i + 1] += outside * exterior[i][j] * prefix_b[j]
+                    prefix_b_out[j] += outside * exterior[i][j] * suffix_a[i + 1]
+
+    probabilities = {(i, j): qb[i][j] * qb_out[i][j] / z[n]
+                     for i in range(n) for j in range(i + 1, n) if qb[i][j] * qb_out[i][j] > 0}
+    if progress:
+        progress.update(stage, cells, cells)
+    return z[n], probabilities
+
+
+def partition_function(sequence: str, temperature: float = 37.0, constraint: str = None,
+                       progress: Progress = None) -> Ensemble:
+    """McCaskill partition function and base-pair probabilities of a single strand
+
+    With a constraint (see parse_constraint) only the structures satisfying it are counted,
+    e.g. ask what a strand folds to when its toehold has to stay open. Pseudoknots are not
+    modelled.
+    """
+    model = EnergyModel(sequence, temperature, constraint=constraint)
+    if len(model.sequence) > MAX_FOLD_LENGTH:
+        raise ValueError(f'Sequences longer than {MAX_FOLD_LENGTH} nt are not supported')
+    if not model.sequence:
+        raise ValueError('A sequence is required')
+    partition, probabilities = _inside_outside(model, progress)
+    if not partition:
+        raise ValueError('No structure satisfies the constraint')
+    return Ensemble(model.sequence, temperature, partition, probabilities)
+
+
+@dataclass
+class Cofold(Ensemble):
+    """Ensemble of two strands bound together: every structure has at least one intermolecular pair
+
+    Pair probabilities are conditional on the strands being bound; indices run over a + b.
+    """
+    monomer_dg: Tuple[float, float] = (0.0, 0.0)
+
+    @property
+    def binding_dg(self) -> float:
+        """Free energy of forming the complex from the two folded strands (1 M standard state)"""
+        return self.free_energy - sum(self.monomer_dg)
+
+    def intermolecular(self) -> List[Tuple[int, int, float]]:
+        return [(i, j, p) for (i, j), p in sorted(self.probabilities.items()) if i < self.cut <= j]
+
+    def to_dict(self, threshold: float = 0.01) -> Dict:
+        return dict(super().to_dict(threshold), binding_dg=round(self.binding_dg, 2),
+                    monomer_dg=[round(dg, 2) for dg in self.monomer_dg])
+
+
+def cofold(a: str, b: str, temperature: float = 37.0, constraint: str = None, progress: Progress = None) -> Cofold:
+    """Joint structure ensemble of strands a and b, e.g. a probe and its target
+
+    The two strands are folded as a + b with a strand break. Structures without an
+    intermolecular pair are the two monomers side by side, Q(a) * Q(b), so they are
+    subtracted; the rest pays the bimolecular initiation penalty. A constraint covers a + b
+    (optionally with & between them); binding is still measured from the unconstrained strands.
+    """
+    a, b = clean_sequence(a).replace('U', 'T'), clean_sequence(b).replace('U', 'T')
+    if not a or not b:
+        raise ValueError('Two sequences are required')
+    if len(a) + len(b) > MAX_FOLD_LENGTH:
+        raise ValueError(f'Strands longer than {MAX_FOLD_LENGTH} nt combined are not supported')
+
+    model = EnergyModel(a + b, temperature, cut=len(a), constraint=constraint)
+    joint, probabilities = _inside_outside(model, progress, 'fold a + b')
+    partition_a, probabilities_a = _inside_outside(EnergyModel(a, temperature), progress, 'fold a')
+    partition_b, probabilities_b = _inside_outside(EnergyModel(b, temperature), progress, 'fold b')
+    monomer_dg = (-model.rt * math.log(partition_a), -model.rt * math.log(partition_b))
+    if constraint:
+        constraint = ''.join(constraint.split()).replace('&', '')
+        if any(i < len(a) <= j for i, j in model.forced.items()):
+            # A forced intermolecular pair leaves no unbound structures
+            separate_a = separate_b = 0.0
+        else:
+            separate_a, probabilities_a = _inside_outside(EnergyModel(a, temperature, constraint=constraint[:len(a)]),
+                                                          progress, 'fold a constrained')
+            separate_b, probabilities_b = _inside_outside(EnergyModel(b, temperature, constraint=constraint[len(a):]),
+                                                          progress, 'fold b constrained')
+    else:
+        separate_a, separate_b = partition_a, partition_b
+    separate = separate_a * separate_b
+    bound = joint - separate
+    if bound <= joint * 1e-12:
+        raise ValueError('The strands have no intermolecular pairs')
+
+    monomer = dict(probabilities_a)
+    monomer.update({(i + len(a), j + len(a)): p for (i, j), p in probabilities_b.items()})
+    dimer = {}
+    for pair, p in probabilities.items():
+        p = (p * joint - monomer.get(pair, 0.0) * separate) / bound
+        if p > 1e-12:
+            dimer[pair] = p
+
+    return Cofold(a + b, temperature, bound * math.exp(-model.initiation() / model.rt), dimer, cut=len(a),
+                  monomer_dg=monomer_dg)
+
+
+def _crossing(points: List[Dict], key: str, level: float) -> float:
+    """Temperature where points[key] first falls through level, interpolated; None if it never does"""
+    for before, after in zip(points, points[1:]):
+        if before[key] >= level > after[key]:
+            share = (before[key] - level) / (before[key] - after[key])
+            return round(before['temperature'] + share * (after['temperature'] - before['temperature']), 1)
+    return None
+
+
+def melt_curve(a: str, b: str = None, temperatures: List[float] = None, concentration: float = 250.0) -> Dict:
+    """Predicted melt of a strand, or of a duplex of a and b, over a range of temperatures
+
+    A strand's curve is the mean pairing probability of its bases, with Tm where it falls to half
+    its low-temperature value. A duplex's is the bound fraction of two strands each at
+    concentration (nM), from the two-state equilibrium of the co-fold binding energy, with Tm
+    where half is bound. The parameters are for 1 M Na+, so Tm runs above that in typical buffers.
+    """
+    temperatures = list(temperatures if temperatures is not None else range(20, 95, 5))
+    if not temperatures or len(temperatures) > 50:
+        raise ValueError('Between 1 and 50 temperatures are supported')
+    if b and concentration <= 0:
+        raise ValueError('concentration must be positive')
+
+    points = []
+    for temperature in temperatures:
+        if b:
+            ensemble = cofold(a, b, temperature)
+            # K [C] (1 - x)^2 = x for equal strand concentrations
+            kc = math.exp(-ensemble.binding_dg / (GAS_CONSTANT * (temperature + 273.15))) * concentration * 1e-9
+            bound = 1.0 if kc > 1e12 else ((2 * kc + 1) - math.sqrt(4 * kc + 1)) / (2 * kc)
+            points.append({'temperature': temperature, 'ensemble_dg': round(ensemble.free_energy, 2),
+                           'binding_dg': round(ensemble.binding_dg, 2), 'bound': round(bound, 4)})
+        else:
+            ensemble = partition_function(a, temperature)
+            unpaired = ensemble.unpaired()
+            points.append({'temperature': temperature, 'ensemble_dg': round(ensemble.free_energy, 2),
+                           'paired': round(1 - sum(unpaired) / len(unpaired), 4)})
+
+    key = 'bound' if b else 'paired'
+    level = 0.5 if b else points[0][key] / 2
+    return {'points': points, 'tm': _crossing(points, key, level) if level > 0 else None}
+
+
+def render_dot_plot_svg(ensemble: Ensemble, size: int = 500, threshold: float = 1e-3) -> str:
+    """Base-pair probability dot plot: squares of area p above the diagonal, centroid pairs below"""
+    n = len(ensemble.sequence)
+    margin = 40
+    scale = size / n
+    parts = [
+        f'<svg xmlns="http://www.w3.org/2000/svg" width="{size + 2 * margin:.0f}" '
+        f'height="{size + 2 * margin:.0f}" font-family="sans-serif" font-size="11">',
+        f'<rect x="{margin}" y="{margin}" width="{size}" height="{size}" fill="white" stroke="#9ca3af"/>',
+        f'<line x1="{margin}" y1="{margin}" x2="{margin + size}" y2="{margin + size}" stroke="#d1d5db"/>',
+        f'<text x="{margin + size / 2:.1f}" y="{margin - 12}" text-anchor="middle">'
+        f'{n} nt, ensemble ΔG {ensemble.free_energy:.2f} kcal/mol</text>'
+    ]
+    if ensemble.cut is not None:
+        # Strand break: intermolecular pairs fall in the top-right block
+        edge = margin + ensemble.cut * scale
+        parts.append(f'<line x1="{edge:.2f}" y1="{margin}" x2="{edge:.2f}" y2="{margin + size}" stroke="#2563eb"/>')
+        parts.append(f'<line x1="{margin}" y1="{edge:.2f}" x2="{margin + size}" y2="{edge:.2f}" stroke="#2563eb"/>')
+    if n <= 80:
+        for index, base in enumerate(ensemble.sequence):
+            position = margin + (index + 0.5) * scale
+            parts.append(f'<text x="{position:.1f}" y="{margin - 2}" font-size="{min(scale, 11):.1f}" '
+                         f'text-anchor="middle">{escape(base)}</text>')
+            parts.append(f'<text x="{margin - 4}" y="{position + min(scale, 11) / 3:.1f}" '
+                         f'font-size="{min(scale, 11):.1f}" text-anchor="end">{escape(base)}</text>')
+    for (i, j), p in sorted(ensemble.probabilities.items()):
+        if p < threshold:
+            continue
+        side = scale * math.sqrt(p)
+        parts.append(f'<rect x="{margin + (j + 0.5) * scale - side / 2:.2f}" y="{margin + (i + 0.5) * scale - side / 2:.2f}" '
+                     f'width="{side:.2f}" height="{side:.2f}" fill="#1f2937"><title>{i + 1}-{j + 1}: {p:.3f}</title></rect>')
+        if p > 0.5:
+            parts.append(f'<rect x="{margin + i * scale:.2f}" y="{margin + j * scale:.2f}" width="{scale:.2f}" '
+                         f'height="{scale:.2f}" fill="#dc2626"/>')
+    parts.append('</svg>')
+    return '\n'.join(parts)
+
+
+def structure_energy(sequence: str, structure: str, temperature: float = 37.0) -> float:
+    """Free energy (kcal/mol) of one pseudoknot-free dot-bracket structure under the same model
+
+    A single & splits two strands, sequence being their concatenation; a complex pays the
+    initiation penalty and loops holding the break cost only their pairs' AT penalties.
+    """
+    if structure.count('&') > 1:
+        raise ValueError('Only two strands are supported')
+    cut = structure.find('&')
+    structure = structure.replace('&', '')
+    model = EnergyModel(sequence, temperature, cut if cut >= 0 else None)
+    if len(structure) != len(model.sequence):
+        raise ValueError(f'Sequence has {len(model.sequence)} bases but the structure has {len(structure)}')
+    partner, stack = [-1] * len(structure), []
+    for position, char in enumerate(structure):
+        if char == '(':
+            stack.append(position)
+        elif char == ')':
+            if not stack:
+                raise ValueError(f'Unbalanced ")" at base {position + 1}')
+            opening = stack.pop()
+            if not model.can_pair(opening, position):
+                raise ValueError(f'Bases {opening + 1} and {position + 1} cannot pair')
+            partner[opening], partner[position] = position, opening
+        elif char != '.':
+            raise ValueError(f'Unexpected "{char}"; only . ( ) & are supported')
+    if stack:
+        raise ValueError(f'Unbalanced "(" at base {stack[-1] + 1}')
+
+    def branches(start: int, end: int) -> List[Tuple[int, int]]:
+        found, position = [], start
+        while position < end:
+            if partner[position] > position:
+                found.append((position, partner[position]))
+                position = partner[position] + 1
+            else:
+                position += 1
+        return found
+
+    dg = sum(model.terminal(i, j) for i, j in branches(0, len(structure)))
+    pairs = [(i, partner[i]) for i in range(len(structure)) if partner[i] > i]
+    if any(model.nicked(i, j) for i, j in pairs):
+        dg += model.initiation()
+    for i, j in pairs:
+        inner = branches(i + 1, j)
+        if model.nicked(i, j) and not any(model.nicked(k, l) for k, l in inner):
+            dg += model.terminal(i, j) + sum(model.terminal(k, l) for k, l in inner)
+        elif not inner:
+            dg += model.hairpin(i, j)
+        elif len(inner) == 1:
+            dg += model.interior(i, j, *inner[0])
+        else:
+            dg += MULTILOOP_A + MULTILOOP_C * (len(inner) + 1) + model.terminal(i, j)
+            dg += sum(model.terminal(k, l) for k, l in inner)
+    return dg
@@ -0,0 +1,78 @@
+import math
+from typing import Dict, List
+from xml.sax.saxutils import escape
+
+# Band sizes (bp) of common DNA ladders, largest first
+LADDERS = {
+    '1kb': [10000, 8000, 6000, 5000, 4000, 3000, 2000, 1500, 1000, 500, 250],
+    '1kb_plus': [10000, 8000, 6000, 5000, 4000, 3000, 2000, 1500, 1000, 700, 500, 400, 300, 200, 75],
+    '100bp': [1517, 1200, 1000, 900, 800, 700, 600, 500, 400, 300, 200, 100],
+    '50bp': [1350, 916, 766, 700, 650, 600, 550, 500, 450, 400, 350, 300, 250, 200, 150, 100, 50],
+    'lambda_hindiii': [23130, 9416, 6557, 4361, 2322, 2027, 564, 125],
+}
+
+
+def _migration(size: int, smallest: int, largest: int) -> float:
+    """Relative migration (0 at the well, 1 at the bottom); distance is roughly linear in log(size)"""
+    low, high = math.log10(smallest), math.log10(largest)
+    clamped = min(max(size, smallest), largest)
+    return (high - math.log10(clamped)) / (high - low) if high > low else 0.5
+
+
+def lane_bands(sizes: List[int]) -> List[Dict]:
+    """Bands of a lane: equal sizes co-migrate, and brightness follows DNA mass
+
+    For equimolar fragments (a digest or PCR) the mass, and so the stain, is proportional
+    to size times copies; intensity is scaled to the brightest band of the lane.
+    """
+    copies = {}
+    for size in sizes:
+        if size <= 0:
+            raise ValueError(f'Fragment sizes must be positive, got {size}')
+        copies[int(size)] = copies.get(int(size), 0) + 1
+    if not copies:
+        return []
+    brightest = max(size * count for size, count in copies.items())
+    return [{'size': size, 'copies': count, 'intensity': round(size * count / brightest, 3)}
+            for size, count in sorted(copies.items(), reverse=True)]
+
+
+def render_gel_svg(lanes: List[Dict], ladder: str = '1kb', lane_width: int = 60, height: int = 360) -> str:
+    """Agarose gel of lanes given as {'name', 'sizes'}, with a size ladder in the first lane
+
+    Bands are dimmer for smaller fragments (see lane_bands); sizes outside the ladder's range
+    pile up at the top or bottom of the gel.
+    """
+    if ladder not in LADDERS:
+        raise ValueError(f'Unknown ladder "{ladder}"; use one of {", ".join(LADDERS)}')
+    marker = LADDERS[ladder]
+    smallest, largest = min(marker), max(marker)
+    columns = [{'name': ladder, 'sizes': marker}] + lanes
+    top, bottom, label_width = 40, 20, 50
+    width = label_width + lane_width * len(columns) + 10
+    run = height - top - bottom
+
+    svg = [f'<svg xmlns="http://www.w3.org/2000/svg" width="{width}" height="{height}" '
+           f'font-family="Arial, sans-serif" font-size="10">',
+           f'<rect width="{width}" height="{height}" fill="#ffffff"/>',
+           f'<rect x="{label_width}" y="{top - 10}" width="{lane_width * len(columns)}" '
+           f'height="{run + 20}" fill="#1a1a2e"/>']
+    for size in marker:
+        y = top + run * _migration(size, smallest, largest)
+        svg.append(f'<text x="{label_width - 4}" y="{y + 3:.1f}" text-anchor="end" fill="#333">{size}</text>')
+    for column, lane in enumerate(columns):
+        x = label_width + column * lane_width
+        svg.append(f'<text x="{x + lane_width / 2}" y="{top - 16}" text-anchor="middle" fill="#333">'
+                   f'{escape(str(lane["name"]))}</text>')
+        svg.append(f'<rect x="{x + 8}" y="{top - 8}" width="{lane_width - 16}" height="4" fill="#444"/>')
+        # The ladder is loaded so its bands stain evenly
+        bands = [{'size': size, 'copies': 1, 'intensity': 1.0} for size in marker] if column == 0 \
+            else lane_bands(lane['sizes'])
+        for band in bands:
+            y = top + run * _migration(band['size'], smallest, largest)
+            opacity = 0.25 + 0.75 * band['intensity']
+            label = f'{band["size"]} bp' + (f' ×{band["copies"]}' if band['copies'] > 1 else '')
+            svg.append(f'<rect x="{x + 8}" y="{y - 1.5:.1f}" width="{lane_width - 16}" height="3" '
+                       f'fill="#f6f6a0" opacity="{opacity:.2f}"><title>{label}</title></rect>')
+    svg.append('</svg>')
+    return ''.join(svg)
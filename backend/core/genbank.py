@@ -0,0 +1,283 @@
+import re
+import textwrap
+from dataclasses import dataclass, field, replace
+from typing import Dict, List, Tuple
+from .strand import Strand
+
+LABEL_QUALIFIERS = ('label', 'gene', 'product', 'locus_tag', 'standard_name', 'note')
+
+
+@dataclass
+class Feature:
+    """Annotated feature; parts are 0-based, end-exclusive intervals on the forward strand
+
+    Parts are kept in forward-strand order. On a circular record a feature spanning the
+    origin, e.g. join(4900..5000,1..200), has a part that starts before the one preceding it;
+    its start is then the first part's start and its end the last part's end, so end < start.
+    """
+    type: str
+    location: str
+    parts: List[Tuple[int, int]]
+    strand: int = 1
+    qualifiers: Dict[str, str] = field(default_factory=dict)
+
+    @property
+    def wraps(self) -> bool:
+        """Whether the feature spans the origin of a circular record"""
+        return any(b[0] < a[0] for a, b in zip(self.parts, self.parts[1:]))
+
+    @property
+    def start(self) -> int:
+        return self.parts[0][0] if self.wraps else min(start for start, _ in self.parts)
+
+    @property
+    def end(self) -> int:
+        return self.parts[-1][1] if self.wraps else max(end for _, end in self.parts)
+
+    @property
+    def label(self) -> str:
+        for key in LABEL_QUALIFIERS:
+            if self.qualifiers.get(key):
+                return self.qualifiers[key]
+        return self.type
+
+    def to_dict(self) -> Dict:
+        return {
+            'type': self.type,
+            'label': self.label,
+            'location': self.location,
+            'start': self.start,
+            'end': self.end,
+            'strand': self.strand,
+            'parts': [list(part) for part in self.parts],
+            'wraps': self.wraps,
+            'qualifiers': self.qualifiers
+        }
+
+    def rotated(self, origin: int, length: int) -> 'Feature':
+        """Copy on the record rotated to start at origin
+
+        Parts crossing the new origin are split and parts that were split at the old origin
+        are joined again. The location is rewritten from the new parts, so partial-end
+        markers (<, >) are dropped.
+        """
+        old_origin = (length - origin) % length
+        parts = []
+        for start, end in self.parts:
+            if start == end:  # site between two bases
+                parts.append(((start - origin) % length,) * 2)
+                continue
+            start, end = (start - origin) % length, (end - origin) % length or length
+            for part in ([(start, length), (0, end)] if end < start else [(start, end)]):
+                if parts and parts[-1][1] == part[0] == old_origin and parts[-1][0] != parts[-1][1]:
+                    parts[-1] = (parts[-1][0], part[1])
+                else:
+                    parts.append(part)
+        return replace(self, location=format_location(parts, self.strand, length), parts=parts,
+                       qualifiers=dict(self.qualifiers))
+
+
+@dataclass
+class GenBankRecord:
+    name: str
+    sequence: str
+    topology: str = 'linear'
+    definition: str = ""
+    features: List[Feature] = field(default_factory=list)
+
+    @property
+    def circular(self) -> bool:
+        return self.topology == 'circular'
+
+    def strand(self) -> Strand:
+        """The record's sequence as a Strand, circular for circular records"""
+        return Strand(self.sequence, name=self.name, circular=self.circular)
+
+    def rotate(self, origin: int) -> 'GenBankRecord':
+        """Circular record renumbered to start at origin (0-based), with every feature moved along"""
+        if not self.circular:
+            raise ValueError('Only circular records can be rotated')
+        length = len(self.sequence)
+        if not length:
+            raise ValueError('GenBank record has no sequence')
+        origin %= length
+        return replace(self, sequence=self.sequence[origin:] + self.sequence[:origin],
+                       features=[feature.rotated(origin, length) for feature in self.features])
+
+
+def parse_location(location: str) -> Tuple[List[Tuple[int, int]], int]:
+    """Intervals and strand of a GenBank location such as complement(join(1..10,20..>30))
+
+    Intervals are returned in forward-strand order, so join(complement(1..200),complement(4900..5000))
+    gives [(4899, 5000), (0, 200)] like complement(join(4900..5000,1..200)).
+    """
+    text = location.replace(' ', '')
+    strand = 1
+    outer_complement = text.startswith('complement(') and text.endswith(')')
+    if outer_complement:
+        strand = -1
+        text = text[len('complement('):-1]
+
+    parts = []
+    for token in re.findall(r'(complement\()?<?(\d+)(?:\.\.>?(\d+)|\^(\d+))?', text):
+        inner_complement, first, last, between = token
+        if inner_complement:
+            strand = -1
+        if between:
+            start = end = int(first)  # site between two bases
+        else:
+            start, end = int(first) - 1, int(last or first)
+        parts.append((start, end))
+    if not parts:
+        raise ValueError(f'Cannot parse feature location "{location}"')
+    if strand == -1 and not outer_complement:
+        parts.reverse()  # join(complement(a),complement(b)) lists parts 5' to 3' on the reverse strand
+    return parts, strand
+
+
+def format_location(parts: List[Tuple[int, int]], strand: int = 1, length: int = None) -> str:
+    """GenBank location text for forward-strand-ordered parts, e.g. complement(join(4900..5000,1..200))
+
+    With the length of a circular sequence, a site at 0 is written between its last and
+    first base (5000^1).
+    """
+    spans = []
+    for start, end in parts:
+        if start == end:
+            spans.append(f'{length}^1' if start == 0 and length else f'{start}^{start + 1}')
+        elif end - start == 1:
+            spans.append(str(end))
+        else:
+            spans.append(f'{start + 1}..{end}')
+    location = spans[0] if len(spans) == 1 else f"join({','.join(spans)})"
+    return f'complement({location})' if strand == -1 else location
+
+
+def _split_at_origin(feature: Feature, length: int) -> Feature:
+    """Feature with ranges written across the origin (4900..200 on a circular record) split in two
+
+    A site between the last and first base (5000^1) is moved to position 0.
+    """
+    if not any(end < start or start == end == length for start, end in feature.parts):
+        return feature
+    parts = []
+    for start, end in feature.parts:
+        if start == end == length:
+            parts.append((0, 0))
+        else:
+            parts += [(start, length), (0, end)] if end < start else [(start, end)]
+    return replace(feature, parts=parts)
+
+
+def _parse_features(lines: List[str]) -> List[Feature]:
+    features = []
+    current = None
+    qualifier = None
+    for line in lines:
+        key, value = line[5:21].strip(), line[21:].strip()
+        if key:
+            current = {'type': key, 'location': value, 'qualifiers': {}}
+            features.append(current)
+            qualifier = None
+        elif current is None:
+            continue
+        elif value.startswith('/'):
+            name, _, text = value[1:].partition('=')
+            qualifier = name
+            current['qualifiers'][name] = text.strip('"') if text else ''
+        elif qualifier is None:
+            current['location'] += value  # wrapped location
+        else:
+            joiner = '' if qualifier == 'translation' else ' '
+            current['qualifiers'][qualifier] = (current['qualifiers'][qualifier] + joiner + value).strip('"')
+
+    parsed = []
+    for item in features:
+        parts, strand = parse_location(item['location'])
+        parsed.append(Feature(item['type'], item['location'], parts, strand, item['qualifiers']))
+    return parsed
+
+
+def parse_genbank(text: str) -> List[GenBankRecord]:
+    """Records from GenBank flat-file text (LOCUS, DEFINITION, FEATURES and ORIGIN sections)"""
+    records = []
+    for chunk in re.split(r'^//\s*$', text, flags=re.MULTILINE):
+        if 'LOCUS' not in chunk:
+            continue
+        lines = chunk.strip('\n').splitlines()
+        name, topology, definition = '', 'linear', ''
+        feature_lines, sequence_parts = [], []
+        section = None
+
+        for line in lines:
+            if line.startswith('LOCUS'):
+                fields = line.split()
+                name = fields[1] if len(fields) > 1 else ''
+                topology = 'circular' if 'circular' in fields else 'linear'
+                section = None
+            elif line.startswith('DEFINITION'):
+                definition = line[12:].strip()
+                section = 'definition'
+            elif line.startswith('FEATURES'):
+                section = 'features'
+            elif line.startswith('ORIGIN'):
+                section = 'origin'
+            elif line[:1].strip():
+                section = None
+            elif section == 'definition':
+                definition += ' ' + line.strip()
+            elif section == 'features':
+                feature_lines.append(line)
+            elif section == 'origin':
+                sequence_parts.append(re.sub(r'[\d\s]', '', line))
+
+        sequence = ''.join(sequence_parts).upper()
+        features = _parse_features(feature_lines)
+        if topology == 'circular':
+            features = [_split_at_origin(feature, len(sequence)) for feature in features]
+        records.append(GenBankRecord(
+            name=name,
+            sequence=sequence,
+            topology=topology,
+            definition=definition,
+            features=features
+        ))
+    if not records:
+        raise ValueError('No GenBank records found')
+    return records
+
+
+def _format_qualifier(name: str, value: str) -> List[str]:
+    indent = ' ' * 21
+    if value == '':
+        return [f'{indent}/{name}']
+    text = f'/{name}={value}' if value.isdigit() else f'/{name}="{value}"'
+    if name == 'translation':
+        return [indent + text[i:i + 58] for i in range(0, len(text), 58)]
+    return textwrap.wrap(text, 79, initial_indent=indent, subsequent_indent=indent, break_on_hyphens=False)
+
+
+def format_genbank(record: GenBankRecord) -> str:
+    """GenBank flat-file text for a record, readable by parse_genbank and other tools
+
+    Locations are written as parsed unless a feature was rotated, so origin-spanning
+    features keep their join(...,1..n) form.
+    """
+    lines = [f'LOCUS       {record.name or "sequence":<16} {len(record.sequence):>11} bp    DNA     {record.topology}']
+    definition = textwrap.wrap(record.definition or '.', 67) or ['.']
+    lines.append(f'DEFINITION  {definition[0]}')
+    lines += [f'            {line}' for line in definition[1:]]
+    lines.append('FEATURES             Location/Qualifiers')
+    for feature in record.features:
+        location = textwrap.wrap(feature.location, 58, break_on_hyphens=False) or ['']
+        lines.append(f'     {feature.type:<16}{location[0]}')
+        lines += [' ' * 21 + line for line in location[1:]]
+        for name, value in feature.qualifiers.items():
+            lines += _format_qualifier(name, value)
+    lines.append('ORIGIN')
+    sequence = record.sequence.lower()
+    for i in range(0, len(sequence), 60):
+        blocks = ' '.join(sequence[j:j + 10] for j in range(i, min(i + 60, len(sequence)), 10))
+        lines.append(f'{i + 1:>9} {blocks}')
+    lines.append('//')
+    return '\n'.join(lines) + '\n'
@@ -0,0 +1,202 @@
+import math
+from dataclasses import dataclass
+from typing import Dict, List, Tuple
+from .context import check_cancelled
+from .sequence import clean_sequence
+
+NEG_INF = float('-inf')
+GAP_CHARS = '-.'
+
+# Transitions out of match, insert and delete states at each model position
+TRANSITION_GROUPS = {'M': ('MM', 'MI', 'MD'), 'I': ('IM', 'II', 'ID'), 'D': ('DM', 'DI', 'DD')}
+
+
+def _log(p: float) -> float:
+    return math.log(p) if p > 0 else NEG_INF
+
+
+def _logsumexp(values: List[float]) -> float:
+    top = max(values)
+    if top == NEG_INF:
+        return NEG_INF
+    return top + math.log(sum(math.exp(v - top) for v in values))
+
+
+@dataclass
+class HMMAlignment:
+    """Viterbi path of a sequence through a profile HMM"""
+    score: float             # log-odds score in bits against the background model
+    path: str                # one state letter (M, I, D) per step
+    model_line: str          # consensus residues, '.' opposite insertions
+    sequence_line: str       # matched residues uppercase, insertions lowercase, deletions '-'
+
+
+class ProfileHMM:
+    """Plan 7 style profile HMM (match, insert and delete states) built from a multiple alignment
+
+    Columns with fewer than max_gap_fraction gaps become match states; residues in the other
+    columns are insertions. Counts get Laplace pseudocounts so unseen residues and transitions
+    keep a small probability.
+    """
+
+    def __init__(self, alignment: List[str], alphabet: str = 'ACGT', max_gap_fraction: float = 0.5,
+                 pseudocount: float = 1.0):
+        rows = [''.join(row.split()).upper() for row in alignment]
+        if not rows or len({len(row) for row in rows}) != 1:
+            raise ValueError('Alignment rows must be non-empty and the same length')
+
+        self.alphabet = alphabet
+        self.background = {symbol: 1 / len(alphabet) for symbol in alphabet}
+        self.match_columns = [
+            c for c in range(len(rows[0]))
+            if sum(row[c] in GAP_CHARS for row in rows) / len(rows) < max_gap_fraction
+        ]
+        if not self.match_columns:
+            raise ValueError('Alignment has no match columns')
+        self.length = len(self.match_columns)
+        self._estimate(rows, pseudocount)
+
+    def _estimate(self, rows: List[str], pseudocount: float):
+        """Count emissions and transitions along each row's state path and normalize"""
+        length = self.length
+        match_index = {column: j + 1 for j, column in enumerate(self.match_columns)}
+        match_counts = [{s: pseudocount for s in self.alphabet} for _ in range(length + 1)]
+        insert_counts = [{s: pseudocount for s in self.alphabet} for _ in range(length + 1)]
+        transitions = [{key: pseudocount for group in TRANSITION_GROUPS.values() for key in group}
+                       for _ in range(length + 1)]
+
+        for row in rows:
+            state, position = 'M', 0
+            for column, residue in enumerate(row):
+                gap = residue in GAP_CHARS
+                if column in match_index:
+                    j = match_index[column]
+                    next_state = 'D' if gap else 'M'
+                    transitions[position][state + next_state] += 1
+                    state, position = next_state, j
+                    if not gap and residue in self.alphabet:
+                        match_counts[j][residue] += 1
+                elif not gap:
+                    transitions[position][state + 'I'] += 1
+                    state = 'I'
+                    if residue in self.alphabet:
+                        insert_counts[position][residue] += 1
+            transitions[position][state + 'M'] += 1  # into the end state
+
+        # No delete state follows the last position
+        for key in ('MD', 'ID', 'DD'):
+            transitions[length][key] = 0
+
+        self.match_emissions = [self._normalize(counts) for counts in match_counts]
+        self.insert_emissions = [self._normalize(counts) for counts in insert_counts]
+        self.transitions = []
+        for counts in transitions:
+            probabilities = {}
+            for group in TRANSITION_GROUPS.values():
+                probabilities.update(self._normalize({key: counts[key] for key in group}))
+            self.transitions.append(probabilities)
+
+    @staticmethod
+    def _normalize(counts: Dict[str, float]) -> Dict[str, float]:
+        total = sum(counts.values())
+        return {key: value / total if total else 0.0 for key, value in counts.items()}
+
+    def consensus(self) -> str:
+        """Most probable residue at each match state"""
+        return ''.join(max(e, key=e.get) for e in self.match_emissions[1:])
+
+    def _emission(self, emissions: Dict[str, float], residue: str) -> float:
+        """Log-odds of a residue; ambiguous residues are neutral"""
+        if residue not in emissions:
+            return 0.0
+        return math.log(emissions[residue] / self.background[residue])
+
+    def _t(self, position: int, key: str) -> float:
+        return _log(self.transitions[position][key])
+
+    def _dp(self, sequence: str, combine) -> Tuple[List[List[float]], List[List[float]], List[List[float]], float]:
+        """Viterbi (combine=max) or forward (combine=logsumexp) matrices and the end score"""
+        n, length = len(sequence), self.length
+        M = [[NEG_INF] * (n + 1) for _ in range(length + 1)]
+        I = [[NEG_INF] * (n + 1) for _ in range(length + 1)]
+        D = [[NEG_INF] * (n + 1) for _ in range(length + 1)]
+        M[0][0] = 0.0
+
+        for j in range(length + 1):
+            check_cancelled()
+            for i in range(n + 1):
+                if j > 0 and i > 0:
+                    M[j][i] = self._emission(self.match_emissions[j], sequence[i - 1]) + combine([
+                        M[j - 1][i - 1] + self._t(j - 1, 'MM'),
+                        I[j - 1][i - 1] + self._t(j - 1, 'IM'),
+                        D[j - 1][i - 1] + self._t(j - 1, 'DM')
+                    ])
+                if i > 0:
+                    I[j][i] = self._emission(self.insert_emissions[j], sequence[i - 1]) + combine([
+                        M[j][i - 1] + self._t(j, 'MI'),
+                        I[j][i - 1] + self._t(j, 'II'),
+                        D[j][i - 1] + self._t(j, 'DI')
+                    ])
+                if j > 0:
+                    D[j][i] = combine([
+                        M[j - 1][i] + self._t(j - 1, 'MD'),
+                        I[j - 1][i] + self._t(j - 1, 'ID'),
+                        D[j - 1][i] + self._t(j - 1, 'DD')
+                    ])
+
+        end = combine([
+            M[length][n] + self._t(length, 'MM'),
+            I[length][n] + self._t(length, 'IM'),
+            D[length][n] + self._t(length, 'DM')
+        ])
+        return M, I, D, end
+
+    def forward(self, sequence: str) -> float:
+        """Log-odds score (bits) of the sequence summed over all paths"""
+        *_, end = self._dp(clean_sequence(sequence), _logsumexp)
+        return end / math.log(2)
+
+    def viterbi(self, sequence: str) -> HMMAlignment:
+        """Most probable state path, its log-odds score (bits) and the resulting alignment"""
+        sequence = clean_sequence(sequence)
+        M, I, D, end = self._dp(sequence, max)
+        matrices = {'M': M, 'I': I, 'D': D}
+        if end == NEG_INF:
+            raise ValueError('Sequence cannot be aligned to the profile')
+
+        # Trace back by re-deriving which predecessor produced each cell
+        j, i = self.length, len(sequence)
+        state = max('MID', key=lambda s: matrices[s][j][i] + self._t(j, s + 'M'))
+        path, model_line, sequence_line = [], [], []
+        consensus = self.consensus()
+        while (j, i) != (0, 0) or state != 'M':
+            path.append(state)
+            if state == 'M':
+                model_line.append(consensus[j - 1])
+                sequence_line.append(sequence[i - 1])
+                prev_j, prev_i, key_of = j - 1, i - 1, lambda s: s + 'M'
+            elif state == 'I':
+                model_line.append('.')
+                sequence_line.append(sequence[i - 1].lower())
+                prev_j, prev_i, key_of = j, i - 1, lambda s: s + 'I'
+            else:
+                model_line.append(consensus[j - 1])
+                sequence_line.append('-')
+                prev_j, prev_i, key_of = j - 1, i, lambda s: s + 'D'
+
+            candidates = 'M' if (prev_j, prev_i) == (0, 0) else ('MID' if prev_j > 0 else 'MI')
+            state = max(candidates, key=lambda s: matrices[s][prev_j][prev_i] + self._t(prev_j, key_of(s)))
+            j, i = prev_j, prev_i
+
+        return HMMAlignment(
+            score=end / math.log(2),
+            path=''.join(reversed(path)),
+            model_line=''.join(reversed(model_line)),
+            sequence_line=''.join(reversed(sequence_line))
+        )
+
+
+def classify(sequence: str, profiles: Dict[str, ProfileHMM]) -> List[Dict]:
+    """Score a sequence against several family profiles, best first"""
+    scores = [{'family': name, 'score': round(profile.forward(sequence), 3)} for name, profile in profiles.items()]
+    return sorted(scores, key=lambda entry: -entry['score'])
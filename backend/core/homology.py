@@ -0,0 +1,156 @@
+import math
+import re
+from collections import defaultdict
+from typing import Dict, List, Tuple
+from .align import ScoringScheme, local_align
+from .blast import BlastHit
+from .seqio import SequenceRecord
+from .sequence import clean_sequence, reverse_complement
+
+# Karlin-Altschul K is not derived from the scoring scheme; 0.1 is typical of nucleotide matrices
+KARLIN_K = 0.1
+
+# Stricter than the pairwise aligner's default so chance gapped alignments stay insignificant
+SEARCH_SCORING = ScoringScheme(match=2, mismatch=-3, gap=-5)
+
+
+def karlin_lambda(scoring: ScoringScheme, tolerance: float = 1e-9) -> float:
+    """Ungapped Karlin-Altschul lambda for uniform base composition
+
+    The positive root of 1/4 e^(lambda match) + 3/4 e^(lambda mismatch) = 1, which exists
+    when the expected score per column is negative.
+    """
+    if scoring.match <= 0 or scoring.match + 3 * scoring.mismatch >= 0:
+        raise ValueError('Scoring needs a positive match score and a negative expected score')
+
+    def balance(value: float) -> float:
+        return 0.25 * math.exp(value * scoring.match) + 0.75 * math.exp(value * scoring.mismatch) - 1
+
+    low, high = tolerance, 1.0
+    while balance(high) < 0:
+        high *= 2
+    while high - low > tolerance:
+        middle = (low + high) / 2
+        low, high = (middle, high) if balance(middle) < 0 else (low, middle)
+    return (low + high) / 2
+
+
+class HomologySearch:
+    """Seeded homology search over a set of sequences, an offline stand-in for blastn
+
+    Subjects are indexed by k-mer; the query's k-mers on both strands seed (subject, strand,
+    diagonal) candidates, nearby diagonals are merged so gapped matches stay together, and
+    each candidate region is extended by local alignment. Scores get Karlin-Altschul bit
+    scores and E-values from the scoring scheme's ungapped lambda, so they rank like BLAST's
+    but are approximate: gaps make real E-values somewhat larger.
+    """
+
+    def __init__(self, subjects: List[SequenceRecord], k: int = 11, max_occurrences: int = 200,
+                 scoring: ScoringScheme = None):
+        if k < 4:
+            raise ValueError('k must be at least 4')
+        self.k, self.max_occurrences = k, max_occurrences
+        self.scoring = scoring or SEARCH_SCORING
+        self.lam = karlin_lambda(self.scoring)
+        self.subjects = [SequenceRecord(record.name, clean_sequence(record.sequence)) for record in subjects]
+        self.database_length = sum(len(record.sequence) for record in self.subjects)
+        self.index: Dict[str, List[Tuple[int, int]]] = defaultdict(list)
+        for subject_index, record in enumerate(self.subjects):
+            for i in range(len(record.sequence) - k + 1):
+                self.index[record.sequence[i:i + k]].append((subject_index, i))
+
+    def bit_score(self, score: int) -> float:
+        return (self.lam * score - math.log(KARLIN_K)) / math.log(2)
+
+    def evalue(self, score: int, query_length: int) -> float:
+        return KARLIN_K * query_length * self.database_length * math.exp(-self.lam * score)
+
+    def _candidates(self, query: str, band: int) -> List[Tuple[int, int, int, int, int]]:
+        """(subject, query start, query end, lowest and highest diagonal) of seeds on nearby diagonals, most seeded first"""
+        seeds = defaultdict(list)
+        for offset in range(len(query) - self.k + 1):
+            positions = self.index.get(query[offset:offset + self.k], ())
+            if len(positions) <= self.max_occurrences:
+                for subject_index, position in positions:
+                    seeds[subject_index].append((position - offset, offset))
+
+        regions = []
+        for subject_index, hits in seeds.items():
+            hits.sort()
+            group = [hits[0]]
+            for diagonal, offset in hits[1:] + [(math.inf, 0)]:
+                if diagonal - group[-1][0] <= band:
+                    group.append((diagonal, offset))
+                    continue
+                offsets = [item[1] for item in group]
+                regions.append((len(group), subject_index, min(offsets), max(offsets) + self.k,
+                                group[0][0], group[-1][0]))
+                group = [(diagonal, offset)]
+        regions.sort(key=lambda region: -region[0])
+        return [region[1:] for region in regions]
+
+    def search(self, sequence: str, name: str = 'query', max_hits: int = 50, max_evalue: float = 10.0,
+               band: int = 16, pad: int = 50) -> List[BlastHit]:
+        """Local alignments of a query against the subjects on both strands, best first
+
+        Coordinates are reported like BLAST's on the forward strands; strand -1 hits align
+        the query's reverse complement.
+        """
+        query = clean_sequence(sequence)
+        if len(query) < self.k:
+            raise ValueError(f'The query must be at least {self.k} bases')
+
+        hits, covered = [], defaultdict(list)
+        for strand, oriented in ((1, query), (-1, reverse_complement(query))):
+            for subject_index, query_start, query_end, low, high in self._candidates(oriented, band):
+                # A region already inside a reported alignment would only find it again
+                if any(start <= low + query_start and high + query_end <= end
+                       for start, end in covered[subject_index, strand]):
+                    continue
+                subject = self.subjects[subject_index].sequence
+                q_from, q_to = max(query_start - pad, 0), min(query_end + pad, len(oriented))
+                s_from = max(low + q_from - band, 0)
+                s_to = min(high + q_to + band, len(subject))
+                alignment = local_align(subject[s_from:s_to], oriented[q_from:q_to], self.scoring)
+                if alignment.score <= 0 or self.evalue(alignment.score, len(query)) > max_evalue:
+                    continue
+                subject_start, subject_end = s_from + alignment.start_a, s_from + alignment.end_a
+                # Neighbouring seed groups often extend into the same alignment
+                if any(min(end, subject_end) - max(start, subject_start) > (subject_end - subject_start) / 2
+                       for start, end in covered[subject_index, strand]):
+                    continue
+                covered[subject_index, strand].append((subject_start, subject_end))
+                hits.append(self._hit(name, subject_index, strand, len(query), alignment,
+                                      q_from + alignment.start_b, q_from + alignment.end_b, subject_start, subject_end))
+
+        hits.sort(key=lambda hit: (hit.evalue, -hit.bit_score))
+        return hits[:max_hits]
+
+    def _hit(self, name: str, subject_index: int, strand: int, query_length: int, alignment,
+             query_start: int, query_end: int, subject_start: int, subject_end: int) -> BlastHit:
+        aligned_query, aligned_subject = alignment.aligned_b, alignment.aligned_a
+        if strand == -1:
+            # Shown like blastn: the query forward against the subject's reverse complement
+            query_start, query_end = query_length - query_end, query_length - query_start
+            aligned_query, aligned_subject = reverse_complement(aligned_query), reverse_complement(aligned_subject)
+        columns = len(aligned_query)
+        identical = sum(1 for a, b in zip(aligned_query, aligned_subject) if a == b and a != '-')
+        gaps = sum(1 for a, b in zip(aligned_query, aligned_subject) if '-' in (a, b))
+        return BlastHit(
+            query=name,
+            subject=self.subjects[subject_index].name,
+            identity=round(100 * identical / columns, 3) if columns else 0.0,
+            length=columns,
+            mismatches=columns - identical - gaps,
+            gap_opens=len(re.findall(r'-+', aligned_query)) + len(re.findall(r'-+', aligned_subject)),
+            query_start=query_start,
+            query_end=query_end,
+            subject_start=subject_start,
+            subject_end=subject_end,
+            strand=strand,
+            evalue=float(f'{self.evalue(alignment.score, query_length):.3g}'),
+            bit_score=round(self.bit_score(alignment.score), 1),
+            aligned_query=aligned_query,
+            aligned_subject=aligned_subject,
+            midline=''.join('|' if a == b and a != '-' else ' ' for a, b in zip(aligned_query, aligned_subject))
+        )
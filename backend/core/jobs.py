@@ -0,0 +1,313 @@
+import json
+import logging
+import os
+import threading
+import time
+import uuid
+from concurrent.futures import ThreadPoolExecutor
+from dataclasses import asdict, dataclass, field
+from datetime import datetime
+from typing import Any, Callable, Dict, List, Optional
+from .context import Cancelled, Context, using
+from .progress import Progress
+
+logger = logging.getLogger(__name__)
+
+# The job each worker thread is running, so long operations can report progress within an item
+_active = threading.local()
+
+# Least time between checkpoints of a running job; its last state is always written when it finishes
+CHECKPOINT_SECONDS = 5.0
+
+
+@dataclass
+class Job:
+    """Batch job applying one operation to many items"""
+    id: str
+    operation: str
+    total: int
+    params: Dict = field(default_factory=dict)
+    owner_id: str = ""
+    status: str = 'queued'  # queued, running, completed, failed, cancelled, interrupted
+    completed: int = 0
+    results: List[Any] = field(default_factory=list)
+    error: str = ""
+    detail: Dict = field(default_factory=dict)
+    created_at: str = ""
+    started_at: str = ""
+    finished_at: str = ""
+    resumed: int = 0  # times the job was picked up again after a restart or failure
+
+    @property
+    def progress(self) -> float:
+        """Percentage of items processed"""
+        return (self.completed / self.total) * 100 if self.total else 100.0
+
+    def to_dict(self, include_results: bool = True) -> Dict:
+        """JSON-serializable view of the job"""
+        data = {
+            'id': self.id,
+            'operation': self.operation,
+            'status': self.status,
+            'total': self.total,
+            'completed': self.completed,
+            'progress': round(self.progress, 1),
+            'error': self.error,
+            'detail': self.detail,
+            'created_at': self.created_at,
+            'started_at': self.started_at,
+            'finished_at': self.finished_at,
+            'resumed': self.resumed
+        }
+        if include_results:
+            data['results'] = self.results
+        return data
+
+    @classmethod
+    def from_checkpoint(cls, data: Dict) -> 'Job':
+        return cls(**{key: value for key, value in data.items() if key in cls.__dataclass_fields__})
+
+
+def current_job() -> Optional[Job]:
+    """The job this worker thread is running, if any"""
+    return getattr(_active, 'job', None)
+
+
+def report_progress(**detail):
+    """Publish progress within the current item (e.g. an optimizer's iteration) on the running job"""
+    job = getattr(_active, 'job', None)
+    if job is not None:
+        job.detail = detail
+
+
+def job_progress(**fields) -> Progress:
+    """Progress sink that publishes stage, percent and ETA, with fields such as the item's name, on the running job"""
+    return Progress(lambda update: report_progress(**fields, **update.to_dict()))
+
+
+class QueueFullError(Exception):
+    """Raised when the job queue has no room for another job"""
+
+
+class JobQueue:
+    """Bounded worker pool that runs batch jobs in the background
+
+    With a checkpoint directory every job's items are saved when it is queued and its state and
+    results as it runs, so after a restart restore() brings finished jobs back and continues
+    unfinished ones from the first item without a result. Items are saved as JSON:
+    encode_items(operation, items) turns a job's items into JSON-serializable values and
+    decode_items(operation, values) turns them back, both keeping items as they are by default.
+    """
+
+    def __init__(self, max_workers: int = 4, max_pending: int = 32, max_retained: int = 1000,
+                 checkpoint_dir: Optional[str] = None,
+                 encode_items: Optional[Callable[[str, List[Any]], List[Any]]] = None,
+                 decode_items: Optional[Callable[[str, List[Any]], List[Any]]] = None):
+        self.max_workers = max_workers
+        self.max_pending = max_pending
+        self.max_retained = max_retained
+        self.checkpoint_dir = checkpoint_dir
+        self.encode_items = encode_items or (lambda operation, items: items)
+        self.decode_items = decode_items or (lambda operation, values: values)
+        self.executor = ThreadPoolExecutor(max_workers=max_workers, thread_name_prefix='job-worker')
+        self.jobs: Dict[str, Job] = {}
+        self.items: Dict[str, List[Any]] = {}
+        self.contexts: Dict[str, Context] = {}
+        self.lock = threading.Lock()
+        if checkpoint_dir:
+            os.makedirs(checkpoint_dir, exist_ok=True)
+
+    def pending_count(self) -> int:
+        """Number of jobs queued or running"""
+        counts = self.stats()
+        return counts['queued'] + counts['running']
+
+    def submit(self, operation: str, items: List[Any], func: Callable[[Any, Dict], Any],
+               params: Optional[Dict] = None, owner_id: str = "") -> Job:
+        """Queue a job that applies func(item, params) to every item"""
+        if self.pending_count() >= self.max_pending:
+            raise QueueFullError(f"Job queue is full ({self.max_pending} pending jobs)")
+
+        job = Job(
+            id=str(uuid.uuid4()),
+            operation=operation,
+            total=len(items),
+            params=params or {},
+            owner_id=owner_id,
+            created_at=datetime.now().isoformat()
+        )
+        with self.lock:
+            self.jobs[job.id] = job
+            self.items[job.id] = list(items)
+            self._prune()
+
+        if self.checkpoint_dir:
+            with open(self._path(job.id, 'items'), 'w') as handle:
+                json.dump(self.encode_items(operation, self.items[job.id]), handle)
+            self._checkpoint(job)
+        self.executor.submit(self._run, job, func)
+        return job
+
+    def resume(self, job_id: str, func: Callable[[Any, Dict], Any]) -> Job:
+        """Continue a failed, cancelled or interrupted job from its first item without a result"""
+        job = self.get(job_id)
+        if job is None:
+            raise KeyError(job_id)
+        if job.status not in ('failed', 'cancelled', 'interrupted'):
+            raise ValueError(f'Only failed, cancelled or interrupted jobs can be resumed; this one is {job.status}')
+        if job.id not in self.items:
+            raise ValueError('The items of this job were not kept, so it cannot be resumed')
+        if self.pending_count() >= self.max_pending:
+            raise QueueFullError(f"Job queue is full ({self.max_pending} pending jobs)")
+        job.status, job.error, job.finished_at = 'queued', '', ''
+        job.resumed += 1
+        self._checkpoint(job)
+        self.executor.submit(self._run, job, func)
+        return job
+
+    def cancel(self, job_id: str) -> Job:
+        """Stop a queued or running job; a running item stops at its next cancellation check"""
+        job = self.get(job_id)
+        if job is None:
+            raise KeyError(job_id)
+        if job.status not in ('queued', 'running'):
+            raise ValueError(f'Only queued or running jobs can be cancelled; this one is {job.status}')
+        with self.lock:
+            context = self.contexts.setdefault(job.id, Context())
+        context.cancel('Cancelled by request')
+        return job
+
+    def restore(self, operations: Dict[str, Callable[[Any, Dict], Any]]) -> List[Job]:
+        """Load checkpointed jobs and resume those a restart interrupted, returning the resumed jobs
+
+        Jobs whose operation is not in operations stay interrupted.
+        """
+        if not self.checkpoint_dir:
+            return []
+        restored = []
+        for filename in sorted(os.listdir(self.checkpoint_dir)):
+            if not filename.endswith('.json'):
+                continue
+            job_id = filename[:-len('.json')]
+            try:
+                with open(self._path(job_id, 'json')) as handle:
+                    job = Job.from_checkpoint(json.load(handle))
+                items = None
+                if job.status != 'completed':
+                    with open(self._path(job_id, 'items')) as handle:
+                        items = self.decode_items(job.operation, json.load(handle))
+            except (OSError, ValueError, TypeError, KeyError):
+                logger.warning("Unreadable job checkpoint", extra={'job_id': job_id})
+                continue
+            if job.status in ('queued', 'running'):
+                job.status = 'interrupted'
+            with self.lock:
+                self.jobs[job.id] = job
+                if items is not None:
+                    self.items[job.id] = items
+            if job.status == 'interrupted' and job.operation in operations:
+                restored.append(job)
+        with self.lock:
+            self._prune()
+        for job in restored:
+            job.status = 'queued'
+            job.resumed += 1
+            self._checkpoint(job)
+            self.executor.submit(self._run, job, operations[job.operation])
+            logger.info("Job resumed", extra={'job_id': job.id, 'operation': job.operation,
+                                               'completed': job.completed})
+        return restored
+
+    def stats(self) -> Dict[str, int]:
+        """Counts of jobs by status"""
+        with self.lock:
+            counts = {'queued': 0, 'running': 0, 'completed': 0, 'failed': 0, 'cancelled': 0, 'interrupted': 0}
+            for job in self.jobs.values():
+                counts[job.status] += 1
+            return counts
+
+    def get(self, job_id: str, owner_id: Optional[str] = None) -> Optional[Job]:
+        """Look up a job by id, optionally only if it belongs to owner_id"""
+        with self.lock:
+            job = self.jobs.get(job_id)
+        if job and owner_id is not None and job.owner_id != owner_id:
+            return None
+        return job
+
+    def list(self, owner_id: Optional[str] = None) -> List[Job]:
+        """Retained jobs (optionally only owner_id's), newest first"""
+        with self.lock:
+            jobs = [job for job in self.jobs.values() if owner_id is None or job.owner_id == owner_id]
+        return sorted(jobs, key=lambda job: job.created_at, reverse=True)
+
+    def _path(self, job_id: str, extension: str) -> str:
+        return os.path.join(self.checkpoint_dir, f'{job_id}.{extension}')
+
+    def _checkpoint(self, job: Job):
+        """Write the job's state and results, replacing the previous checkpoint in one step"""
+        if not self.checkpoint_dir:
+            return
+        path = self._path(job.id, 'json')
+        try:
+            with open(path + '.tmp', 'w') as handle:
+                json.dump(asdict(job), handle)
+            os.replace(path + '.tmp', path)
+        except (OSError, TypeError, ValueError):
+            # A job is never failed by its checkpoint; it just cannot be resumed from this point
+            logger.exception("Job checkpoint failed", extra={'job_id': job.id})
+
+    def _run(self, job: Job, func: Callable[[Any, Dict], Any]):
+        """Worker body: process the items without a result in order, record progress and checkpoint"""
+        with self.lock:
+            # Jobs cancelled while queued keep their context and stop at once; others start a fresh one
+            context = self.contexts.get(job.id)
+            if context is None or not context.cancelled:
+                context = self.contexts[job.id] = Context()
+        job.status = 'running'
+        job.started_at = job.started_at or datetime.now().isoformat()
+        _active.job = job
+        checkpointed = time.monotonic()
+        try:
+            with using(context):
+                for item in self.items[job.id][job.completed:]:
+                    context.check()
+                    job.results.append(func(item, job.params))
+                    job.completed += 1
+                    if time.monotonic() - checkpointed >= CHECKPOINT_SECONDS:
+                        self._checkpoint(job)
+                        checkpointed = time.monotonic()
+            job.status = 'completed'
+            logger.info("Job completed", extra={'job_id': job.id, 'operation': job.operation, 'items': job.total})
+        except Cancelled:
+            logger.info("Job cancelled", extra={'job_id': job.id, 'operation': job.operation})
+            job.status = 'cancelled'
+        except Exception as e:
+            logger.exception("Job failed", extra={'job_id': job.id, 'operation': job.operation})
+            job.status = 'failed'
+            job.error = str(e)
+        _active.job = None
+        job.finished_at = datetime.now().isoformat()
+        self._checkpoint(job)
+        with self.lock:
+            self.contexts.pop(job.id, None)
+        if job.status == 'completed':
+            self._forget_items(job.id)
+
+    def _prune(self):
+        """Drop the oldest finished jobs beyond the retention limit (lock must be held)"""
+        finished = sorted(
+            (job for job in self.jobs.values() if job.status in ('completed', 'failed', 'cancelled', 'interrupted')),
+            key=lambda job: job.created_at
+        )
+        excess = len(self.jobs) - self.max_retained
+        for job in finished[:max(excess, 0)]:
+            del self.jobs[job.id]
+            self._forget_items(job.id)
+            if self.checkpoint_dir and os.path.exists(self._path(job.id, 'json')):
+                os.remove(self._path(job.id, 'json'))
+
+    def _forget_items(self, job_id: str):
+        """Drop a job's items once it can no longer be resumed"""
+        self.items.pop(job_id, None)
+        if self.checkpoint_dir and os.path.exists(self._path(job_id, 'items')):
+            os.remove(self._path(job_id, 'items'))
@@ -0,0 +1,51 @@
+import hashlib
+import json
+import secrets
+from dataclasses import dataclass, field
+from datetime import datetime
+from typing import Dict, Optional
+
+VERSION = '1.0.0'
+
+
+def new_seed() -> int:
+    """Fresh 32-bit seed for a run that was not given one"""
+    return secrets.randbelow(2 ** 32)
+
+
+def resolve_seed(seed) -> int:
+    """The given seed as an int, or a fresh one, so every stochastic run has a seed to report"""
+    return int(seed) if seed is not None else new_seed()
+
+
+def input_hash(value) -> str:
+    """sha256 of an input: text as given, anything else as sorted-key JSON"""
+    text = value if isinstance(value, str) else json.dumps(value, sort_keys=True, default=str)
+    return hashlib.sha256(text.encode()).hexdigest()
+
+
+@dataclass
+class Manifest:
+    """What it takes to reproduce a result: the version that made it, its parameters, seed and inputs
+
+    Inputs are recorded by hash rather than content, so a manifest can be kept with every result
+    and still show whether a rerun was given the same sequences.
+    """
+    operation: str
+    parameters: Dict
+    seed: Optional[int] = None  # None for deterministic operations
+    inputs: Dict[str, str] = field(default_factory=dict)  # input name -> sha256
+    version: str = VERSION
+    created_at: str = ''
+
+    @classmethod
+    def create(cls, operation: str, parameters: Dict, seed: Optional[int] = None, **inputs) -> 'Manifest':
+        # Inputs given by hash and the seed have their own fields, so parameters leave them out
+        return cls(operation, {name: value for name, value in parameters.items()
+                               if name != 'seed' and name not in inputs}, seed,
+                   {name: input_hash(value) for name, value in inputs.items()},
+                   created_at=datetime.now().isoformat())
+
+    def to_dict(self) -> Dict:
+        return {'version': self.version, 'operation': self.operation, 'parameters': self.parameters,
+                'seed': self.seed, 'inputs': self.inputs, 'created_at': self.created_at}
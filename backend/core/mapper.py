@@ -0,0 +1,138 @@
+import statistics
+from collections import Counter
+from dataclasses import dataclass
+from typing import Dict, List, Optional, Tuple
+from .align import ScoringScheme, local_align, striped_local_align
+from .cigar import alignment_cigar, edit_distance, md_tag, reference_length
+from .minimizers import MinimizerIndex
+from .seqio import SequenceRecord
+from .sequence import clean_sequence, reverse_complement
+
+MAX_MAPQ = 60
+# Reads from this length up are verified with the striped kernel; below it the plain DP is as fast
+# (python -m benchmarks.bench_align)
+STRIPED_MIN_LENGTH = 200
+
+
+@dataclass
+class Hit:
+    """Alignment of a read to a reference; position is the 0-based leftmost aligned reference base"""
+    reference: str
+    position: int
+    strand: str              # '+' or '-' (read reverse-complemented to align)
+    cigar: str
+    score: int
+    mapq: int
+    edit_distance: int
+    reference_length: int    # reference bases covered by the alignment
+    md: str = ''             # SAM MD tag: mismatched and deleted reference bases
+
+    @property
+    def end(self) -> int:
+        return self.position + self.reference_length
+
+
+class ReadMapper:
+    """Seed-and-extend read mapper
+
+    References are indexed by their minimizers (core/minimizers.py); a read votes for
+    (reference, diagonal) candidates through its own minimizers on both strands, and the
+    best candidates are verified by local alignment (the striped kernel for long reads)
+    against a padded reference window. Very repetitive minimizers are ignored as seeds. Pass
+    a MinimizerIndex loaded from disk to skip indexing the references again.
+    """
+
+    def __init__(self, references: List[SequenceRecord] = None, k: int = 15, max_occurrences: int = 50,
+                 scoring: ScoringScheme = None, w: int = 10, index: MinimizerIndex = None):
+        self.index = index or MinimizerIndex(references or [], k, w)
+        self.k, self.w = self.index.k, self.index.w
+        self.max_occurrences = max_occurrences
+        self.scoring = scoring or ScoringScheme()
+        self.references = self.index.references
+
+    def _seeds(self, read: str) -> List[Tuple[int, int]]:
+        """(reference index, diagonal) of the read's minimizer hits"""
+        return self.index.seeds(read, self.max_occurrences)
+
+    def map_read(self, sequence: str, candidates: int = 3, min_score: int = None) -> Optional[Hit]:
+        """Best hit of a read on either strand, or None when unmapped"""
+        read = clean_sequence(sequence)
+        if len(read) < self.k:
+            return None
+        min_score = min_score if min_score is not None else self.scoring.max_score() * max(self.k, len(read) // 2)
+
+        votes = Counter()
+        for strand, oriented in (('+', read), ('-', reverse_complement(read))):
+            for ref_index, diagonal in self._seeds(oriented):
+                votes[strand, ref_index, diagonal] += 1
+
+        scored = []
+        pad = max(len(read) // 10, 10)
+        for (strand, ref_index, diagonal), _ in votes.most_common(candidates):
+            oriented = read if strand == '+' else reverse_complement(read)
+            reference = self.references[ref_index].sequence
+            window_start = max(diagonal - pad, 0)
+            window = reference[window_start:diagonal + len(read) + pad]
+            alignment = (striped_local_align if len(read) >= STRIPED_MIN_LENGTH else local_align)(
+                window, oriented, self.scoring)
+            scored.append((alignment.score, strand, ref_index, window_start, alignment, oriented))
+
+        scored.sort(key=lambda item: -item[0])
+        if not scored or scored[0][0] < min_score:
+            return None
+
+        score, strand, ref_index, window_start, alignment, oriented = scored[0]
+        # Candidates on other diagonals that score as well make the placement ambiguous
+        alternatives = [s for s in scored[1:] if (s[2], s[3] + s[4].start_a) != (ref_index, window_start + alignment.start_a)]
+        second = alternatives[0][0] if alternatives else 0
+        mapq = MAX_MAPQ if second <= 0 else max(0, min(MAX_MAPQ, int(MAX_MAPQ * (score - second) / score)))
+
+        reference = self.references[ref_index].sequence
+        position, cigar = window_start + alignment.start_a, alignment_cigar(alignment, len(oriented))
+        return Hit(
+            reference=self.references[ref_index].name,
+            position=position,
+            strand=strand,
+            cigar=cigar,
+            score=score,
+            mapq=mapq,
+            edit_distance=edit_distance(cigar, oriented, reference, position),
+            reference_length=reference_length(cigar),
+            md=md_tag(cigar, oriented, reference, position)
+        )
+
+
+def fr_insert_size(hit1: Optional[Hit], hit2: Optional[Hit]) -> Optional[int]:
+    """Fragment length of a forward-reverse pair on the same reference, else None"""
+    if not hit1 or not hit2 or hit1.reference != hit2.reference or hit1.strand == hit2.strand:
+        return None
+    forward, reverse = (hit1, hit2) if hit1.strand == '+' else (hit2, hit1)
+    if forward.position > reverse.position:
+        return None
+    return reverse.end - forward.position
+
+
+def estimate_insert_size(pairs: List[Tuple[Optional[Hit], Optional[Hit]]], max_insert: int = 10000) -> Dict:
+    """Median, mean and standard deviation of FR insert sizes among confidently mapped pairs"""
+    sizes = [size for size in (fr_insert_size(h1, h2) for h1, h2 in pairs
+                               if h1 and h2 and h1.mapq > 0 and h2.mapq > 0)
+             if size is not None and size <= max_insert]
+    if not sizes:
+        return {'pairs': 0, 'median': None, 'mean': None, 'sd': None}
+    return {
+        'pairs': len(sizes),
+        'median': statistics.median(sizes),
+        'mean': round(statistics.mean(sizes), 2),
+        'sd': round(statistics.pstdev(sizes), 2)
+    }
+
+
+def is_proper_pair(hit1: Optional[Hit], hit2: Optional[Hit], insert_stats: Dict, max_deviations: float = 4.0,
+                   default_max_insert: int = 1000) -> bool:
+    """Mates map forward-reverse on one reference with an insert size consistent with the library"""
+    size = fr_insert_size(hit1, hit2)
+    if size is None:
+        return False
+    if insert_stats.get('mean') is None:
+        return size <= default_max_insert
+    return abs(size - insert_stats['mean']) <= max_deviations * max(insert_stats['sd'], 1.0)
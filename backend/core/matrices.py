@@ -0,0 +1,128 @@
+import json
+from typing import Dict, Iterable, List
+from .sequence import IUPAC_BASES
+
+# NCBI NUC.4.4, the EMBOSS/BLAST default nucleotide matrix, with ambiguity codes
+EDNAFULL_TEXT = """\
+#  EDNAFULL (NUC.4.4)
+   A  T  G  C  S  W  R  Y  K  M  B  V  H  D  N
+A  5 -4 -4 -4 -4  1  1 -4 -4  1 -4 -1 -1 -1 -2
+T -4  5 -4 -4 -4  1 -4  1  1 -4 -1 -4 -1 -1 -2
+G -4 -4  5 -4  1 -4  1 -4  1 -4 -1 -1 -4 -1 -2
+C -4 -4 -4  5  1 -4 -4  1 -4  1 -1 -1 -1 -4 -2
+S -4 -4  1  1 -1 -4 -2 -2 -2 -2 -1 -1 -3 -3 -1
+W  1  1 -4 -4 -4 -1 -2 -2 -2 -2 -3 -3 -1 -1 -1
+R  1 -4  1 -4 -2 -2 -1 -4 -2 -2 -3 -1 -3 -1 -1
+Y -4  1 -4  1 -2 -2 -4 -1 -2 -2 -1 -3 -1 -3 -1
+K -4  1  1 -4 -2 -2 -2 -2 -1 -4 -1 -3 -3 -1 -1
+M  1 -4 -4  1 -2 -2 -2 -2 -4 -1 -3 -1 -1 -3 -1
+B -4 -1 -1 -1 -1 -3 -3 -1 -1 -3 -1 -2 -2 -2 -1
+V -1 -4 -1 -1 -1 -3 -1 -3 -3 -1 -2 -1 -2 -2 -1
+H -1 -1 -4 -1 -3 -1 -3 -1 -3 -1 -2 -2 -1 -2 -1
+D -1 -1 -1 -4 -3 -1 -1 -3 -1 -3 -2 -2 -2 -1 -1
+N -2 -2 -2 -2 -1 -1 -1 -1 -1 -1 -1 -1 -1 -1 -1
+"""
+
+
+class SubstitutionMatrix:
+    """Score of aligning each pair of residues, e.g. EDNAFULL or a user-supplied matrix
+
+    Nucleotide matrices only need the four bases: scores for ambiguity codes a matrix leaves
+    out are derived as the mean score over the bases each code stands for (rounded), so an N
+    scores like an average base and R against A scores between a match and a mismatch. U
+    scores as T. Pairs neither given nor derivable score as the matrix's lowest score.
+    """
+
+    def __init__(self, scores: Dict[str, Dict[str, int]], name: str = 'custom'):
+        self.name = name
+        self.scores = {a.upper(): {b.upper(): int(value) for b, value in row.items()} for a, row in scores.items()}
+        if not self.scores:
+            raise ValueError('A substitution matrix needs at least one row')
+        for a in list(self.scores):
+            for b in self.scores[a]:
+                if self.scores.get(b, {}).get(a, self.scores[a][b]) != self.scores[a][b]:
+                    raise ValueError(f'Matrix is not symmetric: {a}/{b} scores differ')
+        self._derive_ambiguity()
+        self.minimum = min(value for row in self.scores.values() for value in row.values())
+        self.maximum = max(value for row in self.scores.values() for value in row.values())
+
+    def _derive_ambiguity(self):
+        for a in list(self.scores):  # fill in the upper half of lower-triangular matrices
+            for b, value in list(self.scores[a].items()):
+                self.scores.setdefault(b, {}).setdefault(a, value)
+        if not all(b in self.scores.get(a, {}) for a in 'ACGT' for b in 'ACGT'):
+            return  # not a nucleotide matrix (or an incomplete one); nothing to derive from
+        for a, bases_a in IUPAC_BASES.items():
+            row = self.scores.setdefault(a, {})
+            for b, bases_b in IUPAC_BASES.items():
+                if b not in row:
+                    pairs = [self.scores[x][y] for x in bases_a for y in bases_b]
+                    row[b] = round(sum(pairs) / len(pairs))
+
+    def score(self, a: str, b: str) -> int:
+        return self.scores.get(a, {}).get(b, self.minimum)
+
+    def alphabet(self) -> List[str]:
+        return sorted(self.scores)
+
+    def to_dict(self) -> Dict:
+        return {'name': self.name, 'scores': self.scores}
+
+    def format(self, order: Iterable[str] = None) -> str:
+        """NCBI/EMBOSS text layout, readable by parse_matrix"""
+        order = list(order or self.alphabet())
+        lines = [f'# {self.name}', '   ' + ' '.join(f'{b:>2}' for b in order)]
+        lines.extend(f'{a} ' + ' '.join(f'{self.score(a, b):>2}' for b in order) for a in order)
+        return '\n'.join(lines) + '\n'
+
+
+def parse_matrix(text: str, name: str = 'custom') -> SubstitutionMatrix:
+    """Matrix from NCBI/EMBOSS text (a header row of residues, then one row per residue; '#' comments)
+    or JSON ({"A": {"A": 5, "C": -4, ...}, ...})"""
+    stripped = text.strip()
+    if stripped.startswith('{'):
+        try:
+            data = json.loads(stripped)
+        except ValueError as e:
+            raise ValueError(f'Matrix is not valid JSON: {e}')
+        return SubstitutionMatrix(data.get('scores', data), str(data.get('name', name)) if 'scores' in data else name)
+
+    header, scores = None, {}
+    for number, line in enumerate(text.splitlines(), start=1):
+        line = line.split('#', 1)[0].strip()
+        if not line:
+            continue
+        fields = line.split()
+        if header is None:
+            header = fields
+            continue
+        residue, values = fields[0], fields[1:]
+        if len(values) > len(header):
+            raise ValueError(f'Matrix line {number}: {len(values)} scores for {len(header)} columns')
+        try:
+            # Lower-triangular matrices give only the scores up to the diagonal
+            scores[residue] = {column: int(value) for column, value in zip(header, values)}
+        except ValueError:
+            raise ValueError(f'Matrix line {number}: scores must be integers')
+    if header is None:
+        raise ValueError('Matrix has no header row')
+    return SubstitutionMatrix(scores, name)
+
+
+EDNAFULL = parse_matrix(EDNAFULL_TEXT, 'EDNAFULL')
+MATRICES = {'EDNAFULL': EDNAFULL}
+
+
+def load_matrix(value) -> SubstitutionMatrix:
+    """A built-in matrix by name, a matrix given as text, or one as a {residue: {residue: score}} dict"""
+    if isinstance(value, SubstitutionMatrix):
+        return value
+    if isinstance(value, dict):
+        return SubstitutionMatrix(value.get('scores', value), str(value.get('name', 'custom')))
+    if not isinstance(value, str):
+        raise ValueError('matrix must be a name, matrix text or a dict of scores')
+    if value.strip().upper() in MATRICES:
+        return MATRICES[value.strip().upper()]
+    if '\n' not in value.strip() and not value.strip().startswith('{'):
+        raise ValueError(f'Unknown matrix "{value}"; built in: {sorted(MATRICES)}')
+    return parse_matrix(value)
@@ -0,0 +1,92 @@
+import math
+from typing import Dict
+from .sequence import IUPAC_BASES
+
+PURINES = set('AG')
+PYRIMIDINES = set('CT')
+
+
+def _is_transition(x: str, y: str) -> bool:
+    return {x, y} <= PURINES or {x, y} <= PYRIMIDINES
+
+
+def substitution_counts(a: str, b: str) -> Dict[str, int]:
+    """Classify the columns of two aligned sequences
+
+    Columns with a gap (or an unknown character) in either sequence are counted as gaps.
+    Ambiguity codes are resolved when every base they could stand for gives the same
+    answer: R vs Y is always a transversion and R vs C always differs, but A vs R (maybe
+    identical) or M vs K (transition or transversion) is counted as ambiguous. Only
+    identical, transition and transversion columns are compared sites.
+    """
+    if len(a) != len(b):
+        raise ValueError('Sequences must be aligned (equal length)')
+    counts = {'sites': 0, 'identical': 0, 'transitions': 0, 'transversions': 0, 'ambiguous': 0, 'gaps': 0}
+    for x, y in zip(a.upper(), b.upper()):
+        bases_x, bases_y = IUPAC_BASES.get(x), IUPAC_BASES.get(y)
+        if bases_x is None or bases_y is None:
+            counts['gaps'] += 1
+            continue
+        if len(bases_x) == 1 and bases_x == bases_y:
+            kind = 'identical'
+        elif set(bases_x) & set(bases_y):
+            kind = 'ambiguous'
+        else:
+            kinds = {_is_transition(p, q) for p in bases_x for q in bases_y}
+            kind = 'ambiguous' if len(kinds) > 1 else 'transitions' if kinds.pop() else 'transversions'
+        counts[kind] += 1
+        if kind != 'ambiguous':
+            counts['sites'] += 1
+    return counts
+
+
+def _compared(a: str, b: str) -> Dict[str, int]:
+    counts = substitution_counts(a, b)
+    if not counts['sites']:
+        raise ValueError('Sequences share no comparable sites')
+    return counts
+
+
+def p_distance(a: str, b: str) -> float:
+    """Proportion of differing sites"""
+    counts = _compared(a, b)
+    return (counts['transitions'] + counts['transversions']) / counts['sites']
+
+
+def jukes_cantor(a: str, b: str) -> float:
+    """Jukes-Cantor corrected distance, -3/4 ln(1 - 4p/3)"""
+    p = p_distance(a, b)
+    if p >= 0.75:
+        return math.inf
+    return -0.75 * math.log(1 - 4 * p / 3)
+
+
+def kimura_2p(a: str, b: str) -> float:
+    """Kimura two-parameter distance from transition (P) and transversion (Q) proportions"""
+    counts = _compared(a, b)
+    p, q = counts['transitions'] / counts['sites'], counts['transversions'] / counts['sites']
+    if 1 - 2 * p - q <= 0 or 1 - 2 * q <= 0:
+        return math.inf
+    return -0.5 * math.log(1 - 2 * p - q) - 0.25 * math.log(1 - 2 * q)
+
+
+def transition_transversion_ratio(a: str, b: str) -> float:
+    """Observed transitions per transversion (infinite when there are no transversions)"""
+    counts = substitution_counts(a, b)
+    if not counts['transversions']:
+        return math.inf if counts['transitions'] else 0.0
+    return counts['transitions'] / counts['transversions']
+
+
+DISTANCE_MODELS = {
+    'p': p_distance,
+    'jc69': jukes_cantor,
+    'k2p': kimura_2p,
+}
+
+
+def evolutionary_distance(a: str, b: str, model: str = 'jc69') -> float:
+    """Distance between two aligned sequences under a substitution model"""
+    if model not in DISTANCE_MODELS:
+        raise ValueError(f'Unknown distance model "{model}"; use one of {", ".join(DISTANCE_MODELS)}')
+    return DISTANCE_MODELS[model](a, b)
@@ -0,0 +1,122 @@
+import gzip
+import json
+from collections import defaultdict, deque
+from typing import Dict, Iterator, List, Tuple
+from .seqio import SequenceRecord
+from .sequence import clean_sequence
+
+INDEX_FORMAT = 'robin-minimizer-index'
+INDEX_VERSION = 1
+GZIP_MAGIC = b'\x1f\x8b'
+_CODES = {'A': 0, 'C': 1, 'G': 2, 'T': 3}
+
+
+def hash64(key: int, mask: int) -> int:
+    """Invertible integer mix of a 2-bit encoded k-mer, so minimizers are not biased towards poly-A"""
+    key = (~key + (key << 21)) & mask
+    key ^= key >> 24
+    key = (key + (key << 3) + (key << 8)) & mask
+    key ^= key >> 14
+    key = (key + (key << 2) + (key << 4)) & mask
+    key ^= key >> 28
+    return (key + (key << 31)) & mask
+
+
+def minimizers(sequence: str, k: int = 15, w: int = 10) -> Iterator[Tuple[int, int]]:
+    """(position, hash) of the (w, k)-minimizers of a sequence: the smallest-hash k-mer of every w consecutive ones
+
+    Neighbouring windows mostly share their minimizer, so about 2 / (w + 1) of the k-mers are
+    kept, and any two sequences sharing w + k - 1 bases share a minimizer there. K-mers with
+    bases other than ACGT are skipped; ties go to the leftmost k-mer.
+    """
+    if not 1 <= k <= 32 or w < 1:
+        raise ValueError('k must be between 1 and 32 and w positive')
+    mask = (1 << 2 * k) - 1
+    key, valid = 0, 0
+    window = deque()  # (hash, position) with increasing hashes
+    last = -1
+    for i, base in enumerate(sequence):
+        code = _CODES.get(base)
+        if code is None:
+            valid = 0
+        else:
+            key = ((key << 2) | code) & mask
+            valid += 1
+        start = i - k + 1  # of the k-mer ending here
+        if start < 0:
+            continue
+        if valid >= k:
+            value = hash64(key, mask)
+            while window and window[-1][0] > value:
+                window.pop()
+            window.append((value, start))
+        while window and window[0][1] <= start - w:
+            window.popleft()
+        if start >= w - 1 and window and window[0][1] != last:
+            last = window[0][1]
+            yield last, window[0][0]
+
+
+class MinimizerIndex:
+    """Positions of the (w, k)-minimizers of reference sequences, minimap2-style
+
+    Indexing only minimizers keeps roughly 2 / (w + 1) of the reference k-mers, so the index
+    is several times smaller than a full k-mer index, and keys are integer hashes rather than
+    k-mer strings. A read is seeded by its own minimizers, which fall on the same k-mers as the
+    reference's wherever the two share w + k - 1 bases. save() and load() keep a built index on
+    disk (gzipped JSON, references included) so large references are indexed once.
+    """
+
+    def __init__(self, references: List[SequenceRecord], k: int = 15, w: int = 10):
+        if not references:
+            raise ValueError('At least one reference sequence is required')
+        self.k, self.w = k, w
+        self.references = [SequenceRecord(r.name, clean_sequence(r.sequence)) for r in references]
+        self.positions: Dict[int, List[Tuple[int, int]]] = defaultdict(list)
+        for ref_index, record in enumerate(self.references):
+            for position, value in minimizers(record.sequence, k, w):
+                self.positions[value].append((ref_index, position))
+
+    def seeds(self, read: str, max_occurrences: int = 50) -> List[Tuple[int, int]]:
+        """(reference index, diagonal) of every read minimizer found in the index, skipping repetitive ones"""
+        seeds = []
+        for offset, value in minimizers(read, self.k, self.w):
+            positions = self.positions.get(value, ())
+            if len(positions) <= max_occurrences:
+                seeds.extend((ref_index, position - offset) for ref_index, position in positions)
+        return seeds
+
+    def stats(self) -> Dict:
+        return {'k': self.k, 'w': self.w, 'references': len(self.references),
+                'bases': sum(len(record.sequence) for record in self.references),
+                'minimizers': len(self.positions), 'positions': sum(len(hits) for hits in self.positions.values())}
+
+    def save(self, path: str):
+        data = {'format': INDEX_FORMAT, 'version': INDEX_VERSION, 'k': self.k, 'w': self.w,
+                'references': [[record.name, record.sequence] for record in self.references],
+                'positions': {str(value): [item for hit in hits for item in hit]
+                              for value, hits in self.positions.items()}}
+        with gzip.open(path, 'wt', encoding='utf-8') as handle:
+            json.dump(data, handle, separators=(',', ':'))
+
+    @classmethod
+    def load(cls, path: str) -> 'MinimizerIndex':
+        with gzip.open(path, 'rt', encoding='utf-8') as handle:
+            data = json.load(handle)
+        if data.get('format') != INDEX_FORMAT:
+            raise ValueError(f'{path} is not a minimizer index')
+        if data.get('version') != INDEX_VERSION:
+            raise ValueError(f'{path} is index version {data.get("version")}; rebuild it with this version')
+        index = cls.__new__(cls)
+        index.k, index.w = data['k'], data['w']
+        index.references = [SequenceRecord(name, sequence) for name, sequence in data['references']]
+        index.positions = defaultdict(list)
+        for value, flat in data['positions'].items():
+            index.positions[int(value)] = list(zip(flat[::2], flat[1::2]))
+        return index
+
+    @staticmethod
+    def is_index(path: str) -> bool:
+        """Whether a file looks like a saved index (gzip) rather than FASTA"""
+        with open(path, 'rb') as handle:
+            return handle.read(2) == GZIP_MAGIC
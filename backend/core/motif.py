@@ -0,0 +1,270 @@
+import math
+import random
+import re
+from dataclasses import dataclass, field
+from typing import Dict, List, Optional, Tuple
+from .sequence import clean_sequence, reverse_complement
+
+BASES = 'ACGT'
+UNIFORM_BACKGROUND = {base: 0.25 for base in BASES}
+
+
+@dataclass
+class Motif:
+    """Position frequency matrix: counts (or probabilities) per base at each motif position"""
+    name: str
+    counts: Dict[str, List[float]]
+    id: str = ""
+    background: Dict[str, float] = field(default_factory=lambda: dict(UNIFORM_BACKGROUND))
+    pseudocount: float = 0.8
+
+    def __post_init__(self):
+        lengths = {len(self.counts.get(base, [])) for base in BASES}
+        if len(lengths) != 1 or not lengths.pop():
+            raise ValueError(f'Motif "{self.name}" needs equal-length, non-empty rows for A, C, G and T')
+        self._pssm = None
+
+    def __len__(self) -> int:
+        return len(self.counts['A'])
+
+    def probabilities(self) -> List[Dict[str, float]]:
+        """Per-position base probabilities with the pseudocount spread by background"""
+        columns = []
+        for i in range(len(self)):
+            total = sum(self.counts[base][i] for base in BASES) + self.pseudocount
+            columns.append({
+                base: (self.counts[base][i] + self.pseudocount * self.background[base]) / total
+                for base in BASES
+            })
+        return columns
+
+    def pssm(self) -> List[Dict[str, float]]:
+        """Log-odds (bits) position-specific scoring matrix"""
+        if self._pssm is None:
+            self._pssm = [
+                {base: math.log2(column[base] / self.background[base]) for base in BASES}
+                for column in self.probabilities()
+            ]
+        return self._pssm
+
+    def consensus(self) -> str:
+        """Most likely base at each position"""
+        return ''.join(max(column, key=column.get) for column in self.probabilities())
+
+    def max_score(self) -> float:
+        return sum(max(column.values()) for column in self.pssm())
+
+    def min_score(self) -> float:
+        return sum(min(column.values()) for column in self.pssm())
+
+    def score(self, window: str) -> float:
+        """Log-odds score of a motif-length window; ambiguous bases score the column minimum"""
+        return sum(column.get(base, min(column.values())) for column, base in zip(self.pssm(), window))
+
+    def relative_score(self, score: float) -> float:
+        """Score scaled to 0 (worst possible) .. 1 (best possible)"""
+        span = self.max_score() - self.min_score()
+        return (score - self.min_score()) / span if span else 1.0
+
+
+def scan(sequence: str, motif: Motif, threshold: float = 0.8, relative: bool = True,
+         both_strands: bool = True) -> List[Dict]:
+    """Motif hits scoring at or above threshold
+
+    The threshold is a relative score (0..1) by default, or a log-odds score in bits when
+    relative is False. Minus-strand hits report forward-strand coordinates and the
+    site read on the minus strand.
+    """
+    sequence = clean_sequence(sequence)
+    width = len(motif)
+    strands = [('+', sequence)]
+    if both_strands:
+        strands.append(('-', reverse_complement(sequence)))
+
+    hits = []
+    for orientation, target in strands:
+        for i in range(len(target) - width + 1):
+            site = target[i:i + width]
+            score = motif.score(site)
+            rel = motif.relative_score(score)
+            if (rel if relative else score) < threshold:
+                continue
+            start = i if orientation == '+' else len(sequence) - i - width
+            hits.append({
+                'motif': motif.name,
+                'motif_id': motif.id,
+                'start': start,
+                'end': start + width,
+                'strand': orientation,
+                'site': site,
+                'score': round(score, 3),
+                'relative_score': round(rel, 4)
+            })
+    return sorted(hits, key=lambda hit: (hit['start'], hit['strand']))
+
+
+def best_score(sequence: str, motif: Motif, both_strands: bool = True) -> float:
+    """Highest log-odds score of any site in the sequence (-inf when it is shorter than the motif)"""
+    sequence = clean_sequence(sequence)
+    targets = [sequence, reverse_complement(sequence)] if both_strands else [sequence]
+    width = len(motif)
+    return max((motif.score(target[i:i + width]) for target in targets
+                for i in range(len(target) - width + 1)), default=float('-inf'))
+
+
+def parse_jaspar(text: str) -> List[Motif]:
+    """Motifs from JASPAR format (">ID name" headers followed by "A [ counts ]" rows)
+
+    Plain count matrices with four unlabeled rows in A, C, G, T order are also accepted.
+    """
+    motifs = []
+    header: Optional[str] = None
+    rows: List[List[float]] = []
+    labels: List[str] = []
+
+    def flush():
+        if header is None and not rows:
+            return
+        if len(rows) != 4:
+            raise ValueError(f'JASPAR motif "{header or ""}" must have 4 rows, found {len(rows)}')
+        parts = (header or '').split(None, 1)
+        motif_id = parts[0] if parts else ''
+        name = parts[1] if len(parts) > 1 else motif_id or f'motif{len(motifs) + 1}'
+        order = labels if len(labels) == 4 else list(BASES)
+        motifs.append(Motif(name=name, id=motif_id, counts=dict(zip(order, rows))))
+
+    for line in text.splitlines():
+        line = line.strip()
+        if not line:
+            continue
+        if line.startswith('>'):
+            flush()
+            header, rows, labels = line[1:].strip(), [], []
+            continue
+        match = re.match(r'^([ACGT])\s*\[(.*)\]$', line, re.IGNORECASE)
+        if match:
+            labels.append(match.group(1).upper())
+            rows.append([float(value) for value in match.group(2).split()])
+        else:
+            rows.append([float(value) for value in line.split()])
+    flush()
+    return motifs
+
+
+def parse_meme(text: str) -> List[Motif]:
+    """Motifs from MEME minimal format ("MOTIF id name" + letter-probability matrix)"""
+    motifs = []
+    lines = text.splitlines()
+    i = 0
+    while i < len(lines):
+        line = lines[i].strip()
+        if not line.startswith('MOTIF'):
+            i += 1
+            continue
+
+        parts = line.split()
+        motif_id = parts[1] if len(parts) > 1 else f'motif{len(motifs) + 1}'
+        name = parts[2] if len(parts) > 2 else motif_id
+        i += 1
+        while i < len(lines) and not lines[i].strip().startswith('letter-probability'):
+            i += 1
+        if i == len(lines):
+            raise ValueError(f'MEME motif "{motif_id}" has no letter-probability matrix')
+
+        width = re.search(r'w=\s*(\d+)', lines[i])
+        nsites = re.search(r'nsites=\s*(\d+)', lines[i])
+        scale = float(nsites.group(1)) if nsites else 20.0
+        i += 1
+
+        rows = []
+        while i < len(lines) and (width is None or len(rows) < int(width.group(1))):
+            values = lines[i].split()
+            i += 1
+            if not values:
+                if width is None and rows:
+                    break
+                continue
+            if values[0] in ('MOTIF', 'URL'):
+                i -= 1
+                break
+            rows.append([float(value) for value in values[:4]])
+
+        # MEME stores probabilities; scale by site count so pseudocounts behave like JASPAR
+        counts = {base: [row[j] * scale for row in rows] for j, base in enumerate(BASES)}
+        motifs.append(Motif(name=name, id=motif_id, counts=counts))
+    return motifs
+
+
+def parse_motifs(text: str, fmt: str = None) -> List[Motif]:
+    """Parse JASPAR or MEME text, detecting the format when not given"""
+    fmt = (fmt or ('meme' if re.search(r'^MOTIF\s', text, re.MULTILINE) else 'jaspar')).lower()
+    if fmt == 'meme':
+        return parse_meme(text)
+    if fmt == 'jaspar':
+        return parse_jaspar(text)
+    raise ValueError(f'Unknown motif format "{fmt}"')
+
+
+def _profile(sites: List[str], k: int, pseudocount: float = 1.0) -> List[Dict[str, float]]:
+    """Per-position base probabilities of aligned sites, with pseudocounts"""
+    total = len(sites) + 4 * pseudocount
+    return [
+        {base: (sum(site[i] == base for site in sites) + pseudocount) / total for base in BASES}
+        for i in range(k)
+    ]
+
+
+def _information(sites: List[str], k: int) -> float:
+    """Total information content (bits) of aligned sites against a uniform background"""
+    return sum(
+        sum(p * math.log2(p / 0.25) for p in column.values())
+        for column in _profile(sites, k, pseudocount=0.1)
+    )
+
+
+def gibbs_sample(sequences: List[str], k: int, iterations: int = 500, restarts: int = 10,
+                 seed: Optional[int] = None) -> Dict:
+    """De novo discovery of one length-k motif occurring once per sequence (Gibbs sampling)
+
+    Each restart places a random site in every sequence, then repeatedly removes one sequence,
+    builds a profile from the others and resamples that sequence's site in proportion to its
+    profile likelihood. The alignment with the highest information content over all restarts
+    is returned as a Motif with its site locations.
+    """
+    sequences = [clean_sequence(sequence) for sequence in sequences]
+    if len(sequences) < 2:
+        raise ValueError('At least two sequences are needed for motif discovery')
+    if k < 2 or any(len(sequence) < k for sequence in sequences):
+        raise ValueError(f'Motif length {k} must be at least 2 and no longer than the shortest sequence')
+
+    rng = random.Random(seed)
+    best: Tuple[float, List[int]] = (-1.0, [])
+
+    for _ in range(restarts):
+        positions = [rng.randrange(len(sequence) - k + 1) for sequence in sequences]
+        for _ in range(iterations):
+            left_out = rng.randrange(len(sequences))
+            others = [sequences[j][p:p + k] for j, p in enumerate(positions) if j != left_out]
+            profile = _profile(others, k)
+
+            target = sequences[left_out]
+            weights = [
+                math.prod(column.get(base, 0.25) for column, base in zip(profile, target[i:i + k]))
+                for i in range(len(target) - k + 1)
+            ]
+            positions[left_out] = rng.choices(range(len(weights)), weights=weights)[0]
+
+            score = _information([sequences[j][p:p + k] for j, p in enumerate(positions)], k)
+            if score > best[0]:
+                best = (score, list(positions))
+
+    score, positions = best
+    sites = [sequences[j][p:p + k] for j, p in enumerate(positions)]
+    counts = {base: [float(sum(site[i] == base for site in sites)) for i in range(k)] for base in BASES}
+    motif = Motif(name=f'gibbs_k{k}', counts=counts)
+    return {
+        'motif': motif,
+        'information': round(score, 3),
+        'sites': [{'sequence_index': j, 'start': p, 'end': p + k, 'site': site}
+                  for j, (p, site) in enumerate(zip(positions, sites))]
+    }
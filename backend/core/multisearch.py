@@ -0,0 +1,79 @@
+from collections import deque
+from typing import Dict, Iterator, List, Tuple
+from .sequence import clean_sequence, reverse_complement
+
+
+class AhoCorasick:
+    """Aho-Corasick automaton locating many exact patterns in one pass over a text
+
+    Build once from {name: pattern} and search any number of texts; matching costs
+    O(len(text) + matches) however many patterns there are. With both_strands, each
+    pattern's reverse complement is added too, so primers and adapters are found on either
+    strand. Patterns are cleaned (uppercased) like sequences; texts should be as well.
+    """
+
+    def __init__(self, patterns: Dict[str, str], both_strands: bool = False):
+        self._goto: List[Dict[str, int]] = [{}]
+        self._fail: List[int] = [0]
+        self._output: List[List[Tuple[str, str, int]]] = [[]]  # (name, strand, length) per state
+        for name, pattern in patterns.items():
+            pattern = clean_sequence(pattern)
+            if not pattern:
+                raise ValueError(f'Pattern "{name}" is empty')
+            self._add(pattern, (name, '+', len(pattern)))
+            if both_strands and reverse_complement(pattern) != pattern:
+                self._add(reverse_complement(pattern), (name, '-', len(pattern)))
+        self._link()
+
+    def _add(self, pattern: str, output: Tuple[str, str, int]):
+        state = 0
+        for char in pattern:
+            if char not in self._goto[state]:
+                self._goto.append({})
+                self._fail.append(0)
+                self._output.append([])
+                self._goto[state][char] = len(self._goto) - 1
+            state = self._goto[state][char]
+        self._output[state].append(output)
+
+    def _link(self):
+        """Breadth-first failure links; each state also reports the outputs of its failure chain"""
+        queue = deque(self._goto[0].values())
+        while queue:
+            state = queue.popleft()
+            for char, child in self._goto[state].items():
+                queue.append(child)
+                fallback = self._fail[state]
+                while fallback and char not in self._goto[fallback]:
+                    fallback = self._fail[fallback]
+                self._fail[child] = self._goto[fallback].get(char, 0)
+                self._output[child] = self._output[child] + self._output[self._fail[child]]
+
+    def iter_matches(self, text: str) -> Iterator[Tuple[int, int, str, str]]:
+        """(start, end, name, strand) of every match, in order of end position"""
+        goto, fail, output = self._goto, self._fail, self._output
+        state = 0
+        for i, char in enumerate(text):
+            while state and char not in goto[state]:
+                state = fail[state]
+            state = goto[state].get(char, 0)
+            for name, strand, length in output[state]:
+                yield i + 1 - length, i + 1, name, strand
+
+    def search(self, text: str) -> List[Dict]:
+        """Matches as dicts sorted by start, 0-based and end-exclusive"""
+        return sorted(({'start': start, 'end': end, 'name': name, 'strand': strand}
+                       for start, end, name, strand in self.iter_matches(text)),
+                      key=lambda match: (match['start'], match['end'], match['name']))
+
+    def counts(self, text: str) -> Dict[str, int]:
+        """Number of matches per pattern name"""
+        counts = {}
+        for _, _, name, _ in self.iter_matches(text):
+            counts[name] = counts.get(name, 0) + 1
+        return counts
+
+
+def find_patterns(sequence: str, patterns: Dict[str, str], both_strands: bool = True) -> List[Dict]:
+    """Every exact occurrence of named patterns in a sequence, found in one pass"""
+    return AhoCorasick(patterns, both_strands).search(clean_sequence(sequence))
@@ -0,0 +1,122 @@
+import re
+from itertools import product
+from typing import Dict, List
+from .restriction import ENZYMES, site_pattern
+from .sequence import CODON_TABLE, clean_sequence, reverse_complement
+
+SYNONYMOUS_CODONS = {}
+for _codon, _amino_acid in CODON_TABLE.items():
+    SYNONYMOUS_CODONS.setdefault(_amino_acid, []).append(_codon)
+
+SILENT_MODES = ('introduce', 'remove')
+
+
+def synonymous_codons(codon: str) -> List[str]:
+    """Codons for the same amino acid (or stop), the given codon first"""
+    codon = codon.upper()
+    if codon not in CODON_TABLE:
+        return [codon]
+    return [codon] + [other for other in SYNONYMOUS_CODONS[CODON_TABLE[codon]] if other != codon]
+
+
+def _site_patterns(site: str) -> Dict[str, re.Pattern]:
+    patterns = {'+': re.compile(site_pattern(site))}
+    if reverse_complement(site) != site.upper():
+        patterns['-'] = re.compile(site_pattern(reverse_complement(site)))
+    return patterns
+
+
+def _site_starts(sequence: str, patterns: Dict[str, re.Pattern]) -> Dict[int, str]:
+    """Start of every site occurrence, with the strand it reads on"""
+    return {m.start(): strand for strand, pattern in patterns.items()
+            for m in re.finditer(f'(?={pattern.pattern})', sequence)}
+
+
+def _codon_changes(cds: str, first: int, codons) -> List[Dict]:
+    changes = []
+    for i, codon in enumerate(codons):
+        original = cds[3 * (first + i):3 * (first + i) + 3]
+        if codon != original:
+            changes.append({
+                'codon': first + i,
+                'position': 3 * (first + i),
+                'from': original,
+                'to': codon,
+                'amino_acid': CODON_TABLE[codon],
+                'mismatches': sum(1 for a, b in zip(original, codon) if a != b)
+            })
+    return changes
+
+
+def _variants(cds: str, first: int, last: int):
+    """Every synonymous recoding of codons first..last, with the number of nucleotide changes"""
+    codons = [cds[3 * i:3 * i + 3] for i in range(first, last + 1)]
+    original = ''.join(codons)
+    for combination in product(*(synonymous_codons(codon) for codon in codons)):
+        region = ''.join(combination)
+        yield combination, sum(1 for a, b in zip(original, region) if a != b)
+
+
+def silent_site_changes(cds: str, enzyme: str, mode: str = 'introduce', max_changes: int = 3,
+                        limit: int = 20) -> List[Dict]:
+    """Synonymous codon changes that introduce or remove an enzyme's site in a coding sequence
+
+    The CDS is read in frame from its first base. 'introduce' tries every window the site
+    could occupy; 'remove' recodes the codons under each existing site so that no site is
+    left there (nor created nearby). Suggestions with the fewest nucleotide changes come first.
+    """
+    if mode not in SILENT_MODES:
+        raise ValueError(f'Unknown mode "{mode}"; use introduce or remove')
+    if enzyme not in ENZYMES:
+        raise ValueError(f'Unknown enzyme "{enzyme}"')
+    cds = clean_sequence(cds)
+    if len(cds) < 3:
+        raise ValueError('A coding sequence of at least one codon is required')
+    cds = cds[:len(cds) - len(cds) % 3]
+    site = ENZYMES[enzyme].site
+    patterns = _site_patterns(site)
+    existing = _site_starts(cds, patterns)
+
+    suggestions, seen = [], set()
+    if mode == 'introduce':
+        for start in range(len(cds) - len(site) + 1):
+            if start in existing:
+                continue
+            first, last = start // 3, (start + len(site) - 1) // 3
+            offset = start - 3 * first
+            for combination, changes in _variants(cds, first, last):
+                if not 0 < changes <= max_changes:
+                    continue
+                window = ''.join(combination)[offset:offset + len(site)]
+                strand = next((s for s, pattern in patterns.items() if pattern.fullmatch(window)), None)
+                key = (first, combination)
+                if strand is None or key in seen:
+                    continue
+                seen.add(key)
+                suggestions.append({'site_position': start, 'strand': strand, 'nucleotide_changes': changes,
+                                    'changes': _codon_changes(cds, first, combination)})
+    else:
+        for start, strand in sorted(existing.items()):
+            first, last = start // 3, (start + len(site) - 1) // 3
+            # Sites that could overlap the recoded codons must all be gone afterwards
+            context_start = max(3 * first - len(site) + 1, 0)
+            context_end = 3 * (last + 1) + len(site) - 1
+            for combination, changes in _variants(cds, first, last):
+                if not 0 < changes <= max_changes:
+                    continue
+                recoded = cds[:3 * first] + ''.join(combination) + cds[3 * (last + 1):]
+                if _site_starts(recoded[context_start:context_end], patterns):
+                    continue
+                suggestions.append({'site_position': start, 'strand': strand, 'nucleotide_changes': changes,
+                                    'changes': _codon_changes(cds, first, combination)})
+
+    suggestions.sort(key=lambda s: (s['nucleotide_changes'], s['site_position']))
+    return suggestions[:limit]
+
+
+def apply_changes(cds: str, changes: List[Dict]) -> str:
+    """CDS with suggested codon changes applied"""
+    bases = list(clean_sequence(cds))
+    for change in changes:
+        bases[change['position']:change['position'] + 3] = change['to']
+    return ''.join(bases)
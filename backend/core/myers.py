@@ -0,0 +1,96 @@
+from typing import Dict, Iterator, List, Tuple
+from .sequence import IUPAC_BASES, clean_sequence, reverse_complement
+
+
+class ApproximateMatcher:
+    """Myers' bit-vector search for a short pattern with up to max_errors edits
+
+    The pattern's column of the edit-distance matrix is kept as two bit vectors (the +1 and
+    -1 vertical deltas), so each text base costs a handful of word operations however long
+    the pattern, instead of a row of dynamic programming. Substitutions, insertions and
+    deletions all count as one error. Ambiguity codes match when the bases they stand for
+    overlap, as in PCR primer matching, so an N in the pattern matches anything. Patterns of
+    up to 64 bases fit one machine word; longer ones work too, only more slowly.
+    """
+
+    def __init__(self, pattern: str, max_errors: int = 1):
+        self.pattern = clean_sequence(pattern).replace('U', 'T')
+        if not self.pattern:
+            raise ValueError('Pattern is required')
+        if not 0 <= max_errors < len(self.pattern):
+            raise ValueError('max_errors must be at least 0 and shorter than the pattern')
+        self.max_errors = max_errors
+        self._forward = self._peq(self.pattern)
+        self._backward = self._peq(self.pattern[::-1])
+
+    @staticmethod
+    def _peq(pattern: str) -> Dict[str, int]:
+        """Bit i set for each text base that pattern[i] matches"""
+        peq = {}
+        for code, bases in IUPAC_BASES.items():
+            peq[code] = sum(1 << i for i, base in enumerate(pattern)
+                            if set(IUPAC_BASES.get(base, '')) & set(bases))
+        return peq
+
+    def _scores(self, peq: Dict[str, int], text, anchored: bool) -> Iterator[int]:
+        """Edit distance of the whole pattern ending at each text position
+
+        Unanchored, the match may start anywhere (the search); anchored, it must start at the
+        first text base (used to find where a hit begins).
+        """
+        length = len(self.pattern)
+        mask, high = (1 << length) - 1, 1 << (length - 1)
+        positive, negative, score = mask, 0, length
+        for base in text:
+            eq = peq.get(base, 0)
+            xv = eq | negative
+            xh = ((((eq & positive) + positive) & mask) ^ positive) | eq
+            ph = (negative | ~(xh | positive)) & mask
+            mh = positive & xh
+            if ph & high:
+                score += 1
+            elif mh & high:
+                score -= 1
+            ph = ((ph << 1) | int(anchored)) & mask
+            mh = (mh << 1) & mask
+            positive = (mh | ~(xv | ph)) & mask
+            negative = ph & xv
+            yield score
+
+    def _start(self, text: str, end: int, errors: int) -> int:
+        """Start of the best alignment ending at end, preferring the one closest to the pattern's length"""
+        length = len(self.pattern)
+        window = text[max(0, end - length - self.max_errors):end][::-1]
+        best = None
+        for span, score in enumerate(self._scores(self._backward, window, True), start=1):
+            if score <= errors and (best is None or abs(span - length) < abs(best - length)):
+                best = span
+        return end - (best or length)
+
+    def iter_matches(self, text: str) -> Iterator[Tuple[int, int, int]]:
+        """(start, end, errors) of each match, end-exclusive; overlapping hits give only their best"""
+        run = None  # best (errors, end) of the current stretch of hits
+        for position, score in enumerate(self._scores(self._forward, text, False), start=1):
+            if score <= self.max_errors:
+                if run is None or score < run[0]:
+                    run = (score, position)
+            elif run is not None:
+                yield self._start(text, run[1], run[0]), run[1], run[0]
+                run = None
+        if run is not None:
+            yield self._start(text, run[1], run[0]), run[1], run[0]
+
+    def search(self, text: str) -> List[Dict]:
+        """Matches as dicts, 0-based and end-exclusive"""
+        return [{'start': start, 'end': end, 'errors': errors} for start, end, errors in self.iter_matches(text)]
+
+
+def find_approximate(sequence: str, pattern: str, max_errors: int = 1, both_strands: bool = True) -> List[Dict]:
+    """Matches of a pattern with up to max_errors edits, on the forward strand and the reverse complement"""
+    sequence = clean_sequence(sequence).replace('U', 'T')
+    pattern = clean_sequence(pattern)
+    matches = [{**match, 'strand': '+'} for match in ApproximateMatcher(pattern, max_errors).search(sequence)]
+    if both_strands and reverse_complement(pattern) != pattern:
+        matches.extend({**match, 'strand': '-'}
+                       for match in ApproximateMatcher(reverse_complement(pattern), max_errors).search(sequence))
+    return sorted(matches, key=lambda match: (match['start'], match['end'], match['strand']))
@@ -0,0 +1,139 @@
+from typing import Dict
+from .sequence import IUPAC_BASES, clean_sequence, reverse_complement
+
+# Anhydrous nucleotide monophosphate masses (Da) in a chain
+DNA_MASSES = {'A': 313.21, 'C': 289.18, 'G': 329.21, 'T': 304.2}
+RNA_MASSES = {'A': 329.21, 'C': 305.18, 'G': 345.21, 'U': 306.17}
+# Synthetic oligos have a 5' hydroxyl: one phosphate less, plus a proton
+END_CORRECTION = -61.96
+
+# Molar extinction coefficients at 260 nm (M^-1 cm^-1) of single bases, for the base-sum estimate
+BASE_EXTINCTION = {'A': 15400, 'C': 7400, 'G': 11500, 'T': 8700, 'U': 9900}
+
+# Nearest-neighbor ε260 (Cantor et al. 1970; Puglisi & Tinoco 1989): dinucleotides and single bases
+DNA_NN_EXTINCTION = {
+    'AA': 27400, 'AC': 21200, 'AG': 25000, 'AT': 22800,
+    'CA': 21200, 'CC': 14600, 'CG': 18000, 'CT': 15200,
+    'GA': 25200, 'GC': 17600, 'GG': 21600, 'GT': 20000,
+    'TA': 23400, 'TC': 16200, 'TG': 19000, 'TT': 16800
+}
+DNA_SINGLE_EXTINCTION = {'A': 15400, 'C': 7400, 'G': 11500, 'T': 8700}
+RNA_NN_EXTINCTION = {
+    'AA': 27400, 'AC': 21000, 'AG': 25000, 'AU': 24000,
+    'CA': 21000, 'CC': 14200, 'CG': 17800, 'CU': 16200,
+    'GA': 25200, 'GC': 17400, 'GG': 21600, 'GU': 21200,
+    'UA': 24600, 'UC': 17200, 'UG': 20000, 'UU': 19600
+}
+RNA_SINGLE_EXTINCTION = {'A': 15400, 'C': 7200, 'G': 11500, 'U': 9900}
+
+EXTINCTION_METHODS = ('nearest_neighbor', 'base_sum')
+
+
+def _is_rna(sequence: str) -> bool:
+    return 'U' in sequence and 'T' not in sequence
+
+
+def _base_value(base: str, table: Dict[str, float]) -> float:
+    """Value of a base; ambiguity codes average the bases they stand for"""
+    if base in table:
+        return table[base]
+    options = [table.get(b, table.get('U')) for b in IUPAC_BASES.get(base, '')]  # T reads as U in RNA
+    options = [value for value in options if value is not None]
+    if not options:
+        raise ValueError(f'Invalid nucleotide "{base}"')
+    return sum(options) / len(options)
+
+
+def molecular_weight(sequence: str, double_stranded: bool = False) -> float:
+    """Molecular weight (g/mol) of a synthetic oligo, or of the duplex with its complement"""
+    sequence = clean_sequence(sequence)
+    if not sequence:
+        return 0.0
+    table = RNA_MASSES if _is_rna(sequence) else DNA_MASSES
+    weight = sum(_base_value(base, table) for base in sequence) + END_CORRECTION
+    if double_stranded:
+        weight += molecular_weight(reverse_complement(sequence))
+    return weight
+
+
+def _base_sum_extinction(sequence: str) -> float:
+    return sum(_base_value(base, BASE_EXTINCTION) for base in sequence)
+
+
+def _nearest_neighbor_extinction(sequence: str) -> float:
+    """Single-strand ε260: dinucleotide terms minus the internal bases counted twice"""
+    if len(sequence) < 2:
+        return _base_sum_extinction(sequence)
+    rna = _is_rna(sequence)
+    pairs, singles = (RNA_NN_EXTINCTION, RNA_SINGLE_EXTINCTION) if rna else (DNA_NN_EXTINCTION, DNA_SINGLE_EXTINCTION)
+    epsilon = 0.0
+    for i in range(len(sequence) - 1):
+        dinucleotide = sequence[i:i + 2]
+        if dinucleotide in pairs:
+            epsilon += pairs[dinucleotide]
+        else:
+            # Ambiguity codes: average over the dinucleotides they stand for
+            options = [pairs.get(a + b, pairs.get((a + b).replace('T', 'U')))
+                       for a in IUPAC_BASES.get(dinucleotide[0], '') for b in IUPAC_BASES.get(dinucleotide[1], '')]
+            options = [value for value in options if value is not None]
+            if not options:
+                raise ValueError(f'Invalid dinucleotide "{dinucleotide}"')
+            epsilon += sum(options) / len(options)
+    epsilon -= sum(_base_value(base, singles) for base in sequence[1:-1])
+    return epsilon
+
+
+def extinction_coefficient(sequence: str, double_stranded: bool = False, method: str = 'nearest_neighbor') -> float:
+    """ε260 (M^-1 cm^-1) by the nearest-neighbor model, or as a plain sum of base coefficients
+
+    Duplexes sum both strands and apply the hypochromicity correction of Tataurov et al. (2008),
+    (1 - 0.287 f_AT - 0.059 f_GC).
+    """
+    if method not in EXTINCTION_METHODS:
+        raise ValueError(f'Unknown extinction method "{method}"; use one of {", ".join(EXTINCTION_METHODS)}')
+    sequence = clean_sequence(sequence)
+    if not sequence:
+        return 0.0
+    strand_epsilon = _base_sum_extinction if method == 'base_sum' else _nearest_neighbor_extinction
+    epsilon = strand_epsilon(sequence)
+    if double_stranded:
+        epsilon += strand_epsilon(reverse_complement(sequence))
+        gc = sum(1 for base in sequence if base in 'GCS') / len(sequence)
+        epsilon *= 1 - 0.287 * (1 - gc) - 0.059 * gc
+    return epsilon
+
+
+def nmol_per_od(epsilon: float) -> float:
+    """Nanomoles in one A260 unit (1 ml at A260 = 1, 1 cm path)"""
+    return 1e6 / epsilon if epsilon else 0.0
+
+
+def concentration_from_a260(a260: float, epsilon: float, path_length: float = 1.0, dilution: float = 1.0) -> float:
+    """Molar concentration in µM from an absorbance reading (Beer-Lambert)"""
+    if not epsilon or path_length <= 0:
+        raise ValueError('Extinction coefficient and path length must be positive')
+    return a260 * dilution / (epsilon * path_length) * 1e6
+
+
+def ug_to_pmol(ug: float, weight: float) -> float:
+    return ug * 1e6 / weight if weight else 0.0
+
+
+def pmol_to_ug(pmol: float, weight: float) -> float:
+    return pmol * weight / 1e6
+
+
+def oligo_properties(sequence: str, double_stranded: bool = False, method: str = 'nearest_neighbor') -> Dict:
+    """Weight, ε260 and per-OD amounts of an oligo or duplex"""
+    weight = molecular_weight(sequence, double_stranded)
+    epsilon = extinction_coefficient(sequence, double_stranded, method)
+    nmol = nmol_per_od(epsilon)
+    return {
+        'length': len(clean_sequence(sequence)),
+        'double_stranded': double_stranded,
+        'molecular_weight': round(weight, 2),
+        'extinction_coefficient': round(epsilon),
+        'extinction_method': method,
+        'nmol_per_od': round(nmol, 3),
+        'ug_per_od': round(nmol * weight / 1000, 3)
+    }
@@ -0,0 +1,126 @@
+import multiprocessing
+import os
+import re
+from concurrent.futures import ProcessPoolExecutor
+from typing import Callable, Dict, List, Optional, Tuple
+from .sequence import complement, reverse_complement
+
+# Worker processes for chunked operations; ROBIN_WORKERS overrides the CPU count
+DEFAULT_WORKERS = int(os.environ.get('ROBIN_WORKERS', 0)) or os.cpu_count() or 1
+# Below this length the cost of starting workers outweighs the speedup
+MIN_PARALLEL_LENGTH = 1_000_000
+
+# Sequence being processed, set once in each worker process so only chunk bounds are sent per task
+_shared = ''
+
+
+def chunk_bounds(length: int, chunks: int, overlap: int = 0) -> List[Tuple[int, int]]:
+    """(start, end) of up to `chunks` contiguous pieces, each extended overlap bases to the right
+
+    An empty sequence is one empty piece, (0, 0).
+    """
+    if length <= 0:
+        return [(0, 0)]
+    chunks = max(1, min(chunks, length))
+    step = -(-length // chunks)
+    return [(start, min(start + step + overlap, length)) for start in range(0, length, step)]
+
+
+def process_context():
+    """Multiprocessing context for worker pools
+
+    Workers are spawned rather than forked: the API server is threaded, and a forked child
+    inherits locks other threads held at the time, which it can then never acquire.
+    """
+    return multiprocessing.get_context('spawn')
+
+
+def map_chunks(func: Callable[[str, int, int], object], sequence: str, workers: Optional[int] = None,
+               overlap: int = 0) -> List:
+    """Apply func(sequence, start, end) to chunks of a sequence, in parallel when it is worth it
+
+    Results come back in chunk order. Runs as one chunk for one worker or short sequences;
+    func must be a module-level function (or picklable object) so workers can find it.
+    """
+    workers = workers or DEFAULT_WORKERS
+    if workers == 1 or len(sequence) < MIN_PARALLEL_LENGTH:
+        return [func(sequence, 0, len(sequence))]
+
+    bounds = chunk_bounds(len(sequence), workers, overlap)
+    with ProcessPoolExecutor(max_workers=workers, mp_context=process_context(),
+                             initializer=_share, initargs=(sequence,)) as executor:
+        return list(executor.map(_run_shared, [func] * len(bounds), bounds))
+
+
+def _share(sequence: str):
+    global _shared
+    _shared = sequence
+
+
+def _run_shared(func, bounds):
+    return func(_shared, *bounds)
+
+
+def _count_chunk(sequence: str, start: int, end: int) -> Dict[str, int]:
+    chunk = sequence[start:end]
+    return {base: chunk.count(base) for base in set(chunk)}
+
+
+def base_counts(sequence: str, workers: Optional[int] = None) -> Dict[str, int]:
+    """Occurrences of each character, counted in parallel chunks"""
+    totals = {}
+    for counts in map_chunks(_count_chunk, sequence, workers):
+        for base, count in counts.items():
+            totals[base] = totals.get(base, 0) + count
+    return totals
+
+
+def gc_content(sequence: str, workers: Optional[int] = None) -> float:
+    """GC content percentage (S counts as G/C), as sequence.gc_content, for large sequences"""
+    counts = base_counts(sequence.upper(), workers)
+    total = sum(counts.values())
+    return (counts.get('G', 0) + counts.get('C', 0) + counts.get('S', 0)) / total * 100 if total else 0.0
+
+
+def _complement_chunk(sequence: str, start: int, end: int) -> str:
+    return complement(sequence[start:end])
+
+
+def parallel_complement(sequence: str, workers: Optional[int] = None) -> str:
+    """Complement of a large sequence, computed in chunks"""
+    return ''.join(map_chunks(_complement_chunk, sequence, workers))
+
+
+def parallel_reverse_complement(sequence: str, workers: Optional[int] = None) -> str:
+    """Reverse complement of a large sequence, computed in chunks"""
+    if len(sequence) < MIN_PARALLEL_LENGTH:
+        return reverse_complement(sequence)
+    return parallel_complement(sequence, workers)[::-1]
+
+
+def _find_chunk(sequence: str, start: int, end: int, pattern: str, width: int) -> List[int]:
+    # A chunk owns the matches starting before its overlap; the next chunk reports the rest
+    owned_end = end - (width - 1) if end < len(sequence) else end
+    return [start + m.start() for m in re.finditer(f'(?={pattern})', sequence[start:end])
+            if start + m.start() < owned_end]
+
+
+class _Finder:
+    """Picklable find function bound to one pattern"""
+
+    def __init__(self, pattern: str, width: int):
+        self.pattern, self.width = pattern, width
+
+    def __call__(self, sequence: str, start: int, end: int) -> List[int]:
+        return _find_chunk(sequence, start, end, self.pattern, self.width)
+
+
+def find_all(sequence: str, pattern: str, width: int, workers: Optional[int] = None) -> List[int]:
+    """Start of every (possibly overlapping) match of a regex matching exactly width characters
+
+    Chunks overlap by width - 1 so matches spanning a chunk boundary are found exactly once.
+    """
+    if width < 1:
+        raise ValueError('Match width must be positive')
+    return [position for positions in map_chunks(_Finder(pattern, width), sequence, workers, overlap=width - 1)
+            for position in positions]
@@ -0,0 +1,82 @@
+from typing import Dict, List, Tuple
+from .sequence import IUPAC_BASES, clean_sequence, reverse_complement
+
+
+def _bases_match(primer_base: str, template_base: str) -> bool:
+    """Ambiguity codes match when the bases they stand for overlap"""
+    return bool(set(IUPAC_BASES.get(primer_base, '')) & set(IUPAC_BASES.get(template_base, '')))
+
+
+def binding_sites(template: str, primer: str, max_mismatches: int = 2, three_prime_exact: int = 3,
+                  circular: bool = False) -> List[Dict]:
+    """Where a primer anneals, on either strand
+
+    A '+' site means the primer matches the top strand at [start, end) and extends
+    rightwards; a '-' site means its reverse complement matches there and it extends
+    leftwards. Mismatches are only allowed outside the 3'-most three_prime_exact bases.
+    """
+    template, primer = clean_sequence(template), clean_sequence(primer)
+    if not primer:
+        raise ValueError('Primer sequence is required')
+    length = len(primer)
+    search = template + template[:length - 1] if circular else template
+    sites = []
+    for strand, probe in (('+', primer), ('-', reverse_complement(primer))):
+        # 3' end of the primer is at the right of the probe on '+', at the left on '-'
+        exact = range(length - three_prime_exact, length) if strand == '+' else range(three_prime_exact)
+        for start in range(len(template) if circular else len(template) - length + 1):
+            window = search[start:start + length]
+            if any(not _bases_match(probe[i], window[i]) for i in exact if i < length):
+                continue
+            mismatches = 0
+            for primer_base, template_base in zip(probe, window):
+                if not _bases_match(primer_base, template_base):
+                    mismatches += 1
+                    if mismatches > max_mismatches:
+                        break
+            else:
+                sites.append({'strand': strand, 'start': start, 'end': start + length, 'mismatches': mismatches})
+    return sites
+
+
+def virtual_pcr(template: str, primers: List[Tuple[str, str]], max_mismatches: int = 2, three_prime_exact: int = 3,
+                circular: bool = False, max_size: int = 10000) -> List[Dict]:
+    """Predicted amplicons from (name, sequence) primers on a template
+
+    Any '+' site followed downstream by a '-' site within max_size gives a product,
+    including products primed from both ends by the same primer. Product sequences carry
+    the primers' own sequences (so 5' tails are included); coordinates are 0-based,
+    end-exclusive on the template and may wrap for circular templates.
+    """
+    template = clean_sequence(template)
+    sites = []
+    for name, sequence in primers:
+        for site in binding_sites(template, sequence, max_mismatches, three_prime_exact, circular):
+            sites.append(dict(site, primer=name, sequence=clean_sequence(sequence)))
+
+    unrolled = template * 2 if circular else template
+    amplicons = []
+    for forward in (site for site in sites if site['strand'] == '+'):
+        for reverse in (site for site in sites if site['strand'] == '-'):
+            reverse_start, end = reverse['start'], reverse['end']
+            if reverse_start < forward['end']:
+                if not circular:
+                    continue
+                # Product runs through the origin
+                reverse_start, end = reverse_start + len(template), end + len(template)
+            size = end - forward['start']
+            if size > max_size:
+                continue
+            amplicons.append({
+                'forward_primer': forward['primer'],
+                'reverse_primer': reverse['primer'],
+                'start': forward['start'],
+                'end': end % len(template) if end > len(template) else end,
+                'size': size,
+                'forward_mismatches': forward['mismatches'],
+                'reverse_mismatches': reverse['mismatches'],
+                'sequence': (forward['sequence'] + unrolled[forward['end']:reverse_start]
+                             + reverse_complement(reverse['sequence']))
+            })
+    return sorted(amplicons, key=lambda amplicon: (amplicon['forward_mismatches'] + amplicon['reverse_mismatches'],
+                                                     amplicon['start'], amplicon['size']))
@@ -0,0 +1,323 @@
+import math
+from dataclasses import dataclass, field
+from typing import Dict, FrozenSet, List, Optional, Set
+from xml.sax.saxutils import escape
+from .context import check_cancelled
+from .metrics import DISTANCE_MODELS
+from .seqio import SequenceRecord
+
+
+@dataclass
+class TreeNode:
+    """Tree node; leaves have a name and no children, branch_length leads to the parent"""
+    name: str = ""
+    children: List['TreeNode'] = field(default_factory=list)
+    branch_length: Optional[float] = None
+
+    @property
+    def is_leaf(self) -> bool:
+        return not self.children
+
+    def leaves(self) -> List['TreeNode']:
+        if self.is_leaf:
+            return [self]
+        return [leaf for child in self.children for leaf in child.leaves()]
+
+    def to_newick(self) -> str:
+        return self._newick() + ';'
+
+    def _newick(self) -> str:
+        text = _newick_name(self.name)
+        if self.children:
+            text = '(' + ','.join(child._newick() for child in self.children) + ')' + text
+        if self.branch_length is not None:
+            text += f':{self.branch_length:.6g}'
+        return text
+
+
+def _newick_name(name: str) -> str:
+    if any(char in name for char in ' ():;,[]\''):
+        return "'" + name.replace("'", "''") + "'"
+    return name
+
+
+def distance_matrix(records: List[SequenceRecord], model: str = 'jc69') -> List[List[float]]:
+    """Symmetric pairwise distance matrix of aligned records"""
+    if model not in DISTANCE_MODELS:
+        raise ValueError(f'Unknown distance model "{model}"; use one of {", ".join(DISTANCE_MODELS)}')
+    distance = DISTANCE_MODELS[model]
+    size = len(records)
+    matrix = [[0.0] * size for _ in range(size)]
+    for i in range(size):
+        check_cancelled()
+        for j in range(i + 1, size):
+            matrix[i][j] = matrix[j][i] = distance(records[i].sequence, records[j].sequence)
+    return matrix
+
+
+def neighbor_joining(names: List[str], matrix: List[List[float]]) -> TreeNode:
+    """Unrooted neighbor-joining tree (Saitou & Nei), returned with a trifurcating root"""
+    if len(names) < 2:
+        raise ValueError('At least two sequences are needed to build a tree')
+    if any(math.isinf(value) for row in matrix for value in row):
+        raise ValueError('Some distances are infinite (sequences too divergent for the model)')
+
+    nodes = [TreeNode(name=name) for name in names]
+    d = [row[:] for row in matrix]
+
+    while len(nodes) > 3:
+        check_cancelled()
+        n = len(nodes)
+        totals = [sum(row) for row in d]
+        best, pair = None, (0, 1)
+        for i in range(n):
+            for j in range(i + 1, n):
+                q = (n - 2) * d[i][j] - totals[i] - totals[j]
+                if best is None or q < best:
+                    best, pair = q, (i, j)
+
+        i, j = pair
+        length_i = 0.5 * d[i][j] + (totals[i] - totals[j]) / (2 * (n - 2))
+        nodes[i].branch_length = max(length_i, 0.0)
+        nodes[j].branch_length = max(d[i][j] - length_i, 0.0)
+        joined = TreeNode(children=[nodes[i], nodes[j]])
+
+        new_row = [0.5 * (d[i][k] + d[j][k] - d[i][j]) for k in range(n) if k not in (i, j)]
+        keep = [k for k in range(n) if k not in (i, j)]
+        d = [[d[a][b] for b in keep] + [new_row[x]] for x, a in enumerate(keep)] + [new_row + [0.0]]
+        nodes = [nodes[k] for k in keep] + [joined]
+
+    if len(nodes) == 2:
+        nodes[0].branch_length = nodes[1].branch_length = max(d[0][1] / 2, 0.0)
+        return TreeNode(children=nodes)
+
+    # Three nodes left: join them at a central node
+    a, b, c = 0, 1, 2
+    nodes[a].branch_length = max((d[a][b] + d[a][c] - d[b][c]) / 2, 0.0)
+    nodes[b].branch_length = max((d[a][b] + d[b][c] - d[a][c]) / 2, 0.0)
+    nodes[c].branch_length = max((d[a][c] + d[b][c] - d[a][b]) / 2, 0.0)
+    return TreeNode(children=nodes)
+
+
+def upgma(names: List[str], matrix: List[List[float]]) -> TreeNode:
+    """Rooted ultrametric tree by average-linkage clustering (UPGMA)"""
+    if len(names) < 2:
+        raise ValueError('At least two sequences are needed to build a tree')
+    if any(math.isinf(value) for row in matrix for value in row):
+        raise ValueError('Some distances are infinite (sequences too divergent for the model)')
+
+    clusters = [(TreeNode(name=name), 1, 0.0) for name in names]  # (node, size, height)
+    d = [row[:] for row in matrix]
+
+    while len(clusters) > 1:
+        check_cancelled()
+        n = len(clusters)
+        i, j = min(((a, b) for a in range(n) for b in range(a + 1, n)), key=lambda pair: d[pair[0]][pair[1]])
+        (node_i, size_i, height_i), (node_j, size_j, height_j) = clusters[i], clusters[j]
+        height = d[i][j] / 2
+        node_i.branch_length = max(height - height_i, 0.0)
+        node_j.branch_length = max(height - height_j, 0.0)
+        merged = (TreeNode(children=[node_i, node_j]), size_i + size_j, height)
+
+        new_row = [(d[i][k] * size_i + d[j][k] * size_j) / (size_i + size_j) for k in range(n) if k not in (i, j)]
+        keep = [k for k in range(n) if k not in (i, j)]
+        d = [[d[a][b] for b in keep] + [new_row[x]] for x, a in enumerate(keep)] + [new_row + [0.0]]
+        clusters = [clusters[k] for k in keep] + [merged]
+
+    return clusters[0][0]
+
+
+TREE_METHODS = {
+    'nj': neighbor_joining,
+    'upgma': upgma,
+}
+
+
+def build_tree(records: List[SequenceRecord], model: str = 'jc69', method: str = 'nj') -> Dict:
+    """Distance matrix and neighbor-joining (or UPGMA) tree of aligned records"""
+    if method not in TREE_METHODS:
+        raise ValueError(f'Unknown tree method "{method}"; use one of {", ".join(TREE_METHODS)}')
+    matrix = distance_matrix(records, model)
+    tree = TREE_METHODS[method]([record.name for record in records], matrix)
+    return {'names': [record.name for record in records], 'matrix': matrix, 'tree': tree}
+
+
+def render_tree_svg(tree: TreeNode, width: int = 600, row_height: int = 22) -> str:
+    """Rectangular phylogram: x is the distance from the root, one row per leaf"""
+    leaves = tree.leaves()
+    leaf_rows = {id(leaf): i for i, leaf in enumerate(leaves)}
+    depths: Dict[int, float] = {}
+    rows: Dict[int, float] = {}
+
+    def place(node: TreeNode, depth: float):
+        depths[id(node)] = depth
+        for child in node.children:
+            place(child, depth + (child.branch_length or 0.0))
+        if node.is_leaf:
+            rows[id(node)] = leaf_rows[id(node)]
+        else:
+            rows[id(node)] = sum(rows[id(child)] for child in node.children) / len(node.children)
+
+    place(tree, 0.0)
+    max_depth = max(depths.values()) or 1.0
+    margin, label_space = 20, 160
+    scale = (width - 2 * margin - label_space) / max_depth
+    height = len(leaves) * row_height + 2 * margin + 20
+
+    def x_of(node):
+        return margin + depths[id(node)] * scale
+
+    def y_of(node):
+        return margin + rows[id(node)] * row_height + row_height / 2
+
+    svg = [f'<svg xmlns="http://www.w3.org/2000/svg" width="{width}" height="{height}" '
+           f'font-family="sans-serif" font-size="12">']
+
+    def draw(node: TreeNode):
+        for child in node.children:
+            svg.append(f'<line x1="{x_of(node):.2f}" y1="{y_of(child):.2f}" x2="{x_of(child):.2f}" '
+                       f'y2="{y_of(child):.2f}" stroke="#374151" stroke-width="1.5"/>')
+            draw(child)
+        if node.children:
+            svg.append(f'<line x1="{x_of(node):.2f}" y1="{y_of(node.children[0]):.2f}" x2="{x_of(node):.2f}" '
+                       f'y2="{y_of(node.children[-1]):.2f}" stroke="#374151" stroke-width="1.5"/>')
+        else:
+            svg.append(f'<text x="{x_of(node) + 4:.2f}" y="{y_of(node) + 4:.2f}">{escape(node.name)}</text>')
+
+    draw(tree)
+
+    # Scale bar a tenth of the tree depth long
+    bar = max_depth / 10
+    y = height - margin
+    svg.append(f'<line x1="{margin}" y1="{y}" x2="{margin + bar * scale:.2f}" y2="{y}" stroke="#111827"/>')
+    svg.append(f'<text x="{margin + bar * scale + 4:.2f}" y="{y + 4}" font-size="10">{bar:.3g}</text>')
+    svg.append('</svg>')
+    return '\n'.join(svg)
+
+
+def parse_newick(text: str) -> TreeNode:
+    """Tree from Newick text: quoted or plain labels, optional branch lengths, [comments] ignored"""
+    text = text.strip()
+    position = 0
+
+    def peek() -> str:
+        return text[position] if position < len(text) else ''
+
+    def skip():
+        nonlocal position
+        while position < len(text) and (text[position].isspace() or text[position] == '['):
+            if text[position] == '[':
+                end = text.find(']', position)
+                if end < 0:
+                    raise ValueError('Unterminated Newick comment')
+                position = end + 1
+            else:
+                position += 1
+
+    def label() -> str:
+        nonlocal position
+        skip()
+        if peek() == "'":
+            chars = []
+            position += 1
+            while position < len(text):
+                if text[position] == "'":
+                    if text[position + 1:position + 2] == "'":
+                        chars.append("'")
+                        position += 2
+                        continue
+                    position += 1
+                    return ''.join(chars)
+                chars.append(text[position])
+                position += 1
+            raise ValueError('Unterminated quoted Newick label')
+        start = position
+        while position < len(text) and text[position] not in '(),:;[' and not text[position].isspace():
+            position += 1
+        return text[start:position].replace('_', ' ')
+
+    def subtree() -> TreeNode:
+        nonlocal position
+        skip()
+        node = TreeNode()
+        if peek() == '(':
+            position += 1
+            node.children.append(subtree())
+            skip()
+            while peek() == ',':
+                position += 1
+                node.children.append(subtree())
+                skip()
+            if peek() != ')':
+                raise ValueError(f'Expected ")" at position {position} in Newick text')
+            position += 1
+        node.name = label()
+        skip()
+        if peek() == ':':
+            position += 1
+            skip()
+            start = position
+            while position < len(text) and text[position] not in '(),;[' and not text[position].isspace():
+                position += 1
+            try:
+                node.branch_length = float(text[start:position])
+            except ValueError:
+                raise ValueError(f'Invalid branch length "{text[start:position]}"')
+        return node
+
+    tree = subtree()
+    skip()
+    if peek() == ';':
+        position += 1
+    skip()
+    if position != len(text):
+        raise ValueError(f'Unexpected text after the end of the Newick tree at position {position}')
+    return tree
+
+
+def splits(tree: TreeNode) -> Set[FrozenSet[str]]:
+    """Non-trivial bipartitions of the leaf set, as the side without the alphabetically first leaf
+
+    Rooting is ignored, so a rooted and an unrooted version of the same tree have the same splits.
+    """
+    names = [leaf.name for leaf in tree.leaves()]
+    if len(set(names)) != len(names):
+        raise ValueError('Leaf names must be unique')
+    everything = frozenset(names)
+    anchor = min(names)
+    result = set()
+
+    def collect(node: TreeNode) -> FrozenSet[str]:
+        if node.is_leaf:
+            return frozenset([node.name])
+        below = frozenset().union(*(collect(child) for child in node.children))
+        side = everything - below if anchor in below else below
+        if 1 < len(side) < len(everything) - 1:
+            result.add(side)
+        return below
+
+    collect(tree)
+    return result
+
+
+def robinson_foulds(tree_a: TreeNode, tree_b: TreeNode) -> Dict:
+    """Robinson-Foulds distance: bipartitions found in only one of the trees
+
+    The normalized distance divides by the maximum possible, 2(n - 3) for n leaves.
+    """
+    leaves_a = {leaf.name for leaf in tree_a.leaves()}
+    leaves_b = {leaf.name for leaf in tree_b.leaves()}
+    if leaves_a != leaves_b:
+        raise ValueError('Trees must have the same leaf names')
+
+    splits_a, splits_b = splits(tree_a), splits(tree_b)
+    distance = len(splits_a ^ splits_b)
+    maximum = 2 * (len(leaves_a) - 3)
+    return {
+        'distance': distance,
+        'max_distance': max(maximum, 0),
+        'normalized': round(distance / maximum, 4) if maximum > 0 else 0.0,
+        'shared_splits': len(splits_a & splits_b),
+        'only_in_a': len(splits_a - splits_b),
+        'only_in_b': len(splits_b - splits_a)
+    }
@@ -0,0 +1,233 @@
+import io
+import json
+from dataclasses import dataclass, field
+from typing import Any, Callable, Dict, List, Optional, Tuple
+from .assembly import assemble_de_bruijn, assembly_stats
+from .context import check_cancelled
+from .mapper import ReadMapper
+from .registry import registry
+from .sam import mapping_summary, sam_header, sam_record
+from .seqio import FastqRecord, SequenceRecord, format_fasta, format_fastq, read_fastq, read_sequences
+from .trimming import TrimSettings, trim_reads
+from .variants import call_variants, coverage, coverage_summary, format_vcf, pileup
+
+SEQUENCE_FORMATS = ('fasta', 'fastq')
+
+
+@dataclass
+class Artifact:
+    """A pipeline input or step output: the value the next step takes, and the text kept in the workspace"""
+    name: str
+    format: str  # fasta, fastq, sam, pileup, vcf or json
+    value: Any
+    text: str
+    summary: Dict = field(default_factory=dict)
+
+
+def sequence_artifact(name: str, text: str) -> Artifact:
+    """FASTQ (detected by a leading '@') or FASTA/raw records as an artifact"""
+    if text.lstrip().startswith('@'):
+        records = list(read_fastq(io.StringIO(text.lstrip())))
+        return Artifact(name, 'fastq', records, format_fastq(records), {'records': len(records)})
+    records = list(read_sequences(io.StringIO(text)))
+    return Artifact(name, 'fasta', records, format_fasta(records), {'records': len(records)})
+
+
+def _trim(inputs: Dict[str, Artifact], params: Dict, name: str) -> Artifact:
+    # FASTA reads have no qualities, so only adapter trimming and length filtering apply
+    reads = [read if isinstance(read, FastqRecord) else FastqRecord(read.name, read.sequence, 'I' * len(read.sequence))
+             for read in inputs['reads'].value]
+    adapters = params.get('adapters', ())
+    if isinstance(adapters, str):
+        adapters = [adapter.strip() for adapter in adapters.split(',') if adapter.strip()]
+    settings = TrimSettings(
+        adapters=tuple(adapters),
+        window=int(params.get('window', 4)),
+        min_window_quality=float(params.get('min_quality', 20)),
+        min_length=int(params.get('min_length', 30)),
+        max_length=int(params['max_length']) if params.get('max_length') else None
+    )
+    kept, report = trim_reads(reads, settings)
+    return Artifact(name, 'fastq', kept, format_fastq(kept), report)
+
+
+def _map(inputs: Dict[str, Artifact], params: Dict, name: str) -> Artifact:
+    mapper = ReadMapper(inputs['reference'].value, k=int(params.get('k', 15)), w=int(params.get('w', 10)))
+    mapped = []
+    for record in inputs['reads'].value:
+        check_cancelled()
+        mapped.append((record, mapper.map_read(record.sequence)))
+    text = sam_header(mapper.references) + ''.join(sam_record(record, hit) + '\n' for record, hit in mapped)
+    return Artifact(name, 'sam', {'references': mapper.references, 'mapped': mapped}, text,
+                    mapping_summary([hit for _, hit in mapped]))
+
+
+def _pileup(inputs: Dict[str, Artifact], params: Dict, name: str) -> Artifact:
+    alignments = inputs['alignments'].value
+    mapped = [(record.sequence, hit) for record, hit in alignments['mapped']]
+    columns, lines, summary = {}, ['#chrom\tpos\tref\tdepth\tA\tC\tG\tT\tdel\tins'], {}
+    for reference in alignments['references']:
+        check_cancelled()
+        columns[reference.name] = pileup(reference, mapped)
+        for column in columns[reference.name]:
+            inserted = ','.join(f'{sequence}:{count}' for sequence, count in column.insertions.most_common())
+            lines.append('\t'.join(str(value) for value in (
+                reference.name, column.position + 1, column.reference_base, column.depth,
+                *(column.bases[base] for base in 'ACGT*'), inserted or '.')))
+        summary[reference.name] = coverage_summary(coverage(columns[reference.name]))
+    return Artifact(name, 'pileup', {'references': alignments['references'], 'columns': columns},
+                    '\n'.join(lines) + '\n', summary)
+
+
+def _call(inputs: Dict[str, Artifact], params: Dict, name: str) -> Artifact:
+    piles = inputs['pileup'].value
+    variants = []
+    for reference in piles['references']:
+        variants.extend(call_variants(reference.name, piles['columns'][reference.name],
+                                      int(params.get('min_depth', 10)), float(params.get('min_frequency', 0.2))))
+    return Artifact(name, 'vcf', variants, format_vcf(variants, piles['references']), {'variants': len(variants)})
+
+
+def _assemble(inputs: Dict[str, Artifact], params: Dict, name: str) -> Artifact:
+    contigs = assemble_de_bruijn([record.sequence for record in inputs['reads'].value], k=int(params.get('k', 31)),
+                                 min_count=int(params.get('min_count', 2)),
+                                 min_contig_length=int(params.get('min_contig_length', 0)))
+    records = [SequenceRecord(contig.name, contig.sequence) for contig in contigs]
+    return Artifact(name, 'fasta', records, format_fasta(records), assembly_stats(contigs))
+
+
+@dataclass
+class StepType:
+    """What a pipeline step consumes, role by role, and the format it produces; the first role is the main input"""
+    roles: Dict[str, Tuple[str, ...]]  # role -> accepted formats
+    output: str
+    run: Callable[[Dict[str, Artifact], Dict, str], Artifact]
+
+
+STEP_TYPES = {
+    'trim': StepType({'reads': SEQUENCE_FORMATS}, 'fastq', _trim),
+    'map': StepType({'reads': SEQUENCE_FORMATS, 'reference': ('fasta',)}, 'sam', _map),
+    'pileup': StepType({'alignments': ('sam',)}, 'pileup', _pileup),
+    'call': StepType({'pileup': ('pileup',)}, 'vcf', _call),
+    'assemble': StepType({'reads': SEQUENCE_FORMATS}, 'fasta', _assemble)
+}
+
+
+def _plugin_step(analysis) -> StepType:
+    """Analysis plugins run on every record of their input, producing a JSON list of results"""
+
+    def run(inputs: Dict[str, Artifact], params: Dict, name: str) -> Artifact:
+        results = []
+        for record in inputs['sequences'].value:
+            check_cancelled()
+            results.append(analysis(record, params))
+        return Artifact(name, 'json', results, json.dumps(results, indent=2) + '\n', {'records': len(results)})
+
+    return StepType({'sequences': SEQUENCE_FORMATS}, 'json', run)
+
+
+def step_type(analysis: str) -> Optional[StepType]:
+    """Built-in step, or else a registered analysis plugin"""
+    if analysis in STEP_TYPES:
+        return STEP_TYPES[analysis]
+    plugin = registry.discover().get(analysis)
+    return _plugin_step(plugin) if plugin is not None else None
+
+
+@dataclass
+class PipelineStep:
+    id: str
+    analysis: str
+    inputs: Dict[str, str]  # role -> name of a pipeline input or an earlier step's output
+    params: Dict = field(default_factory=dict)
+
+
+@dataclass
+class Pipeline:
+    """Analyses chained by name, each step's output available to later steps under the step's id
+
+    A step is {analysis, id, params} plus role: name bindings, e.g. {analysis: map, reference: ref}. A step's main
+    role defaults to the output of the step before it (the first step's to the first input), and other roles to
+    the input or output named like the role. Inputs are the names the steps use that no step produces.
+    """
+    name: str
+    inputs: List[str]
+    steps: List[PipelineStep]
+
+    @classmethod
+    def from_dict(cls, data: Dict) -> 'Pipeline':
+        if not isinstance(data, dict) or not data.get('steps'):
+            raise ValueError('A pipeline needs a list of steps')
+        declared = [str(name) for name in data.get('inputs') or []]
+        steps, produced, used = [], set(), []
+        for i, raw in enumerate(data['steps']):
+            if not isinstance(raw, dict) or not raw.get('analysis'):
+                raise ValueError(f'Step {i + 1} needs an analysis')
+            analysis = str(raw['analysis'])
+            kind = step_type(analysis)
+            if kind is None:
+                raise ValueError(f'Step {i + 1}: unknown analysis "{analysis}". Available: '
+                                 f'{sorted(STEP_TYPES) + [item.name for item in registry]}')
+            step_id = str(raw.get('id') or (analysis if analysis not in produced else f'{analysis}-{i + 1}'))
+            if step_id in produced or step_id in declared:
+                raise ValueError(f'Step id "{step_id}" is used twice')
+            inputs = {}
+            for j, role in enumerate(kind.roles):
+                if raw.get(role):
+                    inputs[role] = str(raw[role])
+                elif j == 0 and steps:
+                    inputs[role] = steps[-1].id
+                elif j == 0 and (declared or used):
+                    inputs[role] = (declared or used)[0]
+                else:
+                    inputs[role] = role
+            unknown = set(raw) - set(kind.roles) - {'id', 'analysis', 'params'}
+            if unknown:
+                raise ValueError(f'Step "{step_id}" has unknown fields {sorted(unknown)}; {analysis} takes '
+                                 f'{list(kind.roles)}')
+            used.extend(name for name in inputs.values() if name not in produced and name not in used)
+            steps.append(PipelineStep(step_id, analysis, inputs, dict(raw.get('params') or {})))
+            produced.add(step_id)
+        inputs = declared or used
+        missing = [name for name in used if name not in inputs]
+        if missing:
+            raise ValueError(f'Steps use {missing}, which are neither inputs nor earlier steps')
+        return cls(str(data.get('name') or 'pipeline'), inputs, steps)
+
+    def to_dict(self) -> Dict:
+        return {'name': self.name, 'inputs': self.inputs,
+                'steps': [{'id': step.id, 'analysis': step.analysis, **step.inputs, 'params': step.params}
+                          for step in self.steps]}
+
+    def run(self, inputs: Dict[str, Artifact],
+            on_artifact: Optional[Callable[[PipelineStep, Artifact], None]] = None) -> Dict[str, Artifact]:
+        """Run the steps in order; on_artifact hears every step output as soon as it exists"""
+        missing = [name for name in self.inputs if name not in inputs]
+        if missing:
+            raise ValueError(f'Pipeline "{self.name}" needs inputs {missing}')
+        artifacts = dict(inputs)
+        for step in self.steps:
+            check_cancelled()
+            kind = step_type(step.analysis)
+            for role, name in step.inputs.items():
+                if artifacts[name].format not in kind.roles[role]:
+                    raise ValueError(f'Step "{step.id}" takes {"/".join(kind.roles[role])} as {role}, but '
+                                     f'"{name}" is {artifacts[name].format}')
+            artifact = kind.run({role: artifacts[name] for role, name in step.inputs.items()}, step.params, step.id)
+            artifacts[step.id] = artifact
+            if on_artifact:
+                on_artifact(step, artifact)
+        return artifacts
+
+
+def parse_pipeline(text: str) -> Pipeline:
+    """Pipeline from JSON or YAML text"""
+    try:
+        data = json.loads(text)
+    except ValueError:
+        import yaml  # only YAML pipelines need PyYAML
+        try:
+            data = yaml.safe_load(text)
+        except yaml.YAMLError as e:
+            raise ValueError(f'Pipeline is neither JSON nor YAML: {e}')
+    return Pipeline.from_dict(data)
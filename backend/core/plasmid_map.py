@@ -0,0 +1,131 @@
+import math
+from typing import Dict, List, Optional
+from xml.sax.saxutils import escape
+from .genbank import Feature, GenBankRecord
+from .restriction import ENZYMES, find_sites
+
+FEATURE_COLORS = {
+    'CDS': '#2563eb',
+    'gene': '#60a5fa',
+    'promoter': '#059669',
+    'terminator': '#dc2626',
+    'rep_origin': '#d97706',
+    'primer_bind': '#7c3aed',
+    'misc_feature': '#6b7280',
+}
+DEFAULT_COLOR = '#9ca3af'
+HIDDEN_FEATURE_TYPES = ('source',)
+
+
+def unique_cutters(sequence: str, catalog: Dict = None, circular: bool = False) -> Dict[str, int]:
+    """Enzymes from the catalog that cut the sequence exactly once, with their cut position"""
+    catalog = catalog or ENZYMES
+    sites = {}
+    for name, enzyme in catalog.items():
+        if enzyme.nick:
+            continue
+        cuts = find_sites(sequence, enzyme, circular)
+        if len(cuts) == 1:
+            sites[name] = cuts[0]
+    return sites
+
+
+class _Circle:
+    """Maps sequence positions to angles and points around the map"""
+
+    def __init__(self, length: int, center: float):
+        self.length = max(length, 1)
+        self.center = center
+
+    def angle(self, position: float) -> float:
+        return 2 * math.pi * position / self.length - math.pi / 2  # position 0 at 12 o'clock
+
+    def point(self, position: float, radius: float):
+        a = self.angle(position)
+        return self.center + radius * math.cos(a), self.center + radius * math.sin(a)
+
+    def arc(self, start: float, end: float, radius: float) -> str:
+        x1, y1 = self.point(start, radius)
+        x2, y2 = self.point(end, radius)
+        large = 1 if (end - start) > self.length / 2 else 0
+        return f'M {x1:.2f} {y1:.2f} A {radius:.2f} {radius:.2f} 0 {large} 1 {x2:.2f} {y2:.2f}'
+
+
+def _feature_arc(circle: _Circle, feature: Feature, start: int, end: int, radius: float,
+                 thickness: float, color: str) -> str:
+    """Thick arc for one feature part, with an arrowhead on the 3' end when the part is long enough"""
+    head = min((end - start) * 0.3, circle.length * 0.015)
+    body_start, body_end = (start, end - head) if feature.strand >= 0 else (start + head, end)
+    svg = [f'<path d="{circle.arc(body_start, body_end, radius)}" fill="none" stroke="{color}" '
+           f'stroke-width="{thickness}"/>']
+    if head > 0:
+        tip, base = (end, end - head) if feature.strand >= 0 else (start, start + head)
+        points = [circle.point(base, radius + thickness * 0.8), circle.point(tip, radius),
+                  circle.point(base, radius - thickness * 0.8)]
+        svg.append('<polygon points="' + ' '.join(f'{x:.2f},{y:.2f}' for x, y in points)
+                   + f'" fill="{color}"/>')
+    return '\n'.join(svg)
+
+
+def render_plasmid_map(record: GenBankRecord, enzymes: Optional[List[str]] = None, size: int = 600) -> str:
+    """Circular map of a GenBank record as SVG
+
+    Features are drawn as arrows around the backbone (forward strand outside, reverse inside)
+    and restriction sites as labeled ticks. Without an explicit enzyme list, single cutters
+    from the built-in catalog are shown.
+    """
+    length = len(record.sequence)
+    if not length:
+        raise ValueError('GenBank record has no sequence')
+
+    circle = _Circle(length, size / 2)
+    backbone = size * 0.3
+    thickness = size * 0.025
+
+    if enzymes is None:
+        sites = unique_cutters(record.sequence, circular=record.circular)
+    else:
+        sites = {}
+        for name in enzymes:
+            if name not in ENZYMES:
+                raise ValueError(f'Unknown enzyme "{name}"')
+            for i, cut in enumerate(find_sites(record.sequence, ENZYMES[name], record.circular)):
+                sites[name if i == 0 else f'{name} ({i + 1})'] = cut
+
+    svg = [
+        f'<svg xmlns="http://www.w3.org/2000/svg" width="{size}" height="{size}" '
+        f'viewBox="0 0 {size} {size}" font-family="sans-serif" font-size="{size / 50:.1f}">',
+        f'<circle cx="{circle.center}" cy="{circle.center}" r="{backbone}" fill="none" stroke="#374151" stroke-width="2"/>',
+        f'<text x="{circle.center}" y="{circle.center - 4}" text-anchor="middle" font-weight="bold" '
+        f'font-size="{size / 35:.1f}">{escape(record.name)}</text>',
+        f'<text x="{circle.center}" y="{circle.center + size / 30:.1f}" text-anchor="middle" fill="#6b7280">{length} bp</text>'
+    ]
+
+    for feature in record.features:
+        if feature.type in HIDDEN_FEATURE_TYPES:
+            continue
+        color = FEATURE_COLORS.get(feature.type, DEFAULT_COLOR)
+        radius = backbone + (thickness if feature.strand >= 0 else -thickness)
+        for start, end in feature.parts:
+            svg.append(_feature_arc(circle, feature, start, end, radius, thickness, color))
+
+        span = feature.end - feature.start if not feature.wraps else length - feature.start + feature.end
+        middle = feature.start + span / 2
+        label_radius = backbone + thickness * (3 if feature.strand >= 0 else -3.2)
+        x, y = circle.point(middle, label_radius)
+        anchor = 'middle'
+        if feature.strand >= 0:
+            anchor = 'start' if x > circle.center + 1 else ('end' if x < circle.center - 1 else 'middle')
+        svg.append(f'<text x="{x:.2f}" y="{y:.2f}" text-anchor="{anchor}" fill="{color}">'
+                   f'<title>{escape(feature.type)} {escape(feature.location)}</title>{escape(feature.label)}</text>')
+
+    for name, cut in sorted(sites.items(), key=lambda item: item[1]):
+        x1, y1 = circle.point(cut, backbone - thickness * 0.5)
+        x2, y2 = circle.point(cut, size * 0.44)
+        tx, ty = circle.point(cut, size * 0.455)
+        anchor = 'start' if tx > circle.center + 1 else ('end' if tx < circle.center - 1 else 'middle')
+        svg.append(f'<line x1="{x1:.2f}" y1="{y1:.2f}" x2="{x2:.2f}" y2="{y2:.2f}" stroke="#9ca3af" stroke-width="1"/>')
+        svg.append(f'<text x="{tx:.2f}" y="{ty:.2f}" text-anchor="{anchor}" fill="#111827">{escape(name)} ({cut})</text>')
+
+    svg.append('</svg>')
+    return '\n'.join(svg)
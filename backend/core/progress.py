@@ -0,0 +1,43 @@
+import time
+from dataclasses import dataclass
+from typing import Callable, Dict, Optional
+
+
+@dataclass
+class ProgressUpdate:
+    """How far a long operation has got within its current stage"""
+    stage: str
+    percent: float
+    eta: Optional[float]  # seconds left in the stage, once there is enough to go on
+
+    def to_dict(self) -> Dict:
+        return {'stage': self.stage, 'percent': round(self.percent, 1),
+                'eta': round(self.eta, 1) if self.eta is not None else None}
+
+
+class Progress:
+    """Optional progress sink accepted by alignment, folding and assembly
+
+    Operations call update(stage, done, total) as they go. The callback hears at most one
+    update per interval seconds, plus the end of every stage; the ETA extrapolates the rate
+    of the stage so far, so it is only as steady as the work is even.
+    """
+
+    def __init__(self, callback: Callable[[ProgressUpdate], None], interval: float = 0.5):
+        self.callback = callback
+        self.interval = interval
+        self.stage = ''
+        self.started = self.reported = 0.0
+
+    def update(self, stage: str, done: float, total: float):
+        now = time.monotonic()
+        if stage != self.stage:
+            self.stage, self.started, self.reported = stage, now, 0.0
+        finished = done >= total
+        if not finished and now - self.reported < self.interval:
+            return
+        self.reported = now
+        fraction = min(done / total, 1.0) if total else 1.0
+        elapsed = now - self.started
+        eta = elapsed * (1 - fraction) / fraction if fraction and elapsed else None
+        self.callback(ProgressUpdate(stage, fraction * 100, 0.0 if finished else eta))
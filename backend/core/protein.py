@@ -0,0 +1,119 @@
+from dataclasses import dataclass
+from typing import Dict
+
+AMINO_ACIDS = 'ACDEFGHIKLMNPQRSTVWY'
+
+# Average residue masses (Da), i.e. amino acid minus water
+RESIDUE_MASSES = {
+    'A': 71.0788, 'R': 156.1875, 'N': 114.1038, 'D': 115.0886, 'C': 103.1388,
+    'E': 129.1155, 'Q': 128.1307, 'G': 57.0519, 'H': 137.1411, 'I': 113.1594,
+    'L': 113.1594, 'K': 128.1741, 'M': 131.1926, 'F': 147.1766, 'P': 97.1167,
+    'S': 87.0782, 'T': 101.1051, 'W': 186.2132, 'Y': 163.1760, 'V': 99.1326,
+    'X': 110.0  # unknown residue: typical average
+}
+WATER_MASS = 18.01524
+
+# Kyte & Doolittle hydropathy
+HYDROPATHY = {
+    'A': 1.8, 'R': -4.5, 'N': -3.5, 'D': -3.5, 'C': 2.5, 'Q': -3.5, 'E': -3.5, 'G': -0.4,
+    'H': -3.2, 'I': 4.5, 'L': 3.8, 'K': -3.9, 'M': 1.9, 'F': 2.8, 'P': -1.6, 'S': -0.8,
+    'T': -0.7, 'W': -0.9, 'Y': -1.3, 'V': 4.2
+}
+
+# pKa values (EMBOSS) of the termini and ionizable side chains
+PKA_N_TERMINUS = 8.6
+PKA_C_TERMINUS = 3.6
+PKA_POSITIVE = {'K': 10.8, 'R': 12.5, 'H': 6.5}
+PKA_NEGATIVE = {'D': 3.9, 'E': 4.1, 'C': 8.5, 'Y': 10.1}
+
+# Molar extinction coefficients at 280 nm (Pace et al. 1995)
+EXTINCTION_W = 5500
+EXTINCTION_Y = 1490
+EXTINCTION_CYSTINE = 125
+
+
+@dataclass
+class Protein:
+    """Amino acid sequence (one-letter codes, X for unknown); a trailing stop '*' is dropped"""
+    sequence: str
+    name: str = ""
+
+    def __post_init__(self):
+        self.sequence = ''.join(self.sequence.split()).upper().rstrip('*')
+        invalid = sorted(set(self.sequence) - set(AMINO_ACIDS + 'X'))
+        if invalid:
+            raise ValueError(f'Invalid amino acid code(s): {", ".join(invalid)}')
+
+    def __len__(self) -> int:
+        return len(self.sequence)
+
+    def __str__(self) -> str:
+        return self.sequence
+
+    def composition(self) -> Dict[str, int]:
+        """Residue counts"""
+        return {aa: self.sequence.count(aa) for aa in AMINO_ACIDS + 'X' if aa in self.sequence}
+
+    def molecular_weight(self) -> float:
+        """Average molecular weight (Da) of the unmodified chain"""
+        if not self.sequence:
+            return 0.0
+        return sum(RESIDUE_MASSES[aa] for aa in self.sequence) + WATER_MASS
+
+    def charge(self, ph: float = 7.0) -> float:
+        """Net charge at the given pH (Henderson-Hasselbalch)"""
+        if not self.sequence:
+            return 0.0
+        positive = 1 / (1 + 10 ** (ph - PKA_N_TERMINUS))
+        negative = 1 / (1 + 10 ** (PKA_C_TERMINUS - ph))
+        for aa, pka in PKA_POSITIVE.items():
+            positive += self.sequence.count(aa) / (1 + 10 ** (ph - pka))
+        for aa, pka in PKA_NEGATIVE.items():
+            negative += self.sequence.count(aa) / (1 + 10 ** (pka - ph))
+        return positive - negative
+
+    def isoelectric_point(self) -> float:
+        """pH at which the net charge is zero, found by bisection"""
+        if not self.sequence:
+            raise ValueError('Isoelectric point of an empty protein sequence is undefined')
+        low, high = 0.0, 14.0
+        while high - low > 0.001:
+            middle = (low + high) / 2
+            if self.charge(middle) > 0:
+                low = middle
+            else:
+                high = middle
+        return (low + high) / 2
+
+    def gravy(self) -> float:
+        """Grand average of hydropathy (Kyte-Doolittle); unknown residues are skipped"""
+        values = [HYDROPATHY[aa] for aa in self.sequence if aa in HYDROPATHY]
+        return sum(values) / len(values) if values else 0.0
+
+    def extinction_coefficient(self, cystines: bool = True) -> int:
+        """Molar extinction coefficient at 280 nm (M^-1 cm^-1)
+
+        With cystines, cysteines are assumed to form disulfide bonds in pairs; without,
+        all cysteines are reduced and do not absorb.
+        """
+        coefficient = self.sequence.count('W') * EXTINCTION_W + self.sequence.count('Y') * EXTINCTION_Y
+        if cystines:
+            coefficient += (self.sequence.count('C') // 2) * EXTINCTION_CYSTINE
+        return coefficient
+
+    def properties(self) -> Dict:
+        """Physicochemical summary: length, weight, pI, charge at pH 7, GRAVY, extinction, A280 of 1 mg/ml"""
+        weight = self.molecular_weight()
+        extinction = self.extinction_coefficient()
+        return {
+            'name': self.name,
+            'length': len(self),
+            'molecular_weight': round(weight, 2),
+            'isoelectric_point': round(self.isoelectric_point(), 2),
+            'charge_ph7': round(self.charge(7.0), 2),
+            'gravy': round(self.gravy(), 3),
+            'extinction_coefficient': extinction,
+            'extinction_coefficient_reduced': self.extinction_coefficient(cystines=False),
+            'absorbance_1mg_ml': round(extinction / weight, 3) if weight else 0.0,
+            'composition': self.composition()
+        }
@@ -0,0 +1,119 @@
+import re
+from typing import Dict, Iterable, Optional, Tuple
+from .restriction import RestrictionEnzyme
+
+# REBASE names nicking enzymes Nt.* (top strand) and Nb.* (bottom strand)
+NICK_PREFIXES = {'Nt.': 'top', 'Nb.': 'bottom'}
+
+
+def _nick(name: str) -> str:
+    return next((strand for prefix, strand in NICK_PREFIXES.items() if name.startswith(prefix)), '')
+
+
+def parse_site(text: str) -> Optional[Tuple[str, int, int]]:
+    """Site, top cut and bottom cut from REBASE site notation, or None when not modeled
+
+    G^AATTC cuts at the caret (the bottom strand mirrors it) and GGTCTC(1/5) cuts 1 and 5
+    bases past the site; (5/3)SITE cuts before it. Sites with an unknown cut (?, or no
+    caret or offsets) and enzymes cutting on both sides of the site, such as BaeI, are
+    not modeled.
+    """
+    text = text.strip().upper()
+    match = re.fullmatch(r'(?:\((-?\d+)/(-?\d+)\))?([A-Z^]+)(?:\((-?\d+)/(-?\d+)\))?', text)
+    if not match:
+        return None
+    before_top, before_bottom, marked, after_top, after_bottom = match.groups()
+    site = marked.replace('^', '')
+    if not site or (before_top is not None and after_top is not None):
+        return None
+    if after_top is not None:
+        return site, len(site) + int(after_top), len(site) + int(after_bottom)
+    if before_top is not None:
+        return site, -int(before_top), -int(before_bottom)
+    if marked.count('^') == 1:
+        cut = marked.index('^')
+        return site, cut, len(site) - cut
+    return None
+
+
+def parse_withrefm(text: str, commercial_only: bool = False) -> Dict[str, RestrictionEnzyme]:
+    """Enzymes from REBASE withrefm (emboss) format: <1> name, <3> site with cut, <7> suppliers"""
+    enzymes = {}
+    for chunk in re.split(r'^(?=<1>)', text, flags=re.MULTILINE):
+        fields = dict(re.findall(r'^<(\d)>(.*)$', chunk, flags=re.MULTILINE))
+        name = fields.get('1', '').strip()
+        if not name or (commercial_only and not fields.get('7', '').strip()):
+            continue
+        # Enzymes with several sites list them comma-separated; only the first is modeled
+        parsed = parse_site(fields.get('3', '').split(',')[0])
+        if parsed:
+            site, cut, bottom = parsed
+            enzymes[name] = RestrictionEnzyme(name, site, cut, bottom_cut=bottom, nick=_nick(name))
+    return enzymes
+
+
+def parse_bairoch(text: str, commercial_only: bool = False) -> Dict[str, RestrictionEnzyme]:
+    """Enzymes from REBASE bairoch format: ID name, RS site and cut per strand, CR suppliers
+
+    RS gives the cut after n bases of the site on each strand read 5' to 3', e.g.
+    "GGTCTC, 7; GAGACC, -5;"; a single entry is a palindrome cut the same on both strands
+    and ? marks a strand that is not cut (a nicking enzyme) or an unknown cut.
+    """
+    enzymes = {}
+    for chunk in re.split(r'^//\s*$', text, flags=re.MULTILINE):
+        fields = {}
+        for line in chunk.splitlines():
+            if len(line) > 5 and line[:2].strip():
+                fields[line[:2]] = (fields.get(line[:2], '') + ' ' + line[5:]).strip()
+        name = fields.get('ID', '').strip()
+        if not name or (commercial_only and not fields.get('CR')):
+            continue
+        entries = re.findall(r'([A-Z]+),\s*(-?\d+|\?)', fields.get('RS', '').upper())
+        if not entries:
+            continue
+        site, top = entries[0]
+        bottom = entries[1][1] if len(entries) > 1 else top
+        top_cut = int(top) if top != '?' else None
+        # The bottom strand's cut counts from its own 5' end, the site's last base
+        bottom_cut = len(site) - int(bottom) if bottom != '?' else None
+        if top_cut is None and bottom_cut is None:
+            continue
+        nick = _nick(name) or ('bottom' if top_cut is None else 'top' if bottom_cut is None else '')
+        enzymes[name] = RestrictionEnzyme(name, site, top_cut if top_cut is not None else bottom_cut,
+                                          bottom_cut=bottom_cut, nick=nick)
+    return enzymes
+
+
+def read_rebase(text: str, commercial_only: bool = False) -> Dict[str, RestrictionEnzyme]:
+    """Enzymes from REBASE text in withrefm or bairoch format, detected from its field markers"""
+    if re.search(r'^<1>', text, flags=re.MULTILINE):
+        enzymes = parse_withrefm(text, commercial_only)
+    elif re.search(r'^ID   ', text, flags=re.MULTILINE):
+        enzymes = parse_bairoch(text, commercial_only)
+    else:
+        raise ValueError('Unrecognized REBASE format; use withrefm (emboss) or bairoch')
+    if not enzymes:
+        raise ValueError('No enzymes with known cut sites found in the REBASE file')
+    return enzymes
+
+
+def merge_catalog(catalog: Dict[str, RestrictionEnzyme], enzymes: Iterable[RestrictionEnzyme],
+                  replace: bool = False) -> Dict[str, int]:
+    """Update a catalog in place with imported enzymes, returning counts of added and updated ones
+
+    Methylation sensitivity is not in REBASE's site records, so an existing entry's
+    blocked_by and requires are kept when it is updated. With replace, enzymes missing
+    from the import are dropped.
+    """
+    enzymes = {enzyme.name: enzyme for enzyme in enzymes}
+    added = updated = 0
+    for name, enzyme in enzymes.items():
+        if name in catalog:
+            enzyme.blocked_by, enzyme.requires = catalog[name].blocked_by, catalog[name].requires
+            updated += 1
+        else:
+            added += 1
+    if replace:
+        catalog.clear()
+    catalog.update(enzymes)
+    return {'added': added, 'updated': updated, 'total': len(catalog)}
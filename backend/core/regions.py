@@ -0,0 +1,113 @@
+import json
+import time
+import urllib.error
+import urllib.parse
+import urllib.request
+from typing import Callable, Dict, Tuple
+from .entrez import RETRY_STATUSES, RateLimiter
+from .strand import Strand
+
+UCSC_URL = 'https://api.genome.ucsc.edu'
+ENSEMBL_URLS = {'GRCh37': 'https://grch37.rest.ensembl.org'}
+ENSEMBL_URL = 'https://rest.ensembl.org'
+
+# Assembly name -> (UCSC genome, Ensembl species, Ensembl assembly); either naming is accepted
+ASSEMBLIES: Dict[str, Tuple[str, str, str]] = {
+    'hg38': ('hg38', 'homo_sapiens', 'GRCh38'),
+    'hg19': ('hg19', 'homo_sapiens', 'GRCh37'),
+    'mm39': ('mm39', 'mus_musculus', 'GRCm39'),
+    'mm10': ('mm10', 'mus_musculus', 'GRCm38'),
+    'danRer11': ('danRer11', 'danio_rerio', 'GRCz11'),
+    'dm6': ('dm6', 'drosophila_melanogaster', 'BDGP6'),
+    'ce11': ('ce11', 'caenorhabditis_elegans', 'WBcel235'),
+    'sacCer3': ('sacCer3', 'saccharomyces_cerevisiae', 'R64-1-1'),
+}
+ASSEMBLIES.update({names[2]: names for names in list(ASSEMBLIES.values())})
+
+MAX_REGION_LENGTH = 5_000_000
+
+
+class RegionError(Exception):
+    """A region request failed; status is 404 when the assembly has no such region"""
+
+    def __init__(self, message: str, status: int = 502):
+        super().__init__(message)
+        self.status = status
+
+
+class RegionClient:
+    """Fetches a genomic region's sequence from the UCSC or Ensembl REST API
+
+    Coordinates are 0-based and end-exclusive like the rest of robin; UCSC takes them as is
+    and Ensembl gets 1-based inclusive ones. UCSC soft-masks repeats in lowercase, which the
+    returned Strand keeps. Both services ask for no more than a handful of requests per
+    second, so calls are spaced out and busy responses retried with backoff.
+    """
+
+    def __init__(self, source: str = 'ucsc', retries: int = 3, backoff: float = 1.0, timeout: float = 60,
+                 opener: Callable = urllib.request.urlopen, sleep: Callable[[float], None] = time.sleep):
+        if source not in ('ucsc', 'ensembl'):
+            raise ValueError(f'Unknown region source "{source}"; use ucsc or ensembl')
+        self.source = source
+        self.retries, self.backoff, self.timeout = retries, backoff, timeout
+        self.opener, self.sleep = opener, sleep
+        self.limiter = RateLimiter(1 if source == 'ucsc' else 10, sleep=sleep)
+
+    def _get(self, url: str, headers: Dict[str, str]) -> str:
+        for attempt in range(self.retries + 1):
+            self.limiter.wait()
+            try:
+                with self.opener(urllib.request.Request(url, headers=headers), timeout=self.timeout) as response:
+                    return response.read().decode('utf-8', errors='replace')
+            except urllib.error.HTTPError as e:
+                if e.code in (400, 404):
+                    raise RegionError(f'{self.source} has no such region: {_error_message(e)}', 404) from e
+                if e.code not in RETRY_STATUSES or attempt == self.retries:
+                    raise RegionError(f'{self.source} returned HTTP {e.code}') from e
+            except urllib.error.URLError as e:
+                if attempt == self.retries:
+                    raise RegionError(f'Cannot reach {self.source}: {e.reason}') from e
+            self.sleep(self.backoff * 2 ** attempt)
+
+    def fetch(self, assembly: str, chrom: str, start: int, end: int, strand: int = 1) -> Strand:
+        """Sequence of chrom[start:end] on an assembly (hg38, GRCh38, mm39, ...), reverse complemented for strand -1"""
+        if assembly not in ASSEMBLIES:
+            raise ValueError(f'Unknown assembly "{assembly}"; use one of {", ".join(sorted(ASSEMBLIES))}')
+        if start < 0 or end <= start:
+            raise ValueError('The region needs 0 <= start < end')
+        if end - start > MAX_REGION_LENGTH:
+            raise ValueError(f'Region is {end - start} bp, maximum is {MAX_REGION_LENGTH}')
+        if strand not in (1, -1):
+            raise ValueError('strand must be 1 or -1')
+
+        ucsc_genome, species, ensembl_assembly = ASSEMBLIES[assembly]
+        if self.source == 'ucsc':
+            chrom = chrom if chrom.startswith('chr') else f'chr{chrom}'
+            query = urllib.parse.urlencode({'genome': ucsc_genome, 'chrom': chrom, 'start': start, 'end': end})
+            payload = json.loads(self._get(f'{UCSC_URL}/getData/sequence?{query}', {'Accept': 'application/json'}))
+            sequence = payload.get('dna', '')
+        else:
+            chrom = chrom[3:] if chrom.startswith('chr') else chrom
+            base = ENSEMBL_URLS.get(ensembl_assembly, ENSEMBL_URL)
+            region = urllib.parse.quote(f'{chrom}:{start + 1}..{end}:1')
+            query = urllib.parse.urlencode({'coord_system_version': ensembl_assembly})
+            sequence = self._get(f'{base}/sequence/region/{species}/{region}?{query}', {'Content-Type': 'text/plain'})
+
+        sequence = ''.join(sequence.split())
+        if len(sequence) < end - start:
+            raise RegionError(f'{self.source} returned {len(sequence)} bp for a {end - start} bp region; '
+                              f'the region may run past the end of {chrom}', 404)
+        if len(sequence) > end - start:
+            raise RegionError(f'{self.source} returned {len(sequence)} bp for a {end - start} bp region')
+        fetched = Strand(sequence, name=f'{assembly}:{chrom}:{start + 1}-{end}')
+        return fetched if strand == 1 else fetched.reverse_complement()
+
+
+def _error_message(error: urllib.error.HTTPError) -> str:
+    """The service's own error text when it sent one"""
+    try:
+        body = error.read().decode('utf-8', errors='replace')
+        payload = json.loads(body)
+        return payload.get('error') or body
+    except (ValueError, AttributeError, OSError):
+        return str(error.reason)
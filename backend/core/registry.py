@@ -0,0 +1,162 @@
+import importlib
+import os
+import pkgutil
+import re
+import warnings
+from abc import ABC, abstractmethod
+from dataclasses import dataclass
+from typing import Any, Dict, Iterator, List, Optional
+from .seqio import SequenceRecord
+
+PARAM_TYPES = {'string': str, 'integer': int, 'number': float, 'boolean': bool}
+NAME_PATTERN = re.compile(r'^[a-z][a-z0-9-]*$')
+PLUGIN_PACKAGE = 'plugins'
+ENTRY_POINT_GROUP = 'robin.analyses'
+
+
+@dataclass
+class Param:
+    """One parameter of an analysis, described well enough to build a CLI option, a JSON schema and a form field"""
+    name: str
+    type: str = 'string'  # string, integer, number or boolean
+    default: Any = None
+    description: str = ''
+    required: bool = False
+    choices: Optional[List] = None
+
+    def __post_init__(self):
+        if self.type not in PARAM_TYPES:
+            raise ValueError(f'Parameter "{self.name}" has unknown type "{self.type}"; use {sorted(PARAM_TYPES)}')
+
+    def convert(self, value) -> Any:
+        """value as the parameter's type, raising ValueError when it is not one of the choices"""
+        if self.type == 'boolean' and isinstance(value, str):
+            value = value.strip().lower() in ('1', 'true', 'yes', 'on')
+        else:
+            value = PARAM_TYPES[self.type](value)
+        if self.choices is not None and value not in self.choices:
+            raise ValueError(f'{self.name} must be one of {self.choices}, got "{value}"')
+        return value
+
+    def to_schema(self) -> Dict:
+        schema = {'type': self.type}
+        if self.default is not None:
+            schema['default'] = self.default
+        if self.description:
+            schema['description'] = self.description
+        if self.choices is not None:
+            schema['enum'] = list(self.choices)
+        return schema
+
+
+class Analysis(ABC):
+    """An analysis run on one sequence at a time, with declared parameters
+
+    Subclasses set name (lowercase, dashes allowed), title, description and params, and implement
+    run(). Registered analyses become a cli.py subcommand, POST /api/plugins/<name>, a batch-job
+    and workspace operation, and a panel on the Plugins tab, all built from these attributes.
+    """
+    name: str = ''
+    title: str = ''
+    description: str = ''
+    params: List[Param] = []
+
+    @abstractmethod
+    def run(self, record: SequenceRecord, params: Dict) -> Dict:
+        """Result for one sequence; params are already converted and defaulted"""
+
+    def parse_params(self, raw: Optional[Dict]) -> Dict:
+        """Converted params with defaults filled in; unknown names are ignored"""
+        raw = raw or {}
+        params = {}
+        for param in self.params:
+            value = raw.get(param.name)
+            if value is None or value == '':
+                if param.required:
+                    raise ValueError(f'{self.name} requires parameter "{param.name}"')
+                params[param.name] = param.default
+            else:
+                params[param.name] = param.convert(value)
+        return params
+
+    def __call__(self, record: SequenceRecord, params: Dict) -> Dict:
+        # The job-operation signature, so registered analyses also run as batch jobs and workspace analyses
+        return self.run(record, self.parse_params(params))
+
+    def schema(self) -> Dict:
+        """JSON schema of the params"""
+        return {'type': 'object', 'properties': {param.name: param.to_schema() for param in self.params},
+                'required': [param.name for param in self.params if param.required]}
+
+    def to_dict(self) -> Dict:
+        return {'name': self.name, 'title': self.title or self.name, 'description': self.description,
+                'params': self.schema()}
+
+
+class AnalysisRegistry:
+    """Registered analyses by name, with discovery of plugin modules
+
+    Plugin modules register their analyses on import. discover() imports every module of the
+    plugins package, the modules named in ROBIN_PLUGINS (comma-separated) and those advertised
+    under the robin.analyses entry point group; a module that fails to import is skipped with a
+    warning so one broken plugin does not take the rest down.
+    """
+
+    def __init__(self):
+        self._analyses: Dict[str, Analysis] = {}
+        self._discovered = False
+
+    def register(self, analysis):
+        """Register an Analysis instance or class; returns it, so it works as a class decorator"""
+        instance = analysis() if isinstance(analysis, type) else analysis
+        if not NAME_PATTERN.match(instance.name or ''):
+            raise ValueError(f'Analysis name "{instance.name}" must be lowercase letters, digits and dashes')
+        existing = self._analyses.get(instance.name)
+        if existing is not None and type(existing) is not type(instance):
+            raise ValueError(f'Analysis "{instance.name}" is already registered by {type(existing).__name__}')
+        self._analyses[instance.name] = instance
+        return analysis
+
+    def get(self, name: str) -> Optional[Analysis]:
+        return self._analyses.get(name)
+
+    def __iter__(self) -> Iterator[Analysis]:
+        return iter(sorted(self._analyses.values(), key=lambda analysis: analysis.name))
+
+    def __contains__(self, name: str) -> bool:
+        return name in self._analyses
+
+    def discover(self) -> 'AnalysisRegistry':
+        """Import plugin modules once, registering their analyses"""
+        if self._discovered:
+            return self
+        self._discovered = True
+        modules = []
+        try:
+            package = importlib.import_module(PLUGIN_PACKAGE)
+            modules.extend(f'{PLUGIN_PACKAGE}.{info.name}' for info in pkgutil.iter_modules(package.__path__))
+        except ImportError:
+            pass
+        modules.extend(name.strip() for name in os.environ.get('ROBIN_PLUGINS', '').split(',') if name.strip())
+        for module in modules:
+            try:
+                importlib.import_module(module)
+            except Exception as e:
+                warnings.warn(f'Analysis plugin {module} failed to load: {e}')
+        try:
+            from importlib.metadata import entry_points
+            for entry_point in entry_points(group=ENTRY_POINT_GROUP):
+                try:
+                    # An entry point names an Analysis, or a module that registers its own on import
+                    loaded = entry_point.load()
+                    if isinstance(loaded, Analysis) or (isinstance(loaded, type) and issubclass(loaded, Analysis)):
+                        self.register(loaded)
+                except Exception as e:
+                    warnings.warn(f'Analysis plugin {entry_point.name} failed to load: {e}')
+        except TypeError:
+            pass  # entry_points() without group selection, before Python 3.10
+        return self
+
+
+registry = AnalysisRegistry()
+register = registry.register
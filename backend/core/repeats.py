@@ -0,0 +1,148 @@
+from typing import Dict, List
+from .sequence import clean_sequence, reverse_complement
+
+
+def homopolymers(sequence: str, min_length: int = 6) -> List[Dict]:
+    """Runs of a single base at least min_length long"""
+    sequence = clean_sequence(sequence)
+    runs = []
+    start = 0
+    for i in range(1, len(sequence) + 1):
+        if i == len(sequence) or sequence[i] != sequence[start]:
+            if i - start >= min_length:
+                runs.append({
+                    'type': 'homopolymer',
+                    'start': start,
+                    'end': i,
+                    'length': i - start,
+                    'unit': sequence[start],
+                    'copies': i - start
+                })
+            start = i
+    return runs
+
+
+def tandem_repeats(sequence: str, unit_lengths=(2, 3), min_copies: int = 4) -> List[Dict]:
+    """Perfect tandem repeats of short units (dinucleotide, trinucleotide, ...)
+
+    Units made of a single repeated base are left to homopolymers(). Each repeat is reported
+    once, at its leftmost phase.
+    """
+    sequence = clean_sequence(sequence)
+    repeats = []
+    for size in unit_lengths:
+        i = 0
+        while i + size * min_copies <= len(sequence):
+            unit = sequence[i:i + size]
+            if len(set(unit)) == 1:
+                i += 1
+                continue
+            end = i + size
+            while sequence[end:end + size] == unit:
+                end += size
+            copies = (end - i) // size
+            if copies >= min_copies:
+                repeats.append({
+                    'type': 'tandem',
+                    'start': i,
+                    'end': end,
+                    'length': end - i,
+                    'unit': unit,
+                    'copies': copies
+                })
+                i = end
+            else:
+                i += 1
+    return sorted(repeats, key=lambda r: (r['start'], r['end']))
+
+
+def inverted_repeats(sequence: str, min_stem: int = 6, min_loop: int = 3, max_loop: int = 20) -> List[Dict]:
+    """Inverted repeats (stem, loop, reverse-complement stem) that could fold into hairpins
+
+    Stems are extended as far as they pair; where repeats overlap, the one with the longest
+    stem (then shortest loop) is kept.
+    """
+    sequence = clean_sequence(sequence)
+    found = []
+    for start in range(len(sequence) - 2 * min_stem - min_loop + 1):
+        stem = sequence[start:start + min_stem]
+        target = reverse_complement(stem)
+        for loop in range(min_loop, max_loop + 1):
+            right = start + min_stem + loop
+            if right + min_stem > len(sequence):
+                break
+            if sequence[right:right + min_stem] != target:
+                continue
+
+            # Grow the stem outward while the flanking bases still pair
+            left_start, right_end = start, right + min_stem
+            while (left_start > 0 and right_end < len(sequence)
+                   and reverse_complement(sequence[left_start - 1]) == sequence[right_end]):
+                left_start -= 1
+                right_end += 1
+
+            stem_length = min_stem + (start - left_start)
+            found.append({
+                'type': 'inverted',
+                'start': left_start,
+                'end': right_end,
+                'length': right_end - left_start,
+                'stem_length': stem_length,
+                'loop_length': loop,
+                'stem': sequence[left_start:left_start + stem_length]
+            })
+
+    unique = []
+    for repeat in sorted(found, key=lambda r: (-r['stem_length'], r['loop_length'], r['start'])):
+        if not any(other['start'] <= repeat['start'] < other['end'] or repeat['start'] <= other['start'] < repeat['end']
+                   for other in unique):
+            unique.append(repeat)
+    return sorted(unique, key=lambda r: r['start'])
+
+
+def find_repeats(sequence: str, min_homopolymer: int = 6, min_copies: int = 4,
+                 min_stem: int = 6, max_loop: int = 20) -> List[Dict]:
+    """Homopolymers, tandem repeats and inverted repeats ordered by position"""
+    regions = (homopolymers(sequence, min_homopolymer)
+               + tandem_repeats(sequence, min_copies=min_copies)
+               + inverted_repeats(sequence, min_stem=min_stem, max_loop=max_loop))
+    return sorted(regions, key=lambda r: (r['start'], r['end']))
+
+
+def find_palindromes(sequence: str, min_length: int = 4, max_length: int = 12,
+                     max_mismatches: int = 0) -> List[Dict]:
+    """Reverse-complement palindromes such as GAATTC, equal to their own reverse complement
+
+    Each center between two bases is extended outward, allowing up to max_mismatches unpaired
+    positions, and the longest palindrome of min_length..max_length there is reported. Such
+    sites are candidate restriction sites and, when long, potential cruciform structures.
+    """
+    sequence = clean_sequence(sequence)
+    palindromes = []
+    for center in range(1, len(sequence)):
+        best = 0
+        mismatches = 0
+        half = 1
+        while center - half >= 0 and center + half <= len(sequence) and 2 * half <= max_length:
+            left, right = sequence[center - half], sequence[center + half - 1]
+            if reverse_complement(left) != right:
+                mismatches += 1
+                if mismatches > max_mismatches:
+                    break
+            else:
+                # Only end on a paired position so mismatches never sit at the edges
+                best = half
+            half += 1
+
+        if 2 * best >= min_length:
+            start, end = center - best, center + best
+            region = sequence[start:end]
+            palindromes.append({
+                'type': 'palindrome',
+                'start': start,
+                'end': end,
+                'length': end - start,
+                'sequence': region,
+                'mismatches': sum(reverse_complement(region[i]) != region[-1 - i] for i in range(best))
+            })
+    return palindromes
@@ -28,9 +28,10 @@ class OrthogonalRepository:
         }
 
     def get_orthogonal_sequence(self, length: int, gc_target: float = 50.0,
-                                exclude_sequences: List[str] = None) -> str:
-        """Get an orthogonal sequence of specified length"""
+                                exclude_sequences: List[str] = None, rng: random.Random = None) -> str:
+        """Get an orthogonal sequence of specified length; pass a seeded rng for reproducible picks"""
         exclude_sequences = exclude_sequences or []
+        rng = rng or random
 
         # Find sequences of exact length
         candidates = self.sequences_by_length.get(length, [])
@@ -44,12 +45,12 @@ class OrthogonalRepository:
                     suitable_candidates.append(seq)
 
         if suitable_candidates:
-            return random.choice(suitable_candidates)
+            return rng.choice(suitable_candidates)
         else:
             # Generate new sequence if no suitable candidates
-            return self._generate_sequence(length, gc_target)
+            return self._generate_sequence(length, gc_target, rng)
 
-    def _generate_sequence(self, length: int, gc_target: float) -> str:
+    def _generate_sequence(self, length: int, gc_target: float, rng=random) -> str:
         """Generate a new sequence with target GC content"""
         gc_bases = ['G', 'C']
         at_bases = ['A', 'T']
@@ -61,10 +62,10 @@ class OrthogonalRepository:
 
         # Ensure exact length
         while len(bases) < length:
-            bases.append(random.choice(['A', 'T']))
+            bases.append(rng.choice(['A', 'T']))
         bases = bases[:length]
 
-        random.shuffle(bases)
+        rng.shuffle(bases)
         return ''.join(bases)
 
     def _calculate_gc_content(self, sequence: str) -> float:
@@ -0,0 +1,316 @@
+import re
+from dataclasses import dataclass
+from typing import Dict, Iterable, List, Optional, Set, Tuple
+from .sequence import clean_sequence, reverse_complement
+
+
+# Regex character classes for IUPAC codes in recognition sites
+IUPAC_PATTERNS = {
+    'A': 'A', 'C': 'C', 'G': 'G', 'T': 'T',
+    'R': '[AG]', 'Y': '[CT]', 'S': '[CG]', 'W': '[AT]', 'K': '[GT]', 'M': '[AC]',
+    'B': '[CGT]', 'D': '[AGT]', 'H': '[ACT]', 'V': '[ACG]', 'N': '[ACGT]'
+}
+
+
+@dataclass
+class RestrictionEnzyme:
+    """Restriction enzyme with recognition site and cut offsets
+
+    Offsets are relative to the start of the site on the top strand and may lie outside it,
+    as for Type IIS enzymes (BsaI GGTCTC(1/5) cuts at 7 and 11). bottom_cut defaults to the
+    mirror of cut, which is right for palindromic sites. Nicking enzymes cut only the strand
+    named by nick ('top' or 'bottom'); the other offset is ignored.
+
+    blocked_by names the methylation models (see METHYLATION) that stop the enzyme cutting
+    when a methylated base falls inside its site; requires those it needs to cut at all,
+    as DpnI needs Dam methylation.
+    """
+    name: str
+    site: str
+    cut: int  # Cut position on the top strand, relative to the start of the site
+    bottom_cut: Optional[int] = None  # Cut position on the bottom strand, in the same coordinates
+    nick: str = ''
+    blocked_by: Tuple[str, ...] = ()
+    requires: Tuple[str, ...] = ()
+
+    def __post_init__(self):
+        if self.nick not in ('', 'top', 'bottom'):
+            raise ValueError(f'{self.name}: nick must be "top" or "bottom", not "{self.nick}"')
+
+    @property
+    def bottom(self) -> int:
+        return len(self.site) - self.cut if self.bottom_cut is None else self.bottom_cut
+
+    @property
+    def overhang_length(self) -> int:
+        """Length of the single-stranded end left by a cut: positive for 5', negative for 3', 0 when blunt"""
+        return self.bottom - self.cut
+
+    @property
+    def type_iis(self) -> bool:
+        """Whether the enzyme cuts outside its recognition site"""
+        offsets = [offset for offset, strand in ((self.cut, 'top'), (self.bottom, 'bottom')) if self.nick in ('', strand)]
+        return not all(0 <= offset <= len(self.site) for offset in offsets)
+
+    def to_dict(self) -> Dict:
+        return {
+            'name': self.name,
+            'site': self.site,
+            'cut': self.cut if self.nick != 'bottom' else None,
+            'bottom_cut': self.bottom if self.nick != 'top' else None,
+            'nick': self.nick or None,
+            'type_iis': self.type_iis,
+            'overhang_length': self.overhang_length if not self.nick else None,
+            'blocked_by': list(self.blocked_by),
+            'requires': list(self.requires)
+        }
+
+
+@dataclass
+class Cut:
+    """One site's strand breaks in top-strand coordinates; None for a strand a nicking enzyme leaves intact
+
+    strand is 1 when the site reads on the top strand and -1 when on the bottom. overhang
+    is the top-strand bases between the two breaks.
+    """
+    enzyme: str
+    site: int
+    strand: int
+    top: Optional[int]
+    bottom: Optional[int]
+    overhang: str = ""
+    overhang_type: str = 'blunt'  # "5'", "3'", 'blunt' or 'nick'
+
+    def to_dict(self) -> Dict:
+        return {'enzyme': self.enzyme, 'site': self.site, 'strand': self.strand, 'top': self.top,
+                'bottom': self.bottom, 'overhang': self.overhang, 'overhang_type': self.overhang_type}
+
+
+@dataclass
+class MethylationModel:
+    """Methyltransferase that modifies one base of a palindromic site on both strands"""
+    name: str
+    site: str
+    position: int  # Methylated base on the top strand, relative to the start of the site
+    description: str = ""
+
+
+METHYLATION = {
+    model.name: model for model in [
+        MethylationModel('dam', 'GATC', 1, 'E. coli Dam: N6-methyladenine in GATC'),
+        MethylationModel('dcm', 'CCWGG', 1, 'E. coli Dcm: 5-methylcytosine at the second C of CCWGG'),
+        MethylationModel('cpg', 'CG', 0, 'CpG (M.SssI, mammalian DNA): 5-methylcytosine in CG'),
+    ]
+}
+
+# Default E. coli cloning strains (DH5α, TOP10, ...) are dam+ dcm+
+DEFAULT_METHYLATION = ('dam', 'dcm')
+
+
+# Commonly used enzymes for cloning
+ENZYMES = {
+    enzyme.name: enzyme for enzyme in [
+        RestrictionEnzyme('EcoRI', 'GAATTC', 1),
+        RestrictionEnzyme('BamHI', 'GGATCC', 1),
+        RestrictionEnzyme('HindIII', 'AAGCTT', 1),
+        RestrictionEnzyme('XhoI', 'CTCGAG', 1, blocked_by=('cpg',)),
+        RestrictionEnzyme('NdeI', 'CATATG', 2),
+        RestrictionEnzyme('NcoI', 'CCATGG', 1),
+        RestrictionEnzyme('XbaI', 'TCTAGA', 1, blocked_by=('dam',)),
+        RestrictionEnzyme('SpeI', 'ACTAGT', 1),
+        RestrictionEnzyme('PstI', 'CTGCAG', 5),
+        RestrictionEnzyme('SalI', 'GTCGAC', 1, blocked_by=('cpg',)),
+        RestrictionEnzyme('KpnI', 'GGTACC', 5),
+        RestrictionEnzyme('SacI', 'GAGCTC', 5),
+        RestrictionEnzyme('NotI', 'GCGGCCGC', 2, blocked_by=('cpg',)),
+        RestrictionEnzyme('EcoRV', 'GATATC', 3),
+        RestrictionEnzyme('SmaI', 'CCCGGG', 3, blocked_by=('cpg',)),
+        RestrictionEnzyme('NheI', 'GCTAGC', 1, blocked_by=('cpg',)),
+        RestrictionEnzyme('BglII', 'AGATCT', 1),
+        RestrictionEnzyme('DpnI', 'GATC', 2, requires=('dam',)),
+        # Type IIS, cutting outside the site (Golden Gate)
+        RestrictionEnzyme('BsaI', 'GGTCTC', 7, bottom_cut=11),
+        RestrictionEnzyme('BsmBI', 'CGTCTC', 7, bottom_cut=11),
+        RestrictionEnzyme('Esp3I', 'CGTCTC', 7, bottom_cut=11),
+        RestrictionEnzyme('BbsI', 'GAAGAC', 8, bottom_cut=12),
+        RestrictionEnzyme('SapI', 'GCTCTTC', 8, bottom_cut=11),
+        # Nicking enzymes, cutting one strand
+        RestrictionEnzyme('Nt.BbvCI', 'CCTCAGC', 2, bottom_cut=5, nick='top'),
+        RestrictionEnzyme('Nb.BbvCI', 'CCTCAGC', 2, bottom_cut=5, nick='bottom'),
+        RestrictionEnzyme('Nt.BspQI', 'GCTCTTC', 8, bottom_cut=11, nick='top'),
+        RestrictionEnzyme('Nt.BstNBI', 'GAGTC', 9, nick='top'),
+    ]
+}
+
+
+def site_pattern(site: str) -> str:
+    """Convert an IUPAC recognition site to a regular expression"""
+    return ''.join(IUPAC_PATTERNS.get(base, base) for base in site.upper())
+
+
+def _methylation_names(methylation: Iterable[str]) -> Set[str]:
+    names = {name.lower() for name in methylation}
+    unknown = sorted(names - set(METHYLATION))
+    if unknown:
+        raise ValueError(f'Unknown methylation "{unknown[0]}"; use {", ".join(METHYLATION)}')
+    return names
+
+
+def methylated_positions(sequence: str, methylation: Iterable[str] = DEFAULT_METHYLATION,
+                         circular: bool = False) -> Dict[str, Set[int]]:
+    """0-based positions of methylated bases on either strand, per methylation model"""
+    sequence = clean_sequence(sequence)
+    length = len(sequence)
+    positions = {}
+    for name in sorted(_methylation_names(methylation)):
+        model = METHYLATION[name]
+        searched = sequence + sequence[:len(model.site) - 1] if circular and length else sequence
+        found = set()
+        for match in re.finditer(f'(?={site_pattern(model.site)})', searched):
+            if match.start() >= length:
+                break
+            # The bottom strand's copy of the palindromic site is methylated at the mirrored base
+            found.add((match.start() + model.position) % length)
+            found.add((match.start() + len(model.site) - 1 - model.position) % length)
+        positions[name] = found
+    return positions
+
+
+def _site_unblocked(enzyme: RestrictionEnzyme, start: int, methylated: Dict[str, Set[int]], length: int) -> bool:
+    """Whether the site starting at start is cut given the methylated positions"""
+    covered = {(start + offset) % length for offset in range(len(enzyme.site))}
+    for name in enzyme.requires:
+        if not covered & methylated.get(name, set()):
+            return False
+    return not any(covered & methylated.get(name, set()) for name in enzyme.blocked_by)
+
+
+def find_cuts(sequence: str, enzyme: RestrictionEnzyme, circular: bool = False,
+              methylation: Optional[Iterable[str]] = None) -> List[Cut]:
+    """Every site of an enzyme on either strand with where it breaks each strand
+
+    On a circular sequence sites spanning the origin are found too and positions wrap; a
+    break at position 0 lies between the last and first base. On a linear sequence sites
+    whose breaks would fall at or past an end (Type IIS sites near the ends) are left out.
+
+    methylation lists the models active on the DNA, e.g. ('dam', 'dcm') for plasmids from a
+    standard E. coli strain or () for PCR products. Sites an active model blocks are skipped,
+    as are sites of enzymes such as DpnI when the methylation they need is absent. With None
+    methylation is not modeled and every site is cut.
+    """
+    sequence = clean_sequence(sequence)
+    length = len(sequence)
+    if not length:
+        return []
+    methylated = methylated_positions(sequence, methylation, circular) if methylation is not None else None
+    site_length = len(enzyme.site)
+    forward = site_pattern(enzyme.site)
+    # Non-palindromic sites can also be recognised on the bottom strand
+    patterns = {forward: 1}
+    rc_site = reverse_complement(enzyme.site)
+    if rc_site != enzyme.site.upper():
+        patterns[site_pattern(rc_site)] = -1
+
+    searched = sequence + sequence[:site_length - 1] if circular else sequence
+    cuts = []
+    for pattern, strand in patterns.items():
+        for match in re.finditer(f'(?={pattern})', searched):
+            start = match.start()
+            if start >= length:
+                break
+            if methylated is not None and not _site_unblocked(enzyme, start, methylated, length):
+                continue
+            if strand == 1:
+                top = start + enzyme.cut if enzyme.nick != 'bottom' else None
+                bottom = start + enzyme.bottom if enzyme.nick != 'top' else None
+            else:
+                # The enzyme's top strand is our bottom strand, read right to left
+                top = start + site_length - enzyme.bottom if enzyme.nick != 'top' else None
+                bottom = start + site_length - enzyme.cut if enzyme.nick != 'bottom' else None
+            breaks = [position for position in (top, bottom) if position is not None]
+            if not circular and not all(0 < position < length for position in breaks):
+                continue
+
+            overhang, overhang_type = '', 'nick'
+            if top is not None and bottom is not None:
+                left, right = sorted((top, bottom))
+                overhang = ''.join(sequence[i % length] for i in range(left, right))
+                overhang_type = 'blunt' if top == bottom else ("5'" if top < bottom else "3'")
+            if circular:
+                top = top % length if top is not None else None
+                bottom = bottom % length if bottom is not None else None
+            cuts.append(Cut(enzyme.name, start, strand, top, bottom, overhang, overhang_type))
+
+    return sorted(cuts, key=lambda cut: (cut.top if cut.top is not None else cut.bottom, cut.strand))
+
+
+def find_sites(sequence: str, enzyme: RestrictionEnzyme, circular: bool = False,
+               methylation: Optional[Iterable[str]] = None) -> List[int]:
+    """Find top-strand cut positions of an enzyme in a sequence (nicks for top-strand nickases)
+
+    See find_cuts for circular sequences and methylation.
+    """
+    return sorted({cut.top for cut in find_cuts(sequence, enzyme, circular, methylation) if cut.top is not None})
+
+
+def find_nicks(sequence: str, enzyme: RestrictionEnzyme, circular: bool = False,
+               methylation: Optional[Iterable[str]] = None) -> List[Dict]:
+    """Nicks made by a nicking enzyme: position and the strand ('+' top, '-' bottom) broken"""
+    if not enzyme.nick:
+        raise ValueError(f'{enzyme.name} is not a nicking enzyme')
+    nicks = []
+    for cut in find_cuts(sequence, enzyme, circular, methylation):
+        nicks.append({'position': cut.top, 'strand': '+'} if cut.top is not None
+                     else {'position': cut.bottom, 'strand': '-'})
+    return sorted(nicks, key=lambda nick: (nick['position'], nick['strand']))
+
+
+def digest(sequence: str, enzyme_names: List[str],
+           catalog: Optional[Dict[str, RestrictionEnzyme]] = None, circular: bool = False,
+           methylation: Optional[Iterable[str]] = None) -> List[Dict]:
+    """Digest a linear or circular sequence with one or more enzymes and return the fragments
+
+    On a circular sequence the fragment across the origin runs from the last cut to the
+    first and has end < start, as PCR products around the origin do. A single cut
+    linearizes the molecule (start == end, full length); with no cut the uncut circle is
+    returned as one fragment. methylation names the models active on the DNA (see find_cuts).
+
+    Fragments are given on the top strand between top-strand cuts; left_overhang and
+    right_overhang are the single-stranded bases at each end's cut (see Cut), '' when blunt.
+    """
+    catalog = catalog or ENZYMES
+    sequence = clean_sequence(sequence)
+
+    cuts, overhangs = {}, {}
+    for name in enzyme_names:
+        if name not in catalog:
+            raise ValueError(f'Unknown enzyme "{name}"')
+        if catalog[name].nick:
+            raise ValueError(f'{name} is a nicking enzyme and does not fragment DNA')
+        for cut in find_cuts(sequence, catalog[name], circular, methylation):
+            if name not in cuts.setdefault(cut.top, []):
+                cuts[cut.top].append(name)
+            overhangs.setdefault(cut.top, cut.overhang)
+
+    if circular and cuts:
+        positions = sorted(cuts)
+        boundaries = list(zip(positions, positions[1:] + positions[:1]))
+    else:
+        positions = [0] + sorted(cuts) + [len(sequence)]
+        boundaries = list(zip(positions, positions[1:]))
+
+    fragments = []
+    for start, end in boundaries:
+        fragment = sequence[start:end] if start < end else sequence[start:] + sequence[:end]
+        fragments.append({
+            'start': start,
+            'end': end,
+            'length': len(fragment),
+            'left_enzymes': cuts.get(start, []),
+            'right_enzymes': cuts.get(end, []),
+            'left_overhang': overhangs.get(start, ''),
+            'right_overhang': overhangs.get(end, ''),
+            'sequence': fragment
+        })
+
+    return fragments
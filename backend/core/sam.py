@@ -0,0 +1,88 @@
+from typing import Dict, List, Optional
+from .mapper import Hit, fr_insert_size
+from .seqio import FastqRecord, SequenceRecord
+from .sequence import reverse_complement
+
+# SAM FLAG bits
+PAIRED = 0x1
+PROPER_PAIR = 0x2
+UNMAPPED = 0x4
+MATE_UNMAPPED = 0x8
+REVERSE = 0x10
+MATE_REVERSE = 0x20
+FIRST_IN_PAIR = 0x40
+SECOND_IN_PAIR = 0x80
+
+
+def sam_header(references: List[SequenceRecord], program: str = 'robin') -> str:
+    lines = ['@HD\tVN:1.6\tSO:unsorted']
+    lines += [f'@SQ\tSN:{r.name.split()[0]}\tLN:{len(r.sequence)}' for r in references]
+    lines.append(f'@PG\tID:{program}\tPN:{program}')
+    return '\n'.join(lines) + '\n'
+
+
+def _quality(record) -> str:
+    return getattr(record, 'quality', None) or '*'
+
+
+def sam_record(record, hit: Optional[Hit], flag: int = 0, mate: Optional[Hit] = None, tlen: int = 0) -> str:
+    """One SAM line; reverse-strand hits store the reverse-complemented read and reversed qualities"""
+    name = record.name.split()[0]
+    if name.endswith(('/1', '/2')):
+        name = name[:-2]
+    sequence, quality = record.sequence, _quality(record)
+
+    if hit is None:
+        flag |= UNMAPPED
+        rname, pos, mapq, cigar = (mate.reference.split()[0], mate.position + 1, 0, '*') if mate else ('*', 0, 0, '*')
+        tags = ''
+    else:
+        if hit.strand == '-':
+            flag |= REVERSE
+            sequence = reverse_complement(sequence)
+            quality = quality[::-1] if quality != '*' else quality
+        rname, pos, mapq, cigar = hit.reference.split()[0], hit.position + 1, hit.mapq, hit.cigar
+        tags = f'\tAS:i:{hit.score}\tNM:i:{hit.edit_distance}'
+        if hit.md:
+            tags += f'\tMD:Z:{hit.md}'
+
+    if mate is not None:
+        rnext = '=' if hit is None or mate.reference == hit.reference else mate.reference.split()[0]
+        pnext = mate.position + 1
+    elif hit is not None and flag & PAIRED:
+        rnext, pnext = '=', pos  # unmapped mate is placed with this read
+    else:
+        rnext, pnext = '*', 0
+
+    return '\t'.join([name, str(flag), rname, str(pos), str(mapq), cigar, rnext, str(pnext), str(tlen),
+                      sequence or '*', quality]) + tags
+
+
+def sam_pair(r1: FastqRecord, r2: FastqRecord, hit1: Optional[Hit], hit2: Optional[Hit], proper: bool) -> List[str]:
+    """Both mates' SAM lines with pair flags, mate fields and signed TLEN"""
+    lines = []
+    size = fr_insert_size(hit1, hit2) or 0
+    for record, hit, mate, order in ((r1, hit1, hit2, FIRST_IN_PAIR), (r2, hit2, hit1, SECOND_IN_PAIR)):
+        flag = PAIRED | order
+        if proper:
+            flag |= PROPER_PAIR
+        if mate is None:
+            flag |= MATE_UNMAPPED
+        elif mate.strand == '-':
+            flag |= MATE_REVERSE
+
+        tlen = 0
+        if size and hit is not None and mate is not None:
+            tlen = size if (hit.position, hit.strand) <= (mate.position, mate.strand) else -size
+        lines.append(sam_record(record, hit, flag, mate, tlen))
+    return lines
+
+
+def mapping_summary(hits: List[Optional[Hit]]) -> Dict:
+    mapped = [hit for hit in hits if hit is not None]
+    return {
+        'reads': len(hits),
+        'mapped': len(mapped),
+        'mapped_fraction': round(len(mapped) / len(hits), 4) if hits else 0.0,
+        'mean_mapq': round(sum(hit.mapq for hit in mapped) / len(mapped), 2) if mapped else 0.0
+    }
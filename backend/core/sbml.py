@@ -0,0 +1,235 @@
+import re
+import xml.etree.ElementTree as ElementTree
+from typing import Dict, List, Tuple
+from xml.sax.saxutils import quoteattr
+from .crn import CRN, CRNReaction, parse_crn
+
+SBML_NAMESPACE = 'http://www.sbml.org/sbml/level3/version2/core'
+MATHML_NAMESPACE = 'http://www.w3.org/1998/Math/MathML'
+COMPARTMENT = 'solution'
+_SID = re.compile(r'[A-Za-z_][A-Za-z0-9_]*')
+
+
+def _ids(names: List[str], taken: set) -> Dict[str, str]:
+    """SBML identifiers for names, which may hold characters such as ^ and * that SIds cannot"""
+    ids = {}
+    for name in names:
+        base = re.sub(r'[^A-Za-z0-9_]', '_', name.replace('*', '_star').replace('^', '_t'))
+        base = base if _SID.fullmatch(base) else f'_{base}'
+        candidate, number = base, 2
+        while candidate in taken:
+            candidate, number = f'{base}_{number}', number + 1
+        taken.add(candidate)
+        ids[name] = candidate
+    return ids
+
+
+def to_sbml(crn: CRN, amounts: Dict[str, float] = None, model: str = 'crn', default_rate: float = 1.0) -> str:
+    """SBML Level 3 Version 2 core model of a CRN with mass-action kinetic laws
+
+    Species live in one compartment of unit volume and start at the given concentrations, or
+    the CRN's own amounts; each reaction's rate constant is a global parameter k1, k2, ... so
+    tools such as COPASI can fit or scan them. Species whose names are not valid SBML ids keep
+    their names in the name attribute.
+    """
+    amounts = {**crn.amounts, **(amounts or {})}
+    unknown = [name for name in amounts if name not in crn.species]
+    if unknown:
+        raise ValueError(f'Unknown species: {", ".join(unknown)}')
+    parameters = [f'k{number}' for number in range(1, len(crn.reactions) + 1)]
+    ids = _ids(crn.species, {COMPARTMENT, *parameters})
+
+    def reference(name: str, count: int) -> str:
+        return f'<speciesReference species="{ids[name]}" stoichiometry="{count}" constant="true"/>'
+
+    lines = [
+        '<?xml version="1.0" encoding="UTF-8"?>',
+        f'<sbml xmlns="{SBML_NAMESPACE}" level="3" version="2">',
+        f'  <model id={quoteattr(_ids([model], set())[model])} name={quoteattr(model)} substanceUnits="mole" '
+        f'timeUnits="second" volumeUnits="litre" extentUnits="mole">',
+        '    <listOfCompartments>',
+        f'      <compartment id="{COMPARTMENT}" spatialDimensions="3" size="1" constant="true"/>',
+        '    </listOfCompartments>',
+        '    <listOfSpecies>'
+    ]
+    for name in crn.species:
+        label = f' name={quoteattr(name)}' if ids[name] != name else ''
+        lines.append(f'      <species id="{ids[name]}"{label} compartment="{COMPARTMENT}" '
+                     f'initialConcentration="{amounts.get(name, 0.0)!r}" hasOnlySubstanceUnits="false" '
+                     f'boundaryCondition="false" constant="false"/>')
+    lines += ['    </listOfSpecies>', '    <listOfParameters>']
+    for parameter, reaction in zip(parameters, crn.reactions):
+        rate = default_rate if reaction.rate is None else reaction.rate
+        lines.append(f'      <parameter id="{parameter}" value="{rate!r}" constant="true"/>')
+    lines += ['    </listOfParameters>', '    <listOfReactions>']
+    for number, (parameter, reaction) in enumerate(zip(parameters, crn.reactions), start=1):
+        label = f' name={quoteattr(reaction.kind)}' if reaction.kind else ''
+        lines.append(f'      <reaction id="r{number}"{label} reversible="false">')
+        for tag, side in (('listOfReactants', reaction.reactants), ('listOfProducts', reaction.products)):
+            if side:
+                lines.append(f'        <{tag}>')
+                lines += [f'          {reference(name, side.count(name))}' for name in dict.fromkeys(side)]
+                lines.append(f'        </{tag}>')
+        # Concentration-based mass action, scaled by the volume to give extent per time
+        factors = [COMPARTMENT, parameter] + [ids[name] for name in reaction.reactants]
+        lines += [
+            '        <kineticLaw>',
+            f'          <math xmlns="{MATHML_NAMESPACE}">',
+            '            <apply>',
+            '              <times/>',
+            *[f'              <ci> {factor} </ci>' for factor in factors],
+            '            </apply>',
+            '          </math>',
+            '        </kineticLaw>',
+            '      </reaction>'
+        ]
+    lines += ['    </listOfReactions>', '  </model>', '</sbml>']
+    return '\n'.join(lines) + '\n'
+
+
+def _local(tag: str) -> str:
+    return tag.rsplit('}', 1)[-1]
+
+
+def _children(element, name: str) -> List:
+    return [child for child in element if _local(child.tag) == name]
+
+
+def _find(element, *path: str):
+    for name in path:
+        matches = _children(element, name) if element is not None else []
+        element = matches[0] if matches else None
+    return element
+
+
+def _mass_action(math, values: Dict[str, float], species: Dict[str, str], compartments: set,
+                 reaction_id: str) -> Tuple[float, List[str]]:
+    """(rate constant, species in the product) of a kinetic law that multiplies constants and species"""
+    rate, names = 1.0, []
+
+    def walk(node):
+        nonlocal rate
+        tag = _local(node.tag)
+        if tag == 'ci':
+            name = (node.text or '').strip()
+            if name in species:
+                names.append(species[name])
+            elif name in values:
+                rate *= values[name]
+            elif name not in compartments:
+                raise ValueError(f'Reaction {reaction_id}: unknown identifier "{name}" in its kinetic law')
+        elif tag == 'cn':
+            rate *= float((node.text or '').strip())
+        elif tag == 'apply':
+            operator, *arguments = list(node)
+            operation = _local(operator.tag)
+            if operation == 'times':
+                for argument in arguments:
+                    walk(argument)
+            elif operation == 'power' and len(arguments) == 2 and _local(arguments[1].tag) == 'cn':
+                exponent = float((arguments[1].text or '').strip())
+                if exponent != int(exponent) or exponent < 0:
+                    raise ValueError(f'Reaction {reaction_id}: only whole-number powers are mass action')
+                for _ in range(int(exponent)):
+                    walk(arguments[0])
+            else:
+                raise ValueError(f'Reaction {reaction_id}: only mass-action kinetic laws can be read')
+        else:
+            raise ValueError(f'Reaction {reaction_id}: only mass-action kinetic laws can be read')
+
+    walk(math)
+    return rate, names
+
+
+def parse_sbml(text: str) -> CRN:
+    """CRN from an SBML model whose kinetic laws are mass action, with its initial concentrations
+
+    Reversible reactions need a law of the form forward - reverse and become two reactions;
+    species and parameters keep their names, or ids when they have none. Boundary species
+    and compartment sizes are read as constants of the rate laws.
+    """
+    try:
+        root = ElementTree.fromstring(text.strip())
+    except ElementTree.ParseError as e:
+        raise ValueError(f'Not valid SBML: {e}')
+    if _local(root.tag) != 'sbml':
+        raise ValueError('Not an SBML document')
+    model = _find(root, 'model')
+    if model is None:
+        raise ValueError('The SBML document has no model')
+
+    compartments = {element.get('id'): float(element.get('size', 1))
+                    for element in _children(_find(model, 'listOfCompartments') or [], 'compartment')}
+    values: Dict[str, float] = {}
+    for element in _children(_find(model, 'listOfParameters') or [], 'parameter'):
+        if element.get('value') is not None:
+            values[element.get('id')] = float(element.get('value'))
+
+    species: Dict[str, str] = {}
+    amounts: Dict[str, float] = {}
+    names_seen = set()
+    for element in _children(_find(model, 'listOfSpecies') or [], 'species'):
+        identifier = element.get('id')
+        name = element.get('name') or identifier
+        # Names are only used when they are unique and usable as CRN species names
+        if name in names_seen or not re.fullmatch(r'[A-Za-z_][A-Za-z0-9_^*\-]*', name):
+            name = identifier
+        names_seen.add(name)
+        if element.get('initialConcentration') is not None:
+            amount = float(element.get('initialConcentration'))
+        elif element.get('initialAmount') is not None:
+            amount = float(element.get('initialAmount')) / compartments.get(element.get('compartment'), 1.0)
+        else:
+            amount = 0.0
+        if element.get('boundaryCondition') == 'true' or element.get('constant') == 'true':
+            values[identifier] = amount
+            continue
+        species[identifier] = name
+        if amount:
+            amounts[name] = amount
+
+    reactions: List[CRNReaction] = []
+    for element in _children(_find(model, 'listOfReactions') or [], 'reaction'):
+        reaction_id = element.get('id', '?')
+        sides = []
+        for tag in ('listOfReactants', 'listOfProducts'):
+            side = []
+            for ref in _children(_find(element, tag) or [], 'speciesReference'):
+                if ref.get('species') not in species:
+                    continue  # boundary species are constants, not reactants
+                count = float(ref.get('stoichiometry', 1))
+                if count != int(count):
+                    raise ValueError(f'Reaction {reaction_id}: stoichiometries must be whole numbers')
+                side += [species[ref.get('species')]] * int(count)
+            sides.append(tuple(side))
+        reactants, products = sides
+        law = _find(element, 'kineticLaw')
+        math = _find(law, 'math')
+        if math is None or not list(math):
+            reactions.append(CRNReaction(reactants, products, None, element.get('name') or ''))
+            continue
+        local = dict(values)
+        for container in ('listOfLocalParameters', 'listOfParameters'):
+            for parameter in _children(_find(law, container) or [], 'localParameter') + \
+                    _children(_find(law, container) or [], 'parameter'):
+                local[parameter.get('id')] = float(parameter.get('value', 1))
+        expression = list(math)[0]
+        terms = [expression]
+        if _local(expression.tag) == 'apply' and _local(expression[0].tag) == 'minus' and len(expression) == 3:
+            terms = [expression[1], expression[2]]
+        elif element.get('reversible') == 'true':
+            raise ValueError(f'Reaction {reaction_id}: reversible laws must read forward - reverse')
+
+        laws = [_mass_action(term, local, species, set(compartments), reaction_id) for term in terms]
+        for (rate, names), (sources, targets) in zip(laws, [(reactants, products), (products, reactants)]):
+            if sorted(names) != sorted(sources):
+                raise ValueError(f'Reaction {reaction_id}: the kinetic law is not mass action in its reactants')
+            reactions.append(CRNReaction(sources, targets, rate, element.get('name') or ''))
+    if not reactions:
+        raise ValueError('The SBML model has no reactions')
+    return CRN(reactions, amounts=amounts)
+
+
+def read_crn(text: str) -> CRN:
+    """CRN from an SBML document, or from 'A + B -> C [rate]' lines"""
+    return parse_sbml(text) if text.lstrip().startswith('<') else parse_crn(text)
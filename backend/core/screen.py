@@ -0,0 +1,90 @@
+from collections import defaultdict
+from typing import Dict, Iterable, List, Set
+from .seqio import SequenceRecord
+from .sequence import clean_sequence, reverse_complement
+
+# Adapter, primer and vector sequences that commonly contaminate sequencing reads
+CONTAMINANTS = {
+    'Illumina TruSeq Read 1 adapter': 'AGATCGGAAGAGCACACGTCTGAACTCCAGTCA',
+    'Illumina TruSeq Read 2 adapter': 'AGATCGGAAGAGCGTCGTGTAGGGAAAGAGTGT',
+    'Illumina P5 adapter': 'AATGATACGGCGACCACCGAGATCTACAC',
+    'Illumina P7 adapter': 'CAAGCAGAAGACGGCATACGAGAT',
+    'Illumina small RNA 3\' adapter': 'TGGAATTCTCGGGTGCCAAGG',
+    'Nextera transposase adapter': 'CTGTCTCTTATACACATCT',
+    'M13 forward primer site': 'GTAAAACGACGGCCAGT',
+    'M13 reverse primer site': 'CAGGAAACAGCTATGAC',
+    'T7 promoter': 'TAATACGACTCACTATAGGG',
+    'SP6 promoter': 'ATTTAGGTGACACTATAG',
+}
+
+
+class ContaminantScreen:
+    """K-mer index of contaminant sequences for screening reads
+
+    Every k-mer of each contaminant is indexed on both strands, so screening a read is one
+    dictionary lookup per read k-mer. A read counts as contaminated by a sequence once it
+    shares at least min_hits k-mers with it. The default k is short enough for the
+    shortest bundled adapter; longer k trades sensitivity for fewer chance hits.
+    """
+
+    def __init__(self, contaminants: Dict[str, str] = None, k: int = 12, min_hits: int = 1):
+        contaminants = CONTAMINANTS if contaminants is None else contaminants
+        if not contaminants:
+            raise ValueError('At least one contaminant sequence is required')
+        if k < 1 or min_hits < 1:
+            raise ValueError('k and min_hits must be positive')
+        self.k, self.min_hits = k, min_hits
+        self.names = list(contaminants)
+        self.index: Dict[str, Set[str]] = defaultdict(set)
+        for name, sequence in contaminants.items():
+            sequence = clean_sequence(sequence)
+            if len(sequence) < k:
+                raise ValueError(f'Contaminant "{name}" is shorter than k={k}')
+            for strand in (sequence, reverse_complement(sequence)):
+                for i in range(len(strand) - k + 1):
+                    self.index[strand[i:i + k]].add(name)
+
+    def hits(self, sequence: str) -> Dict[str, int]:
+        """Shared k-mer count per contaminant for one read, keeping only those at min_hits or above"""
+        sequence = clean_sequence(sequence)
+        counts: Dict[str, int] = defaultdict(int)
+        for i in range(len(sequence) - self.k + 1):
+            for name in self.index.get(sequence[i:i + self.k], ()):
+                counts[name] += 1
+        return {name: count for name, count in counts.items() if count >= self.min_hits}
+
+    def screen(self, records: Iterable[SequenceRecord]) -> Dict:
+        """Percentage of reads contaminated overall and by each contaminant"""
+        total, contaminated, per_contaminant = 0, 0, {name: 0 for name in self.names}
+        for record in records:
+            total += 1
+            found = self.hits(record.sequence)
+            if found:
+                contaminated += 1
+            for name in found:
+                per_contaminant[name] += 1
+
+        def percent(count: int) -> float:
+            return round(100 * count / total, 2) if total else 0.0
+
+        return {
+            'total': total,
+            'contaminated': contaminated,
+            'contaminated_percent': percent(contaminated),
+            'contaminants': sorted(({'name': name, 'reads': count, 'percent': percent(count)}
+                                    for name, count in per_contaminant.items() if count),
+                                   key=lambda item: -item['reads'])
+        }
+
+
+def contaminant_set(extra: Dict[str, str] = None, include_bundled: bool = True) -> Dict[str, str]:
+    """Bundled contaminants extended (or replaced) by user sequences; user names override bundled ones"""
+    contaminants = dict(CONTAMINANTS) if include_bundled else {}
+    contaminants.update(extra or {})
+    return contaminants
+
+
+def screen_reads(records: List[SequenceRecord], extra: Dict[str, str] = None, include_bundled: bool = True,
+                 k: int = 12, min_hits: int = 1) -> Dict:
+    """Screen reads or strands against the bundled contaminants plus any user sequences"""
+    return ContaminantScreen(contaminant_set(extra, include_bundled), k, min_hits).screen(records)
@@ -0,0 +1,298 @@
+import random
+import re
+from dataclasses import dataclass, field
+from typing import Callable, Dict, List, Optional, Set, Tuple
+from .annealing import anneal
+from .context import check_cancelled
+from .fold import Ensemble, cofold, partition_function
+from .sequence import COMPLEMENTS, IUPAC_BASES, clean_sequence
+from .structure import parse_dot_bracket, strand_breaks
+
+PAIR_CHOICES = ('GC', 'CG', 'AT', 'TA')
+# Strong pairs and bases that cannot pair with each other make a start close to most targets
+INITIAL_PAIRS = ('GC', 'CG')
+INITIAL_UNPAIRED = 'AC'
+
+OBJECTIVES = ('defect', 'ban', 'gc', 'orthogonal')
+DEFAULT_WEIGHTS = {'defect': 1.0, 'ban': 1.0, 'gc': 1.0, 'orthogonal': 0.1}
+# Cross-hybridization of an orthogonality set counts from this ΔG37 (kcal/mol) down
+DEFAULT_ORTHOGONAL_DG = -6.0
+
+
+@dataclass
+class DesignSpec:
+    """Constraints and objective weights for a structure design, usually parsed from text"""
+    target: str = ''
+    template: str = ''
+    banned: List[str] = field(default_factory=list)
+    gc_ranges: List[Tuple[float, float, int]] = field(default_factory=list)  # (min %, max %, window; 0 = whole)
+    forced: List[Tuple[int, str]] = field(default_factory=list)  # (0-based start, bases)
+    orthogonal: List[Tuple[List[Tuple[int, int]], float]] = field(default_factory=list)  # (ranges, ΔG floor)
+    weights: Dict[str, float] = field(default_factory=lambda: dict(DEFAULT_WEIGHTS))
+
+
+def _range(text: str, line: int) -> Tuple[int, int]:
+    """1-based inclusive 'start-end' as a 0-based end-exclusive span"""
+    match = re.fullmatch(r'(\d+)-(\d+)', text)
+    if not match or int(match.group(1)) < 1 or int(match.group(2)) < int(match.group(1)):
+        raise ValueError(f'Line {line}: expected a range like 1-20, got "{text}"')
+    return int(match.group(1)) - 1, int(match.group(2))
+
+
+def parse_design_spec(text: str) -> DesignSpec:
+    """Read the design constraint language, one statement per line (# starts a comment)
+
+        target ((((....))))&((..))    target structure
+        template NNNNGANNNN           fixed bases, N where free
+        ban GGGG                      IUPAC motif that must not occur
+        gc 40 60 [window 20]          GC % range, of every window or the whole design
+        force 5 ACGT                  bases fixed from a 1-based position
+        orthogonal 1-10 21-30 [dg -6] ranges that must not cross-hybridize below the ΔG37
+        weight gc 2                   weight of an objective (defect, ban, gc, orthogonal)
+    """
+    spec = DesignSpec()
+    for number, raw in enumerate(text.splitlines(), start=1):
+        words = raw.split('#', 1)[0].split()
+        if not words:
+            continue
+        keyword, args = words[0].lower(), words[1:]
+        try:
+            if keyword in ('target', 'template') and len(args) == 1:
+                setattr(spec, keyword, args[0])
+            elif keyword == 'ban' and len(args) == 1:
+                motif = clean_sequence(args[0])
+                if set(motif) - set(IUPAC_BASES):
+                    raise ValueError(f'Line {number}: "{args[0]}" is not an IUPAC motif')
+                spec.banned.append(motif)
+            elif keyword == 'gc' and len(args) in (2, 4) and (len(args) == 2 or args[2] == 'window'):
+                low, high = float(args[0]), float(args[1])
+                if not 0 <= low <= high <= 100:
+                    raise ValueError(f'Line {number}: GC range must satisfy 0 <= min <= max <= 100')
+                spec.gc_ranges.append((low, high, int(args[3]) if len(args) == 4 else 0))
+            elif keyword == 'force' and len(args) == 2:
+                if int(args[0]) < 1 or set(clean_sequence(args[1])) - set('ACGT'):
+                    raise ValueError(f'Line {number}: force takes a 1-based position and A/C/G/T bases')
+                spec.forced.append((int(args[0]) - 1, clean_sequence(args[1])))
+            elif keyword == 'orthogonal' and args:
+                floor = DEFAULT_ORTHOGONAL_DG
+                if len(args) >= 2 and args[-2] == 'dg':
+                    floor, args = float(args[-1]), args[:-2]
+                if len(args) < 2:
+                    raise ValueError(f'Line {number}: orthogonal needs at least two ranges')
+                spec.orthogonal.append(([_range(arg, number) for arg in args], floor))
+            elif keyword == 'weight' and len(args) == 2:
+                if args[0] not in OBJECTIVES:
+                    raise ValueError(f'Line {number}: unknown objective "{args[0]}"; use {", ".join(OBJECTIVES)}')
+                if float(args[1]) < 0:
+                    raise ValueError(f'Line {number}: weights cannot be negative')
+                spec.weights[args[0]] = float(args[1])
+            else:
+                raise ValueError(f'Line {number}: cannot read "{raw.strip()}"')
+        except ValueError as e:
+            if str(e).startswith('Line '):
+                raise
+            raise ValueError(f'Line {number}: {e}') from e
+    return spec
+
+
+@dataclass
+class StructureDesign:
+    """Sequence designed to fold into a target dot-bracket structure"""
+    target: str
+    sequence: str
+    defect: float
+    base_defects: List[float]
+    iterations: int
+    history: List[float] = field(default_factory=list)
+    objectives: Dict[str, float] = field(default_factory=dict)
+    objective: float = 0.0
+
+    @property
+    def normalized_defect(self) -> float:
+        """Ensemble defect as a fraction of the bases"""
+        return self.defect / len(self.base_defects) if self.base_defects else 0.0
+
+    def to_dict(self) -> Dict:
+        return {
+            'target': self.target,
+            'sequence': self.sequence,
+            'defect': round(self.defect, 3),
+            'normalized_defect': round(self.normalized_defect, 4),
+            'base_defects': [round(value, 3) for value in self.base_defects],
+            'objective': round(self.objective, 4),
+            'objectives': {name: round(value, 4) for name, value in self.objectives.items()},
+            'iterations': self.iterations,
+            'history': [round(value, 4) for value in self.history]
+        }
+
+
+@dataclass
+class Score:
+    """Weighted objective of one candidate, with the bases each violated constraint touches"""
+    total: float
+    objectives: Dict[str, float]
+    base_defects: List[float]
+    hotspots: Set[int]
+
+
+def fold_target(sequence: str, target: str, temperature: float = 37.0) -> Ensemble:
+    """Ensemble of sequence split into strands where target has a strand break (at most two)"""
+    breaks = strand_breaks(target)
+    if len(breaks) > 1:
+        raise ValueError('Targets of at most two strands are supported')
+    if breaks:
+        return cofold(sequence[:breaks[0]], sequence[breaks[0]:], temperature)
+    return partition_function(sequence, temperature)
+
+
+class DefectDesigner:
+    """Ensemble defect minimization in the style of NUPACK's designer, plus spec constraints
+
+    Each step mutates one base (or one target pair, keeping it complementary) chosen with
+    probability proportional to its defect, or from a region breaking a constraint, and keeps
+    the mutation if the weighted objective falls. Template bases other than N stay fixed.
+    """
+
+    def __init__(self, target: str, template: str = '', temperature: float = 37.0, seed: Optional[int] = None,
+                 spec: DesignSpec = None):
+        self.spec = spec or DesignSpec()
+        self.target = ''.join((target or self.spec.target).split())
+        pairs = parse_dot_bracket(self.target)
+        if any(bracket != '(' for _, _, bracket in pairs):
+            raise ValueError('Pseudoknotted targets are not supported')
+        self.breaks = strand_breaks(self.target)
+        self.length = len(self.target) - len(self.breaks)
+        if not self.length:
+            raise ValueError('A target structure is required')
+        template = list(clean_sequence(template or self.spec.template).replace('U', 'T') or 'N' * self.length)
+        if len(template) != self.length:
+            raise ValueError(f'Template has {len(template)} bases but the target has {self.length}')
+        for start, bases in self.spec.forced:
+            if start + len(bases) > self.length:
+                raise ValueError(f'Forced bases at {start + 1} run past the end of the design')
+            for offset, base in enumerate(bases):
+                if template[start + offset] not in ('N', base):
+                    raise ValueError(f'Forced {base} at {start + offset + 1} conflicts with the template')
+                template[start + offset] = base
+        template = ''.join(template)
+        if set(template) - set('ACGTN'):
+            raise ValueError('Templates may only contain A, C, G, T and N')
+        for ranges, _ in self.spec.orthogonal:
+            if any(end > self.length for _, end in ranges):
+                raise ValueError('An orthogonality range runs past the end of the design')
+        self.template = template
+        self.temperature = temperature
+        self.random = random.Random(seed)
+        self.partner: Dict[int, int] = {}
+        for i, j, _ in pairs:
+            self.partner[i], self.partner[j] = j, i
+            if 'N' not in (template[i], template[j]) and template[i] + template[j] not in PAIR_CHOICES:
+                raise ValueError(f'Fixed bases {i + 1} and {j + 1} must pair in the target but cannot')
+        # A base is free when neither it nor its target partner is fixed
+        self.free = [i for i in range(self.length)
+                     if template[i] == 'N' and template[self.partner.get(i, i)] == 'N']
+        self.banned = [re.compile('(?=(' + ''.join(f'[{IUPAC_BASES[code]}]' for code in motif) + '))')
+                       for motif in self.spec.banned]
+
+    def initial_sequence(self) -> str:
+        sequence = list(self.template)
+        for i in range(self.length):
+            if sequence[i] != 'N':
+                continue
+            j = self.partner.get(i)
+            if j is None:
+                sequence[i] = self.random.choice(INITIAL_UNPAIRED)
+            elif sequence[j] != 'N':
+                sequence[i] = COMPLEMENTS[sequence[j]]
+            else:
+                sequence[i], sequence[j] = self.random.choice(INITIAL_PAIRS)
+        return ''.join(sequence)
+
+    def _strands(self, sequence: str) -> List[Tuple[int, str]]:
+        edges = [0] + self.breaks + [self.length]
+        return [(start, sequence[start:end]) for start, end in zip(edges, edges[1:])]
+
+    def evaluate(self, sequence: str) -> Score:
+        weights = self.spec.weights
+        defects = fold_target(sequence, self.target, self.temperature).defect(self.target)
+        objectives = {'defect': sum(defects) / self.length}
+        hotspots: Set[int] = set()
+
+        if self.banned:
+            hits = 0
+            for start, strand in self._strands(sequence):
+                for pattern, motif in zip(self.banned, self.spec.banned):
+                    for match in pattern.finditer(strand):
+                        hits += 1
+                        hotspots.update(range(start + match.start(), start + match.start() + len(motif)))
+            objectives['ban'] = hits
+
+        if self.spec.gc_ranges:
+            deviation, windows = 0.0, 0
+            for low, high, window in self.spec.gc_ranges:
+                for start, strand in self._strands(sequence):
+                    size = min(window or len(strand), len(strand))
+                    for offset in range(len(strand) - size + 1):
+                        piece = strand[offset:offset + size]
+                        gc = 100 * sum(1 for base in piece if base in 'GC') / size
+                        windows += 1
+                        if not low <= gc <= high:
+                            deviation += (low - gc if gc < low else gc - high) / 100
+                            hotspots.update(range(start + offset, start + offset + size))
+            objectives['gc'] = deviation / windows if windows else 0.0
+
+        if self.spec.orthogonal:
+            excess = 0.0
+            for ranges, floor in self.spec.orthogonal:
+                for index, (a_start, a_end) in enumerate(ranges):
+                    for b_start, b_end in ranges[index + 1:]:
+                        duplexes = anneal(sequence[a_start:a_end], sequence[b_start:b_end], min_run=2, top=1)
+                        if duplexes and duplexes[0].dg < floor:
+                            excess += floor - duplexes[0].dg
+                            hotspots.update(range(a_start, a_end))
+                            hotspots.update(range(b_start, b_end))
+            objectives['orthogonal'] = excess
+
+        total = sum(weights.get(name, 0.0) * value for name, value in objectives.items())
+        return Score(total, objectives, defects, hotspots)
+
+    def mutate(self, sequence: str, score: Score) -> str:
+        spread = self.spec.weights.get('defect', 0.0)
+        # Constraint hotspots weigh like a fully defective base
+        weights = [spread * score.base_defects[i] + (1.0 if i in score.hotspots else 0.0) for i in self.free]
+        i = self.random.choices(self.free, weights=weights if sum(weights) else None)[0]
+        mutated = list(sequence)
+        j = self.partner.get(i)
+        if j is None:
+            mutated[i] = self.random.choice([base for base in 'ACGT' if base != sequence[i]])
+        else:
+            current = sequence[i] + sequence[j]
+            mutated[i], mutated[j] = self.random.choice([pair for pair in PAIR_CHOICES if pair != current])
+        return ''.join(mutated)
+
+    def satisfied(self, score: Score, stop: float) -> bool:
+        """Defect at most stop and every other constraint met"""
+        return score.objectives['defect'] <= stop and not any(
+            value for name, value in score.objectives.items() if name != 'defect')
+
+    def run(self, stop: float = 0.02, max_iterations: int = 500, patience: int = 100,
+            progress: Callable[[int, Score], None] = None) -> StructureDesign:
+        """Mutate until the design is satisfied (see satisfied), or patience steps in a row fail to improve it"""
+        sequence = self.initial_sequence()
+        score = self.evaluate(sequence)
+        history = [score.total]
+        iterations = failures = 0
+        while self.free and not self.satisfied(score, stop) and iterations < max_iterations and failures < patience:
+            check_cancelled()
+            iterations += 1
+            candidate = self.mutate(sequence, score)
+            candidate_score = self.evaluate(candidate)
+            if candidate_score.total < score.total:
+                sequence, score, failures = candidate, candidate_score, 0
+            else:
+                failures += 1
+            history.append(score.total)
+            if progress:
+                progress(iterations, score)
+        return StructureDesign(self.target, sequence, sum(score.base_defects), score.base_defects, iterations,
+                               history, score.objectives, score.total)
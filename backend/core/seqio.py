@@ -0,0 +1,108 @@
+from dataclasses import dataclass
+from typing import Iterator, List, TextIO, Tuple
+
+
+@dataclass
+class SequenceRecord:
+    """Named sequence read from a FASTA file or raw input"""
+    name: str
+    sequence: str
+
+
+def read_sequences(handle: TextIO) -> Iterator[SequenceRecord]:
+    """Read FASTA records, or one raw sequence per line if the input has no headers
+
+    Whitespace is dropped but case is kept, so soft-masked (lowercase) regions survive a round trip.
+    """
+    name, chunks = None, []
+    raw_count = 0
+
+    for line in handle:
+        line = line.strip()
+        if not line:
+            continue
+        if line.startswith('>'):
+            if name is not None:
+                yield SequenceRecord(name, ''.join(''.join(chunks).split()))
+            name, chunks = line[1:].strip() or 'unnamed', []
+        elif name is None:
+            # Raw input: every line is its own sequence
+            raw_count += 1
+            yield SequenceRecord(f'seq{raw_count}', ''.join(line.split()))
+        else:
+            chunks.append(line)
+
+    if name is not None:
+        yield SequenceRecord(name, ''.join(''.join(chunks).split()))
+
+
+def format_fasta(records: List[SequenceRecord], width: int = 60) -> str:
+    """Format records as FASTA text"""
+    lines = []
+    for record in records:
+        lines.append(f'>{record.name}')
+        for offset in range(0, len(record.sequence), width):
+            lines.append(record.sequence[offset:offset + width])
+    return '\n'.join(lines) + ('\n' if lines else '')
+
+
+@dataclass
+class FastqRecord:
+    """Read from a FASTQ file; quality is the Phred+33 encoded string"""
+    name: str
+    sequence: str
+    quality: str
+
+    @property
+    def qualities(self) -> List[int]:
+        """Per-base Phred scores"""
+        return [ord(char) - 33 for char in self.quality]
+
+
+def read_fastq(handle: TextIO) -> Iterator[FastqRecord]:
+    """Read four-line FASTQ records"""
+    while True:
+        header = handle.readline()
+        if not header:
+            return
+        if not header.strip():
+            continue
+        if not header.startswith('@'):
+            raise ValueError(f'Expected a FASTQ header starting with "@", got "{header.strip()[:40]}"')
+        sequence = handle.readline().strip()
+        separator = handle.readline()
+        quality = handle.readline().strip()
+        if not separator.startswith('+') or len(quality) != len(sequence):
+            raise ValueError(f'Malformed FASTQ record "{header[1:].strip()}"')
+        yield FastqRecord(header[1:].strip(), clean_sequence(sequence), quality)
+
+
+def format_fastq(records: List[FastqRecord]) -> str:
+    """Format records as FASTQ text"""
+    return ''.join(f'@{r.name}\n{r.sequence}\n+\n{r.quality}\n' for r in records)
+
+
+def _pair_name(name: str) -> str:
+    """Read name without comments or a trailing /1 or /2 mate suffix"""
+    name = name.split()[0] if name else name
+    return name[:-2] if name.endswith(('/1', '/2')) else name
+
+
+def read_pairs(handle1: TextIO, handle2: TextIO = None) -> Iterator[Tuple[FastqRecord, FastqRecord]]:
+    """Synchronized R1/R2 iteration over two FASTQ files, or one interleaved file when handle2 is None
+
+    Mates must appear in the same order; a name mismatch or a file running out early raises
+    ValueError rather than silently mis-pairing reads.
+    """
+    first = read_fastq(handle1)
+    second = read_fastq(handle2) if handle2 is not None else first
+    while True:
+        r1 = next(first, None)
+        r2 = next(second, None)
+        if r1 is None and r2 is None:
+            return
+        if r1 is None or r2 is None:
+            raise ValueError('Paired FASTQ input has an unequal number of reads')
+        if _pair_name(r1.name) != _pair_name(r2.name):
+            raise ValueError(f'Mate names do not match: "{r1.name}" and "{r2.name}"')
+        yield r1, r2
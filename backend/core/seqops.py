@@ -0,0 +1,104 @@
+"""
+Bulk byte-level sequence operations
+
+Whole-buffer implementations of reverse complement, GC counting and base counting over
+bytes (e.g. the sequence lines of a large FASTA file). The pure-Python backend leans on
+bytes.translate and bytes.count, which run as tight C loops; when numpy is installed a
+vectorised backend is used instead. ROBIN_SEQOPS=python forces the fallback and
+ROBIN_SEQOPS=numpy requires numpy.
+"""
+
+import os
+from typing import Dict
+from .sequence import COMPLEMENT_BYTES, COMPLEMENTS, IUPAC_BASES, reverse_complement_bytes
+
+try:
+    import numpy
+except ImportError:
+    numpy = None
+
+_requested = os.environ.get('ROBIN_SEQOPS', '').lower()
+if _requested == 'numpy' and numpy is None:
+    raise ImportError('ROBIN_SEQOPS=numpy but numpy is not installed')
+BACKEND = 'numpy' if numpy is not None and _requested != 'python' else 'python'
+BACKENDS = ('python', 'numpy') if numpy is not None else ('python',)
+
+# Codes standing only for G or C (G, C and S), and every nucleotide code, gap and line break; either case
+_GC_CODES = ''.join(code for code, bases in IUPAC_BASES.items() if set(bases) <= set('GC'))
+_GC_BYTES = (_GC_CODES + _GC_CODES.lower()).encode('ascii')
+_COMMON_BYTES = ''.join(dict.fromkeys(''.join(COMPLEMENTS) + ''.join(COMPLEMENTS).lower() + '\n')).encode('ascii')
+
+
+# Pure-Python backend
+
+_python_reverse_complement = reverse_complement_bytes
+
+
+def _python_gc_count(data: bytes) -> int:
+    return sum(data.count(base) for base in _GC_BYTES)
+
+
+def _python_base_counts(data: bytes) -> Dict[str, int]:
+    counts = {chr(code): data.count(code) for code in _COMMON_BYTES}
+    # Only bytes outside the common alphabet need a per-byte pass
+    for code in set(data.translate(None, _COMMON_BYTES)):
+        counts[chr(code)] = data.count(code)
+    return {base: count for base, count in counts.items() if count}
+
+
+# numpy backend
+
+if numpy is not None:
+    _COMPLEMENT_ARRAY = numpy.frombuffer(COMPLEMENT_BYTES, dtype=numpy.uint8)
+    _GC_MASK = numpy.zeros(256, dtype=bool)
+    _GC_MASK[list(_GC_BYTES)] = True
+
+
+def _numpy_reverse_complement(data: bytes) -> bytes:
+    return _COMPLEMENT_ARRAY[numpy.frombuffer(data, dtype=numpy.uint8)[::-1]].tobytes()
+
+
+def _numpy_gc_count(data: bytes) -> int:
+    return int(_GC_MASK[numpy.frombuffer(data, dtype=numpy.uint8)].sum())
+
+
+def _numpy_base_counts(data: bytes) -> Dict[str, int]:
+    histogram = numpy.bincount(numpy.frombuffer(data, dtype=numpy.uint8), minlength=256)
+    return {chr(code): int(count) for code, count in enumerate(histogram) if count}
+
+
+_IMPLEMENTATIONS = {
+    'python': (_python_reverse_complement, _python_gc_count, _python_base_counts),
+    'numpy': (_numpy_reverse_complement, _numpy_gc_count, _numpy_base_counts),
+}
+
+
+def _implementation(backend: str):
+    backend = backend or BACKEND
+    if backend not in BACKENDS:
+        raise ValueError(f'Backend "{backend}" is not available; use one of {", ".join(BACKENDS)}')
+    return _IMPLEMENTATIONS[backend]
+
+
+def reverse_complement(data: bytes, backend: str = None) -> bytes:
+    """Reverse complement of a byte buffer; case is kept and unknown bytes become N"""
+    return _implementation(backend)[0](data)
+
+
+def gc_count(data: bytes, backend: str = None) -> int:
+    """Number of G, C and S bytes, either case"""
+    return _implementation(backend)[1](data)
+
+
+def base_counts(data: bytes, backend: str = None) -> Dict[str, int]:
+    """Occurrences of every byte value present, keyed by character"""
+    return _implementation(backend)[2](data)
+
+
+def cross_check(data: bytes) -> Dict[str, bool]:
+    """Whether every available backend agrees with the pure-Python one on data, per operation"""
+    results = {}
+    for name, index in (('reverse_complement', 0), ('gc_count', 1), ('base_counts', 2)):
+        expected = _IMPLEMENTATIONS['python'][index](data)
+        results[name] = all(_IMPLEMENTATIONS[backend][index](data) == expected for backend in BACKENDS)
+    return results
@@ -0,0 +1,220 @@
+from itertools import islice, product
+from typing import Dict, Iterator, List
+from .windows import codons
+
+
+# IUPAC nucleotide complements, including ambiguity codes
+COMPLEMENTS = {
+    'A': 'T', 'T': 'A', 'G': 'C', 'C': 'G', 'U': 'A',
+    'R': 'Y', 'Y': 'R', 'S': 'S', 'W': 'W', 'K': 'M', 'M': 'K',
+    'B': 'V', 'V': 'B', 'D': 'H', 'H': 'D', 'N': 'N',
+    '-': '-', '.': '.'
+}
+# Alignment gap characters; they complement to themselves
+GAP_CHARS = '-.'
+
+
+def _unknown_complement(code: int) -> int:
+    """Anything that is not a nucleotide code complements to N (n if lowercase)"""
+    return ord('n') if chr(code).islower() else ord('N')
+
+
+class _ComplementTable(dict):
+    """str.translate table that also covers characters beyond the 256-entry range"""
+
+    def __missing__(self, key: int) -> int:
+        return _unknown_complement(key)
+
+
+def _complement_pairs():
+    for base, partner in COMPLEMENTS.items():
+        yield base, partner
+        yield base.lower(), partner.lower()
+
+
+# 256-entry lookup tables for str.translate / bytes.translate, keeping soft-masked (lowercase) bases lowercase;
+# line breaks are kept so buffers of FASTA sequence lines can be complemented directly
+COMPLEMENT_TABLE = _ComplementTable({code: _unknown_complement(code) for code in range(256)})
+COMPLEMENT_TABLE.update({ord(base): ord(partner) for base, partner in _complement_pairs()})
+COMPLEMENT_TABLE.update({ord('\n'): ord('\n'), ord('\r'): ord('\r')})
+COMPLEMENT_BYTES = bytes(COMPLEMENT_TABLE[code] for code in range(256))
+
+# Bases each IUPAC code may stand for (U is read as T)
+IUPAC_BASES = {
+    'A': 'A', 'C': 'C', 'G': 'G', 'T': 'T', 'U': 'T',
+    'R': 'AG', 'Y': 'CT', 'S': 'CG', 'W': 'AT', 'K': 'GT', 'M': 'AC',
+    'B': 'CGT', 'D': 'AGT', 'H': 'ACT', 'V': 'ACG', 'N': 'ACGT'
+}
+# Most specific IUPAC code for a set of bases
+IUPAC_CODES = {frozenset(bases): code for code, bases in IUPAC_BASES.items() if code != 'U'}
+
+# Standard genetic code (NCBI translation table 1), codons ordered TCAG
+_CODON_BASES = 'TCAG'
+_CODON_AMINO_ACIDS = 'FFLLSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG'
+CODON_TABLE = {
+    a + b + c: _CODON_AMINO_ACIDS[16 * i + 4 * j + k]
+    for i, a in enumerate(_CODON_BASES)
+    for j, b in enumerate(_CODON_BASES)
+    for k, c in enumerate(_CODON_BASES)
+}
+
+START_CODONS = ['ATG']
+STOP_CODONS = [codon for codon, aa in CODON_TABLE.items() if aa == '*']
+
+
+def clean_sequence(sequence: str) -> str:
+    """Uppercase a sequence and strip whitespace"""
+    return ''.join(sequence.split()).upper()
+
+
+def complement(sequence: str) -> str:
+    """Complement of a DNA sequence (IUPAC aware); whitespace is dropped and soft-masked bases stay lowercase"""
+    return ''.join(sequence.split()).translate(COMPLEMENT_TABLE)
+
+
+def complement_bytes(data: bytes) -> bytes:
+    """Complement of raw sequence bytes in one table lookup pass, case and line breaks kept"""
+    return data.translate(COMPLEMENT_BYTES)
+
+
+def reverse_complement_bytes(data: bytes) -> bytes:
+    """Reverse complement of raw sequence bytes, case kept"""
+    return data.translate(COMPLEMENT_BYTES)[::-1]
+
+
+def reverse_complement(sequence: str) -> str:
+    """Reverse complement of a DNA sequence (IUPAC aware), case kept"""
+    return complement(sequence)[::-1]
+
+
+def gc_content(sequence: str) -> float:
+    """Calculate GC content percentage"""
+    sequence = clean_sequence(sequence)
+    if not sequence:
+        return 0.0
+    gc_count = sequence.count('G') + sequence.count('C') + sequence.count('S')
+    return (gc_count / len(sequence)) * 100
+
+
+def translate(sequence: str, frame: int = 0, to_stop: bool = False) -> str:
+    """Translate a DNA sequence into a protein sequence starting at the given frame"""
+    sequence = clean_sequence(sequence).replace('U', 'T')
+    protein = []
+    for _, codon in codons(sequence, frame):
+        amino_acid = CODON_TABLE.get(codon, 'X')
+        if to_stop and amino_acid == '*':
+            break
+        protein.append(amino_acid)
+    return ''.join(protein)
+
+
+def six_frame_translate(sequence: str) -> List[Dict]:
+    """Translations of all six reading frames, labelled +1..+3 and -1..-3
+
+    Stop positions are 0-based forward-strand starts of the stop codons. 'aligned' is as
+    long as the sequence and places each amino acid under the middle base of its codon
+    (spaces elsewhere), so frames can be printed directly under the nucleotides.
+    """
+    sequence = clean_sequence(sequence).replace('U', 'T')
+    length = len(sequence)
+    frames = []
+    for strand, seq in (('+', sequence), ('-', reverse_complement(sequence))):
+        for offset in range(3):
+            protein = translate(seq, frame=offset)
+            aligned = [' '] * length
+            stops = []
+            for i, amino_acid in enumerate(protein):
+                codon_start = offset + 3 * i
+                fwd_start = codon_start if strand == '+' else length - codon_start - 3
+                aligned[fwd_start + 1] = amino_acid
+                if amino_acid == '*':
+                    stops.append(fwd_start)
+            frames.append({
+                'frame': f'{strand}{offset + 1}',
+                'strand': strand,
+                'offset': offset,
+                'protein': protein,
+                'stops': sorted(stops),
+                'aligned': ''.join(aligned)
+            })
+    return frames
+
+
+def back_translate(protein: str) -> str:
+    """Degenerate DNA encoding a protein, one IUPAC code per codon position
+
+    Each position is the union over the amino acid's codons, so amino acids with codons
+    from two families (L, R, S and stops) get a code that also covers a few other codons,
+    e.g. S (TCN, AGY) becomes WSN. X becomes NNN.
+    """
+    codons = {}
+    for codon, amino_acid in CODON_TABLE.items():
+        codons.setdefault(amino_acid, []).append(codon)
+    codons['X'] = ['NNN']
+
+    dna = []
+    for amino_acid in ''.join(protein.split()).upper():
+        if amino_acid not in codons:
+            raise ValueError(f'Invalid amino acid code "{amino_acid}"')
+        for position in range(3):
+            bases = frozenset(base for codon in codons[amino_acid] for base in IUPAC_BASES[codon[position]])
+            dna.append(IUPAC_CODES[bases])
+    return ''.join(dna)
+
+
+def degeneracy(sequence: str) -> int:
+    """Number of concrete ACGT sequences an IUPAC sequence stands for"""
+    count = 1
+    for base in clean_sequence(sequence):
+        if base not in IUPAC_BASES:
+            raise ValueError(f'Invalid nucleotide code "{base}"')
+        count *= len(IUPAC_BASES[base])
+    return count
+
+
+def expand_ambiguous(sequence: str, cap: int = 1000) -> Iterator[str]:
+    """Concrete ACGT sequences matched by an IUPAC sequence, at most cap of them"""
+    sequence = clean_sequence(sequence)
+    invalid = sorted(set(sequence) - set(IUPAC_BASES))
+    if invalid:
+        raise ValueError(f'Invalid nucleotide code(s): {", ".join(invalid)}')
+    choices = [IUPAC_BASES[base] for base in sequence]
+    return (''.join(bases) for bases in islice(product(*choices), cap))
+
+
+def find_orfs(sequence: str, min_length: int = 30, both_strands: bool = True) -> List[Dict]:
+    """Find open reading frames (ATG to stop) of at least min_length nucleotides
+
+    Coordinates are 0-based, end-exclusive and always refer to the forward strand.
+    """
+    sequence = clean_sequence(sequence).replace('U', 'T')
+    length = len(sequence)
+    strands = [('+', sequence)]
+    if both_strands:
+        strands.append(('-', reverse_complement(sequence)))
+
+    orfs = []
+    for strand, seq in strands:
+        for frame in range(3):
+            start = None
+            for i, codon in codons(seq, frame):
+                if start is None and codon in START_CODONS:
+                    start = i
+                elif start is not None and codon in STOP_CODONS:
+                    end = i + 3
+                    if end - start >= min_length:
+                        if strand == '+':
+                            fwd_start, fwd_end = start, end
+                        else:
+                            fwd_start, fwd_end = length - end, length - start
+                        orfs.append({
+                            'start': fwd_start,
+                            'end': fwd_end,
+                            'strand': strand,
+                            'frame': frame,
+                            'length': end - start,
+                            'protein': translate(seq[start:end], to_stop=True)
+                        })
+                    start = None
+
+    return sorted(orfs, key=lambda orf: (orf['start'], orf['strand']))
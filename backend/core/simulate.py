@@ -0,0 +1,221 @@
+import math
+import random
+from collections import defaultdict
+from typing import Callable, Dict, List
+from .phylo import TreeNode
+from .seqio import FastqRecord
+from .sequence import reverse_complement
+
+TRANSITIONS = {'A': 'G', 'G': 'A', 'C': 'T', 'T': 'C'}
+TRANSVERSIONS = {'A': 'CT', 'G': 'CT', 'C': 'AG', 'T': 'AG'}
+
+
+def _rng(seed) -> random.Random:
+    return seed if isinstance(seed, random.Random) else random.Random(seed)
+
+
+def random_sequence(length: int, gc_bias: float = 0.5, seed=None) -> str:
+    """Random DNA with the given expected GC fraction; seed is an int or a random.Random"""
+    if length < 0:
+        raise ValueError('Length must not be negative')
+    if not 0 <= gc_bias <= 1:
+        raise ValueError('GC bias must be between 0 and 1')
+    rng = _rng(seed)
+    weights = [(1 - gc_bias) / 2, gc_bias / 2, gc_bias / 2, (1 - gc_bias) / 2]
+    return ''.join(rng.choices('ACGT', weights=weights, k=length))
+
+
+def _indel_length(rng: random.Random, mean: float) -> int:
+    """Geometric length with the given mean (at least 1)"""
+    return 1 + int(math.log(1 - rng.random()) / math.log(1 - 1 / mean)) if mean > 1 else 1
+
+
+def evolve_sequence(sequence: str, branch_length: float, kappa: float = 2.0, indel_rate: float = 0.0,
+                    mean_indel_length: float = 2.0, seed=None) -> str:
+    """Mutate a sequence along one branch under the Kimura two-parameter model
+
+    branch_length is the expected substitutions per site and kappa the transition/transversion
+    rate ratio, so the result is consistent with the k2p distance in core.metrics. Indels happen
+    at indel_rate per site per unit branch length, half insertions and half deletions, with
+    geometrically distributed lengths.
+    """
+    if branch_length < 0:
+        raise ValueError('Branch length must not be negative')
+    rng = _rng(seed)
+    beta_t = branch_length / (kappa + 2)
+    alpha_t = kappa * beta_t
+    p_transversion = 0.5 - 0.5 * math.exp(-4 * beta_t)
+    p_transition = 0.25 + 0.25 * math.exp(-4 * beta_t) - 0.5 * math.exp(-2 * (alpha_t + beta_t))
+
+    bases = []
+    for base in sequence.upper():
+        draw = rng.random()
+        if base not in TRANSITIONS:
+            bases.append(base)
+        elif draw < p_transition:
+            bases.append(TRANSITIONS[base])
+        elif draw < p_transition + p_transversion:
+            bases.append(rng.choice(TRANSVERSIONS[base]))
+        else:
+            bases.append(base)
+
+    if indel_rate > 0:
+        p_indel = min(indel_rate * branch_length, 1.0)
+        evolved, i = [], 0
+        while i < len(bases):
+            if rng.random() < p_indel:
+                length = _indel_length(rng, mean_indel_length)
+                if rng.random() < 0.5:
+                    evolved.append(bases[i])
+                    evolved.append(random_sequence(length, seed=rng))
+                    i += 1
+                else:
+                    i += length
+                continue
+            evolved.append(bases[i])
+            i += 1
+        bases = evolved
+    return ''.join(bases)
+
+
+def evolve_along_tree(tree: TreeNode, root_sequence: str, kappa: float = 2.0, indel_rate: float = 0.0,
+                      seed=None) -> Dict[str, str]:
+    """Leaf name to sequence after evolving root_sequence down every branch of the tree
+
+    Missing branch lengths count as zero. Without indels the leaf sequences stay aligned.
+    """
+    rng = _rng(seed)
+    leaves = {}
+
+    def descend(node: TreeNode, sequence: str):
+        sequence = evolve_sequence(sequence, node.branch_length or 0.0, kappa, indel_rate, seed=rng)
+        if node.is_leaf:
+            if node.name in leaves:
+                raise ValueError(f'Duplicate leaf name "{node.name}"')
+            leaves[node.name] = sequence
+        for child in node.children:
+            descend(child, sequence)
+
+    descend(tree, root_sequence.upper())
+    return leaves
+
+
+def simulate_reads(reference: str, count: int, length: int = 100, error_rate: float = 0.0,
+                   both_strands: bool = True, seed=None, prefix: str = 'read') -> List[FastqRecord]:
+    """Uniformly placed reads with substitution errors; names record the true origin
+
+    Names are prefix_index_position_strand (position 0-based on the forward strand), and
+    base qualities reflect error_rate so simulated reads pass through trimming unchanged.
+    """
+    if length > len(reference):
+        raise ValueError('Read length is longer than the reference')
+    rng = _rng(seed)
+    reference = reference.upper()
+    quality = chr(33 + min(41, int(-10 * math.log10(error_rate)) if error_rate > 0 else 41))
+    reads = []
+    for index in range(count):
+        position = rng.randint(0, len(reference) - length)
+        strand = '-' if both_strands and rng.random() < 0.5 else '+'
+        bases = reference[position:position + length]
+        if strand == '-':
+            bases = reverse_complement(bases)
+        if error_rate > 0:
+            bases = ''.join(rng.choice([b for b in 'ACGT' if b != base]) if rng.random() < error_rate else base
+                            for base in bases)
+        reads.append(FastqRecord(f'{prefix}_{index}_{position}_{strand}', bases, quality * length))
+    return reads
+
+
+def random_tree(names: List[str], mean_branch_length: float = 0.1, seed=None) -> TreeNode:
+    """Random rooted binary tree over the names with exponential branch lengths"""
+    if len(names) < 2:
+        raise ValueError('At least two leaves are needed')
+    rng = _rng(seed)
+    nodes = [TreeNode(name=name) for name in names]
+    while len(nodes) > 1:
+        first = nodes.pop(rng.randrange(len(nodes)))
+        second = nodes.pop(rng.randrange(len(nodes)))
+        for node in (first, second):
+            node.branch_length = round(rng.expovariate(1 / mean_branch_length), 6)
+        nodes.append(TreeNode(children=[first, second]))
+    return nodes[0]
+
+
+def simulate_alignment(tree: TreeNode, length: int, gc_bias: float = 0.5, kappa: float = 2.0,
+                       seed=None) -> Dict[str, str]:
+    """Aligned leaf sequences evolved from a random root along the tree (no indels)"""
+    rng = _rng(seed)
+    return evolve_along_tree(tree, random_sequence(length, gc_bias, rng), kappa, seed=rng)
+
+
+def klet_shuffle(sequence: str, k: int = 2, seed=None) -> str:
+    """Random permutation of a sequence with exactly the same k-let (k-mer) counts
+
+    Altschul-Erickson / uShuffle: the (k-1)-mers are vertices and each k-let an edge, so
+    every k-let preserving shuffle is an Eulerian walk from the first to the last (k-1)-mer.
+    A random spanning arborescence into the final vertex (Wilson's algorithm) fixes each
+    vertex's last exit edge and the remaining exits are shuffled, which samples such walks
+    uniformly. The first and last k-1 bases are unchanged.
+    """
+    if k < 1:
+        raise ValueError('k must be at least 1')
+    rng = _rng(seed)
+    if k == 1:
+        bases = list(sequence)
+        rng.shuffle(bases)
+        return ''.join(bases)
+    if len(sequence) <= k:
+        return sequence
+
+    edges = defaultdict(list)  # (k-1)-mer -> next bases
+    for i in range(len(sequence) - k + 1):
+        edges[sequence[i:i + k - 1]].append(sequence[i + k - 1])
+    first, last = sequence[:k - 1], sequence[-(k - 1):]
+
+    # Wilson's algorithm: loop-erased random walks until every vertex reaches the tree
+    in_tree = {last}
+    exit_edge = {}
+    for vertex in edges:
+        current = vertex
+        while current not in in_tree:
+            exit_edge[current] = rng.randrange(len(edges[current]))
+            current = (current + edges[current][exit_edge[current]])[1:]
+        current = vertex
+        while current not in in_tree:
+            in_tree.add(current)
+            current = (current + edges[current][exit_edge[current]])[1:]
+
+    for vertex, nexts in edges.items():
+        if vertex in exit_edge:
+            final = nexts.pop(exit_edge[vertex])
+            rng.shuffle(nexts)
+            nexts.append(final)
+        else:
+            rng.shuffle(nexts)
+
+    walk, vertex = [first], first
+    for _ in range(len(sequence) - k + 1):
+        base = edges[vertex].pop(0)
+        walk.append(base)
+        vertex = (vertex + base)[1:]
+    return ''.join(walk)
+
+
+def empirical_p_value(score: Callable[[str], float], sequence: str, k: int = 2, shuffles: int = 100,
+                      seed=None) -> Dict:
+    """Fraction of k-let shuffles scoring at least as high as the sequence itself
+
+    Uses the (r + 1) / (n + 1) estimate so the p-value is never zero.
+    """
+    if shuffles < 1:
+        raise ValueError('At least one shuffle is required')
+    rng = _rng(seed)
+    observed = score(sequence)
+    background = [score(klet_shuffle(sequence, k, rng)) for _ in range(shuffles)]
+    higher = sum(1 for value in background if value >= observed)
+    return {
+        'observed': observed,
+        'p_value': (higher + 1) / (shuffles + 1),
+        'shuffles': shuffles,
+        'background_mean': sum(background) / shuffles
+    }
@@ -0,0 +1,139 @@
+import hashlib
+import heapq
+import json
+import math
+from dataclasses import dataclass, field
+from typing import Dict, Iterable, List
+from .sequence import clean_sequence, reverse_complement
+from .windows import windows
+
+FORMAT_VERSION = 1
+MAX_HASH = 2 ** 64
+
+
+def kmer_hash(kmer: str, seed: int = 42) -> int:
+    """Stable 64-bit hash of a k-mer, the same in every process (unlike hash())"""
+    digest = hashlib.blake2b(kmer.encode(), digest_size=8, key=seed.to_bytes(8, 'little')).digest()
+    return int.from_bytes(digest, 'little')
+
+
+@dataclass
+class Sketch:
+    """Bottom-s MinHash sketch of the canonical k-mers of a sequence or read set
+
+    Keeps the `size` smallest k-mer hashes, a fixed-size random sample of the k-mer set, so
+    two sketches estimate the Jaccard index and containment of multi-megabase sequences in a
+    few thousand integer comparisons. A k-mer and its reverse complement hash the same, so
+    strand does not matter. Sketches compare only when k, size and seed agree.
+    """
+    k: int = 21
+    size: int = 1000
+    seed: int = 42
+    name: str = ''
+    hashes: List[int] = field(default_factory=list)  # sorted ascending
+
+    def __post_init__(self):
+        if not 1 <= self.k <= 32:
+            raise ValueError('k must be between 1 and 32')
+        if self.size < 1:
+            raise ValueError('size must be positive')
+
+    @classmethod
+    def from_sequences(cls, sequences: Iterable[str], k: int = 21, size: int = 1000, seed: int = 42,
+                       name: str = '') -> 'Sketch':
+        sketch = cls(k, size, seed, name)
+        for sequence in sequences:
+            sketch.add(sequence)
+        return sketch
+
+    def add(self, sequence: str) -> 'Sketch':
+        """Add the k-mers of one more sequence; k-mers with non-ACGT bases are skipped"""
+        sequence = clean_sequence(sequence).replace('U', 'T')
+        # Max-heap (negated) of the kept hashes, so memory stays at size however many k-mers are seen
+        heap, kept = [-value for value in self.hashes], set(self.hashes)
+        heapq.heapify(heap)
+        for _, kmer in windows(sequence, self.k):
+            if not set(kmer) <= set('ACGT'):
+                continue
+            value = kmer_hash(min(kmer, reverse_complement(kmer)), self.seed)
+            if value in kept:
+                continue
+            if len(heap) < self.size:
+                heapq.heappush(heap, -value)
+                kept.add(value)
+            elif value < -heap[0]:
+                kept.discard(-heapq.heapreplace(heap, -value))
+                kept.add(value)
+        self.hashes = sorted(-value for value in heap)
+        return self
+
+    def _check(self, other: 'Sketch'):
+        if (self.k, self.size, self.seed) != (other.k, other.size, other.seed):
+            raise ValueError(f'Sketches differ in k, size or seed: ({self.k}, {self.size}, {self.seed}) vs '
+                             f'({other.k}, {other.size}, {other.seed})')
+
+    def jaccard(self, other: 'Sketch') -> float:
+        """Estimated |A and B| / |A or B| of the two k-mer sets"""
+        self._check(other)
+        union = heapq.nsmallest(self.size, set(self.hashes) | set(other.hashes))
+        if not union:
+            return 0.0
+        shared = set(self.hashes) & set(other.hashes)
+        return sum(1 for value in union if value in shared) / len(union)
+
+    def containment(self, other: 'Sketch') -> float:
+        """Estimated fraction of this sketch's k-mers that are also in other's, e.g. a read set in a genome"""
+        self._check(other)
+        if not self.hashes or not other.hashes:
+            return 0.0
+        # Only hashes below both sketches' cutoffs were sampled by both
+        cutoff = min(self.hashes[-1], other.hashes[-1])
+        mine = [value for value in self.hashes if value <= cutoff]
+        theirs = set(other.hashes)
+        return sum(1 for value in mine if value in theirs) / len(mine)
+
+    def distance(self, other: 'Sketch') -> float:
+        """Mash distance, approximately the per-base divergence implied by the Jaccard index"""
+        jaccard = self.jaccard(other)
+        if jaccard == 0:
+            return 1.0
+        return 0.0 if jaccard == 1 else -math.log(2 * jaccard / (1 + jaccard)) / self.k
+
+    def compare(self, other: 'Sketch') -> Dict:
+        return {'query': self.name, 'subject': other.name, 'jaccard': round(self.jaccard(other), 4),
+                'containment': round(self.containment(other), 4), 'distance': round(self.distance(other), 4)}
+
+    def to_dict(self) -> Dict:
+        return {'version': FORMAT_VERSION, 'name': self.name, 'k': self.k, 'size': self.size, 'seed': self.seed,
+                'hashes': self.hashes}
+
+    @classmethod
+    def from_dict(cls, data: Dict) -> 'Sketch':
+        if not isinstance(data, dict) or 'hashes' not in data:
+            raise ValueError('Not a sketch: expected k, size, seed and hashes')
+        if data.get('version', FORMAT_VERSION) != FORMAT_VERSION:
+            raise ValueError(f'Unsupported sketch version {data.get("version")}')
+        hashes = sorted(int(value) for value in data['hashes'])
+        if any(not 0 <= value < MAX_HASH for value in hashes):
+            raise ValueError('Sketch hashes must be 64-bit unsigned integers')
+        return cls(int(data.get('k', 21)), int(data.get('size', 1000)), int(data.get('seed', 42)),
+                   str(data.get('name', '')), hashes)
+
+
+def dump_sketches(sketches: List[Sketch]) -> str:
+    """Sketches as a JSON list, one object per sketch"""
+    return json.dumps([sketch.to_dict() for sketch in sketches]) + '\n'
+
+
+def load_sketches(text: str) -> List[Sketch]:
+    """Sketches from dump_sketches output (a single sketch object is read too)"""
+    try:
+        data = json.loads(text)
+    except ValueError as e:
+        raise ValueError(f'Sketch file is not JSON: {e}')
+    return [Sketch.from_dict(item) for item in (data if isinstance(data, list) else [data])]
+
+
+def compare_sketches(sketches: List[Sketch]) -> List[Dict]:
+    """Every ordered pair of different sketches; containment is not symmetric, so both directions are listed"""
+    return [query.compare(subject) for i, query in enumerate(sketches) for j, subject in enumerate(sketches) if i != j]
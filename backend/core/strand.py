@@ -0,0 +1,346 @@
+from dataclasses import dataclass
+from typing import Dict, Iterator, List, Tuple
+from .sequence import (COMPLEMENT_TABLE, GAP_CHARS, back_translate, degeneracy, expand_ambiguous,
+                       six_frame_translate, translate)
+from .composition import gc_windows
+from .complexity import dust_regions, linguistic_complexity, shannon_entropy
+from .repeats import find_palindromes
+from .metrics import evolutionary_distance, substitution_counts
+from .motif import Motif, scan
+from .protein import Protein
+from .annealing import Duplex, anneal
+from .oligo_calc import (concentration_from_a260, extinction_coefficient, molecular_weight, nmol_per_od, pmol_to_ug,
+                         ug_to_pmol)
+from .simulate import klet_shuffle, random_sequence
+from .pcr import virtual_pcr
+from .mutagenesis import silent_site_changes
+from .parallel import base_counts, find_all, map_chunks
+from .restriction import digest, site_pattern
+from .multisearch import find_patterns
+from .myers import find_approximate
+from .diff import EditScript, diff, patch
+from .checksum import circular_seguid, md5, minimal_rotation, seguid
+from .windows import chunks, codons, windows
+from .sketch import Sketch
+
+DNA = 'DNA'
+RNA = 'RNA'
+
+
+def _complement_chunk(sequence: str, start: int, end: int) -> str:
+    """Case-preserving complement of sequence[start:end]"""
+    return sequence[start:end].translate(COMPLEMENT_TABLE)
+
+
+@dataclass
+class Strand:
+    """Nucleic acid strand written 5' to 3'
+
+    Operations return new Strands and never modify the original, so they can be chained,
+    e.g. strand.complement().reverse() is the reverse complement. The *_in_place variants
+    modify this Strand and return it instead, avoiding a new Strand per step on hot paths;
+    use clone() first when the original is still needed.
+
+    Case is kept: lowercase bases are soft-masked and stay lowercase through complement,
+    reversal and transcription. Gap characters ('-' and '.') are kept in place too.
+
+    A circular strand (a plasmid) has no ends: searches, digests and PCR wrap around the
+    origin, and rotate() moves the origin.
+    """
+    sequence: str
+    name: str = ""
+    molecule: str = DNA
+    circular: bool = False
+
+    def __post_init__(self):
+        self.sequence = ''.join(self.sequence.split())
+        if self.molecule not in (DNA, RNA):
+            raise ValueError(f'Unknown molecule type "{self.molecule}"')
+
+    @classmethod
+    def random(cls, length: int, gc_bias: float = 0.5, seed=None, name: str = "") -> 'Strand':
+        """Random DNA strand with the given expected GC fraction; a seed makes it reproducible"""
+        return cls(random_sequence(length, gc_bias, seed), name=name)
+
+    @classmethod
+    def back_translate(cls, protein, name: str = None) -> 'Strand':
+        """Degenerate (IUPAC) DNA strand encoding a protein sequence or Protein"""
+        return cls(back_translate(str(protein)), name=name if name is not None else getattr(protein, 'name', ''))
+
+    def __len__(self) -> int:
+        return len(self.sequence)
+
+    def __str__(self) -> str:
+        return self.sequence
+
+    def _derive(self, sequence: str, molecule: str = None) -> 'Strand':
+        """New Strand with the same name, topology and (by default) molecule type; the sequence is used as is"""
+        derived = Strand(sequence='', name=self.name, molecule=molecule or self.molecule, circular=self.circular)
+        derived.sequence = sequence
+        return derived
+
+    def clone(self) -> 'Strand':
+        """Independent copy of this Strand"""
+        return self._derive(self.sequence)
+
+    def upper(self) -> 'Strand':
+        """Copy with soft-masking removed (all bases uppercase)"""
+        return self._derive(self.sequence.upper())
+
+    def ungapped(self) -> 'Strand':
+        """Copy with gap characters removed"""
+        return self._derive(''.join(base for base in self.sequence if base not in GAP_CHARS))
+
+    def masked_fraction(self) -> float:
+        """Fraction of bases that are soft-masked (lowercase)"""
+        return sum(1 for base in self.sequence if base.islower()) / len(self.sequence) if self.sequence else 0.0
+
+    def reverse(self) -> 'Strand':
+        """Sequence reversed (not complemented)"""
+        return self._derive(self.sequence[::-1])
+
+    def _complemented(self, workers: int = None) -> str:
+        """Complemented sequence string; RNA strands complement A to U
+
+        Long sequences are complemented in parallel chunks (see parallel.map_chunks).
+        """
+        complemented = ''.join(map_chunks(_complement_chunk, self.sequence, workers))
+        if self.molecule == RNA:
+            complemented = complemented.replace('T', 'U').replace('t', 'u')
+        return complemented
+
+    def complement(self, workers: int = None) -> 'Strand':
+        """Base-wise complement (not reversed), IUPAC aware"""
+        return self._derive(self._complemented(workers))
+
+    def reverse_complement(self, workers: int = None) -> 'Strand':
+        """Complementary strand read 5' to 3'"""
+        return self.complement(workers).reverse()
+
+    def reverse_in_place(self) -> 'Strand':
+        """Reverse this Strand and return it"""
+        self.sequence = self.sequence[::-1]
+        return self
+
+    def complement_in_place(self, workers: int = None) -> 'Strand':
+        """Complement this Strand and return it"""
+        self.sequence = self._complemented(workers)
+        return self
+
+    def reverse_complement_in_place(self, workers: int = None) -> 'Strand':
+        """Reverse complement this Strand and return it"""
+        return self.complement_in_place(workers).reverse_in_place()
+
+    def transcribe(self) -> 'Strand':
+        """RNA transcript of a DNA coding strand (T to U)"""
+        if self.molecule == RNA:
+            return self._derive(self.sequence)
+        return self._derive(self.sequence.replace('T', 'U').replace('t', 'u'), RNA)
+
+    def back_transcribe(self) -> 'Strand':
+        """DNA copy of an RNA strand (U to T)"""
+        if self.molecule == DNA:
+            return self._derive(self.sequence)
+        return self._derive(self.sequence.replace('U', 'T').replace('u', 't'), DNA)
+
+    def gc_content(self, workers: int = None) -> float:
+        """GC content percentage, ignoring gaps; long strands are counted in parallel chunks"""
+        counts = base_counts(self.sequence.upper(), workers)
+        bases = sum(count for base, count in counts.items() if base not in GAP_CHARS)
+        return (counts.get('G', 0) + counts.get('C', 0) + counts.get('S', 0)) / bases * 100 if bases else 0.0
+
+    def rotate(self, n: int) -> 'Strand':
+        """Circular strand with its origin moved to position n (negative n counts from the end)"""
+        if not self.circular:
+            raise ValueError('Only circular strands can be rotated')
+        n = n % len(self.sequence) if self.sequence else 0
+        return self._derive(self.sequence[n:] + self.sequence[:n])
+
+    def canonical_origin(self) -> int:
+        """Origin of the lexicographically smallest rotation (case-insensitive)"""
+        return minimal_rotation(self.sequence.upper())
+
+    def canonical_rotation(self) -> 'Strand':
+        """Circular strand rotated to its canonical origin, the same whichever origin it was entered with"""
+        return self.rotate(self.canonical_origin())
+
+    def find(self, pattern: str, workers: int = None) -> List[int]:
+        """0-based starts of every (possibly overlapping) match of an IUPAC pattern on this strand
+
+        On a circular strand matches spanning the origin are included.
+        """
+        pattern = ''.join(pattern.split()).upper()
+        if not pattern:
+            raise ValueError('Pattern is required')
+        sequence = self.sequence.upper()
+        if not self.circular or not sequence:
+            return find_all(sequence, site_pattern(pattern), len(pattern), workers)
+        wrapped = sequence + (sequence * (len(pattern) // len(sequence) + 1))[:len(pattern) - 1]
+        return [start for start in find_all(wrapped, site_pattern(pattern), len(pattern), workers)
+                if start < len(sequence)]
+
+    def degeneracy(self) -> int:
+        """Number of concrete ACGT sequences this strand stands for (1 when unambiguous)"""
+        return degeneracy(self.sequence.upper())
+
+    def expand(self, limit: int = 1000) -> List['Strand']:
+        """All concrete ACGT strands this degenerate strand stands for
+
+        The count is checked first, and a ValueError is raised rather than enumerating
+        more than limit sequences.
+        """
+        count = self.degeneracy()
+        if count > limit:
+            raise ValueError(f'Strand represents {count} sequences, more than the limit of {limit}')
+        return [self._derive(sequence) for sequence in expand_ambiguous(self.sequence.upper(), count)]
+
+    def expansions(self, cap: int = 1000) -> List[str]:
+        """Concrete ACGT sequences this (possibly degenerate) strand stands for, at most cap"""
+        return list(expand_ambiguous(self.sequence.upper(), cap))
+
+    def translate(self, frame: int = 0, to_stop: bool = False) -> Protein:
+        """Protein translated from the given frame (standard code)"""
+        return Protein(translate(self.sequence, frame, to_stop), name=self.name)
+
+    def six_frame_translate(self) -> List[Dict]:
+        """Peptides of all six reading frames with frame labels and stop positions"""
+        return six_frame_translate(self.sequence)
+
+    def molecular_weight(self, double_stranded: bool = False) -> float:
+        """Molecular weight (g/mol) of the oligo, or of the duplex with its complement"""
+        return molecular_weight(self.ungapped().sequence, double_stranded)
+
+    def extinction_coefficient(self, double_stranded: bool = False, method: str = 'nearest_neighbor') -> float:
+        """ε260 in M^-1 cm^-1, by the nearest-neighbor model unless method is 'base_sum'"""
+        return extinction_coefficient(self.ungapped().sequence, double_stranded, method)
+
+    def concentration(self, a260: float, double_stranded: bool = False, path_length: float = 1.0,
+                      dilution: float = 1.0) -> float:
+        """Concentration in µM from a measured A260"""
+        return concentration_from_a260(a260, self.extinction_coefficient(double_stranded), path_length, dilution)
+
+    def nmol_per_od(self, double_stranded: bool = False) -> float:
+        """Nanomoles per A260 unit"""
+        return nmol_per_od(self.extinction_coefficient(double_stranded))
+
+    def ug_to_pmol(self, ug: float, double_stranded: bool = False) -> float:
+        return ug_to_pmol(ug, self.molecular_weight(double_stranded))
+
+    def pmol_to_ug(self, pmol: float, double_stranded: bool = False) -> float:
+        return pmol_to_ug(pmol, self.molecular_weight(double_stranded))
+
+    def anneal(self, other: 'Strand', min_run: int = 4, top: int = 3) -> List[Duplex]:
+        """Strongest annealing registers with another strand (this strand on top, 5'->3')"""
+        return anneal(self.sequence, other.sequence, min_run, top)
+
+    def find_patterns(self, patterns: Dict[str, str], both_strands: bool = True) -> List[Dict]:
+        """Occurrences of many named exact patterns (primers, barcodes, adapters) in one pass"""
+        return find_patterns(self.sequence, patterns, both_strands)
+
+    def find_approximate(self, pattern: str, max_errors: int = 1, both_strands: bool = True) -> List[Dict]:
+        """Occurrences of a short pattern with up to max_errors substitutions, insertions or deletions"""
+        return find_approximate(self.sequence, pattern, max_errors, both_strands)
+
+    def pcr(self, forward: 'Strand', reverse: 'Strand', max_mismatches: int = 2, three_prime_exact: int = 3,
+            circular: bool = None, max_size: int = 10000) -> List[Dict]:
+        """Predicted PCR products with this strand as template, circular if the strand is unless overridden"""
+        primers = [(forward.name or 'forward', forward.sequence), (reverse.name or 'reverse', reverse.sequence)]
+        circular = self.circular if circular is None else circular
+        return virtual_pcr(self.sequence, primers, max_mismatches, three_prime_exact, circular, max_size)
+
+    def digest(self, enzymes: List[str], methylation: List[str] = None) -> List[Dict]:
+        """Restriction fragments; on a circular strand the fragment across the origin has end < start
+
+        methylation lists the active models ('dam', 'dcm', 'cpg'); see restriction.find_sites.
+        """
+        return digest(self.sequence, enzymes, circular=self.circular, methylation=methylation)
+
+    def silent_site_changes(self, enzyme: str, mode: str = 'introduce', max_changes: int = 3) -> List[Dict]:
+        """Synonymous codon changes that introduce or remove an enzyme's site, reading this strand as a CDS"""
+        return silent_site_changes(self.sequence, enzyme, mode, max_changes)
+
+    def seguid(self) -> str:
+        """SEGUID checksum of the sequence (case-insensitive)"""
+        return seguid(self.sequence)
+
+    def md5(self) -> str:
+        """Hex MD5 of the uppercased sequence"""
+        return md5(self.sequence)
+
+    def circular_seguid(self) -> str:
+        """Checksum treating the strand as a circular duplex: the same for any origin or strand"""
+        return circular_seguid(self.sequence)
+
+    def diff(self, other: 'Strand') -> EditScript:
+        """Edit script turning this strand into another"""
+        return diff(self.sequence, other.sequence)
+
+    def patch(self, script: EditScript) -> 'Strand':
+        """This strand with an edit script (from diff) applied"""
+        return self._derive(patch(self.sequence, script))
+
+    def windows(self, k: int, step: int = 1) -> Iterator[Tuple[int, str]]:
+        """(start, window) for every full window of k bases, every step bases, generated lazily"""
+        return windows(self.sequence, k, step)
+
+    def codons(self, frame: int = 0) -> Iterator[Tuple[int, str]]:
+        """(start, codon) for every whole codon of a reading frame"""
+        return codons(self.sequence, frame)
+
+    def chunks(self, n: int) -> Iterator[Tuple[int, str]]:
+        """(start, chunk) for consecutive pieces of n bases, the last possibly shorter"""
+        return chunks(self.sequence, n)
+
+    def gc_windows(self, window: int = 100, step: int = 10) -> List[Dict]:
+        """GC content, GC skew and AT skew in sliding windows"""
+        return gc_windows(self.sequence, window, step)
+
+    def sketch(self, k: int = 21, size: int = 1000) -> Sketch:
+        """MinHash sketch of the canonical k-mers, for alignment-free comparison with other strands"""
+        return Sketch.from_sequences([self.sequence], k, size, name=self.name)
+
+    def entropy(self, k: int = 1) -> float:
+        """Shannon entropy (bits) of the k-mer distribution"""
+        return shannon_entropy(self.sequence, k)
+
+    def linguistic_complexity(self, max_k: int = None) -> float:
+        """Distinct substrings observed over the maximum possible, 0 to 1"""
+        return linguistic_complexity(self.sequence, max_k)
+
+    def low_complexity_regions(self, window: int = 64, threshold: float = 20.0) -> List[Tuple[int, int]]:
+        """DUST-flagged low-complexity regions as 0-based, end-exclusive (start, end) pairs"""
+        return dust_regions(self.sequence, window=window, threshold=threshold)
+
+    def mask(self, regions: List[Tuple[int, int]], hard: bool = False) -> 'Strand':
+        """Copy with regions soft-masked (lowercase) or, when hard, replaced with N"""
+        bases = list(self.sequence)
+        for start, end in regions:
+            if start < 0 or end > len(bases) or start > end:
+                raise ValueError(f'Region ({start}, {end}) is outside the strand')
+            for i in range(start, end):
+                bases[i] = 'N' if hard else bases[i].lower()
+        return self._derive(''.join(bases))
+
+    def dust_mask(self, window: int = 64, threshold: float = 20.0, hard: bool = False) -> 'Strand':
+        """Copy with DUST low-complexity regions masked"""
+        return self.mask(self.low_complexity_regions(window, threshold), hard)
+
+    def find_palindromes(self, min_length: int = 4, max_length: int = 12, max_mismatches: int = 0) -> List[Dict]:
+        """Reverse-complement palindromic regions with coordinates and sequence"""
+        return find_palindromes(self.sequence, min_length, max_length, max_mismatches)
+
+    def scan_motif(self, motif: Motif, threshold: float = 0.8, relative: bool = True) -> List[Dict]:
+        """Motif hits on both strands scoring at or above threshold"""
+        return scan(self.sequence, motif, threshold, relative)
+
+    def shuffle(self, k: int = 2, seed=None) -> 'Strand':
+        """Random permutation preserving k-let (by default dinucleotide) counts"""
+        return self._derive(klet_shuffle(self.sequence, k, seed))
+
+    def distance(self, other: 'Strand', model: str = 'jc69') -> float:
+        """Evolutionary distance to an aligned strand of the same length ('p', 'jc69' or 'k2p')"""
+        return evolutionary_distance(self.sequence, other.sequence, model)
+
+    def substitutions(self, other: 'Strand') -> Dict[str, int]:
+        """Identical, transition, transversion, ambiguous and gap columns against an aligned strand"""
+        return substitution_counts(self.sequence, other.sequence)
@@ -0,0 +1,202 @@
+import math
+from typing import Dict, List, Optional, Tuple
+from xml.sax.saxutils import escape
+
+# Opening -> closing bracket; () nests and is laid out, the others mark pseudoknot pairs
+BRACKETS = {'(': ')', '[': ']', '{': '}', '<': '>'}
+STRAND_BREAKS = '&+'
+
+BASE_COLORS = {'A': '#fca5a5', 'C': '#93c5fd', 'G': '#86efac', 'T': '#fde68a', 'U': '#fde68a'}
+DEFAULT_BASE_COLOR = '#e5e7eb'
+
+Point = Tuple[float, float]
+
+
+def parse_dot_bracket(structure: str) -> List[Tuple[int, int, str]]:
+    """Base pairs (i, j, bracket) of a dot-bracket string, 0-based over the bases (strand breaks skipped)"""
+    closing = {close: open_ for open_, close in BRACKETS.items()}
+    stacks = {open_: [] for open_ in BRACKETS}
+    pairs, position = [], 0
+    for char in ''.join(structure.split()):
+        if char in STRAND_BREAKS:
+            continue
+        if char in BRACKETS:
+            stacks[char].append(position)
+        elif char in closing:
+            if not stacks[closing[char]]:
+                raise ValueError(f'Unbalanced "{char}" at base {position + 1}')
+            pairs.append((stacks[closing[char]].pop(), position, closing[char]))
+        elif char != '.':
+            raise ValueError(f'Unexpected "{char}" in dot-bracket; use . ( ) [ ] {{ }} < > and & between strands')
+        position += 1
+    for open_, stack in stacks.items():
+        if stack:
+            raise ValueError(f'Unbalanced "{open_}" at base {stack[-1] + 1}')
+    return sorted(pairs)
+
+
+def strand_breaks(structure: str) -> List[int]:
+    """Base indices that start a new strand"""
+    breaks, position = [], 0
+    for char in ''.join(structure.split()):
+        if char in STRAND_BREAKS:
+            breaks.append(position)
+        else:
+            position += 1
+    return breaks
+
+
+def _add(p: Point, q: Point, scale: float = 1.0) -> Point:
+    return p[0] + q[0] * scale, p[1] + q[1] * scale
+
+
+def _unit(p: Point, q: Point) -> Point:
+    """Unit vector from p to q"""
+    dx, dy = q[0] - p[0], q[1] - p[1]
+    norm = math.hypot(dx, dy) or 1.0
+    return dx / norm, dy / norm
+
+
+class _Layout:
+    """Radial layout: every loop is a regular polygon with unit sides, stems are ladders of unit rungs
+
+    Nodes are the bases plus one spacer per strand break, so separate strands of a complex
+    leave a gap in the loop they share instead of being drawn as one backbone.
+    """
+
+    def __init__(self, length: int, pairs: List[Tuple[int, int, str]], breaks: List[int]):
+        # node index -> base index, None for spacers
+        self.bases: List[Optional[int]] = []
+        for base in range(length):
+            if base in breaks:
+                self.bases.append(None)
+            self.bases.append(base)
+        node_of = {base: node for node, base in enumerate(self.bases) if base is not None}
+        self.partner = [-1] * len(self.bases)
+        for i, j, bracket in pairs:
+            if bracket == '(':
+                self.partner[node_of[i]], self.partner[node_of[j]] = node_of[j], node_of[i]
+        self.positions: List[Optional[Point]] = [None] * len(self.bases)
+
+    def run(self) -> List[Optional[Point]]:
+        # The exterior loop runs along a line with stems rising from it
+        x, node = 0.0, 0
+        while node < len(self.bases):
+            self.positions[node] = (x, 0.0)
+            partner = self.partner[node]
+            if partner > node:
+                self.positions[partner] = (x + 1, 0.0)
+                self._stem(node, partner, (0.0, -1.0))
+                x, node = x + 2, partner + 1
+            else:
+                x, node = x + 1, node + 1
+        return self.positions
+
+    def _stem(self, i: int, j: int, direction: Point):
+        """Stack pairs inward from (i, j), placed, then lay out the loop they close"""
+        while self.partner[i + 1] == j - 1 and i + 1 < j - 1:
+            self.positions[i + 1] = _add(self.positions[i], direction)
+            self.positions[j - 1] = _add(self.positions[j], direction)
+            i, j = i + 1, j - 1
+        self._loop(i, j, direction)
+
+    def _loop(self, i: int, j: int, direction: Point):
+        members, node = [i], i + 1
+        while node < j:
+            members.append(node)
+            if self.partner[node] > node:
+                members.append(self.partner[node])
+                node = self.partner[node] + 1
+            else:
+                node += 1
+        members.append(j)
+
+        sides = len(members)
+        radius = 0.5 / math.sin(math.pi / sides)
+        apothem = radius * math.cos(math.pi / sides)
+        middle = ((self.positions[i][0] + self.positions[j][0]) / 2, (self.positions[i][1] + self.positions[j][1]) / 2)
+        center = _add(middle, direction, apothem)
+        start = math.atan2(self.positions[i][1] - center[1], self.positions[i][0] - center[0])
+        # Go round the long way from i so the polygon closes on j
+        end = math.atan2(self.positions[j][1] - center[1], self.positions[j][0] - center[0])
+        step = 2 * math.pi / sides
+        turn = -1 if abs(math.remainder(start - step * (sides - 1) - end, 2 * math.pi)) < 1e-6 else 1
+        for index, member in enumerate(members[1:-1], start=1):
+            angle = start + turn * step * index
+            self.positions[member] = (center[0] + radius * math.cos(angle), center[1] + radius * math.sin(angle))
+
+        for index, member in enumerate(members[1:-1], start=1):
+            partner = self.partner[member]
+            if partner > member:
+                mid = ((self.positions[member][0] + self.positions[partner][0]) / 2,
+                       (self.positions[member][1] + self.positions[partner][1]) / 2)
+                self._stem(member, partner, _unit(center, mid))
+
+
+def structure_layout(structure: str) -> List[Point]:
+    """2D coordinates of each base, one unit apart along the backbone and across pairs"""
+    pairs = parse_dot_bracket(structure)
+    length = sum(1 for char in ''.join(structure.split()) if char not in STRAND_BREAKS)
+    layout = _Layout(length, pairs, strand_breaks(structure))
+    positions = layout.run()
+    return [positions[node] for node, base in enumerate(layout.bases) if base is not None]
+
+
+def render_structure_svg(structure: str, sequence: str = '', scale: float = 18.0, number_every: int = 10) -> str:
+    """Secondary structure drawing as SVG: bases as colored circles, pairs as bars, pseudoknots dashed"""
+    pairs = parse_dot_bracket(structure)
+    positions = structure_layout(structure)
+    sequence = ''.join(sequence.split()).upper()
+    if sequence and len(sequence) != len(positions):
+        raise ValueError(f'Sequence has {len(sequence)} bases but the structure has {len(positions)}')
+    if not positions:
+        raise ValueError('Structure is empty')
+
+    margin = 2.0
+    xs, ys = [p[0] for p in positions], [p[1] for p in positions]
+    left, top = min(xs) - margin, min(ys) - margin
+    width, height = (max(xs) - left + margin) * scale, (max(ys) - top + margin) * scale
+
+    def point(base: int) -> Point:
+        return (positions[base][0] - left) * scale, (positions[base][1] - top) * scale
+
+    breaks = set(strand_breaks(structure))
+
+    svg = [f'<svg xmlns="http://www.w3.org/2000/svg" width="{width:.0f}" height="{height:.0f}" '
+           f'viewBox="0 0 {width:.2f} {height:.2f}" font-family="Helvetica, Arial, sans-serif">',
+           f'<rect width="{width:.2f}" height="{height:.2f}" fill="white"/>']
+    for base in range(1, len(positions)):
+        if base not in breaks:
+            (x1, y1), (x2, y2) = point(base - 1), point(base)
+            svg.append(f'<line x1="{x1:.2f}" y1="{y1:.2f}" x2="{x2:.2f}" y2="{y2:.2f}" stroke="#374151" stroke-width="1.5"/>')
+    for i, j, bracket in pairs:
+        (x1, y1), (x2, y2) = point(i), point(j)
+        dash = '' if bracket == '(' else ' stroke-dasharray="3,3"'
+        svg.append(f'<line x1="{x1:.2f}" y1="{y1:.2f}" x2="{x2:.2f}" y2="{y2:.2f}" stroke="#9ca3af" stroke-width="2"{dash}/>')
+
+    radius = scale * 0.42
+    for base in range(len(positions)):
+        x, y = point(base)
+        letter = sequence[base] if sequence else ''
+        svg.append(f'<circle cx="{x:.2f}" cy="{y:.2f}" r="{radius:.2f}" fill="{BASE_COLORS.get(letter, DEFAULT_BASE_COLOR)}" '
+                   f'stroke="#374151" stroke-width="0.8"><title>{base + 1}{escape(letter)}</title></circle>')
+        if letter:
+            svg.append(f'<text x="{x:.2f}" y="{y + radius * 0.45:.2f}" font-size="{radius * 1.2:.1f}" '
+                       f'text-anchor="middle">{escape(letter)}</text>')
+        if number_every and (base + 1) % number_every == 0:
+            svg.append(f'<text x="{x + radius * 1.2:.2f}" y="{y - radius * 1.2:.2f}" font-size="{radius:.1f}" '
+                       f'fill="#6b7280">{base + 1}</text>')
+    for base in sorted({0} | breaks):
+        x, y = point(base)
+        svg.append(f'<text x="{x - radius * 2.6:.2f}" y="{y + radius * 0.4:.2f}" font-size="{radius:.1f}" '
+                   f'fill="#6b7280">5\'</text>')
+    svg.append('</svg>')
+    return '\n'.join(svg)
+
+
+def structure_summary(structure: str) -> Dict:
+    pairs = parse_dot_bracket(structure)
+    length = sum(1 for char in ''.join(structure.split()) if char not in STRAND_BREAKS)
+    return {'length': length, 'strands': len(strand_breaks(structure)) + 1,
+            'pairs': [[i, j] for i, j, _ in pairs],
+            'pseudoknots': sum(1 for _, _, bracket in pairs if bracket != '(')}
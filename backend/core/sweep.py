@@ -0,0 +1,314 @@
+import math
+from concurrent.futures import ProcessPoolExecutor, wait
+from dataclasses import dataclass, field
+from typing import Dict, List, Optional
+from xml.sax.saxutils import escape
+from .context import current
+from .crn import CRN
+from .enumerator import DEFAULT_RELEASE_CUTOFF, canonical_kernel, enumerate_reactions, read_complexes
+from .kinetics import TimeCourse, simulate
+from .parallel import DEFAULT_WORKERS, process_context
+from .sbml import read_crn
+
+PARAMETER_KINDS = ('initial', 'rate', 'toehold')
+METRICS = ('final', 'max', 'half_time')
+MAX_SWEEP_POINTS = 400
+MAX_AXIS_VALUES = 50
+CANCEL_POLL_SECONDS = 0.2
+
+
+@dataclass
+class SweepParameter:
+    """Parameter varied by a sweep
+
+    initial sets a species' initial amount, rate the rate constant of the target reaction
+    (numbered from 1 in CRN order), and toehold the length in nucleotides of a domain of
+    enumerated complexes, which changes both the network and its rates.
+    """
+    kind: str
+    target: str
+    values: List[float]
+
+    def __post_init__(self):
+        if self.kind not in PARAMETER_KINDS:
+            raise ValueError(f'Unknown parameter "{self.kind}"; use {", ".join(PARAMETER_KINDS)}')
+        if not self.values:
+            raise ValueError(f'No values to sweep for {self.label}')
+        if len(self.values) > MAX_AXIS_VALUES:
+            raise ValueError(f'At most {MAX_AXIS_VALUES} values per parameter')
+        if self.kind == 'toehold' and any(value < 1 or int(value) != value for value in self.values):
+            raise ValueError('Toehold lengths must be whole numbers of nucleotides')
+
+    @property
+    def label(self) -> str:
+        return f'{self.kind} {self.target}'
+
+    @classmethod
+    def from_dict(cls, data: Dict) -> 'SweepParameter':
+        """Parameter from explicit values, or a start/stop/steps range that is log-spaced with log"""
+        if data.get('values'):
+            values = [float(value) for value in data['values']]
+        elif 'start' not in data or 'stop' not in data:
+            raise ValueError('Each swept parameter needs values, or a start and a stop')
+        else:
+            values = sweep_values(float(data['start']), float(data['stop']), int(data.get('steps', 5)),
+                                  bool(data.get('log', False)))
+        return cls(str(data.get('kind', '')), str(data.get('target', '')), values)
+
+
+def sweep_values(start: float, stop: float, steps: int, log: bool = False) -> List[float]:
+    """steps values from start to stop, evenly spaced or (log) evenly spaced in magnitude"""
+    if steps < 1:
+        raise ValueError('A range needs at least one step')
+    if steps == 1:
+        return [start]
+    if log:
+        if start <= 0 or stop <= 0:
+            raise ValueError('Log-spaced ranges need positive bounds')
+        return [start * (stop / start) ** (k / (steps - 1)) for k in range(steps)]
+    return [start + (stop - start) * k / (steps - 1) for k in range(steps)]
+
+
+def measure(course: TimeCourse, species: str, metric: str) -> Optional[float]:
+    """final or max amount of a species, or half_time: when it first reaches half its final amount"""
+    values = course.values.get(species)
+    if values is None:
+        return 0.0 if metric != 'half_time' else None  # never formed under these parameters
+    if metric == 'final':
+        return values[-1]
+    if metric == 'max':
+        return max(values)
+    half = values[-1] / 2
+    if values[-1] <= 0:
+        return None
+    for k, value in enumerate(values):
+        if value >= half:
+            if k == 0:
+                return course.times[0]
+            # Linear interpolation between the samples either side of the crossing
+            before = values[k - 1]
+            return course.times[k - 1] + (half - before) / (value - before) * (course.times[k] - course.times[k - 1])
+    return None
+
+
+def _run_point(task: Dict) -> Optional[float]:
+    """Simulate one point of a sweep and measure the output; module-level so workers can run it"""
+    lengths = dict(task['lengths'])
+    initial = dict(task['initial'])
+    rates: Dict[int, float] = {}
+    for kind, target, value in task['settings']:
+        if kind == 'initial':
+            initial[target] = value
+        elif kind == 'rate':
+            rates[int(target)] = value
+        else:
+            lengths[target] = int(value)
+    if task['complexes']:
+        enumeration = enumerate_reactions(task['complexes'], 'infinite', lengths, task['release_cutoff'],
+                                          task['max_complex_size'], task['max_species'])
+        crn = CRN.from_enumeration(enumeration)
+    else:
+        crn = read_crn(task['crn'])
+        initial = {**crn.amounts, **initial}
+    for number, rate in rates.items():
+        if not 1 <= number <= len(crn.reactions):
+            raise ValueError(f'There is no reaction {number}; the network has {len(crn.reactions)}')
+        crn.reactions[number - 1].rate = rate
+    output = task['output']
+    if task['output_kernel']:
+        # Products are named as they are found, so the output is matched by its kernel; it may not form at all
+        output = next((name for name, kernel in crn.labels.items() if kernel == task['output_kernel']), output)
+    elif output not in crn.species:
+        raise ValueError(f'Unknown output species "{output}"')
+    initial = {name: amount for name, amount in initial.items() if name in crn.species}
+    course = simulate(crn, initial, task['t_end'], task['method'], task['points'], task['rate'], task['seed'])
+    return measure(course, output, task['metric'])
+
+
+@dataclass
+class SweepResult:
+    """Output metric over a grid of one or two parameters; grid[row][column] follows y then x"""
+    x: SweepParameter
+    y: Optional[SweepParameter]
+    output: str
+    metric: str
+    grid: List[List[Optional[float]]]
+    errors: List[str] = field(default_factory=list)
+
+    def summary(self) -> Dict:
+        cells = [(value, row, column) for row, values in enumerate(self.grid)
+                 for column, value in enumerate(values) if value is not None]
+        if not cells:
+            return {'min': None, 'max': None, 'best': None}
+
+        def point(cell) -> Dict:
+            value, row, column = cell
+            data = {'value': value, self.x.label: self.x.values[column]}
+            if self.y:
+                data[self.y.label] = self.y.values[row]
+            return data
+
+        low, high = min(cells), max(cells)
+        # The best point is the highest output, except for half-times where faster is better
+        best = low if self.metric == 'half_time' else high
+        return {'min': point(low), 'max': point(high), 'best': point(best)}
+
+    def to_csv(self) -> str:
+        header = [self.y.label if self.y else ''] + [f'{value:g}' for value in self.x.values]
+        rows = [','.join(header)]
+        for row, values in enumerate(self.grid):
+            label = f'{self.y.values[row]:g}' if self.y else f'{self.metric} {self.output}'
+            rows.append(','.join([label] + ['' if value is None else f'{value:g}' for value in values]))
+        return '\n'.join(rows) + '\n'
+
+    def to_svg(self, cell: int = 36) -> str:
+        """Heatmap of the grid from white (lowest) to blue (highest); empty cells are grey"""
+        left, top = 90, 30
+        width = left + cell * len(self.x.values) + 20
+        height = top + cell * len(self.grid) + 60
+        values = [value for row in self.grid for value in row if value is not None]
+        low, high = (min(values), max(values)) if values else (0.0, 1.0)
+        span = (high - low) or 1.0
+        svg = [f'<svg xmlns="http://www.w3.org/2000/svg" width="{width}" height="{height}" '
+               f'font-family="Arial, sans-serif" font-size="10">',
+               f'<rect width="{width}" height="{height}" fill="#ffffff"/>',
+               f'<text x="{left}" y="16" font-size="11" fill="#374151">'
+               f'{escape(self.metric)} of {escape(self.output)}</text>']
+        for row, row_values in enumerate(self.grid):
+            y = top + row * cell
+            if self.y:
+                svg.append(f'<text x="{left - 6}" y="{y + cell / 2 + 3}" text-anchor="end" fill="#6b7280">'
+                           f'{self.y.values[row]:.3g}</text>')
+            for column, value in enumerate(row_values):
+                x = left + column * cell
+                if value is None:
+                    fill, text = '#e5e7eb', 'n/a'
+                else:
+                    share = (value - low) / span
+                    fill = f'rgb({round(255 - 218 * share)},{round(255 - 156 * share)},{round(255 - 20 * share)})'
+                    text = f'{value:.3g}'
+                svg.append(f'<rect x="{x}" y="{y}" width="{cell}" height="{cell}" fill="{fill}" stroke="#ffffff">'
+                           f'<title>{text}</title></rect>')
+        for column, value in enumerate(self.x.values):
+            x = left + column * cell + cell / 2
+            svg.append(f'<text x="{x}" y="{top + len(self.grid) * cell + 14}" text-anchor="middle" '
+                       f'fill="#6b7280">{value:.3g}</text>')
+        svg.append(f'<text x="{left + cell * len(self.x.values) / 2}" y="{top + len(self.grid) * cell + 34}" '
+                   f'text-anchor="middle" font-size="11" fill="#374151">{escape(self.x.label)}</text>')
+        if self.y:
+            middle = top + cell * len(self.grid) / 2
+            svg.append(f'<text x="14" y="{middle}" text-anchor="middle" font-size="11" fill="#374151" '
+                       f'transform="rotate(-90 14 {middle})">{escape(self.y.label)}</text>')
+        svg.append('</svg>')
+        return ''.join(svg)
+
+    def to_dict(self) -> Dict:
+        return {
+            'output': self.output,
+            'metric': self.metric,
+            'x': {'label': self.x.label, 'values': self.x.values},
+            'y': {'label': self.y.label, 'values': self.y.values} if self.y else None,
+            'grid': self.grid,
+            'summary': self.summary(),
+            'errors': self.errors
+        }
+
+
+class ParameterSweep:
+    """Simulations of a CRN, or of DSD complexes under infinite semantics, over one or two parameters
+
+    Every grid point is simulated independently, in worker processes when there are several
+    points. Points whose simulation fails (a stiff network, a
+    toehold length that makes the system polymerize) are left empty and their errors listed.
+    """
+
+    def __init__(self, parameters: List[SweepParameter], output: str, initial: Dict[str, float], t_end: float,
+                 crn: str = '', complexes: str = '', metric: str = 'final', method: str = 'ode', points: int = 200,
+                 rate: float = 1.0, seed: int = None, lengths: Dict[str, int] = None, release_cutoff: int = 7,
+                 max_complex_size: int = 6, max_species: int = 200, workers: int = None):
+        if not 1 <= len(parameters) <= 2:
+            raise ValueError('Sweep one or two parameters')
+        if metric not in METRICS:
+            raise ValueError(f'Unknown metric "{metric}"; use {", ".join(METRICS)}')
+        if bool(crn) == bool(complexes):
+            raise ValueError('Give either a CRN or DSD complexes')
+        if any(parameter.kind == 'toehold' for parameter in parameters) and not complexes:
+            raise ValueError('Toehold lengths can only be swept for DSD complexes')
+        size = math.prod(len(parameter.values) for parameter in parameters)
+        if size > MAX_SWEEP_POINTS:
+            raise ValueError(f'The sweep has {size} points; the limit is {MAX_SWEEP_POINTS}')
+        self.parameters = parameters
+        self.workers = workers or DEFAULT_WORKERS
+        # Outputs other than the input complexes are given in kernel notation
+        output_kernel = canonical_kernel(output) if complexes and output not in read_complexes(complexes) else ''
+        self.base = {
+            'crn': crn, 'complexes': complexes, 'initial': dict(initial), 'lengths': dict(lengths or {}),
+            'output': output, 'output_kernel': output_kernel, 'metric': metric, 't_end': t_end, 'method': method,
+            'points': points, 'rate': rate, 'seed': seed, 'release_cutoff': release_cutoff,
+            'max_complex_size': max_complex_size, 'max_species': max_species
+        }
+
+    @classmethod
+    def from_dict(cls, data: Dict) -> 'ParameterSweep':
+        """Sweep described as by the sweep endpoint: parameters, output, initial, t_end and either crn or complexes"""
+        seed = data.get('seed')
+        return cls(
+            [SweepParameter.from_dict(item) for item in data.get('parameters') or []], str(data.get('output', '')),
+            {str(name): float(amount) for name, amount in (data.get('initial') or {}).items()},
+            float(data.get('t_end', 0)), crn=str(data.get('crn') or ''), complexes=str(data.get('complexes') or ''),
+            metric=str(data.get('metric', 'final')), method=str(data.get('method', 'ode')),
+            points=int(data.get('points', 200)), rate=float(data.get('rate', 1.0)),
+            seed=int(seed) if seed is not None else None,
+            lengths={str(name): int(length) for name, length in (data.get('lengths') or {}).items()},
+            release_cutoff=int(data.get('release_cutoff', DEFAULT_RELEASE_CUTOFF)),
+            max_complex_size=int(data.get('max_complex_size', 6)),
+            max_species=min(int(data.get('max_species', 200)), 500))
+
+    def tasks(self) -> List[Dict]:
+        """One simulation per grid point, rows over the second parameter and columns over the first"""
+        x = self.parameters[0]
+        y = self.parameters[1] if len(self.parameters) > 1 else None
+        return [dict(self.base, settings=[(x.kind, x.target, x_value)] + ([(y.kind, y.target, y_value)] if y else []))
+                for y_value in (y.values if y else [None]) for x_value in x.values]
+
+    def run(self) -> SweepResult:
+        tasks = self.tasks()
+        if self.workers == 1 or len(tasks) == 1:
+            outcomes = [_guarded(task) for task in tasks]
+        else:
+            with ProcessPoolExecutor(max_workers=min(self.workers, len(tasks)),
+                                     mp_context=process_context()) as executor:
+                futures = [executor.submit(_guarded, task) for task in tasks]
+                # Worker processes cannot see the caller's context, so it is polled here; on cancellation
+                # or Ctrl-C the points not yet started are dropped rather than run before the pool closes
+                caller, pending = current(), set(futures)
+                try:
+                    while pending:
+                        _, pending = wait(pending, timeout=CANCEL_POLL_SECONDS)
+                        if pending and caller is not None:
+                            caller.check()
+                except BaseException:
+                    executor.shutdown(wait=False, cancel_futures=True)
+                    raise
+                outcomes = [future.result() for future in futures]
+        columns = len(self.parameters[0].values)
+        values = [value for value, _ in outcomes]
+        errors = sorted({error for _, error in outcomes if error})
+        if errors and all(value is None for value in values):
+            raise ValueError(errors[0])
+        grid = [values[k:k + columns] for k in range(0, len(values), columns)]
+        return SweepResult(self.parameters[0], self.parameters[1] if len(self.parameters) > 1 else None,
+                           self.base['output'], self.base['metric'], grid, errors)
+
+
+def run_task(task: Dict) -> Dict:
+    """One point of a sweep with its settings, value and any error, as a job runs it"""
+    value, error = _guarded(task)
+    return {'settings': [list(setting) for setting in task['settings']], 'value': value, 'error': error}
+
+
+def _guarded(task: Dict):
+    try:
+        return _run_point(task), ''
+    except ValueError as e:
+        return None, str(e)
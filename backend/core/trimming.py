@@ -0,0 +1,121 @@
+from dataclasses import dataclass
+from typing import Dict, Iterable, List, Optional, Tuple
+from .seqio import FastqRecord
+from .sequence import clean_sequence
+
+
+@dataclass
+class TrimSettings:
+    adapters: Tuple[str, ...] = ()
+    min_adapter_overlap: int = 3     # shortest adapter prefix trimmed at the read's 3' end
+    max_adapter_error_rate: float = 0.1
+    window: int = 4                  # sliding-window quality trimming
+    min_window_quality: float = 20.0
+    min_length: int = 30
+    max_length: Optional[int] = None
+
+
+def find_adapter(sequence: str, adapter: str, min_overlap: int = 3, max_error_rate: float = 0.1) -> Optional[int]:
+    """Start of the leftmost adapter occurrence, allowing mismatches; partial adapters count at the 3' end"""
+    adapter = clean_sequence(adapter)
+    if not adapter:
+        return None
+    for start in range(len(sequence) - min_overlap + 1):
+        length = min(len(adapter), len(sequence) - start)
+        allowed = int(length * max_error_rate)
+        mismatches = 0
+        for x, y in zip(sequence[start:start + length], adapter):
+            if x != y and 'N' not in (x, y):
+                mismatches += 1
+                if mismatches > allowed:
+                    break
+        else:
+            return start
+    return None
+
+
+def quality_trim_end(qualities: List[int], window: int = 4, min_quality: float = 20.0) -> int:
+    """Length to keep: the read is cut at the first window whose mean quality drops below min_quality"""
+    if len(qualities) < window:
+        return len(qualities) if qualities and sum(qualities) / len(qualities) >= min_quality else 0
+    total = sum(qualities[:window])
+    for start in range(len(qualities) - window + 1):
+        if start:
+            total += qualities[start + window - 1] - qualities[start - 1]
+        if total / window < min_quality:
+            # Keep the good bases at the start of the failing window
+            keep = start
+            while keep < start + window and qualities[keep] >= min_quality:
+                keep += 1
+            return keep
+    return len(qualities)
+
+
+def trim_read(record: FastqRecord, settings: TrimSettings) -> Tuple[Optional[FastqRecord], Dict]:
+    """Adapter and quality trim one read; returns (trimmed read or None when filtered, details)"""
+    sequence, quality = record.sequence, record.quality
+    details = {'name': record.name, 'input_length': len(sequence), 'adapter': None}
+
+    for adapter in settings.adapters:
+        start = find_adapter(sequence, adapter, settings.min_adapter_overlap, settings.max_adapter_error_rate)
+        if start is not None:
+            sequence, quality = sequence[:start], quality[:start]
+            details['adapter'] = adapter
+            break
+
+    keep = quality_trim_end([ord(c) - 33 for c in quality], settings.window, settings.min_window_quality)
+    sequence, quality = sequence[:keep], quality[:keep]
+    details['output_length'] = len(sequence)
+
+    too_short = len(sequence) < settings.min_length
+    too_long = settings.max_length is not None and len(sequence) > settings.max_length
+    details['kept'] = not (too_short or too_long)
+    if not details['kept']:
+        details['reason'] = 'too_short' if too_short else 'too_long'
+        return None, details
+    return FastqRecord(record.name, sequence, quality), details
+
+
+def read_stats(records: Iterable[FastqRecord]) -> Dict:
+    """Read count, base count, length range, mean quality, Q30 fraction and GC content"""
+    reads = bases = gc = q30 = quality_sum = 0
+    shortest, longest = None, 0
+    for record in records:
+        reads += 1
+        bases += len(record.sequence)
+        gc += record.sequence.count('G') + record.sequence.count('C')
+        qualities = record.qualities
+        quality_sum += sum(qualities)
+        q30 += sum(1 for q in qualities if q >= 30)
+        shortest = len(record.sequence) if shortest is None else min(shortest, len(record.sequence))
+        longest = max(longest, len(record.sequence))
+    return {
+        'reads': reads,
+        'bases': bases,
+        'min_length': shortest or 0,
+        'max_length': longest,
+        'mean_length': round(bases / reads, 2) if reads else 0.0,
+        'mean_quality': round(quality_sum / bases, 2) if bases else 0.0,
+        'q30_fraction': round(q30 / bases, 4) if bases else 0.0,
+        'gc_content': round(gc / bases * 100, 2) if bases else 0.0
+    }
+
+
+def trim_reads(records: Iterable[FastqRecord], settings: TrimSettings) -> Tuple[List[FastqRecord], Dict]:
+    """Trim and filter reads; the report has before/after stats and why reads were dropped"""
+    records = list(records)
+    kept, adapters_found, reasons = [], 0, {}
+    for record in records:
+        trimmed, details = trim_read(record, settings)
+        if details['adapter']:
+            adapters_found += 1
+        if trimmed is None:
+            reasons[details['reason']] = reasons.get(details['reason'], 0) + 1
+        else:
+            kept.append(trimmed)
+    return kept, {
+        'before': read_stats(records),
+        'after': read_stats(kept),
+        'adapter_trimmed': adapters_found,
+        'dropped': reasons
+    }
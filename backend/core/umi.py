@@ -0,0 +1,108 @@
+from typing import Dict, Iterable, List, Optional, Tuple
+from .mapper import Hit
+from .seqio import FastqRecord
+
+UMI_SEPARATOR = '_'
+
+
+class UmiPattern:
+    """Read-prefix layout marking UMI bases (N) and bases kept on the read (X)
+
+    'NNNNNNNN' takes an 8 nt UMI off the start of each read; 'NNNXXNNN' takes six UMI bases
+    around two spacer bases that stay on the read. Bases after the pattern are untouched.
+    """
+
+    def __init__(self, pattern: str):
+        self.pattern = pattern.upper()
+        if not self.pattern or set(self.pattern) - {'N', 'X'}:
+            raise ValueError(f'Invalid UMI pattern "{pattern}"; use N for UMI bases and X for kept bases')
+        if 'N' not in self.pattern:
+            raise ValueError('UMI pattern needs at least one N')
+        self.umi_positions = [i for i, char in enumerate(self.pattern) if char == 'N']
+        self.kept_positions = [i for i, char in enumerate(self.pattern) if char == 'X']
+
+    def __len__(self) -> int:
+        return len(self.pattern)
+
+    def extract(self, record: FastqRecord) -> Optional[Tuple[str, FastqRecord]]:
+        """(UMI, read without its UMI bases), or None for reads shorter than the pattern"""
+        if len(record.sequence) < len(self.pattern):
+            return None
+        sequence, quality = record.sequence, record.quality
+        umi = ''.join(sequence[i] for i in self.umi_positions)
+        rest = slice(len(self.pattern), None)
+        kept_sequence = ''.join(sequence[i] for i in self.kept_positions) + sequence[rest]
+        kept_quality = ''.join(quality[i] for i in self.kept_positions) + quality[rest]
+        return umi, FastqRecord(record.name, kept_sequence, kept_quality)
+
+
+def tag_read(record: FastqRecord, umi: str, separator: str = UMI_SEPARATOR) -> FastqRecord:
+    """Read with the UMI appended to its ID ('read1 comment' -> 'read1_ACGT comment'), as SAM QNAMEs keep it"""
+    parts = record.name.split(None, 1)
+    name = f'{parts[0]}{separator}{umi}' + (f' {parts[1]}' if len(parts) > 1 else '')
+    return FastqRecord(name, record.sequence, record.quality)
+
+
+def read_umi(name: str, separator: str = UMI_SEPARATOR) -> str:
+    """UMI from a tagged read ID, or '' when the read is untagged"""
+    read_id = name.split(None, 1)[0] if name.strip() else ''
+    return read_id.rsplit(separator, 1)[1] if separator in read_id else ''
+
+
+def extract_umis(records: Iterable[FastqRecord], pattern: str,
+                 separator: str = UMI_SEPARATOR) -> Tuple[List[FastqRecord], Dict]:
+    """Tagged reads with their UMIs moved into the read IDs, and counts of reads, distinct UMIs and too-short reads"""
+    layout = UmiPattern(pattern)
+    tagged, umis, too_short = [], set(), 0
+    for record in records:
+        extracted = layout.extract(record)
+        if extracted is None:
+            too_short += 1
+            continue
+        umi, trimmed = extracted
+        umis.add(umi)
+        tagged.append(tag_read(trimmed, umi, separator))
+    return tagged, {'reads': len(tagged), 'distinct_umis': len(umis), 'too_short': too_short}
+
+
+def five_prime_position(hit: Hit) -> int:
+    """Reference coordinate of the read's 5' end: leftmost base on '+', last aligned base on '-'"""
+    return hit.position if hit.strand == '+' else hit.end - 1
+
+
+def deduplicate(mapped: Iterable[Tuple[FastqRecord, Optional[Hit]]],
+                separator: str = UMI_SEPARATOR) -> Tuple[List[Tuple[FastqRecord, Hit]], Dict]:
+    """Keep one read per (reference, strand, 5' position, UMI), preferring the highest-scoring hit
+
+    Unmapped reads are dropped. The report gives the UMI-aware duplication rate alongside the
+    position-only rate, whose difference is the PCR-duplicate overcount UMIs avoid.
+    """
+    best: Dict[Tuple, Tuple[FastqRecord, Hit]] = {}
+    positions = set()
+    reads = unmapped = 0
+    for record, hit in mapped:
+        reads += 1
+        if hit is None:
+            unmapped += 1
+            continue
+        position = (hit.reference, hit.strand, five_prime_position(hit))
+        positions.add(position)
+        key = position + (read_umi(record.name, separator),)
+        if key not in best or (hit.score, hit.mapq) > (best[key][1].score, best[key][1].mapq):
+            best[key] = (record, hit)
+
+    mapped_reads = reads - unmapped
+    kept = sorted(best.values(), key=lambda item: (item[1].reference, item[1].position, item[1].strand))
+
+    def rate(unique: int) -> float:
+        return round(1 - unique / mapped_reads, 4) if mapped_reads else 0.0
+
+    return kept, {
+        'reads': reads,
+        'unmapped': unmapped,
+        'mapped': mapped_reads,
+        'unique': len(kept),
+        'duplicates': mapped_reads - len(kept),
+        'duplication_rate': rate(len(kept)),
+        'position_only_duplication_rate': rate(len(positions))
+    }
@@ -0,0 +1,157 @@
+import math
+from collections import Counter
+from dataclasses import dataclass, field
+from typing import Dict, Iterable, List, Optional, Tuple
+from .cigar import parse_cigar
+from .mapper import Hit
+from .seqio import SequenceRecord
+from .sequence import clean_sequence, reverse_complement
+
+DELETION = '*'
+
+
+@dataclass
+class PileupColumn:
+    """Reads covering one reference position; bases counts read bases, with '*' for deletions"""
+    position: int
+    reference_base: str
+    bases: Counter = field(default_factory=Counter)
+    insertions: Counter = field(default_factory=Counter)  # inserted sequence after this position
+
+    @property
+    def depth(self) -> int:
+        return sum(self.bases.values())
+
+
+@dataclass
+class Variant:
+    """VCF-style variant; position is 1-based as in VCF"""
+    chrom: str
+    position: int
+    ref: str
+    alt: str
+    depth: int
+    alt_count: int
+    quality: float = 0.0
+
+    @property
+    def frequency(self) -> float:
+        return self.alt_count / self.depth if self.depth else 0.0
+
+
+def pileup(reference: SequenceRecord, mapped: Iterable[Tuple[str, Optional[Hit]]]) -> List[PileupColumn]:
+    """Pileup of (read sequence, hit) pairs on one reference; other references' hits are skipped"""
+    sequence = clean_sequence(reference.sequence)
+    columns = [PileupColumn(i, base) for i, base in enumerate(sequence)]
+
+    for read, hit in mapped:
+        if hit is None or hit.reference != reference.name:
+            continue
+        read = clean_sequence(read)
+        if hit.strand == '-':
+            read = reverse_complement(read)
+
+        ref_pos, read_pos = hit.position, 0
+        for count, op in parse_cigar(hit.cigar):
+            if op in 'M=X':
+                for offset in range(count):
+                    if 0 <= ref_pos + offset < len(columns):
+                        columns[ref_pos + offset].bases[read[read_pos + offset]] += 1
+                ref_pos += count
+                read_pos += count
+            elif op == 'I':
+                if 0 < ref_pos <= len(columns):
+                    columns[ref_pos - 1].insertions[read[read_pos:read_pos + count]] += 1
+                read_pos += count
+            elif op in 'DN':
+                for offset in range(count):
+                    if 0 <= ref_pos + offset < len(columns):
+                        columns[ref_pos + offset].bases[DELETION] += 1
+                ref_pos += count
+            elif op == 'S':
+                read_pos += count
+    return columns
+
+
+def coverage(columns: List[PileupColumn]) -> List[int]:
+    """Per-position read depth (deletions count as covering)"""
+    return [column.depth for column in columns]
+
+
+def coverage_summary(depths: List[int]) -> Dict:
+    covered = sum(1 for depth in depths if depth)
+    return {
+        'length': len(depths),
+        'mean_depth': round(sum(depths) / len(depths), 2) if depths else 0.0,
+        'max_depth': max(depths, default=0),
+        'covered_fraction': round(covered / len(depths), 4) if depths else 0.0
+    }
+
+
+def call_variants(chrom: str, columns: List[PileupColumn], min_depth: int = 10,
+                  min_frequency: float = 0.2) -> List[Variant]:
+    """Majority-vote calls: the most common non-reference allele at each sufficiently covered position
+
+    SNVs and deletions come from the pileup bases, insertions from inserted sequence after a
+    position. Deletions and insertions are written VCF-style, anchored on the preceding base.
+    QUAL is a simple Phred-scaled confidence from the allele frequency.
+    """
+    variants = []
+    for i, column in enumerate(columns):
+        depth = column.depth
+        if depth < min_depth:
+            continue
+
+        alternatives = [(base, count) for base, count in column.bases.items() if base != column.reference_base]
+        if alternatives:
+            base, count = max(alternatives, key=lambda item: item[1])
+            if count / depth >= min_frequency:
+                if base == DELETION:
+                    if i > 0:
+                        anchor = columns[i - 1].reference_base
+                        variants.append(Variant(chrom, i, anchor + column.reference_base, anchor, depth, count))
+                else:
+                    variants.append(Variant(chrom, i + 1, column.reference_base, base, depth, count))
+
+        if column.insertions:
+            inserted, count = column.insertions.most_common(1)[0]
+            if count / depth >= min_frequency:
+                variants.append(Variant(chrom, i + 1, column.reference_base, column.reference_base + inserted,
+                                        depth, count))
+
+    for variant in variants:
+        error = max(1 - variant.frequency, 1e-6)
+        variant.quality = round(min(-10 * math.log10(error), 99.0), 1)
+    return _merge_deletions(variants)
+
+
+def _merge_deletions(variants: List[Variant]) -> List[Variant]:
+    """Join adjacent single-base deletions into one multi-base deletion record"""
+    merged = []
+    for variant in variants:
+        previous = merged[-1] if merged else None
+        if (previous and len(variant.alt) == 1 and len(variant.ref) == 2 and len(previous.ref) > 1
+                and len(previous.alt) == 1 and previous.chrom == variant.chrom
+                and previous.position + len(previous.ref) - 1 == variant.position):
+            previous.ref += variant.ref[1:]
+            previous.alt_count = min(previous.alt_count, variant.alt_count)
+            continue
+        merged.append(variant)
+    return merged
+
+
+def format_vcf(variants: List[Variant], references: List[SequenceRecord], source: str = 'robin') -> str:
+    """VCF 4.2 text with DP and AF info fields"""
+    lines = [
+        '##fileformat=VCFv4.2',
+        f'##source={source}',
+        *[f'##contig=<ID={r.name.split()[0]},length={len(r.sequence)}>' for r in references],
+        '##INFO=<ID=DP,Number=1,Type=Integer,Description="Read depth">',
+        '##INFO=<ID=AC,Number=A,Type=Integer,Description="Reads supporting the alternate allele">',
+        '##INFO=<ID=AF,Number=A,Type=Float,Description="Alternate allele frequency">',
+        '#CHROM\tPOS\tID\tREF\tALT\tQUAL\tFILTER\tINFO'
+    ]
+    for v in sorted(variants, key=lambda v: (v.chrom, v.position)):
+        lines.append(f'{v.chrom.split()[0]}\t{v.position}\t.\t{v.ref}\t{v.alt}\t{v.quality}\tPASS\t'
+                     f'DP={v.depth};AC={v.alt_count};AF={v.frequency:.3f}')
+    return '\n'.join(lines) + '\n'
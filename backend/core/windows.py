@@ -0,0 +1,46 @@
+from typing import Iterator, Tuple, Union
+
+Sequence = Union[str, bytes, bytearray, memoryview]
+
+
+def _view(sequence: Sequence) -> Sequence:
+    """Byte buffers as a memoryview, whose slices share the buffer instead of copying it"""
+    return memoryview(sequence) if isinstance(sequence, (bytes, bytearray)) else sequence
+
+
+def spans(length: int, size: int, step: int, start: int = 0, partial: bool = False) -> Iterator[Tuple[int, int]]:
+    """(start, end) of pieces of the given size every step bases; partial keeps a short last piece"""
+    if size < 1 or step < 1:
+        raise ValueError('size and step must be positive')
+    if start < 0:
+        raise ValueError('start must not be negative')
+    last = length if partial else length - size + 1
+    for position in range(start, max(last, start), step):
+        yield position, min(position + size, length)
+
+
+def windows(sequence: Sequence, k: int, step: int = 1) -> Iterator[Tuple[int, Sequence]]:
+    """(start, window) for every full window of k bases, every step bases
+
+    Iterating yields windows one at a time, so a scan over a chromosome never holds more than
+    one; bytes and bytearray sequences give memoryview windows that copy nothing.
+    """
+    view = _view(sequence)
+    for start, end in spans(len(view), k, step):
+        yield start, view[start:end]
+
+
+def codons(sequence: Sequence, frame: int = 0) -> Iterator[Tuple[int, Sequence]]:
+    """(start, codon) for every whole codon of a reading frame (0, 1 or 2)"""
+    if frame not in (0, 1, 2):
+        raise ValueError('frame must be 0, 1 or 2')
+    view = _view(sequence)
+    for start, end in spans(len(view), 3, 3, frame):
+        yield start, view[start:end]
+
+
+def chunks(sequence: Sequence, n: int) -> Iterator[Tuple[int, Sequence]]:
+    """(start, chunk) for consecutive pieces of n bases; the last one may be shorter"""
+    view = _view(sequence)
+    for start, end in spans(len(view), n, n, partial=True):
+        yield start, view[start:end]
@@ -0,0 +1,529 @@
+import json
+import sqlite3
+import uuid
+from abc import ABC, abstractmethod
+from contextlib import contextmanager
+from dataclasses import dataclass, field
+from datetime import datetime
+from typing import Dict, Iterator, List, Optional, Tuple
+from .checksum import circular_seguid, seguid
+from .diff import EditScript, diff, patch
+
+
+@dataclass
+class AnalysisRecord:
+    """Stored result of running an operation on a saved sequence"""
+    id: str
+    sequence_id: str
+    operation: str
+    params: Dict
+    result: Dict
+    created_at: str
+    manifest: Dict = field(default_factory=dict)  # version, parameters, seed and input hashes that produced result
+
+
+@dataclass
+class SequenceVersion:
+    """Immutable revision of a saved sequence, stored as a diff from the previous version"""
+    sequence_id: str
+    version: int
+    diff: str
+    author: str
+    created_at: str
+    length: int = 0
+    substituted: int = 0
+    inserted: int = 0
+    deleted: int = 0
+
+
+@dataclass
+class SavedSequence:
+    """Named sequence saved in the workspace"""
+    id: str
+    name: str
+    sequence: str
+    owner_id: str = ""
+    description: str = ""
+    created_at: str = ""
+    updated_at: str = ""
+    seguid: str = ""
+    circular_seguid: str = ""  # the same for every rotation and strand of a circular sequence
+    analyses: List[AnalysisRecord] = field(default_factory=list)
+
+
+@dataclass
+class Project:
+    """Named collection of an owner's saved sequences, optionally shared read-only"""
+    id: str
+    name: str
+    owner_id: str = ""
+    description: str = ""
+    created_at: str = ""
+    share_id: str = ""  # non-empty while a share link is active; replacing it revokes older links
+    sequence_ids: List[str] = field(default_factory=list)
+
+
+@dataclass
+class StoredArtifact:
+    """Output of a pipeline step kept in the workspace, e.g. trimmed reads, alignments or variant calls"""
+    id: str
+    name: str
+    format: str  # fasta, fastq, sam, pileup, vcf or json
+    owner_id: str = ""
+    pipeline: str = ""
+    job_id: str = ""
+    summary: Dict = field(default_factory=dict)
+    size: int = 0
+    created_at: str = ""
+    content: Optional[str] = None  # left out of listings
+
+
+class SequenceStore(ABC):
+    """Storage backend for saved sequences and their analysis results
+
+    Every sequence belongs to an owner (a session or user id); lookups only see the owner's sequences.
+    Each change to a sequence's bases is kept as a numbered version, so edits can be audited and
+    reverted; reverting adds a new version rather than rewriting history.
+    """
+
+    @abstractmethod
+    def create(self, owner_id: str, name: str, sequence: str, description: str = "",
+               author: str = "") -> SavedSequence:
+        """Save a new sequence as its version 1"""
+
+    @abstractmethod
+    def get(self, owner_id: str, sequence_id: str) -> Optional[SavedSequence]:
+        """Fetch a sequence with its analyses"""
+
+    @abstractmethod
+    def list(self, owner_id: str) -> List[SavedSequence]:
+        """All of an owner's saved sequences (without analyses), sorted by name"""
+
+    @abstractmethod
+    def update(self, owner_id: str, sequence_id: str, author: str = "", **changes) -> Optional[SavedSequence]:
+        """Update name, sequence and/or description; a changed sequence becomes a new version"""
+
+    @abstractmethod
+    def delete(self, owner_id: str, sequence_id: str) -> bool:
+        """Delete a sequence and its analyses"""
+
+    @abstractmethod
+    def duplicates(self, owner_id: str, sequence: str, exclude_id: str = "") -> List[Dict]:
+        """An owner's saved sequences identical to sequence ('exact') or to a rotation or reverse complement
+        of it as a circular molecule ('circular'), matched by checksum"""
+
+    @abstractmethod
+    def versions(self, owner_id: str, sequence_id: str) -> Optional[List[SequenceVersion]]:
+        """A sequence's versions, oldest first, or None when the sequence does not exist"""
+
+    @abstractmethod
+    def version_sequence(self, owner_id: str, sequence_id: str, version: int) -> Optional[str]:
+        """The sequence as it was at a version, or None when either does not exist"""
+
+    def revert(self, owner_id: str, sequence_id: str, version: int, author: str = "") -> Optional[SavedSequence]:
+        """Restore the sequence of an earlier version as a new version"""
+        sequence = self.version_sequence(owner_id, sequence_id, version)
+        if sequence is None:
+            return None
+        return self.update(owner_id, sequence_id, author, sequence=sequence)
+
+    @abstractmethod
+    def add_analysis(self, sequence_id: str, operation: str, params: Dict, result: Dict,
+                     manifest: Optional[Dict] = None) -> AnalysisRecord:
+        """Store the result of an operation run on a sequence, with the manifest to reproduce it"""
+
+    @abstractmethod
+    def create_project(self, owner_id: str, name: str, description: str = "") -> Project:
+        """Start an empty project"""
+
+    @abstractmethod
+    def get_project(self, owner_id: str, project_id: str) -> Optional[Project]:
+        """Fetch a project with its sequence ids"""
+
+    @abstractmethod
+    def list_projects(self, owner_id: str) -> List[Project]:
+        """All of an owner's projects, sorted by name"""
+
+    @abstractmethod
+    def delete_project(self, owner_id: str, project_id: str) -> bool:
+        """Delete a project; its sequences stay in the workspace"""
+
+    @abstractmethod
+    def set_membership(self, owner_id: str, project_id: str, sequence_id: str, member: bool) -> Optional[Project]:
+        """Add a sequence to (or remove it from) a project; both must belong to the owner"""
+
+    @abstractmethod
+    def set_share(self, owner_id: str, project_id: str, shared: bool) -> Optional[Project]:
+        """Issue a new share id (revoking any earlier one), or clear it to stop sharing"""
+
+    @abstractmethod
+    def shared_project(self, project_id: str, share_id: str) -> Optional[Tuple[Project, List[SavedSequence]]]:
+        """A shared project with its sequences and analyses, for anyone holding its current share id"""
+
+    @abstractmethod
+    def add_artifact(self, owner_id: str, name: str, format: str, content: str, pipeline: str = "",
+                     job_id: str = "", summary: Optional[Dict] = None) -> StoredArtifact:
+        """Keep a pipeline step's output"""
+
+    @abstractmethod
+    def get_artifact(self, owner_id: str, artifact_id: str) -> Optional[StoredArtifact]:
+        """Fetch an artifact with its content"""
+
+    @abstractmethod
+    def list_artifacts(self, owner_id: str, job_id: str = "") -> List[StoredArtifact]:
+        """An owner's artifacts (without content), newest first, optionally only those of one job"""
+
+    @abstractmethod
+    def delete_artifact(self, owner_id: str, artifact_id: str) -> bool:
+        """Delete an artifact"""
+
+
+class SQLiteSequenceStore(SequenceStore):
+    """SequenceStore backed by a SQLite database file"""
+
+    EDITABLE_FIELDS = ('name', 'sequence', 'description')
+
+    def __init__(self, path: str = 'workspace.db'):
+        self.path = path
+        with self._connect() as conn:
+            conn.executescript("""
+                CREATE TABLE IF NOT EXISTS sequences (
+                    id TEXT PRIMARY KEY,
+                    owner_id TEXT NOT NULL DEFAULT '',
+                    name TEXT NOT NULL,
+                    sequence TEXT NOT NULL,
+                    description TEXT NOT NULL DEFAULT '',
+                    created_at TEXT NOT NULL,
+                    updated_at TEXT NOT NULL,
+                    seguid TEXT NOT NULL DEFAULT '',
+                    circular_seguid TEXT NOT NULL DEFAULT ''
+                );
+                CREATE TABLE IF NOT EXISTS analyses (
+                    id TEXT PRIMARY KEY,
+                    sequence_id TEXT NOT NULL REFERENCES sequences(id) ON DELETE CASCADE,
+                    operation TEXT NOT NULL,
+                    params TEXT NOT NULL,
+                    result TEXT NOT NULL,
+                    created_at TEXT NOT NULL,
+                    manifest TEXT NOT NULL DEFAULT '{}'
+                );
+                CREATE INDEX IF NOT EXISTS analyses_sequence ON analyses(sequence_id);
+                CREATE TABLE IF NOT EXISTS versions (
+                    sequence_id TEXT NOT NULL REFERENCES sequences(id) ON DELETE CASCADE,
+                    version INTEGER NOT NULL,
+                    diff TEXT NOT NULL,
+                    author TEXT NOT NULL DEFAULT '',
+                    created_at TEXT NOT NULL,
+                    PRIMARY KEY (sequence_id, version)
+                );
+                CREATE TABLE IF NOT EXISTS projects (
+                    id TEXT PRIMARY KEY,
+                    owner_id TEXT NOT NULL,
+                    name TEXT NOT NULL,
+                    description TEXT NOT NULL DEFAULT '',
+                    share_id TEXT NOT NULL DEFAULT '',
+                    created_at TEXT NOT NULL
+                );
+                CREATE INDEX IF NOT EXISTS projects_owner ON projects(owner_id);
+                CREATE TABLE IF NOT EXISTS artifacts (
+                    id TEXT PRIMARY KEY,
+                    owner_id TEXT NOT NULL,
+                    name TEXT NOT NULL,
+                    format TEXT NOT NULL,
+                    content TEXT NOT NULL,
+                    pipeline TEXT NOT NULL DEFAULT '',
+                    job_id TEXT NOT NULL DEFAULT '',
+                    summary TEXT NOT NULL DEFAULT '{}',
+                    created_at TEXT NOT NULL
+                );
+                CREATE INDEX IF NOT EXISTS artifacts_owner ON artifacts(owner_id, created_at);
+                CREATE TABLE IF NOT EXISTS project_sequences (
+                    project_id TEXT NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+                    sequence_id TEXT NOT NULL REFERENCES sequences(id) ON DELETE CASCADE,
+                    PRIMARY KEY (project_id, sequence_id)
+                );
+            """)
+            # Databases created before sequences had owners
+            columns = [row['name'] for row in conn.execute('PRAGMA table_info(sequences)')]
+            if 'owner_id' not in columns:
+                conn.execute("ALTER TABLE sequences ADD COLUMN owner_id TEXT NOT NULL DEFAULT ''")
+            conn.execute('CREATE INDEX IF NOT EXISTS sequences_owner ON sequences(owner_id)')
+            # ... and before they had checksums
+            if 'seguid' not in columns:
+                conn.execute("ALTER TABLE sequences ADD COLUMN seguid TEXT NOT NULL DEFAULT ''")
+                conn.execute("ALTER TABLE sequences ADD COLUMN circular_seguid TEXT NOT NULL DEFAULT ''")
+            for row in conn.execute("SELECT id, sequence FROM sequences WHERE seguid = ''").fetchall():
+                conn.execute('UPDATE sequences SET seguid = ?, circular_seguid = ? WHERE id = ?',
+                             (seguid(row['sequence']), circular_seguid(row['sequence']), row['id']))
+            conn.execute('CREATE INDEX IF NOT EXISTS sequences_seguid ON sequences(owner_id, seguid)')
+            conn.execute('CREATE INDEX IF NOT EXISTS sequences_circular_seguid ON sequences(owner_id, circular_seguid)')
+            # ... and before analyses kept manifests
+            if 'manifest' not in [row['name'] for row in conn.execute('PRAGMA table_info(analyses)')]:
+                conn.execute("ALTER TABLE analyses ADD COLUMN manifest TEXT NOT NULL DEFAULT '{}'")
+
+    @contextmanager
+    def _connect(self) -> Iterator[sqlite3.Connection]:
+        """Open a connection, committing on success and always closing it"""
+        conn = sqlite3.connect(self.path)
+        conn.row_factory = sqlite3.Row
+        conn.execute('PRAGMA foreign_keys = ON')
+        try:
+            with conn:
+                yield conn
+        finally:
+            conn.close()
+
+    @staticmethod
+    def _to_sequence(row: sqlite3.Row) -> SavedSequence:
+        return SavedSequence(
+            id=row['id'], name=row['name'], sequence=row['sequence'], owner_id=row['owner_id'],
+            description=row['description'],
+            created_at=row['created_at'], updated_at=row['updated_at'],
+            seguid=row['seguid'], circular_seguid=row['circular_seguid']
+        )
+
+    @staticmethod
+    def _to_analysis(row: sqlite3.Row) -> AnalysisRecord:
+        return AnalysisRecord(
+            id=row['id'], sequence_id=row['sequence_id'], operation=row['operation'],
+            params=json.loads(row['params']), result=json.loads(row['result']), created_at=row['created_at'],
+            manifest=json.loads(row['manifest'])
+        )
+
+    @staticmethod
+    def _to_version(row: sqlite3.Row) -> SequenceVersion:
+        script = EditScript.from_text(row['diff'])
+        summary = script.summary()
+        return SequenceVersion(
+            sequence_id=row['sequence_id'], version=row['version'], diff=row['diff'], author=row['author'],
+            created_at=row['created_at'], length=script.target_length, substituted=summary['substituted'],
+            inserted=summary['inserted'], deleted=summary['deleted']
+        )
+
+    @staticmethod
+    def _add_version(conn: sqlite3.Connection, sequence_id: str, old: str, new: str, author: str, created_at: str):
+        """Append the diff from old to new as the sequence's next version"""
+        latest = conn.execute('SELECT MAX(version) FROM versions WHERE sequence_id = ?', (sequence_id,)).fetchone()[0]
+        conn.execute('INSERT INTO versions (sequence_id, version, diff, author, created_at) VALUES (?, ?, ?, ?, ?)',
+                     (sequence_id, (latest or 0) + 1, diff(old, new).to_text(), author, created_at))
+
+    @classmethod
+    def _ensure_history(cls, conn: sqlite3.Connection, row: sqlite3.Row):
+        """Give sequences saved before versioning their current state as version 1"""
+        if not conn.execute('SELECT 1 FROM versions WHERE sequence_id = ?', (row['id'],)).fetchone():
+            cls._add_version(conn, row['id'], '', row['sequence'], '', row['created_at'])
+
+    def create(self, owner_id: str, name: str, sequence: str, description: str = "",
+               author: str = "") -> SavedSequence:
+        now = datetime.now().isoformat()
+        saved = SavedSequence(id=str(uuid.uuid4()), name=name, sequence=sequence, owner_id=owner_id,
+                              description=description, created_at=now, updated_at=now,
+                              seguid=seguid(sequence), circular_seguid=circular_seguid(sequence))
+        with self._connect() as conn:
+            conn.execute(
+                'INSERT INTO sequences (id, owner_id, name, sequence, description, created_at, updated_at, seguid, '
+                'circular_seguid) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)',
+                (saved.id, saved.owner_id, saved.name, saved.sequence, saved.description,
+                 saved.created_at, saved.updated_at, saved.seguid, saved.circular_seguid)
+            )
+            self._add_version(conn, saved.id, '', sequence, author, now)
+        return saved
+
+    def get(self, owner_id: str, sequence_id: str) -> Optional[SavedSequence]:
+        with self._connect() as conn:
+            row = conn.execute('SELECT * FROM sequences WHERE id = ? AND owner_id = ?',
+                               (sequence_id, owner_id)).fetchone()
+            if not row:
+                return None
+            saved = self._to_sequence(row)
+            saved.analyses = [self._to_analysis(a) for a in conn.execute(
+                'SELECT * FROM analyses WHERE sequence_id = ? ORDER BY created_at', (sequence_id,))]
+        return saved
+
+    def list(self, owner_id: str) -> List[SavedSequence]:
+        with self._connect() as conn:
+            return [self._to_sequence(row) for row in conn.execute(
+                'SELECT * FROM sequences WHERE owner_id = ? ORDER BY name', (owner_id,))]
+
+    def update(self, owner_id: str, sequence_id: str, author: str = "", **changes) -> Optional[SavedSequence]:
+        changes = {key: value for key, value in changes.items() if key in self.EDITABLE_FIELDS}
+        if 'sequence' in changes:
+            changes.update(seguid=seguid(changes['sequence']), circular_seguid=circular_seguid(changes['sequence']))
+        if changes:
+            changes['updated_at'] = datetime.now().isoformat()
+            assignments = ', '.join(f'{key} = ?' for key in changes)
+            with self._connect() as conn:
+                row = conn.execute('SELECT * FROM sequences WHERE id = ? AND owner_id = ?',
+                                   (sequence_id, owner_id)).fetchone()
+                if not row:
+                    return None
+                if 'sequence' in changes and changes['sequence'] != row['sequence']:
+                    self._ensure_history(conn, row)
+                    self._add_version(conn, sequence_id, row['sequence'], changes['sequence'], author,
+                                      changes['updated_at'])
+                conn.execute(f'UPDATE sequences SET {assignments} WHERE id = ? AND owner_id = ?',
+                             (*changes.values(), sequence_id, owner_id))
+        return self.get(owner_id, sequence_id)
+
+    def delete(self, owner_id: str, sequence_id: str) -> bool:
+        with self._connect() as conn:
+            return conn.execute('DELETE FROM sequences WHERE id = ? AND owner_id = ?',
+                                (sequence_id, owner_id)).rowcount > 0
+
+    def duplicates(self, owner_id: str, sequence: str, exclude_id: str = "") -> List[Dict]:
+        exact = seguid(sequence)
+        with self._connect() as conn:
+            return [{'id': row['id'], 'name': row['name'], 'match': 'exact' if row['seguid'] == exact else 'circular'}
+                    for row in conn.execute(
+                        'SELECT id, name, seguid FROM sequences WHERE owner_id = ? AND id != ? '
+                        'AND (seguid = ? OR circular_seguid = ?) ORDER BY name',
+                        (owner_id, exclude_id, exact, circular_seguid(sequence)))]
+
+    def _version_rows(self, owner_id: str, sequence_id: str, up_to: int = None) -> Optional[List[sqlite3.Row]]:
+        with self._connect() as conn:
+            row = conn.execute('SELECT * FROM sequences WHERE id = ? AND owner_id = ?',
+                               (sequence_id, owner_id)).fetchone()
+            if not row:
+                return None
+            self._ensure_history(conn, row)
+            return conn.execute('SELECT * FROM versions WHERE sequence_id = ? AND version <= COALESCE(?, version) '
+                                'ORDER BY version', (sequence_id, up_to)).fetchall()
+
+    def versions(self, owner_id: str, sequence_id: str) -> Optional[List[SequenceVersion]]:
+        rows = self._version_rows(owner_id, sequence_id)
+        return None if rows is None else [self._to_version(row) for row in rows]
+
+    def version_sequence(self, owner_id: str, sequence_id: str, version: int) -> Optional[str]:
+        rows = self._version_rows(owner_id, sequence_id, version)
+        if not rows or rows[-1]['version'] != version:
+            return None
+        sequence = ''
+        for row in rows:
+            sequence = patch(sequence, EditScript.from_text(row['diff']))
+        return sequence
+
+    def add_analysis(self, sequence_id: str, operation: str, params: Dict, result: Dict,
+                     manifest: Optional[Dict] = None) -> AnalysisRecord:
+        record = AnalysisRecord(id=str(uuid.uuid4()), sequence_id=sequence_id, operation=operation,
+                                params=params, result=result, created_at=datetime.now().isoformat(),
+                                manifest=manifest or {})
+        with self._connect() as conn:
+            conn.execute(
+                'INSERT INTO analyses (id, sequence_id, operation, params, result, created_at, manifest) '
+                'VALUES (?, ?, ?, ?, ?, ?, ?)',
+                (record.id, record.sequence_id, record.operation, json.dumps(record.params),
+                 json.dumps(record.result), record.created_at, json.dumps(record.manifest))
+            )
+        return record
+
+    @staticmethod
+    def _load_project(conn: sqlite3.Connection, row: sqlite3.Row) -> Project:
+        return Project(
+            id=row['id'], name=row['name'], owner_id=row['owner_id'], description=row['description'],
+            created_at=row['created_at'], share_id=row['share_id'],
+            sequence_ids=[member['sequence_id'] for member in conn.execute(
+                'SELECT ps.sequence_id FROM project_sequences ps JOIN sequences s ON s.id = ps.sequence_id '
+                'WHERE ps.project_id = ? ORDER BY s.name', (row['id'],))]
+        )
+
+    def create_project(self, owner_id: str, name: str, description: str = "") -> Project:
+        project = Project(id=str(uuid.uuid4()), name=name, owner_id=owner_id, description=description,
+                          created_at=datetime.now().isoformat())
+        with self._connect() as conn:
+            conn.execute('INSERT INTO projects (id, owner_id, name, description, created_at) VALUES (?, ?, ?, ?, ?)',
+                         (project.id, project.owner_id, project.name, project.description, project.created_at))
+        return project
+
+    def get_project(self, owner_id: str, project_id: str) -> Optional[Project]:
+        with self._connect() as conn:
+            row = conn.execute('SELECT * FROM projects WHERE id = ? AND owner_id = ?',
+                               (project_id, owner_id)).fetchone()
+            return self._load_project(conn, row) if row else None
+
+    def list_projects(self, owner_id: str) -> List[Project]:
+        with self._connect() as conn:
+            return [self._load_project(conn, row) for row in conn.execute(
+                'SELECT * FROM projects WHERE owner_id = ? ORDER BY name', (owner_id,))]
+
+    def delete_project(self, owner_id: str, project_id: str) -> bool:
+        with self._connect() as conn:
+            return conn.execute('DELETE FROM projects WHERE id = ? AND owner_id = ?',
+                                (project_id, owner_id)).rowcount > 0
+
+    def set_membership(self, owner_id: str, project_id: str, sequence_id: str, member: bool) -> Optional[Project]:
+        with self._connect() as conn:
+            owned = conn.execute(
+                'SELECT 1 FROM projects p, sequences s '
+                'WHERE p.id = ? AND p.owner_id = ? AND s.id = ? AND s.owner_id = ?',
+                (project_id, owner_id, sequence_id, owner_id)).fetchone()
+            if not owned:
+                return None
+            if member:
+                conn.execute('INSERT OR IGNORE INTO project_sequences (project_id, sequence_id) VALUES (?, ?)',
+                             (project_id, sequence_id))
+            else:
+                conn.execute('DELETE FROM project_sequences WHERE project_id = ? AND sequence_id = ?',
+                             (project_id, sequence_id))
+        return self.get_project(owner_id, project_id)
+
+    def set_share(self, owner_id: str, project_id: str, shared: bool) -> Optional[Project]:
+        with self._connect() as conn:
+            conn.execute('UPDATE projects SET share_id = ? WHERE id = ? AND owner_id = ?',
+                         (uuid.uuid4().hex if shared else '', project_id, owner_id))
+        return self.get_project(owner_id, project_id)
+
+    def shared_project(self, project_id: str, share_id: str) -> Optional[Tuple[Project, List[SavedSequence]]]:
+        if not share_id:
+            return None
+        with self._connect() as conn:
+            row = conn.execute('SELECT * FROM projects WHERE id = ? AND share_id = ?',
+                               (project_id, share_id)).fetchone()
+            if not row:
+                return None
+            project = self._load_project(conn, row)
+        sequences = [self.get(project.owner_id, sequence_id) for sequence_id in project.sequence_ids]
+        return project, [saved for saved in sequences if saved]
+
+    @staticmethod
+    def _to_artifact(row: sqlite3.Row) -> StoredArtifact:
+        return StoredArtifact(
+            id=row['id'], name=row['name'], format=row['format'], owner_id=row['owner_id'],
+            pipeline=row['pipeline'], job_id=row['job_id'], summary=json.loads(row['summary']), size=row['size'],
+            created_at=row['created_at'], content=row['content'] if 'content' in row.keys() else None
+        )
+
+    def add_artifact(self, owner_id: str, name: str, format: str, content: str, pipeline: str = "",
+                     job_id: str = "", summary: Optional[Dict] = None) -> StoredArtifact:
+        artifact = StoredArtifact(id=str(uuid.uuid4()), name=name, format=format, owner_id=owner_id,
+                                  pipeline=pipeline, job_id=job_id, summary=summary or {}, size=len(content),
+                                  created_at=datetime.now().isoformat(), content=content)
+        with self._connect() as conn:
+            conn.execute(
+                'INSERT INTO artifacts (id, owner_id, name, format, content, pipeline, job_id, summary, created_at) '
+                'VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)',
+                (artifact.id, owner_id, name, format, content, pipeline, job_id,
+                 json.dumps(artifact.summary, default=str), artifact.created_at)
+            )
+        return artifact
+
+    def get_artifact(self, owner_id: str, artifact_id: str) -> Optional[StoredArtifact]:
+        with self._connect() as conn:
+            row = conn.execute('SELECT *, length(content) AS size FROM artifacts WHERE id = ? AND owner_id = ?',
+                               (artifact_id, owner_id)).fetchone()
+            return self._to_artifact(row) if row else None
+
+    def list_artifacts(self, owner_id: str, job_id: str = "") -> List[StoredArtifact]:
+        columns = 'id, owner_id, name, format, pipeline, job_id, summary, created_at, length(content) AS size'
+        with self._connect() as conn:
+            if job_id:
+                rows = conn.execute(f'SELECT {columns} FROM artifacts WHERE owner_id = ? AND job_id = ? '
+                                    'ORDER BY created_at', (owner_id, job_id))
+            else:
+                rows = conn.execute(f'SELECT {columns} FROM artifacts WHERE owner_id = ? ORDER BY created_at DESC',
+                                    (owner_id,))
+            return [self._to_artifact(row) for row in rows]
+
+    def delete_artifact(self, owner_id: str, artifact_id: str) -> bool:
+        with self._connect() as conn:
+            return conn.execute('DELETE FROM artifacts WHERE id = ? AND owner_id = ?',
+                                (artifact_id, owner_id)).rowcount > 0
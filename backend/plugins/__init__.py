@@ -0,0 +1,23 @@
+"""
+Analysis plugins
+
+Every module in this package is imported at startup. A module registers its analyses with
+core.registry.register, and each one then appears as a cli.py subcommand, POST /api/plugins/<name>,
+a batch-job operation and a panel on the Plugins tab:
+
+    from core.registry import Analysis, Param, register
+
+    @register
+    class Reverse(Analysis):
+        name = 'reverse'
+        title = 'Reverse'
+        description = 'Reverse a sequence without complementing it'
+        params = [Param('upper', 'boolean', True, 'Uppercase the result')]
+
+        def run(self, record, params):
+            sequence = record.sequence[::-1]
+            return {'name': record.name, 'sequence': sequence.upper() if params['upper'] else sequence}
+
+Plugins kept elsewhere are loaded from the modules named in ROBIN_PLUGINS, or from the robin.analyses
+entry point group of an installed package.
+"""
@@ -0,0 +1,31 @@
+from collections import Counter
+from typing import Dict
+from core.registry import Analysis, Param, register
+from core.seqio import SequenceRecord
+from core.sequence import CODON_TABLE
+from core.windows import codons
+
+
+@register
+class CodonUsage(Analysis):
+    """Codon counts of a coding sequence with each codon's share of its amino acid (RSCU-style fractions)"""
+    name = 'codon-usage'
+    title = 'Codon Usage'
+    description = 'Codon counts and the fraction of each amino acid encoded by each codon'
+    params = [
+        Param('frame', 'integer', 0, 'Reading frame', choices=[0, 1, 2]),
+        Param('stops', 'boolean', False, 'Include stop codons')
+    ]
+
+    def run(self, record: SequenceRecord, params: Dict) -> Dict:
+        sequence = record.sequence.upper().replace('U', 'T')
+        counts = Counter(codon for _, codon in codons(sequence, params['frame']))
+        counts = Counter({codon: count for codon, count in counts.items()
+                          if codon in CODON_TABLE and (params['stops'] or CODON_TABLE[codon] != '*')})
+        per_amino_acid = Counter()
+        for codon, count in counts.items():
+            per_amino_acid[CODON_TABLE[codon]] += count
+        return {'name': record.name, 'codons': sum(counts.values()), 'usage': [
+            {'codon': codon, 'amino_acid': CODON_TABLE[codon], 'count': count,
+             'fraction': round(count / per_amino_acid[CODON_TABLE[codon]], 3)}
+            for codon, count in sorted(counts.items(), key=lambda item: (CODON_TABLE[item[0]], item[0]))]}
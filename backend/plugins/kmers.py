@@ -0,0 +1,31 @@
+from collections import Counter
+from typing import Dict
+from core.registry import Analysis, Param, register
+from core.seqio import SequenceRecord
+from core.sequence import reverse_complement
+from core.windows import windows
+
+
+@register
+class KmerSpectrum(Analysis):
+    """Most frequent k-mers of a sequence, optionally counting a k-mer with its reverse complement"""
+    name = 'kmers'
+    title = 'K-mer Spectrum'
+    description = 'Most frequent k-mers and the number of distinct k-mers'
+    params = [
+        Param('k', 'integer', 4, 'K-mer length (1-12)'),
+        Param('top', 'integer', 10, 'How many of the most frequent k-mers to list'),
+        Param('canonical', 'boolean', False, 'Count each k-mer together with its reverse complement')
+    ]
+
+    def run(self, record: SequenceRecord, params: Dict) -> Dict:
+        k = params['k']
+        if not 1 <= k <= 12:
+            raise ValueError('k must be between 1 and 12')
+        sequence = record.sequence.upper()
+        counts = Counter()
+        for _, kmer in windows(sequence, k):
+            if set(kmer) <= set('ACGT'):
+                counts[min(kmer, reverse_complement(kmer)) if params['canonical'] else kmer] += 1
+        return {'name': record.name, 'total': sum(counts.values()), 'distinct': len(counts),
+                'top': [{'kmer': kmer, 'count': count} for kmer, count in counts.most_common(params['top'])]}
@@ -0,0 +1,106 @@
+#!/usr/bin/env python3
+"""
+robin gRPC server
+Serves the sequence operations defined in rpc/robin.proto (complement, translation, pairwise
+alignment, folding and ORF search) to clients that want typed access instead of the REST API
+"""
+
+import io
+import os
+import sys
+import argparse
+from concurrent import futures
+from typing import Tuple
+import grpc
+from core.align import ScoringScheme, global_align, local_align
+from core.fold import partition_function
+from core.seqio import read_sequences
+from core.sequence import complement, reverse_complement, translate, find_orfs
+from rpc import robin_pb2, robin_pb2_grpc
+
+DEFAULT_PORT = 50051
+
+
+class SequenceService(robin_pb2_grpc.SequenceServiceServicer):
+    """robin.SequenceService on top of the core sequence functions"""
+
+    def Complement(self, request, context):
+        operation = reverse_complement if request.reverse else complement
+        return robin_pb2.SequenceReply(sequence=operation(request.sequence))
+
+    def Translate(self, request, context):
+        if request.frame not in (0, 1, 2):
+            context.abort(grpc.StatusCode.INVALID_ARGUMENT, 'frame must be 0, 1 or 2')
+        return robin_pb2.TranslateReply(protein=translate(request.sequence, request.frame, request.to_stop))
+
+    def Align(self, request, context):
+        if not request.seq_a.strip() or not request.seq_b.strip():
+            context.abort(grpc.StatusCode.INVALID_ARGUMENT, 'Both seq_a and seq_b are required')
+        scoring = ScoringScheme()
+        if request.HasField('scoring'):
+            scoring = ScoringScheme(request.scoring.match, request.scoring.mismatch, request.scoring.gap)
+        align = local_align if request.local else global_align
+        alignment = align(request.seq_a, request.seq_b, scoring)
+        return robin_pb2.AlignReply(
+            aligned_a=alignment.aligned_a,
+            aligned_b=alignment.aligned_b,
+            score=alignment.score,
+            identity=alignment.identity,
+            start_a=alignment.start_a, end_a=alignment.end_a,
+            start_b=alignment.start_b, end_b=alignment.end_b
+        )
+
+    def Fold(self, request, context):
+        temperature = request.temperature if request.HasField('temperature') else 37.0
+        threshold = request.threshold if request.HasField('threshold') else 0.01
+        try:
+            ensemble = partition_function(request.sequence, temperature, request.constraint or None)
+        except ValueError as e:
+            context.abort(grpc.StatusCode.INVALID_ARGUMENT, str(e))
+        return robin_pb2.FoldReply(
+            ensemble_dg=ensemble.free_energy,
+            structure=ensemble.centroid(),
+            pairs=[robin_pb2.BasePair(i=i, j=j, probability=p)
+                   for (i, j), p in sorted(ensemble.probabilities.items()) if p >= threshold],
+            unpaired=ensemble.unpaired(),
+            temperature=temperature
+        )
+
+    def FindOrfs(self, request, context):
+        # Streamed so a chromosome's ORFs reach the client as messages rather than one huge reply
+        for orf in find_orfs(request.sequence, request.min_length or 30, not request.forward_only):
+            yield robin_pb2.Orf(start=orf['start'], end=orf['end'], strand=orf['strand'], frame=orf['frame'],
+                                length=orf['length'], protein=orf['protein'])
+
+    def ReverseComplementRecords(self, request, context):
+        for record in read_sequences(io.StringIO(request.fasta)):
+            yield robin_pb2.Record(name=record.name, sequence=reverse_complement(record.sequence))
+
+
+def serve(port: int = DEFAULT_PORT, workers: int = 4) -> Tuple[grpc.Server, int]:
+    """Start the server in background threads and return it with the port it listens on (port 0 picks a free one)
+
+    Call wait_for_termination() or stop() on the server.
+    """
+    server = grpc.server(futures.ThreadPoolExecutor(max_workers=workers))
+    robin_pb2_grpc.add_SequenceServiceServicer_to_server(SequenceService(), server)
+    port = server.add_insecure_port(f'[::]:{port}')
+    server.start()
+    return server, port
+
+
+def main(argv: list = None) -> int:
+    parser = argparse.ArgumentParser(prog='python -m rpc.server', description='robin gRPC server')
+    parser.add_argument('--port', type=int, default=int(os.environ.get('ROBIN_GRPC_PORT', DEFAULT_PORT)),
+                        help=f'Port to listen on, default: $ROBIN_GRPC_PORT or {DEFAULT_PORT}')
+    parser.add_argument('--workers', type=int, default=4, help='Worker threads, default: 4')
+    args = parser.parse_args(argv)
+
+    server, port = serve(args.port, args.workers)
+    print(f'robin gRPC server listening on port {port}', file=sys.stderr)
+    server.wait_for_termination()
+    return 0
+
+
+if __name__ == '__main__':
+    sys.exit(main())
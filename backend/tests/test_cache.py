@@ -0,0 +1,33 @@
+import pytest
+from flask import Flask
+from api import cache
+from api.cache import cache_bp
+from core.cache import LRUCache
+
+
+@pytest.fixture
+def client(monkeypatch):
+    monkeypatch.setattr(cache.analysis_cache, 'backend', LRUCache(8))
+    cache.analysis_cache.set('gc', 'ACGT', {}, {'success': True, 'gc_content': 50.0})
+    app = Flask(__name__)
+    app.register_blueprint(cache_bp, url_prefix='/api')
+    return app.test_client()
+
+
+def cached():
+    return cache.analysis_cache.get('gc', 'ACGT', {}) is not None
+
+
+def test_clearing_is_disabled_without_admin_token(client, monkeypatch):
+    monkeypatch.setattr(cache, 'ADMIN_TOKEN', '')
+    assert client.delete('/api/cache').status_code == 403
+    assert cached()
+
+
+def test_clearing_needs_the_admin_token(client, monkeypatch):
+    monkeypatch.setattr(cache, 'ADMIN_TOKEN', 'secret')
+    assert client.delete('/api/cache').status_code == 401
+    assert client.delete('/api/cache', headers={'Authorization': 'Bearer wrong'}).status_code == 401
+    assert cached()
+    assert client.delete('/api/cache', headers={'Authorization': 'Bearer secret'}).status_code == 200
+    assert not cached()
@@ -0,0 +1,36 @@
+import pytest
+from core.context import Cancelled, Context, using
+from core.diff import diff
+from core.hmm import ProfileHMM
+from core.phylo import neighbor_joining, upgma
+
+
+def _cancelled() -> Context:
+    context = Context()
+    context.cancel('Stopped by test')
+    return context
+
+
+def test_context_past_deadline_is_cancelled():
+    context = Context(timeout=1e-9)
+    with pytest.raises(Cancelled):
+        context.check()
+
+
+@pytest.mark.parametrize('build', [neighbor_joining, upgma])
+def test_tree_building_stops_when_cancelled(build):
+    names = ['a', 'b', 'c', 'd', 'e']
+    matrix = [[0.0 if i == j else 1.0 + i + j for j in range(5)] for i in range(5)]
+    with using(_cancelled()), pytest.raises(Cancelled):
+        build(names, matrix)
+
+
+def test_profile_hmm_stops_when_cancelled():
+    profile = ProfileHMM(['ACGTAC', 'ACGAAC', 'ACTTAC'])
+    with using(_cancelled()), pytest.raises(Cancelled):
+        profile.forward('ACGTAC')
+
+
+def test_diff_stops_when_cancelled():
+    with using(_cancelled()), pytest.raises(Cancelled):
+        diff('ACGTACGTAC', 'TTTTACGAAC')
@@ -0,0 +1,34 @@
+import json
+import threading
+from api.jobs import decode_job_items, encode_job_items
+from core.jobs import JobQueue
+from core.seqio import FastqRecord, SequenceRecord
+
+
+def test_job_items_round_trip_through_json():
+    records = [SequenceRecord('a', 'ACgt'), FastqRecord('r', 'ACG', 'II#')]
+    task = {'output': 'X', 'settings': [['rate', 'r1', 0.5]]}
+    for operation, items in [('gc', records), ('assemble', [records]), ('sweep', [task]),
+                             ('pipeline', [{'inputs': {'reads': '>a\nACGT\n'}}])]:
+        values = json.loads(json.dumps(encode_job_items(operation, items)))
+        assert decode_job_items(operation, values) == items
+
+
+def test_restore_resumes_interrupted_job_from_json_checkpoint(tmp_path):
+    release = threading.Event()
+    first = JobQueue(max_workers=1, checkpoint_dir=str(tmp_path),
+                     encode_items=encode_job_items, decode_items=decode_job_items)
+    records = [SequenceRecord('a', 'ACGT'), FastqRecord('r', 'GG', 'II')]
+    job = first.submit('gc', records, lambda item, params: release.wait(5))
+    with open(tmp_path / f'{job.id}.items') as handle:
+        assert json.load(handle)[1] == {'name': 'r', 'sequence': 'GG', 'quality': 'II'}
+
+    seen = []
+    second = JobQueue(max_workers=1, checkpoint_dir=str(tmp_path),
+                      encode_items=encode_job_items, decode_items=decode_job_items)
+    restored = second.restore({'gc': lambda item, params: seen.append(item) or len(item.sequence)})
+    release.set()
+    second.executor.shutdown(wait=True)
+    first.executor.shutdown(wait=True)
+    assert [resumed.id for resumed in restored] == [job.id]
+    assert seen == records
@@ -0,0 +1,30 @@
+import pytest
+from core.parallel import chunk_bounds, find_all, map_chunks
+from core.strand import Strand
+
+
+def test_chunk_bounds_cover_the_sequence():
+    assert chunk_bounds(10, 3) == [(0, 4), (4, 8), (8, 10)]
+    assert chunk_bounds(10, 3, overlap=2) == [(0, 6), (4, 10), (8, 10)]
+    assert chunk_bounds(2, 8) == [(0, 1), (1, 2)]
+
+
+def test_chunk_bounds_of_empty_sequence():
+    assert chunk_bounds(0, 4) == [(0, 0)]
+
+
+def test_empty_strand():
+    strand = Strand('')
+    assert strand.complement().sequence == ''
+    assert strand.reverse_complement().sequence == ''
+    assert strand.gc_content() == 0.0
+    assert strand.find('ACG') == []
+
+
+def test_serial_map_is_one_chunk():
+    assert map_chunks(lambda sequence, start, end: (start, end), 'ACGT' * 10, workers=4) == [(0, 40)]
+
+
+def test_find_all_rejects_empty_width():
+    with pytest.raises(ValueError):
+        find_all('ACGT', 'A', 0)
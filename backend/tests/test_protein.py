@@ -0,0 +1,15 @@
+import pytest
+from core.protein import Protein
+
+
+def test_isoelectric_point_of_empty_protein():
+    with pytest.raises(ValueError):
+        Protein('').isoelectric_point()
+    with pytest.raises(ValueError):
+        Protein('*').properties()
+
+
+def test_isoelectric_point_is_where_charge_crosses_zero():
+    protein = Protein('MKWVTFISLLLLFSSAYSRGVFRRDTHKSEIAHRFKDLGE')
+    pi = protein.isoelectric_point()
+    assert protein.charge(pi - 0.1) > 0 > protein.charge(pi + 0.1)
@@ -0,0 +1,72 @@
+import pytest
+
+grpc = pytest.importorskip('grpc')
+from rpc import robin_pb2, robin_pb2_grpc  # noqa: E402
+from rpc.server import serve  # noqa: E402
+
+
+@pytest.fixture(scope='module')
+def stub():
+    server, port = serve(port=0, workers=2)
+    channel = grpc.insecure_channel(f'localhost:{port}')
+    try:
+        yield robin_pb2_grpc.SequenceServiceStub(channel)
+    finally:
+        channel.close()
+        server.stop(None)
+
+
+def test_complement(stub):
+    assert stub.Complement(robin_pb2.SequenceRequest(sequence='AACGTn')).sequence == 'TTGCAn'
+    assert stub.Complement(robin_pb2.SequenceRequest(sequence='AACGTn', reverse=True)).sequence == 'nACGTT'
+
+
+def test_translate(stub):
+    reply = stub.Translate(robin_pb2.TranslateRequest(sequence='ATGGCCTAAGGG', to_stop=True))
+    assert reply.protein == 'MA'
+
+
+def test_translate_rejects_bad_frame(stub):
+    with pytest.raises(grpc.RpcError) as error:
+        stub.Translate(robin_pb2.TranslateRequest(sequence='ATG', frame=4))
+    assert error.value.code() == grpc.StatusCode.INVALID_ARGUMENT
+
+
+def test_align(stub):
+    reply = stub.Align(robin_pb2.AlignRequest(seq_a='ACGTACGT', seq_b='ACGTACGT'))
+    assert (reply.aligned_a, reply.aligned_b, reply.identity) == ('ACGTACGT', 'ACGTACGT', 100.0)
+    local = stub.Align(robin_pb2.AlignRequest(seq_a='TTTTACGTACGTTTTT', seq_b='ACGTACGT', local=True,
+                                              scoring=robin_pb2.Scoring(match=1, mismatch=-1, gap=-1)))
+    assert (local.start_a, local.end_a, local.score) == (4, 12, 8)
+
+
+def test_fold(stub):
+    reply = stub.Fold(robin_pb2.FoldRequest(sequence='GGGGAAAACCCC'))
+    assert reply.temperature == 37.0
+    assert reply.ensemble_dg < 0
+    assert len(reply.structure) == len(reply.unpaired) == 12
+    assert all(pair.i < pair.j and pair.probability >= 0.01 for pair in reply.pairs)
+
+
+def test_fold_temperature_and_constraint(stub):
+    reply = stub.Fold(robin_pb2.FoldRequest(sequence='GGGGAAAACCCC', temperature=0.0, constraint='x' * 12))
+    assert reply.temperature == 0.0
+    assert reply.structure == '.' * 12
+    assert not reply.pairs
+
+
+def test_fold_rejects_empty_sequence(stub):
+    with pytest.raises(grpc.RpcError) as error:
+        stub.Fold(robin_pb2.FoldRequest(sequence=''))
+    assert error.value.code() == grpc.StatusCode.INVALID_ARGUMENT
+
+
+def test_find_orfs_streams(stub):
+    orfs = list(stub.FindOrfs(robin_pb2.OrfRequest(sequence='CCATGAAATTTGGGTAACC', min_length=9,
+                                                   forward_only=True)))
+    assert [(orf.start, orf.end, orf.strand, orf.protein) for orf in orfs] == [(2, 17, '+', 'MKFG')]
+
+
+def test_reverse_complement_records_streams(stub):
+    records = list(stub.ReverseComplementRecords(robin_pb2.RecordsRequest(fasta='>a\nAAC\n>b\nggT\n')))
+    assert [(record.name, record.sequence) for record in records] == [('a', 'GTT'), ('b', 'Acc')]
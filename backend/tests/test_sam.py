@@ -0,0 +1,33 @@
+from core.mapper import Hit
+from core.sam import (FIRST_IN_PAIR, MATE_REVERSE, MATE_UNMAPPED, PAIRED, SECOND_IN_PAIR, UNMAPPED, sam_pair,
+                      sam_record)
+from core.seqio import FastqRecord
+
+REFERENCE = 'chr1 Homo sapiens chromosome 1'
+
+
+def _fields(line):
+    return line.split('\t')
+
+
+def test_pair_with_one_unmapped_mate():
+    r1 = FastqRecord('read1/1 lane 3', 'ACGTACGTAC', 'IIIIIIIIII')
+    r2 = FastqRecord('read1/2 lane 3', 'GGGGCCCCAA', 'JJJJJJJJJJ')
+    hit = Hit(REFERENCE, 99, '-', '10M', 20, 60, 0, 10, '10')
+    mapped, unmapped = (_fields(line) for line in sam_pair(r1, r2, hit, None, proper=False))
+
+    assert mapped[0] == unmapped[0] == 'read1'
+    assert int(mapped[1]) == PAIRED | FIRST_IN_PAIR | MATE_UNMAPPED | 0x10
+    assert mapped[2:4] == ['chr1', '100']
+    assert mapped[6:9] == ['=', '100', '0']
+
+    # The unmapped mate is placed at its mate's position, under the reference's SAM name
+    assert int(unmapped[1]) == PAIRED | SECOND_IN_PAIR | UNMAPPED | MATE_REVERSE
+    assert unmapped[2:6] == ['chr1', '100', '0', '*']
+    assert unmapped[6:9] == ['=', '100', '0']
+    assert unmapped[9:11] == ['GGGGCCCCAA', 'JJJJJJJJJJ']
+
+
+def test_unpaired_unmapped_read():
+    fields = _fields(sam_record(FastqRecord('lonely', 'ACGT', 'IIII'), None))
+    assert fields[1:9] == [str(UNMAPPED), '*', '0', '0', '*', '*', '0', '0']
@@ -0,0 +1,30 @@
+import io
+from cli import build_parser
+from core.seqio import format_fasta, read_sequences
+from core.sequence import complement, reverse_complement
+
+SOFT_MASKED = '>chr1 soft-masked\nACGTacgtNN\nnn--AC\n>raw\nggcc\n'
+
+
+def test_read_sequences_keeps_case():
+    records = list(read_sequences(io.StringIO(SOFT_MASKED)))
+    assert [(r.name, r.sequence) for r in records] == [('chr1 soft-masked', 'ACGTacgtNNnn--AC'), ('raw', 'ggcc')]
+
+
+def test_fasta_round_trip_keeps_case():
+    records = list(read_sequences(io.StringIO(SOFT_MASKED)))
+    assert list(read_sequences(io.StringIO(format_fasta(records)))) == records
+
+
+def test_complement_keeps_case():
+    assert complement('ACgtRyN-') == 'TGcaYrN-'
+    assert reverse_complement('acgtNN--AC') == 'GT--NNacgt'
+    assert reverse_complement(reverse_complement('aCgTtAnRyS.-')) == 'aCgTtAnRyS.-'
+
+
+def test_cli_revcomp_keeps_soft_masking(tmp_path, capsys):
+    path = tmp_path / 'in.fa'
+    path.write_text('>s\nacgtNN--AC\n')
+    args = build_parser().parse_args(['revcomp', str(path)])
+    assert args.handler(args) == 0
+    assert capsys.readouterr().out == '>s\nGT--NNacgt\n'
@@ -0,0 +1,37 @@
+import random
+import pytest
+from core import seqops
+from core.sequence import gc_content, reverse_complement
+
+rng = random.Random(0)
+# Mixed case, ambiguity codes, gaps, line breaks and a stray byte so every table entry is exercised
+EDGE_CASES = ''.join(rng.choices('ACGTUacgtuNnRYSWKMBDHVryswkmbdhv-.X\n', k=10000)).encode('ascii')
+RANDOM_ACGT = ''.join(rng.choices('ACGT', k=100000)).encode('ascii')
+
+
+@pytest.mark.parametrize('data', [b'', EDGE_CASES, RANDOM_ACGT])
+def test_backends_agree(data):
+    assert all(seqops.cross_check(data).values())
+
+
+@pytest.mark.parametrize('backend', seqops.BACKENDS)
+def test_reverse_complement_matches_sequence(backend):
+    text = EDGE_CASES.decode('ascii').replace('\n', '')
+    assert seqops.reverse_complement(text.encode('ascii'), backend).decode('ascii') == reverse_complement(text)
+
+
+@pytest.mark.parametrize('backend', seqops.BACKENDS)
+def test_gc_count_matches_sequence(backend):
+    text = RANDOM_ACGT.decode('ascii')
+    assert seqops.gc_count(RANDOM_ACGT, backend) / len(text) * 100 == pytest.approx(gc_content(text))
+    assert seqops.gc_count(b'GCSgcsWNAT', backend) == 6
+
+
+@pytest.mark.parametrize('backend', seqops.BACKENDS)
+def test_base_counts(backend):
+    assert seqops.base_counts(b'AAcX\n', backend) == {'A': 2, 'c': 1, 'X': 1, '\n': 1}
+
+
+def test_unknown_backend():
+    with pytest.raises(ValueError):
+        seqops.gc_count(b'ACGT', 'fortran')
@@ -0,0 +1,58 @@
+import pytest
+from core.sequence import COMPLEMENTS, IUPAC_BASES
+from core.strand import RNA, Strand
+
+# Every IUPAC nucleotide code and the code for the complementary set of bases
+IUPAC_COMPLEMENTS = {
+    'A': 'T', 'C': 'G', 'G': 'C', 'T': 'A',
+    'R': 'Y', 'Y': 'R', 'S': 'S', 'W': 'W', 'K': 'M', 'M': 'K',
+    'B': 'V', 'V': 'B', 'D': 'H', 'H': 'D', 'N': 'N'
+}
+
+
+@pytest.mark.parametrize('code, partner', sorted(IUPAC_COMPLEMENTS.items()))
+def test_complement_of_each_code(code, partner):
+    assert Strand(code).complement().sequence == partner
+    assert Strand(code.lower()).complement().sequence == partner.lower()
+
+
+@pytest.mark.parametrize('code', sorted(IUPAC_COMPLEMENTS))
+def test_complement_covers_the_complementary_bases(code):
+    partner = Strand(code).complement().sequence
+    expected = {COMPLEMENTS[base] for base in IUPAC_BASES[code]}
+    assert set(IUPAC_BASES[partner]) == expected
+
+
+def test_reverse_complement_of_all_codes():
+    codes = ''.join(IUPAC_COMPLEMENTS)
+    expected = ''.join(IUPAC_COMPLEMENTS[code] for code in reversed(codes))
+    assert Strand(codes).reverse_complement().sequence == expected
+    assert Strand(codes).reverse_complement().reverse_complement().sequence == codes
+
+
+def test_reverse_complement_keeps_gaps_and_case():
+    assert Strand('AcG-T.n').reverse_complement().sequence == 'n.A-CgT'
+
+
+def test_uracil():
+    assert Strand('U').complement().sequence == 'A'
+    assert Strand('ACGU', molecule=RNA).complement().sequence == 'UGCA'
+
+
+def test_in_place_matches_copy():
+    strand = Strand('ACGTRYKMBVDHN')
+    expected = strand.reverse_complement().sequence
+    assert strand.clone().reverse_complement_in_place().sequence == expected
+
+
+@pytest.mark.parametrize('sequence, expected', [
+    ('SSSS', 100.0),
+    ('WWWW', 0.0),
+    ('SSWW', 50.0),
+    ('NNNN', 0.0),
+    ('GCSN', 75.0),
+    ('sw', 50.0),
+    ('S-W.N', 100 / 3),
+])
+def test_gc_content_with_ambiguity_codes(sequence, expected):
+    assert Strand(sequence).gc_content() == pytest.approx(expected)
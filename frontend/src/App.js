@@ -1,11 +1,16 @@
 import React from 'react';
 
 import OligoDesigner from './components/OligoDesigner';
+import SharedProject from './components/SharedProject';
+
+const API_BASE = 'http://localhost:5000/api';
 
 function App() {
+    // Share links open a read-only project view instead of the designer
+    const shareToken = new URLSearchParams(window.location.search).get('share');
     return (
         <div className="App">
-            <OligoDesigner/>
+            {shareToken ? <SharedProject apiBase={API_BASE} token={shareToken}/> : <OligoDesigner/>}
         </div>
     );
 }
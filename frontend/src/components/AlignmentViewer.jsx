@@ -0,0 +1,174 @@
+// AlignmentViewer.jsx
+import React, {useState} from 'react';
+import './OligoDesigner.css';
+
+const download = (text, filename) => {
+    const url = URL.createObjectURL(new Blob([text], {type: 'text/plain'}));
+    const link = document.createElement('a');
+    link.href = url;
+    link.download = filename;
+    link.click();
+    URL.revokeObjectURL(url);
+};
+
+// Ruler line with each tick's column number right-aligned over its column
+const rulerLine = (block) => {
+    let line = '';
+    block.ruler.forEach(column => {
+        const label = String(column);
+        line = line.padEnd(column - block.start + 1 - label.length) + label;
+    });
+    return line;
+};
+
+const AlignmentBlock = ({block, labelWidth}) => (
+    <div className="aln-block">
+        <div className="aln-row aln-ruler">
+            <span className="aln-label" style={{width: `${labelWidth}ch`}}></span>
+            <span>{rulerLine(block)}</span>
+        </div>
+        {block.rows.map(row => (
+            <div className="aln-row" key={row.name}>
+                <span className="aln-label" style={{width: `${labelWidth}ch`}}>{row.name}</span>
+                <span>
+                    {row.segment.split('').map((residue, i) => (
+                        <span key={i} className={`aln-${row.cells[i]}`}>{residue}</span>
+                    ))}
+                </span>
+                <span className="aln-position">{row.end}</span>
+            </div>
+        ))}
+        <div className="aln-row">
+            <span className="aln-label" style={{width: `${labelWidth}ch`}}></span>
+            <span>{block.conservation}</span>
+        </div>
+    </div>
+);
+
+const AlignmentViewer = ({apiBase}) => {
+    const [mode, setMode] = useState('pairwise');
+    const [seqA, setSeqA] = useState('');
+    const [seqB, setSeqB] = useState('');
+    const [local, setLocal] = useState(false);
+    const [alignment, setAlignment] = useState('');
+    const [width, setWidth] = useState(60);
+    const [result, setResult] = useState(null);
+    const [loading, setLoading] = useState(false);
+    const [error, setError] = useState('');
+
+    const viewAlignment = async () => {
+        const body = mode === 'pairwise' ? {a: seqA, b: seqB, local, width} : {alignment, width};
+        if (mode === 'pairwise' ? !seqA.trim() || !seqB.trim() : !alignment.trim()) {
+            setError(mode === 'pairwise' ? 'Two sequences are required' : 'An aligned FASTA is required');
+            return;
+        }
+        setError('');
+        setLoading(true);
+
+        try {
+            const response = await fetch(`${apiBase}/analysis/alignment/view`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify(body)
+            });
+            const data = await response.json();
+            if (data.success) {
+                setResult(data);
+            } else {
+                setError(data.error || 'Alignment failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    const labelWidth = result ? Math.max(...result.view.names.map(name => name.length)) + 2 : 0;
+
+    return (
+        <div className="tab-content">
+            {error && <div className="error">{error}</div>}
+
+            <div className="add-form">
+                <h3 className="add-form-title">Alignment Viewer</h3>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">Input</label>
+                        <select className="form-input" value={mode} onChange={(e) => setMode(e.target.value)}>
+                            <option value="pairwise">Align two sequences</option>
+                            <option value="multiple">Aligned FASTA (multiple alignment)</option>
+                        </select>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Columns per block</label>
+                        <input
+                            type="number"
+                            className="form-input"
+                            min={10}
+                            value={width}
+                            onChange={(e) => setWidth(parseInt(e.target.value, 10) || 60)}
+                        />
+                    </div>
+                    {mode === 'pairwise' && (
+                        <div className="form-group">
+                            <label className="form-label">
+                                <input type="checkbox" checked={local} onChange={(e) => setLocal(e.target.checked)}/>
+                                {' '}Local alignment
+                            </label>
+                        </div>
+                    )}
+                </div>
+                {mode === 'pairwise' ? (
+                    <div className="add-form-grid">
+                        <div className="form-group">
+                            <label className="form-label">Sequence A</label>
+                            <textarea className="form-input sequence-box" rows={4} value={seqA}
+                                      onChange={(e) => setSeqA(e.target.value)}/>
+                        </div>
+                        <div className="form-group">
+                            <label className="form-label">Sequence B</label>
+                            <textarea className="form-input sequence-box" rows={4} value={seqB}
+                                      onChange={(e) => setSeqB(e.target.value)}/>
+                        </div>
+                    </div>
+                ) : (
+                    <div className="form-group">
+                        <textarea
+                            className="form-input sequence-box"
+                            rows={8}
+                            value={alignment}
+                            onChange={(e) => setAlignment(e.target.value)}
+                            placeholder="Aligned FASTA (equal lengths, gaps as -)"
+                        />
+                    </div>
+                )}
+                <button className="btn btn-primary" onClick={viewAlignment} disabled={loading}>
+                    {loading ? 'Aligning...' : 'View Alignment'}
+                </button>
+            </div>
+
+            {result && (
+                <div className="results-section">
+                    <p className="library-item-meta">
+                        {result.view.length} columns, {result.view.identity}% identical
+                        {result.view.score !== undefined ? `, score ${result.view.score}` : ''}
+                    </p>
+                    <div className="aln-view">
+                        {result.view.blocks.map(block => (
+                            <AlignmentBlock key={block.start} block={block} labelWidth={labelWidth}/>
+                        ))}
+                    </div>
+                    <button className="btn btn-primary" onClick={() => download(result.clustal, 'alignment.aln')}>
+                        Download CLUSTAL
+                    </button>
+                    <button className="btn btn-primary" onClick={() => download(result.fasta, 'alignment.fasta')}>
+                        Download FASTA
+                    </button>
+                </div>
+            )}
+        </div>
+    );
+};
+
+export default AlignmentViewer;
@@ -0,0 +1,118 @@
+// AnalysisPlugins.jsx
+import React, {useEffect, useState} from 'react';
+import './OligoDesigner.css';
+
+// Form field for one parameter of a plugin's JSON schema
+const ParamField = ({name, schema, value, onChange}) => {
+    const label = <label className="form-label" title={schema.description}>{name.replace(/_/g, ' ')}</label>;
+    if (schema.type === 'boolean') {
+        return (
+            <label className="form-label" title={schema.description}>
+                <input type="checkbox" checked={!!value} onChange={(e) => onChange(e.target.checked)}/>
+                {' '}{name.replace(/_/g, ' ')}
+            </label>
+        );
+    }
+    if (schema.enum) {
+        return (
+            <div className="form-group">
+                {label}
+                <select className="form-input" value={value ?? ''} onChange={(e) => onChange(e.target.value)}>
+                    {schema.enum.map(option => <option key={option} value={option}>{option}</option>)}
+                </select>
+            </div>
+        );
+    }
+    const numeric = schema.type === 'integer' || schema.type === 'number';
+    return (
+        <div className="form-group">
+            {label}
+            <input type={numeric ? 'number' : 'text'} className="form-input" value={value ?? ''}
+                   step={schema.type === 'number' ? 'any' : undefined} placeholder={schema.description}
+                   onChange={(e) => onChange(e.target.value)}/>
+        </div>
+    );
+};
+
+// One panel per registered plugin, built from its parameter schema; results are shown as JSON
+const PluginPanel = ({apiBase, analysis}) => {
+    const defaults = Object.fromEntries(Object.entries(analysis.params.properties)
+        .map(([name, schema]) => [name, schema.default]));
+    const [fasta, setFasta] = useState('');
+    const [params, setParams] = useState(defaults);
+    const [results, setResults] = useState(null);
+    const [loading, setLoading] = useState(false);
+    const [error, setError] = useState('');
+
+    const run = async () => {
+        setError('');
+        setLoading(true);
+        try {
+            const response = await fetch(`${apiBase}/plugins/${analysis.name}`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                credentials: 'include',
+                body: JSON.stringify({fasta, params})
+            });
+            const result = await response.json();
+            if (result.success) {
+                setResults(result.results);
+            } else {
+                setError(result.error || `${analysis.title} failed`);
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    return (
+        <div className="add-form">
+            <h3 className="add-form-title">{analysis.title}</h3>
+            {analysis.description && <p className="library-item-meta">{analysis.description}</p>}
+            {error && <div className="error">{error}</div>}
+            <div className="form-group">
+                <label className="form-label">Sequences (FASTA or one per line)</label>
+                <textarea className="form-input" rows={4} value={fasta} onChange={(e) => setFasta(e.target.value)}/>
+            </div>
+            <div className="add-form-grid">
+                {Object.entries(analysis.params.properties).map(([name, schema]) => (
+                    <ParamField key={name} name={name} schema={schema} value={params[name]}
+                                onChange={(value) => setParams({...params, [name]: value})}/>
+                ))}
+                <button className="btn btn-primary" onClick={run} disabled={loading || !fasta.trim()}>
+                    {loading ? 'Running...' : 'Run'}
+                </button>
+            </div>
+            {results && (
+                <div className="results-section">
+                    <pre className="sequence-box">{results.map(item => JSON.stringify(item, null, 2)).join('\n')}</pre>
+                </div>
+            )}
+        </div>
+    );
+};
+
+// Every analysis plugin the backend has registered, without the frontend knowing them in advance
+const AnalysisPlugins = ({apiBase}) => {
+    const [analyses, setAnalyses] = useState([]);
+    const [error, setError] = useState('');
+
+    useEffect(() => {
+        fetch(`${apiBase}/plugins`, {credentials: 'include'})
+            .then(response => response.json())
+            .then(result => result.success ? setAnalyses(result.analyses) : setError(result.error))
+            .catch(() => setError('Network error: Unable to connect to server'));
+    }, [apiBase]);
+
+    return (
+        <div className="tab-content">
+            {error && <div className="error">{error}</div>}
+            {!error && analyses.length === 0 && <p className="library-item-meta">No analysis plugins are installed.</p>}
+            {analyses.map(analysis => <PluginPanel key={analysis.name} apiBase={apiBase} analysis={analysis}/>)}
+        </div>
+    );
+};
+
+export default AnalysisPlugins;
@@ -0,0 +1,287 @@
+// BlastSearch.jsx
+import React, {useEffect, useState} from 'react';
+import './OligoDesigner.css';
+
+// Polls the batch job, not NCBI; the backend spaces its own NCBI status checks a minute apart
+const POLL_INTERVAL_MS = 15000;
+
+const readQuery = (text) => {
+    const trimmed = text.trim();
+    if (!trimmed.startsWith('>')) {
+        return {name: 'query', sequence: trimmed.replace(/\s/g, '').toUpperCase()};
+    }
+    const [header, ...lines] = trimmed.split('\n');
+    return {
+        name: header.slice(1).trim().split(/\s+/)[0] || 'query',
+        sequence: lines.join('').replace(/\s/g, '').toUpperCase()
+    };
+};
+
+const HitTrack = ({hits, length, selected, onSelect}) => (
+    <div style={{position: 'relative', height: `${Math.max(hits.length, 1) * 8 + 16}px`, background: '#f3f4f6'}}>
+        <div style={{position: 'absolute', top: 0, left: 0, right: 0, height: '6px', background: '#374151'}}
+             title={`Query, ${length} bp`}/>
+        {hits.map((hit, i) => (
+            <div
+                key={i}
+                onClick={() => onSelect(i)}
+                title={`${hit.subject} ${hit.query_start + 1}-${hit.query_end} (${hit.identity}%)`}
+                style={{
+                    position: 'absolute',
+                    top: `${10 + i * 8}px`,
+                    left: `${(hit.query_start / length) * 100}%`,
+                    width: `${Math.max(((hit.query_end - hit.query_start) / length) * 100, 0.5)}%`,
+                    height: '6px',
+                    cursor: 'pointer',
+                    background: i === selected ? '#dc2626' : hit.strand === 1 ? '#2563eb' : '#7c3aed'
+                }}
+            />
+        ))}
+    </div>
+);
+
+const BlastSearch = ({apiBase}) => {
+    const [query, setQuery] = useState('');
+    const [program, setProgram] = useState('blastn');
+    const [database, setDatabase] = useState('nt');
+    const [job, setJob] = useState(null);
+    const [submitted, setSubmitted] = useState(null);
+    const [results, setResults] = useState(null);
+    const [output, setOutput] = useState('');
+    const [selected, setSelected] = useState(null);
+    const [error, setError] = useState('');
+
+    useEffect(() => {
+        if (!job || job.status === 'completed' || job.status === 'failed') {
+            return undefined;
+        }
+        const timer = setTimeout(async () => {
+            try {
+                const response = await fetch(`${apiBase}/v1/jobs/${job.id}`, {credentials: 'include'});
+                const result = await response.json();
+                if (!result.success) {
+                    setError(result.error || 'Failed to check the search');
+                    setJob(null);
+                } else if (result.job.status === 'failed') {
+                    setError(result.job.error || 'BLAST search failed');
+                    setJob(result.job);
+                } else {
+                    setJob(result.job);
+                    if (result.job.status === 'completed') {
+                        setResults({query: submitted, ...result.job.results[0]});
+                    }
+                }
+            } catch (err) {
+                setError('Network error: Unable to connect to server');
+            }
+        }, POLL_INTERVAL_MS);
+        return () => clearTimeout(timer);
+    }, [apiBase, job, submitted]);
+
+    const runSearch = async () => {
+        const record = readQuery(query);
+        if (!record.sequence) {
+            setError('A query sequence is required');
+            return;
+        }
+        setError('');
+        setResults(null);
+        setSelected(null);
+
+        try {
+            const response = await fetch(`${apiBase}/v1/jobs`, {
+                method: 'POST',
+                credentials: 'include',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({operation: 'blast', sequences: [record], params: {program, database}})
+            });
+            const result = await response.json();
+            if (result.success) {
+                setSubmitted(record);
+                setJob(result.job);
+            } else {
+                setError(result.error || 'Failed to submit the search');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        }
+    };
+
+    const searchLibrary = async () => {
+        const record = readQuery(query);
+        if (!record.sequence) {
+            setError('A query sequence is required');
+            return;
+        }
+        setError('');
+        setSelected(null);
+
+        try {
+            const response = await fetch(`${apiBase}/workspace/search`, {
+                method: 'POST',
+                credentials: 'include',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify(record)
+            });
+            const result = await response.json();
+            if (result.success) {
+                setResults({query: record, name: record.name, length: record.sequence.length, hits: result.hits});
+            } else {
+                setError(result.error || 'Library search failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        }
+    };
+
+    const parseOutput = async () => {
+        if (!output.trim()) {
+            setError('Paste BLAST XML or tabular output');
+            return;
+        }
+        setError('');
+        setSelected(null);
+
+        try {
+            const response = await fetch(`${apiBase}/analysis/blast/parse`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({text: output})
+            });
+            const result = await response.json();
+            if (result.success) {
+                const record = readQuery(query);
+                // Without the query at hand, the furthest hit end stands in for its length
+                const length = record.sequence.length || Math.max(1, ...result.hits.map(hit => hit.query_end));
+                setResults({query: record, name: record.name, length, hits: result.hits});
+            } else {
+                setError(result.error || 'Failed to parse BLAST output');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        }
+    };
+
+    const searching = job && (job.status === 'queued' || job.status === 'running');
+    const hit = results && selected !== null ? results.hits[selected] : null;
+
+    return (
+        <div className="tab-content">
+            {error && <div className="error">{error}</div>}
+
+            <div className="add-form">
+                <h3 className="add-form-title">BLAST Search</h3>
+                <div className="form-group">
+                    <textarea
+                        className="form-input sequence-box"
+                        rows={6}
+                        value={query}
+                        onChange={(e) => setQuery(e.target.value)}
+                        placeholder="Query sequence or FASTA"
+                    />
+                </div>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">Program</label>
+                        <select className="form-input" value={program} onChange={(e) => setProgram(e.target.value)}>
+                            {['blastn', 'blastp', 'blastx', 'tblastn', 'tblastx'].map(name => (
+                                <option key={name} value={name}>{name}</option>
+                            ))}
+                        </select>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Database</label>
+                        <input
+                            type="text"
+                            className="form-input"
+                            value={database}
+                            onChange={(e) => setDatabase(e.target.value)}
+                            placeholder="e.g., nt, refseq_rna, swissprot"
+                        />
+                    </div>
+                    <button className="btn btn-primary" onClick={runSearch} disabled={searching}>
+                        {searching ? 'Searching...' : 'Search'}
+                    </button>
+                    <button className="btn btn-success" onClick={searchLibrary}>
+                        Search My Library
+                    </button>
+                </div>
+                {searching && (
+                    <p className="library-item-meta">
+                        Searching NCBI; remote searches usually take a few minutes
+                    </p>
+                )}
+            </div>
+
+            <div className="add-form">
+                <h3 className="add-form-title">Parse BLAST Output</h3>
+                <div className="form-group">
+                    <textarea
+                        className="form-input"
+                        rows={4}
+                        value={output}
+                        onChange={(e) => setOutput(e.target.value)}
+                        placeholder="BLAST XML (-outfmt 5) or tabular (-outfmt 6/7) output"
+                    />
+                </div>
+                <button className="btn btn-primary" onClick={parseOutput}>Parse</button>
+            </div>
+
+            {results && (
+                <div className="results-section">
+                    <h4>{results.hits.length} hits for {results.name} ({results.length} bp)</h4>
+                    <HitTrack hits={results.hits} length={results.length} selected={selected} onSelect={setSelected}/>
+                    {hit && (
+                        <div className="result-item">
+                            <strong>{hit.subject}</strong> {hit.description}
+                            <p className="library-item-meta">
+                                Query {hit.query_start + 1}-{hit.query_end}, subject {hit.subject_start + 1}-{hit.subject_end}
+                                {hit.strand === -1 ? ' (minus strand)' : ''}
+                            </p>
+                            {results.query.sequence && (
+                                <div className="sequence-box">
+                                    {results.query.sequence.slice(0, hit.query_start)}
+                                    <mark>{results.query.sequence.slice(hit.query_start, hit.query_end)}</mark>
+                                    {results.query.sequence.slice(hit.query_end)}
+                                </div>
+                            )}
+                            {hit.aligned_query && (
+                                <pre className="sequence-box">
+                                    {`${hit.aligned_query}\n${hit.midline}\n${hit.aligned_subject}`}
+                                </pre>
+                            )}
+                        </div>
+                    )}
+                    <table className="matrix-table">
+                        <thead>
+                        <tr>
+                            <th>Subject</th>
+                            <th>Identity</th>
+                            <th>Length</th>
+                            <th>Query</th>
+                            <th>Subject range</th>
+                            <th>E-value</th>
+                            <th>Bit score</th>
+                        </tr>
+                        </thead>
+                        <tbody>
+                        {results.hits.map((item, i) => (
+                            <tr key={i} onClick={() => setSelected(i)} style={{cursor: 'pointer'}}>
+                                <td>{item.subject}</td>
+                                <td>{item.identity}%</td>
+                                <td>{item.length}</td>
+                                <td>{item.query_start + 1}-{item.query_end}</td>
+                                <td>{item.subject_start + 1}-{item.subject_end}{item.strand === -1 ? ' (-)' : ''}</td>
+                                <td>{item.evalue.toExponential(1)}</td>
+                                <td>{item.bit_score}</td>
+                            </tr>
+                        ))}
+                        </tbody>
+                    </table>
+                </div>
+            )}
+        </div>
+    );
+};
+
+export default BlastSearch;
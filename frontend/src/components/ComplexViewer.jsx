@@ -0,0 +1,64 @@
+// ComplexViewer.jsx
+import React, {useState} from 'react';
+import './OligoDesigner.css';
+
+// Domain-level complex in kernel notation, shown as Visual DSD notation and an ASCII duplex diagram
+const ComplexViewer = ({apiBase}) => {
+    const [kernel, setKernel] = useState('x( y( + u( + ) ) ) t*');
+    const [rendered, setRendered] = useState(null);
+    const [error, setError] = useState('');
+
+    const render = async () => {
+        setError('');
+        try {
+            const response = await fetch(`${apiBase}/analysis/complex`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({kernel})
+            });
+            const result = await response.json();
+            if (result.success) {
+                setRendered(result);
+            } else {
+                setError(result.error || 'Failed to read the complex');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        }
+    };
+
+    return (
+        <div className="add-form">
+            <h3 className="add-form-title">Complex Notation</h3>
+            {error && <div className="error">{error}</div>}
+            <div className="add-form-grid">
+                <div className="form-group">
+                    <label className="form-label">Kernel Notation</label>
+                    <input
+                        type="text"
+                        className="form-input"
+                        value={kernel}
+                        onChange={(e) => setKernel(e.target.value)}
+                        placeholder="e.g., t( x( + ) )"
+                    />
+                </div>
+                <button className="btn btn-primary" onClick={render}>Render</button>
+            </div>
+            <div className="add-form-note">
+                x( opens a pair that the matching ) closes with x*; + separates strands.
+            </div>
+            {rendered && (
+                <div className="results-section">
+                    <p className="library-item-meta">Kernel: {rendered.kernel}</p>
+                    <p className="library-item-meta">Domain structure: {rendered.structure}</p>
+                    {rendered.dsd && <p className="library-item-meta">Visual DSD: {rendered.dsd}</p>}
+                    {rendered.ascii
+                        ? <pre className="sequence-box">{rendered.ascii}</pre>
+                        : <p className="library-item-meta">Not drawable as top strands on one bottom strand</p>}
+                </div>
+            )}
+        </div>
+    );
+};
+
+export default ComplexViewer;
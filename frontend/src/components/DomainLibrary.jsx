@@ -0,0 +1,116 @@
+// DomainLibrary.jsx
+import React, {useState} from 'react';
+import './OligoDesigner.css';
+
+const download = (text, filename, type) => {
+    const url = URL.createObjectURL(new Blob([text], {type}));
+    const link = document.createElement('a');
+    link.href = url;
+    link.download = filename;
+    link.click();
+    URL.revokeObjectURL(url);
+};
+
+// Orthogonal domain library generator, exported as FASTA or CSV for ordering
+const DomainLibrary = ({apiBase}) => {
+    const [count, setCount] = useState('10');
+    const [length, setLength] = useState('20');
+    const [method, setMethod] = useState('thermo');
+    const [threshold, setThreshold] = useState('');
+    const [complements, setComplements] = useState(false);
+    const [library, setLibrary] = useState(null);
+    const [loading, setLoading] = useState(false);
+    const [error, setError] = useState('');
+
+    const generate = async () => {
+        setError('');
+        setLoading(true);
+        try {
+            const body = {count: parseInt(count, 10), length: parseInt(length, 10), method, complements};
+            if (threshold !== '') {
+                body.threshold = parseFloat(threshold);
+            }
+            const response = await fetch(`${apiBase}/analysis/domains/library`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify(body)
+            });
+            const result = await response.json();
+            if (result.success) {
+                setLibrary(result);
+            } else {
+                setError(result.error || 'Failed to generate the library');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    return (
+        <div className="add-form">
+            <h3 className="add-form-title">Orthogonal Domain Library</h3>
+            {error && <div className="error">{error}</div>}
+            <div className="add-form-grid">
+                <div className="form-group">
+                    <label className="form-label">Domains</label>
+                    <input type="number" className="form-input" value={count} min="1" max="100"
+                           onChange={(e) => setCount(e.target.value)}/>
+                </div>
+                <div className="form-group">
+                    <label className="form-label">Length (nt)</label>
+                    <input type="number" className="form-input" value={length} min="6" max="60"
+                           onChange={(e) => setLength(e.target.value)}/>
+                </div>
+                <div className="form-group">
+                    <label className="form-label">Measure</label>
+                    <select className="form-input" value={method} onChange={(e) => setMethod(e.target.value)}>
+                        <option value="thermo">Thermodynamic (ΔG37)</option>
+                        <option value="alignment">Alignment identity</option>
+                    </select>
+                </div>
+                <div className="form-group">
+                    <label className="form-label">
+                        {method === 'thermo' ? 'Strongest ΔG37 (kcal/mol)' : 'Highest identity (%)'}
+                    </label>
+                    <input type="number" className="form-input" value={threshold}
+                           onChange={(e) => setThreshold(e.target.value)}
+                           placeholder={method === 'thermo' ? '-7' : '60'}/>
+                </div>
+                <label className="form-label">
+                    <input type="checkbox" checked={complements} onChange={(e) => setComplements(e.target.checked)}/>
+                    {' '}Include complements
+                </label>
+                <button className="btn btn-primary" onClick={generate} disabled={loading}>
+                    {loading ? 'Generating...' : 'Generate'}
+                </button>
+            </div>
+
+            {library && (
+                <div className="results-section">
+                    <h4>{library.domains.length} domains after {library.attempts} candidates</h4>
+                    {library.worst && (
+                        <p className="library-item-meta">
+                            Strongest cross-interaction: {library.worst.a} / {library.worst.b},{' '}
+                            {library.method === 'thermo' ? `${library.worst.value} kcal/mol` : `${library.worst.value}% identity`}
+                        </p>
+                    )}
+                    <div className="sequence-box">
+                        {library.domains.map(domain => (
+                            <div key={domain.name}>{domain.name} {domain.sequence} ({domain.gc_percent}% GC)</div>
+                        ))}
+                    </div>
+                    <button className="btn btn-primary" onClick={() => download(library.fasta, 'domains.fasta', 'text/plain')}>
+                        Download FASTA
+                    </button>
+                    <button className="btn btn-primary" onClick={() => download(library.csv, 'domains.csv', 'text/csv')}>
+                        Download CSV
+                    </button>
+                </div>
+            )}
+        </div>
+    );
+};
+
+export default DomainLibrary;
@@ -0,0 +1,129 @@
+// KineticsSimulator.jsx
+import React, {useState} from 'react';
+import LineChart from './LineChart';
+import './OligoDesigner.css';
+
+const EXAMPLE_CRN = `# Strand displacement with a reversible toehold (rates in /M/s and /s)
+gate + invader <=> bound [3e6, 0.5]
+bound -> waste + output [1]`;
+
+const EXAMPLE_INITIAL = `gate = 1e-8
+invader = 2e-8`;
+
+const download = (text, filename, type) => {
+    const url = URL.createObjectURL(new Blob([text], {type}));
+    const link = document.createElement('a');
+    link.href = url;
+    link.download = filename;
+    link.click();
+    URL.revokeObjectURL(url);
+};
+
+// Time course of a chemical reaction network, by mass-action ODEs or stochastic simulation
+const KineticsSimulator = ({apiBase}) => {
+    const [crn, setCrn] = useState(EXAMPLE_CRN);
+    const [initial, setInitial] = useState(EXAMPLE_INITIAL);
+    const [tEnd, setTEnd] = useState('3600');
+    const [method, setMethod] = useState('ode');
+    const [course, setCourse] = useState(null);
+    const [loading, setLoading] = useState(false);
+    const [error, setError] = useState('');
+
+    const request = (format) => {
+        const amounts = {};
+        initial.split('\n').forEach(line => {
+            const [name, amount] = line.split('=').map(part => part.trim());
+            if (name && amount) {
+                amounts[name] = parseFloat(amount);
+            }
+        });
+        return fetch(`${apiBase}/analysis/simulate`, {
+            method: 'POST',
+            headers: {'Content-Type': 'application/json'},
+            body: JSON.stringify({crn, initial: amounts, t_end: parseFloat(tEnd), method, format})
+        });
+    };
+
+    const run = async () => {
+        setError('');
+        setLoading(true);
+        try {
+            const result = await (await request('json')).json();
+            if (result.success) {
+                setCourse(result);
+            } else {
+                setError(result.error || 'Simulation failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    const save = async (format) => {
+        setError('');
+        try {
+            const response = await request(format);
+            if (!response.ok) {
+                const result = await response.json();
+                setError(result.error || 'Export failed');
+                return;
+            }
+            download(await response.text(), `time-course.${format}`, format === 'svg' ? 'image/svg+xml' : 'text/csv');
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        }
+    };
+
+    return (
+        <div className="add-form">
+            <h3 className="add-form-title">Kinetics</h3>
+            {error && <div className="error">{error}</div>}
+            <div className="add-form-grid">
+                <div className="form-group">
+                    <label className="form-label">Reactions or SBML Model</label>
+                    <textarea className="form-input sequence-box" rows={5} value={crn}
+                              onChange={(e) => setCrn(e.target.value)}/>
+                </div>
+                <div className="form-group">
+                    <label className="form-label">Initial Amounts</label>
+                    <textarea className="form-input sequence-box" rows={5} value={initial}
+                              onChange={(e) => setInitial(e.target.value)}/>
+                </div>
+            </div>
+            <div className="add-form-grid">
+                <div className="form-group">
+                    <label className="form-label">Method</label>
+                    <select className="form-input" value={method} onChange={(e) => setMethod(e.target.value)}>
+                        <option value="ode">ODE (concentrations)</option>
+                        <option value="ssa">SSA (molecule counts)</option>
+                    </select>
+                </div>
+                <div className="form-group">
+                    <label className="form-label">End Time</label>
+                    <input type="number" className="form-input" value={tEnd} onChange={(e) => setTEnd(e.target.value)}/>
+                </div>
+                <button className="btn btn-primary" onClick={run} disabled={loading}>
+                    {loading ? 'Simulating...' : 'Simulate'}
+                </button>
+            </div>
+            <div className="add-form-note">
+                One "A + B -&gt; C [rate]" or "A &lt;=&gt; B [kf, kr]" per line; one "name = amount" per line.
+            </div>
+
+            {course && (
+                <div className="results-section">
+                    <LineChart series={course.series} xLabel="Time"
+                               yLabel={course.method === 'ssa' ? 'Count' : 'Concentration'}/>
+                    <div className="add-form-grid">
+                        <button className="btn btn-primary" onClick={() => save('svg')}>Download SVG</button>
+                        <button className="btn btn-primary" onClick={() => save('csv')}>Download CSV</button>
+                    </div>
+                </div>
+            )}
+        </div>
+    );
+};
+
+export default KineticsSimulator;
@@ -0,0 +1,101 @@
+// LineChart.jsx
+import React, {useState} from 'react';
+
+const COLORS = ['#2563eb', '#dc2626', '#059669', '#d97706', '#7c3aed'];
+
+// Small values such as molar concentrations read better in exponent form
+const formatValue = (value, digits) =>
+    value !== 0 && Math.abs(value) < 0.01 ? value.toExponential(1) : value.toFixed(digits);
+
+// Minimal SVG line chart: series = [{label, points: [{x, y}]}]
+const LineChart = ({series, width = 720, height = 240, xLabel = '', yLabel = ''}) => {
+    const [hover, setHover] = useState(null);
+    const margin = {top: 16, right: 16, bottom: 36, left: 56};
+    const innerWidth = width - margin.left - margin.right;
+    const innerHeight = height - margin.top - margin.bottom;
+
+    const allPoints = series.flatMap(s => s.points);
+    if (allPoints.length === 0) {
+        return null;
+    }
+
+    const xs = allPoints.map(p => p.x);
+    const ys = allPoints.map(p => p.y);
+    const xMin = Math.min(...xs), xMax = Math.max(...xs);
+    let yMin = Math.min(...ys), yMax = Math.max(...ys);
+    if (yMin === yMax) {
+        yMin -= 1;
+        yMax += 1;
+    }
+
+    const scaleX = x => margin.left + (xMax === xMin ? 0 : (x - xMin) / (xMax - xMin) * innerWidth);
+    const scaleY = y => margin.top + (1 - (y - yMin) / (yMax - yMin)) * innerHeight;
+    const yTicks = [0, 0.25, 0.5, 0.75, 1].map(f => yMin + f * (yMax - yMin));
+
+    const onMouseMove = (e) => {
+        const bounds = e.currentTarget.getBoundingClientRect();
+        const x = xMin + ((e.clientX - bounds.left) * (width / bounds.width) - margin.left) / innerWidth * (xMax - xMin);
+        const nearest = series.map(s => s.points.reduce((best, p) =>
+            (!best || Math.abs(p.x - x) < Math.abs(best.x - x)) ? p : best, null));
+        setHover({x: nearest[0] ? nearest[0].x : x, values: nearest});
+    };
+
+    return (
+        <svg
+            viewBox={`0 0 ${width} ${height}`}
+            style={{width: '100%', maxWidth: width, background: 'white'}}
+            onMouseMove={onMouseMove}
+            onMouseLeave={() => setHover(null)}
+        >
+            {yTicks.map((tick, i) => (
+                <g key={i}>
+                    <line x1={margin.left} x2={width - margin.right} y1={scaleY(tick)} y2={scaleY(tick)}
+                          stroke="#e5e7eb"/>
+                    <text x={margin.left - 6} y={scaleY(tick) + 4} fontSize="10" textAnchor="end" fill="#6b7280">
+                        {formatValue(tick, 2)}
+                    </text>
+                </g>
+            ))}
+            <text x={margin.left} y={height - 8} fontSize="10" fill="#6b7280">{Math.round(xMin)}</text>
+            <text x={width - margin.right} y={height - 8} fontSize="10" textAnchor="end" fill="#6b7280">
+                {Math.round(xMax)}
+            </text>
+            <text x={margin.left + innerWidth / 2} y={height - 8} fontSize="11" textAnchor="middle" fill="#374151">
+                {xLabel}
+            </text>
+            <text x={12} y={margin.top + innerHeight / 2} fontSize="11" textAnchor="middle" fill="#374151"
+                  transform={`rotate(-90 12 ${margin.top + innerHeight / 2})`}>
+                {yLabel}
+            </text>
+
+            {series.map((s, i) => (
+                <polyline
+                    key={s.label}
+                    fill="none"
+                    stroke={COLORS[i % COLORS.length]}
+                    strokeWidth="1.5"
+                    points={s.points.map(p => `${scaleX(p.x)},${scaleY(p.y)}`).join(' ')}
+                />
+            ))}
+
+            {series.map((s, i) => (
+                <text key={s.label} x={margin.left + 8 + i * 120} y={margin.top + 10} fontSize="11"
+                      fill={COLORS[i % COLORS.length]}>
+                    {s.label}
+                </text>
+            ))}
+
+            {hover && (
+                <g>
+                    <line x1={scaleX(hover.x)} x2={scaleX(hover.x)} y1={margin.top} y2={margin.top + innerHeight}
+                          stroke="#9ca3af" strokeDasharray="3,3"/>
+                    <text x={scaleX(hover.x) + 4} y={margin.top + innerHeight - 4} fontSize="10" fill="#111827">
+                        {Math.round(hover.x)}: {hover.values.map(v => v && formatValue(v.y, 3)).join(' / ')}
+                    </text>
+                </g>
+            )}
+        </svg>
+    );
+};
+
+export default LineChart;
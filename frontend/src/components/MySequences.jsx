@@ -0,0 +1,545 @@
+// MySequences.jsx
+import React, {useState, useEffect} from 'react';
+import './OligoDesigner.css';
+
+const OPERATIONS = ['gc', 'revcomp', 'translate', 'orfs', 'protein'];
+
+const MySequences = ({apiBase}) => {
+    const [sequences, setSequences] = useState([]);
+    const [selected, setSelected] = useState(null);
+    const [name, setName] = useState('');
+    const [description, setDescription] = useState('');
+    const [sequence, setSequence] = useState('');
+    const [operation, setOperation] = useState('gc');
+    const [error, setError] = useState('');
+    const [account, setAccount] = useState(null);
+    const [versions, setVersions] = useState([]);
+    const [projects, setProjects] = useState([]);
+    const [projectName, setProjectName] = useState('');
+    const [accessions, setAccessions] = useState('');
+    const [fetching, setFetching] = useState(false);
+    const [region, setRegion] = useState({assembly: 'hg38', locus: '', source: 'ucsc'});
+
+    useEffect(() => {
+        loadAccount();
+        loadSequences();
+        loadProjects();
+    }, []);
+
+    const loadAccount = async () => {
+        try {
+            const response = await fetch(`${apiBase}/auth/me`, {credentials: 'include'});
+            setAccount(await response.json());
+        } catch (err) {
+            setAccount(null);
+        }
+    };
+
+    const loadSequences = async () => {
+        try {
+            const response = await fetch(`${apiBase}/workspace/sequences`, {credentials: 'include'});
+            const result = await response.json();
+            setSequences(result.sequences || []);
+        } catch (err) {
+            setError('Network error: Unable to load saved sequences');
+        }
+    };
+
+    const loadProjects = async () => {
+        try {
+            const response = await fetch(`${apiBase}/workspace/projects`, {credentials: 'include'});
+            const result = await response.json();
+            setProjects(result.projects || []);
+        } catch (err) {
+            setError('Network error: Unable to load projects');
+        }
+    };
+
+    const createProject = async () => {
+        if (!projectName.trim()) {
+            setError('Project name is required');
+            return;
+        }
+        setError('');
+        try {
+            const response = await fetch(`${apiBase}/workspace/projects`, {
+                method: 'POST',
+                credentials: 'include',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({name: projectName.trim()})
+            });
+            const result = await response.json();
+            if (result.success) {
+                setProjectName('');
+                loadProjects();
+            } else {
+                setError(result.error || 'Failed to create project');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        }
+    };
+
+    // method is PUT/DELETE for membership or POST/DELETE for sharing; path is relative to the project
+    const updateProject = async (projectId, path, method) => {
+        try {
+            const response = await fetch(`${apiBase}/workspace/projects/${projectId}/${path}`, {
+                method,
+                credentials: 'include'
+            });
+            const result = await response.json();
+            if (result.success) {
+                loadProjects();
+            } else {
+                setError(result.error || 'Project update failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to update project');
+        }
+    };
+
+    const deleteProject = async (projectId) => {
+        try {
+            await fetch(`${apiBase}/workspace/projects/${projectId}`, {method: 'DELETE', credentials: 'include'});
+            loadProjects();
+        } catch (err) {
+            setError('Network error: Unable to delete project');
+        }
+    };
+
+    const loadSequence = async (id) => {
+        try {
+            const response = await fetch(`${apiBase}/workspace/sequences/${id}`, {credentials: 'include'});
+            const result = await response.json();
+            if (result.success) {
+                setSelected(result.sequence);
+                loadVersions(id);
+            } else {
+                setError(result.error || 'Failed to load sequence');
+            }
+        } catch (err) {
+            setError('Network error: Unable to load sequence');
+        }
+    };
+
+    const loadVersions = async (id) => {
+        try {
+            const response = await fetch(`${apiBase}/workspace/sequences/${id}/versions`, {credentials: 'include'});
+            const result = await response.json();
+            setVersions(result.versions || []);
+        } catch (err) {
+            setVersions([]);
+        }
+    };
+
+    const revertToVersion = async (id, version) => {
+        try {
+            const response = await fetch(`${apiBase}/workspace/sequences/${id}/versions/${version}/revert`, {
+                method: 'POST',
+                credentials: 'include'
+            });
+            const result = await response.json();
+            if (result.success) {
+                loadSequence(id);
+                loadSequences();
+            } else {
+                setError(result.error || 'Revert failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to revert sequence');
+        }
+    };
+
+    const saveSequence = async () => {
+        if (!name.trim() || !sequence.trim()) {
+            setError('Sequence name and sequence are required');
+            return;
+        }
+        setError('');
+
+        try {
+            const response = await fetch(`${apiBase}/workspace/sequences`, {
+                method: 'POST',
+                credentials: 'include',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({name: name.trim(), description, sequence})
+            });
+            const result = await response.json();
+            if (result.success) {
+                setName('');
+                setDescription('');
+                setSequence('');
+                loadSequences();
+                if (result.duplicates && result.duplicates.length > 0) {
+                    setError(`Saved, but it matches ${result.duplicates.map(d =>
+                        `${d.name}${d.match === 'circular' ? ' (as a rotated or reverse-complemented circle)' : ''}`
+                    ).join(', ')}`);
+                }
+            } else {
+                setError(result.error || 'Failed to save sequence');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        }
+    };
+
+    const fetchAccessions = async () => {
+        if (!accessions.trim()) {
+            setError('Enter one or more accessions');
+            return;
+        }
+        setError('');
+        setFetching(true);
+
+        try {
+            const response = await fetch(`${apiBase}/workspace/import/entrez`, {
+                method: 'POST',
+                credentials: 'include',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({accessions})
+            });
+            const result = await response.json();
+            if (result.success) {
+                setAccessions('');
+                loadSequences();
+                const duplicates = result.imported.filter(item => item.duplicates.length > 0);
+                if (duplicates.length > 0) {
+                    setError(`Imported, but ${duplicates.map(item => item.accession).join(', ')} ` +
+                             'matches a sequence already saved');
+                }
+            } else {
+                setError(result.error || 'Failed to fetch accessions');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setFetching(false);
+        }
+    };
+
+    const fetchRegion = async () => {
+        // Genome browser notation, 1-based and inclusive: chr7:117,480,025-117,481,000
+        const match = region.locus.replace(/,/g, '').trim().match(/^(\S+):(\d+)-(\d+)$/);
+        if (!match) {
+            setError('Enter a region as chrom:start-end');
+            return;
+        }
+        setError('');
+        setFetching(true);
+
+        try {
+            const response = await fetch(`${apiBase}/workspace/import/region`, {
+                method: 'POST',
+                credentials: 'include',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({
+                    assembly: region.assembly,
+                    chrom: match[1],
+                    start: parseInt(match[2], 10) - 1,
+                    end: parseInt(match[3], 10),
+                    source: region.source
+                })
+            });
+            const result = await response.json();
+            if (result.success) {
+                setRegion({...region, locus: ''});
+                loadSequences();
+            } else {
+                setError(result.error || 'Failed to fetch region');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setFetching(false);
+        }
+    };
+
+    const deleteSequence = async (id) => {
+        try {
+            await fetch(`${apiBase}/workspace/sequences/${id}`, {method: 'DELETE', credentials: 'include'});
+            if (selected && selected.id === id) {
+                setSelected(null);
+            }
+            loadSequences();
+            loadProjects();
+        } catch (err) {
+            setError('Network error: Unable to delete sequence');
+        }
+    };
+
+    const runAnalysis = async (id) => {
+        try {
+            const response = await fetch(`${apiBase}/workspace/sequences/${id}/analyses`, {
+                method: 'POST',
+                credentials: 'include',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({operation, params: {}})
+            });
+            const result = await response.json();
+            if (result.success) {
+                loadSequence(id);
+            } else {
+                setError(result.error || 'Analysis failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to run analysis');
+        }
+    };
+
+    return (
+        <div className="tab-content">
+            {error && <div className="error">{error}</div>}
+
+            <div className="add-form">
+                <h3 className="add-form-title">Save Sequence</h3>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">Name</label>
+                        <input
+                            type="text"
+                            className="form-input"
+                            value={name}
+                            onChange={(e) => setName(e.target.value)}
+                            placeholder="e.g., pUC19 insert"
+                        />
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Description</label>
+                        <input
+                            type="text"
+                            className="form-input"
+                            value={description}
+                            onChange={(e) => setDescription(e.target.value)}
+                        />
+                    </div>
+                    <button className="btn btn-primary" onClick={saveSequence}>
+                        Save
+                    </button>
+                </div>
+                <div className="form-group">
+                    <label className="form-label">Sequence</label>
+                    <textarea
+                        className="form-input sequence-box"
+                        rows={4}
+                        value={sequence}
+                        onChange={(e) => setSequence(e.target.value)}
+                        placeholder="ACGT..."
+                    />
+                </div>
+            </div>
+
+            <div className="add-form">
+                <h3 className="add-form-title">Fetch by Accession</h3>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">NCBI nucleotide accessions</label>
+                        <input
+                            type="text"
+                            className="form-input"
+                            value={accessions}
+                            onChange={(e) => setAccessions(e.target.value)}
+                            placeholder="e.g., L09137.2, NC_001416.1"
+                        />
+                    </div>
+                    <button className="btn btn-primary" onClick={fetchAccessions} disabled={fetching}>
+                        {fetching ? 'Fetching...' : 'Fetch'}
+                    </button>
+                </div>
+            </div>
+
+            <div className="add-form">
+                <h3 className="add-form-title">Fetch Genomic Region</h3>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">Assembly</label>
+                        <select
+                            className="form-input"
+                            value={region.assembly}
+                            onChange={(e) => setRegion({...region, assembly: e.target.value})}
+                        >
+                            {['hg38', 'hg19', 'mm39', 'mm10', 'danRer11', 'dm6', 'ce11', 'sacCer3'].map(assembly => (
+                                <option key={assembly} value={assembly}>{assembly}</option>
+                            ))}
+                        </select>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Region</label>
+                        <input
+                            type="text"
+                            className="form-input"
+                            value={region.locus}
+                            onChange={(e) => setRegion({...region, locus: e.target.value})}
+                            placeholder="e.g., chr7:117,480,025-117,481,000"
+                        />
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Source</label>
+                        <select
+                            className="form-input"
+                            value={region.source}
+                            onChange={(e) => setRegion({...region, source: e.target.value})}
+                        >
+                            <option value="ucsc">UCSC</option>
+                            <option value="ensembl">Ensembl</option>
+                        </select>
+                    </div>
+                    <button className="btn btn-primary" onClick={fetchRegion} disabled={fetching}>
+                        {fetching ? 'Fetching...' : 'Fetch'}
+                    </button>
+                </div>
+            </div>
+
+            <div className="add-form">
+                <h3 className="add-form-title">Projects</h3>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">New project</label>
+                        <input
+                            type="text"
+                            className="form-input"
+                            value={projectName}
+                            onChange={(e) => setProjectName(e.target.value)}
+                            placeholder="e.g., GFP reporter constructs"
+                        />
+                    </div>
+                    <button className="btn btn-primary" onClick={createProject}>
+                        Create
+                    </button>
+                </div>
+                {projects.map(project => (
+                    <div key={project.id} className="result-item">
+                        <div className="result-item-header">
+                            <span className="result-item-name">
+                                {project.name} · {project.sequence_ids.length} sequences
+                            </span>
+                            <div className="library-actions">
+                                {selected && (project.sequence_ids.includes(selected.id) ? (
+                                    <button className="btn btn-primary"
+                                            onClick={() => updateProject(project.id, `sequences/${selected.id}`, 'DELETE')}>
+                                        Remove {selected.name}
+                                    </button>
+                                ) : (
+                                    <button className="btn btn-primary"
+                                            onClick={() => updateProject(project.id, `sequences/${selected.id}`, 'PUT')}>
+                                        Add {selected.name}
+                                    </button>
+                                ))}
+                                <button className="btn btn-success" onClick={() => updateProject(project.id, 'share', 'POST')}>
+                                    {project.share_url ? 'New link' : 'Share'}
+                                </button>
+                                {project.share_url && (
+                                    <button className="btn btn-danger" onClick={() => updateProject(project.id, 'share', 'DELETE')}>
+                                        Stop sharing
+                                    </button>
+                                )}
+                                <button className="btn btn-danger" onClick={() => deleteProject(project.id)}>
+                                    Delete
+                                </button>
+                            </div>
+                        </div>
+                        {project.share_url && (
+                            <input type="text" className="form-input" readOnly value={project.share_url}
+                                   onFocus={(e) => e.target.select()}/>
+                        )}
+                    </div>
+                ))}
+            </div>
+
+            <div className="library-header">
+                <h2 className="library-title">My Sequences</h2>
+                <span className="library-count">
+                    {sequences.length} saved
+                    {account && account.user && ` · signed in as ${account.user.login}`}
+                    {account && !account.user && account.oauth_enabled && (
+                        <> · <a href={`${apiBase}/auth/login`}>Sign in</a> to keep sequences across browsers</>
+                    )}
+                </span>
+            </div>
+
+            {sequences.length === 0 ? (
+                <div className="library-empty">
+                    <div className="library-empty-icon">🧬</div>
+                    <p>No saved sequences yet</p>
+                </div>
+            ) : (
+                <div className="library-list">
+                    {sequences.map(saved => (
+                        <div key={saved.id} className="library-item">
+                            <div className="library-item-header">
+                                <div className="library-item-info">
+                                    <h4>{saved.name}</h4>
+                                    <p className="library-item-meta">
+                                        Length: {saved.sequence.length}nt
+                                        {saved.description && ` · ${saved.description}`}
+                                    </p>
+                                </div>
+                                <div className="library-actions">
+                                    <button className="btn btn-primary" onClick={() => loadSequence(saved.id)}>
+                                        Open
+                                    </button>
+                                    <button className="btn btn-danger" onClick={() => deleteSequence(saved.id)}>
+                                        Delete
+                                    </button>
+                                </div>
+                            </div>
+
+                            {selected && selected.id === saved.id && (
+                                <div className="library-sequence">
+                                    <div className="sequence-box">{selected.sequence}</div>
+                                    <div className="action-buttons">
+                                        <select
+                                            className="form-input"
+                                            value={operation}
+                                            onChange={(e) => setOperation(e.target.value)}
+                                        >
+                                            {OPERATIONS.map(op => <option key={op} value={op}>{op}</option>)}
+                                        </select>
+                                        <button className="btn btn-success" onClick={() => runAnalysis(saved.id)}>
+                                            Run Analysis
+                                        </button>
+                                    </div>
+                                    {selected.analyses.map(analysis => (
+                                        <div key={analysis.id} className="result-item">
+                                            <div className="result-item-header">
+                                                <span className="result-item-name">{analysis.operation}</span>
+                                                <span className="result-meta">{analysis.created_at}</span>
+                                            </div>
+                                            <pre className="result-item-sequence">
+                                                {JSON.stringify(analysis.result, null, 2)}
+                                            </pre>
+                                        </div>
+                                    ))}
+                                    {versions.length > 0 && <h4>History</h4>}
+                                    {versions.slice().reverse().map(version => (
+                                        <div key={version.version} className="result-item">
+                                            <div className="result-item-header">
+                                                <span className="result-item-name">
+                                                    v{version.version} · {version.length}nt
+                                                    {version.version > 1 &&
+                                                        ` · ${version.substituted} substituted, ${version.inserted} inserted, ${version.deleted} deleted`}
+                                                </span>
+                                                <span className="result-meta">
+                                                    {version.author && `${version.author} · `}{version.created_at}
+                                                </span>
+                                            </div>
+                                            {version.version < versions.length && (
+                                                <button
+                                                    className="btn btn-primary"
+                                                    onClick={() => revertToVersion(saved.id, version.version)}
+                                                >
+                                                    Revert to v{version.version}
+                                                </button>
+                                            )}
+                                        </div>
+                                    ))}
+                                </div>
+                            )}
+                        </div>
+                    ))}
+                </div>
+            )}
+        </div>
+    );
+};
+
+export default MySequences;
@@ -0,0 +1,148 @@
+// NetworkGraph.jsx
+import React, {useEffect, useRef, useState} from 'react';
+
+const WIDTH = 720;
+const HEIGHT = 420;
+
+// Spring-electrical layout: nodes repel, edges pull their ends together, everything cools over the iterations
+const layout = (nodes, edges) => {
+    const positions = {};
+    nodes.forEach((node, i) => {
+        const angle = 2 * Math.PI * i / Math.max(nodes.length, 1);
+        positions[node.id] = {x: WIDTH / 2 + WIDTH / 3 * Math.cos(angle), y: HEIGHT / 2 + HEIGHT / 3 * Math.sin(angle)};
+    });
+    const ideal = Math.sqrt(WIDTH * HEIGHT / Math.max(nodes.length, 1)) * 0.6;
+    for (let step = 0; step < 300; step++) {
+        const temperature = 20 * (1 - step / 300);
+        const forces = Object.fromEntries(nodes.map(node => [node.id, {x: 0, y: 0}]));
+        nodes.forEach((a, i) => nodes.slice(i + 1).forEach(b => {
+            const dx = positions[a.id].x - positions[b.id].x || 0.01;
+            const dy = positions[a.id].y - positions[b.id].y;
+            const distance = Math.max(Math.hypot(dx, dy), 1);
+            const push = ideal * ideal / distance;
+            forces[a.id].x += dx / distance * push;
+            forces[a.id].y += dy / distance * push;
+            forces[b.id].x -= dx / distance * push;
+            forces[b.id].y -= dy / distance * push;
+        }));
+        edges.forEach(edge => {
+            const dx = positions[edge.source].x - positions[edge.target].x;
+            const dy = positions[edge.source].y - positions[edge.target].y;
+            const distance = Math.max(Math.hypot(dx, dy), 1);
+            const pull = distance * distance / ideal;
+            forces[edge.source].x -= dx / distance * pull;
+            forces[edge.source].y -= dy / distance * pull;
+            forces[edge.target].x += dx / distance * pull;
+            forces[edge.target].y += dy / distance * pull;
+        });
+        nodes.forEach(node => {
+            const force = forces[node.id];
+            const size = Math.max(Math.hypot(force.x, force.y), 1);
+            const move = Math.min(size, temperature);
+            positions[node.id] = {
+                x: Math.min(WIDTH - 30, Math.max(30, positions[node.id].x + force.x / size * move)),
+                y: Math.min(HEIGHT - 20, Math.max(20, positions[node.id].y + force.y / size * move))
+            };
+        });
+    }
+    return positions;
+};
+
+// Species and reaction nodes of a reaction network; drag nodes to rearrange, hover for kernels and reactions
+const NetworkGraph = ({nodes, edges}) => {
+    const [positions, setPositions] = useState({});
+    const [dragging, setDragging] = useState(null);
+    const [hover, setHover] = useState(null);
+    const svgRef = useRef(null);
+
+    useEffect(() => {
+        setPositions(layout(nodes, edges));
+    }, [nodes, edges]);
+
+    if (nodes.length === 0 || Object.keys(positions).length !== nodes.length) {
+        return null;
+    }
+
+    const pointer = (e) => {
+        const bounds = svgRef.current.getBoundingClientRect();
+        return {
+            x: (e.clientX - bounds.left) * (WIDTH / bounds.width),
+            y: (e.clientY - bounds.top) * (HEIGHT / bounds.height)
+        };
+    };
+
+    const onMouseMove = (e) => {
+        if (dragging) {
+            setPositions({...positions, [dragging]: pointer(e)});
+        }
+    };
+
+    const details = hover && nodes.find(node => node.id === hover);
+
+    return (
+        <div>
+            <svg
+                ref={svgRef}
+                viewBox={`0 0 ${WIDTH} ${HEIGHT}`}
+                style={{width: '100%', maxWidth: WIDTH, background: 'white', border: '1px solid #e5e7eb'}}
+                onMouseMove={onMouseMove}
+                onMouseUp={() => setDragging(null)}
+                onMouseLeave={() => setDragging(null)}
+            >
+                <defs>
+                    <marker id="network-arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6"
+                            markerHeight="6" orient="auto">
+                        <path d="M0,0 L10,5 L0,10 z" fill="#6b7280"/>
+                    </marker>
+                </defs>
+                {edges.map((edge, i) => {
+                    const from = positions[edge.source], to = positions[edge.target];
+                    const distance = Math.max(Math.hypot(to.x - from.x, to.y - from.y), 1);
+                    // Stop short of the target so the arrowhead stays visible
+                    const inset = edge.target.startsWith('r#') ? 6 : 18;
+                    const end = {x: to.x - (to.x - from.x) / distance * inset, y: to.y - (to.y - from.y) / distance * inset};
+                    return (
+                        <g key={i}>
+                            <line x1={from.x} y1={from.y} x2={end.x} y2={end.y} stroke="#9ca3af"
+                                  markerEnd="url(#network-arrow)"/>
+                            {edge.stoichiometry > 1 && (
+                                <text x={(from.x + end.x) / 2} y={(from.y + end.y) / 2 - 4} fontSize="10"
+                                      fill="#374151">{edge.stoichiometry}</text>
+                            )}
+                        </g>
+                    );
+                })}
+                {nodes.map(node => {
+                    const {x, y} = positions[node.id];
+                    const handlers = {
+                        onMouseDown: () => setDragging(node.id),
+                        onMouseEnter: () => setHover(node.id),
+                        onMouseLeave: () => setHover(null),
+                        style: {cursor: 'move'}
+                    };
+                    return node.type === 'species' ? (
+                        <g key={node.id} {...handlers}>
+                            <ellipse cx={x} cy={y} rx={Math.max(18, node.label.length * 4)} ry={12}
+                                     fill={node.initial ? '#dbeafe' : '#f9fafb'} stroke="#2563eb"/>
+                            <text x={x} y={y + 4} fontSize="11" textAnchor="middle" fill="#111827">{node.label}</text>
+                        </g>
+                    ) : (
+                        <g key={node.id} {...handlers}>
+                            <rect x={x - 5} y={y - 5} width={10} height={10} fill="#6b7280"/>
+                            {node.label && (
+                                <text x={x} y={y - 8} fontSize="9" textAnchor="middle" fill="#6b7280">{node.label}</text>
+                            )}
+                        </g>
+                    );
+                })}
+            </svg>
+            <p className="library-item-meta">
+                {details
+                    ? (details.type === 'species' ? `${details.label}: ${details.kernel || ''}` : details.reaction)
+                    : 'Drag nodes to rearrange; hover for details. Shaded species are the initial ones.'}
+            </p>
+        </div>
+    );
+};
+
+export default NetworkGraph;
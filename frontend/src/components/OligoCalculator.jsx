@@ -0,0 +1,198 @@
+// OligoCalculator.jsx
+import React, {useState} from 'react';
+import './OligoDesigner.css';
+
+const OligoCalculator = ({apiBase}) => {
+    const [sequence, setSequence] = useState('');
+    const [doubleStranded, setDoubleStranded] = useState(false);
+    const [ug, setUg] = useState('');
+    const [pmol, setPmol] = useState('');
+    const [method, setMethod] = useState('nearest_neighbor');
+    const [a260, setA260] = useState('');
+    const [dilution, setDilution] = useState('1');
+    const [result, setResult] = useState(null);
+    const [partner, setPartner] = useState('');
+    const [duplexes, setDuplexes] = useState(null);
+    const [loading, setLoading] = useState(false);
+    const [error, setError] = useState('');
+
+    const calculate = async () => {
+        if (!sequence.trim()) {
+            setError('Sequence is required');
+            return;
+        }
+        setError('');
+        setLoading(true);
+
+        try {
+            const response = await fetch(`${apiBase}/analysis/oligo-calc`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({
+                    sequence,
+                    double_stranded: doubleStranded,
+                    method,
+                    a260: a260 === '' ? undefined : parseFloat(a260),
+                    dilution: parseFloat(dilution) || 1,
+                    ug: ug === '' ? undefined : parseFloat(ug),
+                    pmol: pmol === '' ? undefined : parseFloat(pmol)
+                })
+            });
+            const data = await response.json();
+            if (data.success) {
+                setResult(data);
+            } else {
+                setError(data.error || 'Calculation failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    const annealOligos = async () => {
+        if (!sequence.trim() || !partner.trim()) {
+            setError('Two oligo sequences are required');
+            return;
+        }
+        setError('');
+        setLoading(true);
+
+        try {
+            const response = await fetch(`${apiBase}/analysis/anneal`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({sequence_a: sequence, sequence_b: partner})
+            });
+            const data = await response.json();
+            if (data.success) {
+                setDuplexes(data.duplexes);
+            } else {
+                setError(data.error || 'Annealing failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    return (
+        <div className="tab-content">
+            {error && <div className="error">{error}</div>}
+
+            <div className="add-form">
+                <h3 className="add-form-title">Oligo Calculator</h3>
+                <div className="form-group">
+                    <textarea
+                        className="form-input sequence-box"
+                        rows={3}
+                        value={sequence}
+                        onChange={(e) => setSequence(e.target.value)}
+                        placeholder="Oligo sequence (5' to 3')"
+                    />
+                </div>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">
+                            <input type="checkbox" checked={doubleStranded}
+                                   onChange={(e) => setDoubleStranded(e.target.checked)}/> Double-stranded
+                        </label>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Mass (µg)</label>
+                        <input type="number" className="form-input" value={ug} min="0" step="any"
+                               onChange={(e) => setUg(e.target.value)}/>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Amount (pmol)</label>
+                        <input type="number" className="form-input" value={pmol} min="0" step="any"
+                               onChange={(e) => setPmol(e.target.value)}/>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">ε260 model</label>
+                        <select className="form-input" value={method} onChange={(e) => setMethod(e.target.value)}>
+                            <option value="nearest_neighbor">Nearest-neighbor</option>
+                            <option value="base_sum">Base sum</option>
+                        </select>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Measured A260</label>
+                        <input type="number" className="form-input" value={a260} min="0" step="any"
+                               onChange={(e) => setA260(e.target.value)}/>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Dilution factor</label>
+                        <input type="number" className="form-input" value={dilution} min="1" step="any"
+                               onChange={(e) => setDilution(e.target.value)}/>
+                    </div>
+                    <button className="btn btn-primary" onClick={calculate} disabled={loading}>
+                        {loading ? 'Calculating...' : 'Calculate'}
+                    </button>
+                </div>
+
+                {result && (
+                    <div className="results-section">
+                        <table className="matrix-table">
+                            <tbody>
+                            <tr><th>Length</th><td>{result.length} {result.double_stranded ? 'bp' : 'nt'}</td></tr>
+                            <tr><th>Molecular weight</th><td>{result.molecular_weight} g/mol</td></tr>
+                            <tr><th>ε260</th><td>{result.extinction_coefficient} M⁻¹cm⁻¹</td></tr>
+                            <tr><th>nmol / OD260</th><td>{result.nmol_per_od}</td></tr>
+                            <tr><th>µg / OD260</th><td>{result.ug_per_od}</td></tr>
+                            {result.pmol !== undefined && <tr><th>{ug} µg</th><td>{result.pmol} pmol</td></tr>}
+                            {result.ug !== undefined && <tr><th>{pmol} pmol</th><td>{result.ug} µg</td></tr>}
+                            {result.concentration_um !== undefined && (
+                                <tr>
+                                    <th>Concentration</th>
+                                    <td>{result.concentration_um} µM · {result.concentration_ng_ul} ng/µl</td>
+                                </tr>
+                            )}
+                            </tbody>
+                        </table>
+                    </div>
+                )}
+            </div>
+
+            <div className="add-form">
+                <h3 className="add-form-title">Annealing</h3>
+                <div className="form-group">
+                    <label className="form-label">Second oligo (5' to 3'), annealed to the oligo above</label>
+                    <textarea
+                        className="form-input sequence-box"
+                        rows={3}
+                        value={partner}
+                        onChange={(e) => setPartner(e.target.value)}
+                    />
+                </div>
+                <button className="btn btn-primary" onClick={annealOligos} disabled={loading}>
+                    {loading ? 'Annealing...' : 'Anneal'}
+                </button>
+
+                {duplexes && (
+                    <div className="results-section">
+                        {duplexes.length === 0 && <div className="add-form-note">No annealing register found</div>}
+                        {duplexes.map(duplex => (
+                            <div key={duplex.offset} className="result-item">
+                                <img
+                                    alt="Duplex"
+                                    style={{maxWidth: '100%'}}
+                                    src={`data:image/svg+xml;charset=utf-8,${encodeURIComponent(duplex.svg)}`}
+                                />
+                                <div className="add-form-note">
+                                    {duplex.matches}/{duplex.overlap} paired · {duplex.mismatches} mismatches ·
+                                    best run {duplex.best_run_length} bp (ΔG37 {duplex.dg} kcal/mol) ·
+                                    overhangs 5'/3' top {duplex.overhangs.top_5}/{duplex.overhangs.top_3},
+                                    bottom {duplex.overhangs.bottom_5}/{duplex.overhangs.bottom_3}
+                                </div>
+                            </div>
+                        ))}
+                    </div>
+                )}
+            </div>
+        </div>
+    );
+};
+
+export default OligoCalculator;
@@ -1,6 +1,20 @@
 // OligoDesigner.jsx
 import React, {useState, useEffect} from 'react';
 import './OligoDesigner.css';
+import MySequences from './MySequences';
+import SequenceAnalysis from './SequenceAnalysis';
+import PlasmidMap from './PlasmidMap';
+import PhyloTree from './PhyloTree';
+import BlastSearch from './BlastSearch';
+import ComplexViewer from './ComplexViewer';
+import ReactionEnumerator from './ReactionEnumerator';
+import KineticsSimulator from './KineticsSimulator';
+import ParameterSweep from './ParameterSweep';
+import DomainLibrary from './DomainLibrary';
+import StructureDesign from './StructureDesign';
+import AlignmentViewer from './AlignmentViewer';
+import OligoCalculator from './OligoCalculator';
+import AnalysisPlugins from './AnalysisPlugins';
 
 const OligoDesigner = () => {
     const [activeTab, setActiveTab] = useState('domains');
@@ -513,7 +527,8 @@ const OligoDesigner = () => {
             {/* Tabs */}
             <div className="tabs">
                 <div className="tabs-nav">
-                    {['domains', 'strands'].map(tab => (
+                    {['domains', 'strands', 'sequences', 'analysis', 'plasmid', 'alignment', 'phylogeny', 'blast',
+                        'design', 'calculator', 'plugins'].map(tab => (
                         <button
                             key={tab}
                             className={`tab-button ${activeTab === tab ? 'active' : 'inactive'}`}
@@ -573,6 +588,8 @@ const OligoDesigner = () => {
                         </div>
                     </div>
 
+                    <DomainLibrary apiBase={API_BASE}/>
+
                     <div className="library-header">
                         <h2 className="library-title">Domain Instances</h2>
                         <span className="library-count">{domains.length} instances</span>
@@ -630,6 +647,33 @@ const OligoDesigner = () => {
                 </div>
             )}
 
+            {/* My Sequences Tab */}
+            {activeTab === 'sequences' && <MySequences apiBase={API_BASE}/>}
+
+            {/* Analysis Tab */}
+            {activeTab === 'analysis' && <SequenceAnalysis apiBase={API_BASE}/>}
+
+            {/* Plasmid Map Tab */}
+            {activeTab === 'plasmid' && <PlasmidMap apiBase={API_BASE}/>}
+
+            {/* Alignment Tab */}
+            {activeTab === 'alignment' && <AlignmentViewer apiBase={API_BASE}/>}
+
+            {/* Phylogeny Tab */}
+            {activeTab === 'phylogeny' && <PhyloTree apiBase={API_BASE}/>}
+
+            {/* BLAST Tab */}
+            {activeTab === 'blast' && <BlastSearch apiBase={API_BASE}/>}
+
+            {/* Structure Design Tab */}
+            {activeTab === 'design' && <StructureDesign apiBase={API_BASE}/>}
+
+            {/* Oligo Calculator Tab */}
+            {activeTab === 'calculator' && <OligoCalculator apiBase={API_BASE}/>}
+
+            {/* Plugins Tab */}
+            {activeTab === 'plugins' && <AnalysisPlugins apiBase={API_BASE}/>}
+
             {/* Strands Tab */}
             {activeTab === 'strands' && (
                 <div className="tab-content">
@@ -668,6 +712,14 @@ const OligoDesigner = () => {
                         </div>
                     </div>
 
+                    <ComplexViewer apiBase={API_BASE}/>
+
+                    <ReactionEnumerator apiBase={API_BASE}/>
+
+                    <KineticsSimulator apiBase={API_BASE}/>
+
+                    <ParameterSweep apiBase={API_BASE}/>
+
                     <div className="library-header">
                         <h2 className="library-title">Strand Library</h2>
                         <span className="library-count">{strands.length} strands</span>
@@ -0,0 +1,208 @@
+// ParameterSweep.jsx
+import React, {useState} from 'react';
+import LineChart from './LineChart';
+import './OligoDesigner.css';
+
+const EXAMPLE_SYSTEM = `gate = x( + ) t^*
+invader = t^ x`;
+
+const emptyParameter = (kind, target, start, stop) => ({kind, target, start, stop, steps: '5', log: false});
+
+// White (lowest) to blue (highest), as in the server's SVG heatmap
+const cellColor = (value, low, high) => {
+    if (value === null) {
+        return '#e5e7eb';
+    }
+    const share = high === low ? 0 : (value - low) / (high - low);
+    return `rgb(${Math.round(255 - 218 * share)},${Math.round(255 - 156 * share)},${Math.round(255 - 20 * share)})`;
+};
+
+const ParameterFields = ({parameter, onChange, title}) => (
+    <div className="add-form-grid">
+        <div className="form-group">
+            <label className="form-label">{title}</label>
+            <select className="form-input" value={parameter.kind}
+                    onChange={(e) => onChange({...parameter, kind: e.target.value})}>
+                <option value="initial">Initial amount</option>
+                <option value="rate">Rate constant</option>
+                <option value="toehold">Toehold length</option>
+            </select>
+        </div>
+        <div className="form-group">
+            <label className="form-label">Species, Reaction # or Domain</label>
+            <input type="text" className="form-input" value={parameter.target}
+                   onChange={(e) => onChange({...parameter, target: e.target.value})}/>
+        </div>
+        <div className="form-group">
+            <label className="form-label">From</label>
+            <input type="text" className="form-input" value={parameter.start}
+                   onChange={(e) => onChange({...parameter, start: e.target.value})}/>
+        </div>
+        <div className="form-group">
+            <label className="form-label">To</label>
+            <input type="text" className="form-input" value={parameter.stop}
+                   onChange={(e) => onChange({...parameter, stop: e.target.value})}/>
+        </div>
+        <div className="form-group">
+            <label className="form-label">Steps</label>
+            <input type="number" className="form-input" value={parameter.steps}
+                   onChange={(e) => onChange({...parameter, steps: e.target.value})}/>
+        </div>
+        <label className="form-label">
+            <input type="checkbox" checked={parameter.log}
+                   onChange={(e) => onChange({...parameter, log: e.target.checked})}/>
+            {' '}Log spacing
+        </label>
+    </div>
+);
+
+// Sweeps one or two simulation parameters and shows an output metric as a curve or a heatmap
+const ParameterSweep = ({apiBase}) => {
+    const [system, setSystem] = useState(EXAMPLE_SYSTEM);
+    const [dsd, setDsd] = useState(true);
+    const [initial, setInitial] = useState('gate = 1e-8\ninvader = 1e-8');
+    const [output, setOutput] = useState('x');
+    const [metric, setMetric] = useState('half_time');
+    const [tEnd, setTEnd] = useState('600');
+    const [first, setFirst] = useState(emptyParameter('toehold', 't^', '3', '8'));
+    const [second, setSecond] = useState(emptyParameter('initial', 'invader', '1e-9', '1e-7'));
+    const [useSecond, setUseSecond] = useState(true);
+    const [result, setResult] = useState(null);
+    const [loading, setLoading] = useState(false);
+    const [error, setError] = useState('');
+
+    const run = async () => {
+        setError('');
+        setLoading(true);
+        const amounts = {};
+        initial.split('\n').forEach(line => {
+            const [name, amount] = line.split('=').map(part => part.trim());
+            if (name && amount) {
+                amounts[name] = parseFloat(amount);
+            }
+        });
+        const parameter = (p) => ({
+            kind: p.kind, target: p.target, start: parseFloat(p.start), stop: parseFloat(p.stop),
+            steps: parseInt(p.steps, 10), log: p.log
+        });
+        try {
+            const response = await fetch(`${apiBase}/analysis/sweep`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({
+                    [dsd ? 'complexes' : 'crn']: system,
+                    parameters: useSecond ? [parameter(first), parameter(second)] : [parameter(first)],
+                    output, metric, initial: amounts, t_end: parseFloat(tEnd)
+                })
+            });
+            const data = await response.json();
+            if (data.success) {
+                setResult(data);
+            } else {
+                setError(data.error || 'Sweep failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    const values = result ? result.grid.flat().filter(value => value !== null) : [];
+    const low = Math.min(...values), high = Math.max(...values);
+
+    return (
+        <div className="add-form">
+            <h3 className="add-form-title">Parameter Sweep</h3>
+            {error && <div className="error">{error}</div>}
+            <div className="add-form-grid">
+                <div className="form-group">
+                    <label className="form-label">{dsd ? 'Complexes' : 'Reactions'}</label>
+                    <textarea className="form-input sequence-box" rows={4} value={system}
+                              onChange={(e) => setSystem(e.target.value)}/>
+                </div>
+                <div className="form-group">
+                    <label className="form-label">Initial Amounts</label>
+                    <textarea className="form-input sequence-box" rows={4} value={initial}
+                              onChange={(e) => setInitial(e.target.value)}/>
+                </div>
+            </div>
+            <div className="add-form-grid">
+                <label className="form-label">
+                    <input type="checkbox" checked={dsd} onChange={(e) => setDsd(e.target.checked)}/>
+                    {' '}DSD complexes (model rates)
+                </label>
+                <div className="form-group">
+                    <label className="form-label">Output</label>
+                    <input type="text" className="form-input" value={output}
+                           onChange={(e) => setOutput(e.target.value)}/>
+                </div>
+                <div className="form-group">
+                    <label className="form-label">Metric</label>
+                    <select className="form-input" value={metric} onChange={(e) => setMetric(e.target.value)}>
+                        <option value="final">Final amount</option>
+                        <option value="max">Maximum amount</option>
+                        <option value="half_time">Half-completion time</option>
+                    </select>
+                </div>
+                <div className="form-group">
+                    <label className="form-label">End Time</label>
+                    <input type="number" className="form-input" value={tEnd} onChange={(e) => setTEnd(e.target.value)}/>
+                </div>
+            </div>
+            <ParameterFields parameter={first} onChange={setFirst} title="Parameter (columns)"/>
+            <label className="form-label">
+                <input type="checkbox" checked={useSecond} onChange={(e) => setUseSecond(e.target.checked)}/>
+                {' '}Second parameter
+            </label>
+            {useSecond && <ParameterFields parameter={second} onChange={setSecond} title="Parameter (rows)"/>}
+            <button className="btn btn-primary" onClick={run} disabled={loading}>
+                {loading ? 'Sweeping...' : 'Run Sweep'}
+            </button>
+
+            {result && (
+                <div className="results-section">
+                    {result.y ? (
+                        <table className="matrix-table">
+                            <thead>
+                            <tr>
+                                <th>{result.y.label} \ {result.x.label}</th>
+                                {result.x.values.map(value => <th key={value}>{value.toPrecision(3)}</th>)}
+                            </tr>
+                            </thead>
+                            <tbody>
+                            {result.grid.map((row, i) => (
+                                <tr key={i}>
+                                    <th>{result.y.values[i].toPrecision(3)}</th>
+                                    {row.map((value, j) => (
+                                        <td key={j} style={{background: cellColor(value, low, high)}}>
+                                            {value === null ? 'n/a' : value.toPrecision(3)}
+                                        </td>
+                                    ))}
+                                </tr>
+                            ))}
+                            </tbody>
+                        </table>
+                    ) : (
+                        <LineChart
+                            series={[{
+                                label: `${result.metric} ${result.output}`,
+                                points: result.x.values.map((x, i) => ({x, y: result.grid[0][i]}))
+                                    .filter(point => point.y !== null)
+                            }]}
+                            xLabel={result.x.label} yLabel={result.metric}/>
+                    )}
+                    {result.summary.best && (
+                        <p className="library-item-meta">
+                            Best: {Object.entries(result.summary.best)
+                            .map(([name, value]) => `${name} ${value.toPrecision(3)}`).join(', ')}
+                        </p>
+                    )}
+                    {result.errors.map(message => <p className="error" key={message}>{message}</p>)}
+                </div>
+            )}
+        </div>
+    );
+};
+
+export default ParameterSweep;
@@ -0,0 +1,162 @@
+// PhyloTree.jsx
+import React, {useState} from 'react';
+import './OligoDesigner.css';
+
+const PhyloTree = ({apiBase}) => {
+    const [fasta, setFasta] = useState('');
+    const [model, setModel] = useState('jc69');
+    const [method, setMethod] = useState('nj');
+    const [tree, setTree] = useState(null);
+    const [loading, setLoading] = useState(false);
+    const [error, setError] = useState('');
+    const [treeA, setTreeA] = useState('');
+    const [treeB, setTreeB] = useState('');
+    const [comparison, setComparison] = useState(null);
+
+    const buildTree = async () => {
+        if (!fasta.trim()) {
+            setError('Aligned sequences are required');
+            return;
+        }
+        setError('');
+        setLoading(true);
+
+        try {
+            const response = await fetch(`${apiBase}/analysis/phylogeny`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({fasta, model, method})
+            });
+            const result = await response.json();
+            if (result.success) {
+                setTree(result);
+            } else {
+                setError(result.error || 'Tree construction failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    const compareTrees = async () => {
+        if (!treeA.trim() || !treeB.trim()) {
+            setError('Two Newick trees are required');
+            return;
+        }
+        setError('');
+
+        try {
+            const response = await fetch(`${apiBase}/analysis/phylogeny/compare`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({tree_a: treeA, tree_b: treeB})
+            });
+            const result = await response.json();
+            if (result.success) {
+                setComparison(result);
+            } else {
+                setError(result.error || 'Tree comparison failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        }
+    };
+
+    return (
+        <div className="tab-content">
+            {error && <div className="error">{error}</div>}
+
+            <div className="add-form">
+                <h3 className="add-form-title">Distance Tree</h3>
+                <div className="form-group">
+                    <textarea
+                        className="form-input sequence-box"
+                        rows={8}
+                        value={fasta}
+                        onChange={(e) => setFasta(e.target.value)}
+                        placeholder="Aligned FASTA (equal lengths, gaps as -)"
+                    />
+                </div>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">Distance model</label>
+                        <select className="form-input" value={model} onChange={(e) => setModel(e.target.value)}>
+                            <option value="p">p-distance</option>
+                            <option value="jc69">Jukes-Cantor</option>
+                            <option value="k2p">Kimura 2-parameter</option>
+                        </select>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Method</label>
+                        <select className="form-input" value={method} onChange={(e) => setMethod(e.target.value)}>
+                            <option value="nj">Neighbor-joining</option>
+                            <option value="upgma">UPGMA</option>
+                        </select>
+                    </div>
+                    <button className="btn btn-primary" onClick={buildTree} disabled={loading}>
+                        {loading ? 'Building...' : 'Build Tree'}
+                    </button>
+                </div>
+            </div>
+
+            {tree && (
+                <div className="results-section">
+                    <img
+                        alt="Phylogenetic tree"
+                        style={{maxWidth: '100%'}}
+                        src={`data:image/svg+xml;charset=utf-8,${encodeURIComponent(tree.svg)}`}
+                    />
+                    <h4>Newick</h4>
+                    <div className="sequence-box">{tree.newick}</div>
+                    <button className="btn btn-secondary" onClick={() => setTreeA(tree.newick)}>Use as Tree A</button>
+                    <button className="btn btn-secondary" onClick={() => setTreeB(tree.newick)}>Use as Tree B</button>
+                    <h4>Distance matrix</h4>
+                    <table className="matrix-table">
+                        <thead>
+                        <tr>
+                            <th></th>
+                            {tree.names.map(name => <th key={name}>{name}</th>)}
+                        </tr>
+                        </thead>
+                        <tbody>
+                        {tree.matrix.map((row, i) => (
+                            <tr key={tree.names[i]}>
+                                <th>{tree.names[i]}</th>
+                                {row.map((value, j) => <td key={j}>{value.toFixed(4)}</td>)}
+                            </tr>
+                        ))}
+                        </tbody>
+                    </table>
+                </div>
+            )}
+
+            <div className="add-form">
+                <h3 className="add-form-title">Compare Trees (Robinson-Foulds)</h3>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">Tree A (Newick)</label>
+                        <textarea className="form-input" rows={3} value={treeA}
+                                  onChange={(e) => setTreeA(e.target.value)}/>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Tree B (Newick)</label>
+                        <textarea className="form-input" rows={3} value={treeB}
+                                  onChange={(e) => setTreeB(e.target.value)}/>
+                    </div>
+                    <button className="btn btn-primary" onClick={compareTrees}>Compare</button>
+                </div>
+                {comparison && (
+                    <div className="results-section">
+                        RF distance {comparison.distance} of {comparison.max_distance} (normalized{' '}
+                        {comparison.normalized.toFixed(3)}); {comparison.shared_splits} shared splits,{' '}
+                        {comparison.only_in_a} only in A, {comparison.only_in_b} only in B
+                    </div>
+                )}
+            </div>
+        </div>
+    );
+};
+
+export default PhyloTree;
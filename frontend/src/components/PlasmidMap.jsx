@@ -0,0 +1,102 @@
+// PlasmidMap.jsx
+import React, {useState} from 'react';
+import './OligoDesigner.css';
+
+const PlasmidMap = ({apiBase}) => {
+    const [genbank, setGenbank] = useState('');
+    const [enzymes, setEnzymes] = useState('');
+    const [map, setMap] = useState(null);
+    const [loading, setLoading] = useState(false);
+    const [error, setError] = useState('');
+
+    const loadFile = (e) => {
+        const file = e.target.files[0];
+        if (!file) {
+            return;
+        }
+        const reader = new FileReader();
+        reader.onload = () => setGenbank(reader.result);
+        reader.readAsText(file);
+    };
+
+    const renderMap = async () => {
+        if (!genbank.trim()) {
+            setError('GenBank record is required');
+            return;
+        }
+        setError('');
+        setLoading(true);
+
+        const enzymeList = enzymes.split(/[\s,]+/).filter(Boolean);
+        try {
+            const response = await fetch(`${apiBase}/analysis/plasmid-map`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({genbank, enzymes: enzymeList.length ? enzymeList : undefined})
+            });
+            const result = await response.json();
+            if (result.success) {
+                setMap(result);
+            } else {
+                setError(result.error || 'Rendering failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    return (
+        <div className="tab-content">
+            {error && <div className="error">{error}</div>}
+
+            <div className="add-form">
+                <h3 className="add-form-title">Plasmid Map</h3>
+                <div className="form-group">
+                    <label className="form-label">GenBank file</label>
+                    <input type="file" accept=".gb,.gbk,.genbank,.txt" className="form-input" onChange={loadFile}/>
+                </div>
+                <div className="form-group">
+                    <textarea
+                        className="form-input sequence-box"
+                        rows={6}
+                        value={genbank}
+                        onChange={(e) => setGenbank(e.target.value)}
+                        placeholder="...or paste a GenBank record"
+                    />
+                </div>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">Enzymes (blank for single cutters)</label>
+                        <input type="text" className="form-input" value={enzymes}
+                               onChange={(e) => setEnzymes(e.target.value)} placeholder="EcoRI, BamHI"/>
+                    </div>
+                    <button className="btn btn-primary" onClick={renderMap} disabled={loading}>
+                        {loading ? 'Rendering...' : 'Draw Map'}
+                    </button>
+                </div>
+            </div>
+
+            {map && (
+                <div className="results-section">
+                    <img
+                        alt={`${map.name} plasmid map`}
+                        style={{maxWidth: '100%'}}
+                        src={`data:image/svg+xml;charset=utf-8,${encodeURIComponent(map.svg)}`}
+                    />
+                    <div className="add-form-note">
+                        {map.name} · {map.length} bp · {map.topology} · {map.features.length} features
+                    </div>
+                    {map.features.map((feature, i) => (
+                        <div key={i} className="result-item">
+                            <strong>{feature.label}</strong> ({feature.type}) {feature.location}
+                        </div>
+                    ))}
+                </div>
+            )}
+        </div>
+    );
+};
+
+export default PlasmidMap;
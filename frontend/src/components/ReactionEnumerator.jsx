@@ -0,0 +1,195 @@
+// ReactionEnumerator.jsx
+import React, {useMemo, useState} from 'react';
+import NetworkGraph from './NetworkGraph';
+import './OligoDesigner.css';
+
+const EXAMPLE_SYSTEM = `# Toehold-mediated strand displacement
+gate = x( + ) t^*
+invader = t^ x`;
+
+const download = (text, filename, type) => {
+    const url = URL.createObjectURL(new Blob([text], {type}));
+    const link = document.createElement('a');
+    link.href = url;
+    link.download = filename;
+    link.click();
+    URL.revokeObjectURL(url);
+};
+
+// Bipartite species/reaction graph of an enumeration, in the shape /analysis/network returns
+const networkGraph = (network) => {
+    const nodes = network.species.map(species => ({
+        id: species.name, type: 'species', label: species.name, kernel: species.kernel,
+        initial: network.initial.includes(species.name)
+    }));
+    const edges = [];
+    network.reactions.forEach((reaction, i) => {
+        const id = `r#${i + 1}`;
+        nodes.push({
+            id, type: 'reaction', label: reaction.kind,
+            reaction: `${reaction.reactants.join(' + ')} -> ${reaction.products.join(' + ')}`
+        });
+        [...new Set(reaction.reactants)].forEach(name => edges.push(
+            {source: name, target: id, stoichiometry: reaction.reactants.filter(other => other === name).length}));
+        [...new Set(reaction.products)].forEach(name => edges.push(
+            {source: id, target: name, stoichiometry: reaction.products.filter(other => other === name).length}));
+    });
+    return {nodes, edges};
+};
+
+// Reachable species and reactions of a DSD system, like Peppercorn
+const ReactionEnumerator = ({apiBase}) => {
+    const [system, setSystem] = useState(EXAMPLE_SYSTEM);
+    const [semantics, setSemantics] = useState('condensed');
+    const [releaseCutoff, setReleaseCutoff] = useState('7');
+    const [leak, setLeak] = useState(false);
+    const [network, setNetwork] = useState(null);
+    const [loading, setLoading] = useState(false);
+    const [error, setError] = useState('');
+    const graph = useMemo(() => network ? networkGraph(network) : null, [network]);
+
+    const options = () => ({complexes: system, semantics, release_cutoff: parseInt(releaseCutoff, 10)});
+
+    const enumerate = async () => {
+        setError('');
+        setLoading(true);
+        try {
+            const response = await fetch(`${apiBase}/analysis/enumerate`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({...options(), leak})
+            });
+            const result = await response.json();
+            if (result.success) {
+                setNetwork(result);
+            } else {
+                setError(result.error || 'Enumeration failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    const exportGraph = async (format) => {
+        setError('');
+        try {
+            const response = await fetch(`${apiBase}/analysis/network`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({...options(), format})
+            });
+            if (!response.ok) {
+                const result = await response.json();
+                setError(result.error || 'Export failed');
+                return;
+            }
+            const files = {
+                dot: ['network.dot', 'text/vnd.graphviz'],
+                graphml: ['network.graphml', 'application/graphml+xml'],
+                sbml: ['network.xml', 'application/sbml+xml']
+            };
+            download(await response.text(), ...files[format]);
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        }
+    };
+
+    return (
+        <div className="add-form">
+            <h3 className="add-form-title">Reaction Enumeration</h3>
+            {error && <div className="error">{error}</div>}
+            <div className="form-group">
+                <textarea
+                    className="form-input sequence-box"
+                    rows={6}
+                    value={system}
+                    onChange={(e) => setSystem(e.target.value)}
+                />
+            </div>
+            <div className="add-form-grid">
+                <div className="form-group">
+                    <label className="form-label">Semantics</label>
+                    <select className="form-input" value={semantics} onChange={(e) => setSemantics(e.target.value)}>
+                        <option value="condensed">Condensed (resting states)</option>
+                        <option value="infinite">Infinite (every step)</option>
+                    </select>
+                </div>
+                <div className="form-group">
+                    <label className="form-label">Release Cutoff (nt)</label>
+                    <input type="number" className="form-input" value={releaseCutoff}
+                           onChange={(e) => setReleaseCutoff(e.target.value)}/>
+                </div>
+                <label className="form-label">
+                    <input type="checkbox" checked={leak} onChange={(e) => setLeak(e.target.checked)}/>
+                    {' '}Leak analysis
+                </label>
+                <button className="btn btn-primary" onClick={enumerate} disabled={loading}>
+                    {loading ? 'Enumerating...' : 'Enumerate'}
+                </button>
+            </div>
+            <div className="add-form-note">
+                One "name = kernel" complex per line. Domains are 15 nt, toeholds marked t^ are 6 nt.
+            </div>
+
+            {network && (
+                <div className="results-section">
+                    <h4>{network.species.length} species, {network.reactions.length} reactions</h4>
+                    <table className="matrix-table">
+                        <thead>
+                        <tr>
+                            <th>Species</th>
+                            <th>Kernel</th>
+                        </tr>
+                        </thead>
+                        <tbody>
+                        {network.species.map(species => (
+                            <tr key={species.name}>
+                                <td>{species.name}</td>
+                                <td>
+                                    {species.kernel}
+                                    {species.ascii && <pre className="sequence-box">{species.ascii}</pre>}
+                                </td>
+                            </tr>
+                        ))}
+                        </tbody>
+                    </table>
+                    <pre className="sequence-box">
+                        {network.reactions.map(reaction =>
+                            `${reaction.kind}: ${reaction.reactants.join(' + ')} -> ${reaction.products.join(' + ')}`
+                        ).join('\n') || 'No reactions'}
+                    </pre>
+                    <NetworkGraph nodes={graph.nodes} edges={graph.edges}/>
+                    <div className="add-form-grid">
+                        <button className="btn btn-primary" onClick={() => exportGraph('dot')}>Download DOT</button>
+                        <button className="btn btn-primary" onClick={() => exportGraph('graphml')}>
+                            Download GraphML
+                        </button>
+                        <button className="btn btn-primary" onClick={() => exportGraph('sbml')}>Download SBML</button>
+                    </div>
+                    {network.leaks && (
+                        <>
+                            <h4>Leak score {network.leaks.score}</h4>
+                            <pre className="sequence-box">
+                                {network.leaks.leaks.map(item =>
+                                    `${item.kind} ${item.domain}: ${item.invader} + ${item.target} -> ` +
+                                    `${item.outcomes.map(outcome => outcome.join(' + ')).join(' | ')}` +
+                                    (item.intended ? ' (intended)' : item.productive ? ' (productive)' : '')
+                                ).join('\n') || 'No leak pathways'}
+                            </pre>
+                            {network.leaks.offenders.map(offender => (
+                                <p className="library-item-meta" key={offender.name}>
+                                    {offender.name}: {offender.productive} productive of {offender.leaks} leaks,
+                                    with {offender.partners.join(', ')}
+                                </p>
+                            ))}
+                        </>
+                    )}
+                </div>
+            )}
+        </div>
+    );
+};
+
+export default ReactionEnumerator;
@@ -0,0 +1,856 @@
+// SequenceAnalysis.jsx
+import React, {useState} from 'react';
+import './OligoDesigner.css';
+import LineChart from './LineChart';
+import VirtualGel, {LadderSelect} from './VirtualGel';
+import {gcContent, reverseComplement, sixFrameTranslate} from '../utils/sequence';
+
+const SequenceAnalysis = ({apiBase}) => {
+    const [sequence, setSequence] = useState('');
+    const [windowSize, setWindowSize] = useState('100');
+    const [step, setStep] = useState('10');
+    const [windows, setWindows] = useState(null);
+    const [dustWindow, setDustWindow] = useState('64');
+    const [dustThreshold, setDustThreshold] = useState('20');
+    const [hardMask, setHardMask] = useState(false);
+    const [complexity, setComplexity] = useState(null);
+    const [compareSequence, setCompareSequence] = useState('');
+    const [wordSize, setWordSize] = useState('10');
+    const [mismatches, setMismatches] = useState('0');
+    const [dotplotSvg, setDotplotSvg] = useState('');
+    const [dotBracket, setDotBracket] = useState('');
+    const [structureSvg, setStructureSvg] = useState('');
+    const [foldTemperature, setFoldTemperature] = useState('37');
+    const [foldPartner, setFoldPartner] = useState('');
+    const [foldConstraint, setFoldConstraint] = useState('');
+    const [foldTarget, setFoldTarget] = useState('');
+    const [ensemble, setEnsemble] = useState(null);
+    const [melt, setMelt] = useState(null);
+    const [minOrfLength, setMinOrfLength] = useState('300');
+    const [trackEnzymes, setTrackEnzymes] = useState('EcoRI, BamHI');
+    const [primers, setPrimers] = useState('');
+    const [tracks, setTracks] = useState(null);
+    const [lookupPosition, setLookupPosition] = useState('');
+    const [lookupHits, setLookupHits] = useState(null);
+    const [translation, setTranslation] = useState(null);
+    const [forwardPrimer, setForwardPrimer] = useState('');
+    const [reversePrimer, setReversePrimer] = useState('');
+    const [pcrMismatches, setPcrMismatches] = useState('2');
+    const [pcrCircular, setPcrCircular] = useState(false);
+    const [pcr, setPcr] = useState(null);
+    const [ladder, setLadder] = useState('1kb');
+    const [gelEnzymes, setGelEnzymes] = useState('EcoRI');
+    const [gelMethylation, setGelMethylation] = useState({dam: false, dcm: false, cpg: false});
+    const [gelLanes, setGelLanes] = useState('');
+    const [gel, setGel] = useState(null);
+    const [loading, setLoading] = useState(false);
+    const [error, setError] = useState('');
+
+    const runWindows = async () => {
+        if (!sequence.trim()) {
+            setError('Sequence is required');
+            return;
+        }
+        setError('');
+        setLoading(true);
+
+        try {
+            const response = await fetch(`${apiBase}/analysis/windows`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({sequence, window: parseInt(windowSize), step: parseInt(step)})
+            });
+            const result = await response.json();
+            if (result.success) {
+                setWindows(result);
+            } else {
+                setError(result.error || 'Analysis failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    const runComplexity = async () => {
+        if (!sequence.trim()) {
+            setError('Sequence is required');
+            return;
+        }
+        setError('');
+        setLoading(true);
+
+        try {
+            const response = await fetch(`${apiBase}/analysis/complexity`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({
+                    sequence,
+                    window: parseInt(dustWindow),
+                    threshold: parseFloat(dustThreshold),
+                    hard_mask: hardMask
+                })
+            });
+            const result = await response.json();
+            if (result.success) {
+                setComplexity(result);
+            } else {
+                setError(result.error || 'Analysis failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    const runDotplot = async () => {
+        if (!sequence.trim()) {
+            setError('Sequence is required');
+            return;
+        }
+        setError('');
+        setLoading(true);
+
+        try {
+            const response = await fetch(`${apiBase}/analysis/dotplot`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({
+                    sequence_a: sequence,
+                    sequence_b: compareSequence,
+                    name_b: compareSequence.trim() ? 'Sequence B' : 'Sequence A',
+                    word: parseInt(wordSize),
+                    mismatches: parseInt(mismatches)
+                })
+            });
+            const result = await response.json();
+            if (result.success) {
+                setDotplotSvg(result.svg);
+            } else {
+                setError(result.error || 'Dot plot failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    const runStructure = async () => {
+        if (!dotBracket.trim()) {
+            setError('A dot-bracket structure is required');
+            return;
+        }
+        setError('');
+        setLoading(true);
+
+        try {
+            // The sequence labels the bases only when it matches the structure's length
+            const bases = sequence.replace(/\s/g, '');
+            const structureLength = dotBracket.replace(/[\s&+]/g, '').length;
+            const response = await fetch(`${apiBase}/analysis/structure`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({structure: dotBracket, sequence: bases.length === structureLength ? bases : ''})
+            });
+            const result = await response.json();
+            if (result.success) {
+                setStructureSvg(result.svg);
+            } else {
+                setError(result.error || 'Structure drawing failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    const runFold = async () => {
+        if (!sequence.trim()) {
+            setError('Please enter a sequence');
+            return;
+        }
+        setError('');
+        setLoading(true);
+
+        try {
+            const response = await fetch(`${apiBase}/analysis/fold`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({
+                    sequence,
+                    sequence_b: foldPartner.replace(/\s/g, ''),
+                    constraint: foldConstraint,
+                    target: foldTarget.trim(),
+                    temperature: parseFloat(foldTemperature) || 37
+                })
+            });
+            const result = await response.json();
+            if (result.success) {
+                setEnsemble(result);
+            } else {
+                setError(result.error || 'Folding failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    const runMelt = async () => {
+        if (!sequence.trim()) {
+            setError('Please enter a sequence');
+            return;
+        }
+        setError('');
+        setLoading(true);
+
+        try {
+            const response = await fetch(`${apiBase}/analysis/fold/melt`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({sequence, sequence_b: foldPartner.replace(/\s/g, '')})
+            });
+            const result = await response.json();
+            if (result.success) {
+                setMelt({...result, duplex: Boolean(foldPartner.trim())});
+            } else {
+                setError(result.error || 'Melt curve failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    const runTracks = async () => {
+        if (!sequence.trim()) {
+            setError('Sequence is required');
+            return;
+        }
+        setError('');
+        setLoading(true);
+
+        // One primer per line: "name sequence" or just the sequence
+        const primerList = primers.split('\n').map(line => line.trim()).filter(Boolean).map((line, i) => {
+            const parts = line.split(/\s+/);
+            return parts.length > 1 ? {name: parts[0], sequence: parts[1]} : {name: `primer${i + 1}`, sequence: parts[0]};
+        });
+
+        try {
+            const response = await fetch(`${apiBase}/analysis/annotations`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({
+                    sequence,
+                    min_orf_length: parseInt(minOrfLength),
+                    enzymes: trackEnzymes.split(/[\s,]+/).filter(Boolean),
+                    primers: primerList
+                })
+            });
+            const result = await response.json();
+            if (result.success) {
+                setTracks(result);
+                setLookupHits(null);
+            } else {
+                setError(result.error || 'Annotation failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    // Features of the current tracks covering a 1-based position
+    const lookupFeatures = async () => {
+        const position = parseInt(lookupPosition);
+        if (!tracks || !(position >= 1)) {
+            setError('Show the tracks first and enter a position');
+            return;
+        }
+        setError('');
+
+        try {
+            const response = await fetch(`${apiBase}/analysis/annotations/query`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({
+                    annotations: tracks.tracks.flatMap(t => t.features),
+                    position: position - 1
+                })
+            });
+            const result = await response.json();
+            if (result.success) {
+                setLookupHits(result.annotations);
+            } else {
+                setError(result.error || 'Lookup failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        }
+    };
+
+    // Translation, reverse complement and GC run in the browser, so they need no server and work offline
+    const runTranslate = () => {
+        if (!sequence.trim()) {
+            setError('Sequence is required');
+            return;
+        }
+        setError('');
+        setTranslation({
+            ...sixFrameTranslate(sequence),
+            gc: gcContent(sequence),
+            reverse_complement: reverseComplement(sequence)
+        });
+    };
+
+    const runPcr = async () => {
+        if (!sequence.trim() || !forwardPrimer.trim() || !reversePrimer.trim()) {
+            setError('Template sequence and both primers are required');
+            return;
+        }
+        setError('');
+        setLoading(true);
+
+        try {
+            const response = await fetch(`${apiBase}/analysis/pcr`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({
+                    sequence,
+                    forward: forwardPrimer,
+                    reverse: reversePrimer,
+                    max_mismatches: parseInt(pcrMismatches) || 0,
+                    circular: pcrCircular,
+                    ladder
+                })
+            });
+            const result = await response.json();
+            if (result.success) {
+                setPcr(result);
+            } else {
+                setError(result.error || 'PCR simulation failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    // Digest of the sequence (when enzymes are given) followed by one lane per line of "name: sizes"
+    const runGel = async () => {
+        const lanes = [];
+        const enzymes = gelEnzymes.split(/[\s,]+/).filter(Boolean);
+        if (sequence.trim() && enzymes.length) {
+            const methylation = Object.keys(gelMethylation).filter(name => gelMethylation[name]);
+            lanes.push({name: enzymes.join('+'), sequence, enzymes, methylation});
+        }
+        gelLanes.split('\n').filter(line => line.trim()).forEach(line => {
+            const [name, sizes] = line.includes(':') ? line.split(':') : ['', line];
+            lanes.push({name: name.trim(), sizes: sizes.split(/[\s,]+/).filter(Boolean).map(Number)});
+        });
+        if (!lanes.length) {
+            setError('Enter enzymes to digest the sequence or lanes of fragment sizes');
+            return;
+        }
+        setError('');
+        setLoading(true);
+
+        try {
+            const response = await fetch(`${apiBase}/analysis/gel`, {
+                method: 'POST',
+                headers: {'Content-Type': 'application/json'},
+                body: JSON.stringify({lanes, ladder})
+            });
+            const result = await response.json();
+            if (result.success) {
+                setGel(result);
+            } else {
+                setError(result.error || 'Gel rendering failed');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        } finally {
+            setLoading(false);
+        }
+    };
+
+    // Nucleotides in blocks of 60 with the forward frames above and reverse frames below
+    const translationBlocks = (result, width = 60) => {
+        const blocks = [];
+        const forward = result.frames.filter(f => f.strand === '+');
+        const reverse = result.frames.filter(f => f.strand === '-');
+        for (let start = 0; start < result.length; start += width) {
+            const slice = text => text.slice(start, start + width);
+            blocks.push([
+                ...forward.map(f => `${f.frame.padEnd(8)}${slice(f.aligned)}`),
+                `${String(start + 1).padEnd(8)}${slice(result.sequence)}`,
+                ...reverse.map(f => `${f.frame.padEnd(8)}${slice(f.aligned)}`)
+            ].join('\n'));
+        }
+        return blocks.join('\n\n');
+    };
+
+    return (
+        <div className="tab-content">
+            {error && <div className="error">{error}</div>}
+
+            <div className="add-form">
+                <h3 className="add-form-title">Sequence</h3>
+                <div className="form-group">
+                    <textarea
+                        className="form-input sequence-box"
+                        rows={5}
+                        value={sequence}
+                        onChange={(e) => setSequence(e.target.value)}
+                        placeholder="Paste a DNA sequence"
+                    />
+                </div>
+            </div>
+
+            <div className="add-form">
+                <h3 className="add-form-title">GC Content and Skew</h3>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">Window (nt)</label>
+                        <input type="number" className="form-input" value={windowSize} min="1"
+                               onChange={(e) => setWindowSize(e.target.value)}/>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Step (nt)</label>
+                        <input type="number" className="form-input" value={step} min="1"
+                               onChange={(e) => setStep(e.target.value)}/>
+                    </div>
+                    <button className="btn btn-primary" onClick={runWindows} disabled={loading}>
+                        {loading ? 'Analyzing...' : 'Plot'}
+                    </button>
+                </div>
+
+                {windows && windows.points.length > 0 && (
+                    <div className="results-section">
+                        <LineChart
+                            xLabel="Position (nt)"
+                            yLabel="GC %"
+                            series={[{
+                                label: 'GC %',
+                                points: windows.points.map(p => ({x: p.center, y: p.gc_content}))
+                            }]}
+                        />
+                        <LineChart
+                            xLabel="Position (nt)"
+                            yLabel="Skew"
+                            series={[
+                                {label: 'GC skew', points: windows.points.map(p => ({x: p.center, y: p.gc_skew}))},
+                                {label: 'AT skew', points: windows.points.map(p => ({x: p.center, y: p.at_skew}))},
+                                {
+                                    label: 'Cumulative GC skew',
+                                    points: windows.cumulative_gc_skew.map(p => ({x: p.center, y: p.cumulative_gc_skew}))
+                                }
+                            ]}
+                        />
+                        <div className="add-form-note">
+                            Cumulative GC skew minimum (putative origin): {windows.extremes.origin} nt ·
+                            maximum (putative terminus): {windows.extremes.terminus} nt
+                        </div>
+                    </div>
+                )}
+            </div>
+
+            <div className="add-form">
+                <h3 className="add-form-title">Complexity and Low-Complexity Masking</h3>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">DUST window (nt)</label>
+                        <input type="number" className="form-input" value={dustWindow} min="3"
+                               onChange={(e) => setDustWindow(e.target.value)}/>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">DUST threshold</label>
+                        <input type="number" className="form-input" value={dustThreshold} step="0.5"
+                               onChange={(e) => setDustThreshold(e.target.value)}/>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">
+                            <input type="checkbox" checked={hardMask}
+                                   onChange={(e) => setHardMask(e.target.checked)}/> Mask with N
+                        </label>
+                    </div>
+                    <button className="btn btn-primary" onClick={runComplexity} disabled={loading}>
+                        {loading ? 'Analyzing...' : 'Analyze'}
+                    </button>
+                </div>
+
+                {complexity && (
+                    <div className="results-section">
+                        <div className="result-item">
+                            Entropy: {complexity.entropy} bits · Dinucleotide entropy: {complexity.dinucleotide_entropy} bits ·
+                            Linguistic complexity: {complexity.linguistic_complexity} ·
+                            Masked: {(complexity.masked_fraction * 100).toFixed(1)}%
+                        </div>
+                        <div className="result-item">
+                            Low-complexity regions: {complexity.low_complexity_regions.length === 0 ? 'none' :
+                            complexity.low_complexity_regions.map(r => `${r.start + 1}-${r.end}`).join(', ')}
+                        </div>
+                        <div className="sequence-box">{complexity.masked_sequence}</div>
+                    </div>
+                )}
+            </div>
+
+            <div className="add-form">
+                <h3 className="add-form-title">Dot Plot</h3>
+                <div className="form-group">
+                    <label className="form-label">Compare against (leave empty for a self comparison)</label>
+                    <textarea
+                        className="form-input sequence-box"
+                        rows={3}
+                        value={compareSequence}
+                        onChange={(e) => setCompareSequence(e.target.value)}
+                        placeholder="Second DNA sequence"
+                    />
+                </div>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">Word size</label>
+                        <input type="number" className="form-input" value={wordSize} min="1"
+                               onChange={(e) => setWordSize(e.target.value)}/>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Mismatches</label>
+                        <input type="number" className="form-input" value={mismatches} min="0"
+                               onChange={(e) => setMismatches(e.target.value)}/>
+                    </div>
+                    <button className="btn btn-primary" onClick={runDotplot} disabled={loading}>
+                        {loading ? 'Plotting...' : 'Plot'}
+                    </button>
+                </div>
+
+                {dotplotSvg && (
+                    <div className="results-section">
+                        <img
+                            alt="Dot plot"
+                            style={{maxWidth: '100%'}}
+                            src={`data:image/svg+xml;charset=utf-8,${encodeURIComponent(dotplotSvg)}`}
+                        />
+                        <div className="add-form-note">
+                            Blue: forward matches · Red: reverse-complement matches (inversions)
+                        </div>
+                    </div>
+                )}
+            </div>
+
+            <div className="add-form">
+                <h3 className="add-form-title">Secondary Structure</h3>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">Dot-bracket (strands separated by &)</label>
+                        <input
+                            type="text"
+                            className="form-input sequence-box"
+                            value={dotBracket}
+                            onChange={(e) => setDotBracket(e.target.value)}
+                            placeholder="e.g., ((((....))))&((..))"
+                        />
+                    </div>
+                    <button className="btn btn-primary" onClick={runStructure} disabled={loading}>
+                        {loading ? 'Drawing...' : 'Draw'}
+                    </button>
+                </div>
+
+                {structureSvg && (
+                    <div className="results-section">
+                        <img
+                            alt="Secondary structure"
+                            style={{maxWidth: '100%'}}
+                            src={`data:image/svg+xml;charset=utf-8,${encodeURIComponent(structureSvg)}`}
+                        />
+                        <div className="add-form-note">
+                            Bases are labeled when the sequence above matches the structure's length
+                        </div>
+                    </div>
+                )}
+            </div>
+
+            <div className="add-form">
+                <h3 className="add-form-title">Folding Ensemble</h3>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">Temperature (°C)</label>
+                        <input
+                            type="number"
+                            className="form-input"
+                            value={foldTemperature}
+                            onChange={(e) => setFoldTemperature(e.target.value)}
+                        />
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Second strand (optional)</label>
+                        <input
+                            type="text"
+                            className="form-input sequence-box"
+                            value={foldPartner}
+                            onChange={(e) => setFoldPartner(e.target.value)}
+                            placeholder="Fold with a target or invader"
+                        />
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Constraint (optional)</label>
+                        <input
+                            type="text"
+                            className="form-input sequence-box"
+                            value={foldConstraint}
+                            onChange={(e) => setFoldConstraint(e.target.value)}
+                            placeholder="x unpaired, | paired, () forced pair, . free"
+                        />
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Target structure (optional)</label>
+                        <input
+                            type="text"
+                            className="form-input sequence-box"
+                            value={foldTarget}
+                            onChange={(e) => setFoldTarget(e.target.value)}
+                            placeholder="Dot-bracket the design should fold into"
+                        />
+                    </div>
+                    <button className="btn btn-primary" onClick={runFold} disabled={loading}>
+                        {loading ? 'Folding...' : 'Fold'}
+                    </button>
+                    <button className="btn btn-secondary" onClick={runMelt} disabled={loading}>
+                        Melt Curve
+                    </button>
+                </div>
+
+                {melt && (
+                    <div className="results-section">
+                        <p className="library-item-meta">
+                            {melt.tm !== null ? `Tm ≈ ${melt.tm} °C` : 'No melting transition in 20-90 °C'}
+                            {melt.duplex ? ' (250 nM each strand, 1 M Na+)' : ' (1 M Na+)'}
+                        </p>
+                        <LineChart
+                            xLabel="Temperature (°C)"
+                            yLabel={melt.duplex ? 'Fraction bound' : 'Fraction of bases paired'}
+                            series={[{
+                                label: melt.duplex ? 'Bound' : 'Paired',
+                                points: melt.points.map(p => ({x: p.temperature, y: melt.duplex ? p.bound : p.paired}))
+                            }]}
+                        />
+                    </div>
+                )}
+
+                {ensemble && (
+                    <div className="results-section">
+                        <p className="library-item-meta">
+                            Ensemble ΔG {ensemble.ensemble_dg} kcal/mol · centroid ΔG {ensemble.centroid_dg} kcal/mol,
+                            {' '}{(ensemble.centroid_probability * 100).toFixed(1)}% of the ensemble
+                            {ensemble.cut !== null && ` · binding ΔG ${ensemble.binding_dg} kcal/mol`}
+                        </p>
+                        {ensemble.defect !== undefined && (
+                            <p className="library-item-meta">
+                                Ensemble defect {ensemble.defect} nt
+                                ({(ensemble.normalized_defect * 100).toFixed(1)}% of bases out of their target state)
+                            </p>
+                        )}
+                        <div className="sequence-box">{ensemble.centroid}</div>
+                        <img
+                            alt="Base-pair probability dot plot"
+                            style={{maxWidth: '100%'}}
+                            src={`data:image/svg+xml;charset=utf-8,${encodeURIComponent(ensemble.dot_plot)}`}
+                        />
+                        <img
+                            alt="Centroid structure"
+                            style={{maxWidth: '100%'}}
+                            src={`data:image/svg+xml;charset=utf-8,${encodeURIComponent(ensemble.centroid_svg)}`}
+                        />
+                        <div className="add-form-note">
+                            Upper triangle: pair probabilities (square area) · Lower triangle: centroid pairs
+                            (probability above 50%). A low centroid share means the strand has no dominant fold.
+                            With a second strand, probabilities assume the two are bound and blue lines mark the
+                            strand break.
+                        </div>
+                    </div>
+                )}
+            </div>
+
+            <div className="add-form">
+                <h3 className="add-form-title">Feature Tracks</h3>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">Min ORF length (nt)</label>
+                        <input type="number" className="form-input" value={minOrfLength} min="3"
+                               onChange={(e) => setMinOrfLength(e.target.value)}/>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Enzymes</label>
+                        <input type="text" className="form-input" value={trackEnzymes}
+                               onChange={(e) => setTrackEnzymes(e.target.value)}/>
+                    </div>
+                </div>
+                <div className="form-group">
+                    <label className="form-label">Primers (one per line: name sequence)</label>
+                    <textarea className="form-input sequence-box" rows={3} value={primers}
+                              onChange={(e) => setPrimers(e.target.value)}/>
+                </div>
+                <button className="btn btn-primary" onClick={runTracks} disabled={loading}>
+                    {loading ? 'Annotating...' : 'Show Tracks'}
+                </button>
+
+                {tracks && (
+                    <div className="results-section">
+                        <img
+                            alt="Feature tracks"
+                            style={{maxWidth: '100%'}}
+                            src={`data:image/svg+xml;charset=utf-8,${encodeURIComponent(tracks.svg)}`}
+                        />
+                        <div className="add-form-note">
+                            {tracks.tracks.map(t => `${t.name}: ${t.features.length}`).join(' · ') || 'No features found'}
+                        </div>
+                        <div className="add-form-grid">
+                            <div className="form-group">
+                                <label className="form-label">Features at position</label>
+                                <input type="number" className="form-input" value={lookupPosition} min="1"
+                                       onChange={(e) => setLookupPosition(e.target.value)}/>
+                            </div>
+                            <button className="btn btn-primary" onClick={lookupFeatures}>Look Up</button>
+                        </div>
+                        {lookupHits && (
+                            <div className="add-form-note">
+                                {lookupHits.map(f => `${f.label || f.type} (${f.track}) ${f.start + 1}..${f.end}`)
+                                    .join(' · ') || 'No features cover this position'}
+                            </div>
+                        )}
+                    </div>
+                )}
+            </div>
+
+            <div className="add-form">
+                <h3 className="add-form-title">Six-Frame Translation</h3>
+                <button className="btn btn-primary" onClick={runTranslate}>Translate</button>
+
+                {translation && (
+                    <div className="results-section">
+                        <pre className="sequence-box">{translationBlocks(translation)}</pre>
+                        <div className="add-form-note">
+                            {translation.frames.map(f => `${f.frame}: ${f.stops.length} stops`).join(' · ')}
+                        </div>
+                        <div className="add-form-note">
+                            {translation.length} nt · GC {translation.gc.toFixed(1)}%
+                        </div>
+                        <label className="form-label">Reverse complement</label>
+                        <pre className="sequence-box">{translation.reverse_complement}</pre>
+                    </div>
+                )}
+            </div>
+
+            <div className="add-form">
+                <h3 className="add-form-title">In-Silico PCR</h3>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">Forward primer (5' to 3')</label>
+                        <input type="text" className="form-input" value={forwardPrimer}
+                               onChange={(e) => setForwardPrimer(e.target.value)}/>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Reverse primer (5' to 3')</label>
+                        <input type="text" className="form-input" value={reversePrimer}
+                               onChange={(e) => setReversePrimer(e.target.value)}/>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Max mismatches</label>
+                        <input type="number" className="form-input" value={pcrMismatches} min="0"
+                               onChange={(e) => setPcrMismatches(e.target.value)}/>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">
+                            <input type="checkbox" checked={pcrCircular}
+                                   onChange={(e) => setPcrCircular(e.target.checked)}/> Circular template
+                        </label>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Ladder</label>
+                        <LadderSelect value={ladder} onChange={setLadder}/>
+                    </div>
+                    <button className="btn btn-primary" onClick={runPcr} disabled={loading}>
+                        {loading ? 'Amplifying...' : 'Run PCR'}
+                    </button>
+                </div>
+
+                {pcr && (
+                    <div className="results-section">
+                        <VirtualGel svg={pcr.gel_svg} filename="pcr-gel"/>
+                        {pcr.amplicons.length === 0 && <div className="add-form-note">No products predicted</div>}
+                        {pcr.amplicons.length > 0 && (
+                            <table className="matrix-table">
+                                <thead>
+                                <tr><th>Primers</th><th>Start</th><th>End</th><th>Size (bp)</th><th>Mismatches</th></tr>
+                                </thead>
+                                <tbody>
+                                {pcr.amplicons.map(a => (
+                                    <tr key={`${a.forward_primer}-${a.reverse_primer}-${a.start}-${a.end}`}>
+                                        <td>{a.forward_primer} / {a.reverse_primer}</td>
+                                        <td>{a.start + 1}</td>
+                                        <td>{a.end}</td>
+                                        <td>{a.size}</td>
+                                        <td>{a.forward_mismatches} / {a.reverse_mismatches}</td>
+                                    </tr>
+                                ))}
+                                </tbody>
+                            </table>
+                        )}
+                    </div>
+                )}
+            </div>
+
+            <div className="add-form">
+                <h3 className="add-form-title">Virtual Gel</h3>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">Digest the sequence with</label>
+                        <input type="text" className="form-input" value={gelEnzymes}
+                               onChange={(e) => setGelEnzymes(e.target.value)}/>
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Methylation</label>
+                        {[['dam', 'Dam'], ['dcm', 'Dcm'], ['cpg', 'CpG']].map(([name, label]) => (
+                            <label key={name} className="form-label">
+                                <input type="checkbox" checked={gelMethylation[name]}
+                                       onChange={(e) => setGelMethylation({...gelMethylation, [name]: e.target.checked})}/>
+                                {' '}{label}
+                            </label>
+                        ))}
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Ladder</label>
+                        <LadderSelect value={ladder} onChange={setLadder}/>
+                    </div>
+                </div>
+                <div className="form-group">
+                    <label className="form-label">Extra lanes (one per line: name: sizes in bp)</label>
+                    <textarea className="form-input sequence-box" rows={3} value={gelLanes}
+                              placeholder="PCR: 520, 90"
+                              onChange={(e) => setGelLanes(e.target.value)}/>
+                </div>
+                <button className="btn btn-primary" onClick={runGel} disabled={loading}>
+                    {loading ? 'Running...' : 'Run Gel'}
+                </button>
+
+                {gel && (
+                    <div className="results-section">
+                        <VirtualGel svg={gel.svg}/>
+                        <div className="add-form-note">
+                            {gel.lanes.map(l => `${l.name}: ${l.sizes.join(', ') || 'no bands'}`).join(' · ')}
+                        </div>
+                    </div>
+                )}
+            </div>
+        </div>
+    );
+};
+
+export default SequenceAnalysis;
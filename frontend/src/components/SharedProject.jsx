@@ -0,0 +1,73 @@
+// SharedProject.jsx
+import React, {useState, useEffect} from 'react';
+import './OligoDesigner.css';
+
+const SharedProject = ({apiBase, token}) => {
+    const [shared, setShared] = useState(null);
+    const [error, setError] = useState('');
+
+    useEffect(() => {
+        const loadShared = async () => {
+            try {
+                const response = await fetch(`${apiBase}/workspace/shared/${encodeURIComponent(token)}`);
+                const result = await response.json();
+                if (result.success) {
+                    setShared(result);
+                } else {
+                    setError(result.error || 'Failed to load shared project');
+                }
+            } catch (err) {
+                setError('Network error: Unable to load shared project');
+            }
+        };
+        loadShared();
+    }, [apiBase, token]);
+
+    if (error) {
+        return <div className="tab-content"><div className="error">{error}</div></div>;
+    }
+    if (!shared) {
+        return <div className="tab-content"><p>Loading shared project…</p></div>;
+    }
+
+    return (
+        <div className="tab-content">
+            <div className="library-header">
+                <h2 className="library-title">{shared.project.name}</h2>
+                <span className="library-count">
+                    Read-only · {shared.sequences.length} sequences
+                    {shared.project.description && ` · ${shared.project.description}`}
+                </span>
+            </div>
+            <div className="library-list">
+                {shared.sequences.map(saved => (
+                    <div key={saved.id} className="library-item">
+                        <div className="library-item-info">
+                            <h4>{saved.name}</h4>
+                            <p className="library-item-meta">
+                                Length: {saved.sequence.length}nt
+                                {saved.description && ` · ${saved.description}`}
+                            </p>
+                        </div>
+                        <div className="library-sequence">
+                            <div className="sequence-box">{saved.sequence}</div>
+                            {saved.analyses.map(analysis => (
+                                <div key={analysis.id} className="result-item">
+                                    <div className="result-item-header">
+                                        <span className="result-item-name">{analysis.operation}</span>
+                                        <span className="result-meta">{analysis.created_at}</span>
+                                    </div>
+                                    <pre className="result-item-sequence">
+                                        {JSON.stringify(analysis.result, null, 2)}
+                                    </pre>
+                                </div>
+                            ))}
+                        </div>
+                    </div>
+                ))}
+            </div>
+        </div>
+    );
+};
+
+export default SharedProject;
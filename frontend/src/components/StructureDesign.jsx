@@ -0,0 +1,160 @@
+// StructureDesign.jsx
+import React, {useEffect, useState} from 'react';
+import LineChart from './LineChart';
+import './OligoDesigner.css';
+
+const EXAMPLE_SPEC = `# Two hairpins joined by a linker
+target ((((((....))))))....((((((....))))))
+ban GGGG
+ban AAAA
+gc 40 60 window 12
+orthogonal 1-6 21-26 dg -5
+weight orthogonal 0.2`;
+
+const StructureDesign = ({apiBase}) => {
+    const [spec, setSpec] = useState(EXAMPLE_SPEC);
+    const [temperature, setTemperature] = useState(37);
+    const [seed, setSeed] = useState('');
+    const [job, setJob] = useState(null);
+    const [trace, setTrace] = useState([]);
+    const [design, setDesign] = useState(null);
+    const [error, setError] = useState('');
+
+    const running = job && (job.status === 'queued' || job.status === 'running');
+
+    useEffect(() => {
+        if (!job || !running) {
+            return undefined;
+        }
+        // The stream ends with the finished job; the browser would otherwise reconnect
+        const source = new EventSource(`${apiBase}/v1/jobs/${job.id}/events`, {withCredentials: true});
+        source.onmessage = (event) => {
+            const state = JSON.parse(event.data);
+            if (state.detail && state.detail.iteration) {
+                setTrace(points => points.length && points[points.length - 1].x === state.detail.iteration
+                    ? points : [...points, {x: state.detail.iteration, y: state.detail.objective}]);
+            }
+            if (state.status === 'completed') {
+                setDesign(state.results[0]);
+            } else if (state.status === 'failed') {
+                setError(state.error || 'Design failed');
+            }
+            if (['completed', 'failed', 'cancelled'].includes(state.status)) {
+                source.close();
+                setJob(state);
+            }
+        };
+        source.onerror = () => {
+            setError('Lost the connection to the design job');
+            source.close();
+            setJob(null);
+        };
+        return () => source.close();
+    }, [apiBase, job, running]);
+
+    const runDesign = async () => {
+        setError('');
+        setDesign(null);
+        setTrace([]);
+
+        try {
+            const params = {spec, temperature: parseFloat(temperature)};
+            if (seed !== '') {
+                params.seed = parseInt(seed, 10);
+            }
+            const response = await fetch(`${apiBase}/v1/jobs`, {
+                method: 'POST',
+                credentials: 'include',
+                headers: {'Content-Type': 'application/json'},
+                // An all-N template leaves the length to the spec's target
+                body: JSON.stringify({operation: 'design', sequences: [{name: 'design', sequence: 'N'}], params})
+            });
+            const result = await response.json();
+            if (result.success) {
+                setJob(result.job);
+            } else {
+                setError(result.error || 'Failed to start the design');
+            }
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        }
+    };
+
+    const cancelDesign = async () => {
+        try {
+            await fetch(`${apiBase}/v1/jobs/${job.id}/cancel`, {method: 'POST', credentials: 'include'});
+        } catch (err) {
+            setError('Network error: Unable to connect to server');
+        }
+    };
+
+    return (
+        <div className="tab-content">
+            {error && <div className="error">{error}</div>}
+
+            <div className="add-form">
+                <h3 className="add-form-title">Structure Design</h3>
+                <div className="form-group">
+                    <label className="form-label">Design Spec</label>
+                    <textarea
+                        className="form-input sequence-box"
+                        rows={10}
+                        value={spec}
+                        onChange={(e) => setSpec(e.target.value)}
+                    />
+                </div>
+                <p className="add-form-note">
+                    One statement per line: target, template, ban MOTIF, gc MIN MAX [window W], force POS BASES,
+                    orthogonal 1-10 21-30 [dg -6], weight defect|ban|gc|orthogonal VALUE. Positions are 1-based.
+                </p>
+                <div className="add-form-grid">
+                    <div className="form-group">
+                        <label className="form-label">Temperature (°C)</label>
+                        <input
+                            type="number"
+                            className="form-input"
+                            value={temperature}
+                            onChange={(e) => setTemperature(e.target.value)}
+                        />
+                    </div>
+                    <div className="form-group">
+                        <label className="form-label">Seed</label>
+                        <input
+                            type="number"
+                            className="form-input"
+                            value={seed}
+                            onChange={(e) => setSeed(e.target.value)}
+                            placeholder="Random"
+                        />
+                    </div>
+                    <button className="btn btn-primary" onClick={runDesign} disabled={running}>
+                        {running ? 'Designing...' : 'Design'}
+                    </button>
+                    {running && <button className="btn btn-secondary" onClick={cancelDesign}>Cancel</button>}
+                </div>
+            </div>
+
+            {trace.length > 0 && (
+                <div className="results-section">
+                    <h4>Objective</h4>
+                    <LineChart series={[{label: 'Weighted objective', points: trace}]}
+                               xLabel="Iteration" yLabel="Objective"/>
+                </div>
+            )}
+
+            {design && (
+                <div className="results-section">
+                    <h4>{design.sequence.length} nt design after {design.iterations} iterations</h4>
+                    <div className="sequence-box">{design.sequence}</div>
+                    <div className="sequence-box">{design.target}</div>
+                    <p className="library-item-meta">
+                        Objective {design.objective}
+                        {Object.entries(design.objectives).map(([name, value]) => ` · ${name} ${value}`).join('')}
+                    </p>
+                </div>
+            )}
+        </div>
+    );
+};
+
+export default StructureDesign;
@@ -0,0 +1,62 @@
+// VirtualGel.jsx
+import React from 'react';
+import './OligoDesigner.css';
+
+const LADDERS = [
+    {value: '1kb', label: '1 kb'},
+    {value: '1kb_plus', label: '1 kb Plus'},
+    {value: '100bp', label: '100 bp'},
+    {value: '50bp', label: '50 bp'},
+    {value: 'lambda_hindiii', label: 'λ HindIII'}
+];
+
+export const LadderSelect = ({value, onChange}) => (
+    <select className="form-input" value={value} onChange={(e) => onChange(e.target.value)}>
+        {LADDERS.map(l => <option key={l.value} value={l.value}>{l.label}</option>)}
+    </select>
+);
+
+const save = (url, filename) => {
+    const link = document.createElement('a');
+    link.href = url;
+    link.download = filename;
+    link.click();
+};
+
+// Gel image rendered by the API, with SVG and PNG downloads (PNG is rasterised in the browser)
+const VirtualGel = ({svg, filename = 'gel'}) => {
+    const src = `data:image/svg+xml;charset=utf-8,${encodeURIComponent(svg)}`;
+
+    const downloadSvg = () => {
+        const url = URL.createObjectURL(new Blob([svg], {type: 'image/svg+xml'}));
+        save(url, `${filename}.svg`);
+        URL.revokeObjectURL(url);
+    };
+
+    const downloadPng = () => {
+        const image = new Image();
+        image.onload = () => {
+            const scale = 2;
+            const canvas = document.createElement('canvas');
+            canvas.width = image.width * scale;
+            canvas.height = image.height * scale;
+            const context = canvas.getContext('2d');
+            context.scale(scale, scale);
+            context.drawImage(image, 0, 0);
+            save(canvas.toDataURL('image/png'), `${filename}.png`);
+        };
+        image.src = src;
+    };
+
+    return (
+        <div>
+            <img alt="Virtual gel" src={src}/>
+            <div>
+                <button className="btn btn-primary" onClick={downloadSvg}>Download SVG</button>
+                <button className="btn btn-primary" onClick={downloadPng}>Download PNG</button>
+            </div>
+        </div>
+    );
+};
+
+export default VirtualGel;
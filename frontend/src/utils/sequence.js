@@ -0,0 +1,82 @@
+// Small sequence operations run in the browser, mirroring backend/core/sequence.py so that
+// reverse complement, GC content and translation need no server round trip and work offline.
+
+// IUPAC nucleotide complements, including ambiguity codes; gaps complement to themselves
+const COMPLEMENTS = {
+    A: 'T', T: 'A', G: 'C', C: 'G', U: 'A',
+    R: 'Y', Y: 'R', S: 'S', W: 'W', K: 'M', M: 'K',
+    B: 'V', V: 'B', D: 'H', H: 'D', N: 'N',
+    '-': '-', '.': '.'
+};
+
+// Standard genetic code (NCBI translation table 1), codons ordered TCAG
+const CODON_BASES = 'TCAG';
+const CODON_AMINO_ACIDS = 'FFLLSSSSYY**CC*WLLLLPPPPHHQQRRRRIIIMTTTTNNKKSSRRVVVVAAAADDEEGGGG';
+export const CODON_TABLE = {};
+[...CODON_BASES].forEach((a, i) => [...CODON_BASES].forEach((b, j) => [...CODON_BASES].forEach((c, k) => {
+    CODON_TABLE[a + b + c] = CODON_AMINO_ACIDS[16 * i + 4 * j + k];
+})));
+
+// Uppercase a sequence and strip whitespace
+export const cleanSequence = (sequence) => sequence.replace(/\s+/g, '').toUpperCase();
+
+// Complement of a DNA sequence (IUPAC aware); anything else complements to N
+export const complement = (sequence) => [...cleanSequence(sequence)].map(base => COMPLEMENTS[base] || 'N').join('');
+
+export const reverseComplement = (sequence) => [...complement(sequence)].reverse().join('');
+
+// GC content percentage, counting S (G or C) as GC
+export const gcContent = (sequence) => {
+    const clean = cleanSequence(sequence);
+    if (!clean) {
+        return 0;
+    }
+    const gc = [...clean].filter(base => base === 'G' || base === 'C' || base === 'S').length;
+    return gc / clean.length * 100;
+};
+
+// Translate a DNA sequence into a protein sequence starting at the given frame
+export const translate = (sequence, frame = 0, toStop = false) => {
+    const clean = cleanSequence(sequence).replace(/U/g, 'T');
+    let protein = '';
+    for (let i = frame; i < clean.length - 2; i += 3) {
+        const aminoAcid = CODON_TABLE[clean.slice(i, i + 3)] || 'X';
+        if (toStop && aminoAcid === '*') {
+            break;
+        }
+        protein += aminoAcid;
+    }
+    return protein;
+};
+
+// Same result as POST /api/analysis/translate: frames +1..+3 and -1..-3, stop positions as 0-based
+// forward-strand codon starts, and each amino acid aligned under the middle base of its codon
+export const sixFrameTranslate = (sequence) => {
+    const clean = cleanSequence(sequence).replace(/U/g, 'T');
+    const length = clean.length;
+    const frames = [];
+    [['+', clean], ['-', reverseComplement(clean)]].forEach(([strand, seq]) => {
+        for (let offset = 0; offset < 3; offset++) {
+            const protein = translate(seq, offset);
+            const aligned = Array(length).fill(' ');
+            const stops = [];
+            [...protein].forEach((aminoAcid, i) => {
+                const codonStart = offset + 3 * i;
+                const fwdStart = strand === '+' ? codonStart : length - codonStart - 3;
+                aligned[fwdStart + 1] = aminoAcid;
+                if (aminoAcid === '*') {
+                    stops.push(fwdStart);
+                }
+            });
+            frames.push({
+                frame: `${strand}${offset + 1}`,
+                strand,
+                offset,
+                protein,
+                stops: stops.sort((a, b) => a - b),
+                aligned: aligned.join('')
+            });
+        }
+    });
+    return {success: true, length, sequence: clean, frames};
+};